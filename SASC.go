@@ -2,17 +2,18 @@
  * SASC (Sistema Automático de detección de Similaridad de Código) analiza todos los archivos de una extensión
  * definida por el usuario (por defecto se usa la extensión "go") desde el directorio de ejecución (inclusive).
  *
- * El análisis consiste en determinar por cada archivo la frecuencia de todos sus caracteres 
- * (vector n-dimensional de características) y calcular la distancia euclidiana entre ellos 
+ * El análisis consiste en determinar por cada archivo la frecuencia de todos sus caracteres
+ * (vector n-dimensional de características) y calcular la distancia euclidiana entre ellos
  * usando dicho vector.
  *
  * Luego se pueden generar dos informes en pantalla:
- * - Agrupación de trabajos (grupos) que se encuentran a una distancia máxima definida por el usuario de un código 
+ * - Agrupación de trabajos (grupos) que se encuentran a una distancia máxima definida por el usuario de un código
  *   central identificado automáticamente.
  *   Disponible desde la versión 1.8. En la versión 2.0 se optimizó en velocidad y en la cantidad de grupos.
- *   Solamente se repiten integrantes en un grupo si aparece un integrante nuevo a una distancia máxima del código central, 
- *   además se marcan los integrantes que están en otros grupos con un (*) y cual es el código que está como centro de 
- *   dicho grupo.
+ *   Solamente se repiten integrantes en un grupo si aparece un integrante nuevo a una distancia máxima del código central,
+ *   además se marcan los integrantes que están en otros grupos con un (*) y cual es el código que está como centro de
+ *   dicho grupo. El código central es el medoide del grupo (el archivo con la menor suma de distancias a los demás
+ *   integrantes) y por cada grupo se reporta su diámetro (la mayor distancia entre dos integrantes).
  * - Distancia de cada archivo a todos los demás (entre menor la distancia, mayor la similaridad, en donde 0.0 indica que son idénticos con respecto
  *   a su vector de características).
  *
@@ -29,19 +30,29 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/jugutier73/SASC/pkg/sasc"
 )
 
 // Constante que indica el tamaño de la tabla ASCII
-const MAX_ASCII = 256
+const MAX_ASCII = sasc.MaxASCII
+
+// Comandos (el primer parámetro, antes de las opciones); sin comando se ejecuta "analyze"
+var comandos = []string{"analyze", "report", "serve", "classroom", "calibrate", "robustness", "dashboard", "simhash", "cache", "db"}
+
+// Dirección del modo servidor del comando serve sin --serve
+const DIRECCION_SERVIDOR_DEFECTO = ":8080"
+
+// Cantidad de caracteres hexadecimales del identificador de un grupo
+const LONGITUD_IDENTIFICADOR_GRUPO = sasc.GroupIDLength
 
 // Estructura para almacenar la información de la distancia a un archivo.
 // Necesario porque al ordenar sin perder la información del código del que se tiene esa distancia
@@ -53,132 +64,848 @@ type Distancia struct {
 }
 
 // Estructura para almacenar la información de un archivo
-// - nombre del archivo
-// - caracteristicas (frecuencias por cada entrada de la tabla ASCII)
-// - distancias a todos los demás archivos
-// - si el archivo ya pertenece o no a un grupo
+// - nombre del archivo en los reportes
+// - ruta del archivo relativa al directorio base
+// - tamaño del archivo original en bytes
+// - contenido a analizar (después del preprocesamiento)
+// - caracteristicas (frecuencias por cada entrada de la tabla ASCII o de n-gramas con --hash-dims)
+// - frecuencias dispersas de n-gramas de tokens y su proyección (solo para el motor de tokens)
+// - líneas normalizadas (solo para el motor de líneas)
+// - tamaño del contenido comprimido (solo para el motor ncd)
+// - huella de 64 bits (solo para el motor simhash)
+// - tokens (solo para la evidencia por fragmentos y la cobertura)
+// - distancias a todos los demás archivos (en float64 o en float32 con --matrix-precision) y si están almacenadas al cuadrado
+// - nombres de los archivos idénticos que representa (solo con --dedup)
+// - contenido de los archivos de los .zip del listado (para leer el archivo original al mostrar la evidencia)
 type CodigoFuente struct {
-	nombre          string
-	caracteristica  []int
-	tablaDistancias []Distancia
-	perteneceGrupo  bool
+	nombre              string
+	ruta                string
+	tamano              int
+	contenido           []byte
+	caracteristica      []int
+	ngramas             map[uint64]float64
+	proyeccion          []float64
+	lineas              []Linea
+	comprimido          int
+	simhash             uint64
+	tokens              []Token
+	tablaDistancias     []Distancia
+	tablaDistancias32   []float32
+	distanciasCuadradas bool
+	alias               []string
+	archivosZip         ArchivosComprimidos
+}
+
+// Estructura para almacenar un integrante de un grupo
+// - índice del código fuente
+// - distancia al código central del grupo
+// - si el archivo ya pertenecía a un grupo anterior (se marca con un (*))
+type IntegranteGrupo struct {
+	indice          int
+	distanciaCentro float64
+	enGrupoAnterior bool
+}
+
+// Estructura para almacenar un grupo de trabajos a una distancia máxima de su código central
+// - identificador estable del grupo (hash del contenido del medoide)
+// - índice del código central (medoide)
+// - diámetro del grupo
+// - par ejemplar: los dos integrantes más parecidos y su distancia (por donde se sugiere iniciar la revisión)
+// - integrantes del grupo (incluyendo el código central)
+// - términos característicos del grupo (vacío si no se nombran)
+type Grupo struct {
+	identificador     string
+	centro            int
+	diametro          float64
+	ejemplar          [2]int
+	distanciaEjemplar float64
+	integrantes       []IntegranteGrupo
+	terminos          []string
+}
+
+// Estructura para almacenar los parámetros de ejecución definidos por el usuario
+// - extensión de los archivos a analizar
+// - criterio de la distancia máxima para filtrar la impresión y formar grupos ("none", "auto" o un valor)
+// - nombre del archivo CSV (vacío si no se genera)
+// - si se imprime el reporte de composición del corpus antes del análisis
+// - nombre del archivo CSV con el reporte de validez de los archivos (vacío si no se genera)
+// - motor de características ("ascii", "lines", "tokens", "ast", "ncd" o "simhash") y puntaje del motor de líneas ("jaccard" o "containment")
+// - reducción de dimensiones del motor de tokens (vacía si no se reducen) y dimensiones del hashing de n-gramas (0 si no se usa)
+// - archivo del enunciado de la tarea (vacío si no se usa) y peso de los n-gramas exigidos por el enunciado
+// - métrica de distancia de los motores de vectores ("euclidean", "manhattan" o "chebyshev")
+// - normalización de las frecuencias de los motores de vectores ("none" o "relative")
+// - características del motor ascii ("ascii" o "ngram"), cantidad de caracteres de los n-gramas y tamaño de su vector
+// - analizador léxico del motor de tokens ("generic", "auto" o el lenguaje)
+// - si se imprime la evidencia de los pares a una distancia máxima, su motor ("lines", "rabin-karp", "suffix-array", "winnowing" o "gst") y su formato ("text" o "editor")
+// - si se imprime la cobertura de los pares a una distancia máxima
+// - si se imprimen las copias tardías (git) de los pares a una distancia máxima y su cantidad mínima de líneas
+// - si se imprime la línea de tiempo de la similaridad (git) de los pares a una distancia máxima
+// - longitud mínima de un fragmento de evidencia en tokens y en líneas, y ventana (en k-gramas) de winnowing
+// - presupuesto de tiempo de la evidencia por fragmentos por par y total (0 si no hay límite)
+// - cantidad mínima de caracteres con contenido (sin espacios ni comentarios) para analizar un archivo
+// - si se agrupan los archivos idénticos (mismo contenido) en uno solo antes del análisis
+// - cantidad de líneas y expresión regular del encabezado a eliminar antes del análisis y si se eliminan los comentarios
+// - si se renombran los identificadores, se pasa el contenido a minúsculas y se colapsan los espacios antes del análisis
+// - directorio del código base entregado por el docente cuyas líneas se eliminan de las entregas (vacío si no se usa)
+// - nombres de los archivos CSV y JSON con los grupos (vacíos si no se generan)
+// - directorio de las cartas de notificación de los grupos (vacío si no se generan), su plantilla y la fecha de la reunión
+// - cantidad de términos característicos con los que se nombra cada grupo (0 si no se nombran)
+// - distancias máximas de los niveles de grupos (vacío si no se usan niveles)
+// - si se imprimen los reportes en consola (se pueden combinar con los archivos)
+// - si se anota el percentil de cada distancia impresa
+// - cantidad de líneas coincidentes de la vista previa de cada par a una distancia máxima (0 si no se muestra)
+// - si la matriz almacena las distancias euclidianas al cuadrado (sin raíz cuadrada)
+// - si se imprime el resumen por directorio (subdirectorio inmediato del directorio base)
+// - peso de los archivos del puntaje por estudiante ("size" o "equal", vacío si no se imprime) e importancia por patrón
+// - si se imprimen las distancias entre grupos y la distancia máxima entre medoides para fusionar grupos (-1 si no se fusionan)
+// - destinos de salida del resultado JSON (consola, archivo, HTTP o S3)
+// - comandos de los ganchos a ejecutar con el resultado JSON al terminar el análisis
+// - reglas personalizadas para marcar pares de archivos
+// - bandas de severidad de los pares (vacío si no se clasifican)
+// - cantidad de pares de la cola de revisión (0 si no se arma), nombres de sus archivos CSV y HTML (vacíos si no se generan) y semilla de los empates
+// - monitores entre los que se reparte la cola de revisión (vacío si no se reparte)
+// - directorio del paquete de reporte estático (vacío si no se genera), marca de los reportes HTML y si son accesibles
+// - datos personales que se ocultan de los comentarios en los reportes, expresión de los códigos de estudiante y si el paquete los conserva
+// - archivo del flujo de resultados durante el cálculo de las distancias (vacío si no se usa)
+// - subtareas que se analizan de forma independiente (vacío si se analiza todo el corpus junto)
+// - filtro de las celdas del archivo CSV por encima de la distancia máxima ("none", "blank" u "omit")
+// - cantidad de decimales de las distancias del archivo CSV (-1 para la precisión completa)
+// - formato de las rutas en los reportes, etiqueta del directorio base y lista de estudiantes con la que se presentan sus directorios
+// - directorio de la caché de resultados (vacío si no se usa)
+// - si se leen las entregas de la entrada estándar (flujo tar) y se escribe el resultado JSON en la salida estándar
+// - si se atienden peticiones JSON-RPC por la entrada y la salida estándar (backend de extensiones de editor)
+// - dirección del modo servidor, cantidad máxima de trabajos concurrentes y tiempo de retención de los trabajos
+// - tamaño máximo en MB del cuerpo de las peticiones con entregas del modo servidor
+// - núcleo del cálculo de las distancias ("auto", "generic" o "unrolled"), si se calculan con la matriz de Gram y precisión de la matriz
+// - modo del cálculo de los pares ("exact" o "lsh") y cantidad de bandas y de filas por banda de LSH
+// - cantidad de trabajadores de la extracción de las características y del cálculo de las distancias (0 para GOMAXPROCS)
+// - dirección de los perfiles de pprof (vacía si no se atienden), si se registra el uso de memoria y cada cuánto
+// - archivo de autorización, encabezado del usuario autenticado por el proxy OIDC y direcciones del proxy
+// - directorio de datos del servidor y tiempo de retención de las entregas originales
+// - archivo de configuración LTI
+// - URL, token, curso, tarea y directorio de descarga de Canvas
+// - profundidad máxima de los .zip internos y tamaño máximo descomprimido de un .zip en MB
+// - tamaño máximo de cada archivo de un flujo tar en MB y cantidad máxima de archivos leídos de un flujo tar
+// - comando ("classroom", "calibrate", "robustness", "dashboard", "simhash", "cache", "db", "report", "serve" o vacío para analyze) y acción de los comandos simhash y de administración ("index", "check", "stats", "clear", "vacuum", "migrate" o "encrypt")
+// - directorio a analizar (vacío para el directorio de ejecución) y archivo del resultado guardado del comando report
+// - lista de estudiantes, organización, tarea, fecha límite, directorio y servidor de GitHub Classroom
+// - directorio de los archivos de control del comando calibrate, tamaño de sus muestras (0 para todos), rondas y semilla
+// - archivo a ofuscar y motores a evaluar del comando robustness
+// - archivo HTML del tablero del comando dashboard (vacío si no se genera)
+// - archivo del índice de huellas del comando simhash
+// - directorio del corpus de la autoverificación de los estudiantes en el modo servidor (vacío si no se atiende)
+// - peticiones por minuto de cada cliente y peticiones por día de cada curso del modo servidor (0 sin límite)
+// - archivo del registro de auditoría de los modos servidor, report y db (vacío si no se audita)
+// - archivo de la clave del cifrado en reposo de la caché y del directorio de datos (vacío si no se cifran)
+// - archivos del preajuste aplicado y del preajuste en el que se guarda la configuración (vacíos si no se usan)
+type Parametros struct {
+	extension             string
+	criterioDistancia     CriterioDistancia
+	nombreTablaCSV        string
+	reporteCorpus         bool
+	reporteValidez        string
+	motor                 string
+	puntajeLineas         string
+	reduccion             string
+	dimensionesHash       int
+	rutaEspecificacion    string
+	pesoEspecificacion    float64
+	metrica               string
+	normalizacion         string
+	caracteristicas       string
+	longitudNgrama        int
+	dimensionesVector     int
+	lexico                string
+	evidencia             bool
+	motorEvidencia        string
+	formatoEvidencia      string
+	cobertura             bool
+	copiaTardia           bool
+	minimoLineasTardia    int
+	lineaTiempo           bool
+	minimoTokens          int
+	minimoLineas          int
+	ventanaWinnowing      int
+	presupuestoPar        time.Duration
+	presupuestoTotal      time.Duration
+	minimoContenido       int
+	deduplicar            bool
+	lineasEncabezado      int
+	expresionEncabezado   string
+	sinComentarios        bool
+	sinIdentificadores    bool
+	minusculas            bool
+	colapsarEspacios      bool
+	directorioPlantilla   string
+	nombreGruposCSV       string
+	nombreGruposJSON      string
+	directorioCartas      string
+	plantillaCarta        string
+	fechaReunion          string
+	terminosGrupo         int
+	umbrales              []float64
+	consola               bool
+	percentiles           bool
+	lineasFuente          int
+	distanciasCuadradas   bool
+	resumenDirectorios    bool
+	puntajeEstudiantes    string
+	pesosArchivos         []PesoArchivo
+	distanciasGrupos      bool
+	distanciaFusion       float64
+	salidas               ListaOpciones
+	ganchos               ListaOpciones
+	reglas                []ReglaPersonalizada
+	bandasSeveridad       []BandaSeveridad
+	presupuestoRevision   int
+	nombreRevisionCSV     string
+	nombreRevisionHTML    string
+	semillaRevision       int64
+	asistentesRevision    []string
+	directorioPaquete     string
+	marcaReporte          MarcaReporte
+	reporteAccesible      bool
+	redaccion             string
+	patronIdentificacion  string
+	conservarDatosPaquete bool
+	rutaFlujo             string
+	subtareas             []Subtarea
+	filtroCSV             string
+	precisionCSV          int
+	formatoRutas          string
+	etiquetaRaiz          string
+	listaEstudiantes      string
+	directorioCache       string
+	entradaEstandar       bool
+	protocoloEditor       bool
+	direccionServidor     string
+	maximoTrabajos        int
+	maximoEnCola          int
+	tamanoMaximoPeticion  int
+	retencionTrabajos     time.Duration
+	nucleoDistancia       string
+	matrizGram            bool
+	precisionMatriz       string
+	modoPares             string
+	bandasLSH             int
+	filasLSH              int
+	trabajadores          int
+	direccionPerfilado    string
+	estadisticasMemoria   bool
+	intervaloMemoria      time.Duration
+	archivoAutorizacion   string
+	encabezadoUsuario     string
+	proxiesUsuario        string
+	directorioDatos       string
+	retencionFuentes      time.Duration
+	configuracionLTI      string
+	urlCanvas             string
+	tokenCanvas           string
+	cursoCanvas           string
+	tareaCanvas           string
+	directorioCanvas      string
+	profundidadZip        int
+	tamanoMaximoZip       int
+	tamanoMaximoTar       int
+	maximoArchivosTar     int
+	comando               string
+	accionComando         string
+	directorioAnalisis    string
+	rutaResultado         string
+	listaClassroom        string
+	organizacionClassroom string
+	tareaClassroom        string
+	fechaLimiteClassroom  string
+	directorioClassroom   string
+	servidorGit           string
+	directorioControl     string
+	muestraControl        int
+	rondasCalibracion     int
+	semillaCalibracion    int64
+	rutaRobustez          string
+	motoresRobustez       string
+	nombreTableroHTML     string
+	indiceSimHash         string
+	corpusVerificacion    string
+	limitePeticiones      int
+	cuotaCurso            int
+	archivoAuditoria      string
+	archivoCifrado        string
+	preajuste             string
+	preajusteGuardado     string
 }
 
 /*
- * Función para obtener los valores por defecto de los parámetros de la aplicación.
+ * Función para imprimir la presentación de la aplicación
+ */
+func imprimirPresentacion() {
+	fmt.Println("SISTEMA AUTOMÁTICO DE SIMILARIDAD DE CÓDIGO (SASC)")
+	fmt.Println("Julián Esteban Gutiérrez Posada")
+	fmt.Print("jugutier@uniquindio.edu.co\n\n")
+	fmt.Println("Versión 2.0 - Licencia GNU - GPL v3")
+	fmt.Print("Agosto de 2021\n\n")
+}
+
+/*
+ * Función para imprimir la ayuda de la aplicación
+ */
+func imprimirAyuda() {
+	imprimirPresentacion()
+	fmt.Print("AYUDA:\n\n")
+	fmt.Print("El programa se ejecuta con un comando opcional (por defecto analyze) y opciones con nombre\n\n")
+	fmt.Print("\t ./SASC [analyze] [--ext=extensión] [--dir=directorio] [--max-distance=distancia] [--csv=nombreTabla.csv] [opciones]\n")
+	fmt.Print("\t ./SASC report --from=resultado.json [--csv=nombreTabla.csv] [--groups-csv=...] [--groups-json=...] [opciones]\n")
+	fmt.Print("\t ./SASC serve [--serve=:8080] [opciones]\n\n")
+	fmt.Print("Por compatibilidad, la extensión y la distancia máxima o el archivo CSV también se pueden indicar como parámetros\n")
+	fmt.Print("después de las opciones (las opciones con nombre tienen prioridad):\n\n")
+	fmt.Print("\t ./SASC [opciones] [extensión] [distancia máxima | nombreTabla.csv]\n\n")
+	fmt.Print("Para analizar una tarea de GitHub Classroom (clona el repositorio de cada estudiante de la lista):\n\n")
+	fmt.Print("\t ./SASC classroom --roster=lista.csv --org=organización --assignment=tarea [opciones] [extensión] [distancia máxima]\n\n")
+	fmt.Print("Para calibrar la distancia máxima con archivos de control independientes de las entregas (por ejemplo de otro curso):\n\n")
+	fmt.Print("\t ./SASC calibrate --holdout=directorio [--holdout-sample=N] [--calibration-rounds=R] [opciones] [extensión] [distancia máxima]\n\n")
+	fmt.Print("Para evaluar qué tan robustos son los motores ante ofuscaciones sintéticas de un archivo (en el directorio de la tarea):\n\n")
+	fmt.Print("\t ./SASC robustness [--robustness-engines=ascii,lines,tokens] [opciones] archivo\n\n")
+	fmt.Print("Para comparar las entregas de una tarea entre secciones con los trabajos guardados por el modo servidor:\n\n")
+	fmt.Print("\t ./SASC dashboard --data-dir=directorio --assignment=tarea --max-distance=distancia [--dashboard-html=tablero.html] [opciones] [extensión]\n\n")
+	fmt.Print("Para indexar las huellas SimHash de un corpus y verificar entregas nuevas contra el índice:\n\n")
+	fmt.Print("\t ./SASC simhash index --simhash-index=huellas.json [opciones] [extensión]\n")
+	fmt.Print("\t ./SASC simhash check --simhash-index=huellas.json [opciones] [extensión] [distancia máxima en bits]\n\n")
+	fmt.Print("Para administrar la caché de resultados y el directorio de datos del modo servidor:\n\n")
+	fmt.Print("\t ./SASC cache stats|clear --cache-dir=directorio\n")
+	fmt.Print("\t ./SASC db vacuum|migrate --data-dir=directorio\n\n")
+	fmt.Print("Por defecto se asume \"go\", sin distancia máxima y sin archivo CSV.\n\n")
+	fmt.Print("Los archivos y directorios de las opciones (por ejemplo --students, --template o --holdout) son relativos al\n")
+	fmt.Print("directorio de ejecución, no al directorio a analizar (--dir).\n\n")
+	fmt.Print("Opciones:\n\n")
+	flag.PrintDefaults()
+	fmt.Println()
+}
+
+/*
+ * Función para determinar si un parámetro es un comando
+ * param: parámetro
+ * return: si es uno de los comandos
+ */
+func esComando(parametro string) bool {
+	for _, comando := range comandos {
+		if parametro == comando {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+ * Función para obtener los parámetros de la aplicación.
  * Por defecto se asume la extensión "go" y sin un valor mínimo de distancia para filtrar la impresión.
- * El usuario puede indicar otra extensión y si lo desea puede definir un valor mínimo
- * return: extensión por defecto, el valor mínimo de la distancia y el nombre del archivo csv
+ * El primer parámetro puede ser un comando (analyze, report, serve, classroom, calibrate, robustness, dashboard, simhash, cache o db).
+ * La extensión, la distancia máxima y el archivo CSV se indican con --ext, --max-distance y --csv. Por compatibilidad
+ * también se pueden indicar como parámetros después de las opciones (las opciones con nombre tienen prioridad).
+ * param: argumentos de la línea de comandos (sin el nombre del programa)
+ * return: los parámetros de ejecución (extensión, distancia mínima, nombre del archivo CSV y opciones)
+ *         o un error si la distancia máxima no es un número
  */
-func obtenerValorPorDefecto() (string, float64, string) {
-	extensionPorDefecto := "go"
-	distanciaMinima := math.MaxFloat64 // Sin distancia máxima
-	nombreTablaCSV := ""
+func obtenerParametros(argumentos []string) (Parametros, error) {
+	var extension, distanciaMaxima, criterio, asistentes, nombreCSV, umbrales, severidad, fusion, precision, marca string
+	var definicionesReglas, definicionesSubtareas, definicionesPesos ListaOpciones
+
+	parametros := Parametros{
+		extension:         "go",
+		criterioDistancia: CriterioDistancia{criterio: CRITERIO_NINGUNO}, // Sin distancia máxima
+		nombreTablaCSV:    "",
+		distanciaFusion:   -1, // Sin fusión de grupos
+	}
 
-	if len(os.Args) >= 2 && len(os.Args) <= 3 {
-		extensionPorDefecto = os.Args[1]
+	// Conjunto de opciones nuevo en cada llamada (las pruebas obtienen los valores por defecto sin os.Args)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	flag.CommandLine.Usage = imprimirAyuda
+	flag.BoolVar(&parametros.reporteCorpus, "stats", false, "imprime la composición del corpus (archivos por extensión, líneas, bytes y tamaño promedio por estudiante) antes del análisis")
+	flag.StringVar(&parametros.reporteValidez, "validity-report", "", "genera un archivo CSV con la codificación, el tipo (texto o binario), los errores de sintaxis (Go), el tamaño y el estado de cada archivo")
+	flag.StringVar(&parametros.motor, "engine", "ascii", "motor de características: \"ascii\" (frecuencia de caracteres), \"lines\" (líneas normalizadas), \"tokens\" (n-gramas de tokens), \"ast\" (estructura del árbol de sintaxis, solo .go), \"ncd\" (distancia de compresión normalizada, independiente del lenguaje) o \"simhash\" (huella de 64 bits, distancia de Hamming en bits)")
+	flag.StringVar(&parametros.puntajeLineas, "line-score", "jaccard", "puntaje del motor de líneas: \"jaccard\" o \"containment\"")
+	flag.StringVar(&parametros.rutaEspecificacion, "spec", "", "archivo con el enunciado de la tarea (texto, palabras o esqueleto de código): con el motor de tokens, los n-gramas exigidos por el enunciado pesan menos que las decisiones propias de cada entrega")
+	flag.Float64Var(&parametros.pesoEspecificacion, "spec-weight", PESO_ESPECIFICACION, "peso (entre 0 y 1) de los n-gramas exigidos por el enunciado (--spec), los demás pesan 1")
+	flag.StringVar(&parametros.metrica, "metric", METRICA_EUCLIDIANA, "métrica de distancia de los motores \"ascii\", \"tokens\" y \"ast\": \"euclidean\" (L2), \"manhattan\" (L1, suma de las diferencias absolutas) o \"chebyshev\" (L∞, mayor diferencia absoluta)")
+	flag.StringVar(&parametros.normalizacion, "normalize", NORMALIZACION_NINGUNA, "normalización de las frecuencias de los motores \"ascii\", \"tokens\" y \"ast\": \"none\" (frecuencias absolutas) o \"relative\" (divididas entre la longitud del archivo, una copia con comentarios agregados sigue cerca de su origen)")
+	flag.StringVar(&parametros.caracteristicas, "features", CARACTERISTICAS_ASCII, "características del motor \"ascii\": \"ascii\" (frecuencia de cada carácter) o \"ngram\" (frecuencia de los n-gramas de caracteres agrupados por su hash)")
+	flag.IntVar(&parametros.longitudNgrama, "n", LONGITUD_NGRAMA_CARACTERES, "cantidad de caracteres de cada n-grama con --features=ngram")
+	flag.IntVar(&parametros.dimensionesVector, "vector-size", DIMENSIONES_NGRAMAS_CARACTERES, "tamaño del vector en el que se agrupan los n-gramas de caracteres con --features=ngram")
+	flag.StringVar(&parametros.lexico, "lexer", LEXICO_GENERICO, "analizador léxico del motor de tokens: \"generic\" (palabras y símbolos), \"auto\" (según la extensión) o el lenguaje (\"go\", \"java\", \"python\" o \"c\"), que normaliza los identificadores y descarta los comentarios")
+	flag.IntVar(&parametros.dimensionesHash, "hash-dims", 0, "cantidad de dimensiones del vector en el que el motor de tokens agrupa los n-gramas por su hash (hashing trick), 0 usa el vocabulario completo")
+	flag.StringVar(&parametros.reduccion, "reduce", "", "reducción de dimensiones del motor de tokens antes de calcular las distancias: \"pca:K\" (K componentes principales)")
+	flag.BoolVar(&parametros.evidencia, "evidence", false, "imprime la evidencia (líneas o fragmentos coincidentes) de los pares a una distancia máxima")
+	flag.StringVar(&parametros.motorEvidencia, "evidence-engine", "lines", "motor de evidencia: \"lines\" (líneas coincidentes), \"rabin-karp\" (fragmentos comunes por par), \"suffix-array\" (fragmentos comunes de todo el corpus a la vez) \"winnowing\" (regiones con huellas compartidas, como MOSS) o \"gst\" (Greedy String Tiling, como JPlag, con --coverage imprime la similitud de cada par)")
+	flag.StringVar(&parametros.formatoEvidencia, "evidence-format", FORMATO_EVIDENCIA_TEXTO, "formato de la evidencia: \"text\" (agrupada por par) o \"editor\" (una línea \"ruta:línea:columna: mensaje\" por coincidencia, para abrirla desde la terminal o el editor)")
+	flag.BoolVar(&parametros.cobertura, "coverage", false, "imprime el porcentaje de cada archivo que aparece en el otro, para los pares a una distancia máxima")
+	flag.BoolVar(&parametros.copiaTardia, "late-copy", false, "imprime los bloques de los pares a una distancia máxima que aparecieron en un solo commit después de existir en la entrega del otro estudiante (requiere repositorios de git)")
+	flag.IntVar(&parametros.minimoLineasTardia, "late-copy-lines", 10, "cantidad mínima de líneas consecutivas de un bloque para reportarlo como copia tardía")
+	flag.BoolVar(&parametros.lineaTiempo, "timeline", false, "imprime cómo cambió la distancia de los pares a una distancia máxima después de cada commit de sus archivos (requiere repositorios de git)")
+	flag.IntVar(&parametros.minimoTokens, "min-match-tokens", 20, "cantidad mínima de tokens de un fragmento común (evidencia \"rabin-karp\", \"suffix-array\", \"winnowing\" y \"gst\" y cobertura)")
+	flag.IntVar(&parametros.ventanaWinnowing, "winnow-window", VENTANA_WINNOWING, "cantidad de k-gramas de cada ventana de la evidencia \"winnowing\", se detectan las coincidencias de al menos --min-match-tokens + ventana - 1 tokens")
+	flag.IntVar(&parametros.minimoLineas, "min-match-lines", 1, "cantidad mínima de líneas consecutivas de una coincidencia (todos los motores de evidencia y cobertura)")
+	flag.DurationVar(&parametros.presupuestoPar, "evidence-pair-timeout", 0, "tiempo máximo para buscar los fragmentos comunes de cada par con \"rabin-karp\", \"winnowing\" o \"gst\" (por ejemplo 2s), el par que lo agota se reporta solo con su distancia (0 sin límite)")
+	flag.DurationVar(&parametros.presupuestoTotal, "evidence-timeout", 0, "tiempo máximo para buscar los fragmentos comunes de todos los pares con \"rabin-karp\", \"winnowing\" o \"gst\" (por ejemplo 5m), los pares restantes se reportan solo con su distancia (0 sin límite)")
+	flag.IntVar(&parametros.minimoContenido, "min-content", 1, "cantidad mínima de caracteres sin espacios ni comentarios para analizar un archivo (0 analiza todos)")
+	flag.BoolVar(&parametros.deduplicar, "dedup", false, "agrupa los archivos idénticos (mismo hash SHA-256) antes del análisis: se calculan las distancias solamente del primero y los demás se reportan con sus distancias")
+	flag.IntVar(&parametros.lineasEncabezado, "strip-header-lines", 0, "cantidad de líneas del encabezado (autor, fecha, curso) a eliminar al inicio de cada archivo")
+	flag.StringVar(&parametros.expresionEncabezado, "strip-header-regex", "", "expresión regular del encabezado a eliminar, debe coincidir desde el inicio de cada archivo")
+	flag.BoolVar(&parametros.sinComentarios, "strip-comments", false, "elimina los comentarios (//, /* */, #, -- según el lenguaje de la extensión) antes del análisis, conservando los números de línea")
+	flag.BoolVar(&parametros.sinIdentificadores, "rename-identifiers", false, "reemplaza los identificadores que no son palabras reservadas por \"ID\" antes del análisis (extensiones go, java, py, c y h)")
+	flag.BoolVar(&parametros.minusculas, "lowercase", false, "pasa el contenido a minúsculas antes del análisis")
+	flag.BoolVar(&parametros.colapsarEspacios, "collapse-whitespace", false, "reduce los espacios y tabuladores de cada línea a uno solo y elimina la indentación antes del análisis")
+	flag.StringVar(&parametros.directorioPlantilla, "template", "", "directorio con el código base entregado a los estudiantes, sus líneas se eliminan de cada entrega antes del análisis")
+	flag.StringVar(&parametros.nombreGruposCSV, "groups-csv", "", "genera un archivo CSV con los grupos (requiere una distancia máxima)")
+	flag.StringVar(&parametros.nombreGruposJSON, "groups-json", "", "genera un archivo JSON con los grupos (requiere una distancia máxima)")
+	flag.StringVar(&parametros.directorioCartas, "letters", "", "directorio en el que se genera una carta de notificación por estudiante de cada grupo, con el resumen de la evidencia (requiere --students y una distancia máxima)")
+	flag.StringVar(&parametros.plantillaCarta, "letter-template", "", "archivo de texto de la plantilla de las cartas, con marcadores como {{nombre}}, {{grupo}}, {{evidencia}} y {{fecha_reunion}} (vacío usa la plantilla predeterminada)")
+	flag.StringVar(&parametros.fechaReunion, "meeting-date", "", "fecha y hora de la reunión para el marcador {{fecha_reunion}} de las cartas (vacía conserva el marcador para completarlo después)")
+	flag.IntVar(&parametros.terminosGrupo, "group-terms", 2, "cantidad de identificadores más característicos (TF-IDF del grupo frente al corpus) con los que se nombra cada grupo (0 no los nombra)")
+	flag.BoolVar(&parametros.consola, "console", true, "imprime los reportes en consola (grupos, evidencia, cobertura y distancias), también cuando se generan archivos")
+	flag.BoolVar(&parametros.distanciasCuadradas, "squared", false, "almacena las distancias euclidianas al cuadrado para evitar la raíz cuadrada de cada par (los reportes conservan la escala original)")
+	flag.BoolVar(&parametros.percentiles, "percentiles", false, "anota cada distancia impresa con su percentil entre las distancias de todos los pares (por ejemplo 12.40 [p0.3])")
+	flag.StringVar(&parametros.directorioPaquete, "bundle", "", "directorio en donde se genera el reporte estático (index.html, una página por par, estilos y el resultado JSON) para compartirlo o publicarlo sin servidor")
+	flag.StringVar(&parametros.rutaFlujo, "stream", "", "archivo JSON por líneas en el que se escriben las filas de la matriz y los pares a la distancia máxima a medida que se calculan, para procesar resultados parciales")
+	flag.StringVar(&marca, "branding", "", "archivo JSON con la marca institucional de los reportes HTML: logo, nombre del curso y texto al pie, por ejemplo {\"logo\": \"logo.png\", \"curso\": \"...\", \"pie\": \"...\"}")
+	flag.BoolVar(&parametros.reporteAccesible, "accessible", false, "genera los reportes HTML en su variante accesible: alto contraste, tablas con encabezados de fila y columna, etiquetas ARIA y sin información que dependa solo del color")
+	flag.StringVar(&parametros.redaccion, "redact", "email,id,name", "datos personales que se ocultan de los comentarios del código en --show-source y --bundle: \"email\" (correos), \"id\" (códigos de estudiante) y \"name\" (nombres después de @author, Autor:, Nombre:, ...), separados por comas, o \"none\"")
+	flag.StringVar(&parametros.patronIdentificacion, "student-id-pattern", PATRON_IDENTIFICACION, "expresión regular de los códigos de estudiante que se ocultan con --redact=id y con los que se busca a cada estudiante en --students")
+	flag.BoolVar(&parametros.conservarDatosPaquete, "bundle-keep-pii", false, "conserva los datos personales de los comentarios en el paquete de --bundle (paquete confidencial de evidencia), la vista previa de --show-source los sigue ocultando")
+	flag.IntVar(&parametros.lineasFuente, "show-source", 0, "imprime debajo de cada par a una distancia máxima sus primeras N líneas coincidentes (0 no las imprime)")
+	flag.BoolVar(&parametros.resumenDirectorios, "dir-summary", false, "imprime un resumen por subdirectorio inmediato (estudiante o sección): archivos, menor distancia a otro directorio y grupos en los que participa")
+	flag.StringVar(&parametros.puntajeEstudiantes, "student-score", "", "imprime el puntaje de cada estudiante con su estudiante más cercano: promedio de las distancias de sus archivos ponderado por \"size\" (líneas no vacías) o \"equal\" (el mismo peso)")
+	flag.Var(&definicionesPesos, "file-weight", "importancia \"patrón=peso\" de los archivos en el puntaje por estudiante, con el patrón de la ruta dentro del directorio del estudiante (por ejemplo \"Main.java=3\"), se puede repetir")
+	flag.BoolVar(&parametros.distanciasGrupos, "group-distances", false, "imprime la distancia entre los medoides de cada par de grupos, la menor distancia entre sus integrantes y sus integrantes comunes")
+	flag.StringVar(&fusion, "merge-groups", "", "fusiona (de forma transitiva) los grupos cuyos medoides están a esta distancia máxima, antes de generar los reportes de grupos")
+	flag.Var(&parametros.salidas, "output", "destino del resultado JSON, se puede repetir: \"-\" (consola), \"file:ruta\", \"http(s)://...\" (POST) o \"s3://bucket/clave\"")
+	flag.Var(&parametros.ganchos, "hook", "comando a ejecutar al terminar el análisis con la ruta del resultado JSON como último argumento, se puede repetir")
+	flag.Var(&definicionesSubtareas, "subassignment", "subtarea \"nombre=patrón[,patrón...]\" que se analiza de forma independiente, con los patrones de las rutas dentro del directorio del estudiante (por ejemplo \"ej1=ej1/**\"), se puede repetir")
+	flag.Var(&definicionesReglas, "rule", "regla personalizada \"nombre: expresión\" para marcar pares, por ejemplo \"copia: similitud > 85 && !mismo_estudiante\", se puede repetir")
+	flag.StringVar(&severidad, "severity", "", "bandas de severidad nivel:distancia (info, warn, critical), por ejemplo \"critical:10,warn:30,info:60\", o perfiles por motor \"ascii=critical:5;lines=critical:20,warn:40\"")
+	flag.IntVar(&parametros.presupuestoRevision, "review-queue", 0, "imprime la cola de revisión con esta cantidad de pares, del más sospechoso al menos sospechoso y uno por grupo (0 no la arma)")
+	flag.StringVar(&parametros.nombreRevisionCSV, "review-csv", "", "genera la cola de revisión como lista de chequeo CSV (por defecto con "+strconv.Itoa(PRESUPUESTO_REVISION_DEFECTO)+" pares)")
+	flag.StringVar(&parametros.nombreRevisionHTML, "review-html", "", "genera la cola de revisión como lista de chequeo HTML (por defecto con "+strconv.Itoa(PRESUPUESTO_REVISION_DEFECTO)+" pares)")
+	flag.Int64Var(&parametros.semillaRevision, "review-seed", 1, "semilla del orden aleatorio de los pares empatados de la cola de revisión")
+	flag.StringVar(&asistentes, "review-tas", "", "monitores separados por comas entre los que se reparte la cola de revisión (los pares del mismo grupo o con un archivo común quedan con el mismo monitor), con una hoja de trabajo por monitor")
+	flag.StringVar(&extension, "ext", "", "extensión de los archivos a analizar, por ejemplo \"java\" (por defecto \"go\")")
+	flag.StringVar(&parametros.directorioAnalisis, "dir", "", "directorio a analizar, incluyendo sus subdirectorios (por defecto el directorio de ejecución)")
+	flag.StringVar(&parametros.rutaResultado, "from", "", "report: archivo del resultado JSON guardado (--output=file:ruta, --bundle o un trabajo de --data-dir)")
+	flag.StringVar(&distanciaMaxima, "max-distance", "", "distancia máxima (un número mayor o igual a 0) para filtrar las distancias y formar los grupos")
+	flag.StringVar(&criterio, "threshold", "", "criterio de la distancia máxima: \"none\" (sin distancia máxima), \"auto\" (pares atípicamente cercanos, mediana - 3 * MAD de las distancias) o un número (como --max-distance)")
+	flag.StringVar(&umbrales, "thresholds", "", "distancias máximas separadas por comas (por ejemplo 20,50,100) para formar los grupos en cada nivel con la misma matriz")
+	flag.StringVar(&nombreCSV, "csv", "", "nombre del archivo CSV con la matriz de distancias")
+	flag.StringVar(&parametros.filtroCSV, "csv-threshold", "none", "celdas del archivo CSV por encima de la distancia máxima: \"none\" (se conservan), \"blank\" (quedan vacías) u \"omit\" (además se omiten los archivos sin pares cercanos)")
+	flag.StringVar(&precision, "precision", "2", "cantidad de decimales de las distancias del archivo CSV o \"full\" para la precisión completa (para procesamiento estadístico posterior)")
+	flag.StringVar(&parametros.listaEstudiantes, "students", "", "archivo CSV con la lista del curso (columnas id, name, email y section) para presentar el directorio de cada estudiante con su nombre y sección en los reportes")
+	flag.StringVar(&parametros.formatoRutas, "paths", "relative", "rutas de los archivos en los reportes: \"relative\" (relativas al directorio base), \"basename\" (solo el nombre del archivo) o \"label\" (con la etiqueta del directorio base)")
+	flag.StringVar(&parametros.etiquetaRaiz, "root-label", "", "etiqueta del directorio base para --paths=label (por defecto el nombre del directorio)")
+	flag.StringVar(&parametros.directorioCache, "cache-dir", "", "directorio de la caché de resultados, si el corpus y la configuración no cambian se cargan las distancias sin calcularlas (vacío no usa caché)")
+	flag.BoolVar(&parametros.entradaEstandar, "stdin", false, "lee las entregas como un flujo tar por la entrada estándar y escribe el resultado JSON por la salida estándar")
+	flag.BoolVar(&parametros.protocoloEditor, "rpc", false, "atiende peticiones JSON-RPC 2.0 por la entrada y la salida estándar (open, neighbors, fragments, shutdown) como backend de una extensión de editor")
+	flag.StringVar(&parametros.direccionServidor, "serve", "", "ejecuta SASC como servicio HTTP en la dirección indicada (por ejemplo \":8080\"), con POST /analyze y GET /metrics")
+	flag.IntVar(&parametros.maximoTrabajos, "max-jobs", 2, "cantidad máxima de trabajos (POST /jobs) analizados al mismo tiempo en el modo servidor")
+	flag.IntVar(&parametros.maximoEnCola, "max-queued-jobs", 100, "cantidad máxima de trabajos en espera en el modo servidor, con la cola llena POST /jobs y /lti/jobs se responden con 503")
+	flag.IntVar(&parametros.tamanoMaximoPeticion, "max-body-size", 256, "tamaño máximo en MB de las entregas de una petición del modo servidor (POST /analyze, POST /jobs y /lti/jobs), las mayores se responden con 413")
+	flag.DurationVar(&parametros.retencionTrabajos, "job-retention", 24*time.Hour, "tiempo que se conservan los trabajos terminados y sus resultados en el modo servidor (0 los conserva siempre)")
+	flag.StringVar(&parametros.nucleoDistancia, "distance-kernel", "default", "núcleo del cálculo de las distancias: \"default\" (\"unrolled\" si int es de 64 bits, sin detectar las características del procesador), \"generic\" (un elemento a la vez) o \"unrolled\" (desenrollado de a 4 elementos)")
+	flag.BoolVar(&parametros.matrizGram, "gram", false, "calcula todas las distancias a la vez con la matriz de Gram (‖a‖² + ‖b‖² - 2 a·b), solo para los motores de vectores densos: \"ascii\" o \"tokens\" con --hash-dims o --reduce")
+	flag.StringVar(&parametros.precisionMatriz, "matrix-precision", PRECISION_MATRIZ_COMPLETA, "precisión de la matriz de distancias en memoria: \"float64\" o \"float32\" (menos de la mitad de la memoria, unos 7 dígitos significativos)")
+	flag.StringVar(&parametros.modoPares, "pairs", PARES_EXACTO, "pares con la distancia calculada: \"exact\" (todos) o \"lsh\" (solo los candidatos por MinHash y LSH, para corpus muy grandes; requiere --max-distance)")
+	flag.IntVar(&parametros.bandasLSH, "lsh-bands", 32, "lsh: cantidad de bandas de la firma MinHash (más bandas encuentran más pares candidatos)")
+	flag.IntVar(&parametros.filasLSH, "lsh-rows", 4, "lsh: cantidad de valores de la firma MinHash por banda (más filas encuentran menos pares candidatos)")
+	flag.IntVar(&parametros.trabajadores, "workers", 0, "cantidad de trabajadores que leen los archivos y calculan la matriz de distancias al mismo tiempo (0 para la cantidad de procesadores, 1 en un solo hilo)")
+	flag.StringVar(&parametros.direccionPerfilado, "pprof", "", "atiende los perfiles de net/http/pprof en la dirección indicada (por ejemplo \":6060\") para diagnosticar ejecuciones largas")
+	flag.BoolVar(&parametros.estadisticasMemoria, "memstats", false, "imprime periódicamente en la salida de errores el uso de memoria y del recolector de basura")
+	flag.DurationVar(&parametros.intervaloMemoria, "memstats-interval", 10*time.Second, "intervalo entre los registros del uso de memoria de --memstats")
+	flag.StringVar(&parametros.directorioDatos, "data-dir", "", "directorio en donde el modo servidor guarda las entregas y los resultados de los trabajos (vacío los conserva solo en memoria)")
+	flag.DurationVar(&parametros.retencionFuentes, "source-retention", 30*24*time.Hour, "tiempo que se conservan las entregas originales en el directorio de datos (0 las conserva siempre)")
+	flag.StringVar(&parametros.archivoAutorizacion, "auth-file", "", "archivo JSON con los cursos de cada clave de API y usuario, el modo servidor requiere autenticación si se indica")
+	flag.StringVar(&parametros.encabezadoUsuario, "auth-header", "", "encabezado con el usuario autenticado por un proxy OIDC delante del servidor (por ejemplo X-Forwarded-Email)")
+	flag.StringVar(&parametros.proxiesUsuario, "auth-proxy", "", "direcciones IP o redes (CIDR) del proxy OIDC separadas por comas, el encabezado --auth-header solo se acepta en las peticiones que llegan desde ellas")
+	flag.StringVar(&parametros.configuracionLTI, "lti-config", "", "archivo JSON con las plataformas LTI 1.3 (Moodle, Canvas) desde las que se puede lanzar SASC en el modo servidor")
+	flag.StringVar(&parametros.urlCanvas, "canvas-url", "", "URL de Canvas (por ejemplo https://uniquindio.instructure.com) para descargar las entregas de una tarea")
+	flag.StringVar(&parametros.tokenCanvas, "canvas-token", "", "token de acceso a la API de Canvas (por defecto la variable de ambiente CANVAS_TOKEN)")
+	flag.StringVar(&parametros.cursoCanvas, "canvas-course", "", "identificador del curso de Canvas")
+	flag.StringVar(&parametros.tareaCanvas, "canvas-assignment", "", "identificador de la tarea de Canvas, sus entregas se descargan y se analizan")
+	flag.StringVar(&parametros.directorioCanvas, "canvas-dir", "", "directorio en donde se descargan las entregas de Canvas (por defecto canvas-<tarea>)")
+	flag.IntVar(&parametros.profundidadZip, "zip-depth", 3, "profundidad máxima de los .zip dentro de otros .zip (entregas del directorio, de Canvas y de los flujos tar)")
+	flag.IntVar(&parametros.tamanoMaximoZip, "zip-max-size", 200, "tamaño máximo descomprimido de cada .zip en MB, incluyendo sus .zip internos")
+	flag.IntVar(&parametros.tamanoMaximoTar, "tar-max-entry-size", 50, "tamaño máximo en MB de cada archivo de un flujo tar (--stdin, modo servidor y dashboard), un archivo mayor rechaza el flujo")
+	flag.IntVar(&parametros.maximoArchivosTar, "tar-max-files", 20000, "cantidad máxima de archivos a analizar de un flujo tar, incluyendo los de sus .zip, más archivos rechazan el flujo")
+	flag.StringVar(&parametros.listaClassroom, "roster", "", "classroom: archivo CSV con la lista de estudiantes exportada de GitHub Classroom")
+	flag.StringVar(&parametros.organizacionClassroom, "org", "", "classroom: organización de GitHub de la tarea")
+	flag.StringVar(&parametros.tareaClassroom, "assignment", "", "classroom: prefijo de los repositorios de la tarea (<tarea>-<usuario>); dashboard: tarea de los trabajos del modo servidor (?assignment=)")
+	flag.StringVar(&parametros.fechaLimiteClassroom, "deadline", "", "classroom: fecha límite (por ejemplo 2021-08-20T23:59:00-05:00), se usa el último commit anterior a ella")
+	flag.StringVar(&parametros.directorioClassroom, "classroom-dir", "", "classroom: directorio en donde se clonan los repositorios (por defecto classroom-<tarea>)")
+	flag.StringVar(&parametros.servidorGit, "git-server", "https://github.com/", "classroom: servidor de los repositorios")
+	flag.StringVar(&parametros.directorioControl, "holdout", "", "calibrate: directorio con archivos de control independientes de las entregas (por ejemplo soluciones de otro curso)")
+	flag.IntVar(&parametros.muestraControl, "holdout-sample", 0, "calibrate: cantidad de archivos de control de cada ronda, tomados al azar (0 para todos en una sola ronda)")
+	flag.IntVar(&parametros.rondasCalibracion, "calibration-rounds", RONDAS_CALIBRACION, "calibrate: cantidad de rondas con muestras de los archivos de control (--holdout-sample)")
+	flag.Int64Var(&parametros.semillaCalibracion, "calibration-seed", 1, "calibrate: semilla de las muestras aleatorias de los archivos de control")
+	flag.StringVar(&parametros.motoresRobustez, "robustness-engines", MOTORES_ROBUSTEZ, "robustness: motores a evaluar, separados por comas")
+	flag.StringVar(&parametros.indiceSimHash, "simhash-index", "", "simhash: archivo JSON del índice de huellas SimHash")
+	flag.StringVar(&parametros.nombreTableroHTML, "dashboard-html", "tablero.html", "dashboard: archivo HTML del tablero de la tarea (vacío no lo genera)")
+	flag.IntVar(&parametros.limitePeticiones, "rate-limit", 0, "serve: análisis y comparaciones por minuto de cada cliente (clave de API, usuario o IP), 0 sin límite")
+	flag.IntVar(&parametros.cuotaCurso, "course-quota", 0, "serve: análisis y comparaciones por día (UTC) de cada curso, 0 sin cuota")
+	flag.StringVar(&parametros.archivoAuditoria, "audit-log", "", "serve, report y db: archivo en donde se agrega quién ejecuta cada análisis y quién consulta cada reporte (JSON por línea)")
+	flag.StringVar(&parametros.archivoCifrado, "encryption-key", "", "archivo con la clave (64 caracteres hexadecimales) con la que se cifran con AES-256-GCM la caché (--cache-dir) y el directorio de datos (--data-dir)")
+	flag.StringVar(&parametros.preajuste, "preset", "", "archivo JSON del preajuste con las opciones a aplicar (las opciones y parámetros de la línea de comandos tienen prioridad)")
+	flag.StringVar(&parametros.preajusteGuardado, "save-preset", "", "guarda la configuración efectiva completa de la ejecución en un archivo JSON de preajuste para aplicarla con --preset")
+	flag.StringVar(&parametros.corpusVerificacion, "self-check", "", "serve: directorio del corpus de la autoverificación, el servidor solo atiende POST /v1/self-check")
 
-		if extensionPorDefecto == "--help" {
-			fmt.Println("AYUDA:\n")
-			fmt.Println("El programa se puede ejecutar con hasta con dos parámetros opcionales\n")
-			fmt.Println("\t ./SASC [extensión] [distancia máxima | nombreTabla.csv]\n")
-			fmt.Println("Por defecto se asume \"go\", sin distancia máxima y sin archivo CSV.\n")
-			os.Exit(0)
+	// Comando opcional antes de las opciones (por ejemplo ./SASC classroom --roster=lista.csv ... o ./SASC cache stats --cache-dir=dir)
+	if len(argumentos) > 0 && argumentos[0] == "analyze" {
+		argumentos = argumentos[1:]
+	} else if len(argumentos) > 0 && esComando(argumentos[0]) {
+		parametros.comando = argumentos[0]
+		argumentos = argumentos[1:]
+
+		if (parametros.comando == "cache" || parametros.comando == "db" || parametros.comando == "simhash") && len(argumentos) > 0 && !strings.HasPrefix(argumentos[0], "-") {
+			parametros.accionComando = argumentos[0]
+			argumentos = argumentos[1:]
+		}
+	}
+	flag.CommandLine.Parse(argumentos)
+
+	argumentos = flag.Args()
+
+	if parametros.preajuste != "" {
+		if err := aplicarPreajuste(parametros.preajuste, argumentos); err != nil {
+			return parametros, err
 		}
+	}
 
-		if len(os.Args) == 3 {
-			// Intento de convertir el tercer parámetro a un entero,
+	if parametros.comando == "robustness" {
+		if len(argumentos) != 1 {
+			return parametros, fmt.Errorf("El comando robustness requiere un único archivo a ofuscar")
+		}
+		// El archivo es relativo al directorio de ejecución, como las rutas de las opciones
+		if ruta, err := filepath.Abs(argumentos[0]); err == nil {
+			parametros.rutaRobustez = ruta
+		}
+		parametros.extension = strings.TrimPrefix(filepath.Ext(argumentos[0]), ".")
+		argumentos = nil
+	}
+
+	if len(argumentos) > 2 {
+		return parametros, fmt.Errorf("Demasiados parámetros (%s): las opciones (--opción) deben indicarse antes de la extensión", strings.Join(argumentos, " "))
+	}
+	if len(argumentos) > 0 && (parametros.comando == "report" || parametros.comando == "serve") {
+		return parametros, fmt.Errorf("El comando %s no recibe parámetros, solamente opciones (--opción)", parametros.comando)
+	}
+
+	if len(argumentos) >= 1 && len(argumentos) <= 2 {
+		parametros.extension = argumentos[0]
+
+		if len(argumentos) == 2 {
+			// Intento de convertir el segundo parámetro a un entero,
 			// si es posible, entonces será la distancia máxima definida por el usuario
 			// en otro caso será el nombre del archivo CSV
-			distancia, err := strconv.ParseFloat(os.Args[2], 64)
-
-			if err != nil {
-				nombreTablaCSV = os.Args[2]
+			if _, err := strconv.ParseFloat(argumentos[1], 64); err != nil {
+				parametros.nombreTablaCSV = argumentos[1]
 			} else {
-				distanciaMinima = distancia
+				criterioDistancia, err := obtenerCriterioValor(argumentos[1])
+				if err != nil {
+					return parametros, err
+				}
+				parametros.criterioDistancia = criterioDistancia
 			}
 		}
 	}
 
-	return extensionPorDefecto, distanciaMinima, nombreTablaCSV
+	if extension != "" {
+		parametros.extension = extension
+	}
+
+	if parametros.comando == "report" && parametros.rutaResultado == "" {
+		return parametros, fmt.Errorf("El comando report requiere el archivo del resultado guardado (--from)")
+	}
+	if parametros.comando == "report" && (distanciaMaxima != "" || criterio != "" || umbrales != "" || fusion != "") {
+		return parametros, fmt.Errorf("El comando report conserva la distancia máxima y los grupos del resultado guardado (sin --max-distance, --threshold, --thresholds ni --merge-groups)")
+	}
+	if parametros.comando == "calibrate" && parametros.directorioControl == "" {
+		return parametros, fmt.Errorf("El comando calibrate requiere el directorio de los archivos de control (--holdout)")
+	}
+	if parametros.comando == "dashboard" && (parametros.directorioDatos == "" || parametros.tareaClassroom == "") {
+		return parametros, fmt.Errorf("El comando dashboard requiere el directorio de datos del modo servidor (--data-dir) y la tarea (--assignment)")
+	}
+	if parametros.comando == "simhash" && parametros.indiceSimHash == "" {
+		return parametros, fmt.Errorf("El comando simhash requiere el archivo del índice (--simhash-index)")
+	}
+	if parametros.directorioCartas != "" && parametros.listaEstudiantes == "" {
+		return parametros, fmt.Errorf("Las cartas de notificación (--letters) requieren la lista de estudiantes (--students)")
+	}
+	if parametros.comando == "serve" && parametros.direccionServidor == "" {
+		parametros.direccionServidor = DIRECCION_SERVIDOR_DEFECTO
+	}
+	if parametros.archivoAuditoria != "" && parametros.direccionServidor == "" && parametros.comando != "report" && parametros.comando != "db" {
+		return parametros, fmt.Errorf("El registro de auditoría (--audit-log) requiere el modo servidor (serve o --serve) o los comandos report y db")
+	}
+	if parametros.corpusVerificacion != "" && parametros.direccionServidor == "" {
+		return parametros, fmt.Errorf("La autoverificación (--self-check) requiere el modo servidor (serve o --serve)")
+	}
+
+	// Las rutas de las opciones son relativas al directorio de ejecución, no al directorio a analizar (--dir)
+	for _, ruta := range []*string{&parametros.preajusteGuardado, &parametros.indiceSimHash, &parametros.listaEstudiantes,
+		&parametros.directorioCartas, &parametros.plantillaCarta, &parametros.directorioPlantilla, &parametros.directorioControl,
+		&parametros.archivoAuditoria, &parametros.corpusVerificacion} {
+		if *ruta == "" {
+			continue
+		}
+		if absoluta, err := filepath.Abs(*ruta); err == nil {
+			*ruta = absoluta
+		}
+	}
+
+	if distanciaMaxima != "" {
+		criterioDistancia, err := obtenerCriterioValor(distanciaMaxima)
+
+		if err != nil {
+			return parametros, err
+		}
+		parametros.criterioDistancia = criterioDistancia
+	}
+
+	if criterio != "" {
+		criterioDistancia, err := obtenerCriterioDistancia(criterio)
+
+		if err != nil {
+			return parametros, err
+		}
+		parametros.criterioDistancia = criterioDistancia
+	}
+	if parametros.modoPares != PARES_EXACTO && parametros.modoPares != PARES_LSH {
+		return parametros, fmt.Errorf("Modo de los pares (--pairs) \"%s\" no soportado (exact | lsh)", parametros.modoPares)
+	}
+	if parametros.modoPares == PARES_LSH && parametros.criterioDistancia.criterio != CRITERIO_VALOR {
+		return parametros, fmt.Errorf("El modo aproximado (--pairs=lsh) requiere una distancia máxima definida por el usuario (--max-distance)")
+	}
+	if parametros.modoPares == PARES_LSH && (parametros.bandasLSH < 1 || parametros.filasLSH < 1) {
+		return parametros, fmt.Errorf("La cantidad de bandas (--lsh-bands) y de filas (--lsh-rows) de LSH debe ser al menos 1")
+	}
+	if parametros.comando == "simhash" && parametros.accionComando == "check" && parametros.criterioDistancia.criterio != CRITERIO_VALOR {
+		return parametros, fmt.Errorf("El comando simhash check requiere la distancia de Hamming máxima en bits (--max-distance)")
+	}
+	if parametros.comando == "dashboard" && !parametros.criterioDistancia.definido() {
+		return parametros, fmt.Errorf("El comando dashboard requiere la distancia máxima (--max-distance o --threshold)")
+	}
+
+	if nombreCSV != "" {
+		parametros.nombreTablaCSV = nombreCSV
+	}
+
+	reglas, err := compilarReglas(definicionesReglas)
+
+	if err != nil {
+		return parametros, err
+	}
+	parametros.reglas = reglas
+
+	subtareas, err := obtenerSubtareas(definicionesSubtareas)
+	if err != nil {
+		return parametros, err
+	}
+	if len(subtareas) > 0 && (len(parametros.salidas) > 0 || len(parametros.ganchos) > 0 || parametros.rutaFlujo != "") {
+		return parametros, fmt.Errorf("Las subtareas (--subassignment) no se admiten con --output, --hook ni --stream")
+	}
+	parametros.subtareas = subtareas
+
+	if parametros.puntajeEstudiantes != "" && parametros.puntajeEstudiantes != PUNTAJE_TAMANO && parametros.puntajeEstudiantes != PUNTAJE_IGUAL {
+		return parametros, fmt.Errorf("Peso de los archivos del puntaje por estudiante (--student-score) \"%s\" no válido, debe ser \"size\" o \"equal\"", parametros.puntajeEstudiantes)
+	}
+	pesos, err := obtenerPesosArchivos(definicionesPesos)
+	if err != nil {
+		return parametros, err
+	}
+	if len(pesos) > 0 && parametros.puntajeEstudiantes == "" {
+		return parametros, fmt.Errorf("La importancia de los archivos (--file-weight) requiere el puntaje por estudiante (--student-score)")
+	}
+	parametros.pesosArchivos = pesos
+
+	if umbrales != "" {
+		valores, err := obtenerUmbrales(umbrales)
+
+		if err != nil {
+			return parametros, err
+		}
+		parametros.umbrales = valores
+	}
+
+	parametros.precisionCSV = -1
+	if precision != "full" {
+		decimales, err := strconv.Atoi(precision)
+
+		if err != nil || decimales < 0 {
+			return parametros, fmt.Errorf("La precisión del archivo CSV (--precision) \"%s\" debe ser un entero mayor o igual a 0 o \"full\"", precision)
+		}
+		parametros.precisionCSV = decimales
+	}
+
+	if fusion != "" {
+		distancia, err := strconv.ParseFloat(fusion, 64)
+
+		if err != nil || distancia < 0 {
+			return parametros, fmt.Errorf("La distancia de fusión de grupos (--merge-groups) \"%s\" no es un número mayor o igual a 0", fusion)
+		}
+		parametros.distanciaFusion = distancia
+	}
+
+	if parametros.lineasFuente < 0 {
+		return parametros, fmt.Errorf("La cantidad de líneas de la vista previa (--show-source) debe ser mayor o igual a 0")
+	}
+
+	if parametros.presupuestoRevision < 0 {
+		return parametros, fmt.Errorf("La cantidad de pares de la cola de revisión (--review-queue) debe ser mayor o igual a 0")
+	}
+	if asistentes != "" {
+		nombres, err := obtenerAsistentes(asistentes)
+
+		if err != nil {
+			return parametros, err
+		}
+		parametros.asistentesRevision = nombres
+	}
+	if parametros.presupuestoRevision == 0 && (parametros.nombreRevisionCSV != "" || parametros.nombreRevisionHTML != "" || len(parametros.asistentesRevision) > 0) {
+		parametros.presupuestoRevision = PRESUPUESTO_REVISION_DEFECTO
+	}
+
+	if severidad != "" {
+		bandas, err := obtenerPerfilSeveridad(severidad, parametros.motor)
+
+		if err != nil {
+			return parametros, err
+		}
+		parametros.bandasSeveridad = bandas
+	}
+
+	marcaReporte, err := cargarMarcaReporte(marca)
+
+	if err != nil {
+		return parametros, err
+	}
+	parametros.marcaReporte = marcaReporte
+
+	return parametros, nil
 }
 
 /*
- * Función para obtener el listado de todos los archivos que cumplan con la extensión definida.
- * La lista incluye todos los archivos del directorio actual y todos sus subdirectorio.
- * param: la extensión que deben cumplir para ser ingresados a la lista
- * return: el arreglo con los nombres de todos los archivos que cumplen las condiciones
+ * Función para descartar los archivos cuyo contenido, sin espacios ni comentarios, es menor al mínimo indicado
+ * (archivos vacíos o con solo el comentario de encabezado), porque aparecen como pares casi idénticos.
+ * param: listado de archivos, extensión, cantidad mínima de caracteres con contenido y preprocesamiento
+ * return: los archivos a analizar y los archivos descartados
  */
-func obtenerListado(directorioActual string, extension string) ([]string, error) {
-	var archivos []string
-	var nombre string
-
-	err := filepath.Walk(directorioActual,
-		func(path string, info os.FileInfo, err error) error {
-			if !info.IsDir() &&
-				strings.HasSuffix(path, extension) {
-				nombre = strings.Replace(path, directorioActual, ".", 1)
-				archivos = append(archivos, nombre)
-			}
-			return nil
-		})
+func filtrarArchivosSinContenido(listado []string, extension string, minimoContenido int, preprocesamiento Preprocesamiento) ([]string, []string) {
+	var conservados, descartados []string
 
-	return archivos, err
+	for _, archivo := range listado {
+		contenido, err := preprocesamiento.archivosZip.leer(archivo)
+		if err != nil {
+			panic(err)
+		}
+
+		if contarContenidoSignificativo(preprocesamiento.preprocesar(contenido), extension) < minimoContenido {
+			descartados = append(descartados, archivo)
+		} else {
+			conservados = append(conservados, archivo)
+		}
+	}
+
+	return conservados, descartados
 }
 
 /*
- * Función para procesar un archivo (determinar la frecuencia de todos los elementos de la tabla ASCII en el archivo)
- * param: nombre del archivo a procesar
- * return: arreglo con la frecuancia de todos los elementos de la tabla ASCII en el archivo indicado
+ * Función para procesar un archivo (determinar sus características según el motor indicado)
+ * param: ruta del archivo a procesar, el motor a emplear, el preprocesamiento del contenido y la arena de las características
+ * return: la información del archivo con sus características (sin distancias)
  */
-func prodesarArchivo(nombre string) []int {
+func prodesarArchivo(ruta string, motor Motor, preprocesamiento Preprocesamiento, arena *ArenaCaracteristicas) CodigoFuente {
+
+	codigoFuente := leerCodigoFuente(ruta, preprocesamiento)
+	caracterizarEnArena(arena, motor, &codigoFuente)
 
-	tabla := make([]int, MAX_ASCII)
+	return codigoFuente
+}
+
+/*
+ * Función para leer un archivo y aplicarle el preprocesamiento (sin determinar sus características)
+ * param: ruta del archivo a leer y el preprocesamiento del contenido
+ * return: la información del archivo con su contenido a analizar
+ */
+func leerCodigoFuente(ruta string, preprocesamiento Preprocesamiento) CodigoFuente {
 
-	filebuffer, err := ioutil.ReadFile(nombre)
+	codigoFuente, err := leerArchivoCodigoFuente(ruta, preprocesamiento)
 	if err != nil {
 		panic(err)
 	}
 
-	inputdata := string(filebuffer)
-	data := bufio.NewScanner(strings.NewReader(inputdata))
-	data.Split(bufio.ScanRunes)
+	return codigoFuente
+}
+
+/*
+ * Función para leer un archivo y aplicarle el preprocesamiento, sin detener la ejecución si no se puede leer
+ * (la usan los trabajadores de la fase 1, ver caracteristicas_paralelas.go)
+ * param: ruta del archivo a leer y el preprocesamiento del contenido
+ * return: la información del archivo con su contenido a analizar o un error si no se puede leer
+ */
+func leerArchivoCodigoFuente(ruta string, preprocesamiento Preprocesamiento) (CodigoFuente, error) {
 
-	for data.Scan() {
-		tabla[int(data.Text()[0])]++
+	filebuffer, err := preprocesamiento.archivosZip.leer(ruta)
+	if err != nil {
+		return CodigoFuente{}, err
 	}
 
-	return tabla
+	return CodigoFuente{nombre: ruta, ruta: ruta, tamano: len(filebuffer), contenido: preprocesamiento.preprocesar(filebuffer), archivosZip: preprocesamiento.archivosZip}, nil
+}
+
+/*
+ * Función para determinar la frecuencia de todos los elementos de la tabla ASCII en un contenido (ver pkg/sasc)
+ * param: contenido del archivo y arreglo en el que se cuentan (se crea si es nulo, ver memoria.go)
+ * return: arreglo con la frecuancia de todos los elementos de la tabla ASCII en el contenido
+ */
+func calcularFrecuencias(filebuffer []byte, tabla []int) []int {
+	return sasc.CharacterFrequencies(filebuffer, tabla)
 }
 
 /*
- * Función que calcula la distancia euclidiana entre dos archivos usando el arreglo de frecuencias.
+ * Función que calcula la distancia euclidiana entre dos archivos usando el arreglo de frecuencias
+ * (de caracteres ASCII o de n-gramas con --hash-dims, ambos arreglos tienen la misma longitud).
  * param: dos elementos de tipo CodigoFuente
  * return: el valor de la distancia euclidiana entre estos dos archivos (códigos fuente)
  */
 func calcularDistancia(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	return math.Sqrt(calcularDistanciaCuadrada(c1, c2))
+}
 
-	suma := 0.0
-	for i := 0; i < MAX_ASCII; i++ {
-		suma += math.Pow((float64)(c1.caracteristica[i]-c2.caracteristica[i]), 2.0)
-	}
-
-	return math.Sqrt(suma)
+/*
+ * Función que calcula el cuadrado de la distancia euclidiana entre dos archivos usando el arreglo de frecuencias
+ * (con el núcleo seleccionado, ver nucleos_distancia.go)
+ * param: dos elementos de tipo CodigoFuente
+ * return: el cuadrado de la distancia euclidiana entre estos dos archivos
+ */
+func calcularDistanciaCuadrada(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	return nucleoDistancia.enteros(c1.caracteristica, c2.caracteristica)
 }
 
 /*
  * Función que determina las caracteristicas de todos los archivos indicados
- * param: arreglo con los nombres de todos los archivos para determinar sus caracteristicas
+ * param: arreglo con los nombres de todos los archivos para determinar sus caracteristicas, el motor a emplear,
+ *        el preprocesamiento del contenido, el formato de las rutas en los reportes y la cantidad de trabajadores
+ *        (0 para GOMAXPROCS, ver caracteristicas_paralelas.go)
  * return: arreglo con las caracteristicas de todos los archivos de la lista
  */
-func determinarCaracteristicas(listado []string) []CodigoFuente {
+func determinarCaracteristicas(listado []string, motor Motor, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas, trabajadores int) []CodigoFuente {
 	var tablaCodigoFuente []CodigoFuente
+	var arena ArenaCaracteristicas
 
-	cantidadArchivo := len(listado)
+	if trabajadores = obtenerTrabajadoresCaracteristicas(trabajadores, len(listado)); trabajadores > 1 {
+		return determinarCaracteristicasParalelas(listado, motor, preprocesamiento, formatoRutas, trabajadores)
+	}
 
 	for _, archivo := range listado {
-		arregloDistancia := make([]Distancia, cantidadArchivo)
-		tablaCodigoFuente = append(tablaCodigoFuente, CodigoFuente{nombre: archivo, caracteristica: prodesarArchivo(archivo), tablaDistancias: arregloDistancia, perteneceGrupo: false})
+		codigoFuente := prodesarArchivo(archivo, motor, preprocesamiento, &arena)
+		codigoFuente.nombre = formatoRutas.formatear(archivo)
+
+		tablaCodigoFuente = append(tablaCodigoFuente, codigoFuente)
 	}
 
 	return tablaCodigoFuente
@@ -186,22 +913,57 @@ func determinarCaracteristicas(listado []string) []CodigoFuente {
 
 /*
  * Función que determina las distancias entre todos los archivos de la tabla de código fuente
- * Como la matriz de distancias es una matriz simétrica, se optimizó su llenado. 
- * param: arreglo de la información de todos los archivos de código fuente
+ * Como la matriz de distancias es una matriz simétrica, se optimizó su llenado.
+ * Si se piden distancias cuadradas y el motor las soporta, se almacenan las distancias al cuadrado (sin raíz cuadrada).
+ * Si se pide la matriz de Gram y el motor es de vectores densos, se calculan todas a la vez (ver matriz_gram.go).
+ * Las tablas de distancias se crean con la precisión indicada (ver precision_matriz.go).
+ * Cada fila completa se envía al flujo de resultados, si lo hay (ver flujo_resultados.go).
+ * param: arreglo de la información de todos los archivos de código fuente, el motor a emplear, los parámetros de ejecución
+ *        (distancias cuadradas, matriz de Gram y precisión de la matriz) y el flujo de resultados (nil si no se usa)
  * return: completa la información en el arreglo de código fuente con la distancia a todos los demás (matriz de similaridad)
  */
-func determinarDistanciasEntreArchivos(tablaCodigoFuente []CodigoFuente) []CodigoFuente {
+func determinarDistanciasEntreArchivos(tablaCodigoFuente []CodigoFuente, motor Motor, parametros Parametros, flujo *FlujoResultados) []CodigoFuente {
 
 	var distanciaTemp float64
 	var i, j int
 
 	cantidadArchivos := len(tablaCodigoFuente)
+	cuadradas := parametros.distanciasCuadradas
+
+	crearTablasDistancias(tablaCodigoFuente, parametros.precisionMatriz == PRECISION_MATRIZ_REDUCIDA)
+
+	if parametros.modoPares == PARES_LSH {
+		determinarDistanciasCandidatas(tablaCodigoFuente, motor.distancia, parametros, flujo)
+		return tablaCodigoFuente
+	}
+
+	distancia := motor.distancia
+	if motorCuadratico, ok := motor.(MotorCuadratico); ok && cuadradas {
+		distancia = motorCuadratico.distanciaCuadrada
+		for i = range tablaCodigoFuente {
+			tablaCodigoFuente[i].distanciasCuadradas = true
+		}
+	}
+
+	if motorDenso, ok := motor.(MotorDenso); ok && parametros.matrizGram {
+		_, cuadratico := motor.(MotorCuadratico)
+		return determinarDistanciasGram(tablaCodigoFuente, motorDenso, cuadradas && cuadratico, flujo)
+	}
+
+	if trabajadores := obtenerCantidadTrabajadores(parametros.trabajadores, cantidadArchivos); trabajadores > 1 {
+		determinarDistanciasParalelas(tablaCodigoFuente, distancia, trabajadores, flujo)
+		return tablaCodigoFuente
+	}
 
 	for i = 0; i < cantidadArchivos; i++ {
 		for j = 0; j <= i; j++ {
-			distanciaTemp = calcularDistancia(tablaCodigoFuente[i], tablaCodigoFuente[j])
-			tablaCodigoFuente[i].tablaDistancias[j] = Distancia{indiceCodigoFuente: j, distancia: distanciaTemp}
-			tablaCodigoFuente[j].tablaDistancias[i] = Distancia{indiceCodigoFuente: i, distancia: distanciaTemp}
+			distanciaTemp = distancia(tablaCodigoFuente[i], tablaCodigoFuente[j])
+			asignarValorDistancia(&tablaCodigoFuente[i], j, distanciaTemp)
+			asignarValorDistancia(&tablaCodigoFuente[j], i, distanciaTemp)
+		}
+
+		if flujo != nil {
+			flujo.enviarFila(tablaCodigoFuente, i)
 		}
 	}
 
@@ -209,44 +971,133 @@ func determinarDistanciasEntreArchivos(tablaCodigoFuente []CodigoFuente) []Codig
 }
 
 /*
- * Función para imprimir los grupos de trabajo que se encuentran a una distancia máxima.
+ * Función para obtener la distancia entre un archivo y el archivo con el índice indicado.
+ * param: información del archivo e índice del otro archivo
+ * return: distancia entre ambos archivos (en la escala original, también con distancias cuadradas)
+ */
+func obtenerDistancia(codigoFuente CodigoFuente, indice int) float64 {
+	return escalarDistancia(codigoFuente, obtenerValorDistancia(codigoFuente, indice))
+}
+
+/*
+ * Función para obtener el valor almacenado en la tabla de distancias de un archivo para el archivo con el índice indicado
+ * param: información del archivo e índice del otro archivo
+ * return: valor almacenado (la distancia al cuadrado si la tabla es de distancias cuadradas)
+ */
+func obtenerValorDistancia(codigoFuente CodigoFuente, indice int) float64 {
+	if codigoFuente.tablaDistancias32 != nil {
+		return float64(codigoFuente.tablaDistancias32[indice])
+	}
+	return codigoFuente.tablaDistancias[indice].distancia
+}
+
+// Distancias de la tabla de código fuente para la biblioteca (sasc.Distances y sasc.ThresholdDistances)
+type DistanciasTabla []CodigoFuente
+
+func (tabla DistanciasTabla) Len() int {
+	return len(tabla)
+}
+
+func (tabla DistanciasTabla) Distance(i int, j int) float64 {
+	return obtenerDistancia(tabla[i], j)
+}
+
+func (tabla DistanciasTabla) Within(i int, j int, distanciaMaxima float64) bool {
+	return estaADistanciaMaxima(tabla[i], obtenerValorDistancia(tabla[i], j), distanciaMaxima)
+}
+
+/*
+ * Función para convertir un grupo de la biblioteca (pkg/sasc) en un grupo de los reportes
+ * param: grupo de la biblioteca
+ * return: el grupo con los mismos integrantes
+ */
+func convertirGrupo(grupo sasc.Group) Grupo {
+	convertido := Grupo{identificador: grupo.ID, centro: grupo.Medoid, diametro: grupo.Diameter, ejemplar: grupo.Exemplar, distanciaEjemplar: grupo.ExemplarDistance}
+
+	for _, integrante := range grupo.Members {
+		convertido.integrantes = append(convertido.integrantes, IntegranteGrupo{
+			indice:          integrante.Index,
+			distanciaCentro: integrante.DistanceToMedoid,
+			enGrupoAnterior: integrante.InPreviousGroup,
+		})
+	}
+
+	return convertido
+}
+
+/*
+ * Función para determinar los grupos de trabajo que se encuentran a una distancia máxima (ver pkg/sasc).
  * Un programa puede estar en varios grupos, lo que significa que él está a una distancia máxima de varios programas.
+ * El código central de cada grupo es su medoide (no el primer archivo encontrado) y se calcula el diámetro del grupo.
+ * Cada grupo se identifica por el hash del contenido de su medoide, de modo que es estable entre ejecuciones.
+ * La función no modifica la información de los archivos, por lo que se puede llamar varias veces (por ejemplo
+ * con distintas distancias máximas) o de forma concurrente sobre la misma tabla.
  * param: arreglo con la información del código fuente de los archivos y la distancia mínina
+ * return: arreglo con los grupos (solamente los que tienen al menos un integrante nuevo)
  */
-func imprimitGrupos(tablaCodigoFuente []CodigoFuente, distanciaMinima float64) {
-	var nombre, integrantes string
-	var cantidadIntegrantes, cantidadGrupos int
-	var imprimirGrupo bool
-
-	fmt.Println("\nGRUPOS CON SUS MIEMBROS A UNA DISTANCIA MÁXIMA DE", distanciaMinima, "RESPECTO AL CÓDIGO CENTRAL\n")
-
-	cantidadGrupos = 1
-	for _, archivo := range tablaCodigoFuente {
-		integrantes = "GRUPO " + strconv.Itoa(cantidadGrupos) + "\n"
-		cantidadIntegrantes = 0
-		imprimirGrupo = false
-		for _, distanciaArchivo := range archivo.tablaDistancias {
-			if distanciaArchivo.distancia <= distanciaMinima {
-				if tablaCodigoFuente[distanciaArchivo.indiceCodigoFuente].perteneceGrupo == true {
-					nombre = "(*) "
-				} else {
-					imprimirGrupo = true
-					nombre = "    "
-					tablaCodigoFuente[distanciaArchivo.indiceCodigoFuente].perteneceGrupo = true
-				}
-				integrantes += ("\t" + nombre + tablaCodigoFuente[distanciaArchivo.indiceCodigoFuente].nombre)
+func determinarGrupos(tablaCodigoFuente []CodigoFuente, distanciaMinima float64) []Grupo {
+	var grupos []Grupo
 
-				if archivo.nombre == tablaCodigoFuente[distanciaArchivo.indiceCodigoFuente].nombre {
-					integrantes += " <- Código central"
-				}
-				integrantes += "\n"
-				cantidadIntegrantes++
+	for _, grupo := range sasc.FormGroups(DistanciasTabla(tablaCodigoFuente), distanciaMinima, func(i int) []byte {
+		return tablaCodigoFuente[i].contenido
+	}) {
+		grupos = append(grupos, convertirGrupo(grupo))
+	}
+
+	return grupos
+}
+
+/*
+ * Función para obtener los identificadores de los demás grupos a los que pertenece un archivo
+ * param: arreglo con los grupos, índice del archivo y grupo actual
+ * return: identificadores de los otros grupos que incluyen al archivo
+ */
+func obtenerOtrosGrupos(grupos []Grupo, indice int, actual string) []string {
+	var otros []string
+
+	for _, grupo := range grupos {
+		if grupo.identificador == actual {
+			continue
+		}
+		for _, integrante := range grupo.integrantes {
+			if integrante.indice == indice {
+				otros = append(otros, grupo.identificador)
+				break
 			}
 		}
-		if cantidadIntegrantes > 1 && imprimirGrupo == true {
-			fmt.Println(integrantes)
-			cantidadGrupos++
+	}
+
+	return otros
+}
+
+/*
+ * Función para imprimir los grupos de trabajo que se encuentran a una distancia máxima.
+ * param: arreglo con la información del código fuente de los archivos, los grupos y el criterio de la distancia máxima
+ */
+func imprimitGrupos(tablaCodigoFuente []CodigoFuente, grupos []Grupo, criterio CriterioDistancia) {
+	var nombre, integrantes string
+
+	fmt.Println("\nGRUPOS CON SUS MIEMBROS A UNA DISTANCIA MÁXIMA DE", criterio.describir(), "RESPECTO AL CÓDIGO CENTRAL (MEDOIDE)")
+	fmt.Println()
+
+	for _, grupo := range grupos {
+		integrantes = fmt.Sprintf("GRUPO %s (medoide %s, diámetro %.2f)\n", describirNombreGrupo(grupo.identificador, grupo.terminos), tablaCodigoFuente[grupo.centro].nombre, grupo.diametro)
+		integrantes += fmt.Sprintf("\tpar ejemplar: %s - %s (%.2f)\n", tablaCodigoFuente[grupo.ejemplar[0]].nombre, tablaCodigoFuente[grupo.ejemplar[1]].nombre, grupo.distanciaEjemplar)
+		for _, integrante := range grupo.integrantes {
+			if integrante.enGrupoAnterior {
+				nombre = "(*) "
+			} else {
+				nombre = "    "
+			}
+			integrantes += ("\t" + nombre + tablaCodigoFuente[integrante.indice].nombre)
+
+			if integrante.indice == grupo.centro {
+				integrantes += " <- Código central"
+			}
+			integrantes += "\n"
 		}
+
+		fmt.Println(integrantes)
 	}
 	fmt.Println()
 }
@@ -254,23 +1105,27 @@ func imprimitGrupos(tablaCodigoFuente []CodigoFuente, distanciaMinima float64) {
 /*
  * Función para imprimir las distancias de cada archivo a todos los demás
  * usando el filtro de la distancia máxima
- * param: arreglo con la información del código fuente de los archivos y la distancia mínina
+ * param: resultado del análisis (con la distribución de las distancias si se anotan los percentiles),
+ *        las bandas de severidad (vacías si no se clasifican) y la vista previa del código fuente de los pares
  */
-func imprimirDistancias(tablaCodigoFuente []CodigoFuente, distanciaMinima float64) {
-	fmt.Println("\nDISTANCIAS\n")
+func imprimirDistancias(resultado ResultadoAnalisis, bandas []BandaSeveridad, vista VistaFuente) {
+	fmt.Print("\nDISTANCIAS\n\n")
 
-	for _, archivo := range tablaCodigoFuente {
-		// Ordena las distancias de forma ascendente
-		sort.Slice(archivo.tablaDistancias, func(j, k int) bool {
-			return archivo.tablaDistancias[j].distancia < archivo.tablaDistancias[k].distancia
-		})
+	tablaCodigoFuente, distanciaMinima, distribucion := resultado.archivos, resultado.criterio.limite(), resultado.distribucion
+	colores := consolaConColores()
 
+	for i, archivo := range tablaCodigoFuente {
 		fmt.Println(archivo.nombre)
 
-		for _, distanciaArchivo := range archivo.tablaDistancias { // Se recorre toda la matriz para imprimir todas las distancias
-			if distanciaArchivo.distancia <= distanciaMinima {
-				if archivo.nombre != tablaCodigoFuente[distanciaArchivo.indiceCodigoFuente].nombre {
-					fmt.Printf("\t%8.2f %s\n", distanciaArchivo.distancia, tablaCodigoFuente[distanciaArchivo.indiceCodigoFuente].nombre)
+		for _, j := range ordenarIndicesPorDistancia(resultado.matriz[i]) { // Se recorre toda la fila, de forma ascendente, para imprimir todas las distancias
+			if distancia := resultado.matriz[i][j]; distancia <= distanciaMinima {
+				if archivo.nombre != tablaCodigoFuente[j].nombre {
+					fmt.Printf("\t%8.2f%s%s %s\n", distancia, anotarPercentil(distribucion, distancia),
+						etiquetarSeveridad(bandas, distancia, colores), tablaCodigoFuente[j].nombre)
+
+					if resultado.tieneDistanciaMaxima() {
+						vista.imprimir(tablaCodigoFuente, i, j)
+					}
 				}
 			}
 		}
@@ -279,29 +1134,90 @@ func imprimirDistancias(tablaCodigoFuente []CodigoFuente, distanciaMinima float6
 }
 
 /*
- * Función para guarda en un archivo CSV las distancias de cada archivo a todos los demás
- * param: arreglo con la información del código fuente de los archivos y nombre CSV
+ * Función para obtener los archivos que se incluyen en el archivo CSV.
+ * Con el filtro "omit" solamente se incluyen los archivos que tienen otro archivo a una distancia máxima.
+ * param: resultado del análisis y el filtro
+ * return: índices de los archivos a incluir, en orden
+ */
+func obtenerArchivosCSV(resultado ResultadoAnalisis, filtroCSV string) []int {
+	var indices []int
+
+	for i, distancias := range resultado.matriz {
+		incluir := filtroCSV != "omit"
+
+		for j := 0; j < len(distancias) && !incluir; j++ {
+			incluir = j != i && distancias[j] <= resultado.criterio.limite()
+		}
+
+		if incluir {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+/*
+ * Función para formatear una distancia del archivo CSV
+ * param: distancia y cantidad de decimales (-1 para la precisión completa, el menor texto que conserva el valor exacto)
+ * return: distancia formateada
  */
-func generarArchivoCSV(tablaCodigoFuente []CodigoFuente, nombreTablaCSV string) {
-	var nombre string
+func formatearDistanciaCSV(distancia float64, precision int) string {
+	if precision < 0 {
+		return strconv.FormatFloat(distancia, 'g', -1, 64)
+	}
+
+	return fmt.Sprintf("%8.*f", precision, distancia)
+}
 
+/*
+ * Función para guarda en un archivo CSV las distancias de cada archivo a todos los demás.
+ * Antes de las distancias se incluye el tamaño, el lenguaje, el estudiante y los grupos de cada archivo,
+ * y su mayor severidad si se definieron bandas de severidad.
+ * Con los filtros "blank" y "omit" las celdas por encima de la distancia máxima quedan vacías,
+ * lo que produce una matriz con solamente los pares sospechosos.
+ * param: resultado del análisis, nombre CSV, el filtro, las bandas de severidad (vacías si no se clasifican)
+ *        y la cantidad de decimales de las distancias
+ */
+func generarArchivoCSV(resultado ResultadoAnalisis, nombreTablaCSV string, filtroCSV string, bandas []BandaSeveridad, precision int) {
 	ptrArchivo, err := os.Create(nombreTablaCSV)
 
 	if err != nil {
 		panic(err)
 	}
+	defer ptrArchivo.Close()
+
+	tablaCodigoFuente := resultado.archivos
+	indices := obtenerArchivosCSV(resultado, filtroCSV)
 
-	fmt.Fprintf(ptrArchivo, "CÓDIGO FUENTE\t%s", nombre)
-	for _, archivo := range tablaCodigoFuente {
-		fmt.Fprintf(ptrArchivo, "\t%s", archivo.nombre)
+	fmt.Fprintf(ptrArchivo, "CÓDIGO FUENTE\tTAMAÑO (BYTES)\tLENGUAJE\tESTUDIANTE\tGRUPOS")
+	if len(bandas) > 0 {
+		fmt.Fprintf(ptrArchivo, "\tSEVERIDAD")
+	}
+	for _, indice := range indices {
+		fmt.Fprintf(ptrArchivo, "\t%s", tablaCodigoFuente[indice].nombre)
 	}
 	fmt.Fprintf(ptrArchivo, "\n")
 
-	for _, archivo := range tablaCodigoFuente { // Se genera toda la matriz simétrica, en lugar de generar únicamente la mitad de ella.
-		fmt.Fprintf(ptrArchivo, "%s\t", archivo.nombre)
+	for _, i := range indices { // Se genera toda la matriz simétrica, en lugar de generar únicamente la mitad de ella.
+		fmt.Fprintf(ptrArchivo, "%s\t%d\t%s\t%s\t%s",
+			tablaCodigoFuente[i].nombre,
+			tablaCodigoFuente[i].tamano,
+			obtenerLenguaje(tablaCodigoFuente[i].nombre),
+			obtenerEstudiante(".", tablaCodigoFuente[i].ruta),
+			strings.Join(obtenerOtrosGrupos(resultado.grupos, i, ""), ";"))
+		if len(bandas) > 0 {
+			fmt.Fprintf(ptrArchivo, "\t%s", obtenerSeveridadMaxima(resultado, i, bandas))
+		}
 
-		for _, distanciaArchivo := range archivo.tablaDistancias {
-			fmt.Fprintf(ptrArchivo, "\t%8.2f", distanciaArchivo.distancia)
+		for _, j := range indices {
+			distancia := resultado.matriz[i][j]
+
+			if (filtroCSV != "none" && distancia > resultado.criterio.limite()) || distancia == DISTANCIA_NO_CALCULADA {
+				fmt.Fprintf(ptrArchivo, "\t")
+			} else {
+				fmt.Fprintf(ptrArchivo, "\t%s", formatearDistanciaCSV(distancia, precision))
+			}
 		}
 		fmt.Fprintf(ptrArchivo, "\n")
 	}
@@ -311,45 +1227,639 @@ func generarArchivoCSV(tablaCodigoFuente []CodigoFuente, nombreTablaCSV string)
  * Función principal
  */
 func main() {
-	fmt.Println("SISTEMA AUTOMÁTICO DE SIMILARIDAD DE CÓDIGO (SASC)")
-	fmt.Println("Julián Esteban Gutiérrez Posada")
-	fmt.Println("jugutier@uniquindio.edu.co\n")
-	fmt.Println("Versión 2.0 - Licencia GNU - GPL v3")
-	fmt.Println("Agosto de 2021\n")
+	parametros, err := obtenerParametros(os.Args[1:])
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// En los modos de entrada estándar y JSON-RPC la salida estándar contiene únicamente JSON, lo mismo con --output=-
+	if !parametros.entradaEstandar && !parametros.protocoloEditor {
+		reservarSalidaEstandar(&parametros)
+		imprimirPresentacion()
+		fmt.Print("Para más información user ./SASC --help\n\n")
+	}
+
+	if parametros.preajusteGuardado != "" {
+		guardarPreajuste(parametros)
+		if !parametros.entradaEstandar && !parametros.protocoloEditor {
+			fmt.Print("Configuración guardada en el preajuste \"" + parametros.preajusteGuardado + "\"\n\n")
+		}
+	}
+
+	// Los comandos report y db se agregan al registro de auditoría (--audit-log no se admite con cache)
+	if parametros.comando == "cache" || parametros.comando == "db" || parametros.comando == "report" {
+		auditoria, err := abrirAuditoria(parametros.archivoAuditoria)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if parametros.comando == "report" {
+			err = generarReportesGuardados(parametros)
+		} else {
+			err = ejecutarComandoAdministracion(parametros)
+		}
+
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err = auditoria.registrar(nil, crearRegistroComando(parametros)); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if auditoria != nil {
+			fmt.Fprintln(os.Stderr, "Registro de auditoría \""+parametros.archivoAuditoria+"\":", auditoria.describirCabeza())
+		}
+		return
+	}
+
+	extensionPorDefecto := parametros.extension
+
+	motor, err := crearMotor(parametros)
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if parametros.motorEvidencia != "lines" && parametros.motorEvidencia != "rabin-karp" && parametros.motorEvidencia != "suffix-array" && parametros.motorEvidencia != "winnowing" && parametros.motorEvidencia != "gst" {
+		fmt.Printf("Motor de evidencia \"%s\" no soportado (lines | rabin-karp | suffix-array | winnowing | gst)\n", parametros.motorEvidencia)
+		os.Exit(1)
+	}
+
+	if parametros.formatoEvidencia != FORMATO_EVIDENCIA_TEXTO && parametros.formatoEvidencia != FORMATO_EVIDENCIA_EDITOR {
+		fmt.Printf("Formato de evidencia \"%s\" no soportado (text | editor)\n", parametros.formatoEvidencia)
+		os.Exit(1)
+	}
+
+	preprocesamiento, err := crearPreprocesamiento(parametros)
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	redaccion, err := crearRedaccion(parametros)
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if parametros.filtroCSV != "none" && parametros.filtroCSV != "blank" && parametros.filtroCSV != "omit" {
+		fmt.Printf("Filtro del archivo CSV \"%s\" no soportado (none | blank | omit)\n", parametros.filtroCSV)
+		os.Exit(1)
+	}
+
+	if parametros.presupuestoPar < 0 || parametros.presupuestoTotal < 0 {
+		fmt.Println("El presupuesto de tiempo de la evidencia (--evidence-pair-timeout y --evidence-timeout) no puede ser negativo")
+		os.Exit(1)
+	}
+
+	if parametros.minimoTokens < 1 || parametros.minimoLineas < 1 {
+		fmt.Println("La longitud mínima de una coincidencia (--min-match-tokens y --min-match-lines) debe ser al menos 1")
+		os.Exit(1)
+	}
+
+	if parametros.ventanaWinnowing < 1 {
+		fmt.Println("La ventana de winnowing (--winnow-window) debe ser al menos 1")
+		os.Exit(1)
+	}
+
+	if _, err = crearLimitesExtraccion(parametros); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	destinos, err := crearDestinosSalida(parametros.salidas)
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if parametros.minimoLineasTardia < 1 {
+		fmt.Println("La cantidad mínima de líneas de una copia tardía (--late-copy-lines) debe ser al menos 1")
+		os.Exit(1)
+	}
+
+	if parametros.precisionMatriz != PRECISION_MATRIZ_COMPLETA && parametros.precisionMatriz != PRECISION_MATRIZ_REDUCIDA {
+		fmt.Printf("Precisión de la matriz \"%s\" no soportada (float64 | float32)\n", parametros.precisionMatriz)
+		os.Exit(1)
+	}
+
+	if parametros.trabajadores < 0 {
+		fmt.Println("La cantidad de trabajadores (--workers) no puede ser negativa")
+		os.Exit(1)
+	}
+
+	if err = configurarNucleoDistancia(parametros.nucleoDistancia); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if parametros.direccionPerfilado != "" {
+		direccion, err := iniciarPerfilado(parametros.direccionPerfilado)
+
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintln(os.Stderr, "Perfiles (pprof) en http://"+direccion+"/debug/pprof/")
+	}
 
-	fmt.Println("Para más información user ./SASC --help\n")
+	if parametros.estadisticasMemoria {
+		if parametros.intervaloMemoria <= 0 {
+			fmt.Println("El intervalo del registro de memoria (--memstats-interval) debe ser mayor que 0")
+			os.Exit(1)
+		}
+
+		defer iniciarEstadisticasMemoria(os.Stderr, parametros.intervaloMemoria)()
+	}
+
+	// Las rutas de la evidencia en formato de editor son relativas al directorio de ejecución
+	directorioInicial, _ := os.Getwd()
+
+	if parametros.directorioAnalisis != "" {
+		if err = os.Chdir(parametros.directorioAnalisis); err != nil {
+			fmt.Println("No se puede analizar el directorio (--dir):", err)
+			os.Exit(1)
+		}
+	}
+
+	if parametros.comando == "classroom" {
+		fmt.Println("Clonando los repositorios de la tarea \"" + parametros.tareaClassroom + "\" de GitHub Classroom...")
+		directorio, clonados, err := clonarRepositoriosClassroom(parametros)
 
-	extensionPorDefecto, distanciaMinima, nombreTablaCSV := obtenerValorPorDefecto()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Print(clonados, " repositorios en \"", directorio, "\"\n\n")
+
+		// El análisis se realiza sobre los repositorios clonados
+		if err = os.Chdir(directorio); err != nil {
+			panic(err)
+		}
+	}
+
+	if parametros.tareaCanvas != "" {
+		directorio, estudiantes, err := descargarEntregasCanvas(parametros)
+
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Print("Entregas de Canvas de ", estudiantes, " estudiantes descargadas en \"", directorio, "\"\n\n")
+
+		// El análisis se realiza sobre las entregas descargadas
+		if err = os.Chdir(directorio); err != nil {
+			panic(err)
+		}
+	}
 
 	directorioActual, _ := os.Getwd()
 
-	listado, err := obtenerListado(directorioActual, extensionPorDefecto)
+	directorioBase := directorioActual
+	if parametros.entradaEstandar || parametros.direccionServidor != "" {
+		directorioBase = "stdin" // Etiqueta por defecto de las rutas del flujo tar
+	}
+
+	formatoRutas, err := crearFormatoRutas(parametros, directorioBase)
 
 	if err != nil {
-		panic("Error al obtener el listado de los programas.")
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
-	fmt.Println("Procesando", len(listado), "archivo de extensión ."+extensionPorDefecto+" en", directorioActual, "\n")
+	if parametros.entradaEstandar {
+		if err = analizarEntradaEstandar(parametros, motor, preprocesamiento, formatoRutas, destinos); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	fmt.Println("Fase 1 de 3: Calculando características de cada archivo...")
-	tablaCodigoFuente := determinarCaracteristicas(listado)
+	if parametros.protocoloEditor {
+		if err = atenderProtocoloEditor(parametros, motor, preprocesamiento, formatoRutas); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	fmt.Println("Fase 2 de 3: Calculando distancia entre los archivos...")
-	tablaCodigoFuente = determinarDistanciasEntreArchivos(tablaCodigoFuente)
+	if parametros.direccionServidor != "" && parametros.corpusVerificacion != "" {
+		fmt.Println("Servidor de autoverificación en", parametros.direccionServidor, "(POST /v1/self-check, GET /metrics)")
+	} else if parametros.direccionServidor != "" {
+		fmt.Println("Servidor en", parametros.direccionServidor, "(POST /analyze, POST /jobs, GET /jobs/{id}, POST /v1/similarity, GET /metrics)")
+	}
+	if parametros.direccionServidor != "" {
+		if err = iniciarServidor(parametros, motor, preprocesamiento, formatoRutas); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	if nombreTablaCSV != "" {
-		fmt.Println("Fase 3 de 3: Generando el archivo \"" + nombreTablaCSV + "\"")
-		generarArchivoCSV(tablaCodigoFuente, nombreTablaCSV)
+	if parametros.comando == "robustness" {
+		if err = evaluarRobustez(parametros, preprocesamiento, formatoRutas, directorioActual); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if parametros.comando == "calibrate" {
+		if err = calibrarDistanciaMaxima(parametros, motor, preprocesamiento, formatoRutas, directorioActual); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if parametros.comando == "simhash" {
+		if err = ejecutarComandoSimHash(parametros, preprocesamiento, formatoRutas, directorioActual); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if parametros.comando == "dashboard" {
+		if err = generarTableroTarea(parametros, motor, preprocesamiento); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if parametros.reporteCorpus {
+		composicion, err := calcularComposicionCorpus(directorioActual, extensionPorDefecto, limites)
+
+		if err != nil {
+			fmt.Println("Error al calcular la composición del corpus:", err)
+			os.Exit(1)
+		}
+
+		imprimirComposicionCorpus(composicion, extensionPorDefecto)
+	}
+
+	listado, archivosZip, omitidos, err := obtenerListadoEntregas(directorioActual, extensionPorDefecto, limites)
+
+	if err != nil {
+		fmt.Println("Error al obtener el listado de los programas:", err)
+		os.Exit(1)
+	}
+	preprocesamiento.archivosZip = archivosZip
+	imprimirZipOmitidos(omitidos, formatoRutas)
+
+	if parametros.directorioPlantilla != "" {
+		listado = excluirArchivosPlantilla(listado, directorioActual, parametros.directorioPlantilla)
+	}
+
+	if directorios := formatoRutas.lista.obtenerDirectoriosSinEstudiante(listado); len(directorios) > 0 {
+		fmt.Println("Directorios sin estudiante en la lista (--students), se presentan con su nombre:")
+		for _, directorio := range directorios {
+			fmt.Println("\t" + directorio)
+		}
+		fmt.Println()
+	}
+
+	if len(parametros.subtareas) > 0 {
+		analizarSubtareas(listado, parametros, formatoRutas, func(listado []string, parametros Parametros) ResultadoAnalisis {
+			// Las subtareas no se combinan con --output ni --hook, no hay envíos ni ganchos que fallen
+			resultado, _ := analizarListado(listado, omitidos, parametros, motor, preprocesamiento, formatoRutas, redaccion, destinos, directorioInicial, directorioActual)
+			return resultado
+		})
+		return
+	}
+
+	if _, err = analizarListado(listado, omitidos, parametros, motor, preprocesamiento, formatoRutas, redaccion, destinos, directorioInicial, directorioActual); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+/*
+ * Función para analizar un listado de archivos y generar los reportes solicitados (fases 1 a 3)
+ * param: listado de archivos, .zip omitidos, parámetros de ejecución, motor de características, preprocesamiento
+ *        (con el contenido de los .zip del listado), formato de las rutas, ocultamiento de los datos personales,
+ *        destinos de salida, directorio de ejecución y directorio base
+ * return: el resultado del análisis y un error si el envío a algún destino de salida o algún gancho falló (los
+ *         reportes se generan de todos modos)
+ */
+func analizarListado(listado []string, omitidos []ZipOmitido, parametros Parametros, motor Motor, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas, redaccion *Redaccion, destinos []DestinoSalida, directorioInicial string, directorioActual string) (ResultadoAnalisis, error) {
+	extensionPorDefecto, nombreTablaCSV := parametros.extension, parametros.nombreTablaCSV
+
+	var descartados []string
+	listadoCompleto := listado
+
+	if parametros.minimoContenido > 0 {
+		listado, descartados = filtrarArchivosSinContenido(listado, extensionPorDefecto, parametros.minimoContenido, preprocesamiento)
+
+		if len(descartados) > 0 {
+			fmt.Println("Archivos descartados por tener menos de", parametros.minimoContenido, "caracteres sin espacios ni comentarios:")
+			for _, archivo := range descartados {
+				fmt.Println("\t" + formatoRutas.formatear(archivo))
+			}
+			fmt.Println()
+		}
+	}
+
+	var validez []ValidezArchivo
+	if parametros.reporteValidez != "" || len(destinos) > 0 || len(parametros.ganchos) > 0 || parametros.directorioPaquete != "" {
+		validez = evaluarValidezListado(listadoCompleto, descartados, omitidos, extensionPorDefecto, formatoRutas, preprocesamiento.archivosZip)
+	}
+
+	if parametros.reporteValidez != "" {
+		fmt.Println("Generando el reporte de validez \""+parametros.reporteValidez+"\":", contarProblemasValidez(validez), "de", len(validez), "archivos binarios, con errores de sintaxis o descartados")
+		fmt.Println()
+		generarReporteValidez(validez, parametros.reporteValidez)
+	}
+
+	var alias map[string][]string
+	listadoSinAgrupar := listado
+	if parametros.deduplicar {
+		cantidad := len(listado)
+		if listado, alias = agruparArchivosIdenticos(listado, preprocesamiento.archivosZip); len(listado) < cantidad {
+			fmt.Println("Archivos idénticos agrupados:", cantidad, "archivos con", len(listado), "contenidos distintos")
+			fmt.Println()
+		}
+	}
+
+	fmt.Println("Procesando", len(listado), "archivo de extensión ."+extensionPorDefecto+" en", directorioActual)
+	fmt.Println("Motor de características:", motor.nombre())
+	fmt.Println()
+
+	var tablaCodigoFuente []CodigoFuente
+	var huella string
+	var proyeccion *Proyeccion
+	var resultadosCache ResultadosCache
+	enCache := false
+
+	cifrado, err := crearCifrado(parametros.archivoCifrado)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if parametros.directorioCache != "" {
+		huella = calcularHuellaCorpus(listado, motor, parametros, preprocesamiento.archivosZip)
+		resultadosCache, enCache = cargarResultadosCache(parametros.directorioCache, huella, listado, cifrado)
+	}
+
+	var flujo *FlujoResultados
+	if parametros.rutaFlujo != "" {
+		if flujo, err = iniciarFlujoResultados(parametros.rutaFlujo, len(listado), motor.nombre(), parametros.criterioDistancia); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	if enCache {
+		fmt.Println("Fase 1 de 3: Leyendo los archivos (corpus sin cambios, huella " + huella[:LONGITUD_IDENTIFICADOR_GRUPO] + ")...")
+		fmt.Println("Fase 2 de 3: Cargando las distancias desde la caché...")
+		tablaCodigoFuente = cargarCodigoFuenteCache(listado, preprocesamiento, formatoRutas, resultadosCache, parametros.precisionMatriz == PRECISION_MATRIZ_REDUCIDA)
+
+		for i := 0; i < len(tablaCodigoFuente) && flujo != nil; i++ {
+			flujo.enviarFila(tablaCodigoFuente, i)
+		}
 	} else {
-		fmt.Println("Fase 3 de 3: Imprimiendo distancia entre archivos de forma creciente...")
-		if distanciaMinima < math.MaxFloat64 {
-			fmt.Println("             incluye listado de grupos por definir una distancia máxima.")
-			imprimitGrupos(tablaCodigoFuente, distanciaMinima)
-			fmt.Println(" (*) Este código pertence a otros grupos")
-		} else {
-			fmt.Println("             NO incluye grupos por no definir una distancia máxima")
+		fmt.Println("Fase 1 de 3: Calculando características de cada archivo...")
+		tablaCodigoFuente = determinarCaracteristicas(listado, motor, preprocesamiento, formatoRutas, parametros.trabajadores)
+
+		if proyeccion = proyectarCorpus(tablaCodigoFuente, motor); proyeccion != nil {
+			fmt.Println("             proyección:", describirProyeccion(proyeccion))
+		}
+
+		fmt.Println("Fase 2 de 3: Calculando distancia entre los archivos...")
+		if trabajadores := obtenerCantidadTrabajadores(parametros.trabajadores, len(tablaCodigoFuente)); trabajadores > 1 && !parametros.matrizGram {
+			fmt.Println("             con", trabajadores, "trabajadores")
+		}
+		tablaCodigoFuente = determinarDistanciasEntreArchivos(tablaCodigoFuente, motor, parametros, flujo)
+		if parametros.modoPares == PARES_LSH {
+			calculados, total := contarParesCalculados(tablaCodigoFuente)
+			fmt.Println("             modo aproximado (MinHash y LSH):", calculados, "de", total, "pares candidatos con la distancia calculada")
+		}
+
+		if parametros.directorioCache != "" {
+			guardarResultadosCache(parametros.directorioCache, huella, tablaCodigoFuente, cifrado)
+		}
+	}
+
+	tablaCodigoFuente = expandirArchivosIdenticos(tablaCodigoFuente, listadoSinAgrupar, alias, formatoRutas)
+
+	if flujo != nil {
+		if err = flujo.terminar(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Println("Fase 3 de 3: Generando los reportes solicitados...")
+
+	var nombresDescartados []string
+	for _, archivo := range descartados {
+		nombresDescartados = append(nombresDescartados, formatoRutas.formatear(archivo))
+	}
+
+	resultado := construirResultadoAnalisis(tablaCodigoFuente, parametros, motor, nombresDescartados, validez, proyeccion)
+	grupos, distanciaMinima := resultado.grupos, resultado.criterio.limite()
+	fmt.Println("             distancia máxima:", resultado.criterio.describir())
+
+	if resultado.tieneDistanciaMaxima() {
+		if parametros.distanciaFusion >= 0 {
+			fmt.Println("             fusionando los grupos con medoides a una distancia máxima de", parametros.distanciaFusion, "("+strconv.Itoa(resultado.gruposAntesFusion), "grupos a", strconv.Itoa(len(grupos))+")")
+		}
+
+		if parametros.nombreGruposCSV != "" {
+			fmt.Println("             generando el archivo \"" + parametros.nombreGruposCSV + "\" con los grupos")
+			generarGruposCSV(resultado.archivos, grupos, parametros.nombreGruposCSV)
+		}
+		if parametros.nombreGruposJSON != "" {
+			fmt.Println("             generando el archivo \"" + parametros.nombreGruposJSON + "\" con los grupos")
+			generarGruposJSON(resultado.archivos, grupos, resultado.criterio, parametros.nombreGruposJSON)
+		}
+		if parametros.directorioCartas != "" {
+			cartas, err := generarCartasNotificacion(resultado, formatoRutas.lista, parametros)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Println("             generadas", cartas, "cartas de notificación en \""+parametros.directorioCartas+"\"")
+		}
+	} else if (parametros.nombreGruposCSV != "" || parametros.nombreGruposJSON != "" || parametros.directorioCartas != "") && len(parametros.umbrales) == 0 {
+		fmt.Println("             los archivos de grupos y las cartas NO se generan por no definir una distancia máxima")
+	}
+
+	if len(resultado.niveles) > 0 {
+		generarArchivosNiveles(resultado, parametros.nombreGruposCSV, parametros.nombreGruposJSON)
+	}
+
+	if nombreTablaCSV != "" {
+		fmt.Println("             generando el archivo \"" + nombreTablaCSV + "\"")
+		if parametros.filtroCSV != "none" && !resultado.tieneDistanciaMaxima() {
+			fmt.Println("             el archivo CSV se genera completo por no definir una distancia máxima")
+		}
+		generarArchivoCSV(resultado, nombreTablaCSV, parametros.filtroCSV, parametros.bandasSeveridad, parametros.precisionCSV)
+	}
+
+	var colaRevision []ParRevision
+	if parametros.presupuestoRevision > 0 {
+		colaRevision = construirColaRevision(resultado, parametros.presupuestoRevision, parametros.semillaRevision)
+		repartirColaRevision(resultado.grupos, colaRevision, parametros.asistentesRevision)
+
+		if parametros.nombreRevisionCSV != "" {
+			fmt.Println("             generando el archivo \"" + parametros.nombreRevisionCSV + "\" con la cola de revisión")
+			generarColaRevisionCSV(resultado, colaRevision, parametros.bandasSeveridad, parametros.nombreRevisionCSV)
+		}
+		if parametros.nombreRevisionHTML != "" {
+			fmt.Println("             generando el archivo \"" + parametros.nombreRevisionHTML + "\" con la cola de revisión")
+			generarColaRevisionHTML(resultado, colaRevision, parametros.bandasSeveridad, parametros.marcaReporte, parametros.reporteAccesible, parametros.nombreRevisionHTML)
+		}
+
+		for _, asistente := range parametros.asistentesRevision {
+			pares := obtenerParesAsistente(colaRevision, asistente)
+
+			if parametros.nombreRevisionCSV != "" {
+				generarColaRevisionCSV(resultado, pares, parametros.bandasSeveridad, obtenerNombreArchivoAsistente(parametros.nombreRevisionCSV, asistente))
+			}
+			if parametros.nombreRevisionHTML != "" {
+				generarColaRevisionHTML(resultado, pares, parametros.bandasSeveridad, parametros.marcaReporte, parametros.reporteAccesible, obtenerNombreArchivoAsistente(parametros.nombreRevisionHTML, asistente))
+			}
+		}
+		if len(parametros.asistentesRevision) > 0 && (parametros.nombreRevisionCSV != "" || parametros.nombreRevisionHTML != "") {
+			fmt.Println("             generando una hoja de trabajo por monitor (" + strings.Join(parametros.asistentesRevision, ", ") + ")")
 		}
+	}
+
+	// Los destinos de salida y los ganchos que fallan no detienen los reportes, el error se devuelve al final
+	var fallas []string
+	if len(destinos) > 0 || len(parametros.ganchos) > 0 || parametros.directorioPaquete != "" {
+		resultadoJSON := construirResultadoJSON(resultado, parametros)
+
+		if parametros.directorioPaquete != "" {
+			redaccionPaquete := redaccion
+			if parametros.conservarDatosPaquete {
+				redaccionPaquete = nil
+			}
+			paginas := generarPaquete(resultado, resultadoJSON, parametros.directorioPaquete, parametros.bandasSeveridad, parametros.marcaReporte, parametros.reporteAccesible, parametros.minimoLineas, redaccionPaquete)
+			fmt.Println("             reporte estático generado en \""+parametros.directorioPaquete+"\" con", paginas, "páginas de pares")
+		}
+
+		if len(destinos) > 0 {
+			fmt.Println("             enviando el resultado JSON a", len(destinos), "destinos de salida")
+			if fallidos := enviarResultado(resultadoJSON, destinos); fallidos > 0 {
+				fallas = append(fallas, fmt.Sprintf("No se pudo enviar el resultado a %d destinos de salida (--output)", fallidos))
+			}
+		}
+		if len(parametros.ganchos) > 0 {
+			fmt.Println("             ejecutando", len(parametros.ganchos), "ganchos con el resultado JSON")
+			if fallidos := ejecutarGanchos(resultadoJSON, parametros.ganchos, os.Stdout); fallidos > 0 {
+				fallas = append(fallas, fmt.Sprintf("Fallaron %d ganchos (--hook)", fallidos))
+			}
+		}
+	}
+
+	if !parametros.consola {
+		return resultado, crearErrorPublicacion(fallas)
+	}
+
+	fmt.Println("             imprimiendo distancia entre archivos de forma creciente...")
+	if resultado.tieneDistanciaMaxima() {
+		fmt.Println("             incluye listado de grupos por definir una distancia máxima.")
+		imprimitGrupos(tablaCodigoFuente, grupos, resultado.criterio)
+		fmt.Println(" (*) Este código pertence a otros grupos")
+	} else if len(resultado.niveles) == 0 {
+		fmt.Println("             NO incluye grupos por no definir una distancia máxima")
+	}
 
-		imprimirDistancias(tablaCodigoFuente, distanciaMinima)
+	if len(resultado.niveles) > 0 {
+		fmt.Println("             incluye los grupos de cada nivel de --thresholds.")
+		imprimirNiveles(resultado)
+		fmt.Println(" (*) Este código pertence a otros grupos")
 	}
-}
\ No newline at end of file
+
+	if parametros.deduplicar {
+		imprimirArchivosIdenticos(tablaCodigoFuente)
+	}
+
+	if parametros.distanciasGrupos {
+		imprimirDistanciasGrupos(resultado)
+	}
+
+	if parametros.resumenDirectorios {
+		imprimirResumenDirectorios(resultado)
+	}
+
+	if parametros.puntajeEstudiantes != "" {
+		imprimirPuntajesEstudiantes(resultado, parametros.puntajeEstudiantes, parametros.pesosArchivos)
+	}
+
+	var paresFragmentos []ParFragmentos
+	if parametros.cobertura || (parametros.evidencia && parametros.motorEvidencia != "lines") {
+		presupuesto := iniciarPresupuestoEvidencia(parametros.presupuestoPar, parametros.presupuestoTotal)
+		paresFragmentos = compararParesPorFragmentos(tablaCodigoFuente, distanciaMinima, parametros.motorEvidencia, parametros.minimoTokens, parametros.minimoLineas, parametros.ventanaWinnowing, presupuesto)
+
+		if sinEvidencia := contarParesSinEvidencia(paresFragmentos); sinEvidencia > 0 {
+			fmt.Printf("\nPresupuesto de tiempo de la evidencia agotado: %d de %d pares se reportan solo con su distancia\n", sinEvidencia, len(paresFragmentos))
+		}
+	}
+
+	if parametros.evidencia {
+		editor := parametros.formatoEvidencia == FORMATO_EVIDENCIA_EDITOR
+
+		switch {
+		case parametros.motorEvidencia != "lines" && editor:
+			imprimirEvidenciaFragmentosEditor(tablaCodigoFuente, paresFragmentos, directorioInicial, directorioActual)
+		case parametros.motorEvidencia != "lines":
+			imprimirEvidenciaFragmentos(tablaCodigoFuente, paresFragmentos, parametros.minimoTokens, parametros.minimoLineas)
+		case editor:
+			imprimirEvidenciaLineasEditor(tablaCodigoFuente, distanciaMinima, parametros.minimoLineas, directorioInicial, directorioActual)
+		default:
+			imprimirEvidenciaLineas(tablaCodigoFuente, distanciaMinima, parametros.minimoLineas)
+		}
+	}
+
+	if parametros.cobertura && parametros.motorEvidencia == "gst" {
+		imprimirSimilitudBaldosas(tablaCodigoFuente, paresFragmentos)
+	} else if parametros.cobertura {
+		imprimirCobertura(tablaCodigoFuente, paresFragmentos)
+	}
+
+	if len(parametros.reglas) > 0 {
+		marcados, err := evaluarReglas(resultado, parametros.reglas)
+		imprimirReglas(parametros.reglas, marcados)
+		if err != nil {
+			fmt.Print(err, "\n\n")
+		}
+	}
+
+	if parametros.presupuestoRevision > 0 {
+		imprimirColaRevision(resultado, colaRevision, parametros.bandasSeveridad)
+	}
+
+	if parametros.copiaTardia {
+		imprimirCopiasTardias(tablaCodigoFuente, distanciaMinima, parametros.minimoLineasTardia)
+	}
+
+	if parametros.lineaTiempo {
+		imprimirLineaTiempo(tablaCodigoFuente, distanciaMinima, motor, preprocesamiento)
+	}
+
+	imprimirDistancias(resultado, parametros.bandasSeveridad, crearVistaFuente(tablaCodigoFuente, parametros.lineasFuente, parametros.minimoLineas, redaccion))
+
+	return resultado, crearErrorPublicacion(fallas)
+}
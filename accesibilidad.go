@@ -0,0 +1,105 @@
+/*
+ * Variante accesible de los reportes HTML (--accessible).
+ *
+ * Los reportes HTML (--bundle y --review-html) se generan con alto contraste y pensados para lectores de pantalla:
+ * - tablas con título (caption), encabezados de columna y de fila (scope) y cuerpo separado del encabezado
+ * - etiquetas ARIA en el selector de idioma, las casillas de verificación, las observaciones y el código de cada archivo
+ * - ninguna información depende solo del color: las líneas coincidentes llevan una marca visible ("=") y un texto
+ *   para lectores de pantalla, y la severidad se muestra como texto con borde (sin fondo de color)
+ * - colores de alto contraste, enlaces subrayados y foco visible al navegar con el teclado
+ */
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Hoja de estilos de alto contraste (se agrega a la hoja de estilos de los reportes)
+const ESTILO_ACCESIBLE = `body { color: #000; background: #fff; font-size: 1.1em; line-height: 1.5; }
+a { color: #0000ee; text-decoration: underline; }
+:focus { outline: 3px solid #000; outline-offset: 2px; }
+th, td { border: 1px solid #000; }
+caption { text-align: left; font-weight: bold; }
+pre span.coincide { background: #ffff00; color: #000; font-weight: bold; }
+pre span b { display: inline-block; width: 1.5em; }
+.severidad { border: 2px solid #000; padding: 0 6px; font-weight: bold; }
+.oculto { position: absolute; width: 1px; height: 1px; overflow: hidden; clip: rect(0 0 0 0); white-space: nowrap; }
+`
+
+/*
+ * Función para iniciar una tabla de un reporte HTML
+ * param: si el reporte es accesible, título de la tabla (solo para la variante accesible) y encabezados de las columnas
+ * return: el inicio de la tabla con la fila de encabezados
+ */
+func iniciarTablaReporte(accesible bool, titulo string, columnas ...string) string {
+	if !accesible {
+		return "<table><tr><th>" + strings.Join(columnas, "</th><th>") + "</th></tr>\n"
+	}
+
+	return fmt.Sprintf("<table><caption>%s</caption><thead><tr><th scope=\"col\">%s</th></tr></thead><tbody>\n",
+		titulo, strings.Join(columnas, "</th><th scope=\"col\">"))
+}
+
+/*
+ * Función para terminar una tabla de un reporte HTML
+ * param: si el reporte es accesible
+ * return: el final de la tabla
+ */
+func terminarTablaReporte(accesible bool) string {
+	if !accesible {
+		return "</table>\n"
+	}
+
+	return "</tbody></table>\n"
+}
+
+/*
+ * Función para obtener la primera celda de una fila (el encabezado de la fila en la variante accesible)
+ * param: si el reporte es accesible y contenido de la celda
+ * return: la celda
+ */
+func encabezarFilaReporte(accesible bool, contenido string) string {
+	if !accesible {
+		return "<td>" + contenido + "</td>"
+	}
+
+	return "<th scope=\"row\">" + contenido + "</th>"
+}
+
+/*
+ * Función para obtener la insignia de severidad de un reporte HTML
+ * param: si el reporte es accesible y nivel de severidad (vacío si el par no se clasificó)
+ * return: la insignia de color, o el nivel como texto con borde en la variante accesible
+ */
+func obtenerInsigniaReporte(accesible bool, nivel string) string {
+	if nivel == "" {
+		return ""
+	}
+	if !accesible {
+		return obtenerInsigniaSeveridad(nivel)
+	}
+
+	return fmt.Sprintf("<span class=\"severidad\">%s</span>", html.EscapeString(strings.ToUpper(nivel)))
+}
+
+/*
+ * Función para obtener los atributos de la etiqueta ARIA de un elemento de la variante accesible
+ * param: si el reporte es accesible, clave del texto de la etiqueta y sufijo que no se traduce
+ * return: los atributos (vacío si el reporte no es accesible)
+ */
+func etiquetarReporte(accesible bool, clave string, sufijo string) string {
+	if !accesible {
+		return ""
+	}
+
+	for _, texto := range textosReporte {
+		if texto.clave == clave {
+			return fmt.Sprintf(" aria-label=\"%s\" data-i18n-etiqueta=\"%s\" data-sufijo=\"%s\"", html.EscapeString(texto.espanol+sufijo), clave, html.EscapeString(sufijo))
+		}
+	}
+
+	panic("Texto del reporte sin traducción: " + clave)
+}
@@ -0,0 +1,439 @@
+/*
+ * Comandos de administración de la caché y del directorio de datos.
+ *
+ * La caché de resultados (--cache-dir) y el directorio de datos del modo servidor (--data-dir) crecen con cada
+ * análisis. Para administrar el espacio en disco y las actualizaciones del formato sin borrar los archivos a mano:
+ * - ./SASC cache stats --cache-dir=dir     cantidad, tamaño y antigüedad de las entradas de la caché
+ * - ./SASC cache clear --cache-dir=dir     elimina todas las entradas de la caché (solo los archivos de la caché)
+ * - ./SASC db vacuum --data-dir=dir        aplica la retención (--source-retention y --job-retention), elimina las
+ *                                          entregas sin trabajo (de los trabajos interrumpidos al detener el servidor)
+ *                                          y los archivos temporales de una migración interrumpida
+ * - ./SASC db migrate --data-dir=dir       actualiza los trabajos guardados a la versión actual del formato
+ * - ./SASC db encrypt --data-dir=dir --encryption-key=clave.txt
+ *                                          cifra las entregas y los trabajos guardados que aún no están cifrados
+ *                                          (ver cifrado.go)
+ *
+ * La versión del formato del directorio de datos se guarda en el archivo VERSION. El servidor no inicia con un
+ * directorio de una versión anterior (se debe ejecutar db migrate) ni de una versión posterior (de un SASC más nuevo).
+ * Cada trabajo se reescribe en un archivo temporal que luego reemplaza al original, por lo que una migración
+ * interrumpida no deja trabajos a medio escribir y se puede repetir. Los comandos de db se ejecutan con el servidor detenido.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Versión actual del formato del directorio de datos
+const VERSION_ALMACEN = 1
+
+// Nombre del archivo con la versión del formato del directorio de datos
+const ARCHIVO_VERSION_ALMACEN = "VERSION"
+
+// Extensión de los archivos temporales de la migración
+const EXTENSION_TEMPORAL = ".tmp"
+
+// Nombre de las entradas de la caché (huella SHA-256 en hexadecimal)
+var expresionEntradaCache = regexp.MustCompile(`^[0-9a-f]{64}\.json$`)
+
+// Estructura de una migración del directorio de datos (de la versión de su posición a la siguiente)
+// - descripción de la migración
+// - función que actualiza un trabajo guardado y retorna si lo modificó (nil si los trabajos no cambian, solo se validan)
+type MigracionAlmacen struct {
+	descripcion string
+	migrar      func(trabajo *Trabajo) bool
+}
+
+// Migraciones del directorio de datos, la posición i actualiza de la versión i a la versión i + 1
+var migracionesAlmacen = []MigracionAlmacen{
+	{"registro de la versión del formato (los trabajos guardados no cambian)", nil},
+}
+
+/*
+ * Función para ejecutar un comando de administración
+ * param: parámetros de ejecución (comando, acción y directorios de la caché y de datos)
+ * return: un error si la acción no existe o no se pudo ejecutar
+ */
+func ejecutarComandoAdministracion(parametros Parametros) error {
+	cifrado, err := crearCifrado(parametros.archivoCifrado)
+	if err != nil {
+		return err
+	}
+
+	switch parametros.comando + " " + parametros.accionComando {
+	case "cache stats":
+		return imprimirEstadisticasCache(parametros.directorioCache, cifrado)
+	case "cache clear":
+		return limpiarCache(parametros.directorioCache)
+	case "db vacuum":
+		return compactarAlmacen(parametros.directorioDatos, parametros.retencionFuentes, parametros.retencionTrabajos)
+	case "db migrate":
+		return migrarAlmacen(parametros.directorioDatos, cifrado)
+	case "db encrypt":
+		return cifrarAlmacen(parametros.directorioDatos, cifrado)
+	case "cache ":
+		return fmt.Errorf("Se debe indicar la acción del comando cache (stats | clear)")
+	case "db ":
+		return fmt.Errorf("Se debe indicar la acción del comando db (vacuum | migrate | encrypt)")
+	}
+
+	if parametros.comando == "cache" {
+		return fmt.Errorf("Acción \"%s\" del comando cache no soportada (stats | clear)", parametros.accionComando)
+	}
+	return fmt.Errorf("Acción \"%s\" del comando db no soportada (vacuum | migrate | encrypt)", parametros.accionComando)
+}
+
+/*
+ * Función para obtener las entradas de la caché
+ * param: directorio de la caché
+ * return: información de las entradas o un error si no se indicó el directorio o no se puede leer
+ */
+func obtenerEntradasCache(directorioCache string) ([]os.FileInfo, error) {
+	var entradas []os.FileInfo
+
+	if directorioCache == "" {
+		return entradas, fmt.Errorf("Se debe indicar el directorio de la caché (--cache-dir)")
+	}
+
+	archivos, err := ioutil.ReadDir(directorioCache)
+	if err != nil {
+		return entradas, fmt.Errorf("No se puede leer el directorio de la caché \"%s\": %v", directorioCache, err)
+	}
+
+	for _, archivo := range archivos {
+		if !archivo.IsDir() && expresionEntradaCache.MatchString(archivo.Name()) {
+			entradas = append(entradas, archivo)
+		}
+	}
+
+	return entradas, nil
+}
+
+/*
+ * Función para imprimir la cantidad, el tamaño y la antigüedad de las entradas de la caché
+ * param: directorio de la caché y cifrado (nil si no se cifra, las entradas cifradas se cuentan como no válidas)
+ * return: un error si el directorio no se puede leer
+ */
+func imprimirEstadisticasCache(directorioCache string, cifrado *Cifrado) error {
+	entradas, err := obtenerEntradasCache(directorioCache)
+	if err != nil {
+		return err
+	}
+
+	var tamano int64
+	archivos, invalidas := 0, 0
+	var antigua, reciente time.Time
+
+	for _, entrada := range entradas {
+		tamano += entrada.Size()
+		if antigua.IsZero() || entrada.ModTime().Before(antigua) {
+			antigua = entrada.ModTime()
+		}
+		if entrada.ModTime().After(reciente) {
+			reciente = entrada.ModTime()
+		}
+
+		var resultados ResultadosCache
+		contenido, err := cifrado.leerArchivo(filepath.Join(directorioCache, entrada.Name()))
+		if err != nil || json.Unmarshal(contenido, &resultados) != nil || resultados.Huella+".json" != entrada.Name() {
+			invalidas++
+			continue
+		}
+		archivos += len(resultados.Archivos)
+	}
+
+	fmt.Println("Caché de resultados en \"" + directorioCache + "\"")
+	fmt.Println("\tentradas:", len(entradas), "("+strconv.Itoa(invalidas), "no válidas)")
+	fmt.Printf("\ttamaño: %.1f MB\n", float64(tamano)/BYTES_MB)
+	fmt.Println("\tarchivos analizados en total:", archivos)
+	if len(entradas) > 0 {
+		fmt.Println("\tentrada más antigua:", antigua.Format(time.RFC3339))
+		fmt.Println("\tentrada más reciente:", reciente.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+/*
+ * Función para eliminar todas las entradas de la caché (los demás archivos del directorio se conservan)
+ * param: directorio de la caché
+ * return: un error si el directorio no se puede leer o una entrada no se puede eliminar
+ */
+func limpiarCache(directorioCache string) error {
+	entradas, err := obtenerEntradasCache(directorioCache)
+	if err != nil {
+		return err
+	}
+
+	var tamano int64
+	for _, entrada := range entradas {
+		if err = os.Remove(filepath.Join(directorioCache, entrada.Name())); err != nil {
+			return fmt.Errorf("No se puede eliminar la entrada de la caché \"%s\": %v", entrada.Name(), err)
+		}
+		tamano += entrada.Size()
+	}
+
+	fmt.Printf("Caché de resultados en \"%s\": %d entradas eliminadas (%.1f MB liberados)\n", directorioCache, len(entradas), float64(tamano)/BYTES_MB)
+
+	return nil
+}
+
+/*
+ * Función para leer la versión del formato del directorio de datos
+ * param: directorio de datos
+ * return: la versión (0 si el directorio es anterior al archivo de versión) o un error si el archivo no es válido
+ */
+func leerVersionAlmacen(directorio string) (int, error) {
+	contenido, err := ioutil.ReadFile(filepath.Join(directorio, ARCHIVO_VERSION_ALMACEN))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := strconv.Atoi(strings.TrimSpace(string(contenido)))
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("Versión del directorio de datos no válida en \"%s\"", filepath.Join(directorio, ARCHIVO_VERSION_ALMACEN))
+	}
+
+	return version, nil
+}
+
+/*
+ * Función para guardar la versión del formato del directorio de datos
+ * param: directorio de datos y versión
+ * return: un error si no se puede guardar
+ */
+func guardarVersionAlmacen(directorio string, version int) error {
+	return os.WriteFile(filepath.Join(directorio, ARCHIVO_VERSION_ALMACEN), []byte(strconv.Itoa(version)+"\n"), 0600)
+}
+
+/*
+ * Función para verificar que el servidor puede usar el directorio de datos (un directorio nuevo queda en la versión actual)
+ * param: directorio de datos
+ * return: un error si el directorio es de otra versión
+ */
+func verificarVersionAlmacen(directorio string) error {
+	version, err := leerVersionAlmacen(directorio)
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		trabajos, err := filepath.Glob(filepath.Join(directorio, "*.json"))
+		if err != nil {
+			return err
+		}
+		if len(trabajos) == 0 {
+			return guardarVersionAlmacen(directorio, VERSION_ALMACEN)
+		}
+	}
+
+	if version < VERSION_ALMACEN {
+		return fmt.Errorf("El directorio de datos \"%s\" es de la versión %d, se debe actualizar a la versión %d con: ./SASC db migrate --data-dir=%s", directorio, version, VERSION_ALMACEN, directorio)
+	}
+	if version > VERSION_ALMACEN {
+		return fmt.Errorf("El directorio de datos \"%s\" es de la versión %d, posterior a la que soporta esta versión de SASC (%d)", directorio, version, VERSION_ALMACEN)
+	}
+
+	return nil
+}
+
+/*
+ * Función para reescribir un trabajo guardado, primero en un archivo temporal que luego reemplaza al original
+ * param: ruta del trabajo, trabajo y cifrado (nil si no se cifra)
+ * return: un error si no se puede guardar
+ */
+func reescribirTrabajo(ruta string, trabajo Trabajo, cifrado *Cifrado) error {
+	contenido, err := json.Marshal(trabajo)
+	if err != nil {
+		return err
+	}
+
+	return reescribirArchivo(ruta, contenido, cifrado)
+}
+
+/*
+ * Función para reescribir un archivo del directorio de datos, primero en un archivo temporal que luego reemplaza al original
+ * param: ruta del archivo, contenido y cifrado (nil si no se cifra)
+ * return: un error si no se puede guardar
+ */
+func reescribirArchivo(ruta string, contenido []byte, cifrado *Cifrado) error {
+	if err := cifrado.escribirArchivo(ruta+EXTENSION_TEMPORAL, contenido, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(ruta+EXTENSION_TEMPORAL, ruta)
+}
+
+/*
+ * Función para actualizar el directorio de datos a la versión actual del formato, una migración a la vez
+ * param: directorio de datos y cifrado (nil si no se cifra)
+ * return: un error si no se indicó el directorio, es de una versión posterior o un trabajo no se puede migrar
+ */
+func migrarAlmacen(directorio string, cifrado *Cifrado) error {
+	if directorio == "" {
+		return fmt.Errorf("Se debe indicar el directorio de datos (--data-dir)")
+	}
+
+	version, err := leerVersionAlmacen(directorio)
+	if err != nil {
+		return err
+	}
+	if version > VERSION_ALMACEN {
+		return fmt.Errorf("El directorio de datos \"%s\" es de la versión %d, posterior a la que soporta esta versión de SASC (%d)", directorio, version, VERSION_ALMACEN)
+	}
+	if version == VERSION_ALMACEN {
+		fmt.Println("El directorio de datos \""+directorio+"\" ya está en la versión", VERSION_ALMACEN)
+		return nil
+	}
+
+	rutas, err := filepath.Glob(filepath.Join(directorio, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for ; version < VERSION_ALMACEN; version++ {
+		migracion := migracionesAlmacen[version]
+		modificados := 0
+
+		for _, ruta := range rutas {
+			contenido, err := cifrado.leerArchivo(ruta)
+			if err != nil {
+				return err
+			}
+
+			var trabajo Trabajo
+			if err = json.Unmarshal(contenido, &trabajo); err != nil {
+				return fmt.Errorf("Trabajo guardado no válido \"%s\": %v", ruta, err)
+			}
+
+			if migracion.migrar != nil && migracion.migrar(&trabajo) {
+				if err = reescribirTrabajo(ruta, trabajo, cifrado); err != nil {
+					return fmt.Errorf("No se puede migrar el trabajo \"%s\": %v", ruta, err)
+				}
+				modificados++
+			}
+		}
+
+		// La versión se guarda después de cada migración completa, para continuar desde ella si se interrumpe la siguiente
+		if err = guardarVersionAlmacen(directorio, version+1); err != nil {
+			return err
+		}
+
+		fmt.Printf("Migración a la versión %d: %s (%d de %d trabajos modificados)\n", version+1, migracion.descripcion, modificados, len(rutas))
+	}
+
+	return nil
+}
+
+/*
+ * Función para compactar el directorio de datos: aplica la retención y elimina las entregas sin trabajo y los
+ * archivos temporales de una migración interrumpida
+ * param: directorio de datos y tiempos de retención de las entregas y de los resultados
+ * return: un error si no se indicó el directorio o un archivo no se puede eliminar
+ */
+func compactarAlmacen(directorio string, retencionFuentes time.Duration, retencionResultados time.Duration) error {
+	if directorio == "" {
+		return fmt.Errorf("Se debe indicar el directorio de datos (--data-dir)")
+	}
+
+	almacen := &AlmacenTrabajos{directorio: directorio, retencionFuentes: retencionFuentes, retencionResultados: retencionResultados}
+
+	fuentes, resultados, err := almacen.purgar()
+	if err != nil {
+		return err
+	}
+
+	archivos, err := ioutil.ReadDir(directorio)
+	if err != nil {
+		return err
+	}
+
+	huerfanas, temporales := 0, 0
+	var tamano int64
+
+	for _, archivo := range archivos {
+		ruta := filepath.Join(directorio, archivo.Name())
+
+		switch {
+		case strings.HasSuffix(archivo.Name(), ".tar"):
+			if _, err := os.Stat(strings.TrimSuffix(ruta, ".tar") + ".json"); !os.IsNotExist(err) {
+				continue
+			}
+			huerfanas++
+		case strings.HasSuffix(archivo.Name(), EXTENSION_TEMPORAL):
+			temporales++
+		default:
+			continue
+		}
+
+		if err = os.Remove(ruta); err != nil {
+			return err
+		}
+		tamano += archivo.Size()
+	}
+
+	fmt.Println("Directorio de datos \"" + directorio + "\"")
+	fmt.Println("\tretención:", fuentes, "entregas y", resultados, "resultados eliminados")
+	fmt.Printf("\tentregas sin trabajo: %d, archivos temporales: %d (%.1f MB liberados)\n", huerfanas, temporales, float64(tamano)/BYTES_MB)
+
+	return nil
+}
+
+/*
+ * Función para cifrar las entregas y los trabajos del directorio de datos que aún no están cifrados (los que ya están
+ * cifrados se conservan)
+ * param: directorio de datos y cifrado
+ * return: un error si no se indicó el directorio o la clave, o un archivo no se puede leer o reescribir
+ */
+func cifrarAlmacen(directorio string, cifrado *Cifrado) error {
+	if directorio == "" {
+		return fmt.Errorf("Se debe indicar el directorio de datos (--data-dir)")
+	}
+	if cifrado == nil {
+		return fmt.Errorf("Se debe indicar la clave de cifrado (--encryption-key)")
+	}
+
+	archivos, err := ioutil.ReadDir(directorio)
+	if err != nil {
+		return err
+	}
+
+	cifrados, existentes := 0, 0
+	for _, archivo := range archivos {
+		if !strings.HasSuffix(archivo.Name(), ".tar") && !strings.HasSuffix(archivo.Name(), ".json") {
+			continue
+		}
+		ruta := filepath.Join(directorio, archivo.Name())
+
+		contenido, err := ioutil.ReadFile(ruta)
+		if err != nil {
+			return err
+		}
+		if estaCifrado(contenido) {
+			existentes++
+			continue
+		}
+
+		if err = reescribirArchivo(ruta, contenido, cifrado); err != nil {
+			return fmt.Errorf("No se puede cifrar \"%s\": %v", ruta, err)
+		}
+		cifrados++
+	}
+
+	fmt.Println("Directorio de datos \"" + directorio + "\"")
+	fmt.Println("\tarchivos cifrados:", cifrados, "(ya estaban cifrados:", strconv.Itoa(existentes)+")")
+
+	return nil
+}
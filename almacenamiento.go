@@ -0,0 +1,260 @@
+/*
+ * Almacenamiento de los trabajos del modo servidor y su política de retención.
+ *
+ * Si se indica un directorio de datos (--data-dir), por cada trabajo se guardan las entregas originales
+ * (<id>.tar) y el trabajo con su resultado (<id>.json), de modo que los resultados se conservan entre
+ * reinicios del servidor. Para cumplir con las normas institucionales de protección de datos, cada tipo
+ * de archivo tiene su propio tiempo de retención:
+ * - las entregas originales se eliminan después de --source-retention (por ejemplo 30 días)
+ * - los resultados se eliminan después de --job-retention (por ejemplo 5 años, para la detección entre cohortes)
+ * La purga se realiza al iniciar el servidor y luego cada hora. Con --encryption-key las entregas y los trabajos se
+ * guardan cifrados (ver cifrado.go).
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Intervalo entre las purgas del almacenamiento
+const INTERVALO_PURGA = time.Hour
+
+// Estructura del almacenamiento de los trabajos
+// - directorio de datos
+// - tiempo de retención de las entregas originales y de los resultados (0 los conserva siempre)
+// - cifrado de los archivos (nil si no se cifran)
+type AlmacenTrabajos struct {
+	directorio          string
+	retencionFuentes    time.Duration
+	retencionResultados time.Duration
+	cifrado             *Cifrado
+}
+
+/*
+ * Función para crear el almacenamiento de los trabajos
+ * param: directorio de datos (vacío si no se almacenan), tiempos de retención de las entregas y de los resultados y
+ *        cifrado de los archivos (nil si no se cifran)
+ * return: el almacenamiento (nil si no se almacenan) o un error si el directorio no se puede crear o es de otra versión (ver administracion.go)
+ */
+func crearAlmacenTrabajos(directorio string, retencionFuentes time.Duration, retencionResultados time.Duration, cifrado *Cifrado) (*AlmacenTrabajos, error) {
+	if directorio == "" {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(directorio, 0700); err != nil {
+		return nil, fmt.Errorf("No se puede crear el directorio de datos (--data-dir): %v", err)
+	}
+
+	if err := verificarVersionAlmacen(directorio); err != nil {
+		return nil, err
+	}
+
+	return &AlmacenTrabajos{directorio: directorio, retencionFuentes: retencionFuentes, retencionResultados: retencionResultados, cifrado: cifrado}, nil
+}
+
+/*
+ * Función para guardar las entregas originales de un trabajo. Sin cifrado se copian al disco mientras se leen; con
+ * cifrado se cifran completas en memoria, porque AES-GCM autentica el archivo completo (ver cifrado.go).
+ * param: identificador del trabajo y flujo tar de las entregas
+ * return: un error si no se pueden leer o guardar (no queda ningún archivo)
+ */
+func (almacen *AlmacenTrabajos) guardarFuentes(identificador string, lector io.Reader) error {
+	if almacen == nil {
+		return nil
+	}
+
+	ruta := filepath.Join(almacen.directorio, identificador+".tar")
+	if almacen.cifrado != nil {
+		entregas, err := io.ReadAll(lector)
+		if err != nil {
+			return err
+		}
+		return almacen.cifrado.escribirArchivo(ruta, entregas, 0600)
+	}
+
+	archivo, err := os.OpenFile(ruta+EXTENSION_TEMPORAL, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(archivo, lector)
+	if errCerrar := archivo.Close(); err == nil {
+		err = errCerrar
+	}
+	if err != nil {
+		os.Remove(archivo.Name())
+		return err
+	}
+
+	return os.Rename(archivo.Name(), ruta)
+}
+
+/*
+ * Función para abrir las entregas originales de un trabajo
+ * param: identificador del trabajo
+ * return: el flujo tar de las entregas (se debe cerrar) o un error si no se pueden leer
+ */
+func (almacen *AlmacenTrabajos) abrirFuentes(identificador string) (io.ReadCloser, error) {
+	ruta := filepath.Join(almacen.directorio, identificador+".tar")
+	if almacen.cifrado == nil {
+		return os.Open(ruta)
+	}
+
+	entregas, err := almacen.cifrado.leerArchivo(ruta)
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(entregas)), nil
+}
+
+/*
+ * Función para eliminar las entregas originales de un trabajo (y su archivo temporal si no se terminaron de guardar)
+ * param: identificador del trabajo
+ * return: un error si no se pueden eliminar
+ */
+func (almacen *AlmacenTrabajos) eliminarFuentes(identificador string) error {
+	if almacen == nil {
+		return nil
+	}
+
+	for _, ruta := range []string{identificador + ".tar", identificador + ".tar" + EXTENSION_TEMPORAL} {
+		if err := os.Remove(filepath.Join(almacen.directorio, ruta)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Función para eliminar un trabajo con sus entregas (un trabajo que no se pudo encolar)
+ * param: identificador del trabajo
+ * return: un error si no se puede eliminar
+ */
+func (almacen *AlmacenTrabajos) eliminarTrabajo(identificador string) error {
+	if err := almacen.eliminarFuentes(identificador); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(almacen.directorio, identificador+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+/*
+ * Función para guardar un trabajo con su resultado
+ * param: trabajo
+ * return: un error si no se puede guardar
+ */
+func (almacen *AlmacenTrabajos) guardarTrabajo(trabajo Trabajo) error {
+	if almacen == nil {
+		return nil
+	}
+
+	contenido, err := json.Marshal(trabajo)
+	if err != nil {
+		return err
+	}
+
+	return almacen.cifrado.escribirArchivo(filepath.Join(almacen.directorio, trabajo.Identificador+".json"), contenido, 0600)
+}
+
+/*
+ * Función para cargar los trabajos guardados
+ * return: los trabajos o un error si el directorio no se puede leer
+ */
+func (almacen *AlmacenTrabajos) cargarTrabajos() ([]Trabajo, error) {
+	var trabajos []Trabajo
+
+	if almacen == nil {
+		return trabajos, nil
+	}
+
+	archivos, err := filepath.Glob(filepath.Join(almacen.directorio, "*.json"))
+	if err != nil {
+		return trabajos, err
+	}
+
+	for _, archivo := range archivos {
+		contenido, err := almacen.cifrado.leerArchivo(archivo)
+		if err != nil {
+			return trabajos, fmt.Errorf("No se puede leer el trabajo guardado: %v", err)
+		}
+
+		var trabajo Trabajo
+		if err = json.Unmarshal(contenido, &trabajo); err != nil {
+			return trabajos, fmt.Errorf("Trabajo guardado no válido \"%s\": %v", archivo, err)
+		}
+		trabajos = append(trabajos, trabajo)
+	}
+
+	return trabajos, nil
+}
+
+/*
+ * Función para eliminar las entregas y los resultados que superan su tiempo de retención
+ * return: cantidad de entregas y de resultados eliminados, o un error si el directorio no se puede leer
+ */
+func (almacen *AlmacenTrabajos) purgar() (int, int, error) {
+	fuentes, resultados := 0, 0
+
+	if almacen == nil {
+		return fuentes, resultados, nil
+	}
+
+	archivos, err := ioutil.ReadDir(almacen.directorio)
+	if err != nil {
+		return fuentes, resultados, err
+	}
+
+	for _, archivo := range archivos {
+		antiguedad := time.Since(archivo.ModTime())
+		ruta := filepath.Join(almacen.directorio, archivo.Name())
+
+		if strings.HasSuffix(archivo.Name(), ".tar") && almacen.retencionFuentes > 0 && antiguedad > almacen.retencionFuentes {
+			if err = os.Remove(ruta); err != nil {
+				return fuentes, resultados, err
+			}
+			fuentes++
+		} else if strings.HasSuffix(archivo.Name(), ".json") && almacen.retencionResultados > 0 && antiguedad > almacen.retencionResultados {
+			if err = os.Remove(ruta); err != nil {
+				return fuentes, resultados, err
+			}
+			resultados++
+		}
+	}
+
+	return fuentes, resultados, nil
+}
+
+/*
+ * Función para purgar el almacenamiento al iniciar y luego periódicamente (en segundo plano)
+ */
+func (almacen *AlmacenTrabajos) purgarPeriodicamente() {
+	if almacen == nil {
+		return
+	}
+
+	go func() {
+		for {
+			fuentes, resultados, err := almacen.purgar()
+			if err != nil {
+				fmt.Println("Error al purgar el directorio de datos:", err)
+			} else if fuentes > 0 || resultados > 0 {
+				fmt.Println("Purga del directorio de datos:", fuentes, "entregas y", resultados, "resultados eliminados")
+			}
+
+			time.Sleep(INTERVALO_PURGA)
+		}
+	}()
+}
@@ -0,0 +1,277 @@
+/*
+ * Registro de auditoría de los análisis y de las consultas de los reportes (--audit-log).
+ *
+ * La oficina de integridad académica debe poder saber quién ejecutó cada análisis, con qué parámetros, y quién
+ * consultó cada reporte. Con --audit-log cada acción se agrega como una línea JSON al final del archivo:
+ *
+ *     {"fecha":"2023-03-01T14:05:09Z","anterior":"9f2c...","accion":"consultar_trabajo","identidad":"usuario:docente@uniquindio.edu.co",
+ *      "direccion":"10.0.0.7","curso":"programacion1","trabajo":"4be1...","estado":200}
+ *
+ * - en el modo servidor: los análisis (POST /analyze), los trabajos encolados (POST /jobs y /lti/jobs), las
+ *   comparaciones (POST /v1/similarity y POST /v1/self-check) y las consultas de los trabajos y sus reportes
+ *   (GET /jobs, GET /jobs/{id} y /lti/report/{id}), después de la autenticación, con el código de estado de la respuesta
+ * - con los comandos report y db: la generación de reportes de un resultado guardado y la compactación, migración o
+ *   cifrado del directorio de datos, con el usuario del sistema operativo
+ * La identidad es la huella de la clave de API (nunca la clave), el usuario del proxy OIDC, el usuario de la
+ * plataforma LTI o la dirección IP si el servidor no requiere autenticación (ver limites_servidor.go).
+ *
+ * El archivo solamente se abre para agregar registros, y cada registro incluye la huella SHA-256 del registro anterior
+ * ("anterior"), de modo que una línea modificada, o eliminada antes de la última, rompe la cadena. La cadena se
+ * verifica al abrir el archivo y SASC no inicia si está rota. La cadena sola no detecta que se eliminen las últimas
+ * líneas (el archivo truncado sigue siendo una cadena válida), por eso al abrir el archivo y con cada comando report y
+ * db se imprime la cantidad de registros y la huella del último (la cabeza de la cadena), que se debe conservar fuera
+ * del servidor (por ejemplo en el registro del sistema): una cabeza anotada que ya no está en el archivo indica que se
+ * eliminaron registros. El servidor y los comandos db no deben escribir el mismo archivo al mismo tiempo (los
+ * comandos db se ejecutan con el servidor detenido).
+ *
+ * Si un registro no se puede escribir, la petición responde 500 (la acción no se reporta sin su registro) y el
+ * registro no acepta más escrituras, porque una línea escrita a medias rompería la cadena de las siguientes.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+)
+
+// Acciones del registro de auditoría
+const (
+	ACCION_ANALIZAR          = "analizar"
+	ACCION_ENCOLAR           = "encolar"
+	ACCION_COMPARAR          = "comparar"
+	ACCION_AUTOVERIFICAR     = "autoverificar"
+	ACCION_LISTAR_TRABAJOS   = "listar_trabajos"
+	ACCION_CONSULTAR_TRABAJO = "consultar_trabajo"
+	ACCION_GENERAR_REPORTE   = "generar_reporte"
+	ACCION_COMPACTAR_DATOS   = "compactar_datos"
+	ACCION_MIGRAR_DATOS      = "migrar_datos"
+	ACCION_CIFRAR_DATOS      = "cifrar_datos"
+)
+
+// Estructura de un registro de auditoría (una línea JSON del archivo)
+// - fecha (UTC) y huella SHA-256 del registro anterior (vacía en el primero)
+// - acción e identidad de quien la ejecutó, con su dirección IP en el modo servidor
+// - curso, trabajo y parámetros de la acción (si aplican)
+// - código de estado de la respuesta en el modo servidor
+type RegistroAuditoriaJSON struct {
+	Fecha      string            `json:"fecha"`
+	Anterior   string            `json:"anterior"`
+	Accion     string            `json:"accion"`
+	Identidad  string            `json:"identidad"`
+	Direccion  string            `json:"direccion,omitempty"`
+	Curso      string            `json:"curso,omitempty"`
+	Trabajo    string            `json:"trabajo,omitempty"`
+	Parametros map[string]string `json:"parametros,omitempty"`
+	Estado     int               `json:"estado,omitempty"`
+}
+
+// Estructura del registro de auditoría
+// - archivo abierto solo para agregar (protegido por el mutex)
+// - huella del último registro del archivo (cabeza de la cadena) y cantidad de registros
+// - error de la primera escritura fallida (después no se escribe más)
+type Auditoria struct {
+	mutex     sync.Mutex
+	archivo   *os.File
+	anterior  string
+	registros int
+	fallo     error
+}
+
+/*
+ * Función para abrir el registro de auditoría, verificando la cadena de los registros existentes
+ * param: nombre del archivo (vacío si no se audita)
+ * return: el registro (nil si no se audita) o un error si el archivo no se puede abrir o su cadena está rota
+ */
+func abrirAuditoria(nombreArchivo string) (*Auditoria, error) {
+	if nombreArchivo == "" {
+		return nil, nil
+	}
+
+	contenido, err := ioutil.ReadFile(nombreArchivo)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("No se puede leer el registro de auditoría (--audit-log): %v", err)
+	}
+
+	anterior, registros, err := verificarCadenaAuditoria(contenido)
+	if err != nil {
+		return nil, err
+	}
+
+	archivo, err := os.OpenFile(nombreArchivo, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("No se puede abrir el registro de auditoría (--audit-log): %v", err)
+	}
+
+	auditoria := &Auditoria{archivo: archivo, anterior: anterior, registros: registros}
+	fmt.Fprintln(os.Stderr, "Registro de auditoría \""+nombreArchivo+"\":", auditoria.describirCabeza())
+
+	return auditoria, nil
+}
+
+/*
+ * Función para describir la cabeza de la cadena, que se debe conservar para detectar que se eliminen los últimos registros
+ * return: cantidad de registros y huella del último
+ */
+func (auditoria *Auditoria) describirCabeza() string {
+	auditoria.mutex.Lock()
+	defer auditoria.mutex.Unlock()
+
+	if auditoria.registros == 0 {
+		return "sin registros"
+	}
+
+	return fmt.Sprintf("%d registros, último %s", auditoria.registros, auditoria.anterior)
+}
+
+/*
+ * Función para calcular la huella de un registro
+ * param: línea JSON del registro (sin el salto de línea)
+ * return: huella SHA-256 en hexadecimal
+ */
+func calcularHuellaAuditoria(linea []byte) string {
+	huella := sha256.Sum256(linea)
+	return hex.EncodeToString(huella[:])
+}
+
+/*
+ * Función para verificar que cada registro contiene la huella del registro anterior
+ * param: contenido del archivo de auditoría
+ * return: huella del último registro (vacía si no hay registros) y cantidad de registros, o un error con la primera
+ *         línea que rompe la cadena
+ */
+func verificarCadenaAuditoria(contenido []byte) (string, int, error) {
+	anterior := ""
+	if len(contenido) == 0 {
+		return anterior, 0, nil
+	}
+	if contenido[len(contenido)-1] != '\n' {
+		return anterior, 0, fmt.Errorf("El registro de auditoría (--audit-log) está incompleto: la última línea no termina")
+	}
+
+	lineas := bytes.Split(contenido[:len(contenido)-1], []byte("\n"))
+	for numero, linea := range lineas {
+		var registro RegistroAuditoriaJSON
+		if err := json.Unmarshal(linea, &registro); err != nil || registro.Anterior != anterior {
+			return anterior, 0, fmt.Errorf("El registro de auditoría (--audit-log) fue modificado: la línea %d no continúa la cadena", numero+1)
+		}
+		anterior = calcularHuellaAuditoria(linea)
+	}
+
+	return anterior, len(lineas), nil
+}
+
+/*
+ * Función para agregar un registro de auditoría, con la fecha, la huella del registro anterior y, en el modo
+ * servidor, la dirección IP y los parámetros de la consulta (?max-distance=, ?threshold=, ...) de la petición
+ * param: petición (nil fuera del modo servidor) y registro con la acción, la identidad, el curso, el trabajo y el estado
+ * return: un error si el registro no se puede escribir o una escritura anterior falló
+ */
+func (auditoria *Auditoria) registrar(peticion *http.Request, registro RegistroAuditoriaJSON) error {
+	if auditoria == nil {
+		return nil
+	}
+
+	if peticion != nil {
+		direccion, _, err := net.SplitHostPort(peticion.RemoteAddr)
+		if err != nil {
+			direccion = peticion.RemoteAddr
+		}
+		registro.Direccion = direccion
+
+		for clave, valores := range peticion.URL.Query() {
+			// El curso tiene su propio campo y la sesión LTI es una credencial
+			if clave == "course" || clave == "session" || len(valores) == 0 {
+				continue
+			}
+			if registro.Parametros == nil {
+				registro.Parametros = make(map[string]string)
+			}
+			registro.Parametros[clave] = valores[0]
+		}
+	}
+
+	auditoria.mutex.Lock()
+	defer auditoria.mutex.Unlock()
+
+	if auditoria.fallo != nil {
+		return auditoria.fallo
+	}
+
+	registro.Fecha = time.Now().UTC().Format(time.RFC3339)
+	registro.Anterior = auditoria.anterior
+
+	linea, err := json.Marshal(registro)
+	if err != nil {
+		return err
+	}
+
+	if _, err = auditoria.archivo.Write(append(linea, '\n')); err == nil {
+		err = auditoria.archivo.Sync()
+	}
+	if err != nil {
+		auditoria.fallo = fmt.Errorf("No se puede escribir el registro de auditoría (--audit-log): %v", err)
+		return auditoria.fallo
+	}
+
+	auditoria.anterior = calcularHuellaAuditoria(linea)
+	auditoria.registros++
+
+	return nil
+}
+
+/*
+ * Función para agregar el registro de auditoría de una petición antes de responderla
+ * param: respuesta, petición y registro
+ * return: si se agregó el registro (si no, se responde 500 y la petición no se debe responder de nuevo)
+ */
+func (auditoria *Auditoria) registrarPeticion(respuesta http.ResponseWriter, peticion *http.Request, registro RegistroAuditoriaJSON) bool {
+	if err := auditoria.registrar(peticion, registro); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		http.Error(respuesta, "No se puede escribir el registro de auditoría", http.StatusInternalServerError)
+		return false
+	}
+
+	return true
+}
+
+/*
+ * Función para crear el registro de auditoría de los comandos report y db
+ * param: parámetros de ejecución (comando, acción, resultado guardado y directorio de datos)
+ * return: registro con la acción, el usuario del sistema operativo y el archivo o directorio empleado
+ */
+func crearRegistroComando(parametros Parametros) RegistroAuditoriaJSON {
+	registro := RegistroAuditoriaJSON{Accion: ACCION_MIGRAR_DATOS, Identidad: identificarUsuarioSistema(), Parametros: map[string]string{"data-dir": parametros.directorioDatos}}
+
+	if parametros.comando == "report" {
+		registro.Accion, registro.Parametros = ACCION_GENERAR_REPORTE, map[string]string{"from": parametros.rutaResultado}
+	} else if parametros.accionComando == "vacuum" {
+		registro.Accion = ACCION_COMPACTAR_DATOS
+	} else if parametros.accionComando == "encrypt" {
+		registro.Accion = ACCION_CIFRAR_DATOS
+	}
+
+	return registro
+}
+
+/*
+ * Función para identificar al usuario del sistema operativo que ejecuta un comando
+ * return: identidad del usuario (por ejemplo "sistema:jugutier")
+ */
+func identificarUsuarioSistema() string {
+	if actual, err := user.Current(); err == nil {
+		return "sistema:" + actual.Username
+	}
+
+	return "sistema:" + os.Getenv("USER")
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+ * Función para crear el contenido de un registro de auditoría con registros encadenados
+ * param: prueba y acciones de los registros
+ * return: el contenido del archivo
+ */
+func crearCadenaAuditoriaPrueba(t *testing.T, acciones ...string) []byte {
+	t.Helper()
+
+	archivo := filepath.Join(t.TempDir(), "auditoria.jsonl")
+	auditoria := abrirAuditoriaPrueba(t, archivo)
+	for _, accion := range acciones {
+		if err := auditoria.registrar(nil, RegistroAuditoriaJSON{Accion: accion, Identidad: "sistema:prueba"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	contenido, err := os.ReadFile(archivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return contenido
+}
+
+/*
+ * Función para abrir un registro de auditoría en una prueba, sin imprimir su cabeza
+ * param: prueba y nombre del archivo
+ * return: el registro (se cierra al terminar la prueba)
+ */
+func abrirAuditoriaPrueba(t *testing.T, archivo string) *Auditoria {
+	t.Helper()
+
+	salida := os.Stderr
+	os.Stderr = nil
+	auditoria, err := abrirAuditoria(archivo)
+	os.Stderr = salida
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { auditoria.archivo.Close() })
+
+	return auditoria
+}
+
+func TestVerificarCadenaAuditoria(t *testing.T) {
+	contenido := crearCadenaAuditoriaPrueba(t, ACCION_ANALIZAR, ACCION_ENCOLAR, ACCION_CONSULTAR_TRABAJO)
+	lineas := bytes.SplitAfter(contenido, []byte("\n"))[:3]
+
+	cabezaCompleta, _, err := verificarCadenaAuditoria(contenido)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	casos := []struct {
+		nombre    string
+		contenido []byte
+		registros int
+		valido    bool
+	}{
+		{"vacío", nil, 0, true},
+		{"completo", contenido, 3, true},
+		{"sin la última línea", bytes.Join(lineas[:2], nil), 2, true},
+		{"sin una línea intermedia", bytes.Join([][]byte{lineas[0], lineas[2]}, nil), 0, false},
+		{"línea modificada", bytes.Replace(contenido, []byte(ACCION_ENCOLAR), []byte(ACCION_ANALIZAR), 1), 0, false},
+		{"última línea incompleta", contenido[:len(contenido)-1], 0, false},
+	}
+
+	for _, caso := range casos {
+		cabeza, registros, err := verificarCadenaAuditoria(caso.contenido)
+		if (err == nil) != caso.valido {
+			t.Errorf("%s: error = %v", caso.nombre, err)
+			continue
+		}
+		if caso.valido && registros != caso.registros {
+			t.Errorf("%s: registros = %d, se esperaban %d", caso.nombre, registros, caso.registros)
+		}
+		// Sin la última línea la cadena es válida: solamente la cabeza anotada antes lo detecta
+		if caso.nombre == "sin la última línea" && cabeza == cabezaCompleta {
+			t.Errorf("%s: la cabeza no cambió", caso.nombre)
+		}
+	}
+}
+
+func TestDescribirCabezaAuditoria(t *testing.T) {
+	archivo := filepath.Join(t.TempDir(), "auditoria.jsonl")
+	auditoria := abrirAuditoriaPrueba(t, archivo)
+
+	if cabeza := auditoria.describirCabeza(); cabeza != "sin registros" {
+		t.Errorf("cabeza = %q, se esperaba sin registros", cabeza)
+	}
+	if err := auditoria.registrar(nil, RegistroAuditoriaJSON{Accion: ACCION_MIGRAR_DATOS}); err != nil {
+		t.Fatal(err)
+	}
+
+	contenido, err := os.ReadFile(archivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperada := "1 registros, último " + calcularHuellaAuditoria(bytes.TrimSuffix(contenido, []byte("\n")))
+	if cabeza := auditoria.describirCabeza(); cabeza != esperada {
+		t.Errorf("cabeza = %q, se esperaba %q", cabeza, esperada)
+	}
+}
+
+func TestRegistrarAuditoriaFallida(t *testing.T) {
+	auditoria := abrirAuditoriaPrueba(t, filepath.Join(t.TempDir(), "auditoria.jsonl"))
+	servidor := crearServidorAuditoriaPrueba(t, crearParametrosServidorPrueba(), auditoria)
+
+	if estado, _ := enviarPeticionPrueba(t, http.MethodGet, servidor.URL+"/jobs", nil); estado != http.StatusOK {
+		t.Fatalf("estado con el registro disponible = %d, se esperaba 200", estado)
+	}
+
+	// Una escritura fallida (el archivo cerrado) no detiene el servidor: la petición responde 500
+	auditoria.archivo.Close()
+	estado, cuerpo := enviarPeticionPrueba(t, http.MethodGet, servidor.URL+"/jobs", nil)
+	if estado != http.StatusInternalServerError || !strings.Contains(cuerpo, "registro de auditoría") {
+		t.Errorf("estado con el registro fallido = %d %q, se esperaba 500", estado, cuerpo)
+	}
+
+	if err := auditoria.registrar(nil, RegistroAuditoriaJSON{Accion: ACCION_ANALIZAR}); err == nil {
+		t.Error("el registro aceptó una escritura después de una fallida")
+	}
+}
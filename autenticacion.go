@@ -0,0 +1,231 @@
+/*
+ * Autenticación y autorización por curso del modo servidor.
+ *
+ * El archivo de autorización (--auth-file) indica los cursos a los que tiene acceso cada identidad:
+ *
+ *     {
+ *       "claves":   {"clave-secreta-1": ["programacion1"], "clave-admin": ["*"]},
+ *       "usuarios": {"docente@uniquindio.edu.co": ["programacion1", "programacion2"]}
+ *     }
+ *
+ * - las claves de API se envían en el encabezado "Authorization: Bearer <clave>"; una petición con el encabezado
+ *   Authorization sin el esquema Bearer se rechaza
+ * - los usuarios se identifican con el encabezado indicado en --auth-header (por ejemplo X-Forwarded-Email),
+ *   que debe ser establecido por un proxy OIDC delante del servidor (por ejemplo oauth2-proxy). El encabezado solo se
+ *   acepta en las peticiones que llegan desde las direcciones del proxy (--auth-proxy), porque cualquier otro cliente
+ *   lo puede enviar con el usuario que quiera
+ * - "*" da acceso a todos los cursos
+ * Cada análisis pertenece a un curso (?course=) y solamente es visible para quienes tienen acceso a él.
+ */
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Estructura del archivo de autorización
+// - cursos por clave de API
+// - cursos por usuario (identificado por el proxy OIDC)
+type ArchivoAutorizacion struct {
+	Claves   map[string][]string `json:"claves"`
+	Usuarios map[string][]string `json:"usuarios"`
+}
+
+// Estructura de la autorización del servidor
+// - identidades con sus cursos
+// - encabezado con el usuario autenticado por el proxy OIDC (vacío si no se usa) y redes del proxy
+type Autorizacion struct {
+	identidades ArchivoAutorizacion
+	encabezado  string
+	proxies     []*net.IPNet
+}
+
+/*
+ * Función para crear la autorización del servidor a partir del archivo de autorización
+ * param: nombre del archivo de autorización (vacío si el servidor no requiere autenticación),
+ *        encabezado del usuario autenticado por el proxy OIDC y direcciones IP o redes (CIDR) del proxy separadas por comas
+ * return: la autorización (nil si no se requiere) o un error si el archivo no se puede leer o las direcciones no son válidas
+ */
+func crearAutorizacion(nombreArchivo string, encabezado string, proxies string) (*Autorizacion, error) {
+	if nombreArchivo == "" {
+		if encabezado != "" {
+			return nil, fmt.Errorf("El encabezado del usuario (--auth-header) requiere un archivo de autorización (--auth-file)")
+		}
+		return nil, nil
+	}
+	if encabezado != "" && proxies == "" {
+		return nil, fmt.Errorf("El encabezado del usuario (--auth-header) requiere las direcciones del proxy OIDC (--auth-proxy)")
+	}
+
+	contenido, err := ioutil.ReadFile(nombreArchivo)
+	if err != nil {
+		return nil, fmt.Errorf("No se puede leer el archivo de autorización (--auth-file): %v", err)
+	}
+
+	autorizacion := &Autorizacion{encabezado: encabezado}
+	if err = json.Unmarshal(contenido, &autorizacion.identidades); err != nil {
+		return nil, fmt.Errorf("Archivo de autorización (--auth-file) no válido: %v", err)
+	}
+
+	if encabezado != "" {
+		if autorizacion.proxies, err = obtenerRedesProxy(proxies); err != nil {
+			return nil, err
+		}
+	}
+
+	return autorizacion, nil
+}
+
+/*
+ * Función para obtener las redes del proxy OIDC
+ * param: direcciones IP o redes (CIDR) separadas por comas
+ * return: las redes (una dirección IP es una red de un solo elemento) o un error si alguna no es válida
+ */
+func obtenerRedesProxy(proxies string) ([]*net.IPNet, error) {
+	var redes []*net.IPNet
+
+	for _, texto := range strings.Split(proxies, ",") {
+		texto = strings.TrimSpace(texto)
+		if !strings.Contains(texto, "/") {
+			direccion := net.ParseIP(texto)
+			if direccion == nil {
+				return nil, fmt.Errorf("Dirección del proxy OIDC (--auth-proxy) \"%s\" no válida", texto)
+			}
+			if direccion.To4() != nil {
+				texto += "/32"
+			} else {
+				texto += "/128"
+			}
+		}
+
+		_, red, err := net.ParseCIDR(texto)
+		if err != nil {
+			return nil, fmt.Errorf("Red del proxy OIDC (--auth-proxy) \"%s\" no válida", texto)
+		}
+		redes = append(redes, red)
+	}
+
+	return redes, nil
+}
+
+/*
+ * Función para obtener el usuario autenticado por el proxy OIDC
+ * param: petición
+ * return: el valor del encabezado del usuario, vacío si no se usa, no se envió o la petición no viene del proxy
+ */
+func (autorizacion *Autorizacion) obtenerUsuarioProxy(peticion *http.Request) string {
+	if autorizacion.encabezado == "" {
+		return ""
+	}
+
+	direccion, _, err := net.SplitHostPort(peticion.RemoteAddr)
+	if err != nil {
+		direccion = peticion.RemoteAddr
+	}
+	ip := net.ParseIP(direccion)
+	if ip == nil {
+		return ""
+	}
+
+	for _, red := range autorizacion.proxies {
+		if red.Contains(ip) {
+			return peticion.Header.Get(autorizacion.encabezado)
+		}
+	}
+
+	return ""
+}
+
+/*
+ * Función para identificar a quien hace una petición
+ * param: petición
+ * return: cursos a los que tiene acceso y si se pudo identificar
+ */
+func (autorizacion *Autorizacion) identificar(peticion *http.Request) ([]string, bool) {
+	if valor := peticion.Header.Get("Authorization"); valor != "" {
+		if !strings.HasPrefix(valor, "Bearer ") {
+			return nil, false
+		}
+
+		// Comparación en tiempo constante para no revelar las claves por el tiempo de respuesta
+		clave := strings.TrimPrefix(valor, "Bearer ")
+		for claveValida, cursos := range autorizacion.identidades.Claves {
+			if subtle.ConstantTimeCompare([]byte(clave), []byte(claveValida)) == 1 {
+				return cursos, true
+			}
+		}
+	}
+
+	if usuario := autorizacion.obtenerUsuarioProxy(peticion); usuario != "" {
+		cursos, existe := autorizacion.identidades.Usuarios[usuario]
+		return cursos, existe
+	}
+
+	return nil, false
+}
+
+/*
+ * Función para obtener y autorizar el curso de una petición (?course=). Si la petición no se autoriza se responde
+ * el error.
+ * param: respuesta, petición y si el curso es obligatorio cuando el servidor requiere autenticación
+ * return: el curso (vacío si no se indicó) y si se autorizó
+ */
+func (autorizacion *Autorizacion) autorizarCurso(respuesta http.ResponseWriter, peticion *http.Request, obligatorio bool) (string, bool) {
+	curso := peticion.URL.Query().Get("course")
+	if autorizacion != nil && obligatorio && curso == "" {
+		http.Error(respuesta, "Se debe indicar el curso (?course=)", http.StatusBadRequest)
+		return curso, false
+	}
+	if _, autorizado := autorizacion.autorizar(respuesta, peticion, curso); !autorizado {
+		return curso, false
+	}
+
+	return curso, true
+}
+
+/*
+ * Función para determinar si se tiene acceso a un curso
+ * param: cursos a los que se tiene acceso y curso solicitado
+ * return: verdadero si el curso está entre los permitidos o se tiene acceso a todos ("*")
+ */
+func permiteCurso(cursos []string, curso string) bool {
+	for _, permitido := range cursos {
+		if permitido == "*" || permitido == curso {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+ * Función para autorizar una petición sobre un curso. Si la petición no se autoriza se responde el error.
+ * param: respuesta, petición y curso solicitado
+ * return: los cursos a los que se tiene acceso (["*"] si el servidor no requiere autenticación) y si se autorizó
+ */
+func (autorizacion *Autorizacion) autorizar(respuesta http.ResponseWriter, peticion *http.Request, curso string) ([]string, bool) {
+	if autorizacion == nil {
+		return []string{"*"}, true
+	}
+
+	cursos, identificado := autorizacion.identificar(peticion)
+	if !identificado {
+		respuesta.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(respuesta, "Se requiere autenticación", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	if curso != "" && !permiteCurso(cursos, curso) {
+		http.Error(respuesta, fmt.Sprintf("Sin acceso al curso \"%s\"", curso), http.StatusForbidden)
+		return nil, false
+	}
+
+	return cursos, true
+}
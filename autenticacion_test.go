@@ -0,0 +1,164 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+/*
+ * Función para crear el archivo de autorización de una prueba
+ * param: prueba y contenido del archivo
+ * return: nombre del archivo
+ */
+func crearArchivoAutorizacionPrueba(t *testing.T, contenido string) string {
+	t.Helper()
+
+	archivo := filepath.Join(t.TempDir(), "autorizacion.json")
+	if err := os.WriteFile(archivo, []byte(contenido), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return archivo
+}
+
+func TestCrearAutorizacion(t *testing.T) {
+	if autorizacion, err := crearAutorizacion("", "", ""); autorizacion != nil || err != nil {
+		t.Errorf("sin archivo = %v, %v, se esperaba nil", autorizacion, err)
+	}
+	if _, err := crearAutorizacion("", "X-Forwarded-Email", "127.0.0.1"); err == nil {
+		t.Error("encabezado sin archivo de autorización sin error")
+	}
+	if _, err := crearAutorizacion(filepath.Join(t.TempDir(), "no_existe.json"), "", ""); err == nil {
+		t.Error("archivo de autorización que no existe sin error")
+	}
+	if _, err := crearAutorizacion(crearArchivoAutorizacionPrueba(t, "{\"claves\": ["), "", ""); err == nil {
+		t.Error("archivo de autorización no válido sin error")
+	}
+
+	archivo := crearArchivoAutorizacionPrueba(t, "{}")
+	casos := []struct {
+		proxies string
+		valido  bool
+	}{
+		{"", false},
+		{"127.0.0.1", true},
+		{"10.0.0.0/8, ::1", true},
+		{"proxy.local", false},
+		{"10.0.0.0/33", false},
+	}
+	for _, caso := range casos {
+		if _, err := crearAutorizacion(archivo, "X-Forwarded-Email", caso.proxies); (err == nil) != caso.valido {
+			t.Errorf("encabezado con --auth-proxy=%q: error = %v", caso.proxies, err)
+		}
+	}
+}
+
+func TestAutorizarCurso(t *testing.T) {
+	autorizacion, err := crearAutorizacion(crearArchivoAutorizacionPrueba(t, `{
+		"claves":   {"clave-p1": ["programacion1"], "clave-admin": ["*"]},
+		"usuarios": {"docente@uniquindio.edu.co": ["programacion1", "programacion2"]}
+	}`), "X-Forwarded-Email", "192.0.2.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Las peticiones de prueba llegan desde 192.0.2.1, la red del proxy, salvo las de otra dirección
+	casos := []struct {
+		nombre      string
+		encabezados map[string]string
+		direccion   string
+		curso       string
+		estado      int
+	}{
+		{"sin identidad", nil, "", "programacion1", http.StatusUnauthorized},
+		{"clave desconocida", map[string]string{"Authorization": "Bearer otra"}, "", "programacion1", http.StatusUnauthorized},
+		{"clave del curso", map[string]string{"Authorization": "Bearer clave-p1"}, "", "programacion1", http.StatusOK},
+		{"clave de otro curso", map[string]string{"Authorization": "Bearer clave-p1"}, "", "programacion2", http.StatusForbidden},
+		{"clave de todos los cursos", map[string]string{"Authorization": "Bearer clave-admin"}, "", "bases_datos", http.StatusOK},
+		{"usuario del proxy", map[string]string{"X-Forwarded-Email": "docente@uniquindio.edu.co"}, "", "programacion2", http.StatusOK},
+		{"usuario desconocido", map[string]string{"X-Forwarded-Email": "otro@uniquindio.edu.co"}, "", "programacion1", http.StatusUnauthorized},
+		{"usuario sin acceso", map[string]string{"X-Forwarded-Email": "docente@uniquindio.edu.co"}, "", "bases_datos", http.StatusForbidden},
+		{"sin curso", map[string]string{"Authorization": "Bearer clave-p1"}, "", "", http.StatusBadRequest},
+		{"usuario desde otra dirección", map[string]string{"X-Forwarded-Email": "docente@uniquindio.edu.co"}, "203.0.113.5:4000", "programacion1", http.StatusUnauthorized},
+		{"clave sin Bearer", map[string]string{"Authorization": "clave-p1"}, "", "programacion1", http.StatusUnauthorized},
+		{"clave sin Bearer con usuario del proxy", map[string]string{"Authorization": "clave-p1", "X-Forwarded-Email": "docente@uniquindio.edu.co"}, "", "programacion1", http.StatusUnauthorized},
+		{"token del proxy con usuario del proxy", map[string]string{"Authorization": "Bearer token-oidc", "X-Forwarded-Email": "docente@uniquindio.edu.co"}, "", "programacion1", http.StatusOK},
+	}
+
+	for _, caso := range casos {
+		peticion := httptest.NewRequest(http.MethodGet, "/jobs?course="+caso.curso, nil)
+		if caso.direccion != "" {
+			peticion.RemoteAddr = caso.direccion
+		}
+		for nombre, valor := range caso.encabezados {
+			peticion.Header.Set(nombre, valor)
+		}
+		respuesta := httptest.NewRecorder()
+
+		curso, autorizado := autorizacion.autorizarCurso(respuesta, peticion, true)
+		if autorizado != (caso.estado == http.StatusOK) || respuesta.Code != caso.estado {
+			t.Errorf("%s: autorizado = %v con estado %d, se esperaba %d", caso.nombre, autorizado, respuesta.Code, caso.estado)
+		}
+		if curso != caso.curso {
+			t.Errorf("%s: curso = %q, se esperaba %q", caso.nombre, curso, caso.curso)
+		}
+		if caso.estado == http.StatusUnauthorized && respuesta.Header().Get("WWW-Authenticate") != "Bearer" {
+			t.Errorf("%s: respuesta 401 sin el encabezado WWW-Authenticate", caso.nombre)
+		}
+	}
+}
+
+func TestAutorizarSinAutenticacion(t *testing.T) {
+	var autorizacion *Autorizacion
+
+	peticion := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+	respuesta := httptest.NewRecorder()
+
+	if cursos, autorizado := autorizacion.autorizar(respuesta, peticion, "programacion1"); !autorizado || !reflect.DeepEqual(cursos, []string{"*"}) {
+		t.Errorf("autorizar sin autenticación = %v, %v, se esperaba [*]", cursos, autorizado)
+	}
+	if curso, autorizado := autorizacion.autorizarCurso(respuesta, peticion, true); !autorizado || curso != "" {
+		t.Errorf("autorizarCurso sin autenticación = %q, %v, se esperaba un curso opcional", curso, autorizado)
+	}
+}
+
+func TestServidorAutenticacion(t *testing.T) {
+	parametros := crearParametrosServidorPrueba()
+	parametros.archivoAutorizacion = crearArchivoAutorizacionPrueba(t, `{"claves": {"clave-p1": ["programacion1"]}}`)
+	servidor := crearServidorPrueba(t, parametros)
+
+	entregas := map[string]string{"a/main.go": "package main\n\nfunc main() {}\n", "b/main.go": "package main\n\nfunc main() { println(1) }\n"}
+
+	casos := []struct {
+		nombre, clave, curso string
+		estado               int
+	}{
+		{"sin clave", "", "programacion1", http.StatusUnauthorized},
+		{"otro curso", "clave-p1", "programacion2", http.StatusForbidden},
+		{"sin curso", "clave-p1", "", http.StatusBadRequest},
+		{"curso autorizado", "clave-p1", "programacion1", http.StatusOK},
+	}
+
+	for _, caso := range casos {
+		peticion, err := http.NewRequest(http.MethodPost, servidor.URL+"/analyze?course="+caso.curso, crearEntregasPrueba(t, entregas))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if caso.clave != "" {
+			peticion.Header.Set("Authorization", "Bearer "+caso.clave)
+		}
+		respuesta, err := http.DefaultClient.Do(peticion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		respuesta.Body.Close()
+
+		if respuesta.StatusCode != caso.estado {
+			t.Errorf("%s: estado %d, se esperaba %d", caso.nombre, respuesta.StatusCode, caso.estado)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+/*
+ * Autoverificación de los estudiantes antes de entregar (--self-check con el modo servidor).
+ *
+ * ./SASC serve --self-check=entregas java 40 ejecuta un servidor restringido para los estudiantes: solamente atiende
+ * POST /v1/self-check (cuerpo: el archivo del estudiante) y GET /metrics. La respuesta es un resultado grueso, si se
+ * detectó o no una similitud alta con el corpus, sin distancias, nombres, rutas ni cantidad de archivos cercanos,
+ * de modo que un estudiante no puede usar el servicio para conocer o reconstruir el código de otros.
+ *
+ * Al iniciar se caracterizan los archivos del directorio indicado (por ejemplo las entregas de semestres anteriores) y
+ * se descartan su contenido, sus nombres y sus tokens: el servidor conserva solamente las características del motor
+ * (frecuencias y hashes de las líneas o de los n-gramas) y el hash SHA-256 de cada contenido preprocesado, con el que
+ * se detectan las copias exactas. El motor se crea como en el comando robustness (sin --reduce ni --gram, ver
+ * robustez.go). La distancia máxima es la del servidor (--max-distance o --threshold); sin distancia máxima se usa
+ * el criterio "auto" con las distancias entre los archivos del corpus.
+ *
+ * El corpus no debe incluir las entregas del mismo estudiante, porque su propio archivo se reportaría como similar.
+ * Si el servidor tiene --auth-file, la petición debe identificarse. Con --rate-limit y --course-quota se limita la
+ * cantidad de verificaciones de cada estudiante y de cada curso (?course=, ver limites_servidor.go), para que no se
+ * pueda explorar el corpus enviando muchas variantes de un archivo. Con --audit-log se registra quién hizo cada
+ * verificación, sin el contenido ni el resultado (ver auditoria.go).
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Tamaño máximo (en bytes) del archivo de una autoverificación
+const LIMITE_PETICION_AUTOVERIFICACION = 1 << 20
+
+// Mensajes del resultado de la autoverificación
+const (
+	MENSAJE_SIMILITUD_ALTA = "Se detectó una similitud alta con otros trabajos; revise su código antes de entregarlo"
+	MENSAJE_SIMILITUD_BAJA = "No se detectó una similitud alta con otros trabajos"
+)
+
+// Estructura del resultado de la autoverificación (no revela nada del corpus)
+// - si se detectó una similitud alta y mensaje para el estudiante
+type ResultadoAutoverificacionJSON struct {
+	SimilitudAlta bool   `json:"similitud_alta"`
+	Mensaje       string `json:"mensaje"`
+}
+
+// Estructura del corpus de la autoverificación
+// - motor de características y preprocesamiento del contenido
+// - características de los archivos del corpus (sin contenido, nombres ni tokens)
+// - hashes SHA-256 del contenido preprocesado de los archivos del corpus
+// - distancia máxima a partir de la cual no se considera una similitud alta
+type CorpusAutoverificacion struct {
+	motor            Motor
+	preprocesamiento Preprocesamiento
+	archivos         []CodigoFuente
+	huellas          map[[sha256.Size]byte]bool
+	limite           float64
+}
+
+/*
+ * Función para crear el corpus de la autoverificación con los archivos del directorio indicado
+ * param: parámetros de ejecución, el preprocesamiento y el formato de las rutas
+ * return: el corpus o un error si el motor no se puede crear o el directorio no tiene archivos de la extensión
+ */
+func crearCorpusAutoverificacion(parametros Parametros, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas) (*CorpusAutoverificacion, error) {
+	motor, _, err := crearMotorRobustez(parametros, parametros.motor)
+	if err != nil {
+		return nil, err
+	}
+
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		return nil, err
+	}
+	listado, archivosZip, err := obtenerListadoDirectorio(parametros.corpusVerificacion, parametros.extension, limites)
+	if err != nil {
+		return nil, fmt.Errorf("El directorio de la autoverificación (--self-check) no se puede leer: %v", err)
+	}
+	if len(listado) == 0 {
+		return nil, fmt.Errorf("El directorio de la autoverificación (--self-check) no tiene archivos de extensión .%s", parametros.extension)
+	}
+
+	corpus := &CorpusAutoverificacion{motor: motor, preprocesamiento: preprocesamiento, huellas: make(map[[sha256.Size]byte]bool)}
+	preprocesamiento.archivosZip = archivosZip
+	corpus.archivos = determinarCaracteristicas(listado, motor, preprocesamiento, formatoRutas, parametros.trabajadores)
+	for i := range corpus.archivos {
+		corpus.huellas[sha256.Sum256(corpus.archivos[i].contenido)] = true
+		corpus.archivos[i] = CodigoFuente{caracteristica: corpus.archivos[i].caracteristica, ngramas: corpus.archivos[i].ngramas, proyeccion: corpus.archivos[i].proyeccion, lineas: corpus.archivos[i].lineas, simhash: corpus.archivos[i].simhash}
+	}
+
+	criterio := parametros.criterioDistancia
+	if !criterio.definido() {
+		criterio = CriterioDistancia{criterio: CRITERIO_AUTOMATICO}
+	}
+	corpus.limite = criterio.resolver(corpus.obtenerDistribucion()).limite()
+
+	return corpus, nil
+}
+
+/*
+ * Función para obtener la distribución de las distancias entre los archivos del corpus (solo con el criterio "auto")
+ * return: distancias de todos los pares, ordenadas de forma ascendente
+ */
+func (corpus *CorpusAutoverificacion) obtenerDistribucion() []float64 {
+	var distribucion []float64
+	for i := range corpus.archivos {
+		for j := i + 1; j < len(corpus.archivos); j++ {
+			distribucion = append(distribucion, corpus.motor.distancia(corpus.archivos[i], corpus.archivos[j]))
+		}
+	}
+	sort.Float64s(distribucion)
+
+	return distribucion
+}
+
+/*
+ * Función para verificar el archivo de un estudiante contra el corpus
+ * param: contenido del archivo
+ * return: si el archivo es una copia exacta o está a la distancia máxima de algún archivo del corpus
+ */
+func (corpus *CorpusAutoverificacion) verificar(contenido []byte) bool {
+	codigoFuente := caracterizarContenido("", contenido, corpus.motor, corpus.preprocesamiento)
+	if corpus.huellas[sha256.Sum256(codigoFuente.contenido)] {
+		return true
+	}
+
+	for _, archivo := range corpus.archivos {
+		if corpus.motor.distancia(codigoFuente, archivo) <= corpus.limite {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+ * Función para iniciar el servidor restringido de la autoverificación
+ * param: parámetros de ejecución, el preprocesamiento, el formato de las rutas, autorización, límites y registro de
+ *        auditoría (nil si no se requieren) y métricas del servidor
+ * return: un error si el corpus no se puede crear o el servidor no se puede iniciar o se detiene
+ */
+func iniciarAutoverificacion(parametros Parametros, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas, autorizacion *Autorizacion, limites *LimitesServidor, auditoria *Auditoria, metricas *Metricas) error {
+	corpus, err := crearCorpusAutoverificacion(parametros, preprocesamiento, formatoRutas)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Autoverificación con", len(corpus.archivos), "archivos y motor", corpus.motor.nombre())
+
+	return http.ListenAndServe(parametros.direccionServidor, crearRutasAutoverificacion(corpus, autorizacion, limites, auditoria, metricas))
+}
+
+/*
+ * Función para crear las rutas del servidor restringido de la autoverificación
+ * param: corpus de la autoverificación, autorización, límites y registro de auditoría (nil si no se requieren) y
+ *        métricas del servidor
+ * return: las rutas (POST /v1/self-check y GET /metrics)
+ */
+func crearRutasAutoverificacion(corpus *CorpusAutoverificacion, autorizacion *Autorizacion, limites *LimitesServidor, auditoria *Auditoria, metricas *Metricas) *http.ServeMux {
+	rutas := http.NewServeMux()
+
+	rutas.HandleFunc("/v1/self-check", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		if peticion.Method != http.MethodPost {
+			http.Error(respuesta, "Se debe usar POST con el contenido del archivo", http.StatusMethodNotAllowed)
+			return
+		}
+
+		curso, autorizado := autorizacion.autorizarCurso(respuesta, peticion, limites.requiereCurso())
+		if !autorizado || !limites.permitir(respuesta, peticion, autorizacion, curso) {
+			return
+		}
+
+		registro := RegistroAuditoriaJSON{Accion: ACCION_AUTOVERIFICAR, Identidad: identificarCliente(peticion, autorizacion), Curso: curso, Estado: http.StatusOK}
+
+		contenido, err := ioutil.ReadAll(http.MaxBytesReader(respuesta, peticion.Body, LIMITE_PETICION_AUTOVERIFICACION))
+		if err != nil || strings.TrimSpace(string(contenido)) == "" {
+			metricas.registrarFallo()
+			registro.Estado = http.StatusBadRequest
+			if !auditoria.registrarPeticion(respuesta, peticion, registro) {
+				return
+			}
+			http.Error(respuesta, "Se debe enviar el contenido del archivo (hasta 1 MB)", http.StatusBadRequest)
+			return
+		}
+
+		resultado := ResultadoAutoverificacionJSON{Mensaje: MENSAJE_SIMILITUD_BAJA}
+		if corpus.verificar(contenido) {
+			resultado = ResultadoAutoverificacionJSON{SimilitudAlta: true, Mensaje: MENSAJE_SIMILITUD_ALTA}
+		}
+		metricas.registrarAutoverificacion()
+		if !auditoria.registrarPeticion(respuesta, peticion, registro) {
+			return
+		}
+
+		responderJSON(respuesta, http.StatusOK, resultado)
+	})
+
+	rutas.HandleFunc("/metrics", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		respuesta.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		metricas.escribir(respuesta)
+	})
+
+	return rutas
+}
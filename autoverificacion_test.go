@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/*
+ * Función para crear un servidor de autoverificación de prueba con un corpus generado
+ * param: prueba y parámetros de ejecución
+ * return: el servidor (se cierra al terminar la prueba) y el contenido de los archivos del corpus por su ruta
+ */
+func crearServidorAutoverificacionPrueba(t *testing.T, parametros Parametros) (*httptest.Server, map[string]string) {
+	t.Helper()
+
+	archivos := crearCorpusFamiliasPrueba(6, 1)
+	parametros.corpusVerificacion = crearCorpusPrueba(t, archivos)
+
+	preprocesamiento, err := crearPreprocesamiento(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatoRutas, err := crearFormatoRutas(parametros, parametros.corpusVerificacion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corpus, err := crearCorpusAutoverificacion(parametros, preprocesamiento, formatoRutas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	servidor := httptest.NewServer(crearRutasAutoverificacion(corpus, nil, nil, nil, crearMetricas()))
+	t.Cleanup(servidor.Close)
+
+	return servidor, archivos
+}
+
+func TestCorpusAutoverificacionSinArchivos(t *testing.T) {
+	parametros := crearParametrosPrueba()
+	parametros.corpusVerificacion = crearCorpusPrueba(t, map[string]string{"a/main.java": "class A {}\n"})
+
+	preprocesamiento, err := crearPreprocesamiento(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatoRutas, err := crearFormatoRutas(parametros, parametros.corpusVerificacion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = crearCorpusAutoverificacion(parametros, preprocesamiento, formatoRutas); err == nil {
+		t.Error("corpus sin archivos de la extensión sin error")
+	}
+}
+
+func TestServidorAutoverificacion(t *testing.T) {
+	parametros := crearParametrosPrueba()
+	parametros.criterioDistancia = crearCriterioValor(10)
+	servidor, archivos := crearServidorAutoverificacionPrueba(t, parametros)
+	direccion := servidor.URL + "/v1/self-check"
+
+	// El corpus tiene la primera variante de cada familia, la segunda cambia una línea
+	variantes := crearCorpusFamiliasPrueba(6, 2)
+
+	casos := []struct {
+		nombre, contenido string
+		similitudAlta     bool
+	}{
+		{"copia exacta", archivos["e3_0/main.go"], true},
+		{"copia con una línea cambiada", variantes["e3_1/main.go"], true},
+		{"archivo propio", "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hola\")\n}\n", false},
+	}
+
+	for _, caso := range casos {
+		estado, cuerpo := enviarPeticionPrueba(t, http.MethodPost, direccion, strings.NewReader(caso.contenido))
+		if estado != http.StatusOK {
+			t.Fatalf("%s: estado %d: %s", caso.nombre, estado, cuerpo)
+		}
+
+		var resultado ResultadoAutoverificacionJSON
+		if err := json.Unmarshal([]byte(cuerpo), &resultado); err != nil {
+			t.Fatal(err)
+		}
+		if resultado.SimilitudAlta != caso.similitudAlta {
+			t.Errorf("%s: similitud alta = %v, se esperaba %v", caso.nombre, resultado.SimilitudAlta, caso.similitudAlta)
+		}
+
+		// La respuesta no revela nombres, rutas ni distancias del corpus
+		if strings.Contains(cuerpo, "e3_0") || strings.Contains(cuerpo, "distancia") {
+			t.Errorf("%s: la respuesta revela el corpus: %s", caso.nombre, cuerpo)
+		}
+	}
+}
+
+func TestServidorAutoverificacionPeticionNoValida(t *testing.T) {
+	servidor, _ := crearServidorAutoverificacionPrueba(t, crearParametrosPrueba())
+	direccion := servidor.URL + "/v1/self-check"
+
+	casos := []struct {
+		nombre, metodo, cuerpo string
+		estado                 int
+	}{
+		{"GET", http.MethodGet, "", http.StatusMethodNotAllowed},
+		{"archivo vacío", http.MethodPost, " \n\t", http.StatusBadRequest},
+		{"más del tamaño máximo", http.MethodPost, strings.Repeat("a", LIMITE_PETICION_AUTOVERIFICACION+1), http.StatusBadRequest},
+	}
+
+	for _, caso := range casos {
+		if estado, cuerpo := enviarPeticionPrueba(t, caso.metodo, direccion, strings.NewReader(caso.cuerpo)); estado != caso.estado {
+			t.Errorf("%s: estado %d, se esperaba %d: %s", caso.nombre, estado, caso.estado, cuerpo)
+		}
+	}
+
+	// Solamente se atienden la autoverificación y las métricas
+	for _, ruta := range []string{"/analyze", "/jobs", "/v1/similarity"} {
+		if estado, _ := enviarPeticionPrueba(t, http.MethodPost, servidor.URL+ruta, strings.NewReader("{}")); estado != http.StatusNotFound {
+			t.Errorf("%s: estado %d en el servidor de autoverificación, se esperaba 404", ruta, estado)
+		}
+	}
+}
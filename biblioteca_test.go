@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jugutier73/SASC/pkg/sasc"
+)
+
+func TestBibliotecaIgualLineaComandos(t *testing.T) {
+	archivos := crearCorpusFamiliasPrueba(3, 3)
+	archivos["vacio/main.go"] = ""
+
+	parametros := crearParametrosPrueba()
+	parametros.criterioDistancia = crearCriterioValor(40)
+	parametros.minimoContenido = 0
+
+	resultado := analizarCorpusPrueba(t, parametros, archivos)
+	biblioteca, err := sasc.Analyze(sasc.Options{Directory: ".", Extension: "go", Group: true, MaxDistance: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(biblioteca.Matrix.Files) != len(resultado.archivos) {
+		t.Fatalf("archivos de la biblioteca = %v, de la línea de comandos = %d", biblioteca.Matrix.Files, len(resultado.archivos))
+	}
+	for i := range resultado.archivos {
+		if biblioteca.Matrix.Files[i] != resultado.archivos[i].nombre {
+			t.Fatalf("archivo %d: %s en la biblioteca, %s en la línea de comandos", i, biblioteca.Matrix.Files[i], resultado.archivos[i].nombre)
+		}
+		for j := range resultado.archivos {
+			if biblioteca.Matrix.Distance(i, j) != resultado.matriz[i][j] {
+				t.Errorf("distancia %d-%d = %v en la biblioteca, %v en la línea de comandos", i, j, biblioteca.Matrix.Distance(i, j), resultado.matriz[i][j])
+			}
+		}
+	}
+
+	if len(biblioteca.Groups) != len(resultado.grupos) || len(resultado.grupos) == 0 {
+		t.Fatalf("grupos = %d en la biblioteca, %d en la línea de comandos", len(biblioteca.Groups), len(resultado.grupos))
+	}
+	for g, grupo := range biblioteca.Groups {
+		if grupo.ID != resultado.grupos[g].identificador || len(grupo.Members) != len(resultado.grupos[g].integrantes) {
+			t.Errorf("grupo %d: %s con %d integrantes en la biblioteca, %s con %d en la línea de comandos", g, grupo.ID, len(grupo.Members), resultado.grupos[g].identificador, len(resultado.grupos[g].integrantes))
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * Caché de resultados por huella del corpus.
+ *
+ * La huella se calcula con el motor de características, el preprocesamiento y la lista ordenada de pares
+ * (ruta, hash del contenido) de los archivos a analizar. Si un corpus idéntico ya se analizó con la misma
+ * configuración, la matriz de distancias se carga desde la caché (--cache-dir) sin calcular las características
+ * ni las distancias, y solamente se generan los reportes solicitados. Con --encryption-key las entradas se guardan
+ * cifradas (ver cifrado.go).
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Versión del formato de la caché, hace parte de la huella para descartar cachés de versiones anteriores
+const VERSION_CACHE = 1
+
+// Estructura de los resultados guardados en la caché
+// - huella del corpus
+// - rutas de los archivos analizados (en el orden de la matriz)
+// - matriz de distancias entre los archivos
+type ResultadosCache struct {
+	Huella     string      `json:"huella"`
+	Archivos   []string    `json:"archivos"`
+	Distancias [][]float64 `json:"distancias"`
+}
+
+/*
+ * Función para calcular la huella de un corpus con la configuración del análisis
+ * param: listado de archivos a analizar (en orden), el motor a emplear, los parámetros de ejecución y el contenido de
+ *        los .zip del listado
+ * return: huella hexadecimal (SHA-256)
+ */
+func calcularHuellaCorpus(listado []string, motor Motor, parametros Parametros, archivosZip ArchivosComprimidos) string {
+	huella := sha256.New()
+
+	fmt.Fprintf(huella, "%d\n%s\n%d\n%s\n", VERSION_CACHE, motor.nombre(), parametros.lineasEncabezado, parametros.expresionEncabezado)
+	if parametros.sinComentarios {
+		fmt.Fprintln(huella, "sin comentarios")
+	}
+	if parametros.sinIdentificadores || parametros.minusculas || parametros.colapsarEspacios {
+		fmt.Fprintf(huella, "identificadores %t minúsculas %t espacios %t\n", parametros.sinIdentificadores, parametros.minusculas, parametros.colapsarEspacios)
+	}
+	if parametros.directorioPlantilla != "" {
+		// Los límites ya se validaron al leer el código base
+		limites, _ := crearLimitesExtraccion(parametros)
+		fmt.Fprintln(huella, "plantilla", calcularHuellaPlantilla(parametros.directorioPlantilla, parametros.extension, limites))
+	}
+	if parametros.modoPares == PARES_LSH {
+		// Las distancias de los pares que no son candidatos no se calcularon
+		fmt.Fprintf(huella, "%s %d %d\n", PARES_LSH, parametros.bandasLSH, parametros.filasLSH)
+	}
+
+	for _, archivo := range listado {
+		contenido, err := archivosZip.leer(archivo)
+		if err != nil {
+			panic(err)
+		}
+
+		hashContenido := sha256.Sum256(contenido)
+		fmt.Fprintf(huella, "%s\x00%x\n", archivo, hashContenido)
+	}
+
+	return hex.EncodeToString(huella.Sum(nil))
+}
+
+/*
+ * Función para obtener el nombre del archivo de la caché de una huella
+ * param: directorio de la caché y huella del corpus
+ * return: ruta del archivo de la caché
+ */
+func obtenerArchivoCache(directorioCache string, huella string) string {
+	return filepath.Join(directorioCache, huella+".json")
+}
+
+/*
+ * Función para cargar los resultados de la caché
+ * param: directorio de la caché, huella del corpus, listado de archivos a analizar y cifrado (nil si no se cifra)
+ * return: los resultados y si se encontraron (no se usan si no corresponden al listado o no se pueden descifrar)
+ */
+func cargarResultadosCache(directorioCache string, huella string, listado []string, cifrado *Cifrado) (ResultadosCache, bool) {
+	var resultados ResultadosCache
+
+	contenido, err := cifrado.leerArchivo(obtenerArchivoCache(directorioCache, huella))
+	if err != nil {
+		return resultados, false
+	}
+
+	if err = json.Unmarshal(contenido, &resultados); err != nil || resultados.Huella != huella ||
+		len(resultados.Archivos) != len(listado) || len(resultados.Distancias) != len(listado) {
+		return resultados, false
+	}
+
+	for i, archivo := range listado {
+		if resultados.Archivos[i] != archivo || len(resultados.Distancias[i]) != len(listado) {
+			return resultados, false
+		}
+	}
+
+	return resultados, true
+}
+
+/*
+ * Función para guardar la matriz de distancias en la caché
+ * param: directorio de la caché, huella del corpus, arreglo con la información del código fuente de los archivos y
+ *        cifrado (nil si no se cifra)
+ */
+func guardarResultadosCache(directorioCache string, huella string, tablaCodigoFuente []CodigoFuente, cifrado *Cifrado) {
+	// Una entrada cifrada no se reemplaza por una sin cifrar si se ejecuta sin la clave
+	if anterior, err := ioutil.ReadFile(obtenerArchivoCache(directorioCache, huella)); err == nil && estaCifrado(anterior) && cifrado == nil {
+		return
+	}
+
+	resultados := ResultadosCache{Huella: huella}
+
+	for i, archivo := range tablaCodigoFuente {
+		distancias := make([]float64, len(tablaCodigoFuente))
+		for j := range tablaCodigoFuente {
+			distancias[j] = obtenerDistancia(archivo, j)
+		}
+
+		resultados.Archivos = append(resultados.Archivos, tablaCodigoFuente[i].ruta)
+		resultados.Distancias = append(resultados.Distancias, distancias)
+	}
+
+	contenido, err := json.Marshal(resultados)
+	if err != nil {
+		panic(err)
+	}
+
+	if err = os.MkdirAll(directorioCache, 0755); err != nil {
+		panic(err)
+	}
+
+	if err = cifrado.escribirArchivo(obtenerArchivoCache(directorioCache, huella), contenido, 0644); err != nil {
+		panic(err)
+	}
+}
+
+/*
+ * Función para leer los archivos y asignarles las distancias guardadas en la caché (sin calcular características)
+ * param: listado de archivos, el preprocesamiento del contenido, el formato de las rutas, los resultados de la caché
+ *        y si las distancias se almacenan en float32
+ * return: arreglo con la información de todos los archivos y sus distancias
+ */
+func cargarCodigoFuenteCache(listado []string, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas, resultados ResultadosCache, reducida bool) []CodigoFuente {
+	var tablaCodigoFuente []CodigoFuente
+
+	for _, archivo := range listado {
+		codigoFuente := leerCodigoFuente(archivo, preprocesamiento)
+		codigoFuente.nombre = formatoRutas.formatear(archivo)
+
+		tablaCodigoFuente = append(tablaCodigoFuente, codigoFuente)
+	}
+	crearTablasDistancias(tablaCodigoFuente, reducida)
+
+	for i := range tablaCodigoFuente {
+		for j, distancia := range resultados.Distancias[i] {
+			asignarValorDistancia(&tablaCodigoFuente[i], j, distancia)
+		}
+	}
+
+	return tablaCodigoFuente
+}
@@ -0,0 +1,284 @@
+/*
+ * Calibración de la distancia máxima con archivos de control (comando calibrate).
+ *
+ * Una distancia máxima (--max-distance o --threshold=auto) se elige por intuición y no se sabe cuántas entregas
+ * independientes agruparía por error. El comando calibrate mezcla en el corpus archivos de control que se sabe que
+ * son independientes de las entregas (--holdout=directorio, por ejemplo soluciones de la misma tarea en otro curso u
+ * otra universidad) y reporta a partir de qué distancia empiezan a quedar agrupados con alguna entrega:
+ * - en cada ronda se toma una muestra aleatoria de --holdout-sample archivos de control (todos si es 0) y se registra
+ *   la menor distancia de un archivo de la muestra a una entrega (a partir de ella hay un falso positivo) y la
+ *   proporción de la muestra a la distancia máxima de alguna entrega (los agrupados por error)
+ * - las rondas (--calibration-rounds, con la semilla --calibration-seed) muestran la variación de esas cifras según
+ *   los archivos de control que se tomen
+ * - las distancias sugeridas son las mayores con las que queda agrupado por error a lo sumo el 0%, 1%, 5% o 10% de
+ *   los archivos de control, con la cantidad de pares de entregas que quedan agrupados con cada una
+ * Un archivo queda en el grupo de otro cuando están a la distancia máxima (ver determinarGrupos), por lo que un
+ * archivo de control queda agrupado por error si alguna entrega está a la distancia máxima de él. Los pares entre
+ * archivos de control no cuentan (pueden ser copias entre ellos). El criterio automático se calcula solo con las
+ * distancias entre las entregas, como en el análisis sin archivos de control.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Cantidad de rondas por defecto de la calibración con muestras de los archivos de control
+const RONDAS_CALIBRACION = 20
+
+// Proporciones de archivos de control agrupados por error de las distancias sugeridas
+var tasasCalibracion = []float64{0, 0.01, 0.05, 0.10}
+
+// Cantidad de archivos de control más cercanos a las entregas que se listan
+const CONTROLES_CERCANOS = 5
+
+// Estructura de la distancia de un archivo de control a la entrega más cercana
+// - índice del archivo de control y de la entrega más cercana en la tabla
+// - distancia entre ellos
+type ControlCalibracion struct {
+	control   int
+	entrega   int
+	distancia float64
+}
+
+// Estructura del resultado de una ronda de la calibración
+// - menor distancia de un archivo de control de la muestra a una entrega
+// - proporción de la muestra agrupada por error con la distancia máxima
+type RondaCalibracion struct {
+	primerFalso float64
+	agrupados   float64
+}
+
+/*
+ * Función para obtener el listado de los archivos de control
+ * param: directorio de los archivos de control, extensión y límites de la extracción de los .zip
+ * return: rutas de los archivos de control y contenido de los archivos de los .zip, o un error si el directorio no
+ *         existe, un .zip no es válido o no tiene archivos
+ */
+func obtenerListadoControl(directorio string, extension string, limites LimitesExtraccion) ([]string, ArchivosComprimidos, error) {
+	absoluto, err := filepath.Abs(directorio)
+	if err != nil {
+		return nil, nil, err
+	}
+	if informacion, err := os.Stat(absoluto); err != nil || !informacion.IsDir() {
+		return nil, nil, fmt.Errorf("El directorio de los archivos de control (--holdout) \"%s\" no existe", directorio)
+	}
+
+	listado, archivosZip, err := obtenerListadoDirectorio(absoluto, extension, limites)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Los archivos de control (--holdout) no se pueden leer: %v", err)
+	}
+	if len(listado) == 0 {
+		return nil, nil, fmt.Errorf("El directorio de los archivos de control (--holdout) \"%s\" no tiene archivos de extensión .%s", directorio, extension)
+	}
+
+	return listado, archivosZip, nil
+}
+
+/*
+ * Función para determinar la entrega más cercana a cada archivo de control
+ * param: tabla con las entregas seguidas de los archivos de control (con sus distancias) y cantidad de entregas
+ * return: la distancia de cada archivo de control a la entrega más cercana, en el orden de la tabla
+ */
+func determinarControlesCercanos(tablaCodigoFuente []CodigoFuente, cantidadEntregas int) []ControlCalibracion {
+	var controles []ControlCalibracion
+
+	for c := cantidadEntregas; c < len(tablaCodigoFuente); c++ {
+		cercano := ControlCalibracion{control: c, entrega: -1, distancia: math.Inf(1)}
+		for e := 0; e < cantidadEntregas; e++ {
+			if distancia := obtenerDistancia(tablaCodigoFuente[c], e); distancia < cercano.distancia {
+				cercano.entrega, cercano.distancia = e, distancia
+			}
+		}
+		controles = append(controles, cercano)
+	}
+
+	return controles
+}
+
+/*
+ * Función para ejecutar las rondas de la calibración
+ * param: distancia de cada archivo de control a la entrega más cercana, tamaño de la muestra (0 para todos),
+ *        cantidad de rondas, semilla y distancia máxima
+ * return: el resultado de cada ronda (una sola si la muestra incluye todos los archivos de control)
+ */
+func ejecutarRondasCalibracion(controles []ControlCalibracion, muestra int, rondas int, semilla int64, limite float64) []RondaCalibracion {
+	if muestra <= 0 || muestra >= len(controles) {
+		muestra, rondas = len(controles), 1
+	}
+
+	aleatorio := rand.New(rand.NewSource(semilla))
+	resultados := make([]RondaCalibracion, rondas)
+
+	for r := range resultados {
+		indices := aleatorio.Perm(len(controles))[:muestra]
+
+		primerFalso, agrupados := math.Inf(1), 0
+		for _, k := range indices {
+			primerFalso = math.Min(primerFalso, controles[k].distancia)
+			if controles[k].distancia <= limite {
+				agrupados++
+			}
+		}
+
+		resultados[r] = RondaCalibracion{primerFalso: primerFalso, agrupados: float64(agrupados) / float64(muestra)}
+	}
+
+	return resultados
+}
+
+/*
+ * Función para contar los pares de entregas a una distancia máxima
+ * param: distribución ordenada de las distancias entre las entregas y la distancia máxima
+ * return: cantidad de pares a la distancia máxima o menos
+ */
+func contarParesDistancia(distribucion []float64, limite float64) int {
+	return sort.Search(len(distribucion), func(i int) bool {
+		return distribucion[i] > limite
+	})
+}
+
+/*
+ * Función para imprimir el resultado de la calibración
+ * param: tabla con las entregas y los archivos de control, cantidad de entregas, distancia de cada archivo de control
+ *        a la entrega más cercana, resultado de las rondas, distribución de las distancias entre las entregas y
+ *        criterio de la distancia máxima (resuelto)
+ */
+func imprimirCalibracion(tablaCodigoFuente []CodigoFuente, cantidadEntregas int, controles []ControlCalibracion, rondas []RondaCalibracion, distribucion []float64, criterio CriterioDistancia) {
+	primeros := make([]float64, len(rondas))
+	agrupados := 0.0
+	for r, ronda := range rondas {
+		primeros[r] = ronda.primerFalso
+		agrupados += ronda.agrupados
+	}
+	sort.Float64s(primeros)
+
+	fmt.Println()
+	fmt.Println("CALIBRACIÓN DE LA DISTANCIA MÁXIMA CON", len(controles), "ARCHIVOS DE CONTROL (INDEPENDIENTES DE LAS", cantidadEntregas, "ENTREGAS)")
+	fmt.Println()
+
+	if len(rondas) > 1 {
+		fmt.Printf("Primer archivo de control agrupado por error (%d rondas): desde la distancia %.2f (mínima), %.2f (mediana), %.2f (máxima)\n", len(rondas), primeros[0], calcularCuantil(primeros, 0.5), primeros[len(primeros)-1])
+	} else {
+		fmt.Printf("Primer archivo de control agrupado por error: desde la distancia %.2f\n", primeros[0])
+	}
+
+	if criterio.definido() {
+		limite := criterio.limite()
+		fmt.Printf("Distancia máxima %s: agrupa por error el %.1f%% de los archivos de control y agrupa %d pares de entregas\n", criterio.describir(), 100*agrupados/float64(len(rondas)), contarParesDistancia(distribucion, limite))
+		if limite < primeros[0] {
+			fmt.Println("             ningún archivo de control queda agrupado por error con esta distancia máxima")
+		}
+	}
+
+	cercanias := make([]float64, len(controles))
+	for k, control := range controles {
+		cercanias[k] = control.distancia
+	}
+	sort.Float64s(cercanias)
+
+	fmt.Println()
+	fmt.Println("DISTANCIAS MÁXIMAS SUGERIDAS (MENORES QUE LA DISTANCIA INDICADA, CON LOS ARCHIVOS DE CONTROL AGRUPADOS POR ERROR)")
+	fmt.Println()
+	for _, tasa := range tasasCalibracion {
+		// Con una distancia máxima menor que la de este archivo de control quedan agrupados los anteriores
+		permitidos := int(math.Floor(tasa * float64(len(cercanias))))
+		if permitidos >= len(cercanias) {
+			continue
+		}
+		fmt.Printf("	< %8.2f  hasta el %3.0f%% (%d de %d archivos de control), %d pares de entregas\n", cercanias[permitidos], 100*tasa, permitidos, len(cercanias), contarParesDistancia(distribucion, math.Nextafter(cercanias[permitidos], math.Inf(-1))))
+	}
+
+	sort.SliceStable(controles, func(i, j int) bool {
+		return controles[i].distancia < controles[j].distancia
+	})
+
+	fmt.Println()
+	fmt.Println("ARCHIVOS DE CONTROL MÁS CERCANOS A LAS ENTREGAS")
+	fmt.Println()
+	for k := 0; k < len(controles) && k < CONTROLES_CERCANOS; k++ {
+		fmt.Printf("\t%8.2f %s - %s\n", controles[k].distancia, tablaCodigoFuente[controles[k].control].nombre, tablaCodigoFuente[controles[k].entrega].nombre)
+	}
+	fmt.Println()
+}
+
+/*
+ * Función para calibrar la distancia máxima con los archivos de control (comando calibrate)
+ * param: parámetros de ejecución, el motor, el preprocesamiento, el formato de las rutas y el directorio de las entregas
+ * return: un error si los archivos de control no se pueden obtener o no hay entregas
+ */
+func calibrarDistanciaMaxima(parametros Parametros, motor Motor, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas, directorioActual string) error {
+	if parametros.muestraControl < 0 || parametros.rondasCalibracion < 1 {
+		return fmt.Errorf("La muestra de los archivos de control (--holdout-sample) no puede ser negativa y las rondas (--calibration-rounds) deben ser al menos 1")
+	}
+
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		return err
+	}
+	entregas, archivosZip, omitidos, err := obtenerListadoEntregas(directorioActual, parametros.extension, limites)
+	if err != nil {
+		return fmt.Errorf("Error al obtener el listado de los programas: %v", err)
+	}
+	imprimirZipOmitidos(omitidos, formatoRutas)
+	controles, archivosZipControl, err := obtenerListadoControl(parametros.directorioControl, parametros.extension, limites)
+	if err != nil {
+		return err
+	}
+
+	// Las rutas de los archivos de control son absolutas y no coinciden con las de las entregas
+	for ruta, contenido := range archivosZipControl {
+		archivosZip[ruta] = contenido
+	}
+	preprocesamiento.archivosZip = archivosZip
+
+	if parametros.minimoContenido > 0 {
+		entregas, _ = filtrarArchivosSinContenido(entregas, parametros.extension, parametros.minimoContenido, preprocesamiento)
+		controles, _ = filtrarArchivosSinContenido(controles, parametros.extension, parametros.minimoContenido, preprocesamiento)
+	}
+	if len(entregas) < 2 || len(controles) == 0 {
+		return fmt.Errorf("La calibración requiere al menos dos entregas y un archivo de control con contenido")
+	}
+
+	fmt.Println("Calibrando con", len(entregas), "entregas de extensión ."+parametros.extension+" en", directorioActual, "y", len(controles), "archivos de control en", parametros.directorioControl)
+	fmt.Println("Motor de características:", motor.nombre())
+	fmt.Println()
+
+	fmt.Println("Fase 1 de 3: Calculando características de cada archivo...")
+	tablaCodigoFuente := determinarCaracteristicas(append(entregas, controles...), motor, preprocesamiento, formatoRutas, parametros.trabajadores)
+	for c := len(entregas); c < len(tablaCodigoFuente); c++ {
+		tablaCodigoFuente[c].nombre = "[control] " + obtenerRutaRelativa(parametros.directorioControl, controles[c-len(entregas)])
+	}
+	if proyeccion := proyectarCorpus(tablaCodigoFuente, motor); proyeccion != nil {
+		fmt.Println("             proyección:", describirProyeccion(proyeccion))
+	}
+
+	fmt.Println("Fase 2 de 3: Calculando distancia entre los archivos...")
+	tablaCodigoFuente = determinarDistanciasEntreArchivos(tablaCodigoFuente, motor, parametros, nil)
+
+	fmt.Println("Fase 3 de 3: Comparando los archivos de control con las entregas...")
+	var distribucion []float64
+	for i := range entregas {
+		for j := i + 1; j < len(entregas); j++ {
+			distribucion = append(distribucion, obtenerDistancia(tablaCodigoFuente[i], j))
+		}
+	}
+	sort.Float64s(distribucion)
+	criterio := parametros.criterioDistancia.resolver(distribucion)
+
+	cercanos := determinarControlesCercanos(tablaCodigoFuente, len(entregas))
+	rondas := ejecutarRondasCalibracion(cercanos, parametros.muestraControl, parametros.rondasCalibracion, parametros.semillaCalibracion, criterio.limite())
+	if len(rondas) > 1 {
+		fmt.Println("             muestras de", parametros.muestraControl, "archivos de control en", len(rondas), "rondas (semilla", fmt.Sprint(parametros.semillaCalibracion)+")")
+	}
+
+	imprimirCalibracion(tablaCodigoFuente, len(entregas), cercanos, rondas, distribucion, criterio)
+
+	return nil
+}
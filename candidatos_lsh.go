@@ -0,0 +1,338 @@
+/*
+ * Pares candidatos por MinHash y LSH (--pairs=lsh).
+ *
+ * Con miles de archivos el cálculo de todas las distancias (n²/2 pares) domina el tiempo del análisis, aunque casi
+ * todos los pares están lejos. En el modo aproximado solo se calcula la distancia exacta (con el motor indicado) de los
+ * pares candidatos:
+ * - cada archivo se representa por el conjunto de sus tejas (ventanas de LONGITUD_TEJA_LSH tokens consecutivos)
+ * - su firma MinHash tiene --lsh-bands * --lsh-rows valores, el mínimo del hash de sus tejas con cada función
+ *   (la probabilidad de que dos firmas coincidan en un valor es la similitud de Jaccard de sus conjuntos)
+ * - la firma se divide en --lsh-bands bandas de --lsh-rows valores y dos archivos son candidatos si coinciden en
+ *   todos los valores de al menos una banda, lo que ocurre con probabilidad 1 - (1 - J^filas)^bandas
+ * Con los valores por defecto (32 bandas de 4 filas) un par con Jaccard 0.5 es candidato con probabilidad 0.87 y uno
+ * con Jaccard 0.7 con probabilidad 0.9998; más bandas o menos filas encuentran más pares y calculan más distancias.
+ *
+ * Los pares que no son candidatos quedan con la distancia DISTANCIA_NO_CALCULADA, por encima de cualquier distancia
+ * máxima, y el modo requiere una distancia máxima definida por el usuario (--max-distance o --threshold=N), ya que
+ * los reportes solo son completos para los pares a esa distancia. Para que los grupos sean exactos (medoide, diámetro
+ * y par ejemplar) se calculan además todas las distancias entre los archivos conectados por pares candidatos a la
+ * distancia máxima (o a la de --merge-groups y --thresholds, si es mayor). Los demás pares no tienen distancia en
+ * ningún reporte: se omiten de las listas de pares, vecinos, percentiles, reglas y resúmenes, se imprimen como "-",
+ * quedan vacíos en el archivo CSV y son null en el resultado JSON y en el flujo de resultados. La matriz sigue
+ * ocupando n² celdas (ver --matrix-precision); --squared y --gram no aplican en este modo.
+ * --pairs=exact (por defecto) calcula todas las distancias.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Modos del cálculo de los pares
+const (
+	PARES_EXACTO = "exact"
+	PARES_LSH    = "lsh"
+)
+
+// Cantidad de tokens de cada teja de la firma MinHash
+const LONGITUD_TEJA_LSH = 5
+
+// Distancia de los pares que no son candidatos (también se representa exactamente en la matriz de float32)
+const DISTANCIA_NO_CALCULADA = math.MaxFloat32
+
+// Fila de distancias del resultado JSON y del flujo de resultados (las distancias no calculadas se escriben como null)
+type DistanciasJSON []float64
+
+/*
+ * Función para determinar si la distancia de un par fue calculada (en el modo aproximado solo la de los candidatos)
+ * param: distancia del par
+ * return: si la distancia fue calculada
+ */
+func esDistanciaCalculada(distancia float64) bool {
+	return distancia != DISTANCIA_NO_CALCULADA
+}
+
+/*
+ * Función para formatear una distancia en los reportes de texto
+ * param: distancia del par
+ * return: la distancia con 2 decimales o "-" si no fue calculada
+ */
+func formatearDistancia(distancia float64) string {
+	if !esDistanciaCalculada(distancia) {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.2f", distancia)
+}
+
+/*
+ * Función para codificar una fila de distancias en JSON, con null en las distancias no calculadas
+ * return: la fila codificada
+ */
+func (distancias DistanciasJSON) MarshalJSON() ([]byte, error) {
+	valores := make([]*float64, len(distancias))
+	for j := range distancias {
+		if esDistanciaCalculada(distancias[j]) {
+			valores[j] = &distancias[j]
+		}
+	}
+
+	return json.Marshal(valores)
+}
+
+/*
+ * Función para decodificar una fila de distancias en JSON, con DISTANCIA_NO_CALCULADA en los null
+ * param: fila codificada
+ * return: un error si la fila no es una lista de números o null
+ */
+func (distancias *DistanciasJSON) UnmarshalJSON(contenido []byte) error {
+	var valores []*float64
+	if err := json.Unmarshal(contenido, &valores); err != nil {
+		return err
+	}
+
+	*distancias = make(DistanciasJSON, len(valores))
+	for j, valor := range valores {
+		(*distancias)[j] = DISTANCIA_NO_CALCULADA
+		if valor != nil {
+			(*distancias)[j] = *valor
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Función para obtener la mayor distancia de los grupos del análisis (distancia máxima, de fusión y de los niveles)
+ * param: parámetros de ejecución
+ * return: la mayor distancia a la que se pueden formar grupos
+ */
+func obtenerDistanciaGruposLSH(parametros Parametros) float64 {
+	distancia := parametros.criterioDistancia.limite()
+	if math.IsInf(distancia, 1) {
+		distancia = -1
+	}
+
+	distancia = math.Max(distancia, parametros.distanciaFusion)
+	for _, umbral := range parametros.umbrales {
+		distancia = math.Max(distancia, umbral)
+	}
+
+	return distancia
+}
+
+/*
+ * Función para calcular todas las distancias entre los archivos conectados por pares calculados a una distancia
+ * máxima (las componentes conexas en las que se forman los grupos), para que los grupos sean exactos
+ * param: arreglo de la información de todos los archivos (con las distancias de los candidatos), función de distancia
+ *        y distancia máxima de los grupos (negativa si no hay grupos)
+ * return: cantidad de distancias calculadas
+ */
+func completarDistanciasComponentes(tablaCodigoFuente []CodigoFuente, distancia func(CodigoFuente, CodigoFuente) float64, distanciaMaxima float64) int {
+	if distanciaMaxima < 0 {
+		return 0
+	}
+
+	raices := make([]int, len(tablaCodigoFuente))
+	for i := range raices {
+		raices[i] = i
+	}
+	for i := range tablaCodigoFuente {
+		for j := 0; j < i; j++ {
+			if valor := obtenerValorDistancia(tablaCodigoFuente[i], j); esDistanciaCalculada(valor) && valor <= distanciaMaxima {
+				raices[obtenerRaizGrupo(raices, i)] = obtenerRaizGrupo(raices, j)
+			}
+		}
+	}
+
+	componentes := make(map[int][]int)
+	for i := range tablaCodigoFuente {
+		raiz := obtenerRaizGrupo(raices, i)
+		componentes[raiz] = append(componentes[raiz], i)
+	}
+
+	calculadas := 0
+	for _, componente := range componentes {
+		for a := 1; a < len(componente); a++ {
+			for b := 0; b < a; b++ {
+				i, j := componente[a], componente[b]
+				if esDistanciaCalculada(obtenerValorDistancia(tablaCodigoFuente[i], j)) {
+					continue
+				}
+
+				valor := distancia(tablaCodigoFuente[i], tablaCodigoFuente[j])
+				asignarValorDistancia(&tablaCodigoFuente[i], j, valor)
+				asignarValorDistancia(&tablaCodigoFuente[j], i, valor)
+				calculadas++
+			}
+		}
+	}
+
+	return calculadas
+}
+
+/*
+ * Función para mezclar los bits de un hash (finalizador de SplitMix64)
+ * param: valor a mezclar
+ * return: el valor mezclado
+ */
+func mezclarHash(valor uint64) uint64 {
+	valor += 0x9E3779B97F4A7C15
+	valor = (valor ^ (valor >> 30)) * 0xBF58476D1CE4E5B9
+	valor = (valor ^ (valor >> 27)) * 0x94D049BB133111EB
+
+	return valor ^ (valor >> 31)
+}
+
+/*
+ * Función para calcular la firma MinHash del contenido de un archivo
+ * param: contenido del archivo y cantidad de funciones de hash
+ * return: el mínimo hash de las tejas del archivo con cada función
+ */
+func calcularFirmaMinHash(contenido []byte, cantidad int) []uint64 {
+	firma := make([]uint64, cantidad)
+	for k := range firma {
+		firma[k] = math.MaxUint64
+	}
+
+	// Un archivo más corto que una teja se representa por una sola teja con todos sus tokens
+	tokens := obtenerTokens(contenido)
+	longitud := LONGITUD_TEJA_LSH
+	if len(tokens) < longitud {
+		longitud = len(tokens)
+	}
+	if longitud == 0 {
+		return firma
+	}
+
+	for _, teja := range calcularHashesVentanas(tokens, longitud) {
+		for k := range firma {
+			if valor := mezclarHash(teja ^ mezclarHash(uint64(k))); valor < firma[k] {
+				firma[k] = valor
+			}
+		}
+	}
+
+	return firma
+}
+
+/*
+ * Función para obtener los pares candidatos de un corpus con LSH sobre las firmas MinHash
+ * param: firmas de los archivos, cantidad de bandas y de filas por banda
+ * return: por cada archivo, los índices menores de sus candidatos en orden y sin repetir
+ */
+func obtenerParesCandidatos(firmas [][]uint64, bandas int, filas int) [][]int {
+	candidatos := make([][]int, len(firmas))
+
+	for banda := 0; banda < bandas; banda++ {
+		cubetas := make(map[uint64][]int)
+
+		for i, firma := range firmas {
+			var clave uint64
+			for _, valor := range firma[banda*filas : (banda+1)*filas] {
+				clave = mezclarHash(clave*BASE_RABIN_KARP + valor)
+			}
+			cubetas[clave] = append(cubetas[clave], i)
+		}
+
+		for _, cubeta := range cubetas {
+			for a := 1; a < len(cubeta); a++ {
+				for b := 0; b < a; b++ {
+					candidatos[cubeta[a]] = append(candidatos[cubeta[a]], cubeta[b])
+				}
+			}
+		}
+	}
+
+	for i, fila := range candidatos {
+		sort.Ints(fila)
+		unicos := fila[:0]
+		for k, j := range fila {
+			if k == 0 || j != fila[k-1] {
+				unicos = append(unicos, j)
+			}
+		}
+		candidatos[i] = unicos
+	}
+
+	return candidatos
+}
+
+/*
+ * Función que determina solo las distancias de los pares candidatos por MinHash y LSH
+ * param: arreglo de la información de todos los archivos (con las tablas de distancias creadas), función de distancia,
+ *        parámetros de ejecución (bandas, filas y trabajadores) y flujo de resultados (nil si no se usa)
+ * return: completa la información en el arreglo de código fuente con la distancia de los pares candidatos y de los
+ *         archivos de una misma componente a la distancia de los grupos (DISTANCIA_NO_CALCULADA en los demás)
+ */
+func determinarDistanciasCandidatas(tablaCodigoFuente []CodigoFuente, distancia func(CodigoFuente, CodigoFuente) float64, parametros Parametros, flujo *FlujoResultados) {
+	cantidadArchivos := len(tablaCodigoFuente)
+
+	firmas := make([][]uint64, cantidadArchivos)
+	for i := range tablaCodigoFuente {
+		firmas[i] = calcularFirmaMinHash(tablaCodigoFuente[i].contenido, parametros.bandasLSH*parametros.filasLSH)
+	}
+	candidatos := obtenerParesCandidatos(firmas, parametros.bandasLSH, parametros.filasLSH)
+
+	filas := make(chan int, cantidadArchivos)
+	for i := 0; i < cantidadArchivos; i++ {
+		filas <- i
+	}
+	close(filas)
+
+	// Cada fila escribe sus celdas y las simétricas de las filas anteriores, sin celdas en común con las demás filas
+	var espera sync.WaitGroup
+	for trabajador := 0; trabajador < obtenerCantidadTrabajadores(parametros.trabajadores, cantidadArchivos); trabajador++ {
+		espera.Add(1)
+		go func() {
+			defer espera.Done()
+
+			for i := range filas {
+				siguiente := 0
+				for j := 0; j <= i; j++ {
+					valor := DISTANCIA_NO_CALCULADA
+					if j == i || (siguiente < len(candidatos[i]) && candidatos[i][siguiente] == j) {
+						valor = distancia(tablaCodigoFuente[i], tablaCodigoFuente[j])
+						if j < i {
+							siguiente++
+						}
+					}
+					asignarValorDistancia(&tablaCodigoFuente[i], j, valor)
+					asignarValorDistancia(&tablaCodigoFuente[j], i, valor)
+				}
+			}
+		}()
+	}
+	espera.Wait()
+
+	completarDistanciasComponentes(tablaCodigoFuente, distancia, obtenerDistanciaGruposLSH(parametros))
+
+	for i := 0; i < cantidadArchivos && flujo != nil; i++ {
+		flujo.enviarFila(tablaCodigoFuente, i)
+	}
+}
+
+/*
+ * Función para contar los pares de archivos con la distancia calculada
+ * param: arreglo de la información de todos los archivos
+ * return: cantidad de pares distintos con la distancia calculada y cantidad total de pares
+ */
+func contarParesCalculados(tablaCodigoFuente []CodigoFuente) (int, int) {
+	calculados, total := 0, 0
+
+	for i := range tablaCodigoFuente {
+		for j := 0; j < i; j++ {
+			if obtenerValorDistancia(tablaCodigoFuente[i], j) != DISTANCIA_NO_CALCULADA {
+				calculados++
+			}
+			total++
+		}
+	}
+
+	return calculados, total
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Representaciones de DISTANCIA_NO_CALCULADA que no deben aparecer en ningún reporte
+var textosDistanciaNoCalculada = []string{
+	"340282346638528859811704183484516925440",
+	"3.4028234663852886e+38",
+	"3.4028235e+38",
+}
+
+/*
+ * Función para crear un corpus de varias familias de programas parecidos entre sí y distintos a las demás familias
+ * param: cantidad de familias y de variantes por familia
+ * return: contenido de cada archivo por su ruta relativa (un estudiante por archivo)
+ */
+func crearCorpusFamiliasPrueba(familias int, variantes int) map[string]string {
+	archivos := make(map[string]string)
+
+	for f := 0; f < familias; f++ {
+		var lineas []string
+		for l := 0; l < 30; l++ {
+			lineas = append(lineas, fmt.Sprintf("\tvalor%d_%d := calcular%d(%d, \"%x\")", f, l, (f*31+l*7)%97, f*1000+l*37, (f+1)*(l+3)*7919))
+		}
+
+		for v := 0; v < variantes; v++ {
+			programa := append([]string{}, lineas...)
+			programa[v] = fmt.Sprintf("\tcambio%d := %d", v, v*11)
+			archivos[fmt.Sprintf("e%d_%d/main.go", f, v)] = "package main\n\nfunc main() {\n" + strings.Join(programa, "\n") + "\n}\n"
+		}
+	}
+
+	return archivos
+}
+
+func TestDistanciasJSON(t *testing.T) {
+	distancias := DistanciasJSON{0, 1.5, DISTANCIA_NO_CALCULADA}
+
+	contenido, err := json.Marshal(distancias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contenido) != "[0,1.5,null]" {
+		t.Errorf("JSON = %s, se esperaba [0,1.5,null]", contenido)
+	}
+
+	var leidas DistanciasJSON
+	if err = json.Unmarshal(contenido, &leidas); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(leidas, distancias) {
+		t.Errorf("distancias leídas = %v, se esperaban %v", leidas, distancias)
+	}
+}
+
+func TestFormatearDistancia(t *testing.T) {
+	casos := []struct {
+		distancia float64
+		esperado  string
+	}{
+		{0, "0.00"},
+		{12.345, "12.35"},
+		{DISTANCIA_NO_CALCULADA, "-"},
+	}
+
+	for _, caso := range casos {
+		if obtenido := formatearDistancia(caso.distancia); obtenido != caso.esperado {
+			t.Errorf("formatearDistancia(%v) = %q, se esperaba %q", caso.distancia, obtenido, caso.esperado)
+		}
+	}
+}
+
+/*
+ * Función para crear un programa de sentencias numeradas, distintas entre sí
+ * param: número de la primera sentencia y cantidad de sentencias
+ * return: el contenido del programa
+ */
+func crearSentenciasPrueba(inicio int, cantidad int) string {
+	var sentencias []string
+	for i := inicio; i < inicio+cantidad; i++ {
+		sentencias = append(sentencias, fmt.Sprintf("valor%d = calcular(%d)", i, i*7))
+	}
+
+	return strings.Join(sentencias, "\n")
+}
+
+/*
+ * Función para calcular el índice de Jaccard exacto entre las tejas de dos contenidos
+ * param: dos contenidos
+ * return: tamaño de la intersección sobre tamaño de la unión de sus tejas
+ */
+func calcularJaccardTejasPrueba(contenido1 []byte, contenido2 []byte) float64 {
+	tejas := make(map[uint64]int)
+	for _, teja := range calcularHashesVentanas(obtenerTokens(contenido1), LONGITUD_TEJA_LSH) {
+		tejas[teja] |= 1
+	}
+	for _, teja := range calcularHashesVentanas(obtenerTokens(contenido2), LONGITUD_TEJA_LSH) {
+		tejas[teja] |= 2
+	}
+
+	comunes := 0
+	for _, presente := range tejas {
+		if presente == 3 {
+			comunes++
+		}
+	}
+
+	return float64(comunes) / float64(len(tejas))
+}
+
+func TestCalcularFirmaMinHash(t *testing.T) {
+	base := []byte(crearSentenciasPrueba(0, 200))
+
+	casos := []struct {
+		nombre string
+		otro   []byte
+	}{
+		{"idénticos", base},
+		{"tres cuartas partes en común", []byte(crearSentenciasPrueba(0, 150) + "\n" + crearSentenciasPrueba(1000, 50))},
+		{"la mitad en común", []byte(crearSentenciasPrueba(0, 100) + "\n" + crearSentenciasPrueba(1000, 100))},
+		{"sin relación", []byte(crearSentenciasPrueba(1000, 200))},
+	}
+
+	// La fracción de funciones de hash con el mismo mínimo estima el índice de Jaccard de las tejas
+	for _, caso := range casos {
+		firma1, firma2 := calcularFirmaMinHash(base, 512), calcularFirmaMinHash(caso.otro, 512)
+
+		iguales := 0
+		for k := range firma1 {
+			if firma1[k] == firma2[k] {
+				iguales++
+			}
+		}
+
+		estimado, jaccard := float64(iguales)/float64(len(firma1)), calcularJaccardTejasPrueba(base, caso.otro)
+		if math.Abs(estimado-jaccard) > 0.08 {
+			t.Errorf("%s: similitud estimada %.3f, índice de Jaccard %.3f", caso.nombre, estimado, jaccard)
+		}
+	}
+}
+
+func TestCalcularFirmaMinHashArchivosCortos(t *testing.T) {
+	vacio := calcularFirmaMinHash(nil, 8)
+	for k, valor := range vacio {
+		if valor != math.MaxUint64 {
+			t.Errorf("firma de un archivo vacío[%d] = %d, se esperaba el máximo", k, valor)
+		}
+	}
+
+	// Un archivo más corto que una teja tiene una firma propia
+	corto, otro := calcularFirmaMinHash([]byte("x = 1"), 8), calcularFirmaMinHash([]byte("y = 2"), 8)
+	if reflect.DeepEqual(corto, vacio) || reflect.DeepEqual(corto, otro) {
+		t.Errorf("firmas de archivos cortos = %v y %v, se esperaban firmas distintas", corto, otro)
+	}
+	if !reflect.DeepEqual(corto, calcularFirmaMinHash([]byte("x = 1"), 8)) {
+		t.Error("la firma de un mismo contenido cambia entre ejecuciones")
+	}
+}
+
+func TestObtenerParesCandidatos(t *testing.T) {
+	base := []byte(strings.Repeat("for i := 0; i < n; i++ { suma += i * i }\n", 10))
+	distinto := []byte(strings.Repeat("fmt.Println(\"otro programa sin relación\")\n", 10))
+
+	firmas := [][]uint64{
+		calcularFirmaMinHash(base, 32*4),
+		calcularFirmaMinHash(distinto, 32*4),
+		calcularFirmaMinHash(base, 32*4),
+	}
+	candidatos := obtenerParesCandidatos(firmas, 32, 4)
+
+	if !reflect.DeepEqual(candidatos, [][]int{nil, nil, {0}}) && !reflect.DeepEqual(candidatos, [][]int{{}, {}, {0}}) {
+		t.Errorf("candidatos = %v, se esperaba solo el par de archivos idénticos", candidatos)
+	}
+}
+
+func TestObtenerDistanciaGruposLSH(t *testing.T) {
+	parametros := crearParametrosPrueba()
+	if distancia := obtenerDistanciaGruposLSH(parametros); distancia >= 0 {
+		t.Errorf("sin grupos = %v, se esperaba una distancia negativa", distancia)
+	}
+
+	parametros.criterioDistancia = crearCriterioValor(10)
+	parametros.distanciaFusion = 12
+	parametros.umbrales = []float64{5, 20}
+	if distancia := obtenerDistanciaGruposLSH(parametros); distancia != 20 {
+		t.Errorf("distancia de los grupos = %v, se esperaba 20", distancia)
+	}
+}
+
+func TestCompletarDistanciasComponentes(t *testing.T) {
+	tabla := make([]CodigoFuente, 4)
+	crearTablasDistancias(tabla, false)
+	for i := range tabla {
+		for j := range tabla {
+			asignarValorDistancia(&tabla[i], j, DISTANCIA_NO_CALCULADA)
+		}
+		asignarValorDistancia(&tabla[i], i, 0)
+	}
+	// Cadena 0-1-2 calculada a distancia 1 y el archivo 3 aislado
+	for _, par := range [][2]int{{0, 1}, {1, 2}} {
+		asignarValorDistancia(&tabla[par[0]], par[1], 1)
+		asignarValorDistancia(&tabla[par[1]], par[0], 1)
+	}
+
+	calculadas := completarDistanciasComponentes(tabla, func(a CodigoFuente, b CodigoFuente) float64 { return 2 }, 5)
+
+	if calculadas != 1 {
+		t.Errorf("distancias calculadas = %d, se esperaba 1 (el par 0-2)", calculadas)
+	}
+	if obtenerValorDistancia(tabla[0], 2) != 2 || obtenerValorDistancia(tabla[2], 0) != 2 {
+		t.Errorf("distancia 0-2 = %v, se esperaba 2", obtenerValorDistancia(tabla[0], 2))
+	}
+	for i := 0; i < 3; i++ {
+		if esDistanciaCalculada(obtenerValorDistancia(tabla[3], i)) {
+			t.Errorf("distancia 3-%d calculada fuera de su componente", i)
+		}
+	}
+}
+
+func TestLSHSinDistanciaNoCalculadaEnReportes(t *testing.T) {
+	parametros := crearParametrosPrueba()
+	parametros.modoPares = PARES_LSH
+	parametros.criterioDistancia = crearCriterioValor(10)
+	parametros.distanciasGrupos = true
+	parametros.resumenDirectorios = true
+	parametros.percentiles = true
+	parametros.puntajeEstudiantes = PUNTAJE_IGUAL
+	parametros.nombreTablaCSV = filepath.Join(t.TempDir(), "distancias.csv")
+
+	resultado, salida := analizarCorpusSalidaPrueba(t, parametros, crearCorpusFamiliasPrueba(3, 3))
+
+	if calculados, total := contarParesCalculados(resultado.archivos); calculados == total {
+		t.Fatalf("se calcularon los %d pares, el corpus no prueba los pares omitidos", total)
+	}
+	if len(resultado.grupos) != 3 {
+		t.Fatalf("grupos = %d, se esperaban 3 (uno por familia)", len(resultado.grupos))
+	}
+	for _, grupo := range resultado.grupos {
+		for _, a := range grupo.integrantes {
+			for _, b := range grupo.integrantes {
+				if !esDistanciaCalculada(resultado.matriz[a.indice][b.indice]) {
+					t.Errorf("distancia no calculada entre los integrantes %d y %d de un grupo", a.indice, b.indice)
+				}
+			}
+		}
+	}
+
+	csv, err := os.ReadFile(parametros.nombreTablaCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultadoJSON, err := json.Marshal(construirResultadoJSON(resultado, parametros))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(resultadoJSON), "null") {
+		t.Error("el resultado JSON no marca los pares no calculados con null")
+	}
+
+	for nombre, reporte := range map[string]string{"consola": salida, "CSV": string(csv), "JSON": string(resultadoJSON)} {
+		for _, texto := range textosDistanciaNoCalculada {
+			if strings.Contains(reporte, texto) {
+				t.Errorf("el reporte %s contiene la distancia no calculada %s", nombre, texto)
+			}
+		}
+	}
+
+	for _, distancia := range resultado.distribucion {
+		if !esDistanciaCalculada(distancia) || math.IsInf(distancia, 0) {
+			t.Fatalf("la distribución de los percentiles contiene la distancia %v", distancia)
+		}
+	}
+}
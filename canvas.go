@@ -0,0 +1,203 @@
+/*
+ * Descarga de las entregas de una tarea de Canvas por su API REST.
+ *
+ * Se consultan todas las entregas de la tarea (con paginación), y los archivos adjuntos de cada estudiante se
+ * guardan en un subdirectorio con su usuario (login) dentro del directorio de descarga, de modo que el análisis
+ * asocia cada archivo a su estudiante. Los adjuntos .zip se guardan sin extraer y el análisis lee sus archivos al
+ * recorrer el directorio (ver comprimidos.go).
+ * El token de acceso se toma de --canvas-token o de la variable de ambiente CANVAS_TOKEN. Las mismas entregas se
+ * descargan en el modo servidor desde un lanzamiento LTI de Canvas (ver lti_entregas.go).
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cantidad de entregas por página de la API de Canvas y tiempo máximo de cada consulta (incluye la descarga de un
+// adjunto)
+const (
+	ENTREGAS_POR_PAGINA_CANVAS = 100
+	TIEMPO_CONSULTA_CANVAS     = 2 * time.Minute
+)
+
+// Estructura de una entrega de Canvas (solo los campos empleados)
+type EntregaCanvas struct {
+	IdentificadorUsuario int `json:"user_id"`
+	Usuario              struct {
+		Login            string `json:"login_id"`
+		IdentificadorSIS string `json:"sis_user_id"`
+	} `json:"user"`
+	Adjuntos []struct {
+		NombreArchivo string `json:"filename"`
+		URL           string `json:"url"`
+	} `json:"attachments"`
+}
+
+// Expresión para obtener la página siguiente del encabezado Link de la API de Canvas
+var expresionSiguienteCanvas = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// Expresión de los caracteres no permitidos en el nombre del directorio de un estudiante
+var expresionNombreSeguro = regexp.MustCompile(`[^A-Za-z0-9._@-]+`)
+
+// Cliente HTTP de la API de Canvas, para que una consulta que no responde no detenga el análisis ni el servidor
+var clienteCanvas = &http.Client{Timeout: TIEMPO_CONSULTA_CANVAS}
+
+/*
+ * Función para hacer una petición autenticada a la API de Canvas
+ * param: URL y token de acceso
+ * return: la respuesta (con estado 200) o un error
+ */
+func consultarCanvas(direccion string, token string) (*http.Response, error) {
+	peticion, err := http.NewRequest(http.MethodGet, direccion, nil)
+	if err != nil {
+		return nil, err
+	}
+	peticion.Header.Set("Authorization", "Bearer "+token)
+
+	respuesta, err := clienteCanvas.Do(peticion)
+	if err != nil {
+		return nil, err
+	}
+	if respuesta.StatusCode != http.StatusOK {
+		respuesta.Body.Close()
+		return nil, fmt.Errorf("La API de Canvas respondió \"%s\" para %s", respuesta.Status, direccion)
+	}
+
+	return respuesta, nil
+}
+
+/*
+ * Función para obtener todas las entregas de una tarea de Canvas
+ * param: URL base de Canvas, token de acceso, identificador del curso y de la tarea
+ * return: las entregas o un error
+ */
+func obtenerEntregasCanvas(urlCanvas string, token string, curso string, tarea string) ([]EntregaCanvas, error) {
+	var entregas []EntregaCanvas
+
+	direccion := fmt.Sprintf("%s/api/v1/courses/%s/assignments/%s/submissions?include[]=user&per_page=%d",
+		strings.TrimRight(urlCanvas, "/"), curso, tarea, ENTREGAS_POR_PAGINA_CANVAS)
+
+	for direccion != "" {
+		respuesta, err := consultarCanvas(direccion, token)
+		if err != nil {
+			return entregas, err
+		}
+
+		var pagina []EntregaCanvas
+		err = json.NewDecoder(respuesta.Body).Decode(&pagina)
+		respuesta.Body.Close()
+		if err != nil {
+			return entregas, fmt.Errorf("Respuesta de la API de Canvas no válida: %v", err)
+		}
+		entregas = append(entregas, pagina...)
+
+		direccion = ""
+		if siguiente := expresionSiguienteCanvas.FindStringSubmatch(respuesta.Header.Get("Link")); siguiente != nil {
+			direccion = siguiente[1]
+		}
+	}
+
+	return entregas, nil
+}
+
+/*
+ * Función para obtener el nombre del directorio de un estudiante a partir de su entrega
+ * param: entrega de Canvas
+ * return: login, código SIS o identificador del usuario (sin caracteres no permitidos)
+ */
+func obtenerDirectorioEstudianteCanvas(entrega EntregaCanvas) string {
+	nombre := entrega.Usuario.Login
+	if nombre == "" {
+		nombre = entrega.Usuario.IdentificadorSIS
+	}
+	if nombre == "" {
+		nombre = "usuario_" + strconv.Itoa(entrega.IdentificadorUsuario)
+	}
+
+	return expresionNombreSeguro.ReplaceAllString(nombre, "_")
+}
+
+/*
+ * Función para recorrer los adjuntos de todas las entregas de una tarea de Canvas
+ * param: URL base de Canvas, token de acceso, identificador del curso y de la tarea, y la función que procesa cada
+ *        adjunto (directorio del estudiante, nombre del archivo sin directorios y contenido)
+ * return: cantidad de estudiantes con adjuntos o un error de la API o del procesamiento de un adjunto
+ */
+func recorrerAdjuntosCanvas(urlCanvas string, token string, curso string, tarea string, procesar func(string, string, io.Reader) error) (int, error) {
+	entregas, err := obtenerEntregasCanvas(urlCanvas, token, curso, tarea)
+	if err != nil {
+		return 0, err
+	}
+
+	estudiantes := 0
+	for _, entrega := range entregas {
+		if len(entrega.Adjuntos) == 0 {
+			continue
+		}
+
+		estudiante := obtenerDirectorioEstudianteCanvas(entrega)
+		for _, adjunto := range entrega.Adjuntos {
+			respuesta, err := consultarCanvas(adjunto.URL, token)
+			if err != nil {
+				return estudiantes, err
+			}
+			err = procesar(estudiante, filepath.Base(filepath.Clean("/"+adjunto.NombreArchivo)), respuesta.Body)
+			respuesta.Body.Close()
+			if err != nil {
+				return estudiantes, err
+			}
+		}
+		estudiantes++
+	}
+
+	return estudiantes, nil
+}
+
+/*
+ * Función para descargar los adjuntos de todas las entregas de una tarea de Canvas
+ * param: parámetros de ejecución (URL, token, curso, tarea y directorio de descarga)
+ * return: directorio con un subdirectorio por estudiante, cantidad de estudiantes con adjuntos, o un error
+ */
+func descargarEntregasCanvas(parametros Parametros) (string, int, error) {
+	token := parametros.tokenCanvas
+	if token == "" {
+		token = os.Getenv("CANVAS_TOKEN")
+	}
+	if parametros.urlCanvas == "" || parametros.cursoCanvas == "" || token == "" {
+		return "", 0, fmt.Errorf("Para descargar de Canvas se requiere --canvas-url, --canvas-course y el token (--canvas-token o CANVAS_TOKEN)")
+	}
+
+	directorio := parametros.directorioCanvas
+	if directorio == "" {
+		directorio = "canvas-" + parametros.tareaCanvas
+	}
+
+	estudiantes, err := recorrerAdjuntosCanvas(parametros.urlCanvas, token, parametros.cursoCanvas, parametros.tareaCanvas, func(estudiante string, nombre string, contenido io.Reader) error {
+		directorioEstudiante := filepath.Join(directorio, estudiante)
+		if err := os.MkdirAll(directorioEstudiante, 0755); err != nil {
+			return err
+		}
+
+		salida, err := os.Create(filepath.Join(directorioEstudiante, nombre))
+		if err != nil {
+			return err
+		}
+		defer salida.Close()
+
+		_, err = io.Copy(salida, contenido)
+		return err
+	})
+
+	return directorio, estudiantes, err
+}
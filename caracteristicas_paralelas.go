@@ -0,0 +1,114 @@
+/*
+ * Extracción concurrente de las características (fase 1, --workers).
+ *
+ * La fase 1 lee cada archivo, lo preprocesa y determina sus características; en un solo hilo el procesador espera
+ * a cada lectura del disco y el disco espera a cada cálculo. Los archivos se reparten por una cola de trabajo entre
+ * varios trabajadores (la misma cantidad de la fase 2, --workers=1 procesa en un solo hilo como antes), de modo que
+ * la lectura de unos archivos se superpone con el cálculo de otros.
+ *
+ * Cada trabajador envía el resultado de cada archivo, con su posición en el listado, por un canal de resultados, y la
+ * tabla se llena en el mismo orden sin importar cuál termina primero; cada trabajador toma los vectores de
+ * características de su propia arena (ver memoria.go), que no se comparte entre trabajadores. Un archivo que no se
+ * puede leer no detiene al trabajador: el error se envía por el mismo canal y, al terminar, se reporta el del primer
+ * archivo del listado que falló, igual que en un solo hilo (en el que la lectura detiene la ejecución).
+ */
+
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+/*
+ * Función para obtener la cantidad de trabajadores de la extracción de las características
+ * param: cantidad indicada por el usuario (0 para GOMAXPROCS) y cantidad de archivos
+ * return: cantidad de trabajadores (al menos 1 y no más que los archivos)
+ */
+func obtenerTrabajadoresCaracteristicas(indicada int, cantidadArchivos int) int {
+	trabajadores := indicada
+	if trabajadores <= 0 {
+		trabajadores = runtime.GOMAXPROCS(0)
+	}
+
+	if trabajadores > cantidadArchivos {
+		trabajadores = cantidadArchivos
+	}
+	if trabajadores < 1 {
+		trabajadores = 1
+	}
+
+	return trabajadores
+}
+
+// Resultado de un trabajador de la fase 1
+// - posición del archivo en el listado
+// - información del archivo con sus características
+// - error si el archivo no se pudo leer
+type ResultadoCaracteristicas struct {
+	indice       int
+	codigoFuente CodigoFuente
+	err          error
+}
+
+/*
+ * Función que determina las características de todos los archivos con varios trabajadores
+ * param: listado de archivos, el motor a emplear, el preprocesamiento del contenido, el formato de las rutas
+ *        y la cantidad de trabajadores
+ * return: arreglo con la información de todos los archivos (en el orden del listado) y sus características; si un
+ *         archivo no se puede leer se detiene la ejecución con el error del primero del listado, como en un solo hilo
+ */
+func determinarCaracteristicasParalelas(listado []string, motor Motor, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas, trabajadores int) []CodigoFuente {
+	tablaCodigoFuente := make([]CodigoFuente, len(listado))
+
+	indices := make(chan int, len(listado))
+	for i := range listado {
+		indices <- i
+	}
+	close(indices)
+
+	resultados := make(chan ResultadoCaracteristicas, trabajadores)
+
+	var espera sync.WaitGroup
+	for trabajador := 0; trabajador < trabajadores; trabajador++ {
+		espera.Add(1)
+		go func() {
+			defer espera.Done()
+
+			var arena ArenaCaracteristicas
+			for i := range indices {
+				codigoFuente, err := leerArchivoCodigoFuente(listado[i], preprocesamiento)
+				if err != nil {
+					resultados <- ResultadoCaracteristicas{indice: i, err: err}
+					continue
+				}
+				caracterizarEnArena(&arena, motor, &codigoFuente)
+				codigoFuente.nombre = formatoRutas.formatear(listado[i])
+
+				resultados <- ResultadoCaracteristicas{indice: i, codigoFuente: codigoFuente}
+			}
+		}()
+	}
+	go func() {
+		espera.Wait()
+		close(resultados)
+	}()
+
+	primerError := len(listado)
+	var err error
+	for resultado := range resultados {
+		if resultado.err != nil {
+			if resultado.indice < primerError {
+				primerError, err = resultado.indice, resultado.err
+			}
+			continue
+		}
+		tablaCodigoFuente[resultado.indice] = resultado.codigoFuente
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return tablaCodigoFuente
+}
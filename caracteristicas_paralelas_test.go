@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+/*
+ * Función para determinar las características de un listado y recuperar el error con el que se detiene
+ * param: prueba, listado de archivos, parámetros de ejecución y cantidad de trabajadores
+ * return: el arreglo con las características y el error con el que se detuvo (vacío si terminó)
+ */
+func determinarCaracteristicasErrorPrueba(t *testing.T, listado []string, parametros Parametros, trabajadores int) (tabla []CodigoFuente, detenido string) {
+	t.Helper()
+
+	motor, err := crearMotor(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	preprocesamiento, err := crearPreprocesamiento(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatoRutas, err := crearFormatoRutas(parametros, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if recuperado := recover(); recuperado != nil {
+			detenido = fmt.Sprint(recuperado)
+		}
+	}()
+
+	return determinarCaracteristicas(listado, motor, preprocesamiento, formatoRutas, trabajadores), ""
+}
+
+func TestCaracteristicasParalelasIgualesSecuenciales(t *testing.T) {
+	crearCorpusPrueba(t, crearCorpusFamiliasPrueba(3, 5))
+	parametros := crearParametrosPrueba()
+
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listado, _, _, err := obtenerListadoEntregas(".", parametros.extension, limites)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secuencial, _ := determinarCaracteristicasErrorPrueba(t, listado, parametros, 1)
+	paralela, detenido := determinarCaracteristicasErrorPrueba(t, listado, parametros, 4)
+
+	if detenido != "" {
+		t.Fatalf("la extracción con 4 trabajadores se detuvo: %s", detenido)
+	}
+	for i := range secuencial {
+		if secuencial[i].nombre != paralela[i].nombre || !reflect.DeepEqual(secuencial[i].caracteristica, paralela[i].caracteristica) {
+			t.Errorf("archivo %d: %s con 4 trabajadores, %s en un solo hilo", i, paralela[i].nombre, secuencial[i].nombre)
+		}
+	}
+}
+
+func TestCaracteristicasParalelasArchivoIlegible(t *testing.T) {
+	crearCorpusPrueba(t, crearCorpusFamiliasPrueba(2, 4))
+	parametros := crearParametrosPrueba()
+
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listado, _, _, err := obtenerListadoEntregas(".", parametros.extension, limites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listado = append(listado[:3], append([]string{"./no_existe/a.go", "./no_existe/b.go"}, listado[3:]...)...)
+
+	_, secuencial := determinarCaracteristicasErrorPrueba(t, listado, parametros, 1)
+	if secuencial == "" {
+		t.Fatal("la extracción en un solo hilo no se detuvo con un archivo que no existe")
+	}
+
+	for _, trabajadores := range []int{2, 4, len(listado)} {
+		_, paralela := determinarCaracteristicasErrorPrueba(t, listado, parametros, trabajadores)
+		if paralela != secuencial {
+			t.Errorf("con %d trabajadores se detuvo con %q, en un solo hilo con %q", trabajadores, paralela, secuencial)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+/*
+ * Cartas de notificación de los grupos (--letters).
+ *
+ * Después de revisar los grupos, el docente debe citar a cada estudiante involucrado a una reunión, lo que implica
+ * redactar una carta o correo por estudiante con los archivos y la evidencia de su caso. Con --letters=cartas (y la
+ * lista del curso de --students) se genera en el directorio una carta por estudiante de cada grupo a la distancia
+ * máxima, "grupo_código.txt", a partir de una plantilla de texto (--letter-template, o la predeterminada) con los
+ * marcadores:
+ * - {{nombre}}, {{codigo}}, {{correo}} y {{seccion}} del estudiante (de la lista del curso)
+ * - {{grupo}}, {{integrantes}} y {{otros_integrantes}} (cantidad de estudiantes del grupo y de los demás) y
+ *   {{companeros}} (nombres de los demás)
+ * - {{archivos}}: los archivos del estudiante en el grupo, sin el directorio del estudiante
+ * - {{evidencia}}: los pares más cercanos del estudiante con los demás integrantes, con su distancia y las líneas de
+ *   su archivo en bloques coincidentes (sin nombrar a los demás estudiantes)
+ * - {{fecha}} (fecha de generación), {{motor}}, {{distancia_maxima}} y {{fecha_reunion}} (de --meeting-date; sin la
+ *   opción el marcador se conserva para completarlo después, por ejemplo al combinar correspondencia)
+ * Los grupos con archivos de un solo estudiante no generan cartas. Además se genera "cartas.csv" (separado por
+ * tabuladores) con el grupo, el código, el nombre, el correo y la carta de cada estudiante, para enviar las cartas
+ * como correos. Los directorios que no están en la lista se presentan con su nombre y sin correo.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Cantidad máxima de pares de la evidencia de cada carta
+const PARES_CARTA = 5
+
+// Plantilla predeterminada de las cartas de notificación
+const PLANTILLA_CARTA_DEFECTO = `Fecha: {{fecha}}
+Para: {{nombre}} ({{codigo}}) <{{correo}}>
+Sección: {{seccion}}
+
+Asunto: Citación por similaridad en la entrega (grupo {{grupo}})
+
+Estimado(a) {{nombre}}:
+
+La revisión de similaridad de las entregas de la tarea encontró que sus archivos forman un grupo de entregas muy
+parecidas con las de otros {{otros_integrantes}} estudiantes (grupo {{grupo}}, motor {{motor}}, distancia máxima
+{{distancia_maxima}}).
+
+Archivos involucrados:
+{{archivos}}
+
+Resumen de la evidencia:
+{{evidencia}}
+
+Esta similaridad no es por sí misma una conclusión; por ello lo(a) citamos a una reunión el {{fecha_reunion}} para
+que explique el proceso de desarrollo de su entrega. Por favor confirme su asistencia respondiendo a este mensaje.
+
+Cordialmente,
+
+El equipo docente del curso
+`
+
+// Estructura de un estudiante involucrado en un grupo
+// - directorio del estudiante (subdirectorio inmediato del directorio base)
+// - estudiante de la lista del curso (nil si el directorio no está en la lista)
+// - índices de sus archivos en el grupo
+type InvolucradoGrupo struct {
+	directorio string
+	estudiante *Estudiante
+	archivos   []int
+}
+
+/*
+ * Función para obtener los estudiantes involucrados en un grupo
+ * param: resultado del análisis, grupo y lista de estudiantes
+ * return: los estudiantes con sus archivos, en el orden de los integrantes (sin los archivos del directorio base)
+ */
+func obtenerInvolucradosGrupo(resultado ResultadoAnalisis, grupo Grupo, lista *ListaEstudiantes) []InvolucradoGrupo {
+	var involucrados []InvolucradoGrupo
+	posiciones := make(map[string]int)
+
+	for _, integrante := range grupo.integrantes {
+		directorio := obtenerEstudiante(".", resultado.archivos[integrante.indice].ruta)
+		if directorio == "." {
+			continue
+		}
+
+		posicion, existe := posiciones[directorio]
+		if !existe {
+			posicion = len(involucrados)
+			posiciones[directorio] = posicion
+			involucrados = append(involucrados, InvolucradoGrupo{directorio: directorio, estudiante: lista.buscar(directorio)})
+		}
+		involucrados[posicion].archivos = append(involucrados[posicion].archivos, integrante.indice)
+	}
+
+	return involucrados
+}
+
+/*
+ * Función para presentar el archivo de un estudiante en una carta
+ * param: ruta relativa del archivo (iniciando con "./")
+ * return: la ruta sin el directorio del estudiante, por ejemplo "src/Main.java"
+ */
+func presentarArchivoCarta(ruta string) string {
+	partes := strings.SplitN(strings.TrimPrefix(ruta, "./"), "/", 2)
+
+	return partes[len(partes)-1]
+}
+
+/*
+ * Función para resumir la evidencia de un estudiante frente a los demás integrantes de su grupo
+ * param: resultado del análisis, estudiantes del grupo, posición del estudiante y cantidad mínima de líneas
+ *        consecutivas de un bloque coincidente
+ * return: una línea por cada uno de los pares más cercanos (hasta PARES_CARTA)
+ */
+func resumirEvidenciaCarta(resultado ResultadoAnalisis, involucrados []InvolucradoGrupo, posicion int, minimoLineas int) string {
+	var pares []Distancia
+	origenes := make(map[int]int)
+
+	for _, propio := range involucrados[posicion].archivos {
+		for otro, involucrado := range involucrados {
+			if otro == posicion {
+				continue
+			}
+			for _, ajeno := range involucrado.archivos {
+				distancia := obtenerValorDistancia(resultado.archivos[propio], ajeno)
+				if distancia == DISTANCIA_NO_CALCULADA {
+					continue
+				}
+				origenes[len(pares)] = propio
+				pares = append(pares, Distancia{indiceCodigoFuente: ajeno, distancia: distancia})
+			}
+		}
+	}
+
+	orden := make([]int, len(pares))
+	for i := range orden {
+		orden[i] = i
+	}
+	sort.SliceStable(orden, func(i, j int) bool {
+		return pares[orden[i]].distancia < pares[orden[j]].distancia
+	})
+	if len(orden) > PARES_CARTA {
+		orden = orden[:PARES_CARTA]
+	}
+
+	var evidencia strings.Builder
+	for _, i := range orden {
+		archivo1, archivo2 := resultado.archivos[origenes[i]], resultado.archivos[pares[i].indiceCodigoFuente]
+		bloques := agruparBloquesLineas(obtenerLineasCoincidentes(archivo1.lineas, archivo2.lineas), minimoLineas)
+
+		fmt.Fprintf(&evidencia, "- %s: distancia %s con la entrega de otro integrante del grupo", presentarArchivoCarta(archivo1.ruta), strconv.FormatFloat(pares[i].distancia, 'f', 2, 64))
+		if len(bloques) == 0 {
+			evidencia.WriteString(", sin bloques de líneas idénticas\n")
+			continue
+		}
+
+		rangos := make([]string, len(bloques))
+		for k, bloque := range bloques {
+			rangos[k] = fmt.Sprintf("%d-%d", bloque[0].numero1, bloque[len(bloque)-1].numero1)
+		}
+		fmt.Fprintf(&evidencia, ", %d bloques de líneas idénticas (líneas %s de su archivo)\n", len(bloques), strings.Join(rangos, ", "))
+	}
+
+	if evidencia.Len() == 0 {
+		return "- (sin pares con la distancia calculada)"
+	}
+
+	return strings.TrimSuffix(evidencia.String(), "\n")
+}
+
+/*
+ * Función para obtener un nombre de archivo seguro a partir de un texto
+ * param: texto (código del estudiante o nombre de su directorio)
+ * return: el texto con las letras, dígitos, "-" y "_" (los demás caracteres se reemplazan por "_")
+ */
+func obtenerNombreSeguroCarta(texto string) string {
+	return strings.Map(func(caracter rune) rune {
+		if unicode.IsLetter(caracter) || unicode.IsDigit(caracter) || caracter == '-' || caracter == '_' {
+			return caracter
+		}
+		return '_'
+	}, texto)
+}
+
+/*
+ * Función para generar las cartas de notificación de los estudiantes de los grupos
+ * param: resultado del análisis (con los grupos a la distancia máxima), lista de estudiantes y parámetros de ejecución
+ *        (directorio, plantilla, fecha de la reunión y cantidad mínima de líneas de un bloque)
+ * return: cantidad de cartas generadas o un error si la plantilla no se puede leer
+ */
+func generarCartasNotificacion(resultado ResultadoAnalisis, lista *ListaEstudiantes, parametros Parametros) (int, error) {
+	plantilla := PLANTILLA_CARTA_DEFECTO
+	if parametros.plantillaCarta != "" {
+		contenido, err := os.ReadFile(parametros.plantillaCarta)
+		if err != nil {
+			return 0, fmt.Errorf("No se puede leer la plantilla de las cartas (--letter-template): %v", err)
+		}
+		plantilla = string(contenido)
+	}
+
+	fechaReunion := parametros.fechaReunion
+	if fechaReunion == "" {
+		fechaReunion = "{{fecha_reunion}}"
+	}
+
+	for i := range resultado.archivos {
+		if resultado.archivos[i].lineas == nil {
+			resultado.archivos[i].lineas = obtenerLineasNormalizadas(resultado.archivos[i].contenido)
+		}
+	}
+
+	if err := os.MkdirAll(parametros.directorioCartas, 0755); err != nil {
+		panic(err)
+	}
+
+	var indice strings.Builder
+	indice.WriteString("Grupo\tCódigo\tNombre\tCorreo\tCarta\n")
+
+	cartas := 0
+	for _, grupo := range resultado.grupos {
+		involucrados := obtenerInvolucradosGrupo(resultado, grupo, lista)
+		if len(involucrados) < 2 {
+			continue
+		}
+
+		for posicion, involucrado := range involucrados {
+			estudiante := involucrado.estudiante
+			if estudiante == nil {
+				estudiante = &Estudiante{identificador: involucrado.directorio, nombre: involucrado.directorio}
+			}
+
+			var archivos, companeros []string
+			for _, archivo := range involucrado.archivos {
+				archivos = append(archivos, "- "+presentarArchivoCarta(resultado.archivos[archivo].ruta))
+			}
+			for otro, companero := range involucrados {
+				if otro == posicion {
+					continue
+				}
+				if companero.estudiante != nil {
+					companeros = append(companeros, companero.estudiante.nombre)
+				} else {
+					companeros = append(companeros, companero.directorio)
+				}
+			}
+
+			reemplazos := strings.NewReplacer(
+				"{{nombre}}", estudiante.nombre,
+				"{{codigo}}", estudiante.identificador,
+				"{{correo}}", estudiante.correo,
+				"{{seccion}}", estudiante.seccion,
+				"{{grupo}}", grupo.identificador,
+				"{{integrantes}}", strconv.Itoa(len(involucrados)),
+				"{{otros_integrantes}}", strconv.Itoa(len(involucrados)-1),
+				"{{companeros}}", strings.Join(companeros, ", "),
+				"{{archivos}}", strings.Join(archivos, "\n"),
+				"{{evidencia}}", resumirEvidenciaCarta(resultado, involucrados, posicion, parametros.minimoLineas),
+				"{{fecha}}", time.Now().Format("2006-01-02"),
+				"{{motor}}", resultado.motor,
+				"{{distancia_maxima}}", strconv.FormatFloat(resultado.criterio.limite(), 'g', -1, 64),
+				"{{fecha_reunion}}", fechaReunion,
+			)
+
+			nombreCarta := obtenerNombreSeguroCarta(grupo.identificador) + "_" + obtenerNombreSeguroCarta(estudiante.identificador) + ".txt"
+			if err := os.WriteFile(filepath.Join(parametros.directorioCartas, nombreCarta), []byte(reemplazos.Replace(plantilla)), 0644); err != nil {
+				panic(err)
+			}
+
+			fmt.Fprintf(&indice, "%s\t%s\t%s\t%s\t%s\n", grupo.identificador, estudiante.identificador, estudiante.nombre, estudiante.correo, nombreCarta)
+			cartas++
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(parametros.directorioCartas, "cartas.csv"), []byte(indice.String()), 0644); err != nil {
+		panic(err)
+	}
+
+	return cartas, nil
+}
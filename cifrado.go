@@ -0,0 +1,172 @@
+/*
+ * Cifrado en reposo de la caché y del directorio de datos (--encryption-key).
+ *
+ * El código de los estudiantes y los resultados de sus análisis son datos personales protegidos. Con una clave de
+ * 256 bits (64 caracteres hexadecimales en un archivo, por ejemplo generada con "openssl rand -hex 32 > clave.txt"),
+ * SASC cifra con AES-256-GCM todo lo que guarda en disco:
+ * - las entradas de la caché de resultados (--cache-dir, rutas de los archivos y distancias)
+ * - las entregas originales (<id>.tar) y los trabajos con su resultado (<id>.json) del directorio de datos (--data-dir)
+ * Cada archivo cifrado inicia con la marca MARCA_CIFRADO seguida de un nonce aleatorio y del contenido cifrado, que
+ * incluye una etiqueta de autenticación. El nombre del archivo (el identificador del trabajo o la huella de la entrada
+ * de la caché) se autentica como dato adicional: un archivo modificado, cifrado con otra clave o copiado sobre otro
+ * archivo (por ejemplo el resultado de un trabajo en lugar del de otro) no se puede leer.
+ *
+ * Con la clave, un archivo sin la marca no se acepta, para que nadie pueda reemplazar un archivo cifrado por uno en
+ * claro: el servidor no inicia y una entrada de la caché se descarta (se vuelve a calcular cifrada). Antes de activar
+ * el cifrado en un directorio de datos existente se cifran sus archivos con
+ * ./SASC db encrypt --data-dir=dir --encryption-key=clave.txt (ver administracion.go). Sin la clave, los archivos sin la marca se leen sin descifrar y un archivo cifrado no se puede leer. La
+ * clave no se guarda en ningún archivo de SASC y perderla implica perder los datos cifrados.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Marca al inicio de los archivos cifrados (identifica el formato y el algoritmo)
+const MARCA_CIFRADO = "SASC-AES-256-GCM-AD\n"
+
+// Estructura del cifrado en reposo
+// - cifrador autenticado AES-256-GCM con la clave del usuario
+type Cifrado struct {
+	aead cipher.AEAD
+}
+
+/*
+ * Función para crear el cifrado a partir del archivo de la clave
+ * param: nombre del archivo de la clave (vacío si no se cifra)
+ * return: el cifrado (nil si no se cifra) o un error si el archivo no se puede leer o la clave no es válida
+ */
+func crearCifrado(nombreArchivo string) (*Cifrado, error) {
+	if nombreArchivo == "" {
+		return nil, nil
+	}
+
+	contenido, err := ioutil.ReadFile(nombreArchivo)
+	if err != nil {
+		return nil, fmt.Errorf("No se puede leer la clave de cifrado (--encryption-key): %v", err)
+	}
+
+	clave, err := hex.DecodeString(strings.TrimSpace(string(contenido)))
+	if err != nil || len(clave) != 32 {
+		return nil, fmt.Errorf("La clave de cifrado (--encryption-key) debe tener 64 caracteres hexadecimales (por ejemplo \"openssl rand -hex 32\")")
+	}
+
+	bloque, err := aes.NewCipher(clave)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(bloque)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cifrado{aead: aead}, nil
+}
+
+/*
+ * Función para determinar si un contenido está cifrado
+ * param: contenido leído de un archivo
+ * return: si inicia con la marca de los archivos cifrados
+ */
+func estaCifrado(contenido []byte) bool {
+	return bytes.HasPrefix(contenido, []byte(MARCA_CIFRADO))
+}
+
+/*
+ * Función para obtener el identificador con el que se cifra un archivo (su nombre, el de un archivo temporal es el
+ * del archivo que reemplaza)
+ * param: ruta del archivo
+ * return: el nombre del archivo, que se autentica como dato adicional
+ */
+func obtenerIdentificadorCifrado(ruta string) string {
+	return filepath.Base(strings.TrimSuffix(ruta, EXTENSION_TEMPORAL))
+}
+
+/*
+ * Función para cifrar un contenido con un nonce aleatorio
+ * param: contenido a guardar e identificador del archivo (dato adicional autenticado)
+ * return: marca, nonce y contenido cifrado (el contenido sin cambios si no se cifra)
+ */
+func (cifrado *Cifrado) cifrar(contenido []byte, identificador string) []byte {
+	if cifrado == nil {
+		return contenido
+	}
+
+	nonce := make([]byte, cifrado.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+
+	cifrados := append([]byte(MARCA_CIFRADO), nonce...)
+	return cifrado.aead.Seal(cifrados, nonce, contenido, []byte(identificador))
+}
+
+/*
+ * Función para descifrar un contenido leído de un archivo
+ * param: contenido leído (cifrado o no) e identificador del archivo (dato adicional autenticado)
+ * return: el contenido descifrado (sin cambios si no estaba cifrado y no se indicó la clave) o un error si está
+ *         cifrado y no se indicó la clave, no está cifrado y se indicó la clave, la clave es otra o el archivo se
+ *         modificó o es de otro identificador
+ */
+func (cifrado *Cifrado) descifrar(contenido []byte, identificador string) ([]byte, error) {
+	if !estaCifrado(contenido) {
+		if cifrado != nil {
+			return nil, fmt.Errorf("el archivo no está cifrado y se indicó la clave (--encryption-key), se cifra con ./SASC db encrypt")
+		}
+		return contenido, nil
+	}
+	if cifrado == nil {
+		return nil, fmt.Errorf("el archivo está cifrado, se debe indicar la clave (--encryption-key)")
+	}
+
+	contenido = contenido[len(MARCA_CIFRADO):]
+	if len(contenido) < cifrado.aead.NonceSize() {
+		return nil, fmt.Errorf("el archivo cifrado está incompleto")
+	}
+
+	descifrado, err := cifrado.aead.Open(nil, contenido[:cifrado.aead.NonceSize()], contenido[cifrado.aead.NonceSize():], []byte(identificador))
+	if err != nil {
+		return nil, fmt.Errorf("no se puede descifrar el archivo (otra clave, archivo modificado o de otro trabajo)")
+	}
+
+	return descifrado, nil
+}
+
+/*
+ * Función para leer un archivo, descifrándolo si está cifrado
+ * param: ruta del archivo
+ * return: el contenido o un error si no se puede leer o descifrar
+ */
+func (cifrado *Cifrado) leerArchivo(ruta string) ([]byte, error) {
+	contenido, err := ioutil.ReadFile(ruta)
+	if err != nil {
+		return nil, err
+	}
+
+	contenido, err = cifrado.descifrar(contenido, obtenerIdentificadorCifrado(ruta))
+	if err != nil {
+		return nil, fmt.Errorf("\"%s\": %v", ruta, err)
+	}
+
+	return contenido, nil
+}
+
+/*
+ * Función para escribir un archivo, cifrándolo si se indicó la clave
+ * param: ruta del archivo, contenido y permisos
+ * return: un error si no se puede escribir
+ */
+func (cifrado *Cifrado) escribirArchivo(ruta string, contenido []byte, permisos os.FileMode) error {
+	return os.WriteFile(ruta, cifrado.cifrar(contenido, obtenerIdentificadorCifrado(ruta)), permisos)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+ * Función para crear el cifrado de una prueba con una clave aleatoria
+ * param: prueba
+ * return: el cifrado
+ */
+func crearCifradoPrueba(t *testing.T) *Cifrado {
+	t.Helper()
+
+	clave := make([]byte, 32)
+	if _, err := rand.Read(clave); err != nil {
+		t.Fatal(err)
+	}
+	archivo := filepath.Join(t.TempDir(), "clave.txt")
+	if err := os.WriteFile(archivo, []byte(strings.ToUpper(hex.EncodeToString(clave))+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cifrado, err := crearCifrado(archivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cifrado
+}
+
+/*
+ * Función para cifrar un contenido sin el identificador del archivo como dato adicional
+ * param: cifrado y contenido
+ * return: marca, nonce y contenido cifrado
+ */
+func cifrarSinIdentificadorPrueba(cifrado *Cifrado, contenido []byte) []byte {
+	nonce := make([]byte, cifrado.aead.NonceSize())
+
+	return cifrado.aead.Seal(append([]byte(MARCA_CIFRADO), nonce...), nonce, contenido, nil)
+}
+
+func TestCrearCifrado(t *testing.T) {
+	if cifrado, err := crearCifrado(""); cifrado != nil || err != nil {
+		t.Errorf("sin clave = %v, %v, se esperaba nil", cifrado, err)
+	}
+
+	directorio := t.TempDir()
+	for nombre, contenido := range map[string]string{
+		"corta.txt":   strings.Repeat("ab", 16),
+		"larga.txt":   strings.Repeat("ab", 33),
+		"no_hex.txt":  strings.Repeat("zz", 32),
+		"vacia.txt":   "",
+		"no_existe/x": "",
+	} {
+		archivo := filepath.Join(directorio, nombre)
+		if !strings.Contains(nombre, "/") {
+			if err := os.WriteFile(archivo, []byte(contenido), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := crearCifrado(archivo); err == nil {
+			t.Errorf("crearCifrado(%s) sin error, se esperaba una clave no válida", nombre)
+		}
+	}
+}
+
+func TestCifrarDescifrar(t *testing.T) {
+	cifrado := crearCifradoPrueba(t)
+	contenido := []byte("{\"id\":\"trabajo\"}")
+
+	cifrados := cifrado.cifrar(contenido, "a.json")
+	if !bytes.HasPrefix(cifrados, []byte(MARCA_CIFRADO)) || bytes.Contains(cifrados, contenido) {
+		t.Fatalf("contenido cifrado = %q", cifrados)
+	}
+	if otros := cifrado.cifrar(contenido, "a.json"); bytes.Equal(otros, cifrados) {
+		t.Error("dos cifrados del mismo contenido son iguales, el nonce no es aleatorio")
+	}
+
+	modificado := append([]byte{}, cifrados...)
+	modificado[len(modificado)-1] ^= 1
+
+	casos := []struct {
+		nombre        string
+		cifrado       *Cifrado
+		contenido     []byte
+		identificador string
+		valido        bool
+	}{
+		{"mismo archivo", cifrado, cifrados, "a.json", true},
+		{"byte modificado", cifrado, modificado, "a.json", false},
+		{"copiado sobre otro archivo", cifrado, cifrados, "b.json", false},
+		{"otra clave", crearCifradoPrueba(t), cifrados, "a.json", false},
+		{"incompleto", cifrado, []byte(MARCA_CIFRADO + "abc"), "a.json", false},
+		{"cifrado sin la clave", nil, cifrados, "a.json", false},
+		{"sin cifrar con la clave", cifrado, contenido, "a.json", false},
+		{"sin cifrar sin la clave", nil, contenido, "a.json", true},
+		{"sin dato adicional", cifrado, cifrarSinIdentificadorPrueba(cifrado, contenido), "a.json", false},
+	}
+
+	for _, caso := range casos {
+		descifrado, err := caso.cifrado.descifrar(caso.contenido, caso.identificador)
+		if (err == nil) != caso.valido {
+			t.Errorf("%s: error = %v", caso.nombre, err)
+			continue
+		}
+		if caso.valido && !bytes.Equal(descifrado, contenido) {
+			t.Errorf("%s: descifrado = %q, se esperaba %q", caso.nombre, descifrado, contenido)
+		}
+	}
+}
+
+func TestEscribirLeerArchivoCifrado(t *testing.T) {
+	cifrado := crearCifradoPrueba(t)
+	directorio := t.TempDir()
+	contenido := []byte("entregas")
+
+	// Un archivo temporal que se renombra se autentica con el nombre del archivo que reemplaza
+	ruta := filepath.Join(directorio, "trabajo1.tar")
+	if err := cifrado.escribirArchivo(ruta+EXTENSION_TEMPORAL, contenido, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(ruta+EXTENSION_TEMPORAL, ruta); err != nil {
+		t.Fatal(err)
+	}
+	if leido, err := cifrado.leerArchivo(ruta); err != nil || !bytes.Equal(leido, contenido) {
+		t.Fatalf("leerArchivo = %q, %v, se esperaba %q", leido, err, contenido)
+	}
+
+	// El mismo archivo copiado con el nombre de otro trabajo no se puede leer
+	cifrados, err := os.ReadFile(ruta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otro := filepath.Join(directorio, "trabajo2.tar")
+	if err = os.WriteFile(otro, cifrados, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cifrado.leerArchivo(otro); err == nil {
+		t.Error("se leyó un archivo cifrado copiado sobre otro")
+	}
+
+	// Un archivo sin cifrar no se acepta con la clave
+	claro := filepath.Join(directorio, "trabajo3.tar")
+	if err = os.WriteFile(claro, contenido, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cifrado.leerArchivo(claro); err == nil {
+		t.Error("se leyó un archivo sin cifrar con la clave")
+	}
+}
+
+func TestCifrarAlmacen(t *testing.T) {
+	cifrado := crearCifradoPrueba(t)
+	directorio := t.TempDir()
+	contenido := []byte("{\"id\":\"x\"}")
+
+	archivos := map[string][]byte{
+		"claro.json":   contenido,
+		"claro.tar":    contenido,
+		"cifrado.json": cifrado.cifrar(contenido, "cifrado.json"),
+	}
+	for nombre, datos := range archivos {
+		if err := os.WriteFile(filepath.Join(directorio, nombre), datos, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	salida := capturarSalidaPrueba(t, func() {
+		if err := cifrarAlmacen(directorio, cifrado); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(salida, "archivos cifrados: 2 (ya estaban cifrados: 1)") {
+		t.Errorf("salida = %q, se esperaban 2 archivos cifrados y 1 existente", salida)
+	}
+
+	for nombre := range archivos {
+		ruta := filepath.Join(directorio, nombre)
+		datos, err := os.ReadFile(ruta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(datos, []byte(MARCA_CIFRADO)) {
+			t.Errorf("%s no está cifrado", nombre)
+		}
+		if leido, err := cifrado.leerArchivo(ruta); err != nil || !bytes.Equal(leido, contenido) {
+			t.Errorf("%s: leerArchivo = %q, %v", nombre, leido, err)
+		}
+	}
+}
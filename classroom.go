@@ -0,0 +1,201 @@
+/*
+ * Comando classroom: análisis de una tarea de GitHub Classroom a partir de su lista de estudiantes.
+ *
+ *     ./SASC classroom --roster=classroom_roster.csv --org=uniquindio-prog1 --assignment=taller1 \
+ *                      --deadline=2021-08-20T23:59:00-05:00 java 40
+ *
+ * Por cada estudiante de la lista (CSV exportado por GitHub Classroom con las columnas "identifier" y
+ * "github_username") se clona el repositorio <tarea>-<usuario> de la organización y se deja en el último
+ * commit anterior a la fecha límite (según la fecha de los commits). Cada repositorio queda en un
+ * subdirectorio con el identificador del estudiante, de modo que los reportes muestran a quién pertenece
+ * cada archivo. Para repositorios privados se usa el token de la variable de ambiente GITHUB_TOKEN, y con
+ * --git-server se puede usar otro servidor (por ejemplo GitHub Enterprise).
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Estructura de un estudiante de la lista de GitHub Classroom
+// - identificador del estudiante en la lista (código, correo o nombre)
+// - usuario de GitHub (vacío si no ha vinculado su cuenta)
+type EstudianteClassroom struct {
+	identificador string
+	usuario       string
+}
+
+/*
+ * Función para leer la lista de estudiantes de GitHub Classroom
+ * param: nombre del archivo CSV de la lista
+ * return: los estudiantes o un error si el archivo no se puede leer o no tiene las columnas esperadas
+ */
+func leerListaClassroom(nombreArchivo string) ([]EstudianteClassroom, error) {
+	var estudiantes []EstudianteClassroom
+
+	archivo, err := os.Open(nombreArchivo)
+	if err != nil {
+		return estudiantes, fmt.Errorf("No se puede leer la lista de estudiantes (--roster): %v", err)
+	}
+	defer archivo.Close()
+
+	registros, err := csv.NewReader(archivo).ReadAll()
+	if err != nil || len(registros) == 0 {
+		return estudiantes, fmt.Errorf("Lista de estudiantes (--roster) no válida: %v", err)
+	}
+
+	columnaIdentificador, columnaUsuario := -1, -1
+	for i, columna := range registros[0] {
+		switch strings.TrimSpace(strings.TrimPrefix(columna, "\uFEFF")) {
+		case "identifier":
+			columnaIdentificador = i
+		case "github_username":
+			columnaUsuario = i
+		}
+	}
+	if columnaIdentificador < 0 || columnaUsuario < 0 {
+		return estudiantes, fmt.Errorf("La lista de estudiantes (--roster) debe tener las columnas \"identifier\" y \"github_username\"")
+	}
+
+	for _, registro := range registros[1:] {
+		estudiantes = append(estudiantes, EstudianteClassroom{
+			identificador: strings.TrimSpace(registro[columnaIdentificador]),
+			usuario:       strings.TrimSpace(registro[columnaUsuario]),
+		})
+	}
+
+	return estudiantes, nil
+}
+
+/*
+ * Función para ejecutar un comando de git
+ * param: argumentos del comando
+ * return: salida del comando (sin espacios al final) o un error con la salida de errores
+ */
+func ejecutarGit(argumentos ...string) (string, error) {
+	comando := exec.Command("git", argumentos...)
+	comando.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	salida, err := comando.Output()
+	if err != nil {
+		if errorSalida, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("%s", strings.TrimSpace(string(errorSalida.Stderr)))
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(string(salida)), nil
+}
+
+/*
+ * Función para clonar un repositorio y dejarlo en el último commit anterior a la fecha límite
+ * param: URL del repositorio, directorio destino, fecha límite (cero si se usa el último commit)
+ *        y configuración adicional de git para clonar (por ejemplo la autenticación)
+ * return: commit seleccionado o un error
+ */
+func clonarRepositorioFechaLimite(url string, destino string, fechaLimite time.Time, configuracionGit []string) (string, error) {
+	if _, err := ejecutarGit(append(configuracionGit, "clone", "--quiet", url, destino)...); err != nil {
+		return "", err
+	}
+
+	if fechaLimite.IsZero() {
+		return ejecutarGit("-C", destino, "rev-parse", "HEAD")
+	}
+
+	commit, err := ejecutarGit("-C", destino, "rev-list", "-1", "--before="+fechaLimite.Format(time.RFC3339), "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if commit == "" {
+		return "", fmt.Errorf("no tiene commits antes de la fecha límite")
+	}
+
+	if _, err = ejecutarGit("-C", destino, "checkout", "--quiet", commit); err != nil {
+		return "", err
+	}
+
+	return commit, nil
+}
+
+/*
+ * Función para clonar los repositorios de todos los estudiantes de una tarea de GitHub Classroom
+ * param: parámetros de ejecución (lista, organización, tarea, fecha límite y directorio)
+ * return: directorio con un subdirectorio por estudiante, cantidad de repositorios clonados, o un error
+ */
+func clonarRepositoriosClassroom(parametros Parametros) (string, int, error) {
+	if parametros.listaClassroom == "" || parametros.organizacionClassroom == "" || parametros.tareaClassroom == "" {
+		return "", 0, fmt.Errorf("El comando classroom requiere --roster, --org y --assignment")
+	}
+
+	var fechaLimite time.Time
+	if parametros.fechaLimiteClassroom != "" {
+		fecha, err := time.Parse(time.RFC3339, parametros.fechaLimiteClassroom)
+		if err != nil {
+			return "", 0, fmt.Errorf("Fecha límite (--deadline) no válida, use el formato 2021-08-20T23:59:00-05:00")
+		}
+		fechaLimite = fecha
+	}
+
+	estudiantes, err := leerListaClassroom(parametros.listaClassroom)
+	if err != nil {
+		return "", 0, err
+	}
+
+	directorio := parametros.directorioClassroom
+	if directorio == "" {
+		directorio = "classroom-" + parametros.tareaClassroom
+	}
+	if err = os.MkdirAll(directorio, 0755); err != nil {
+		return directorio, 0, err
+	}
+
+	// El token se envía en un encabezado (y no en la URL) para que no aparezca en los mensajes de error de git
+	var configuracionGit []string
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		credenciales := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+		configuracionGit = []string{"-c", "http." + parametros.servidorGit + ".extraheader=AUTHORIZATION: basic " + credenciales}
+	}
+
+	clonados := 0
+	for _, estudiante := range estudiantes {
+		etiqueta := estudiante.identificador
+		if etiqueta == "" {
+			etiqueta = estudiante.usuario
+		}
+
+		if estudiante.usuario == "" {
+			fmt.Println("\t" + etiqueta + ": sin usuario de GitHub vinculado")
+			continue
+		}
+
+		repositorio := parametros.tareaClassroom + "-" + estudiante.usuario
+		destino := filepath.Join(directorio, expresionNombreSeguro.ReplaceAllString(etiqueta, "_"))
+
+		if _, err = os.Stat(destino); err == nil {
+			fmt.Println("\t" + etiqueta + ": ya existe " + destino + ", no se clona de nuevo")
+			clonados++
+			continue
+		}
+
+		commit, err := clonarRepositorioFechaLimite(parametros.servidorGit+parametros.organizacionClassroom+"/"+repositorio+".git", destino, fechaLimite, configuracionGit)
+		if err != nil {
+			fmt.Println("\t" + etiqueta + " (" + repositorio + "): " + err.Error())
+			os.RemoveAll(destino)
+			continue
+		}
+
+		fmt.Println("\t" + etiqueta + " (" + repositorio + "): commit " + commit)
+		clonados++
+	}
+	fmt.Println()
+
+	return directorio, clonados, nil
+}
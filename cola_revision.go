@@ -0,0 +1,207 @@
+/*
+ * Cola de revisión (--review-queue, --review-csv y --review-html).
+ *
+ * Con un presupuesto fijo de pares (por ejemplo los 25 pares que más vale la pena que revise una persona) se arma
+ * una cola priorizada: primero los pares más sospechosos (menor distancia). Los pares cuyos dos archivos están en
+ * un mismo grupo que ya tiene un par en la cola se omiten, de modo que el presupuesto no se gasta revisando varias
+ * veces la misma copia. Los empates (distancias iguales con 2 decimales) se ordenan al azar con una semilla
+ * (--review-seed), para repartir la revisión sin favorecer el orden de los directorios y poder repetir la cola.
+ *
+ * La cola se imprime en la consola y se puede exportar como lista de chequeo para los monitores, en CSV
+ * (con columnas vacías para la decisión y las observaciones) y en HTML (con casillas de verificación).
+ * La cola se puede repartir entre varios monitores (ver reparto_revision.go).
+ */
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// Cantidad de pares de la cola de revisión si solamente se indica el archivo CSV o HTML
+const PRESUPUESTO_REVISION_DEFECTO = 25
+
+// Estructura de un par de la cola de revisión
+// - posición en la cola (desde 1)
+// - índices de los dos archivos
+// - distancia entre ellos y su percentil entre las distancias de todos los pares
+// - identificador del grupo que representa (vacío si los archivos no están en un mismo grupo)
+// - monitor al que se asignó (vacío si la cola no se reparte)
+type ParRevision struct {
+	posicion  int
+	archivo1  int
+	archivo2  int
+	distancia float64
+	percentil float64
+	grupo     string
+	asistente string
+}
+
+/*
+ * Función para obtener el grupo que contiene a los dos archivos de un par
+ * param: grupos e índices de los dos archivos
+ * return: posición del primer grupo que contiene a ambos archivos (-1 si ninguno)
+ */
+func obtenerGrupoPar(grupos []Grupo, archivo1 int, archivo2 int) int {
+	for posicion, grupo := range grupos {
+		contiene1, contiene2 := false, false
+		for _, integrante := range grupo.integrantes {
+			contiene1 = contiene1 || integrante.indice == archivo1
+			contiene2 = contiene2 || integrante.indice == archivo2
+		}
+
+		if contiene1 && contiene2 {
+			return posicion
+		}
+	}
+
+	return -1
+}
+
+/*
+ * Función para construir la cola de revisión
+ * param: resultado del análisis, cantidad máxima de pares y semilla del orden de los empates
+ * return: los pares de la cola, del más sospechoso al menos sospechoso
+ */
+func construirColaRevision(resultado ResultadoAnalisis, presupuesto int, semilla int64) []ParRevision {
+	var candidatos []ParRevision
+
+	for i := range resultado.archivos {
+		for j := i + 1; j < len(resultado.archivos); j++ {
+			if esDistanciaCalculada(resultado.matriz[i][j]) {
+				candidatos = append(candidatos, ParRevision{archivo1: i, archivo2: j, distancia: resultado.matriz[i][j]})
+			}
+		}
+	}
+
+	aleatorio := rand.New(rand.NewSource(semilla))
+	aleatorio.Shuffle(len(candidatos), func(i, j int) {
+		candidatos[i], candidatos[j] = candidatos[j], candidatos[i]
+	})
+	sort.SliceStable(candidatos, func(i, j int) bool {
+		return math.Round(candidatos[i].distancia*100) < math.Round(candidatos[j].distancia*100)
+	})
+
+	distribucion := resultado.distribucion
+	if distribucion == nil {
+		distribucion = obtenerDistribucionDistancias(resultado)
+	}
+
+	var cola []ParRevision
+	representados := make(map[int]bool)
+
+	for _, candidato := range candidatos {
+		if len(cola) >= presupuesto {
+			break
+		}
+
+		if grupo := obtenerGrupoPar(resultado.grupos, candidato.archivo1, candidato.archivo2); grupo >= 0 {
+			if representados[grupo] {
+				continue
+			}
+			representados[grupo] = true
+			candidato.grupo = resultado.grupos[grupo].identificador
+		}
+
+		candidato.posicion = len(cola) + 1
+		candidato.percentil = calcularPercentil(distribucion, candidato.distancia)
+		cola = append(cola, candidato)
+	}
+
+	return cola
+}
+
+/*
+ * Función para imprimir la cola de revisión
+ * param: resultado del análisis, pares de la cola y bandas de severidad (vacías si no se clasifican)
+ */
+func imprimirColaRevision(resultado ResultadoAnalisis, cola []ParRevision, bandas []BandaSeveridad) {
+	fmt.Printf("\nCOLA DE REVISIÓN (%d PARES, DEL MÁS SOSPECHOSO AL MENOS SOSPECHOSO, UNO POR GRUPO)\n\n", len(cola))
+
+	colores := consolaConColores()
+
+	for _, par := range cola {
+		grupo := ""
+		if par.grupo != "" {
+			grupo = " [grupo " + par.grupo + "]"
+		}
+		if par.asistente != "" {
+			grupo += " -> " + par.asistente
+		}
+
+		fmt.Printf("%4d. %8.2f [p%.1f]%s %s - %s%s\n", par.posicion, par.distancia, par.percentil, etiquetarSeveridad(bandas, par.distancia, colores),
+			resultado.archivos[par.archivo1].nombre, resultado.archivos[par.archivo2].nombre, grupo)
+	}
+	fmt.Println()
+}
+
+/*
+ * Función para guardar la cola de revisión en un archivo CSV (separado por tabuladores, como la matriz de distancias),
+ * con las columnas DECISIÓN y OBSERVACIONES vacías para que las diligencie quien revisa
+ * param: resultado del análisis, pares de la cola, bandas de severidad y nombre del archivo CSV
+ */
+func generarColaRevisionCSV(resultado ResultadoAnalisis, cola []ParRevision, bandas []BandaSeveridad, nombreArchivo string) {
+	ptrArchivo, err := os.Create(nombreArchivo)
+
+	if err != nil {
+		panic(err)
+	}
+	defer ptrArchivo.Close()
+
+	fmt.Fprintf(ptrArchivo, "POSICIÓN\tARCHIVO 1\tESTUDIANTE 1\tARCHIVO 2\tESTUDIANTE 2\tDISTANCIA\tPERCENTIL\tSEVERIDAD\tGRUPO\tMONITOR\tDECISIÓN\tOBSERVACIONES\n")
+
+	for _, par := range cola {
+		archivo1, archivo2 := resultado.archivos[par.archivo1], resultado.archivos[par.archivo2]
+
+		fmt.Fprintf(ptrArchivo, "%d\t%s\t%s\t%s\t%s\t%.2f\t%.1f\t%s\t%s\t%s\t\t\n",
+			par.posicion,
+			archivo1.nombre, obtenerEstudiante(".", archivo1.ruta),
+			archivo2.nombre, obtenerEstudiante(".", archivo2.ruta),
+			par.distancia, par.percentil, clasificarSeveridad(bandas, par.distancia), par.grupo, par.asistente)
+	}
+}
+
+/*
+ * Función para guardar la cola de revisión como una lista de chequeo HTML
+ * param: resultado del análisis, pares de la cola, bandas de severidad, marca institucional, si el reporte es accesible
+ *        y nombre del archivo HTML
+ */
+func generarColaRevisionHTML(resultado ResultadoAnalisis, cola []ParRevision, bandas []BandaSeveridad, marca MarcaReporte, accesible bool, nombreArchivo string) {
+	ptrArchivo, err := os.Create(nombreArchivo)
+
+	if err != nil {
+		panic(err)
+	}
+	defer ptrArchivo.Close()
+
+	estilo := ""
+	if accesible {
+		estilo = "<style>\n" + ESTILO_ACCESIBLE + "</style>"
+	}
+
+	fmt.Fprintf(ptrArchivo, "<!DOCTYPE html><html lang=\"es\"><head><meta charset=\"utf-8\">%s%s</head><body>", titularReporte("titulo_cola", ""), estilo)
+	fmt.Fprint(ptrArchivo, generarSelectorIdioma(accesible), marca.encabezado())
+	fmt.Fprintf(ptrArchivo, "<h1>%s</h1><p>%d %s %s: %s</p>",
+		traducirReporte("cola"), len(cola), traducirReporte("pares_cola"), traducirReporte("distancia_maxima"), describirCriterioReporte(resultado.criterio))
+	fmt.Fprint(ptrArchivo, iniciarTablaReporte(accesible, traducirReporte("cola"),
+		traducirReporte("revisado"), "#", traducirReporte("archivo_1"), traducirReporte("archivo_2"), traducirReporte("distancia"), traducirReporte("percentil"),
+		traducirReporte("severidad"), traducirReporte("grupo"), traducirReporte("monitor"), traducirReporte("observaciones")))
+
+	for _, par := range cola {
+		posicion := strconv.Itoa(par.posicion)
+
+		fmt.Fprintf(ptrArchivo, "<tr><td><input type=\"checkbox\"%s></td>%s<td>%s</td><td>%s</td><td>%.2f</td><td>%.1f</td><td>%s</td><td>%s</td><td>%s</td><td contenteditable=\"true\"%s></td></tr>",
+			etiquetarReporte(accesible, "revisado_par", posicion), encabezarFilaReporte(accesible, posicion),
+			html.EscapeString(resultado.archivos[par.archivo1].nombre), html.EscapeString(resultado.archivos[par.archivo2].nombre),
+			par.distancia, par.percentil, obtenerInsigniaReporte(accesible, clasificarSeveridad(bandas, par.distancia)), html.EscapeString(par.grupo), html.EscapeString(par.asistente),
+			etiquetarReporte(accesible, "observaciones_par", posicion))
+	}
+
+	fmt.Fprintf(ptrArchivo, "%s%s%s</body></html>\n", terminarTablaReporte(accesible), marca.pie(), generarScriptIdiomas())
+}
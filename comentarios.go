@@ -0,0 +1,171 @@
+/*
+ * Eliminación de comentarios según el lenguaje (extensión) del archivo.
+ *
+ * Se reconocen los comentarios de línea (//, #, --) y de bloque (estilo C, <!-- -->) de los lenguajes más comunes,
+ * respetando las cadenas de caracteres para no eliminar, por ejemplo, un "//" dentro de una URL.
+ * Los saltos de línea de los comentarios se conservan para que los números de línea no cambien.
+ */
+
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Estructura para almacenar la sintaxis de los comentarios y cadenas de un lenguaje
+// - marcadores de comentario de línea
+// - marcadores de inicio y fin de comentario de bloque (vacíos si el lenguaje no tiene)
+// - delimitadores de cadenas de caracteres
+// - delimitadores de cadenas sin secuencias de escape (por ejemplo ` en Go)
+type SintaxisComentarios struct {
+	linea         []string
+	inicioBloque  string
+	finBloque     string
+	cadenas       string
+	cadenasCrudas string
+}
+
+// Sintaxis de los lenguajes con comentarios estilo C
+var sintaxisC = SintaxisComentarios{linea: []string{"//"}, inicioBloque: "/*", finBloque: "*/", cadenas: "\"'"}
+
+// Sintaxis de comentarios por extensión, las extensiones no incluidas usan la sintaxis de C
+var sintaxisPorExtension = map[string]SintaxisComentarios{
+	"go":   {linea: []string{"//"}, inicioBloque: "/*", finBloque: "*/", cadenas: "\"'", cadenasCrudas: "`"},
+	"js":   {linea: []string{"//"}, inicioBloque: "/*", finBloque: "*/", cadenas: "\"'`"},
+	"ts":   {linea: []string{"//"}, inicioBloque: "/*", finBloque: "*/", cadenas: "\"'`"},
+	"php":  {linea: []string{"//", "#"}, inicioBloque: "/*", finBloque: "*/", cadenas: "\"'"},
+	"py":   {linea: []string{"#"}, cadenas: "\"'"},
+	"rb":   {linea: []string{"#"}, cadenas: "\"'"},
+	"sh":   {linea: []string{"#"}, cadenas: "\"'"},
+	"r":    {linea: []string{"#"}, cadenas: "\"'"},
+	"pl":   {linea: []string{"#"}, cadenas: "\"'"},
+	"sql":  {linea: []string{"--"}, inicioBloque: "/*", finBloque: "*/", cadenas: "'\""},
+	"hs":   {linea: []string{"--"}, inicioBloque: "{-", finBloque: "-}", cadenas: "\""},
+	"html": {inicioBloque: "<!--", finBloque: "-->"},
+	"xml":  {inicioBloque: "<!--", finBloque: "-->"},
+	"m":    {linea: []string{"%"}, cadenas: "\""},
+	"pas":  {linea: []string{"//"}, inicioBloque: "{", finBloque: "}", cadenas: "'"},
+	"lisp": {linea: []string{";"}, cadenas: "\""},
+}
+
+/*
+ * Función para obtener la sintaxis de comentarios de una extensión
+ * param: extensión (con o sin punto)
+ * return: sintaxis de comentarios del lenguaje (la de C si no es conocida)
+ */
+func obtenerSintaxisComentarios(extension string) SintaxisComentarios {
+	if sintaxis, existe := sintaxisPorExtension[strings.ToLower(strings.TrimPrefix(extension, "."))]; existe {
+		return sintaxis
+	}
+
+	return sintaxisC
+}
+
+/*
+ * Función para buscar los comentarios de un contenido
+ * param: contenido del archivo y sintaxis de comentarios del lenguaje
+ * return: posición de inicio y de fin (sin incluir) de cada comentario, incluidos sus marcadores
+ */
+func buscarComentarios(contenido []byte, sintaxis SintaxisComentarios) [][2]int {
+	texto := string(contenido)
+	var comentarios [][2]int
+
+	for i := 0; i < len(texto); {
+		caracter := texto[i]
+
+		// Cadenas de caracteres (se saltan completas)
+		if strings.IndexByte(sintaxis.cadenas, caracter) >= 0 || strings.IndexByte(sintaxis.cadenasCrudas, caracter) >= 0 {
+			escape := strings.IndexByte(sintaxis.cadenasCrudas, caracter) < 0
+			fin := i + 1
+			for fin < len(texto) && texto[fin] != caracter {
+				if escape && texto[fin] == '\\' {
+					fin++
+				} else if escape && texto[fin] == '\n' {
+					break // Cadena sin cerrar o un apóstrofo que no inicia una cadena
+				}
+				fin++
+			}
+			if fin < len(texto) && texto[fin] == caracter {
+				fin++
+			}
+			if fin > len(texto) {
+				fin = len(texto)
+			}
+			i = fin
+			continue
+		}
+
+		// Comentarios de bloque
+		if sintaxis.inicioBloque != "" && strings.HasPrefix(texto[i:], sintaxis.inicioBloque) {
+			fin := strings.Index(texto[i+len(sintaxis.inicioBloque):], sintaxis.finBloque)
+			if fin < 0 {
+				fin = len(texto)
+			} else {
+				fin += i + len(sintaxis.inicioBloque) + len(sintaxis.finBloque)
+			}
+			comentarios = append(comentarios, [2]int{i, fin})
+			i = fin
+			continue
+		}
+
+		// Comentarios de línea
+		comentarioLinea := false
+		for _, marcador := range sintaxis.linea {
+			if strings.HasPrefix(texto[i:], marcador) {
+				comentarioLinea = true
+				break
+			}
+		}
+		if comentarioLinea {
+			fin := strings.IndexByte(texto[i:], '\n')
+			if fin < 0 {
+				fin = len(texto)
+			} else {
+				fin += i
+			}
+			comentarios = append(comentarios, [2]int{i, fin})
+			i = fin
+			continue
+		}
+
+		i++
+	}
+
+	return comentarios
+}
+
+/*
+ * Función para eliminar los comentarios de un contenido
+ * param: contenido del archivo y sintaxis de comentarios del lenguaje
+ * return: contenido sin comentarios (conservando los saltos de línea)
+ */
+func eliminarComentarios(contenido []byte, sintaxis SintaxisComentarios) []byte {
+	resultado := make([]byte, 0, len(contenido))
+
+	anterior := 0
+	for _, comentario := range buscarComentarios(contenido, sintaxis) {
+		resultado = append(resultado, contenido[anterior:comentario[0]]...)
+		resultado = append(resultado, conservarSaltosLinea(contenido[comentario[0]:comentario[1]])...)
+		anterior = comentario[1]
+	}
+
+	return append(resultado, contenido[anterior:]...)
+}
+
+/*
+ * Función para contar los caracteres con contenido (sin espacios ni comentarios) de un archivo
+ * param: contenido del archivo y su extensión
+ * return: cantidad de caracteres que no son espacios ni comentarios
+ */
+func contarContenidoSignificativo(contenido []byte, extension string) int {
+	cantidad := 0
+
+	for _, caracter := range string(eliminarComentarios(contenido, obtenerSintaxisComentarios(extension))) {
+		if !unicode.IsSpace(caracter) {
+			cantidad++
+		}
+	}
+
+	return cantidad
+}
@@ -0,0 +1,342 @@
+/*
+ * Reporte de composición del corpus (previo al análisis).
+ *
+ * Permite verificar que la exportación de los trabajos (por ejemplo desde el LMS) es la esperada:
+ * - cantidad de archivos, líneas y bytes por extensión (lenguaje)
+ * - cantidad de archivos y tamaño promedio por estudiante (subdirectorio inmediato del directorio base)
+ * Los archivos de un .zip se cuentan en lugar del .zip, como en el análisis. Se omiten los directorios y archivos
+ * ocultos (por ejemplo .git) y los de otros sistemas de control de versiones, y de los archivos binarios solo se
+ * cuentan los bytes, sin leerlos completos.
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Nombre del lenguaje asociado a las extensiones más comunes
+var nombreLenguaje = map[string]string{
+	"go":   "Go",
+	"java": "Java",
+	"c":    "C",
+	"h":    "C (cabecera)",
+	"cpp":  "C++",
+	"cc":   "C++",
+	"hpp":  "C++ (cabecera)",
+	"cs":   "C#",
+	"py":   "Python",
+	"js":   "JavaScript",
+	"ts":   "TypeScript",
+	"kt":   "Kotlin",
+	"rb":   "Ruby",
+	"php":  "PHP",
+	"rs":   "Rust",
+	"sql":  "SQL",
+	"html": "HTML",
+	"css":  "CSS",
+}
+
+// Directorios de los sistemas de control de versiones que no empiezan con punto (los ocultos se omiten siempre)
+var directoriosControlVersiones = map[string]bool{
+	"CVS":    true,
+	"_darcs": true,
+}
+
+// Estructura para almacenar la composición de los archivos de una extensión
+// - extensión (sin punto, vacía si el archivo no tiene extensión)
+// - cantidad de archivos, líneas y bytes
+type ComposicionLenguaje struct {
+	extension        string
+	cantidadArchivos int
+	cantidadLineas   int
+	cantidadBytes    int64
+}
+
+// Estructura para almacenar la composición de la entrega de un estudiante (subdirectorio inmediato)
+// - nombre del subdirectorio ("." para los archivos del directorio base)
+// - cantidad de archivos (todas las extensiones)
+// - cantidad y bytes de los archivos con la extensión a analizar
+type ComposicionEstudiante struct {
+	nombre           string
+	cantidadArchivos int
+	archivosAnalizar int
+	bytesAnalizar    int64
+}
+
+// Estructura para almacenar un archivo de un .zip hasta terminar de recorrerlo
+// - ruta virtual, tamaño y contenido (nil si es binario)
+type ArchivoInternoComposicion struct {
+	ruta      string
+	tamano    int64
+	contenido []byte
+}
+
+// Estructura con la composición completa del corpus
+type ComposicionCorpus struct {
+	lenguajes   []ComposicionLenguaje
+	estudiantes []ComposicionEstudiante
+}
+
+/*
+ * Función para contar la cantidad de líneas de un contenido.
+ * Una última línea sin salto de línea también se cuenta.
+ * param: contenido del archivo
+ * return: cantidad de líneas
+ */
+func contarLineas(contenido []byte) int {
+	lineas := bytes.Count(contenido, []byte("\n"))
+
+	if len(contenido) > 0 && contenido[len(contenido)-1] != '\n' {
+		lineas++
+	}
+
+	return lineas
+}
+
+/*
+ * Función para determinar el estudiante (subdirectorio inmediato del directorio base) al que pertenece un archivo
+ * param: directorio base y ruta del archivo
+ * return: nombre del subdirectorio inmediato o "." si el archivo está en el directorio base
+ */
+func obtenerEstudiante(directorioActual string, path string) string {
+	relativo, err := filepath.Rel(directorioActual, path)
+
+	if err != nil {
+		return "."
+	}
+
+	partes := strings.Split(filepath.ToSlash(relativo), "/")
+
+	if len(partes) < 2 {
+		return "."
+	}
+
+	return partes[0]
+}
+
+/*
+ * Función para obtener el lenguaje de un archivo según su extensión
+ * param: nombre o ruta del archivo
+ * return: nombre del lenguaje o la extensión (sin punto) si no es conocida
+ */
+func obtenerLenguaje(path string) string {
+	extension := strings.TrimPrefix(filepath.Ext(path), ".")
+
+	if lenguaje, existe := nombreLenguaje[strings.ToLower(extension)]; existe {
+		return lenguaje
+	}
+
+	return extension
+}
+
+/*
+ * Función para determinar si un archivo o directorio se omite de la composición
+ * param: nombre del archivo o directorio
+ * return: verdadero si es oculto (empieza con punto) o es un directorio de control de versiones
+ */
+func esOmitidoComposicion(nombre string) bool {
+	return (strings.HasPrefix(nombre, ".") && nombre != "." && nombre != "..") || directoriosControlVersiones[nombre]
+}
+
+/*
+ * Función para leer un archivo del corpus para la composición, sin leer completo un archivo binario
+ * param: ruta del archivo
+ * return: contenido del archivo (nil si es binario) o un error si no se puede leer
+ */
+func leerArchivoComposicion(ruta string) ([]byte, error) {
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		return nil, err
+	}
+	defer archivo.Close()
+
+	inicio := make([]byte, BYTES_DETECCION_BINARIO)
+	leidos, err := io.ReadFull(archivo, inicio)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	inicio = inicio[:leidos]
+	if esBinario(inicio, detectarCodificacion(inicio)) {
+		return nil, nil
+	}
+
+	resto, err := ioutil.ReadAll(archivo)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(inicio, resto...), nil
+}
+
+/*
+ * Función para calcular la composición del corpus a partir del directorio base
+ * param: directorio base, extensión de los archivos a analizar y límites de la extracción de los .zip
+ * return: la composición del corpus por extensión y por estudiante o un error si el directorio no se puede leer
+ */
+func calcularComposicionCorpus(directorioActual string, extension string, limites LimitesExtraccion) (ComposicionCorpus, error) {
+	lenguajes := make(map[string]*ComposicionLenguaje)
+	estudiantes := make(map[string]*ComposicionEstudiante)
+
+	// Cuenta un archivo del directorio o de un .zip (contenido nil si es binario, solo se cuentan sus bytes)
+	contar := func(ruta string, nombreEstudiante string, tamano int64, contenido []byte) {
+		extensionArchivo := strings.TrimPrefix(path.Ext(ruta), ".")
+
+		lenguaje, existe := lenguajes[extensionArchivo]
+		if !existe {
+			lenguaje = &ComposicionLenguaje{extension: extensionArchivo}
+			lenguajes[extensionArchivo] = lenguaje
+		}
+		lenguaje.cantidadArchivos++
+		lenguaje.cantidadLineas += contarLineas(contenido)
+		lenguaje.cantidadBytes += tamano
+
+		estudiante, existe := estudiantes[nombreEstudiante]
+		if !existe {
+			estudiante = &ComposicionEstudiante{nombre: nombreEstudiante}
+			estudiantes[nombreEstudiante] = estudiante
+		}
+		estudiante.cantidadArchivos++
+		if strings.HasSuffix(ruta, extension) {
+			estudiante.archivosAnalizar++
+			estudiante.bytesAnalizar += tamano
+		}
+	}
+
+	err := filepath.Walk(directorioActual,
+		func(ruta string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if ruta != directorioActual && esOmitidoComposicion(info.Name()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			nombreEstudiante := obtenerEstudiante(directorioActual, ruta)
+
+			if strings.EqualFold(filepath.Ext(ruta), ".zip") {
+				contenido, err := ioutil.ReadFile(ruta)
+				if err != nil {
+					return err
+				}
+
+				// Un .zip que no se puede leer o supera los límites se cuenta como un archivo (el análisis lo omite)
+				lector, err := zip.NewReader(bytes.NewReader(contenido), int64(len(contenido)))
+				if err == nil {
+					var internos []ArchivoInternoComposicion
+					restante := limites.tamano
+					err = recorrerZip(lector, filepath.ToSlash(ruta), 0, limites, &restante, func(rutaArchivo string, contenidoArchivo []byte) error {
+						for _, segmento := range strings.Split(strings.TrimPrefix(rutaArchivo, filepath.ToSlash(ruta)+"/"), "/") {
+							if esOmitidoComposicion(segmento) {
+								return nil
+							}
+						}
+						interno := ArchivoInternoComposicion{ruta: rutaArchivo, tamano: int64(len(contenidoArchivo))}
+						if !esBinario(contenidoArchivo, detectarCodificacion(contenidoArchivo)) {
+							interno.contenido = contenidoArchivo
+						}
+						internos = append(internos, interno)
+						return nil
+					})
+					if err == nil {
+						for _, interno := range internos {
+							contar(interno.ruta, nombreEstudiante, interno.tamano, interno.contenido)
+						}
+						return nil
+					}
+				}
+				contar(ruta, nombreEstudiante, info.Size(), nil)
+				return nil
+			}
+
+			contenido, err := leerArchivoComposicion(ruta)
+			if err != nil {
+				return err
+			}
+			contar(ruta, nombreEstudiante, info.Size(), contenido)
+
+			return nil
+		})
+
+	var composicion ComposicionCorpus
+
+	for _, lenguaje := range lenguajes {
+		composicion.lenguajes = append(composicion.lenguajes, *lenguaje)
+	}
+	for _, estudiante := range estudiantes {
+		composicion.estudiantes = append(composicion.estudiantes, *estudiante)
+	}
+
+	// Extensiones de la más frecuente a la menos frecuente y estudiantes en orden alfabético
+	sort.Slice(composicion.lenguajes, func(j, k int) bool {
+		if composicion.lenguajes[j].cantidadArchivos != composicion.lenguajes[k].cantidadArchivos {
+			return composicion.lenguajes[j].cantidadArchivos > composicion.lenguajes[k].cantidadArchivos
+		}
+		return composicion.lenguajes[j].extension < composicion.lenguajes[k].extension
+	})
+	sort.Slice(composicion.estudiantes, func(j, k int) bool {
+		return composicion.estudiantes[j].nombre < composicion.estudiantes[k].nombre
+	})
+
+	return composicion, err
+}
+
+/*
+ * Función para obtener la descripción de una extensión para el reporte
+ * param: extensión sin punto
+ * return: extensión con el nombre del lenguaje si es conocido
+ */
+func describirExtension(extension string) string {
+	if extension == "" {
+		return "(sin extensión)"
+	}
+
+	if lenguaje, existe := nombreLenguaje[strings.ToLower(extension)]; existe {
+		return "." + extension + " (" + lenguaje + ")"
+	}
+
+	return "." + extension
+}
+
+/*
+ * Función para imprimir la composición del corpus
+ * param: composición del corpus y extensión de los archivos a analizar
+ */
+func imprimirComposicionCorpus(composicion ComposicionCorpus, extension string) {
+	fmt.Print("COMPOSICIÓN DEL CORPUS\n\n")
+
+	fmt.Printf("\t%-25s %8s %10s %12s\n", "EXTENSIÓN", "ARCHIVOS", "LÍNEAS", "BYTES")
+	for _, lenguaje := range composicion.lenguajes {
+		fmt.Printf("\t%-25s %8d %10d %12d\n", describirExtension(lenguaje.extension), lenguaje.cantidadArchivos, lenguaje.cantidadLineas, lenguaje.cantidadBytes)
+	}
+	fmt.Println()
+
+	fmt.Printf("\t%-25s %8s %8s %14s\n", "ESTUDIANTE", "ARCHIVOS", "."+extension, "PROMEDIO BYTES")
+	for _, estudiante := range composicion.estudiantes {
+		promedio := 0.0
+		if estudiante.archivosAnalizar > 0 {
+			promedio = float64(estudiante.bytesAnalizar) / float64(estudiante.archivosAnalizar)
+		}
+		fmt.Printf("\t%-25s %8d %8d %14.2f\n", estudiante.nombre, estudiante.cantidadArchivos, estudiante.archivosAnalizar, promedio)
+
+		if estudiante.archivosAnalizar == 0 && estudiante.nombre != "." {
+			fmt.Printf("\t    (!) sin archivos de extensión .%s\n", extension)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCalcularComposicionCorpus(t *testing.T) {
+	limites := LimitesExtraccion{profundidad: 1, tamano: 1 << 20}
+	directorio := crearCorpusPrueba(t, map[string]string{
+		"ana/main.go":           "package main\n\nfunc main() {}\n",
+		"ana/logo.png":          "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\n\n\n",
+		"ana/.git/config":       "[core]\n",
+		"ana/.git/HEAD":         "ref: refs/heads/main\n",
+		"ana/.idea/misc.xml":    "<project/>\n",
+		"ana/.DS_Store":         "\x00\x00\x00\x01Bud1",
+		"ana/CVS/Entries":       "/main.go/1.1///\n",
+		"beto/entrega.zip":      crearZipPrueba(t, map[string]string{"src/main.go": "package main\n", "src/.git/HEAD": "ref\n", "LEEME.txt": "a\nb\n"}),
+		"carla/entrega.zip":     "no es un zip",
+		"carla/notas.txt":       "una línea",
+		"carla/.oculto/otro.go": "package main\n",
+	})
+
+	composicion, err := calcularComposicionCorpus(directorio, ".go", limites)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lenguajes := map[string]ComposicionLenguaje{}
+	for _, lenguaje := range composicion.lenguajes {
+		lenguajes[lenguaje.extension] = lenguaje
+	}
+	esperados := []struct {
+		extension        string
+		archivos, lineas int
+		bytes            int64
+	}{
+		{"go", 2, 4, 42},
+		{"png", 1, 0, 19},
+		{"txt", 2, 3, 14},
+		{"zip", 1, 0, 12},
+	}
+	if len(lenguajes) != len(esperados) {
+		t.Errorf("extensiones = %v, se esperaban %d", composicion.lenguajes, len(esperados))
+	}
+	for _, esperado := range esperados {
+		lenguaje := lenguajes[esperado.extension]
+		if lenguaje.cantidadArchivos != esperado.archivos || lenguaje.cantidadLineas != esperado.lineas || lenguaje.cantidadBytes != esperado.bytes {
+			t.Errorf("extensión %q = %+v, se esperaban %d archivos, %d líneas y %d bytes", esperado.extension, lenguaje, esperado.archivos, esperado.lineas, esperado.bytes)
+		}
+	}
+
+	// Los archivos del .zip son del estudiante que lo entregó
+	estudiantes := map[string]ComposicionEstudiante{}
+	for _, estudiante := range composicion.estudiantes {
+		estudiantes[estudiante.nombre] = estudiante
+	}
+	if beto := estudiantes["beto"]; beto.cantidadArchivos != 2 || beto.archivosAnalizar != 1 {
+		t.Errorf("estudiante beto = %+v, se esperaban 2 archivos y 1 a analizar", beto)
+	}
+	if carla := estudiantes["carla"]; carla.cantidadArchivos != 2 || carla.archivosAnalizar != 0 {
+		t.Errorf("estudiante carla = %+v, se esperaban 2 archivos y ninguno a analizar", carla)
+	}
+}
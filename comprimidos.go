@@ -0,0 +1,261 @@
+/*
+ * Lectura de archivos .zip que contienen otros archivos .zip (por ejemplo la exportación de un proyecto del IDE
+ * que el estudiante comprime de nuevo para entregarla).
+ *
+ * Los .zip se leen en memoria al recorrer el directorio de las entregas (también las descargadas de Canvas), del
+ * código base, de los archivos de control o de la autoverificación y al leer un flujo tar, sin extraerlos en el disco. Los .zip internos se leen de forma recursiva y sus archivos se
+ * identifican con una ruta virtual que incluye el nombre de cada .zip, por ejemplo
+ * "./ana/entrega.zip/proyecto.zip/src/Main.java". Para protegerse de las bombas zip se limitan:
+ * - la profundidad de los .zip internos (--zip-depth)
+ * - el tamaño total descomprimido de cada archivo .zip, contando los bytes realmente leídos (--zip-max-size)
+ * Un .zip de una entrega que no es válido o supera los límites se omite y se reporta en el reporte de validez.
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Contenido de los archivos de los .zip de un listado de entregas, por su ruta virtual (los demás archivos del listado
+// se leen del disco)
+type ArchivosComprimidos map[string][]byte
+
+// Estructura de un archivo .zip omitido del análisis (también su representación JSON)
+// - ruta del .zip en el listado
+// - motivo (no es un .zip válido o supera los límites de la extracción)
+// - tamaño del .zip en bytes (para el reporte de validez)
+type ZipOmitido struct {
+	Archivo string `json:"archivo"`
+	Motivo  string `json:"motivo"`
+	tamano  int
+}
+
+// Estructura de los límites de la extracción de un archivo .zip y de la lectura de un flujo tar
+// - profundidad máxima de los .zip internos (0 no permite .zip internos)
+// - tamaño máximo descomprimido en bytes
+// - tamaño máximo en bytes de cada archivo de un flujo tar y cantidad máxima de archivos leídos del flujo
+type LimitesExtraccion struct {
+	profundidad    int
+	tamano         int64
+	tamanoEntrada  int64
+	maximoArchivos int
+}
+
+/*
+ * Función para crear los límites de la extracción indicados por el usuario
+ * param: parámetros de ejecución
+ * return: los límites o un error si no son válidos
+ */
+func crearLimitesExtraccion(parametros Parametros) (LimitesExtraccion, error) {
+	if parametros.profundidadZip < 0 || parametros.tamanoMaximoZip < 1 {
+		return LimitesExtraccion{}, fmt.Errorf("La profundidad (--zip-depth) no puede ser negativa y el tamaño máximo (--zip-max-size) debe ser al menos 1 MB")
+	}
+	if parametros.tamanoMaximoTar < 1 || parametros.maximoArchivosTar < 1 {
+		return LimitesExtraccion{}, fmt.Errorf("El tamaño máximo de cada archivo del flujo tar (--tar-max-entry-size) debe ser al menos 1 MB y la cantidad máxima de archivos (--tar-max-files) al menos 1")
+	}
+
+	return LimitesExtraccion{
+		profundidad:    parametros.profundidadZip,
+		tamano:         int64(parametros.tamanoMaximoZip) * 1024 * 1024,
+		tamanoEntrada:  int64(parametros.tamanoMaximoTar) * 1024 * 1024,
+		maximoArchivos: parametros.maximoArchivosTar,
+	}, nil
+}
+
+/*
+ * Función para recorrer los archivos de un .zip, extrayendo los .zip internos de forma recursiva
+ * param: lector del .zip, ruta virtual del .zip, profundidad actual, límites de la extracción, bytes que
+ *        aún se pueden descomprimir y la función que procesa cada archivo (ruta virtual y contenido)
+ * return: un error si el .zip no se puede leer, supera los límites o el procesamiento falla
+ */
+func recorrerZip(lector *zip.Reader, ruta string, profundidad int, limites LimitesExtraccion, restante *int64, procesar func(string, []byte) error) error {
+	for _, archivo := range lector.File {
+		if archivo.FileInfo().IsDir() {
+			continue
+		}
+
+		rutaArchivo := path.Join(ruta, strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(archivo.Name)), "/"))
+
+		flujo, err := archivo.Open()
+		if err != nil {
+			return err
+		}
+		// No se confía en el tamaño declarado en el .zip, se cuentan los bytes leídos
+		contenido, err := ioutil.ReadAll(io.LimitReader(flujo, *restante+1))
+		flujo.Close()
+		if err != nil {
+			return err
+		}
+		if int64(len(contenido)) > *restante {
+			return fmt.Errorf("\"%s\" supera el tamaño máximo descomprimido (--zip-max-size)", rutaArchivo)
+		}
+		*restante -= int64(len(contenido))
+
+		if !strings.EqualFold(path.Ext(rutaArchivo), ".zip") {
+			if err = procesar(rutaArchivo, contenido); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if profundidad >= limites.profundidad {
+			return fmt.Errorf("\"%s\" supera la profundidad máxima de archivos .zip internos (--zip-depth)", rutaArchivo)
+		}
+
+		interno, err := zip.NewReader(bytes.NewReader(contenido), int64(len(contenido)))
+		if err != nil {
+			return fmt.Errorf("\"%s\" no es un archivo .zip válido: %v", rutaArchivo, err)
+		}
+		if err = recorrerZip(interno, rutaArchivo, profundidad+1, limites, restante, procesar); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Función para leer los archivos de una extensión de un .zip en memoria (incluyendo los .zip internos)
+ * param: contenido y ruta virtual del .zip, extensión de los archivos y límites de la extracción
+ * return: rutas virtuales y contenido de los archivos, o un error si el .zip no se puede leer o supera los límites
+ */
+func leerArchivosZip(contenido []byte, ruta string, extension string, limites LimitesExtraccion) ([]string, [][]byte, error) {
+	var rutas []string
+	var contenidos [][]byte
+
+	lector, err := zip.NewReader(bytes.NewReader(contenido), int64(len(contenido)))
+	if err != nil {
+		return rutas, contenidos, fmt.Errorf("\"%s\" no es un archivo .zip válido: %v", ruta, err)
+	}
+
+	restante := limites.tamano
+
+	err = recorrerZip(lector, ruta, 0, limites, &restante, func(rutaArchivo string, contenidoArchivo []byte) error {
+		if strings.HasSuffix(rutaArchivo, extension) {
+			rutas = append(rutas, rutaArchivo)
+			contenidos = append(contenidos, contenidoArchivo)
+		}
+		return nil
+	})
+
+	return rutas, contenidos, err
+}
+
+/*
+ * Función para obtener el listado de las entregas de un directorio, incluyendo los archivos de la extensión que están
+ * dentro de los .zip (con su ruta virtual). Un .zip que no es válido o supera los límites se omite sin detener el
+ * análisis, para que la entrega de un estudiante no impida analizar las demás.
+ * param: directorio de las entregas, extensión de los archivos y límites de la extracción
+ * return: rutas relativas de los archivos (las de cada .zip en orden alfabético, en la posición del .zip), contenido
+ *         de los archivos de los .zip, .zip omitidos con su motivo o un error si el directorio no se puede leer
+ */
+func obtenerListadoEntregas(directorioActual string, extension string, limites LimitesExtraccion) ([]string, ArchivosComprimidos, []ZipOmitido, error) {
+	var archivos []string
+	var omitidos []ZipOmitido
+	comprimidos := make(ArchivosComprimidos)
+
+	err := filepath.Walk(directorioActual, func(ruta string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relativa := obtenerRutaRelativa(directorioActual, ruta)
+		if !strings.EqualFold(filepath.Ext(ruta), ".zip") {
+			if strings.HasSuffix(ruta, extension) {
+				archivos = append(archivos, relativa)
+			}
+			return nil
+		}
+
+		contenido, err := ioutil.ReadFile(ruta)
+		if err != nil {
+			return err
+		}
+		rutas, contenidos, err := leerArchivosZip(contenido, strings.TrimPrefix(relativa, "./"), extension, limites)
+		if err != nil {
+			omitidos = append(omitidos, ZipOmitido{Archivo: relativa, Motivo: err.Error(), tamano: len(contenido)})
+			return nil
+		}
+
+		internos := make([]string, len(rutas))
+		for i := range rutas {
+			internos[i] = "./" + rutas[i]
+			comprimidos[internos[i]] = contenidos[i]
+		}
+		sort.Strings(internos)
+
+		archivos = append(archivos, internos...)
+		return nil
+	})
+
+	return archivos, comprimidos, omitidos, err
+}
+
+/*
+ * Función para obtener el listado de un directorio que no es de las entregas (código base, archivos de control o
+ * corpus de la autoverificación), con las rutas unidas al directorio
+ * param: directorio, extensión de los archivos y límites de la extracción
+ * return: rutas de los archivos, contenido de los archivos de los .zip o un error si el directorio no se puede leer
+ *         o un .zip no es válido (los archivos del docente no se omiten)
+ */
+func obtenerListadoDirectorio(directorio string, extension string, limites LimitesExtraccion) ([]string, ArchivosComprimidos, error) {
+	listado, comprimidos, omitidos, err := obtenerListadoEntregas(directorio, extension, limites)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(omitidos) > 0 {
+		return nil, nil, fmt.Errorf("%s", omitidos[0].Motivo)
+	}
+
+	unidos := make(ArchivosComprimidos, len(comprimidos))
+	for i, ruta := range listado {
+		listado[i] = filepath.Join(directorio, ruta)
+		if contenido, existe := comprimidos[ruta]; existe {
+			unidos[listado[i]] = contenido
+		}
+	}
+
+	return listado, unidos, nil
+}
+
+/*
+ * Función para imprimir los archivos .zip omitidos del análisis
+ * param: .zip omitidos y formato de las rutas
+ */
+func imprimirZipOmitidos(omitidos []ZipOmitido, formatoRutas FormatoRutas) {
+	if len(omitidos) == 0 {
+		return
+	}
+
+	fmt.Println("Archivos .zip omitidos por no ser válidos o superar los límites de la extracción (--zip-depth, --zip-max-size):")
+	for _, omitido := range omitidos {
+		fmt.Println("\t" + formatoRutas.formatear(omitido.Archivo) + ": " + omitido.Motivo)
+	}
+	fmt.Println()
+}
+
+/*
+ * Función para leer un archivo de un listado de entregas, del disco o de un .zip
+ * param: ruta del archivo en el listado
+ * return: el contenido del archivo o un error si no se puede leer
+ */
+func (comprimidos ArchivosComprimidos) leer(ruta string) ([]byte, error) {
+	if contenido, existe := comprimidos[ruta]; existe {
+		return contenido, nil
+	}
+
+	return ioutil.ReadFile(ruta)
+}
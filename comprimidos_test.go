@@ -0,0 +1,119 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestObtenerListadoEntregas(t *testing.T) {
+	programa := "package main\n\nfunc main() { println(\"hola mundo\") }\n"
+	proyecto := crearZipPrueba(t, map[string]string{"src/main.go": programa, "LEEME.txt": "proyecto"})
+
+	directorio := crearCorpusPrueba(t, map[string]string{
+		"ana/main.go":      programa,
+		"luis/entrega.zip": crearZipPrueba(t, map[string]string{"proyecto.zip": proyecto, "b.go": "package b", "a.go": "package a"}),
+	})
+	limites := LimitesExtraccion{profundidad: 1, tamano: 1 << 20}
+
+	listado, archivosZip, omitidos, err := obtenerListadoEntregas(directorio, "go", limites)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	esperado := []string{"./ana/main.go", "./luis/entrega.zip/a.go", "./luis/entrega.zip/b.go", "./luis/entrega.zip/proyecto.zip/src/main.go"}
+	if !reflect.DeepEqual(listado, esperado) || len(omitidos) != 0 {
+		t.Fatalf("listado = %v y .zip omitidos %v, se esperaba %v", listado, omitidos, esperado)
+	}
+	if contenido, err := archivosZip.leer(esperado[3]); err != nil || string(contenido) != programa {
+		t.Errorf("leer(%s) = %q, %v", esperado[3], contenido, err)
+	}
+	if contenido, err := archivosZip.leer(esperado[0]); err != nil || string(contenido) != programa {
+		t.Errorf("leer(%s) = %q, %v", esperado[0], contenido, err)
+	}
+
+	// Cada listado tiene su propio contenido de los .zip
+	if _, otros, _, _ := obtenerListadoEntregas(crearCorpusPrueba(t, map[string]string{"eva/main.go": programa}), "go", limites); len(otros) != 0 {
+		t.Errorf("contenido de los .zip de otro listado = %v", otros)
+	}
+}
+
+func TestObtenerListadoEntregasZipOmitidos(t *testing.T) {
+	programa := "package main\n\nfunc main() { println(\"hola mundo\") }\n"
+	proyecto := crearZipPrueba(t, map[string]string{"src/main.go": programa})
+
+	directorio := crearCorpusPrueba(t, map[string]string{
+		"ana/main.go":      programa,
+		"eva/entrega.zip":  "no es un archivo .zip",
+		"luis/entrega.zip": crearZipPrueba(t, map[string]string{"proyecto.zip": proyecto}),
+		"rosa/entrega.zip": crearZipPrueba(t, map[string]string{"main.go": strings.Repeat("a", 2000)}),
+	})
+	limites := LimitesExtraccion{profundidad: 0, tamano: 1000}
+
+	listado, archivosZip, omitidos, err := obtenerListadoEntregas(directorio, "go", limites)
+	if err != nil {
+		t.Fatalf("un .zip no válido detuvo el listado: %v", err)
+	}
+	if !reflect.DeepEqual(listado, []string{"./ana/main.go"}) || len(archivosZip) != 0 {
+		t.Errorf("listado = %v con %d archivos de .zip, se esperaba solamente ./ana/main.go", listado, len(archivosZip))
+	}
+
+	motivos := map[string]string{"./eva/entrega.zip": "no es un archivo .zip válido", "./luis/entrega.zip": "--zip-depth", "./rosa/entrega.zip": "--zip-max-size"}
+	if len(omitidos) != len(motivos) {
+		t.Fatalf(".zip omitidos = %+v", omitidos)
+	}
+	for _, omitido := range omitidos {
+		if !strings.Contains(omitido.Motivo, motivos[omitido.Archivo]) || omitido.tamano == 0 {
+			t.Errorf("%s omitido por %q, se esperaba %q", omitido.Archivo, omitido.Motivo, motivos[omitido.Archivo])
+		}
+	}
+
+	// Los archivos del docente no se omiten
+	if _, _, err = obtenerListadoDirectorio(directorio, "go", limites); err == nil {
+		t.Error("listado del docente con un .zip no válido sin error")
+	}
+}
+
+func TestAnalizarDirectorioConZip(t *testing.T) {
+	programa := "package main\n\nfunc main() { println(\"hola mundo\") }\n"
+
+	parametros := crearParametrosPrueba()
+	parametros.criterioDistancia = crearCriterioValor(0)
+	parametros.deduplicar = true
+
+	resultado := analizarCorpusPrueba(t, parametros, map[string]string{
+		"ana/main.go":      programa,
+		"eva/main.go":      "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"otro\")\n}\n",
+		"luis/entrega.zip": crearZipPrueba(t, map[string]string{"proyecto.zip": crearZipPrueba(t, map[string]string{"src/main.go": programa})}),
+	})
+
+	ana, luis := buscarArchivoPrueba(t, resultado, "./ana/main.go"), buscarArchivoPrueba(t, resultado, "./luis/entrega.zip/proyecto.zip/src/main.go")
+	if resultado.matriz[ana][luis] != 0 {
+		t.Errorf("distancia entre la copia y el archivo del .zip = %v, se esperaba 0", resultado.matriz[ana][luis])
+	}
+	if len(resultado.grupos) != 1 || len(resultado.grupos[0].integrantes) != 2 {
+		t.Errorf("grupos = %v, se esperaba el grupo de la copia", resultado.grupos)
+	}
+}
+
+func TestAnalizarDirectorioConZipOmitido(t *testing.T) {
+	parametros := crearParametrosPrueba()
+	parametros.reporteValidez = "validez.csv"
+
+	resultado, salida := analizarCorpusSalidaPrueba(t, parametros, map[string]string{
+		"ana/main.go":    "package main\n\nfunc main() { println(\"hola mundo\") }\n",
+		"eva/main.go":    "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"otro\")\n}\n",
+		"luis/tarea.zip": "no es un archivo .zip",
+	})
+
+	if len(resultado.archivos) != 2 {
+		t.Errorf("archivos analizados = %d, se esperaban 2", len(resultado.archivos))
+	}
+	omitido := resultado.validez[len(resultado.validez)-1]
+	if omitido.Archivo != "./luis/tarea.zip" || omitido.Estudiante != "luis" || omitido.Estado != VALIDEZ_OMITIDO || omitido.Motivo == "" {
+		t.Errorf("validez del .zip omitido = %+v", omitido)
+	}
+	if !strings.Contains(salida, "1 de 3 archivos") {
+		t.Errorf("salida sin el .zip omitido en el reporte de validez:\n%s", salida)
+	}
+}
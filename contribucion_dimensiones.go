@@ -0,0 +1,311 @@
+/*
+ * Contribución de cada dimensión de las características a la distancia de un par (páginas de los pares del paquete).
+ *
+ * Una distancia baja no dice por qué dos archivos se parecen. La página de cada par del paquete (--bundle) incluye
+ * un gráfico de barras con las dimensiones de los vectores de características que más aportan:
+ * - a la diferencia: la proporción de cada dimensión en el cuadrado de la distancia, (a - b)² / Σ (a - b)²
+ * - a lo común: la proporción de cada dimensión en la frecuencia compartida, min(a, b) / Σ min(a, b)
+ *   (solo para frecuencias, no para vectores con signo como los del hashing o de una proyección)
+ * Con el motor "ascii" cada dimensión es un carácter y se resume el aporte por categoría (letras, dígitos, espacios,
+ * símbolos), de modo que se ve si el parecido viene de la estructura (símbolos y espacios) o del texto (letras de los
+ * identificadores, comentarios y cadenas). Con el motor "tokens" cada dimensión es un n-grama (palabras o solo
+ * símbolos); con --hash-dims o --reduce las dimensiones no tienen nombre y se muestran por su posición. Con el motor
+ * "ast" cada dimensión es un tipo de nodo o un subárbol normalizado (por su raíz y su cantidad de nodos).
+ * El motor "lines" no tiene vectores, por lo que sus páginas no incluyen el gráfico.
+ */
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Cantidad de dimensiones de cada gráfico de contribución
+const DIMENSIONES_CONTRIBUCION = 10
+
+// Ancho en píxeles de una barra con el 100% del aporte
+const ANCHO_BARRA_CONTRIBUCION = 200
+
+// Estructura de la contribución de una dimensión a la distancia de un par
+// - etiqueta de la dimensión (carácter, n-grama o posición) y su categoría (clave del texto, vacía si no tiene)
+// - valor de la dimensión en cada archivo del par
+// - proporción de la dimensión en el cuadrado de la distancia y en la frecuencia compartida
+type ContribucionDimension struct {
+	etiqueta   string
+	categoria  string
+	valor1     float64
+	valor2     float64
+	diferencia float64
+	comun      float64
+}
+
+/*
+ * Función para obtener la etiqueta y la categoría de un carácter (una dimensión del motor "ascii")
+ * param: posición de la dimensión (primer byte del carácter UTF-8)
+ * return: la etiqueta del carácter y la clave de su categoría
+ */
+func etiquetarCaracter(posicion int) (string, string) {
+	caracter := rune(posicion)
+
+	switch {
+	case posicion >= utf8.RuneSelf:
+		return fmt.Sprintf("0x%02X", posicion), "categoria_no_ascii"
+	case caracter == ' ':
+		return "␣", "categoria_espacios"
+	case caracter == '\n':
+		return `\n`, "categoria_espacios"
+	case caracter == '\t':
+		return `\t`, "categoria_espacios"
+	case caracter == '\r':
+		return `\r`, "categoria_espacios"
+	case unicode.IsLetter(caracter) || caracter == '_':
+		return string(caracter), "categoria_letras"
+	case unicode.IsDigit(caracter):
+		return string(caracter), "categoria_digitos"
+	case unicode.IsControl(caracter):
+		return fmt.Sprintf("0x%02X", posicion), "categoria_simbolos"
+	}
+
+	return string(caracter), "categoria_simbolos"
+}
+
+/*
+ * Función para obtener el texto de cada n-grama de un archivo (motor "tokens" sin --hash-dims)
+ * param: arreglo con la información del código fuente de los archivos, índice del archivo y analizador léxico del
+ *        motor (nil para el tokenizador genérico)
+ * return: texto y categoría de cada n-grama, por su hash
+ */
+func obtenerTextosNgramas(tablaCodigoFuente []CodigoFuente, indice int, lexico *AnalizadorLexico) (map[uint64]string, map[uint64]string) {
+	var tokens []Token
+	if lexico != nil {
+		tokens = lexico.tokenizar(tablaCodigoFuente[indice].contenido)
+	} else {
+		tokens = obtenerTokensArchivo(tablaCodigoFuente, indice)
+	}
+	textos, categorias := make(map[uint64]string), make(map[uint64]string)
+
+	for inicio, hash := range agregarHashesVentanas(nil, tokens, LONGITUD_NGRAMA_TOKENS) {
+		if _, existe := textos[hash]; existe {
+			continue
+		}
+
+		palabras, categoria := make([]string, LONGITUD_NGRAMA_TOKENS), "categoria_simbolos"
+		for k, token := range tokens[inicio : inicio+LONGITUD_NGRAMA_TOKENS] {
+			palabras[k] = token.texto
+			if primero, _ := utf8.DecodeRuneInString(token.texto); unicode.IsLetter(primero) || unicode.IsDigit(primero) || primero == '_' {
+				categoria = "categoria_palabras"
+			}
+		}
+
+		textos[hash], categorias[hash] = strings.Join(palabras, " "), categoria
+	}
+
+	return textos, categorias
+}
+
+/*
+ * Función para calcular la contribución de cada dimensión de las características a la distancia de un par
+ * param: resultado del análisis (con las características de los archivos) e índices de los dos archivos
+ * return: la contribución de cada dimensión (vacío si el motor no tiene vectores) y si los valores son frecuencias
+ *         (no negativas, con las que se calcula lo común)
+ */
+func calcularContribuciones(resultado ResultadoAnalisis, i int, j int) ([]ContribucionDimension, bool) {
+	archivo1, archivo2 := resultado.archivos[i], resultado.archivos[j]
+	var contribuciones []ContribucionDimension
+	frecuencias := true
+
+	// Con el motor "ascii" cada dimensión es un carácter, salvo con los n-gramas de caracteres (--features=ngram)
+	ascii := strings.HasPrefix(resultado.motor, "ascii")
+	caracteres := ascii && !strings.Contains(resultado.motor, "-gramas de caracteres")
+
+	switch {
+	case archivo1.proyeccion != nil && archivo2.proyeccion != nil:
+		// Con el motor "ascii" la proyección son las frecuencias relativas de los caracteres (--normalize=relative)
+		frecuencias = ascii
+		for k := range archivo1.proyeccion {
+			contribucion := ContribucionDimension{etiqueta: "#" + strconv.Itoa(k+1), valor1: archivo1.proyeccion[k], valor2: archivo2.proyeccion[k]}
+			if caracteres {
+				contribucion.etiqueta, contribucion.categoria = etiquetarCaracter(k)
+			}
+			contribuciones = append(contribuciones, contribucion)
+		}
+
+	case archivo1.ngramas != nil && archivo2.ngramas != nil:
+		// Los n-gramas de un analizador léxico (--lexer) se etiquetan con sus tokens normalizados
+		lexico := obtenerAnalizadorLexicoMotor(resultado.motor)
+		var textos, categorias, textos2, categorias2 map[uint64]string
+		if strings.HasPrefix(resultado.motor, "ast") {
+			textos, categorias = etiquetarCaracteristicasAST(archivo1.contenido)
+			textos2, categorias2 = etiquetarCaracteristicasAST(archivo2.contenido)
+		} else {
+			textos, categorias = obtenerTextosNgramas(resultado.archivos, i, lexico)
+			textos2, categorias2 = obtenerTextosNgramas(resultado.archivos, j, lexico)
+		}
+		for hash, texto := range textos2 {
+			textos[hash], categorias[hash] = texto, categorias2[hash]
+		}
+
+		for hash, valor := range archivo1.ngramas {
+			contribuciones = append(contribuciones, ContribucionDimension{etiqueta: textos[hash], categoria: categorias[hash], valor1: valor, valor2: archivo2.ngramas[hash]})
+		}
+		for hash, valor := range archivo2.ngramas {
+			if _, existe := archivo1.ngramas[hash]; !existe {
+				contribuciones = append(contribuciones, ContribucionDimension{etiqueta: textos[hash], categoria: categorias[hash], valor2: valor})
+			}
+		}
+
+	case archivo1.caracteristica != nil && archivo2.caracteristica != nil:
+		frecuencias = ascii
+		for k := range archivo1.caracteristica {
+			contribucion := ContribucionDimension{etiqueta: "#" + strconv.Itoa(k+1), valor1: float64(archivo1.caracteristica[k]), valor2: float64(archivo2.caracteristica[k])}
+			if caracteres {
+				contribucion.etiqueta, contribucion.categoria = etiquetarCaracter(k)
+			}
+			contribuciones = append(contribuciones, contribucion)
+		}
+	}
+
+	sumaDiferencia, sumaComun := 0.0, 0.0
+	for k, contribucion := range contribuciones {
+		diferencia := contribucion.valor1 - contribucion.valor2
+		contribuciones[k].diferencia = diferencia * diferencia
+		sumaDiferencia += contribuciones[k].diferencia
+
+		if frecuencias {
+			if contribucion.valor1 < contribucion.valor2 {
+				contribuciones[k].comun = contribucion.valor1
+			} else {
+				contribuciones[k].comun = contribucion.valor2
+			}
+			sumaComun += contribuciones[k].comun
+		}
+	}
+
+	for k := range contribuciones {
+		if sumaDiferencia > 0 {
+			contribuciones[k].diferencia /= sumaDiferencia
+		}
+		if sumaComun > 0 {
+			contribuciones[k].comun /= sumaComun
+		}
+	}
+
+	return contribuciones, frecuencias
+}
+
+/*
+ * Función para obtener las dimensiones con el mayor aporte (en empate, por etiqueta para que el orden sea estable)
+ * param: contribución de cada dimensión, aporte a comparar de una contribución y cantidad de dimensiones
+ * return: las dimensiones con aporte positivo, de la de mayor a la de menor aporte
+ */
+func seleccionarContribuciones(contribuciones []ContribucionDimension, aporte func(ContribucionDimension) float64, cantidad int) []ContribucionDimension {
+	var seleccionadas []ContribucionDimension
+	for _, contribucion := range contribuciones {
+		if aporte(contribucion) > 0 {
+			seleccionadas = append(seleccionadas, contribucion)
+		}
+	}
+
+	sort.Slice(seleccionadas, func(a, b int) bool {
+		if aporte(seleccionadas[a]) != aporte(seleccionadas[b]) {
+			return aporte(seleccionadas[a]) > aporte(seleccionadas[b])
+		}
+		return seleccionadas[a].etiqueta < seleccionadas[b].etiqueta
+	})
+
+	if len(seleccionadas) > cantidad {
+		seleccionadas = seleccionadas[:cantidad]
+	}
+
+	return seleccionadas
+}
+
+/*
+ * Función para generar una tabla de barras con el aporte de las dimensiones
+ * param: dimensiones, aporte a graficar de una contribución, clave del título, clase de las barras y si el reporte es accesible
+ * return: la tabla en HTML
+ */
+func generarBarrasContribucion(dimensiones []ContribucionDimension, aporte func(ContribucionDimension) float64, titulo string, clase string, accesible bool) string {
+	var tabla strings.Builder
+
+	fmt.Fprintf(&tabla, "<h3>%s</h3>\n", traducirReporte(titulo))
+	tabla.WriteString(iniciarTablaReporte(accesible, traducirReporte(titulo),
+		traducirReporte("dimension"), traducirReporte("categoria"), traducirReporte("archivo_1"), traducirReporte("archivo_2"), traducirReporte("aporte")))
+
+	for _, dimension := range dimensiones {
+		categoria := "-"
+		if dimension.categoria != "" {
+			categoria = traducirReporte(dimension.categoria)
+		}
+
+		fmt.Fprintf(&tabla, "<tr>%s<td>%s</td><td>%s</td><td>%s</td><td><span class=\"barra%s\" style=\"width: %.0fpx\"></span> %.1f%%</td></tr>\n",
+			encabezarFilaReporte(accesible, "<code>"+html.EscapeString(dimension.etiqueta)+"</code>"), categoria,
+			strconv.FormatFloat(dimension.valor1, 'f', -1, 64), strconv.FormatFloat(dimension.valor2, 'f', -1, 64),
+			clase, aporte(dimension)*ANCHO_BARRA_CONTRIBUCION, aporte(dimension)*100)
+	}
+	tabla.WriteString(terminarTablaReporte(accesible))
+
+	return tabla.String()
+}
+
+/*
+ * Función para generar el gráfico de la contribución de las dimensiones a la distancia de un par
+ * param: resultado del análisis, índices de los dos archivos y si el reporte es accesible
+ * return: la sección en HTML (vacía si el motor no tiene vectores)
+ */
+func generarGraficoContribucion(resultado ResultadoAnalisis, i int, j int, accesible bool) string {
+	contribuciones, frecuencias := calcularContribuciones(resultado, i, j)
+	if len(contribuciones) == 0 {
+		return ""
+	}
+
+	diferencia := func(contribucion ContribucionDimension) float64 { return contribucion.diferencia }
+	comun := func(contribucion ContribucionDimension) float64 { return contribucion.comun }
+
+	var seccion strings.Builder
+
+	fmt.Fprintf(&seccion, "<section class=\"contribucion\"><h2>%s</h2><p>%s</p>\n", traducirReporte("contribucion"), traducirReporte("descripcion_contribucion"))
+
+	// Resumen por categoría, en el orden en que aparecen las categorías
+	var categorias []string
+	porCategoria := make(map[string][2]float64)
+	for _, contribucion := range contribuciones {
+		if contribucion.categoria == "" {
+			continue
+		}
+		if _, existe := porCategoria[contribucion.categoria]; !existe {
+			categorias = append(categorias, contribucion.categoria)
+		}
+		suma := porCategoria[contribucion.categoria]
+		porCategoria[contribucion.categoria] = [2]float64{suma[0] + contribucion.diferencia, suma[1] + contribucion.comun}
+	}
+	sort.SliceStable(categorias, func(a, b int) bool {
+		return porCategoria[categorias[a]][1] > porCategoria[categorias[b]][1]
+	})
+
+	if len(categorias) > 0 {
+		fmt.Fprintf(&seccion, "<h3>%s</h3>\n", traducirReporte("por_categoria"))
+		seccion.WriteString(iniciarTablaReporte(accesible, traducirReporte("por_categoria"), traducirReporte("categoria"), traducirReporte("aporte_comun"), traducirReporte("aporte_diferencia")))
+		for _, categoria := range categorias {
+			fmt.Fprintf(&seccion, "<tr>%s<td><span class=\"barra comun\" style=\"width: %.0fpx\"></span> %.1f%%</td><td><span class=\"barra\" style=\"width: %.0fpx\"></span> %.1f%%</td></tr>\n",
+				encabezarFilaReporte(accesible, traducirReporte(categoria)),
+				porCategoria[categoria][1]*ANCHO_BARRA_CONTRIBUCION, porCategoria[categoria][1]*100,
+				porCategoria[categoria][0]*ANCHO_BARRA_CONTRIBUCION, porCategoria[categoria][0]*100)
+		}
+		seccion.WriteString(terminarTablaReporte(accesible))
+	}
+
+	if frecuencias {
+		seccion.WriteString(generarBarrasContribucion(seleccionarContribuciones(contribuciones, comun, DIMENSIONES_CONTRIBUCION), comun, "mas_comparten", " comun", accesible))
+	}
+	seccion.WriteString(generarBarrasContribucion(seleccionarContribuciones(contribuciones, diferencia, DIMENSIONES_CONTRIBUCION), diferencia, "mas_diferencian", "", accesible))
+	seccion.WriteString("</section>\n")
+
+	return seccion.String()
+}
@@ -0,0 +1,211 @@
+/*
+ * Detección de copias tardías en entregas de git (--late-copy).
+ *
+ * Para cada par de archivos de distinto estudiante a una distancia máxima se obtienen los bloques de líneas
+ * coincidentes (como en la evidencia por líneas) y, con git blame, el commit que introdujo cada línea.
+ * Un bloque (o un tramo del bloque con la cantidad mínima de líneas) es una copia tardía si en uno de los archivos
+ * se introdujo en un solo commit y en el otro archivo ya estaba completo antes de ese commit. De esta forma la evidencia
+ * incluye quién tuvo primero el código y en qué momento apareció en la otra entrega.
+ * Los archivos que no pertenecen a un repositorio de git (o con líneas sin commit) se ignoran.
+ */
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Estructura para almacenar el commit que introdujo una línea de un archivo
+// - identificador del commit (vacío si la línea no tiene commit)
+// - fecha del commit
+type LineaBlame struct {
+	commit string
+	fecha  time.Time
+}
+
+// Estructura para almacenar una copia tardía
+// - índices del archivo con la copia y del archivo con el código original
+// - commit y fecha en que el bloque apareció en la copia
+// - fecha desde la que el bloque estaba completo en el original
+// - primera y última línea del bloque en la copia y en el original
+type CopiaTardia struct {
+	copia        int
+	original     int
+	commit       string
+	fechaCopia   time.Time
+	fechaOrigen  time.Time
+	inicioCopia  int
+	finCopia     int
+	inicioOrigen int
+	finOrigen    int
+}
+
+/*
+ * Función para obtener el commit que introdujo cada línea de un archivo
+ * param: ruta del archivo
+ * return: arreglo con el commit de cada línea (la posición 0 es la línea 1) o un error si no está en un repositorio
+ */
+func obtenerBlame(ruta string) ([]LineaBlame, error) {
+	var lineas []LineaBlame
+
+	salida, err := ejecutarGit("-C", filepath.Dir(ruta), "blame", "--line-porcelain", "--", filepath.Base(ruta))
+	if err != nil {
+		return lineas, err
+	}
+
+	var actual LineaBlame
+	for _, renglon := range strings.Split(salida, "\n") {
+		switch {
+		case strings.HasPrefix(renglon, "\t"):
+			lineas = append(lineas, actual)
+			actual = LineaBlame{}
+		case strings.HasPrefix(renglon, "committer-time "):
+			segundos, _ := strconv.ParseInt(strings.TrimPrefix(renglon, "committer-time "), 10, 64)
+			actual.fecha = time.Unix(segundos, 0)
+		case actual.commit == "" && len(renglon) >= 40 && strings.Trim(renglon[:40], "0123456789abcdef") == "":
+			// Las líneas sin commit (cambios locales) se identifican con ceros
+			if strings.Trim(renglon[:40], "0") != "" {
+				actual.commit = renglon[:40]
+			} else {
+				actual.commit = "-"
+			}
+		}
+	}
+
+	return lineas, nil
+}
+
+/*
+ * Función para obtener las copias tardías de un bloque coincidente: los tramos del bloque de al menos la cantidad
+ * mínima de líneas que se introdujeron en un solo commit de la copia y que ya estaban completos en el original
+ * param: commits de las líneas de ambos archivos, números de línea del bloque en cada archivo
+ *        y la cantidad mínima de líneas de un tramo
+ * return: las copias tardías del bloque (con los índices de los archivos sin definir)
+ */
+func obtenerCopiasBloque(blameCopia []LineaBlame, blameOrigen []LineaBlame, numerosCopia []int, numerosOrigen []int, minimoLineas int) []CopiaTardia {
+	var copias []CopiaTardia
+
+	// Commit de una línea del bloque (vacío si no tiene commit en alguno de los archivos)
+	commit := func(i int) string {
+		if numerosCopia[i] > len(blameCopia) || numerosOrigen[i] > len(blameOrigen) ||
+			blameCopia[numerosCopia[i]-1].commit == "-" || blameOrigen[numerosOrigen[i]-1].commit == "-" {
+			return ""
+		}
+		return blameCopia[numerosCopia[i]-1].commit
+	}
+
+	for inicio := 0; inicio < len(numerosCopia); {
+		fin := inicio + 1
+		for fin < len(numerosCopia) && commit(fin) == commit(inicio) {
+			fin++
+		}
+
+		if commit(inicio) != "" && fin-inicio >= minimoLineas {
+			copia := CopiaTardia{
+				commit:       commit(inicio),
+				fechaCopia:   blameCopia[numerosCopia[inicio]-1].fecha,
+				inicioCopia:  numerosCopia[inicio],
+				finCopia:     numerosCopia[fin-1],
+				inicioOrigen: numerosOrigen[inicio],
+				finOrigen:    numerosOrigen[fin-1],
+			}
+			for i := inicio; i < fin; i++ {
+				if fecha := blameOrigen[numerosOrigen[i]-1].fecha; fecha.After(copia.fechaOrigen) {
+					copia.fechaOrigen = fecha
+				}
+			}
+
+			if copia.fechaOrigen.Before(copia.fechaCopia) {
+				copias = append(copias, copia)
+			}
+		}
+		inicio = fin
+	}
+
+	return copias
+}
+
+/*
+ * Función para detectar las copias tardías entre los pares de archivos de distinto estudiante a una distancia máxima.
+ * Si el motor empleado no es el de líneas, las líneas normalizadas se calculan en este momento.
+ * param: arreglo con la información del código fuente de los archivos, la distancia mínima
+ *        y la cantidad mínima de líneas consecutivas de un bloque
+ * return: las copias tardías y la cantidad de archivos que pertenecen a un repositorio de git
+ */
+func detectarCopiasTardias(tablaCodigoFuente []CodigoFuente, distanciaMinima float64, minimoLineas int) ([]CopiaTardia, int) {
+	var copias []CopiaTardia
+
+	blames := make([][]LineaBlame, len(tablaCodigoFuente))
+	versionados := 0
+	for i := range tablaCodigoFuente {
+		if tablaCodigoFuente[i].lineas == nil {
+			tablaCodigoFuente[i].lineas = obtenerLineasNormalizadas(tablaCodigoFuente[i].contenido)
+		}
+
+		if blame, err := obtenerBlame(tablaCodigoFuente[i].ruta); err == nil {
+			blames[i] = blame
+			versionados++
+		}
+	}
+
+	for i := 0; i < len(tablaCodigoFuente); i++ {
+		for j := i + 1; j < len(tablaCodigoFuente); j++ {
+			if blames[i] == nil || blames[j] == nil || obtenerDistancia(tablaCodigoFuente[i], j) > distanciaMinima ||
+				obtenerEstudiante(".", tablaCodigoFuente[i].ruta) == obtenerEstudiante(".", tablaCodigoFuente[j].ruta) {
+				continue
+			}
+
+			for _, bloque := range agruparBloquesLineas(obtenerLineasCoincidentes(tablaCodigoFuente[i].lineas, tablaCodigoFuente[j].lineas), minimoLineas) {
+				numeros1 := make([]int, len(bloque))
+				numeros2 := make([]int, len(bloque))
+				for k, coincidente := range bloque {
+					numeros1[k], numeros2[k] = coincidente.numero1, coincidente.numero2
+				}
+
+				for _, copia := range obtenerCopiasBloque(blames[i], blames[j], numeros1, numeros2, minimoLineas) {
+					copia.copia, copia.original = i, j
+					copias = append(copias, copia)
+				}
+				for _, copia := range obtenerCopiasBloque(blames[j], blames[i], numeros2, numeros1, minimoLineas) {
+					copia.copia, copia.original = j, i
+					copias = append(copias, copia)
+				}
+			}
+		}
+	}
+
+	return copias, versionados
+}
+
+/*
+ * Función para imprimir las copias tardías entre los pares de archivos a una distancia máxima
+ * param: arreglo con la información del código fuente de los archivos, la distancia mínima
+ *        y la cantidad mínima de líneas consecutivas de un bloque
+ */
+func imprimirCopiasTardias(tablaCodigoFuente []CodigoFuente, distanciaMinima float64, minimoLineas int) {
+	fmt.Printf("\nCOPIAS TARDÍAS (BLOQUES DE AL MENOS %d LÍNEAS INTRODUCIDOS EN UN SOLO COMMIT)\n\n", minimoLineas)
+
+	copias, versionados := detectarCopiasTardias(tablaCodigoFuente, distanciaMinima, minimoLineas)
+
+	if versionados == 0 {
+		fmt.Print("Ningún archivo pertenece a un repositorio de git\n\n")
+		return
+	}
+
+	if len(copias) == 0 {
+		fmt.Print("No se encontraron copias tardías\n\n")
+		return
+	}
+
+	for _, copia := range copias {
+		fmt.Printf("%s (líneas %d-%d) <- %s (líneas %d-%d)\n",
+			tablaCodigoFuente[copia.copia].nombre, copia.inicioCopia, copia.finCopia,
+			tablaCodigoFuente[copia.original].nombre, copia.inicioOrigen, copia.finOrigen)
+		fmt.Printf("\tintroducido en el commit %.8s del %s, ya existía en el original desde el %s\n\n",
+			copia.commit, copia.fechaCopia.Format("2006-01-02 15:04"), copia.fechaOrigen.Format("2006-01-02 15:04"))
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Criterio de la distancia máxima (--threshold).
+ *
+ * La distancia máxima que filtra las distancias y forma los grupos se define con uno de tres criterios:
+ * - "none": sin distancia máxima, se imprimen todas las distancias y no se forman grupos.
+ * - "auto": la distancia máxima se calcula con la distribución de las distancias de todos los pares del corpus,
+ *   como la cerca inferior robusta mediana - 3 * MAD, es decir, solamente los pares atípicamente cercanos. La MAD
+ *   (mediana de las desviaciones absolutas respecto a la mediana) se multiplica por 1.4826 para que equivalga a
+ *   la desviación estándar de una distribución normal, por lo que la cerca queda a unas 3 desviaciones de la
+ *   mediana. A diferencia de la cerca de Tukey (Q1 - 1.5 * IQR), que en un curso normal queda por debajo de 0,
+ *   esta cerca sí alcanza la cola inferior. Si la MAD es 0 (más de la mitad de los pares a la misma distancia),
+ *   la distancia máxima es la mayor distancia por debajo de la mediana.
+ * - un número: la distancia máxima definida por el usuario (equivale a --max-distance).
+ *
+ * Los reportes indican el criterio empleado (y el valor calculado con "auto") en lugar de imprimir un valor
+ * centinela cuando no hay distancia máxima.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Criterios de la distancia máxima
+const (
+	CRITERIO_NINGUNO    = "none"
+	CRITERIO_AUTOMATICO = "auto"
+	CRITERIO_VALOR      = "value"
+)
+
+// Cerca inferior del criterio automático: cantidad de MAD por debajo de la mediana y factor de la MAD para
+// que equivalga a la desviación estándar de una distribución normal
+const (
+	FACTOR_CERCA_AUTOMATICA = 3.0
+	FACTOR_MAD_NORMAL       = 1.4826
+)
+
+// Estructura del criterio de la distancia máxima
+// - criterio ("none", "auto" o "value")
+// - distancia máxima (la definida por el usuario o la calculada con "auto", sin uso con "none")
+// - si la distancia máxima ya se calculó (con "auto" se calcula después de la fase 2)
+// - mediana y MAD escalada de las distancias con las que se calculó la distancia máxima (solo con "auto")
+type CriterioDistancia struct {
+	criterio string
+	valor    float64
+	resuelto bool
+	mediana  float64
+	mad      float64
+}
+
+/*
+ * Función para obtener el criterio de la distancia máxima
+ * param: criterio indicado por el usuario ("none", "auto" o un número)
+ * return: el criterio o un error si no es válido
+ */
+func obtenerCriterioDistancia(texto string) (CriterioDistancia, error) {
+	switch texto {
+	case CRITERIO_NINGUNO:
+		return CriterioDistancia{criterio: CRITERIO_NINGUNO}, nil
+	case CRITERIO_AUTOMATICO:
+		return CriterioDistancia{criterio: CRITERIO_AUTOMATICO}, nil
+	}
+
+	criterio, err := obtenerCriterioValor(texto)
+	if err != nil {
+		return CriterioDistancia{}, fmt.Errorf("La distancia máxima (--threshold) \"%s\" debe ser \"none\", \"auto\" o un número mayor o igual a 0", texto)
+	}
+
+	return criterio, nil
+}
+
+/*
+ * Función para obtener el criterio de una distancia máxima numérica (--max-distance o --threshold=N)
+ * param: distancia máxima indicada por el usuario
+ * return: el criterio o un error si no es un número finito mayor o igual a 0
+ */
+func obtenerCriterioValor(texto string) (CriterioDistancia, error) {
+	distancia, err := strconv.ParseFloat(texto, 64)
+	if err != nil || distancia < 0 || math.IsInf(distancia, 0) || math.IsNaN(distancia) {
+		return CriterioDistancia{}, fmt.Errorf("La distancia máxima (--max-distance) \"%s\" debe ser un número mayor o igual a 0", texto)
+	}
+
+	return crearCriterioValor(distancia), nil
+}
+
+/*
+ * Función para crear el criterio de una distancia máxima definida por el usuario
+ * param: distancia máxima
+ * return: el criterio
+ */
+func crearCriterioValor(distancia float64) CriterioDistancia {
+	return CriterioDistancia{criterio: CRITERIO_VALOR, valor: distancia, resuelto: true}
+}
+
+/*
+ * Función para determinar si el criterio define una distancia máxima
+ * return: si hay distancia máxima ("auto" o un número)
+ */
+func (criterio CriterioDistancia) definido() bool {
+	return criterio.criterio == CRITERIO_AUTOMATICO || criterio.criterio == CRITERIO_VALOR
+}
+
+/*
+ * Función para obtener el límite con el que se comparan las distancias
+ * return: la distancia máxima, o infinito si no hay distancia máxima (ninguna distancia queda por fuera)
+ */
+func (criterio CriterioDistancia) limite() float64 {
+	if !criterio.definido() || !criterio.resuelto {
+		return math.Inf(1)
+	}
+
+	return criterio.valor
+}
+
+/*
+ * Función para calcular la distancia máxima del criterio automático (no cambia los demás criterios)
+ * param: distribución ordenada de las distancias de todos los pares
+ * return: el criterio con la distancia máxima calculada
+ */
+func (criterio CriterioDistancia) resolver(distribucion []float64) CriterioDistancia {
+	if criterio.criterio != CRITERIO_AUTOMATICO {
+		return criterio
+	}
+
+	criterio.valor, criterio.resuelto = 0, true
+	if len(distribucion) == 0 {
+		return criterio
+	}
+
+	criterio.mediana = calcularCuantil(distribucion, 0.5)
+	desviaciones := make([]float64, len(distribucion))
+	for i, distancia := range distribucion {
+		desviaciones[i] = math.Abs(distancia - criterio.mediana)
+	}
+	sort.Float64s(desviaciones)
+	criterio.mad = FACTOR_MAD_NORMAL * calcularCuantil(desviaciones, 0.5)
+
+	if criterio.mad > 0 {
+		criterio.valor = math.Max(0, criterio.mediana-FACTOR_CERCA_AUTOMATICA*criterio.mad)
+		return criterio
+	}
+
+	// MAD igual a 0: solamente son atípicos los pares por debajo de la mediana
+	for _, distancia := range distribucion {
+		if distancia >= criterio.mediana {
+			break
+		}
+		criterio.valor = distancia
+	}
+
+	return criterio
+}
+
+/*
+ * Función para describir el criterio en los reportes
+ * return: descripción (por ejemplo "30 (definida por el usuario)" o "sin distancia máxima")
+ */
+func (criterio CriterioDistancia) describir() string {
+	switch {
+	case criterio.criterio == CRITERIO_VALOR:
+		return strconv.FormatFloat(criterio.valor, 'g', -1, 64) + " (definida por el usuario)"
+	case criterio.criterio == CRITERIO_AUTOMATICO && criterio.resuelto:
+		return fmt.Sprintf("%.2f (automática, mediana %.2f - %.0f * MAD %.2f de las distancias)", criterio.valor, criterio.mediana, FACTOR_CERCA_AUTOMATICA, criterio.mad)
+	case criterio.criterio == CRITERIO_AUTOMATICO:
+		return "automática (se calcula después de la fase 2)"
+	}
+
+	return "sin distancia máxima"
+}
+
+/*
+ * Función para calcular un cuantil de una distribución ordenada (con interpolación lineal)
+ * param: distribución ordenada (no vacía) y cuantil entre 0 y 1
+ * return: valor del cuantil
+ */
+func calcularCuantil(distribucion []float64, cuantil float64) float64 {
+	posicion := cuantil * float64(len(distribucion)-1)
+	inferior := int(math.Floor(posicion))
+
+	if inferior+1 >= len(distribucion) {
+		return distribucion[len(distribucion)-1]
+	}
+
+	return distribucion[inferior] + (posicion-float64(inferior))*(distribucion[inferior+1]-distribucion[inferior])
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestObtenerCriterioDistancia(t *testing.T) {
+	casos := []struct {
+		texto, criterio string
+		valido          bool
+	}{
+		{"none", CRITERIO_NINGUNO, true},
+		{"auto", CRITERIO_AUTOMATICO, true},
+		{"12.5", CRITERIO_VALOR, true},
+		{"0", CRITERIO_VALOR, true},
+		{"-1", "", false},
+		{"+Inf", "", false},
+		{"cerca", "", false},
+	}
+
+	for _, caso := range casos {
+		criterio, err := obtenerCriterioDistancia(caso.texto)
+		if (err == nil) != caso.valido || criterio.criterio != caso.criterio {
+			t.Errorf("obtenerCriterioDistancia(%q) = %q, %v", caso.texto, criterio.criterio, err)
+		}
+	}
+}
+
+func TestObtenerCriterioValor(t *testing.T) {
+	casos := []struct {
+		texto  string
+		valido bool
+	}{
+		{"12.5", true},
+		{"0", true},
+		{"-1", false},
+		{"-0.5", false},
+		{"+Inf", false},
+		{"NaN", false},
+		{"cerca", false},
+	}
+
+	for _, caso := range casos {
+		criterio, err := obtenerCriterioValor(caso.texto)
+		if (err == nil) != caso.valido || (caso.valido && criterio.criterio != CRITERIO_VALOR) {
+			t.Errorf("obtenerCriterioValor(%q) = %q, %v", caso.texto, criterio.criterio, err)
+		}
+	}
+}
+
+func TestResolverCriterioAutomatico(t *testing.T) {
+	// Distancias de un curso normal: entre 40 y 60, sin pares atípicamente cercanos
+	var normales []float64
+	for i := 0; i < 100; i++ {
+		normales = append(normales, 40+float64(i%21))
+	}
+
+	casos := []struct {
+		nombre   string
+		atipicas []float64
+		limite   float64
+	}{
+		{"sin atípicas", nil, 0},
+		{"dos pares copiados", []float64{3, 8}, 8},
+		{"un par cercano", []float64{20}, 20},
+	}
+
+	for _, caso := range casos {
+		distribucion := append(append([]float64{}, normales...), caso.atipicas...)
+		sort.Float64s(distribucion)
+
+		criterio := CriterioDistancia{criterio: CRITERIO_AUTOMATICO}.resolver(distribucion)
+		limite := criterio.limite()
+
+		if limite < caso.limite {
+			t.Errorf("%s: distancia máxima %.2f, deja por fuera las distancias atípicas %v", caso.nombre, limite, caso.atipicas)
+		}
+		if limite >= distribucion[len(caso.atipicas)] {
+			t.Errorf("%s: distancia máxima %.2f, incluye distancias normales desde %.2f", caso.nombre, limite, distribucion[len(caso.atipicas)])
+		}
+		if criterio.mediana < 40 || criterio.mediana > 60 || criterio.mad <= 0 {
+			t.Errorf("%s: mediana %.2f y MAD %.2f", caso.nombre, criterio.mediana, criterio.mad)
+		}
+	}
+}
+
+func TestResolverCriterioAutomaticoMADCero(t *testing.T) {
+	casos := []struct {
+		distribucion []float64
+		limite       float64
+	}{
+		{[]float64{2, 5, 30, 30, 30, 30, 30, 41}, 5},
+		{[]float64{30, 30, 30, 41}, 0},
+		{nil, 0},
+	}
+
+	for _, caso := range casos {
+		if limite := (CriterioDistancia{criterio: CRITERIO_AUTOMATICO}).resolver(caso.distribucion).limite(); limite != caso.limite {
+			t.Errorf("resolver(%v) = %v, se esperaba %v", caso.distribucion, limite, caso.limite)
+		}
+	}
+}
+
+func TestResolverOtrosCriterios(t *testing.T) {
+	distribucion := []float64{1, 2, 3}
+
+	if limite := crearCriterioValor(7).resolver(distribucion).limite(); limite != 7 {
+		t.Errorf("criterio definido por el usuario resuelto = %v, se esperaba 7", limite)
+	}
+	if criterio := (CriterioDistancia{criterio: CRITERIO_NINGUNO}).resolver(distribucion); criterio.definido() {
+		t.Errorf("criterio sin distancia máxima resuelto con distancia máxima %v", criterio.limite())
+	}
+}
+
+func TestCriterioAutomaticoAgrupaCopias(t *testing.T) {
+	// 12 entregas distintas y una entrega copiada con un cambio en una línea
+	archivos := crearCorpusFamiliasPrueba(13, 2)
+	for f := 0; f < 12; f++ {
+		delete(archivos, fmt.Sprintf("e%d_1/main.go", f))
+	}
+
+	parametros := crearParametrosPrueba()
+	parametros.criterioDistancia = CriterioDistancia{criterio: CRITERIO_AUTOMATICO}
+
+	resultado, salida := analizarCorpusSalidaPrueba(t, parametros, archivos)
+
+	if len(resultado.grupos) != 1 || len(resultado.grupos[0].integrantes) != 2 {
+		t.Fatalf("grupos con la distancia máxima %s = %v, se esperaba un grupo con la copia", resultado.criterio.describir(), resultado.grupos)
+	}
+	for _, integrante := range resultado.grupos[0].integrantes {
+		if nombre := resultado.archivos[integrante.indice].nombre; nombre != "./e12_0/main.go" && nombre != "./e12_1/main.go" {
+			t.Errorf("%s está en el grupo de la copia", nombre)
+		}
+	}
+	if describir := resultado.criterio.describir(); !strings.Contains(salida, describir) {
+		t.Errorf("la consola no indica la distancia máxima calculada %q", describir)
+	}
+}
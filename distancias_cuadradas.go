@@ -0,0 +1,44 @@
+/*
+ * Modo de distancias cuadradas (--squared).
+ *
+ * Con los motores de distancia euclidiana ("ascii" y "tokens") la matriz puede almacenar la distancia al cuadrado,
+ * lo que evita la raíz cuadrada de cada uno de los n²/2 pares en la fase 2. El modo es transparente: los valores
+ * se leen en la escala original (con la raíz solo al leerlos, ver obtenerDistancia) y las comparaciones con las
+ * distancias máximas se hacen elevando al cuadrado la distancia máxima, sin calcular ninguna raíz.
+ */
+
+package main
+
+import "math"
+
+// Interfaz opcional de los motores de distancia euclidiana que pueden calcular la distancia al cuadrado
+type MotorCuadratico interface {
+	distanciaCuadrada(c1 CodigoFuente, c2 CodigoFuente) float64
+}
+
+/*
+ * Función para convertir un valor almacenado en la tabla de distancias de un archivo a la escala original
+ * param: información del archivo y valor almacenado
+ * return: distancia en la escala original
+ */
+func escalarDistancia(codigoFuente CodigoFuente, valor float64) float64 {
+	if codigoFuente.distanciasCuadradas {
+		return math.Sqrt(valor)
+	}
+
+	return valor
+}
+
+/*
+ * Función para determinar si un valor almacenado en la tabla de distancias de un archivo está a una distancia máxima,
+ * sin convertirlo a la escala original (la distancia máxima se eleva al cuadrado si la tabla es de distancias cuadradas)
+ * param: información del archivo, valor almacenado y distancia máxima
+ * return: si la distancia es menor o igual a la distancia máxima
+ */
+func estaADistanciaMaxima(codigoFuente CodigoFuente, valor float64, distanciaMaxima float64) bool {
+	if codigoFuente.distanciasCuadradas && distanciaMaxima < math.MaxFloat64 {
+		return distanciaMaxima >= 0 && valor <= distanciaMaxima*distanciaMaxima
+	}
+
+	return valor <= distanciaMaxima
+}
@@ -0,0 +1,163 @@
+/*
+ * Distancias entre grupos (--group-distances) y fusión de grupos cercanos (--merge-groups).
+ *
+ * La distancia entre dos grupos es la distancia entre sus medoides; también se reporta la menor distancia entre
+ * sus integrantes y cuántos integrantes comparten. Dos grupos cercanos suelen ser el mismo código original
+ * que circuló entre varios estudiantes, por lo que con --merge-groups=D se fusionan (de forma transitiva) los
+ * grupos cuyos medoides están a una distancia máxima D, antes de generar los reportes de grupos.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jugutier73/SASC/pkg/sasc"
+)
+
+// Estructura de la distancia entre dos grupos
+// - posiciones de los dos grupos en el arreglo de grupos
+// - distancia entre sus medoides (DISTANCIA_NO_CALCULADA si no se calculó con --pairs=lsh)
+// - menor distancia calculada entre un integrante de cada grupo (de grupos distintos)
+// - cantidad de integrantes comunes
+type DistanciaGrupos struct {
+	grupo1            int
+	grupo2            int
+	distanciaMedoides float64
+	distanciaMinima   float64
+	comunes           int
+}
+
+/*
+ * Función para calcular las distancias entre todos los pares de grupos
+ * param: arreglo con la información del código fuente de los archivos y los grupos
+ * return: distancias entre los pares de grupos, de menor a mayor distancia entre medoides
+ */
+func calcularDistanciasGrupos(tablaCodigoFuente []CodigoFuente, grupos []Grupo) []DistanciaGrupos {
+	var distancias []DistanciaGrupos
+
+	for i := range grupos {
+		for j := i + 1; j < len(grupos); j++ {
+			distancia := DistanciaGrupos{grupo1: i, grupo2: j, distanciaMedoides: obtenerDistancia(tablaCodigoFuente[grupos[i].centro], grupos[j].centro), distanciaMinima: math.MaxFloat64}
+
+			for _, integrante1 := range grupos[i].integrantes {
+				for _, integrante2 := range grupos[j].integrantes {
+					if integrante1.indice == integrante2.indice {
+						distancia.comunes++
+					} else if valor := obtenerDistancia(tablaCodigoFuente[integrante1.indice], integrante2.indice); esDistanciaCalculada(valor) {
+						distancia.distanciaMinima = math.Min(distancia.distanciaMinima, valor)
+					}
+				}
+			}
+
+			distancias = append(distancias, distancia)
+		}
+	}
+
+	sort.SliceStable(distancias, func(i, j int) bool {
+		return distancias[i].distanciaMedoides < distancias[j].distanciaMedoides
+	})
+
+	return distancias
+}
+
+/*
+ * Función para imprimir las distancias entre los grupos
+ * param: resultado del análisis
+ */
+func imprimirDistanciasGrupos(resultado ResultadoAnalisis) {
+	fmt.Print("\nDISTANCIAS ENTRE GRUPOS (DE MENOR A MAYOR DISTANCIA ENTRE MEDOIDES)\n\n")
+
+	grupos := resultado.grupos
+	distancias := calcularDistanciasGrupos(resultado.archivos, grupos)
+	if len(distancias) == 0 {
+		fmt.Print("\tse requieren al menos dos grupos\n\n")
+		return
+	}
+
+	fmt.Printf("%-14s %-14s %24s %18s %22s\n", "GRUPO", "GRUPO", "DISTANCIA ENTRE MEDOIDES", "DISTANCIA MÍNIMA", "INTEGRANTES COMUNES")
+	for _, distancia := range distancias {
+		minima := "-"
+		if distancia.distanciaMinima < math.MaxFloat64 {
+			minima = fmt.Sprintf("%.2f", distancia.distanciaMinima)
+		}
+
+		fmt.Printf("%-14s %-14s %24s %18s %22d\n", grupos[distancia.grupo1].identificador, grupos[distancia.grupo2].identificador,
+			formatearDistancia(distancia.distanciaMedoides), minima, distancia.comunes)
+	}
+	fmt.Println()
+}
+
+/*
+ * Función para obtener la raíz de un grupo en la fusión (conjuntos disjuntos)
+ * param: raíz de cada grupo y posición del grupo
+ * return: posición de la raíz del grupo
+ */
+func obtenerRaizGrupo(raices []int, grupo int) int {
+	for raices[grupo] != grupo {
+		raices[grupo] = raices[raices[grupo]]
+		grupo = raices[grupo]
+	}
+
+	return grupo
+}
+
+/*
+ * Función para fusionar los grupos cuyos medoides están a una distancia máxima (de forma transitiva).
+ * Cada grupo fusionado tiene la unión de los integrantes, su propio medoide, diámetro y par ejemplar,
+ * y se identifica con el hash de su nuevo medoide.
+ * param: arreglo con la información del código fuente de los archivos, los grupos y la distancia máxima entre medoides
+ * return: los grupos después de la fusión, en el orden del primer grupo de cada fusión
+ */
+func fusionarGrupos(tablaCodigoFuente []CodigoFuente, grupos []Grupo, distanciaFusion float64) []Grupo {
+	var fusionados []Grupo
+
+	raices := make([]int, len(grupos))
+	for i := range raices {
+		raices[i] = i
+	}
+
+	for _, distancia := range calcularDistanciasGrupos(tablaCodigoFuente, grupos) {
+		if distancia.distanciaMedoides <= distanciaFusion {
+			raices[obtenerRaizGrupo(raices, distancia.grupo2)] = obtenerRaizGrupo(raices, distancia.grupo1)
+		}
+	}
+
+	distancias := DistanciasTabla(tablaCodigoFuente)
+	agrupados := make(map[int]bool)
+	identificadores := make(map[string]int)
+
+	for i := range grupos {
+		if obtenerRaizGrupo(raices, i) != i {
+			continue
+		}
+
+		incluidos := make(map[int]bool)
+		var miembros []int
+		for j := range grupos {
+			if obtenerRaizGrupo(raices, j) != i {
+				continue
+			}
+			for _, integrante := range grupos[j].integrantes {
+				if !incluidos[integrante.indice] {
+					incluidos[integrante.indice] = true
+					miembros = append(miembros, integrante.indice)
+				}
+			}
+		}
+		sort.Ints(miembros)
+
+		centro := sasc.Medoid(distancias, miembros, grupos[i].centro)
+		identificador := sasc.UniqueGroupID(identificadores, sasc.GroupID(tablaCodigoFuente[centro].contenido))
+
+		fusionados = append(fusionados, convertirGrupo(sasc.BuildGroup(distancias, identificador, centro, miembros, agrupados)))
+
+		for _, miembro := range miembros {
+			agrupados[miembro] = true
+		}
+	}
+
+	return fusionados
+}
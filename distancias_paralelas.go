@@ -0,0 +1,116 @@
+/*
+ * Cálculo concurrente de la matriz de distancias (--workers).
+ *
+ * El cálculo de las distancias es O(n²): con 800 entregas y los motores "tokens" o "lines" tarda minutos en un
+ * solo núcleo. Las filas de la matriz se reparten en bloques de FILAS_BLOQUE_DISTANCIAS filas por una cola de
+ * trabajo entre varios trabajadores (por defecto GOMAXPROCS, --workers=1 calcula en un solo hilo como antes). Cada
+ * trabajador calcula la distancia de cada fila de su bloque a las filas anteriores (el triángulo de la matriz) y la
+ * asigna en ambas tablas; como cada par se calcula una sola vez y cada celda la escribe un solo trabajador, no se
+ * necesitan bloqueos y las distancias son exactamente las mismas que en un solo hilo.
+ *
+ * Las filas tienen distinto costo (la fila i tiene i distancias), por lo que los bloques se toman de la cola a medida
+ * que los trabajadores se desocupan en lugar de repartirlos de antemano. Con --stream las filas se envían en orden al
+ * terminar su bloque y todos los bloques anteriores.
+ *
+ * Para medir la aceleración en un equipo basta comparar el tiempo de la misma ejecución con --workers=1 y sin la
+ * opción (por ejemplo con time), o ejecutar "go test -run - -bench Distancias", que mide los motores "ascii", "tokens"
+ * y "lines" sobre un corpus generado con 1 y con GOMAXPROCS trabajadores; la aceleración es mayor en los motores costosos ("tokens" sin --hash-dims y
+ * "lines") que en "ascii", en el que la escritura de la matriz pesa más que el cálculo de cada distancia. La matriz de
+ * Gram (--gram) se sigue calculando en un solo hilo.
+ */
+
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Cantidad de filas de la matriz de distancias de cada bloque de la cola de trabajo
+const FILAS_BLOQUE_DISTANCIAS = 16
+
+/*
+ * Función para obtener la cantidad de trabajadores del cálculo de las distancias
+ * param: cantidad indicada por el usuario (0 para GOMAXPROCS) y cantidad de archivos
+ * return: cantidad de trabajadores (al menos 1 y no más que los bloques de filas)
+ */
+func obtenerCantidadTrabajadores(indicada int, cantidadArchivos int) int {
+	trabajadores := indicada
+	if trabajadores <= 0 {
+		trabajadores = runtime.GOMAXPROCS(0)
+	}
+
+	bloques := (cantidadArchivos + FILAS_BLOQUE_DISTANCIAS - 1) / FILAS_BLOQUE_DISTANCIAS
+	if trabajadores > bloques {
+		trabajadores = bloques
+	}
+	if trabajadores < 1 {
+		trabajadores = 1
+	}
+
+	return trabajadores
+}
+
+/*
+ * Función que determina las distancias entre todos los archivos con varios trabajadores
+ * param: arreglo de la información de todos los archivos (con las tablas de distancias creadas), función de distancia,
+ *        cantidad de trabajadores y flujo de resultados (nil si no se usa)
+ * return: completa la información en el arreglo de código fuente con la distancia a todos los demás (matriz de similaridad)
+ */
+func determinarDistanciasParalelas(tablaCodigoFuente []CodigoFuente, distancia func(CodigoFuente, CodigoFuente) float64, trabajadores int, flujo *FlujoResultados) {
+	cantidadArchivos := len(tablaCodigoFuente)
+	cantidadBloques := (cantidadArchivos + FILAS_BLOQUE_DISTANCIAS - 1) / FILAS_BLOQUE_DISTANCIAS
+
+	bloques := make(chan int, cantidadBloques)
+	terminados := make(chan int, cantidadBloques)
+
+	for bloque := 0; bloque < cantidadBloques; bloque++ {
+		bloques <- bloque
+	}
+	close(bloques)
+
+	var espera sync.WaitGroup
+	for trabajador := 0; trabajador < trabajadores; trabajador++ {
+		espera.Add(1)
+		go func() {
+			defer espera.Done()
+
+			for bloque := range bloques {
+				fin := (bloque + 1) * FILAS_BLOQUE_DISTANCIAS
+				if fin > cantidadArchivos {
+					fin = cantidadArchivos
+				}
+
+				for i := bloque * FILAS_BLOQUE_DISTANCIAS; i < fin; i++ {
+					for j := 0; j <= i; j++ {
+						distanciaTemp := distancia(tablaCodigoFuente[i], tablaCodigoFuente[j])
+						asignarValorDistancia(&tablaCodigoFuente[i], j, distanciaTemp)
+						asignarValorDistancia(&tablaCodigoFuente[j], i, distanciaTemp)
+					}
+				}
+
+				terminados <- bloque
+			}
+		}()
+	}
+
+	// Las filas de un bloque solo dependen de las distancias a las filas anteriores, que calcula el mismo bloque,
+	// pero se envían en orden al flujo de resultados
+	listos := make([]bool, cantidadBloques)
+	siguiente := 0
+	for recibidos := 0; recibidos < cantidadBloques; recibidos++ {
+		listos[<-terminados] = true
+
+		for ; siguiente < cantidadBloques && listos[siguiente]; siguiente++ {
+			fin := (siguiente + 1) * FILAS_BLOQUE_DISTANCIAS
+			if fin > cantidadArchivos {
+				fin = cantidadArchivos
+			}
+			for i := siguiente * FILAS_BLOQUE_DISTANCIAS; i < fin && flujo != nil; i++ {
+				flujo.enviarFila(tablaCodigoFuente, i)
+			}
+		}
+	}
+
+	espera.Wait()
+}
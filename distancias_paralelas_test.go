@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+/*
+ * Función para crear un corpus generado y determinar las características de sus archivos
+ * param: prueba o medición, parámetros de ejecución, cantidad de familias y de variantes por familia
+ * return: el arreglo con las características de los archivos y el motor
+ */
+func prepararCorpusDistanciasPrueba(t testing.TB, parametros Parametros, familias int, variantes int) ([]CodigoFuente, Motor) {
+	t.Helper()
+
+	directorio := crearCorpusPrueba(t, crearCorpusFamiliasPrueba(familias, variantes))
+
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listado, _, _, err := obtenerListadoEntregas(directorio, parametros.extension, limites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	motor, err := crearMotor(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	preprocesamiento, err := crearPreprocesamiento(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatoRutas, err := crearFormatoRutas(parametros, directorio)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return determinarCaracteristicas(listado, motor, preprocesamiento, formatoRutas, 1), motor
+}
+
+/*
+ * Función para calcular la matriz de distancias con la cantidad de trabajadores indicada
+ * param: arreglo con las características de los archivos (no se modifica), motor, parámetros de ejecución y
+ *        cantidad de trabajadores
+ * return: la matriz de distancias
+ */
+func calcularMatrizTrabajadoresPrueba(tablaCodigoFuente []CodigoFuente, motor Motor, parametros Parametros, trabajadores int) [][]float64 {
+	parametros.trabajadores = trabajadores
+	tabla := determinarDistanciasEntreArchivos(append([]CodigoFuente{}, tablaCodigoFuente...), motor, parametros, nil)
+
+	matriz := make([][]float64, len(tabla))
+	for i := range tabla {
+		matriz[i] = make([]float64, len(tabla))
+		for j := range tabla {
+			matriz[i][j] = obtenerDistancia(tabla[i], j)
+		}
+	}
+
+	return matriz
+}
+
+func TestObtenerCantidadTrabajadores(t *testing.T) {
+	casos := []struct {
+		indicada, archivos, esperado int
+	}{
+		{1, 1000, 1},
+		{4, 1000, 4},
+		{8, FILAS_BLOQUE_DISTANCIAS * 3, 3},
+		{8, FILAS_BLOQUE_DISTANCIAS*3 + 1, 4},
+		{4, 0, 1},
+		{0, FILAS_BLOQUE_DISTANCIAS, 1},
+	}
+
+	for _, caso := range casos {
+		if obtenido := obtenerCantidadTrabajadores(caso.indicada, caso.archivos); obtenido != caso.esperado {
+			t.Errorf("obtenerCantidadTrabajadores(%d, %d) = %d, se esperaba %d", caso.indicada, caso.archivos, obtenido, caso.esperado)
+		}
+	}
+}
+
+func TestDistanciasParalelasIgualesSecuenciales(t *testing.T) {
+	casos := []struct {
+		motor     string
+		precision string
+		cuadradas bool
+	}{
+		{"ascii", PRECISION_MATRIZ_COMPLETA, false},
+		{"ascii", PRECISION_MATRIZ_REDUCIDA, false},
+		{"ascii", PRECISION_MATRIZ_COMPLETA, true},
+		{"tokens", PRECISION_MATRIZ_COMPLETA, false},
+		{"lines", PRECISION_MATRIZ_COMPLETA, false},
+	}
+
+	for _, caso := range casos {
+		parametros := crearParametrosPrueba()
+		parametros.motor = caso.motor
+		parametros.precisionMatriz = caso.precision
+		parametros.distanciasCuadradas = caso.cuadradas
+
+		// 70 archivos: varios bloques de filas y el último incompleto
+		tablaCodigoFuente, motor := prepararCorpusDistanciasPrueba(t, parametros, 7, 10)
+		secuencial := calcularMatrizTrabajadoresPrueba(tablaCodigoFuente, motor, parametros, 1)
+
+		for _, trabajadores := range []int{2, 3, runtime.GOMAXPROCS(0) + 1} {
+			paralela := calcularMatrizTrabajadoresPrueba(tablaCodigoFuente, motor, parametros, trabajadores)
+
+			for i := range secuencial {
+				for j := range secuencial[i] {
+					if paralela[i][j] != secuencial[i][j] {
+						t.Fatalf("%s %s cuadradas=%v con %d trabajadores: distancia %d-%d = %v, en un solo hilo %v",
+							caso.motor, caso.precision, caso.cuadradas, trabajadores, i, j, paralela[i][j], secuencial[i][j])
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkDistancias(b *testing.B) {
+	for _, motorPrueba := range []string{"ascii", "tokens", "lines"} {
+		parametros := crearParametrosPrueba()
+		parametros.motor = motorPrueba
+
+		// 200 archivos generados (20 familias de 10 variantes)
+		tablaCodigoFuente, motor := prepararCorpusDistanciasPrueba(b, parametros, 20, 10)
+
+		cantidades := []int{1}
+		if runtime.GOMAXPROCS(0) > 1 {
+			cantidades = append(cantidades, runtime.GOMAXPROCS(0))
+		}
+		for _, trabajadores := range cantidades {
+			b.Run(fmt.Sprintf("%s/workers=%d", motorPrueba, trabajadores), func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					parametros.trabajadores = trabajadores
+					determinarDistanciasEntreArchivos(append([]CodigoFuente{}, tablaCodigoFuente...), motor, parametros, nil)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Agrupación de los archivos idénticos (--dedup).
+ *
+ * Cuando muchos estudiantes entregan sin cambios el mismo archivo proporcionado por el profesor (una plantilla, una
+ * biblioteca), cada copia repite el cálculo de sus características y de n distancias. Con --dedup, antes del análisis
+ * se calcula el hash SHA-256 del contenido de cada archivo y los archivos con el mismo hash se agrupan en uno solo:
+ * - se calculan las características y las distancias solamente del primero (en el orden del listado)
+ * - los demás quedan como sus alias y, después del cálculo de las distancias, vuelven a la tabla como archivos
+ *   completos con las distancias de su representante (y distancia 0 entre las copias), antes de formar los grupos,
+ *   por lo que una copia exacta aparece en todos los reportes (grupos, CSV, JSON, cola de revisión, resúmenes y
+ *   puntajes) igual que sin --dedup
+ * - la sección ARCHIVOS IDÉNTICOS de la consola y el campo "alias" del resultado JSON listan las copias agrupadas
+ * La caché (--cache-dir) y las filas del flujo de resultados (--stream) tienen solamente los archivos analizados.
+ *
+ * Se comparan los bytes originales de los archivos (antes de eliminar encabezados), por lo que solo se agrupan las
+ * copias exactas; las copias con cambios mínimos se siguen reportando a distancia 0 como antes.
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+/*
+ * Función para agrupar los archivos con exactamente el mismo contenido
+ * param: listado de archivos a analizar y contenido de los .zip del listado
+ * return: listado sin los archivos repetidos (se conserva el primero de cada contenido) y las rutas de los
+ *         archivos repetidos de cada archivo conservado
+ */
+func agruparArchivosIdenticos(listado []string, archivosZip ArchivosComprimidos) ([]string, map[string][]string) {
+	var conservados []string
+	alias := make(map[string][]string)
+	representantes := make(map[[sha256.Size]byte]string)
+
+	for _, archivo := range listado {
+		filebuffer, err := archivosZip.leer(archivo)
+		if err != nil {
+			panic(err)
+		}
+
+		hash := sha256.Sum256(filebuffer)
+		if representante, ok := representantes[hash]; ok {
+			alias[representante] = append(alias[representante], archivo)
+			continue
+		}
+
+		representantes[hash] = archivo
+		conservados = append(conservados, archivo)
+	}
+
+	return conservados, alias
+}
+
+/*
+ * Función para volver a agregar los archivos idénticos a la tabla como archivos completos, con las distancias de su
+ * representante (distancia 0 entre las copias), en el orden del listado original
+ * param: arreglo con la información de los archivos analizados (con sus distancias), listado original (con los
+ *        archivos idénticos), rutas de los alias de cada archivo analizado y formato de las rutas
+ * return: arreglo con la información de todos los archivos del listado original (los representantes con los nombres
+ *         de sus alias)
+ */
+func expandirArchivosIdenticos(tablaCodigoFuente []CodigoFuente, listado []string, alias map[string][]string, formatoRutas FormatoRutas) []CodigoFuente {
+	if len(alias) == 0 {
+		return tablaCodigoFuente
+	}
+
+	posiciones := make(map[string]int)
+	for i, codigoFuente := range tablaCodigoFuente {
+		posiciones[codigoFuente.ruta] = i
+	}
+	for representante, archivos := range alias {
+		for _, archivo := range archivos {
+			posiciones[archivo] = posiciones[representante]
+		}
+	}
+
+	origenes := make([]int, len(listado))
+	expandida := make([]CodigoFuente, len(listado))
+	for k, archivo := range listado {
+		origenes[k] = posiciones[archivo]
+		expandida[k] = tablaCodigoFuente[origenes[k]]
+		expandida[k].ruta, expandida[k].nombre, expandida[k].alias = archivo, formatoRutas.formatear(archivo), nil
+
+		for _, copia := range alias[archivo] {
+			expandida[k].alias = append(expandida[k].alias, formatoRutas.formatear(copia))
+		}
+	}
+
+	crearTablasDistancias(expandida, len(tablaCodigoFuente) > 0 && tablaCodigoFuente[0].tablaDistancias32 != nil)
+	for k := range expandida {
+		for l := range expandida {
+			valor := 0.0
+			if origenes[k] != origenes[l] {
+				valor = obtenerValorDistancia(tablaCodigoFuente[origenes[k]], origenes[l])
+			}
+			asignarValorDistancia(&expandida[k], l, valor)
+		}
+	}
+
+	return expandida
+}
+
+/*
+ * Función para obtener los alias de los archivos analizados para el resultado JSON
+ * param: resultado del análisis
+ * return: nombres de los alias de cada archivo que los tiene (nil si no hay alias)
+ */
+func obtenerAliasJSON(resultado ResultadoAnalisis) map[string][]string {
+	var alias map[string][]string
+
+	for _, archivo := range resultado.archivos {
+		if len(archivo.alias) > 0 {
+			if alias == nil {
+				alias = make(map[string][]string)
+			}
+			alias[archivo.nombre] = append([]string{}, archivo.alias...)
+		}
+	}
+
+	return alias
+}
+
+/*
+ * Función para imprimir los archivos idénticos agrupados y sus alias
+ * param: arreglo con la información de todos los archivos analizados
+ */
+func imprimirArchivosIdenticos(tablaCodigoFuente []CodigoFuente) {
+	fmt.Print("\nARCHIVOS IDÉNTICOS (se calculan las distancias solamente del primero de cada contenido)\n\n")
+
+	for _, archivo := range tablaCodigoFuente {
+		if len(archivo.alias) == 0 {
+			continue
+		}
+
+		fmt.Println(archivo.nombre)
+		for _, nombre := range archivo.alias {
+			fmt.Println("\t= " + nombre)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+var corpusIdenticos = map[string]string{
+	"a/main.go": "package main\n\nfunc main() { println(\"hola mundo\") }\n",
+	"b/main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfor i := 0; i < 10; i++ {\n\t\tfmt.Println(i * i)\n\t}\n}\n",
+	"c/main.go": "package main\n\nfunc main() { println(\"hola mundo\") }\n",
+}
+
+func TestAgruparArchivosIdenticos(t *testing.T) {
+	crearCorpusPrueba(t, corpusIdenticos)
+
+	conservados, alias := agruparArchivosIdenticos([]string{"./a/main.go", "./b/main.go", "./c/main.go"}, nil)
+
+	if !reflect.DeepEqual(conservados, []string{"./a/main.go", "./b/main.go"}) {
+		t.Errorf("conservados = %v", conservados)
+	}
+	if !reflect.DeepEqual(alias, map[string][]string{"./a/main.go": {"./c/main.go"}}) {
+		t.Errorf("alias = %v", alias)
+	}
+}
+
+func TestDeduplicarConservaCopiasEnGrupos(t *testing.T) {
+	parametros := crearParametrosPrueba()
+	parametros.criterioDistancia = crearCriterioValor(5)
+	parametros.deduplicar = true
+
+	resultado := analizarCorpusPrueba(t, parametros, corpusIdenticos)
+
+	if len(resultado.archivos) != 3 {
+		t.Fatalf("archivos = %d, se esperaban 3", len(resultado.archivos))
+	}
+	a, c := buscarArchivoPrueba(t, resultado, "./a/main.go"), buscarArchivoPrueba(t, resultado, "./c/main.go")
+	if resultado.matriz[a][c] != 0 || resultado.matriz[c][a] != 0 {
+		t.Errorf("distancia entre las copias = %v, se esperaba 0", resultado.matriz[a][c])
+	}
+
+	if len(resultado.grupos) != 1 {
+		t.Fatalf("grupos = %d, se esperaba 1", len(resultado.grupos))
+	}
+	integrantes := map[int]bool{}
+	for _, integrante := range resultado.grupos[0].integrantes {
+		integrantes[integrante.indice] = true
+	}
+	if len(integrantes) != 2 || !integrantes[a] || !integrantes[c] {
+		t.Errorf("integrantes del grupo = %v, se esperaban las dos copias", integrantes)
+	}
+}
+
+func TestDeduplicarConservaLaMatriz(t *testing.T) {
+	parametros := crearParametrosPrueba()
+	sinAgrupar := analizarCorpusPrueba(t, parametros, corpusIdenticos)
+
+	parametros.deduplicar = true
+	agrupado := analizarCorpusPrueba(t, parametros, corpusIdenticos)
+
+	if !reflect.DeepEqual(sinAgrupar.matriz, agrupado.matriz) {
+		t.Errorf("matriz con --dedup = %v, sin --dedup = %v", agrupado.matriz, sinAgrupar.matriz)
+	}
+}
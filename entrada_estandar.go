@@ -0,0 +1,267 @@
+/*
+ * Modo de una sola pasada por la entrada y la salida estándar (--stdin).
+ *
+ * Lee las entregas como un flujo tar por la entrada estándar y escribe el resultado en JSON por la salida
+ * estándar, sin leer ni escribir archivos, para ejecutar SASC como un paso sin estado de un contenedor
+ * en un proceso de calificación automática, por ejemplo:
+ *
+ *     tar -cf - entregas | docker run -i sasc --stdin java 40 > resultado.json
+ *
+ * Los errores al leer el flujo se escriben en la salida de errores para no mezclarlos con el JSON.
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Estructura del resultado JSON del análisis de un flujo tar (modo de entrada estándar y servidor)
+// - versión del esquema (ver esquema_resultado.go)
+// - extensión y motor de características empleados
+// - criterio de la distancia máxima ("none", "auto" o "value") y distancia máxima (se omite si no se definió)
+// - archivos analizados y descartados por no tener contenido
+// - matriz de distancias entre los archivos analizados (en el orden de los archivos)
+// - grupos (vacío si no se definió una distancia máxima)
+// - validez de cada archivo (analizado o descartado)
+// - pares marcados por las reglas personalizadas (se omite si no hay reglas)
+// - archivos idénticos que representa cada archivo analizado (se omite sin --dedup)
+type ResultadoAnalisisJSON struct {
+	Version           int                 `json:"version"`
+	Extension         string              `json:"extension"`
+	Motor             string              `json:"motor"`
+	CriterioDistancia string              `json:"criterio_distancia"`
+	DistanciaMaxima   *float64            `json:"distancia_maxima,omitempty"`
+	Archivos          []string            `json:"archivos"`
+	Descartados       []string            `json:"descartados"`
+	Distancias        []DistanciasJSON    `json:"distancias"`
+	Grupos            []GrupoJSON         `json:"grupos"`
+	Validez           []ValidezArchivo    `json:"validez"`
+	Reglas            []ParReglaJSON      `json:"reglas,omitempty"`
+	Severidad         []ParSeveridadJSON  `json:"severidad,omitempty"`
+	Proyeccion        *Proyeccion         `json:"proyeccion,omitempty"`
+	Alias             map[string][]string `json:"alias,omitempty"`
+}
+
+/*
+ * Función para leer los archivos de una extensión de un flujo tar, incluyendo los de los archivos .zip del flujo
+ * param: flujo tar, extensión de los archivos a analizar y límites de la extracción de los .zip y de la lectura del tar
+ * return: rutas relativas (iniciando con "./") y contenido de los archivos, ordenados por ruta, los .zip omitidos por
+ *         no ser válidos o superar los límites de la extracción, o un error si el flujo no es un tar válido, uno de
+ *         sus archivos supera el tamaño máximo o tiene demasiados archivos
+ */
+func leerArchivosTar(lector io.Reader, extension string, limites LimitesExtraccion) ([]string, [][]byte, []ZipOmitido, error) {
+	var rutas []string
+	var contenidos [][]byte
+	var omitidos []ZipOmitido
+
+	flujo := tar.NewReader(lector)
+
+	for {
+		encabezado, err := flujo.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Error al leer el flujo tar de la entrada estándar: %v", err)
+		}
+
+		comprimido := strings.EqualFold(path.Ext(encabezado.Name), ".zip")
+		if encabezado.Typeflag != tar.TypeReg || (!comprimido && !strings.HasSuffix(encabezado.Name, extension)) {
+			continue
+		}
+
+		// No se confía en el tamaño declarado en el encabezado, se cuentan los bytes leídos
+		contenido, err := ioutil.ReadAll(io.LimitReader(flujo, limites.tamanoEntrada+1))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Error al leer \"%s\" del flujo tar: %v", encabezado.Name, err)
+		}
+		if int64(len(contenido)) > limites.tamanoEntrada {
+			return nil, nil, nil, fmt.Errorf("\"%s\" del flujo tar supera el tamaño máximo de cada archivo (--tar-max-entry-size)", encabezado.Name)
+		}
+
+		ruta := strings.TrimPrefix(path.Clean("/"+encabezado.Name), "/")
+
+		if comprimido {
+			rutasZip, contenidosZip, err := leerArchivosZip(contenido, ruta, extension, limites)
+			if err != nil {
+				omitidos = append(omitidos, ZipOmitido{Archivo: "./" + ruta, Motivo: err.Error(), tamano: len(contenido)})
+				continue
+			}
+			for _, rutaZip := range rutasZip {
+				rutas = append(rutas, "./"+rutaZip)
+			}
+			contenidos = append(contenidos, contenidosZip...)
+		} else {
+			rutas = append(rutas, "./"+ruta)
+			contenidos = append(contenidos, contenido)
+		}
+
+		if len(rutas) > limites.maximoArchivos {
+			return nil, nil, nil, fmt.Errorf("El flujo tar tiene más de %d archivos a analizar (--tar-max-files)", limites.maximoArchivos)
+		}
+	}
+
+	// Mismo orden que el recorrido de un directorio, sin importar el orden en que se creó el flujo
+	orden := make([]int, len(rutas))
+	for i := range orden {
+		orden[i] = i
+	}
+	sort.SliceStable(orden, func(a, b int) bool {
+		return rutas[orden[a]] < rutas[orden[b]]
+	})
+
+	rutasOrdenadas := make([]string, len(rutas))
+	contenidosOrdenados := make([][]byte, len(rutas))
+	for i, k := range orden {
+		rutasOrdenadas[i], contenidosOrdenados[i] = rutas[k], contenidos[k]
+	}
+
+	return rutasOrdenadas, contenidosOrdenados, omitidos, nil
+}
+
+/*
+ * Función para analizar las entregas de un flujo tar
+ * param: flujo tar, parámetros de ejecución, el motor a emplear, el preprocesamiento del contenido y el formato de las rutas
+ * return: el resultado del análisis o un error si el flujo no se puede leer
+ */
+func analizarTar(lector io.Reader, parametros Parametros, motor Motor, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas) (ResultadoAnalisisJSON, error) {
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		return ResultadoAnalisisJSON{}, err
+	}
+
+	rutas, contenidos, omitidos, err := leerArchivosTar(lector, parametros.extension, limites)
+	if err != nil {
+		return ResultadoAnalisisJSON{}, err
+	}
+
+	var tablaCodigoFuente []CodigoFuente
+	var arena ArenaCaracteristicas
+	var descartados []string
+	var validez []ValidezArchivo
+
+	for i, ruta := range rutas {
+		codigoFuente := CodigoFuente{nombre: formatoRutas.formatear(ruta), ruta: ruta, tamano: len(contenidos[i]), contenido: preprocesamiento.preprocesar(contenidos[i])}
+
+		if parametros.minimoContenido > 0 && contarContenidoSignificativo(codigoFuente.contenido, parametros.extension) < parametros.minimoContenido {
+			descartados = append(descartados, codigoFuente.nombre)
+			validez = append(validez, evaluarValidez(codigoFuente.nombre, obtenerEstudiante(".", ruta), contenidos[i], parametros.extension, VALIDEZ_DESCARTADO))
+			continue
+		}
+		validez = append(validez, evaluarValidez(codigoFuente.nombre, obtenerEstudiante(".", ruta), contenidos[i], parametros.extension, VALIDEZ_ANALIZADO))
+
+		caracterizarEnArena(&arena, motor, &codigoFuente)
+		tablaCodigoFuente = append(tablaCodigoFuente, codigoFuente)
+	}
+	for _, omitido := range omitidos {
+		validez = append(validez, evaluarValidezOmitido(formatoRutas.formatear(omitido.Archivo), obtenerEstudiante(".", omitido.Archivo), omitido.tamano, omitido.Motivo))
+	}
+
+	proyeccion := proyectarCorpus(tablaCodigoFuente, motor)
+
+	tablaCodigoFuente = determinarDistanciasEntreArchivos(tablaCodigoFuente, motor, parametros, nil)
+
+	return construirResultadoJSON(construirResultadoAnalisis(tablaCodigoFuente, parametros, motor, descartados, validez, proyeccion), parametros), nil
+}
+
+/*
+ * Función para construir el resultado JSON de un análisis
+ * param: resultado del análisis y parámetros de ejecución
+ * return: el resultado JSON del análisis
+ */
+func construirResultadoJSON(resultado ResultadoAnalisis, parametros Parametros) ResultadoAnalisisJSON {
+	resultadoJSON := ResultadoAnalisisJSON{
+		Version:           VERSION_RESULTADO,
+		Extension:         resultado.extension,
+		Motor:             resultado.motor,
+		CriterioDistancia: resultado.criterio.criterio,
+		Archivos:          []string{},
+		Descartados:       append([]string{}, resultado.descartados...),
+		Distancias:        []DistanciasJSON{},
+		Grupos:            []GrupoJSON{},
+		Validez:           append([]ValidezArchivo{}, resultado.validez...),
+		Proyeccion:        resultado.proyeccion,
+		Alias:             obtenerAliasJSON(resultado),
+	}
+
+	for i, archivo := range resultado.archivos {
+		resultadoJSON.Archivos = append(resultadoJSON.Archivos, archivo.nombre)
+		resultadoJSON.Distancias = append(resultadoJSON.Distancias, append([]float64{}, resultado.matriz[i]...))
+	}
+
+	if resultado.tieneDistanciaMaxima() {
+		distanciaMaxima := resultado.criterio.limite()
+		resultadoJSON.DistanciaMaxima = &distanciaMaxima
+		resultadoJSON.Grupos = construirGruposJSON(resultado.archivos, resultado.grupos)
+	}
+
+	// Los errores de evaluación se reportan en la consola, en el JSON solamente quedan los pares marcados
+	resultadoJSON.Reglas, _ = evaluarReglas(resultado, parametros.reglas)
+	resultadoJSON.Severidad = construirSeveridadesJSON(resultado, parametros.bandasSeveridad)
+
+	return resultadoJSON
+}
+
+/*
+ * Función para contar los pares de archivos a una distancia máxima en un resultado
+ * param: resultado del análisis
+ * return: cantidad de pares (sin repetir) a una distancia máxima, 0 si no se definió
+ */
+func contarParesMarcados(resultado ResultadoAnalisisJSON) int {
+	cantidad := 0
+
+	if resultado.DistanciaMaxima == nil {
+		return cantidad
+	}
+
+	for i := range resultado.Distancias {
+		for j := i + 1; j < len(resultado.Distancias[i]); j++ {
+			if resultado.Distancias[i][j] <= *resultado.DistanciaMaxima && esDistanciaCalculada(resultado.Distancias[i][j]) {
+				cantidad++
+			}
+		}
+	}
+
+	return cantidad
+}
+
+/*
+ * Función para analizar las entregas de la entrada estándar y escribir el resultado JSON en la salida estándar
+ * (y en los demás destinos de salida), ejecutando luego los ganchos
+ * param: parámetros de ejecución, el motor a emplear, el preprocesamiento del contenido, el formato de las rutas
+ *        y los destinos de salida adicionales
+ * return: un error si la entrada no se puede leer o el resultado no se puede escribir o enviar
+ */
+func analizarEntradaEstandar(parametros Parametros, motor Motor, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas, destinos []DestinoSalida) error {
+	resultado, err := analizarTar(os.Stdin, parametros, motor, preprocesamiento, formatoRutas)
+	if err != nil {
+		return err
+	}
+
+	codificador := json.NewEncoder(os.Stdout)
+	codificador.SetIndent("", "  ")
+
+	if err = codificador.Encode(resultado); err != nil {
+		return err
+	}
+
+	if fallidos := enviarResultado(resultado, destinos); fallidos > 0 {
+		return fmt.Errorf("No se pudo enviar el resultado a %d destinos de salida (--output)", fallidos)
+	}
+
+	// El registro de los ganchos va a la salida de errores para no mezclarlo con el JSON
+	if fallidos := ejecutarGanchos(resultado, parametros.ganchos, os.Stderr); fallidos > 0 {
+		return fmt.Errorf("Fallaron %d ganchos (--hook)", fallidos)
+	}
+
+	return nil
+}
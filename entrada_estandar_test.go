@@ -0,0 +1,129 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/*
+ * Función para crear un flujo tar en memoria
+ * param: prueba, rutas y contenido de los archivos en el orden del flujo
+ * return: el flujo tar
+ */
+func crearTarPrueba(t *testing.T, archivos [][2]string) *bytes.Buffer {
+	t.Helper()
+
+	var flujo bytes.Buffer
+	escritor := tar.NewWriter(&flujo)
+	for _, archivo := range archivos {
+		if err := escritor.WriteHeader(&tar.Header{Name: archivo[0], Mode: 0644, Size: int64(len(archivo[1])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := escritor.Write([]byte(archivo[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := escritor.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &flujo
+}
+
+/*
+ * Función para crear un archivo .zip en memoria
+ * param: prueba y contenido de los archivos por su ruta
+ * return: el contenido del .zip
+ */
+func crearZipPrueba(t *testing.T, archivos map[string]string) string {
+	t.Helper()
+
+	var contenido bytes.Buffer
+	escritor := zip.NewWriter(&contenido)
+	for ruta, texto := range archivos {
+		archivo, err := escritor.Create(ruta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archivo.Write([]byte(texto))
+	}
+	if err := escritor.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return contenido.String()
+}
+
+func TestLeerArchivosTar(t *testing.T) {
+	limites := LimitesExtraccion{profundidad: 1, tamano: 1 << 20, tamanoEntrada: 1000, maximoArchivos: 3}
+	entregas := crearZipPrueba(t, map[string]string{"src/Main.go": "package main"})
+
+	casos := []struct {
+		nombre   string
+		archivos [][2]string
+		limites  LimitesExtraccion
+		rutas    []string
+		error    string
+	}{
+		{"ordenados y filtrados", [][2]string{{"luis/main.go", "b"}, {"ana/main.go", "a"}, {"ana/notas.txt", "x"}}, limites, []string{"./ana/main.go", "./luis/main.go"}, ""},
+		{"rutas fuera del directorio", [][2]string{{"../../etc/main.go", "a"}}, limites, []string{"./etc/main.go"}, ""},
+		{"con .zip", [][2]string{{"ana/entrega.zip", entregas}}, limites, []string{"./ana/entrega.zip/src/Main.go"}, ""},
+		{"archivo en el límite", [][2]string{{"ana/main.go", strings.Repeat("a", 1000)}}, limites, []string{"./ana/main.go"}, ""},
+		{"archivo mayor al límite", [][2]string{{"ana/main.go", strings.Repeat("a", 1001)}}, limites, nil, "--tar-max-entry-size"},
+		{"archivo ignorado mayor al límite", [][2]string{{"ana/datos.bin", strings.Repeat("a", 5000)}, {"ana/main.go", "a"}}, limites, []string{"./ana/main.go"}, ""},
+		{"demasiados archivos", [][2]string{{"a/main.go", "a"}, {"b/main.go", "b"}, {"c/main.go", "c"}, {"d/main.go", "d"}}, limites, nil, "--tar-max-files"},
+		{"demasiados archivos en un .zip", [][2]string{{"a/main.go", "a"}, {"b/main.go", "b"}, {"c/entrega.zip", crearZipPrueba(t, map[string]string{"x.go": "x", "y.go": "y"})}}, limites, nil, "--tar-max-files"},
+	}
+
+	for _, caso := range casos {
+		rutas, contenidos, omitidos, err := leerArchivosTar(crearTarPrueba(t, caso.archivos), ".go", caso.limites)
+		if caso.error != "" {
+			if err == nil || !strings.Contains(err.Error(), caso.error) {
+				t.Errorf("%s: error = %v, se esperaba %s", caso.nombre, err, caso.error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: error = %v", caso.nombre, err)
+			continue
+		}
+		if !reflect.DeepEqual(rutas, caso.rutas) || len(contenidos) != len(rutas) || len(omitidos) != 0 {
+			t.Errorf("%s: rutas = %v y .zip omitidos %v, se esperaban %v", caso.nombre, rutas, omitidos, caso.rutas)
+		}
+	}
+
+	// Un .zip que no es válido o supera los límites se omite sin rechazar el flujo
+	profundo := crearZipPrueba(t, map[string]string{"a.zip": crearZipPrueba(t, map[string]string{"b.zip": entregas})})
+	rutas, _, omitidos, err := leerArchivosTar(crearTarPrueba(t, [][2]string{{"ana/entrega.zip", "no es un zip"}, {"eva/entrega.zip", profundo}, {"luis/main.go", "a"}}), ".go", limites)
+	if err != nil || !reflect.DeepEqual(rutas, []string{"./luis/main.go"}) {
+		t.Fatalf("flujo con .zip no válidos: rutas = %v, %v", rutas, err)
+	}
+	if len(omitidos) != 2 || omitidos[0].Archivo != "./ana/entrega.zip" || omitidos[1].Archivo != "./eva/entrega.zip" || !strings.Contains(omitidos[1].Motivo, "--zip-depth") {
+		t.Errorf(".zip omitidos = %+v", omitidos)
+	}
+}
+
+func TestCrearLimitesExtraccion(t *testing.T) {
+	parametros := crearParametrosPrueba()
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil || limites.tamanoEntrada != 50<<20 || limites.maximoArchivos != 20000 {
+		t.Errorf("límites por defecto = %+v, %v", limites, err)
+	}
+
+	for _, cambiar := range []func(*Parametros){
+		func(p *Parametros) { p.tamanoMaximoTar = 0 },
+		func(p *Parametros) { p.maximoArchivosTar = 0 },
+		func(p *Parametros) { p.profundidadZip = -1 },
+		func(p *Parametros) { p.tamanoMaximoZip = 0 },
+	} {
+		invalidos := crearParametrosPrueba()
+		cambiar(&invalidos)
+		if _, err := crearLimitesExtraccion(invalidos); err == nil {
+			t.Errorf("límites %d/%d/%d/%d aceptados", invalidos.tamanoMaximoTar, invalidos.maximoArchivosTar, invalidos.profundidadZip, invalidos.tamanoMaximoZip)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Ponderación de las construcciones exigidas por el enunciado (--spec y --spec-weight).
+ *
+ * En una tarea muy especificada (nombres de funciones, firmas y estructuras exigidas) todas las entregas comparten
+ * lo que pide el enunciado, y las distancias bajas no distinguen las copias de las soluciones correctas. Con
+ * --spec=enunciado.txt se indica el enunciado (texto libre, una lista de palabras o un esqueleto de código) y con el
+ * motor "tokens" los n-gramas exigidos por el enunciado pesan --spec-weight (0.25 por defecto) en lugar de 1:
+ * - los n-gramas con algún término del enunciado (identificadores y palabras reservadas de al menos dos caracteres)
+ * - los n-gramas que aparecen tal cual en el enunciado (por ejemplo las firmas de un esqueleto de código)
+ * Los demás n-gramas (las decisiones propias de cada estudiante) conservan su peso, por lo que pesan relativamente
+ * más en la distancia. Con --spec-weight=0 lo exigido por el enunciado no cuenta.
+ *
+ * Solo se aplica al motor "tokens" con frecuencias dispersas (también con --reduce), porque los demás motores no
+ * tienen n-gramas que ponderar. El nombre del motor incluye la huella del enunciado, por lo que también hace parte
+ * de la huella de la caché (un enunciado distinto no usa las distancias de otro).
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Peso por defecto de los n-gramas exigidos por el enunciado
+const PESO_ESPECIFICACION = 0.25
+
+// Estructura del enunciado de la tarea
+// - términos del enunciado
+// - hashes de los n-gramas de tokens del enunciado
+// - peso de los n-gramas exigidos
+// - huella del contenido del enunciado (SHA-256)
+type Especificacion struct {
+	terminos map[string]bool
+	ngramas  map[uint64]bool
+	peso     float64
+	huella   string
+}
+
+/*
+ * Función para determinar si un token del enunciado es un término (identificador o palabra reservada)
+ * param: texto del token
+ * return: si empieza por una letra o "_" y tiene al menos dos caracteres
+ */
+func esTerminoEspecificacion(texto string) bool {
+	primero, _ := utf8.DecodeRuneInString(texto)
+
+	return utf8.RuneCountInString(texto) >= 2 && (unicode.IsLetter(primero) || primero == '_')
+}
+
+/*
+ * Función para cargar el enunciado de la tarea
+ * param: ruta del archivo del enunciado y peso de los n-gramas exigidos
+ * return: el enunciado o un error si el archivo no se puede leer, el peso no es válido o no tiene términos
+ */
+func cargarEspecificacion(ruta string, peso float64) (*Especificacion, error) {
+	if peso < 0 || peso > 1 {
+		return nil, fmt.Errorf("El peso de lo exigido por el enunciado (--spec-weight) debe estar entre 0 y 1")
+	}
+
+	contenido, err := ioutil.ReadFile(ruta)
+	if err != nil {
+		return nil, fmt.Errorf("No se puede leer el enunciado (--spec): %v", err)
+	}
+
+	hash := sha256.Sum256(contenido)
+	especificacion := &Especificacion{terminos: make(map[string]bool), ngramas: make(map[uint64]bool), peso: peso, huella: hex.EncodeToString(hash[:])}
+
+	tokens := obtenerTokens(contenido)
+	for _, token := range tokens {
+		if esTerminoEspecificacion(token.texto) {
+			especificacion.terminos[token.texto] = true
+		}
+	}
+	for _, ngrama := range agregarHashesVentanas(nil, tokens, LONGITUD_NGRAMA_TOKENS) {
+		especificacion.ngramas[ngrama] = true
+	}
+
+	if len(especificacion.terminos) == 0 {
+		return nil, fmt.Errorf("El enunciado \"%s\" no tiene términos (identificadores o palabras reservadas)", ruta)
+	}
+
+	return especificacion, nil
+}
+
+/*
+ * Función para ponderar las frecuencias de los n-gramas de un archivo según el enunciado
+ * param: tokens del archivo y frecuencia de sus n-gramas (se modifica)
+ */
+func (especificacion *Especificacion) ponderar(tokens []Token, frecuencias map[uint64]float64) {
+	exigidos := make(map[uint64]bool)
+
+	for inicio, hash := range agregarHashesVentanas(nil, tokens, LONGITUD_NGRAMA_TOKENS) {
+		if exigidos[hash] {
+			continue
+		}
+		if especificacion.ngramas[hash] {
+			exigidos[hash] = true
+			continue
+		}
+		for _, token := range tokens[inicio : inicio+LONGITUD_NGRAMA_TOKENS] {
+			if especificacion.terminos[token.texto] {
+				exigidos[hash] = true
+				break
+			}
+		}
+	}
+
+	for hash := range exigidos {
+		frecuencias[hash] *= especificacion.peso
+	}
+}
+
+/*
+ * Función para describir el enunciado en el nombre del motor
+ * return: por ejemplo "enunciado 3f9a1c2e con 12 términos, peso 0.25"
+ */
+func (especificacion *Especificacion) describir() string {
+	return fmt.Sprintf("enunciado %s con %d términos, peso %g", especificacion.huella[:8], len(especificacion.terminos), especificacion.peso)
+}
@@ -0,0 +1,175 @@
+/*
+ * Versión del esquema del resultado JSON y migración de los resultados anteriores.
+ *
+ * El resultado JSON de un análisis (--stdin, --output, --bundle y los trabajos del modo servidor) incluye la versión
+ * de su esquema. Al leer un resultado de una versión anterior (por ejemplo uno archivado de un semestre pasado con
+ * report --from, o un trabajo guardado al iniciar el servidor) se aplican en orden las migraciones hasta la versión
+ * actual, de modo que se puede seguir usando con una versión más nueva de SASC sin reescribir el archivo. Los
+ * resultados sin versión son de la versión 0 (anteriores a este registro). Un resultado de una versión posterior a la
+ * que soporta esta versión de SASC se rechaza.
+ *
+ * Versiones del esquema:
+ * - 0: sin versión; puede no tener la validez de los archivos, el par ejemplar de los grupos ni el criterio de la
+ *      distancia máxima
+ * - 1: versión registrada en el campo "version", con todos los campos anteriores
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Versión actual del esquema del resultado JSON
+const VERSION_RESULTADO = 1
+
+// Migraciones del resultado JSON, la posición i actualiza de la versión i a la versión i + 1
+// (sobre el objeto JSON genérico, porque los campos de una versión anterior pueden no corresponder a la estructura actual)
+var migracionesResultado = []func(resultado map[string]interface{}) error{
+	migrarResultadoSinVersion,
+}
+
+/*
+ * Función para leer un resultado JSON de cualquier versión del esquema, aplicando las migraciones necesarias
+ * param: contenido JSON del resultado
+ * return: un error si el contenido no es válido o el resultado es de una versión posterior
+ */
+func (resultado *ResultadoAnalisisJSON) UnmarshalJSON(contenido []byte) error {
+	// Tipo sin el método UnmarshalJSON para leer el resultado ya migrado
+	type resultadoMigrado ResultadoAnalisisJSON
+
+	var generico map[string]interface{}
+	if err := json.Unmarshal(contenido, &generico); err != nil {
+		return err
+	}
+
+	version, err := obtenerVersionResultado(generico)
+	if err != nil {
+		return err
+	}
+
+	for ; version < VERSION_RESULTADO; version++ {
+		if err = migracionesResultado[version](generico); err != nil {
+			return fmt.Errorf("No se puede migrar el resultado de la versión %d a la versión %d: %v", version, version+1, err)
+		}
+		generico["version"] = version + 1
+	}
+
+	if contenido, err = json.Marshal(generico); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(contenido, (*resultadoMigrado)(resultado))
+}
+
+/*
+ * Función para obtener la versión del esquema de un resultado JSON
+ * param: objeto JSON genérico del resultado
+ * return: la versión (0 si no tiene) o un error si no es válida o es posterior a la versión actual
+ */
+func obtenerVersionResultado(resultado map[string]interface{}) (int, error) {
+	valor, ok := resultado["version"]
+	if !ok || valor == nil {
+		return 0, nil
+	}
+
+	numero, ok := valor.(float64)
+	if !ok || numero < 0 || numero != math.Trunc(numero) {
+		return 0, fmt.Errorf("Versión del esquema del resultado no válida: %v", valor)
+	}
+
+	version := int(numero)
+	if version > VERSION_RESULTADO {
+		return version, fmt.Errorf("El resultado es de la versión %d del esquema, posterior a la que soporta esta versión de SASC (%d)", version, VERSION_RESULTADO)
+	}
+
+	return version, nil
+}
+
+/*
+ * Función para migrar un resultado sin versión (versión 0) a la versión 1: completa la validez de los archivos
+ * (vacía), el criterio de la distancia máxima ("value" si tiene distancia máxima, "none" si no) y el par ejemplar
+ * de cada grupo (calculado con la matriz de distancias)
+ * param: objeto JSON genérico del resultado (se modifica)
+ * return: un error si un grupo incluye un archivo desconocido o la matriz no corresponde a los archivos
+ */
+func migrarResultadoSinVersion(resultado map[string]interface{}) error {
+	for _, campo := range []string{"archivos", "descartados", "distancias", "grupos", "validez"} {
+		if resultado[campo] == nil {
+			resultado[campo] = []interface{}{}
+		}
+	}
+
+	if _, ok := resultado["criterio_distancia"]; !ok {
+		if resultado["distancia_maxima"] != nil {
+			resultado["criterio_distancia"] = CRITERIO_VALOR
+		} else {
+			resultado["criterio_distancia"] = CRITERIO_NINGUNO
+		}
+	}
+
+	archivos, _ := resultado["archivos"].([]interface{})
+	distancias, _ := resultado["distancias"].([]interface{})
+	grupos, _ := resultado["grupos"].([]interface{})
+
+	indices := make(map[string]int)
+	for i, archivo := range archivos {
+		if nombre, ok := archivo.(string); ok {
+			indices[nombre] = i
+		}
+	}
+
+	distancia := func(i int, j int) (float64, bool) {
+		if i >= len(distancias) {
+			return 0, false
+		}
+		fila, _ := distancias[i].([]interface{})
+		if j >= len(fila) {
+			return 0, false
+		}
+		valor, ok := fila[j].(float64)
+		return valor, ok
+	}
+
+	for _, elemento := range grupos {
+		grupo, ok := elemento.(map[string]interface{})
+		if !ok || grupo["par_ejemplar"] != nil {
+			continue
+		}
+
+		var miembros []string
+		integrantes, _ := grupo["integrantes"].([]interface{})
+		for _, integrante := range integrantes {
+			if datos, ok := integrante.(map[string]interface{}); ok {
+				nombre, _ := datos["archivo"].(string)
+				if _, ok := indices[nombre]; !ok {
+					return fmt.Errorf("el grupo %v incluye el archivo desconocido \"%s\"", grupo["grupo"], nombre)
+				}
+				miembros = append(miembros, nombre)
+			}
+		}
+		if len(miembros) < 2 {
+			continue
+		}
+
+		// Mismo criterio que al formar los grupos: el primer par (en orden) a la menor distancia
+		parEjemplar := map[string]interface{}{"archivo1": miembros[0], "archivo2": miembros[1], "distancia": math.MaxFloat64}
+		for i, miembro1 := range miembros {
+			for _, miembro2 := range miembros[i+1:] {
+				valor, ok := distancia(indices[miembro1], indices[miembro2])
+				if !ok {
+					return fmt.Errorf("la matriz de distancias no corresponde a los archivos")
+				}
+				if valor < parEjemplar["distancia"].(float64) {
+					parEjemplar["archivo1"], parEjemplar["archivo2"], parEjemplar["distancia"] = miembro1, miembro2, valor
+				}
+			}
+		}
+
+		grupo["par_ejemplar"] = parEjemplar
+	}
+
+	return nil
+}
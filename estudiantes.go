@@ -0,0 +1,224 @@
+/*
+ * Lista de estudiantes para presentar los nombres reales en los reportes (--students).
+ *
+ * Las exportaciones de los LMS nombran el directorio de cada entrega con el código o el correo del estudiante
+ * (por ejemplo "1094123456_entrega" o "ana.perez@uniquindio.edu.co"), lo que obliga a buscar a cada estudiante en la
+ * lista del curso al revisar los reportes. Con --students=estudiantes.csv se lee la lista del curso (CSV con las
+ * columnas "id", "name" y, opcionalmente, "email" y "section"; también "codigo", "nombre", "correo" y "seccion") y el
+ * directorio de cada estudiante (el subdirectorio inmediato del directorio base) se presenta en todos los reportes
+ * como "Nombre [sección]", por ejemplo "./Ana Pérez [grupo2]/src/Main.java". El estudiante de un directorio es:
+ * - el que tiene como código o correo el nombre completo del directorio (sin distinguir mayúsculas)
+ * - o el que tiene como código alguno de los números del directorio que coinciden con --student-id-pattern
+ * - o el que tiene como correo alguna dirección de correo del nombre del directorio
+ * Los directorios sin estudiante en la lista conservan su nombre y se listan antes del análisis. Con --paths=basename
+ * los reportes muestran solo el nombre del archivo, sin el estudiante.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Estructura de un estudiante de la lista del curso
+// - código, nombre, correo y sección (el correo y la sección pueden estar vacíos)
+type Estudiante struct {
+	identificador string
+	nombre        string
+	correo        string
+	seccion       string
+}
+
+// Estructura de la lista de estudiantes del curso
+// - estudiantes por código y por correo (en minúsculas)
+// - expresión regular con la que se extraen los códigos del nombre de un directorio
+type ListaEstudiantes struct {
+	porIdentificador        map[string]*Estudiante
+	porCorreo               map[string]*Estudiante
+	expresionIdentificacion *regexp.Regexp
+}
+
+/*
+ * Función para obtener la columna de un campo en el encabezado de la lista de estudiantes
+ * param: encabezado del CSV y nombres aceptados de la columna
+ * return: índice de la columna (-1 si no está)
+ */
+func obtenerColumnaEstudiantes(encabezado []string, nombres ...string) int {
+	for i, columna := range encabezado {
+		columna = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(columna, "\uFEFF")))
+		for _, nombre := range nombres {
+			if columna == nombre {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+/*
+ * Función para leer la lista de estudiantes del curso
+ * param: parámetros de ejecución (archivo de la lista y expresión regular de los códigos)
+ * return: la lista (nil si no se indicó) o un error si el archivo no se puede leer o no tiene las columnas esperadas
+ */
+func leerListaEstudiantes(parametros Parametros) (*ListaEstudiantes, error) {
+	if parametros.listaEstudiantes == "" {
+		return nil, nil
+	}
+
+	expresion, err := regexp.Compile(parametros.patronIdentificacion)
+	if err != nil {
+		return nil, fmt.Errorf("Expresión regular del código de estudiante (--student-id-pattern) no válida: %v", err)
+	}
+
+	archivo, err := os.Open(parametros.listaEstudiantes)
+	if err != nil {
+		return nil, fmt.Errorf("No se puede leer la lista de estudiantes (--students): %v", err)
+	}
+	defer archivo.Close()
+
+	lector := csv.NewReader(archivo)
+	lector.FieldsPerRecord = -1
+	registros, err := lector.ReadAll()
+	if err != nil || len(registros) == 0 {
+		return nil, fmt.Errorf("Lista de estudiantes (--students) no válida: %v", err)
+	}
+
+	columnaIdentificador := obtenerColumnaEstudiantes(registros[0], "id", "identifier", "codigo", "código")
+	columnaNombre := obtenerColumnaEstudiantes(registros[0], "name", "nombre")
+	columnaCorreo := obtenerColumnaEstudiantes(registros[0], "email", "correo")
+	columnaSeccion := obtenerColumnaEstudiantes(registros[0], "section", "seccion", "sección", "grupo")
+	if columnaIdentificador < 0 || columnaNombre < 0 {
+		return nil, fmt.Errorf("La lista de estudiantes (--students) debe tener las columnas \"id\" y \"name\" (y opcionalmente \"email\" y \"section\")")
+	}
+
+	lista := ListaEstudiantes{porIdentificador: make(map[string]*Estudiante), porCorreo: make(map[string]*Estudiante), expresionIdentificacion: expresion}
+
+	campo := func(registro []string, columna int) string {
+		if columna < 0 || columna >= len(registro) {
+			return ""
+		}
+		return strings.TrimSpace(registro[columna])
+	}
+
+	for numero, registro := range registros[1:] {
+		estudiante := &Estudiante{
+			identificador: campo(registro, columnaIdentificador),
+			nombre:        campo(registro, columnaNombre),
+			correo:        campo(registro, columnaCorreo),
+			seccion:       campo(registro, columnaSeccion),
+		}
+		if estudiante.identificador == "" || estudiante.nombre == "" {
+			return nil, fmt.Errorf("La fila %d de la lista de estudiantes (--students) no tiene código o nombre", numero+2)
+		}
+
+		lista.porIdentificador[strings.ToLower(estudiante.identificador)] = estudiante
+		if estudiante.correo != "" {
+			lista.porCorreo[strings.ToLower(estudiante.correo)] = estudiante
+		}
+	}
+
+	return &lista, nil
+}
+
+/*
+ * Función para buscar el estudiante de un directorio de entregas
+ * param: nombre del directorio del estudiante
+ * return: el estudiante o nil si no está en la lista (o no hay lista)
+ */
+func (lista *ListaEstudiantes) buscar(directorio string) *Estudiante {
+	if lista == nil {
+		return nil
+	}
+
+	clave := strings.ToLower(directorio)
+	if estudiante, existe := lista.porIdentificador[clave]; existe {
+		return estudiante
+	}
+	if estudiante, existe := lista.porCorreo[clave]; existe {
+		return estudiante
+	}
+
+	// Los LMS separan los campos del directorio con "_" (por ejemplo "Ana Perez_1094123456_assignsubmission_file_"),
+	// que para \b hace parte de la palabra
+	for _, identificador := range lista.expresionIdentificacion.FindAllString(strings.ReplaceAll(directorio, "_", " "), -1) {
+		if estudiante, existe := lista.porIdentificador[strings.ToLower(identificador)]; existe {
+			return estudiante
+		}
+	}
+	for _, correo := range expresionCorreo.FindAllString(directorio, -1) {
+		if estudiante, existe := lista.porCorreo[strings.ToLower(correo)]; existe {
+			return estudiante
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Función para presentar a un estudiante en los reportes
+ * return: el nombre con la sección, por ejemplo "Ana Pérez [grupo2]"
+ */
+func (estudiante *Estudiante) presentar() string {
+	if estudiante.seccion == "" {
+		return estudiante.nombre
+	}
+
+	return estudiante.nombre + " [" + estudiante.seccion + "]"
+}
+
+/*
+ * Función para reemplazar el directorio del estudiante de una ruta relativa por su nombre y sección
+ * param: ruta relativa del archivo (iniciando con "./")
+ * return: la ruta con el estudiante (sin cambios si no hay lista, el archivo está en el directorio base o el
+ *         directorio no está en la lista)
+ */
+func (lista *ListaEstudiantes) presentarRuta(ruta string) string {
+	if lista == nil {
+		return ruta
+	}
+
+	partes := strings.SplitN(strings.TrimPrefix(ruta, "./"), "/", 2)
+	if len(partes) < 2 {
+		return ruta
+	}
+
+	estudiante := lista.buscar(partes[0])
+	if estudiante == nil {
+		return ruta
+	}
+
+	return "./" + estudiante.presentar() + "/" + partes[1]
+}
+
+/*
+ * Función para obtener los directorios de estudiantes de un listado que no están en la lista
+ * param: listado de archivos a analizar (rutas relativas al directorio base)
+ * return: los directorios sin estudiante, sin repetir y en el orden del listado (vacío si no hay lista)
+ */
+func (lista *ListaEstudiantes) obtenerDirectoriosSinEstudiante(listado []string) []string {
+	var directorios []string
+
+	if lista == nil {
+		return directorios
+	}
+
+	vistos := make(map[string]bool)
+	for _, archivo := range listado {
+		directorio := obtenerEstudiante(".", archivo)
+		if directorio == "." || vistos[directorio] {
+			continue
+		}
+		vistos[directorio] = true
+
+		if lista.buscar(directorio) == nil {
+			directorios = append(directorios, directorio)
+		}
+	}
+
+	return directorios
+}
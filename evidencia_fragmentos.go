@@ -0,0 +1,463 @@
+/*
+ * Evidencia por fragmentos comunes (Rabin-Karp).
+ *
+ * Cada archivo se convierte en una secuencia de tokens (identificadores, números y símbolos, sin espacios)
+ * y se buscan todos los fragmentos (subcadenas de tokens) compartidos entre un par de archivos con una
+ * longitud mínima, usando un hash rodante de Rabin-Karp sobre las ventanas de tokens.
+ *
+ * Con los fragmentos se calcula la cobertura de cada archivo, es decir, el porcentaje de sus tokens que
+ * aparece textualmente en el otro archivo ("34% de A aparece en B"). La cobertura es asimétrica y se
+ * reporta en ambos sentidos, por ser más fácil de interpretar que la distancia euclidiana.
+ */
+
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"time"
+	"unicode"
+)
+
+// Base del hash rodante de Rabin-Karp (módulo 2^64 por desbordamiento)
+const BASE_RABIN_KARP = 1000003
+
+// Estructura para almacenar un token de un archivo
+// - texto del token
+// - hash del texto
+// - número de la línea y columna (en caracteres) en la que inicia (ambos inician en 1)
+type Token struct {
+	texto   string
+	hash    uint64
+	numero  int
+	columna int
+}
+
+// Estructura para almacenar un fragmento común entre dos archivos
+// - posición (en tokens) del fragmento en el primer archivo
+// - posición (en tokens) del fragmento en el segundo archivo
+// - longitud del fragmento (en tokens)
+type Fragmento struct {
+	inicio1  int
+	inicio2  int
+	longitud int
+}
+
+// Estructura para almacenar los fragmentos comunes de un par de archivos y su cobertura
+// - fragmentos comunes ordenados por su posición en el primer archivo
+// - porcentaje del primer archivo que aparece en el segundo
+// - porcentaje del segundo archivo que aparece en el primero
+type ResultadoFragmentos struct {
+	fragmentos []Fragmento
+	cobertura1 float64
+	cobertura2 float64
+}
+
+// Estructura para almacenar la comparación por fragmentos de un par de archivos candidatos
+// - índices de ambos archivos en la tabla de código fuente
+// - distancia entre ambos archivos según el motor de características
+// - fragmentos comunes y cobertura
+// - si el par se quedó sin evidencia por el presupuesto de tiempo (solo se reporta su distancia)
+// - cantidad de huellas distintas compartidas (solo con winnowing, ver evidencia_winnowing.go)
+type ParFragmentos struct {
+	indice1      int
+	indice2      int
+	distancia    float64
+	resultado    ResultadoFragmentos
+	sinEvidencia bool
+	huellas      int
+}
+
+/*
+ * Función para agregar un token a una secuencia de tokens
+ * param: secuencia de tokens, texto del token, número de línea y columna
+ * return: secuencia de tokens con el nuevo token
+ */
+func agregarToken(tokens []Token, texto string, numero int, columna int) []Token {
+	hash := fnv.New64a()
+	hash.Write([]byte(texto))
+
+	return append(tokens, Token{texto: texto, hash: hash.Sum64(), numero: numero, columna: columna})
+}
+
+/*
+ * Función para obtener los tokens de un contenido.
+ * Un token es una secuencia de letras, dígitos o "_", o un símbolo individual. Los espacios se descartan.
+ * param: contenido del archivo
+ * return: secuencia de tokens con su número de línea y columna
+ */
+func obtenerTokens(contenido []byte) []Token {
+	var tokens []Token
+	var palabra []rune
+
+	numero, numeroPalabra := 1, 1
+	columna, columnaPalabra := 0, 0
+
+	for _, caracter := range string(contenido) {
+		columna++
+
+		if unicode.IsLetter(caracter) || unicode.IsDigit(caracter) || caracter == '_' {
+			if len(palabra) == 0 {
+				numeroPalabra, columnaPalabra = numero, columna
+			}
+			palabra = append(palabra, caracter)
+			continue
+		}
+
+		if len(palabra) > 0 {
+			tokens = agregarToken(tokens, string(palabra), numeroPalabra, columnaPalabra)
+			palabra = palabra[:0]
+		}
+
+		if caracter == '\n' {
+			numero, columna = numero+1, 0
+		} else if !unicode.IsSpace(caracter) {
+			tokens = agregarToken(tokens, string(caracter), numero, columna)
+		}
+	}
+
+	if len(palabra) > 0 {
+		tokens = agregarToken(tokens, string(palabra), numeroPalabra, columnaPalabra)
+	}
+
+	return tokens
+}
+
+/*
+ * Función para calcular el hash rodante de todas las ventanas de una secuencia de tokens
+ * param: secuencia de tokens y longitud de la ventana
+ * return: arreglo con el hash de la ventana que inicia en cada posición
+ */
+func calcularHashesVentanas(tokens []Token, longitud int) []uint64 {
+	if len(tokens) < longitud {
+		return nil
+	}
+
+	return agregarHashesVentanas(make([]uint64, 0, len(tokens)-longitud+1), tokens, longitud)
+}
+
+/*
+ * Función para agregar a un arreglo el hash rodante de todas las ventanas de una secuencia de tokens
+ * param: arreglo al que se agregan los hashes (para reutilizarlo entre archivos), secuencia de tokens y longitud de la ventana
+ * return: el arreglo con el hash de la ventana que inicia en cada posición
+ */
+func agregarHashesVentanas(hashes []uint64, tokens []Token, longitud int) []uint64 {
+	if len(tokens) < longitud {
+		return hashes
+	}
+
+	// BASE^(longitud-1) para retirar el primer token de la ventana
+	potencia := uint64(1)
+	for i := 1; i < longitud; i++ {
+		potencia *= BASE_RABIN_KARP
+	}
+
+	var hash uint64
+	for i := 0; i < longitud; i++ {
+		hash = hash*BASE_RABIN_KARP + tokens[i].hash
+	}
+	hashes = append(hashes, hash)
+
+	for i := 1; i <= len(tokens)-longitud; i++ {
+		hash = (hash-tokens[i-1].hash*potencia)*BASE_RABIN_KARP + tokens[i+longitud-1].hash
+		hashes = append(hashes, hash)
+	}
+
+	return hashes
+}
+
+/*
+ * Función para determinar la longitud de la coincidencia exacta a partir de dos posiciones
+ * param: ambas secuencias de tokens y las posiciones iniciales
+ * return: cantidad de tokens iguales consecutivos
+ */
+func longitudCoincidencia(tokens1 []Token, inicio1 int, tokens2 []Token, inicio2 int) int {
+	longitud := 0
+
+	for inicio1+longitud < len(tokens1) && inicio2+longitud < len(tokens2) &&
+		tokens1[inicio1+longitud].hash == tokens2[inicio2+longitud].hash {
+		longitud++
+	}
+
+	return longitud
+}
+
+/*
+ * Función para buscar los fragmentos comunes entre dos secuencias de tokens (Rabin-Karp).
+ * Se recorre el primer archivo y por cada ventana presente en el segundo archivo se extiende la coincidencia
+ * tanto como sea posible, luego se continúa después del fragmento encontrado.
+ * param: ambas secuencias de tokens, la longitud mínima de un fragmento y el límite de tiempo (cero si no hay límite)
+ * return: los fragmentos comunes ordenados por su posición en el primer archivo y si la búsqueda terminó antes del límite
+ */
+func buscarFragmentosComunes(tokens1 []Token, tokens2 []Token, minimo int, limite time.Time) ([]Fragmento, bool) {
+	var fragmentos []Fragmento
+
+	if minimo < 1 || len(tokens1) < minimo || len(tokens2) < minimo {
+		return fragmentos, true
+	}
+
+	ventanas := make(map[uint64][]int)
+	for j, hash := range calcularHashesVentanas(tokens2, minimo) {
+		ventanas[hash] = append(ventanas[hash], j)
+	}
+
+	hashes1 := calcularHashesVentanas(tokens1, minimo)
+	comparaciones := 0
+
+	for i := 0; i < len(hashes1); {
+		mejor := Fragmento{inicio1: i}
+
+		for _, j := range ventanas[hashes1[i]] {
+			if comparaciones++; comparaciones%VERIFICACION_PRESUPUESTO == 0 && superaLimite(limite) {
+				return nil, false
+			}
+
+			// Se verifica la coincidencia porque dos ventanas distintas pueden tener el mismo hash
+			if longitud := longitudCoincidencia(tokens1, i, tokens2, j); longitud >= minimo && longitud > mejor.longitud {
+				mejor.inicio2 = j
+				mejor.longitud = longitud
+			}
+		}
+
+		if mejor.longitud == 0 {
+			i++
+			continue
+		}
+
+		fragmentos = append(fragmentos, mejor)
+		i += mejor.longitud
+	}
+
+	return fragmentos, true
+}
+
+/*
+ * Función para descartar los fragmentos que abarcan menos de la cantidad mínima de líneas en alguno de los archivos
+ * param: fragmentos, tokens de ambos archivos y la cantidad mínima de líneas
+ * return: los fragmentos que abarcan al menos la cantidad mínima de líneas
+ */
+func filtrarFragmentosPorLineas(fragmentos []Fragmento, tokens1 []Token, tokens2 []Token, minimoLineas int) []Fragmento {
+	var filtrados []Fragmento
+
+	for _, fragmento := range fragmentos {
+		lineas1 := tokens1[fragmento.inicio1+fragmento.longitud-1].numero - tokens1[fragmento.inicio1].numero + 1
+		lineas2 := tokens2[fragmento.inicio2+fragmento.longitud-1].numero - tokens2[fragmento.inicio2].numero + 1
+
+		if lineas1 >= minimoLineas && lineas2 >= minimoLineas {
+			filtrados = append(filtrados, fragmento)
+		}
+	}
+
+	return filtrados
+}
+
+/*
+ * Función para obtener el resultado (fragmentos y cobertura) de un par a partir de sus fragmentos comunes.
+ * Los fragmentos contenidos en otro fragmento del primer archivo se descartan del listado, pero la cobertura
+ * considera todas las posiciones cubiertas en cada archivo.
+ * param: fragmentos del par y la cantidad de tokens de ambos archivos
+ * return: los fragmentos comunes y la cobertura de ambos archivos
+ */
+func resumirFragmentos(fragmentos []Fragmento, cantidad1 int, cantidad2 int) ResultadoFragmentos {
+	var resultado ResultadoFragmentos
+
+	if cantidad1 == 0 || cantidad2 == 0 {
+		return resultado
+	}
+
+	sort.Slice(fragmentos, func(j, k int) bool {
+		if fragmentos[j].inicio1 != fragmentos[k].inicio1 {
+			return fragmentos[j].inicio1 < fragmentos[k].inicio1
+		}
+		return fragmentos[j].longitud > fragmentos[k].longitud
+	})
+
+	cubierto1 := make([]bool, cantidad1)
+	cubierto2 := make([]bool, cantidad2)
+	finMayor := -1
+
+	for _, fragmento := range fragmentos {
+		for k := 0; k < fragmento.longitud; k++ {
+			cubierto1[fragmento.inicio1+k] = true
+			cubierto2[fragmento.inicio2+k] = true
+		}
+
+		if fragmento.inicio1+fragmento.longitud > finMayor {
+			resultado.fragmentos = append(resultado.fragmentos, fragmento)
+			finMayor = fragmento.inicio1 + fragmento.longitud
+		}
+	}
+
+	resultado.cobertura1 = 100.0 * float64(contarCubiertos(cubierto1)) / float64(cantidad1)
+	resultado.cobertura2 = 100.0 * float64(contarCubiertos(cubierto2)) / float64(cantidad2)
+
+	return resultado
+}
+
+/*
+ * Función para contar las posiciones cubiertas
+ * param: arreglo de posiciones cubiertas
+ * return: cantidad de posiciones cubiertas
+ */
+func contarCubiertos(cubierto []bool) int {
+	cantidad := 0
+
+	for _, valor := range cubierto {
+		if valor {
+			cantidad++
+		}
+	}
+
+	return cantidad
+}
+
+/*
+ * Función para obtener los tokens de un archivo de la tabla, calculándolos si aún no se tienen
+ * param: arreglo con la información del código fuente de los archivos e índice del archivo
+ * return: secuencia de tokens del archivo
+ */
+func obtenerTokensArchivo(tablaCodigoFuente []CodigoFuente, indice int) []Token {
+	if tablaCodigoFuente[indice].tokens == nil {
+		tablaCodigoFuente[indice].tokens = obtenerTokens(tablaCodigoFuente[indice].contenido)
+	}
+
+	return tablaCodigoFuente[indice].tokens
+}
+
+/*
+ * Función para comparar por fragmentos comunes todos los pares de archivos a una distancia máxima.
+ * Con el arreglo de sufijos los fragmentos de todo el corpus se obtienen de una sola vez, con winnowing se
+ * extienden las huellas compartidas de cada par candidato, con Greedy String Tiling se cubre cada par candidato con
+ * baldosas sin tokens en común, y en otro caso se usa Rabin-Karp para cada par candidato.
+ * Los fragmentos deben tener al menos la cantidad mínima de tokens y abarcar la cantidad mínima de líneas.
+ * Con Rabin-Karp, winnowing y Greedy String Tiling los pares que agotan el presupuesto de tiempo quedan sin evidencia (ver presupuesto_evidencia.go).
+ * param: arreglo con la información del código fuente de los archivos, la distancia mínima, el motor de evidencia
+ *        ("rabin-karp", "suffix-array", "winnowing" o "gst"), las longitudes mínimas de un fragmento (en tokens y en líneas),
+ *        la ventana de winnowing y el presupuesto de tiempo
+ * return: arreglo con la comparación de cada par candidato
+ */
+func compararParesPorFragmentos(tablaCodigoFuente []CodigoFuente, distanciaMinima float64, motorEvidencia string, minimoTokens int, minimoLineas int, ventana int, presupuesto PresupuestoEvidencia) []ParFragmentos {
+	var pares []ParFragmentos
+	var fragmentosCorpus map[[2]int][]Fragmento
+
+	usarSufijos := motorEvidencia == "suffix-array"
+	huellasArchivos := make(map[int][]HuellaWinnowing)
+	obtenerHuellas := func(indice int) []HuellaWinnowing {
+		if _, existe := huellasArchivos[indice]; !existe {
+			huellasArchivos[indice] = calcularHuellasWinnowing(obtenerTokensArchivo(tablaCodigoFuente, indice), minimoTokens, ventana)
+		}
+		return huellasArchivos[indice]
+	}
+
+	if usarSufijos {
+		tokensArchivos := make([][]Token, len(tablaCodigoFuente))
+		for i := range tablaCodigoFuente {
+			tokensArchivos[i] = obtenerTokensArchivo(tablaCodigoFuente, i)
+		}
+		fragmentosCorpus = buscarFragmentosCorpus(tokensArchivos, minimoTokens)
+	}
+
+	for i := 0; i < len(tablaCodigoFuente); i++ {
+		for j := i + 1; j < len(tablaCodigoFuente); j++ {
+			distancia := obtenerDistancia(tablaCodigoFuente[i], j)
+
+			if distancia > distanciaMinima {
+				continue
+			}
+
+			if !usarSufijos && presupuesto.agotado() {
+				pares = append(pares, ParFragmentos{indice1: i, indice2: j, distancia: distancia, sinEvidencia: true})
+				continue
+			}
+
+			tokens1, tokens2 := obtenerTokensArchivo(tablaCodigoFuente, i), obtenerTokensArchivo(tablaCodigoFuente, j)
+
+			var fragmentos []Fragmento
+			huellas := 0
+			completo := true
+			switch motorEvidencia {
+			case "suffix-array":
+				fragmentos = fragmentosCorpus[[2]int{i, j}]
+			case "winnowing":
+				fragmentos, huellas, completo = buscarFragmentosWinnowing(tokens1, tokens2, obtenerHuellas(i), obtenerHuellas(j), minimoTokens, presupuesto.limitePar())
+			case "gst":
+				fragmentos, completo = buscarBaldosas(tokens1, tokens2, minimoTokens, presupuesto.limitePar())
+			default:
+				fragmentos, completo = buscarFragmentosComunes(tokens1, tokens2, minimoTokens, presupuesto.limitePar())
+			}
+			if !completo {
+				pares = append(pares, ParFragmentos{indice1: i, indice2: j, distancia: distancia, sinEvidencia: true})
+				continue
+			}
+			fragmentos = filtrarFragmentosPorLineas(fragmentos, tokens1, tokens2, minimoLineas)
+
+			resultado := resumirFragmentos(fragmentos, len(tokens1), len(tokens2))
+			pares = append(pares, ParFragmentos{indice1: i, indice2: j, distancia: distancia, resultado: resultado, huellas: huellas})
+		}
+	}
+
+	return pares
+}
+
+/*
+ * Función para imprimir los fragmentos comunes y la cobertura de cada par de archivos a una distancia máxima
+ * param: arreglo con la información del código fuente de los archivos, la comparación de los pares candidatos
+ *        y las longitudes mínimas de un fragmento (en tokens y en líneas)
+ */
+func imprimirEvidenciaFragmentos(tablaCodigoFuente []CodigoFuente, pares []ParFragmentos, minimoTokens int, minimoLineas int) {
+	fmt.Printf("\nEVIDENCIA (FRAGMENTOS COMUNES DE AL MENOS %d TOKENS Y %d LÍNEAS)\n\n", minimoTokens, minimoLineas)
+
+	for _, par := range pares {
+		codigo1, codigo2 := tablaCodigoFuente[par.indice1], tablaCodigoFuente[par.indice2]
+
+		if par.sinEvidencia {
+			fmt.Printf("%s <-> %s (distancia %.2f, evidencia omitida por el presupuesto de tiempo)\n\n", codigo1.nombre, codigo2.nombre, par.distancia)
+			continue
+		}
+
+		fmt.Printf("%s <-> %s (distancia %.2f, %d fragmentos comunes)\n", codigo1.nombre, codigo2.nombre, par.distancia, len(par.resultado.fragmentos))
+		fmt.Printf("\t%6.2f%% de %s aparece en %s\n", par.resultado.cobertura1, codigo1.nombre, codigo2.nombre)
+		fmt.Printf("\t%6.2f%% de %s aparece en %s\n", par.resultado.cobertura2, codigo2.nombre, codigo1.nombre)
+		if par.huellas > 0 {
+			fmt.Printf("\t%d huellas de winnowing compartidas\n", par.huellas)
+		}
+
+		for _, fragmento := range par.resultado.fragmentos {
+			fmt.Printf("\tlíneas %d-%d = líneas %d-%d (%d tokens)\n",
+				codigo1.tokens[fragmento.inicio1].numero, codigo1.tokens[fragmento.inicio1+fragmento.longitud-1].numero,
+				codigo2.tokens[fragmento.inicio2].numero, codigo2.tokens[fragmento.inicio2+fragmento.longitud-1].numero,
+				fragmento.longitud)
+		}
+		fmt.Println()
+	}
+}
+
+/*
+ * Función para imprimir la cobertura (en ambos sentidos) de cada par de archivos a una distancia máxima,
+ * ordenada desde el par con mayor cobertura
+ * param: arreglo con la información del código fuente de los archivos y la comparación de los pares candidatos
+ */
+func imprimirCobertura(tablaCodigoFuente []CodigoFuente, pares []ParFragmentos) {
+	fmt.Print("\nCOBERTURA (PORCENTAJE DE CADA ARCHIVO QUE APARECE TEXTUALMENTE EN EL OTRO)\n\n")
+
+	ordenados := make([]ParFragmentos, len(pares))
+	copy(ordenados, pares)
+
+	sort.SliceStable(ordenados, func(j, k int) bool {
+		return math.Max(ordenados[j].resultado.cobertura1, ordenados[j].resultado.cobertura2) >
+			math.Max(ordenados[k].resultado.cobertura1, ordenados[k].resultado.cobertura2)
+	})
+
+	fmt.Printf("\t%9s %9s  %s\n", "% A EN B", "% B EN A", "A <-> B")
+	for _, par := range ordenados {
+		if par.sinEvidencia {
+			fmt.Printf("\t%9s %9s  %s <-> %s (presupuesto de tiempo agotado)\n", "-", "-", tablaCodigoFuente[par.indice1].nombre, tablaCodigoFuente[par.indice2].nombre)
+			continue
+		}
+		fmt.Printf("\t%8.2f%% %8.2f%%  %s <-> %s\n", par.resultado.cobertura1, par.resultado.cobertura2,
+			tablaCodigoFuente[par.indice1].nombre, tablaCodigoFuente[par.indice2].nombre)
+	}
+	fmt.Println()
+}
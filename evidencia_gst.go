@@ -0,0 +1,150 @@
+/*
+ * Evidencia y similitud por Greedy String Tiling (--evidence-engine=gst, como JPlag).
+ *
+ * Los pares a la distancia máxima según el motor de características (la pasada rápida por vectores) se comparan
+ * token a token con Greedy String Tiling: en cada ronda se buscan las coincidencias más largas entre los tokens aún
+ * no marcados de ambos archivos y se marcan como "baldosas" (tiles), hasta que no queda ninguna coincidencia de al
+ * menos --min-match-tokens tokens. Cada token pertenece como máximo a una baldosa, por lo que una porción repetida
+ * del otro archivo no se cuenta dos veces. Los candidatos de cada ronda se obtienen con el hash de Rabin-Karp de las
+ * ventanas de la longitud mínima (Running Karp-Rabin Greedy String Tiling).
+ *
+ * La similitud del par es la proporción de los tokens de ambos archivos cubiertos por las baldosas:
+ *
+ *     similitud = 2 * tokens cubiertos / (tokens de A + tokens de B)
+ *
+ * Con --coverage se imprime la similitud de cada par, ordenada desde el más similar, y con --evidence las baldosas
+ * con sus rangos de líneas. La búsqueda por par usa el presupuesto de tiempo de la evidencia (ver presupuesto_evidencia.go).
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+/*
+ * Función para determinar la longitud de la coincidencia entre dos posiciones sin cruzar tokens marcados
+ * param: ambas secuencias de tokens, sus tokens marcados y las posiciones iniciales
+ * return: cantidad de tokens iguales y sin marcar consecutivos
+ */
+func longitudCoincidenciaLibre(tokens1 []Token, marcados1 []bool, inicio1 int, tokens2 []Token, marcados2 []bool, inicio2 int) int {
+	longitud := 0
+
+	for inicio1+longitud < len(tokens1) && inicio2+longitud < len(tokens2) &&
+		!marcados1[inicio1+longitud] && !marcados2[inicio2+longitud] &&
+		tokens1[inicio1+longitud].hash == tokens2[inicio2+longitud].hash {
+		longitud++
+	}
+
+	return longitud
+}
+
+/*
+ * Función para cubrir dos secuencias de tokens con baldosas (Greedy String Tiling)
+ * param: ambas secuencias de tokens, la longitud mínima de una baldosa y el límite de tiempo (cero si no hay límite)
+ * return: las baldosas (sin tokens en común) y si la búsqueda terminó antes del límite
+ */
+func buscarBaldosas(tokens1 []Token, tokens2 []Token, minimo int, limite time.Time) ([]Fragmento, bool) {
+	var baldosas []Fragmento
+
+	if minimo < 1 || len(tokens1) < minimo || len(tokens2) < minimo {
+		return baldosas, true
+	}
+
+	ventanas := make(map[uint64][]int)
+	for j, hash := range calcularHashesVentanas(tokens2, minimo) {
+		ventanas[hash] = append(ventanas[hash], j)
+	}
+	hashes1 := calcularHashesVentanas(tokens1, minimo)
+
+	marcados1, marcados2 := make([]bool, len(tokens1)), make([]bool, len(tokens2))
+	comparaciones := 0
+
+	for {
+		// Coincidencias más largas de la ronda entre los tokens sin marcar
+		var mayores []Fragmento
+		mayor := minimo
+
+		for i, hash := range hashes1 {
+			if marcados1[i] {
+				continue
+			}
+			for _, j := range ventanas[hash] {
+				if comparaciones++; comparaciones%VERIFICACION_PRESUPUESTO == 0 && superaLimite(limite) {
+					return nil, false
+				}
+
+				longitud := longitudCoincidenciaLibre(tokens1, marcados1, i, tokens2, marcados2, j)
+				if longitud > mayor {
+					mayores, mayor = mayores[:0], longitud
+				}
+				if longitud == mayor {
+					mayores = append(mayores, Fragmento{inicio1: i, inicio2: j, longitud: longitud})
+				}
+			}
+		}
+
+		if len(mayores) == 0 {
+			return baldosas, true
+		}
+
+		// Se marcan las coincidencias que no se solapan con una baldosa de la misma ronda
+		for _, coincidencia := range mayores {
+			if longitudCoincidenciaLibre(tokens1, marcados1, coincidencia.inicio1, tokens2, marcados2, coincidencia.inicio2) < coincidencia.longitud {
+				continue
+			}
+			for k := 0; k < coincidencia.longitud; k++ {
+				marcados1[coincidencia.inicio1+k], marcados2[coincidencia.inicio2+k] = true, true
+			}
+			baldosas = append(baldosas, coincidencia)
+		}
+	}
+}
+
+/*
+ * Función para calcular la similitud de un par a partir de la cobertura de ambos archivos
+ * param: comparación del par y arreglo con la información del código fuente de los archivos (con sus tokens)
+ * return: porcentaje de los tokens de ambos archivos cubiertos por las baldosas
+ */
+func calcularSimilitudBaldosas(par ParFragmentos, tablaCodigoFuente []CodigoFuente) float64 {
+	cantidad1, cantidad2 := float64(len(tablaCodigoFuente[par.indice1].tokens)), float64(len(tablaCodigoFuente[par.indice2].tokens))
+	if cantidad1+cantidad2 == 0 {
+		return 0
+	}
+
+	// Las baldosas cubren la misma cantidad de tokens en ambos archivos
+	return (par.resultado.cobertura1*cantidad1 + par.resultado.cobertura2*cantidad2) / (cantidad1 + cantidad2)
+}
+
+/*
+ * Función para imprimir la similitud por Greedy String Tiling de cada par de archivos a una distancia máxima,
+ * ordenada desde el par más similar
+ * param: arreglo con la información del código fuente de los archivos y la comparación de los pares candidatos
+ */
+func imprimirSimilitudBaldosas(tablaCodigoFuente []CodigoFuente, pares []ParFragmentos) {
+	fmt.Print("\nSIMILITUD (GREEDY STRING TILING, PORCENTAJE DE LOS TOKENS DE AMBOS ARCHIVOS CUBIERTO POR COINCIDENCIAS)\n\n")
+
+	ordenados := make([]ParFragmentos, len(pares))
+	copy(ordenados, pares)
+
+	sort.SliceStable(ordenados, func(j, k int) bool {
+		if ordenados[j].sinEvidencia != ordenados[k].sinEvidencia {
+			return !ordenados[j].sinEvidencia
+		}
+		return calcularSimilitudBaldosas(ordenados[j], tablaCodigoFuente) > calcularSimilitudBaldosas(ordenados[k], tablaCodigoFuente)
+	})
+
+	fmt.Printf("\t%9s %9s %9s  %s\n", "SIMILITUD", "% A EN B", "% B EN A", "A <-> B")
+	for _, par := range ordenados {
+		if par.sinEvidencia {
+			fmt.Printf("\t%9s %9s %9s  %s <-> %s (presupuesto de tiempo agotado)\n", "-", "-", "-", tablaCodigoFuente[par.indice1].nombre, tablaCodigoFuente[par.indice2].nombre)
+			continue
+		}
+		fmt.Printf("\t%8.2f%% %8.2f%% %8.2f%%  %s <-> %s\n", math.Round(calcularSimilitudBaldosas(par, tablaCodigoFuente)*100)/100,
+			par.resultado.cobertura1, par.resultado.cobertura2, tablaCodigoFuente[par.indice1].nombre, tablaCodigoFuente[par.indice2].nombre)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuscarBaldosas(t *testing.T) {
+	// La porción repetida del segundo archivo se cubre una sola vez: cada token pertenece a una baldosa como máximo
+	tokens1 := obtenerTokens([]byte("a b c d e f g h"))
+	tokens2 := obtenerTokens([]byte("a b c d x a b c d e f g h"))
+
+	baldosas, completo := buscarBaldosas(tokens1, tokens2, 3, time.Time{})
+	if esperadas := []Fragmento{{inicio1: 0, inicio2: 5, longitud: 8}}; !completo || !reflect.DeepEqual(baldosas, esperadas) {
+		t.Errorf("baldosas = %v (completo %v), se esperaban %v", baldosas, completo, esperadas)
+	}
+}
+
+func TestEvidenciaGST(t *testing.T) {
+	par, rangos := compararFragmentoPrueba(t, "gst", 10)
+
+	if esperados := [][4]int{{5, 11, 8, 14}}; !reflect.DeepEqual(rangos, esperados) {
+		t.Errorf("rangos de líneas = %v, se esperaban %v", rangos, esperados)
+	}
+
+	// La similitud es la proporción de los tokens de ambos archivos cubiertos por la baldosa
+	tablaCodigoFuente := []CodigoFuente{{tokens: obtenerTokens([]byte(archivosFragmentoPrueba[0]))}, {tokens: obtenerTokens([]byte(archivosFragmentoPrueba[1]))}}
+	longitud := par.resultado.fragmentos[0].longitud
+	esperada := 100.0 * 2 * float64(longitud) / float64(len(tablaCodigoFuente[0].tokens)+len(tablaCodigoFuente[1].tokens))
+	if similitud := calcularSimilitudBaldosas(par, tablaCodigoFuente); math.Abs(similitud-esperada) > 1e-9 {
+		t.Errorf("similitud = %v, se esperaba %v", similitud, esperada)
+	}
+}
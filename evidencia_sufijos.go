@@ -0,0 +1,193 @@
+/*
+ * Evidencia por fragmentos comunes usando un arreglo de sufijos.
+ *
+ * En lugar de comparar cada par de archivos, se concatenan los tokens de TODOS los archivos (separados por
+ * un símbolo único por archivo) y se construye un arreglo de sufijos con su arreglo LCP (prefijo común más
+ * largo entre sufijos vecinos). Los sufijos que comparten un prefijo de al menos la longitud mínima quedan
+ * contiguos en el arreglo, lo que permite obtener todas las coincidencias exactas maximales del corpus de una
+ * sola vez y escalar la búsqueda de fragmentos a cohortes grandes.
+ */
+
+package main
+
+import "sort"
+
+// Estructura para almacenar el corpus concatenado
+// - secuencia de símbolos (identificador de cada token, o un separador negativo único por archivo)
+// - archivo y posición (en tokens) dentro del archivo de cada símbolo
+type CorpusConcatenado struct {
+	simbolos []int
+	archivo  []int
+	posicion []int
+}
+
+/*
+ * Función para concatenar los tokens de todos los archivos en una sola secuencia de símbolos
+ * param: secuencias de tokens de cada archivo
+ * return: el corpus concatenado
+ */
+func concatenarCorpus(tokensArchivos [][]Token) CorpusConcatenado {
+	var corpus CorpusConcatenado
+
+	identificadores := make(map[uint64]int)
+
+	for k, tokens := range tokensArchivos {
+		for posicion, token := range tokens {
+			identificador, existe := identificadores[token.hash]
+			if !existe {
+				identificador = len(identificadores)
+				identificadores[token.hash] = identificador
+			}
+			corpus.simbolos = append(corpus.simbolos, identificador)
+			corpus.archivo = append(corpus.archivo, k)
+			corpus.posicion = append(corpus.posicion, posicion)
+		}
+
+		// Separador único para que ninguna coincidencia cruce el final de un archivo
+		corpus.simbolos = append(corpus.simbolos, -(k + 1))
+		corpus.archivo = append(corpus.archivo, k)
+		corpus.posicion = append(corpus.posicion, len(tokens))
+	}
+
+	return corpus
+}
+
+/*
+ * Función para construir el arreglo de sufijos por duplicación de prefijos
+ * param: secuencia de símbolos
+ * return: arreglo con las posiciones de los sufijos en orden lexicográfico
+ */
+func construirArregloSufijos(simbolos []int) []int {
+	n := len(simbolos)
+	sufijos := make([]int, n)
+	rango := make([]int, n)
+	temporal := make([]int, n)
+
+	for i := range simbolos {
+		sufijos[i] = i
+		rango[i] = simbolos[i]
+	}
+
+	for k := 1; ; k *= 2 {
+		segundo := func(i int) int {
+			if i+k < n {
+				return rango[i+k]
+			}
+			return -n - 1
+		}
+		menor := func(a, b int) bool {
+			if rango[a] != rango[b] {
+				return rango[a] < rango[b]
+			}
+			return segundo(a) < segundo(b)
+		}
+
+		sort.Slice(sufijos, func(a, b int) bool {
+			return menor(sufijos[a], sufijos[b])
+		})
+
+		temporal[sufijos[0]] = 0
+		for i := 1; i < n; i++ {
+			temporal[sufijos[i]] = temporal[sufijos[i-1]]
+			if menor(sufijos[i-1], sufijos[i]) {
+				temporal[sufijos[i]]++
+			}
+		}
+		copy(rango, temporal)
+
+		// Todos los rangos son distintos: el arreglo de sufijos está completo
+		if n == 0 || rango[sufijos[n-1]] == n-1 {
+			break
+		}
+	}
+
+	return sufijos
+}
+
+/*
+ * Función para construir el arreglo LCP (algoritmo de Kasai)
+ * param: secuencia de símbolos y su arreglo de sufijos
+ * return: arreglo en donde la posición i tiene el prefijo común más largo entre los sufijos i-1 e i
+ */
+func construirArregloLCP(simbolos []int, sufijos []int) []int {
+	n := len(simbolos)
+	lcp := make([]int, n)
+	inverso := make([]int, n)
+
+	for i, sufijo := range sufijos {
+		inverso[sufijo] = i
+	}
+
+	h := 0
+	for i := 0; i < n; i++ {
+		if inverso[i] > 0 {
+			j := sufijos[inverso[i]-1]
+			for i+h < n && j+h < n && simbolos[i+h] == simbolos[j+h] && simbolos[i+h] >= 0 {
+				h++
+			}
+			lcp[inverso[i]] = h
+			if h > 0 {
+				h--
+			}
+		} else {
+			h = 0
+		}
+	}
+
+	return lcp
+}
+
+/*
+ * Función para buscar todas las coincidencias exactas maximales de al menos la longitud mínima en el corpus.
+ * Se recorren los intervalos del arreglo de sufijos con LCP mayor o igual a la longitud mínima y se registra
+ * cada par de sufijos de archivos distintos que sea maximal a la izquierda.
+ * param: secuencias de tokens de cada archivo y la longitud mínima de un fragmento
+ * return: fragmentos comunes por cada par de archivos (índice menor, índice mayor)
+ */
+func buscarFragmentosCorpus(tokensArchivos [][]Token, minimo int) map[[2]int][]Fragmento {
+	fragmentos := make(map[[2]int][]Fragmento)
+
+	corpus := concatenarCorpus(tokensArchivos)
+	if len(corpus.simbolos) == 0 || minimo < 1 {
+		return fragmentos
+	}
+
+	sufijos := construirArregloSufijos(corpus.simbolos)
+	lcp := construirArregloLCP(corpus.simbolos, sufijos)
+
+	for inicio := 0; inicio < len(sufijos); {
+		fin := inicio + 1
+		for fin < len(sufijos) && lcp[fin] >= minimo {
+			fin++
+		}
+
+		for a := inicio; a < fin; a++ {
+			longitud := len(corpus.simbolos)
+			for b := a + 1; b < fin; b++ {
+				if lcp[b] < longitud {
+					longitud = lcp[b]
+				}
+
+				p, q := sufijos[a], sufijos[b]
+				if corpus.archivo[p] == corpus.archivo[q] {
+					continue
+				}
+
+				// Si el símbolo anterior también coincide, el fragmento se reporta desde una posición antes
+				if p > 0 && q > 0 && corpus.simbolos[p-1] >= 0 && corpus.simbolos[p-1] == corpus.simbolos[q-1] {
+					continue
+				}
+
+				if corpus.archivo[p] > corpus.archivo[q] {
+					p, q = q, p
+				}
+				par := [2]int{corpus.archivo[p], corpus.archivo[q]}
+				fragmentos[par] = append(fragmentos[par], Fragmento{inicio1: corpus.posicion[p], inicio2: corpus.posicion[q], longitud: longitud})
+			}
+		}
+
+		inicio = fin
+	}
+
+	return fragmentos
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConstruirArregloSufijos(t *testing.T) {
+	// "banana" con un separador al final
+	simbolos := []int{1, 0, 2, 0, 2, 0, -1}
+
+	sufijos := construirArregloSufijos(simbolos)
+	if esperados := []int{6, 5, 3, 1, 0, 4, 2}; !reflect.DeepEqual(sufijos, esperados) {
+		t.Errorf("arreglo de sufijos = %v, se esperaba %v", sufijos, esperados)
+	}
+	if lcp, esperado := construirArregloLCP(simbolos, sufijos), []int{0, 0, 1, 3, 0, 0, 2}; !reflect.DeepEqual(lcp, esperado) {
+		t.Errorf("arreglo LCP = %v, se esperaba %v", lcp, esperado)
+	}
+}
+
+func TestBuscarFragmentosCorpus(t *testing.T) {
+	// El fragmento común de los tres archivos se reporta una vez por par, sin cruzar el final de un archivo
+	tokensArchivos := [][]Token{
+		obtenerTokens([]byte("x a b c d")),
+		obtenerTokens([]byte("a b c d y")),
+		obtenerTokens([]byte("z z a b c d")),
+	}
+
+	fragmentos := buscarFragmentosCorpus(tokensArchivos, 3)
+	esperados := map[[2]int][]Fragmento{
+		{0, 1}: {{inicio1: 1, inicio2: 0, longitud: 4}},
+		{0, 2}: {{inicio1: 1, inicio2: 2, longitud: 4}},
+		{1, 2}: {{inicio1: 0, inicio2: 2, longitud: 4}},
+	}
+	if !reflect.DeepEqual(fragmentos, esperados) {
+		t.Errorf("fragmentos = %v, se esperaban %v", fragmentos, esperados)
+	}
+}
+
+func TestEvidenciaArregloSufijos(t *testing.T) {
+	_, rangos := compararFragmentoPrueba(t, "suffix-array", 10)
+
+	if esperados := [][4]int{{5, 11, 8, 14}}; !reflect.DeepEqual(rangos, esperados) {
+		t.Errorf("rangos de líneas = %v, se esperaban %v", rangos, esperados)
+	}
+}
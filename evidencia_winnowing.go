@@ -0,0 +1,124 @@
+/*
+ * Evidencia por huellas de winnowing (--evidence-engine=winnowing, como MOSS).
+ *
+ * De cada archivo se calcula el hash de todos sus k-gramas de tokens (k = --min-match-tokens, con el mismo hash
+ * rodante de Rabin-Karp) y se conservan como huellas únicamente los mínimos de cada ventana de w k-gramas
+ * consecutivos (w = --winnow-window). Si el mínimo de una ventana ya se había seleccionado en la ventana anterior no
+ * se repite, y entre varios mínimos iguales se selecciona el de más a la derecha (winnowing robusto). Así:
+ * - cada archivo se representa con una fracción de sus k-gramas (cerca de 2 / (w + 1))
+ * - toda coincidencia de al menos k + w - 1 tokens comparte al menos una huella, y las de menos de k tokens ninguna
+ *
+ * Cada huella compartida por un par de archivos se verifica (dos k-gramas distintos pueden tener el mismo hash) y se
+ * extiende token a token hacia ambos lados hasta obtener la región coincidente completa, que se reporta con los rangos
+ * de líneas de ambos archivos, la cobertura y la cantidad de huellas compartidas. Las huellas de cada archivo se
+ * calculan una sola vez y la búsqueda por par usa el presupuesto de tiempo de la evidencia (ver presupuesto_evidencia.go).
+ */
+
+package main
+
+import "time"
+
+// Cantidad de k-gramas consecutivos de cada ventana de winnowing
+const VENTANA_WINNOWING = 4
+
+// Estructura de una huella de winnowing
+// - hash del k-grama
+// - posición (en tokens) en la que inicia el k-grama
+type HuellaWinnowing struct {
+	hash     uint64
+	posicion int
+}
+
+/*
+ * Función para seleccionar las huellas de un archivo (el mínimo de cada ventana de hashes)
+ * param: hash de cada k-grama del archivo y cantidad de k-gramas de cada ventana
+ * return: huellas ordenadas por su posición
+ */
+func seleccionarHuellas(hashes []uint64, ventana int) []HuellaWinnowing {
+	var huellas []HuellaWinnowing
+
+	if len(hashes) == 0 {
+		return huellas
+	}
+	if len(hashes) < ventana {
+		ventana = len(hashes)
+	}
+
+	seleccionada := -1
+	for inicio := 0; inicio+ventana <= len(hashes); inicio++ {
+		// Se conserva la huella anterior si sigue en la ventana y es el mínimo, si no el mínimo de más a la derecha
+		minimo := inicio
+		for k := inicio + 1; k < inicio+ventana; k++ {
+			if hashes[k] <= hashes[minimo] {
+				minimo = k
+			}
+		}
+		if seleccionada >= inicio && hashes[seleccionada] == hashes[minimo] {
+			continue
+		}
+
+		seleccionada = minimo
+		huellas = append(huellas, HuellaWinnowing{hash: hashes[minimo], posicion: minimo})
+	}
+
+	return huellas
+}
+
+/*
+ * Función para calcular las huellas de winnowing de una secuencia de tokens
+ * param: secuencia de tokens, longitud de los k-gramas y cantidad de k-gramas de cada ventana
+ * return: huellas ordenadas por su posición (ninguna si el archivo tiene menos de k tokens)
+ */
+func calcularHuellasWinnowing(tokens []Token, longitud int, ventana int) []HuellaWinnowing {
+	return seleccionarHuellas(calcularHashesVentanas(tokens, longitud), ventana)
+}
+
+/*
+ * Función para buscar las regiones coincidentes de dos archivos a partir de sus huellas compartidas.
+ * Cada huella compartida y verificada se extiende hacia ambos lados; las huellas que caen dentro de una región ya
+ * encontrada en la misma diagonal (el mismo desplazamiento entre ambos archivos) no se vuelven a extender.
+ * param: ambas secuencias de tokens, sus huellas, la longitud de los k-gramas y el límite de tiempo (cero si no hay límite)
+ * return: las regiones coincidentes, la cantidad de huellas distintas compartidas y si la búsqueda terminó antes del límite
+ */
+func buscarFragmentosWinnowing(tokens1 []Token, tokens2 []Token, huellas1 []HuellaWinnowing, huellas2 []HuellaWinnowing, longitud int, limite time.Time) ([]Fragmento, int, bool) {
+	var fragmentos []Fragmento
+
+	posiciones2 := make(map[uint64][]int)
+	for _, huella := range huellas2 {
+		posiciones2[huella.hash] = append(posiciones2[huella.hash], huella.posicion)
+	}
+
+	compartidas := make(map[uint64]bool)
+	finDiagonal := make(map[int]int)
+	comparaciones := 0
+
+	for _, huella := range huellas1 {
+		for _, posicion2 := range posiciones2[huella.hash] {
+			if comparaciones++; comparaciones%VERIFICACION_PRESUPUESTO == 0 && superaLimite(limite) {
+				return nil, 0, false
+			}
+
+			inicio1, inicio2 := huella.posicion, posicion2
+			if fin, existe := finDiagonal[inicio2-inicio1]; existe && inicio1 < fin {
+				if inicio1+longitud <= fin {
+					compartidas[huella.hash] = true
+				}
+				continue
+			}
+			if longitudCoincidencia(tokens1, inicio1, tokens2, inicio2) < longitud {
+				continue
+			}
+			compartidas[huella.hash] = true
+
+			for inicio1 > 0 && inicio2 > 0 && tokens1[inicio1-1].hash == tokens2[inicio2-1].hash {
+				inicio1, inicio2 = inicio1-1, inicio2-1
+			}
+			fragmento := Fragmento{inicio1: inicio1, inicio2: inicio2, longitud: longitudCoincidencia(tokens1, inicio1, tokens2, inicio2)}
+
+			fragmentos = append(fragmentos, fragmento)
+			finDiagonal[inicio2-inicio1] = inicio1 + fragmento.longitud
+		}
+	}
+
+	return fragmentos, len(compartidas), true
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeleccionarHuellas(t *testing.T) {
+	casos := []struct {
+		hashes     []uint64
+		ventana    int
+		posiciones []int
+	}{
+		{nil, 4, nil},
+		{[]uint64{5, 3, 7}, 4, []int{1}},
+		// Entre mínimos iguales se selecciona el de más a la derecha y no se repite en la ventana siguiente
+		{[]uint64{9, 2, 8, 2, 7, 6, 5, 4}, 3, []int{1, 3, 6, 7}},
+	}
+
+	for _, caso := range casos {
+		var posiciones []int
+		for _, huella := range seleccionarHuellas(caso.hashes, caso.ventana) {
+			posiciones = append(posiciones, huella.posicion)
+		}
+		if !reflect.DeepEqual(posiciones, caso.posiciones) {
+			t.Errorf("seleccionarHuellas(%v, %d) = %v, se esperaba %v", caso.hashes, caso.ventana, posiciones, caso.posiciones)
+		}
+	}
+}
+
+func TestEvidenciaWinnowing(t *testing.T) {
+	par, rangos := compararFragmentoPrueba(t, "winnowing", 10)
+
+	if esperados := [][4]int{{5, 11, 8, 14}}; !reflect.DeepEqual(rangos, esperados) {
+		t.Errorf("rangos de líneas = %v, se esperaban %v", rangos, esperados)
+	}
+	if par.huellas == 0 {
+		t.Error("región común sin huellas compartidas")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Exportación de los grupos en archivos CSV y JSON.
+ *
+ * Por cada integrante de cada grupo se exporta el identificador del grupo, el archivo, su distancia al código
+ * central, si es el código central, si forma parte del par ejemplar (los dos integrantes más parecidos, por
+ * donde se sugiere iniciar la revisión) y los demás grupos a los que pertenece, de modo que las asignaciones
+ * se puedan cruzar con el listado de estudiantes en una hoja electrónica.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Estructura de un integrante de un grupo en el archivo JSON
+type IntegranteGrupoJSON struct {
+	Archivo         string   `json:"archivo"`
+	DistanciaCentro float64  `json:"distancia_centro"`
+	EsCentro        bool     `json:"es_centro"`
+	EnGrupoAnterior bool     `json:"en_grupo_anterior"`
+	OtrosGrupos     []string `json:"otros_grupos"`
+}
+
+// Estructura del par ejemplar de un grupo en el archivo JSON
+type ParEjemplarJSON struct {
+	Archivo1  string  `json:"archivo1"`
+	Archivo2  string  `json:"archivo2"`
+	Distancia float64 `json:"distancia"`
+}
+
+// Estructura de un grupo en el archivo JSON
+type GrupoJSON struct {
+	Grupo       string                `json:"grupo"`
+	Medoide     string                `json:"medoide"`
+	Diametro    float64               `json:"diametro"`
+	ParEjemplar ParEjemplarJSON       `json:"par_ejemplar"`
+	Integrantes []IntegranteGrupoJSON `json:"integrantes"`
+	Terminos    []string              `json:"terminos,omitempty"`
+}
+
+// Estructura del archivo JSON con todos los grupos
+type GruposJSON struct {
+	DistanciaMaxima   float64     `json:"distancia_maxima"`
+	CriterioDistancia string      `json:"criterio_distancia"`
+	Grupos            []GrupoJSON `json:"grupos"`
+}
+
+/*
+ * Función para guardar en un archivo CSV (separado por tabuladores, como la matriz de distancias)
+ * un registro por cada integrante de cada grupo
+ * param: arreglo con la información del código fuente de los archivos, los grupos y el nombre del archivo CSV
+ */
+func generarGruposCSV(tablaCodigoFuente []CodigoFuente, grupos []Grupo, nombreGruposCSV string) {
+	ptrArchivo, err := os.Create(nombreGruposCSV)
+
+	if err != nil {
+		panic(err)
+	}
+	defer ptrArchivo.Close()
+
+	fmt.Fprintf(ptrArchivo, "GRUPO\tINTEGRANTE\tDISTANCIA AL CENTRO\tES CENTRO\tPAR EJEMPLAR\tEN GRUPO ANTERIOR\tOTROS GRUPOS\n")
+
+	for _, grupo := range grupos {
+		for _, integrante := range grupo.integrantes {
+			fmt.Fprintf(ptrArchivo, "%s\t%s\t%.2f\t%s\t%s\t%s\t%s\n",
+				grupo.identificador,
+				tablaCodigoFuente[integrante.indice].nombre,
+				integrante.distanciaCentro,
+				textoSiNo(integrante.indice == grupo.centro),
+				textoSiNo(integrante.indice == grupo.ejemplar[0] || integrante.indice == grupo.ejemplar[1]),
+				textoSiNo(integrante.enGrupoAnterior),
+				strings.Join(obtenerOtrosGrupos(grupos, integrante.indice, grupo.identificador), ";"))
+		}
+	}
+}
+
+/*
+ * Función para construir la representación JSON de los grupos con sus integrantes
+ * param: arreglo con la información del código fuente de los archivos y los grupos
+ * return: los grupos en su representación JSON
+ */
+func construirGruposJSON(tablaCodigoFuente []CodigoFuente, grupos []Grupo) []GrupoJSON {
+	gruposJSON := []GrupoJSON{}
+
+	for _, grupo := range grupos {
+		grupoJSON := GrupoJSON{Grupo: grupo.identificador, Medoide: tablaCodigoFuente[grupo.centro].nombre, Diametro: grupo.diametro,
+			ParEjemplar: ParEjemplarJSON{Archivo1: tablaCodigoFuente[grupo.ejemplar[0]].nombre, Archivo2: tablaCodigoFuente[grupo.ejemplar[1]].nombre, Distancia: grupo.distanciaEjemplar},
+			Terminos:    grupo.terminos}
+
+		for _, integrante := range grupo.integrantes {
+			otrosGrupos := obtenerOtrosGrupos(grupos, integrante.indice, grupo.identificador)
+			if otrosGrupos == nil {
+				otrosGrupos = []string{}
+			}
+
+			grupoJSON.Integrantes = append(grupoJSON.Integrantes, IntegranteGrupoJSON{
+				Archivo:         tablaCodigoFuente[integrante.indice].nombre,
+				DistanciaCentro: integrante.distanciaCentro,
+				EsCentro:        integrante.indice == grupo.centro,
+				EnGrupoAnterior: integrante.enGrupoAnterior,
+				OtrosGrupos:     otrosGrupos,
+			})
+		}
+
+		gruposJSON = append(gruposJSON, grupoJSON)
+	}
+
+	return gruposJSON
+}
+
+/*
+ * Función para guardar en un archivo JSON los grupos con sus integrantes
+ * param: arreglo con la información del código fuente de los archivos, los grupos, el criterio de la distancia máxima
+ *        y el nombre del archivo JSON
+ */
+func generarGruposJSON(tablaCodigoFuente []CodigoFuente, grupos []Grupo, criterio CriterioDistancia, nombreGruposJSON string) {
+	gruposJSON := GruposJSON{DistanciaMaxima: criterio.limite(), CriterioDistancia: criterio.criterio, Grupos: construirGruposJSON(tablaCodigoFuente, grupos)}
+
+	contenido, err := json.MarshalIndent(gruposJSON, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	if err = os.WriteFile(nombreGruposJSON, append(contenido, '\n'), 0644); err != nil {
+		panic(err)
+	}
+}
+
+/*
+ * Función para representar un valor lógico en los reportes
+ * param: valor lógico
+ * return: "SI" o "NO"
+ */
+func textoSiNo(valor bool) string {
+	if valor {
+		return "SI"
+	}
+	return "NO"
+}
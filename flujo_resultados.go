@@ -0,0 +1,161 @@
+/*
+ * Flujo de resultados durante el cálculo de las distancias (--stream).
+ *
+ * Con corpus grandes la fase 2 tarda y los exportadores (--csv, --output) solo reciben el resultado al final. Con
+ * --stream=ruta las filas de la matriz y los pares a la distancia máxima se escriben en un archivo JSON por líneas
+ * (NDJSON) a medida que se calculan, de modo que otra herramienta (tail -f, un tablero) puede procesar resultados
+ * parciales desde el inicio:
+ *
+ *     {"tipo":"inicio","archivos":120,"motor":"ascii (...)","distancia_maxima":30}
+ *     {"tipo":"fila","indice":7,"archivo":"./E8/a.go","distancias":[12.4, ...]}     distancias a los archivos 0..indice-1 (la fila 0 no tiene)
+ *     {"tipo":"par","archivo1":"./E8/a.go","archivo2":"./E3/c.go","distancia":3.16}
+ *     {"tipo":"fin","filas":120,"pares":15}
+ *
+ * El cálculo envía cada fila completa por un canal de capacidad acotada y un escritor en segundo plano la convierte
+ * a JSON, por lo que el flujo solo retiene unas pocas filas a la vez (la matriz de los reportes se conserva como antes).
+ * Los pares se escriben solo con una distancia máxima numérica: el criterio automático requiere todas las distancias.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Cantidad de filas pendientes de escribir en el flujo (si el escritor se atrasa, el cálculo espera)
+const CAPACIDAD_FLUJO = 64
+
+// Estructura de una línea del flujo de resultados (los campos vacíos se omiten según el tipo)
+type LineaFlujo struct {
+	Tipo            string         `json:"tipo"`
+	Archivos        int            `json:"archivos,omitempty"`
+	Motor           string         `json:"motor,omitempty"`
+	DistanciaMaxima *float64       `json:"distancia_maxima,omitempty"`
+	Indice          *int           `json:"indice,omitempty"`
+	Archivo         string         `json:"archivo,omitempty"`
+	Distancias      DistanciasJSON `json:"distancias,omitempty"`
+	Archivo1        string         `json:"archivo1,omitempty"`
+	Archivo2        string         `json:"archivo2,omitempty"`
+	Distancia       *float64       `json:"distancia,omitempty"`
+	Filas           *int           `json:"filas,omitempty"`
+	Pares           *int           `json:"pares,omitempty"`
+}
+
+// Estructura de una fila de la matriz enviada al escritor del flujo
+// - índice y nombre del archivo
+// - distancias a los archivos anteriores (en la escala original) y nombres de los archivos anteriores a la distancia máxima
+type FilaFlujo struct {
+	indice     int
+	archivo    string
+	distancias []float64
+	cercanos   []string
+}
+
+// Estructura del flujo de resultados
+// - canal de las filas calculadas y canal por el que el escritor informa que terminó (con el error, si lo hubo)
+// - distancia máxima de los pares (infinita si no se escriben pares)
+type FlujoResultados struct {
+	filas           chan FilaFlujo
+	terminado       chan error
+	distanciaMaxima float64
+}
+
+/*
+ * Función para iniciar el flujo de resultados y su escritor en segundo plano
+ * param: ruta del archivo del flujo, cantidad de archivos, nombre del motor y criterio de la distancia máxima
+ * return: el flujo o un error si no se puede crear el archivo
+ */
+func iniciarFlujoResultados(ruta string, cantidad int, motor string, criterio CriterioDistancia) (*FlujoResultados, error) {
+	archivo, err := os.Create(ruta)
+	if err != nil {
+		return nil, fmt.Errorf("No se puede crear el flujo de resultados (--stream): %v", err)
+	}
+
+	flujo := &FlujoResultados{filas: make(chan FilaFlujo, CAPACIDAD_FLUJO), terminado: make(chan error, 1), distanciaMaxima: math.Inf(1)}
+
+	inicio := LineaFlujo{Tipo: "inicio", Archivos: cantidad, Motor: motor}
+	if criterio.criterio == CRITERIO_VALOR {
+		flujo.distanciaMaxima = criterio.limite()
+		inicio.DistanciaMaxima = &flujo.distanciaMaxima
+	}
+
+	go func() {
+		salida := bufio.NewWriter(archivo)
+		codificador := json.NewEncoder(salida)
+
+		err := codificador.Encode(inicio)
+		filas, pares := 0, 0
+
+		for fila := range flujo.filas {
+			if err != nil {
+				continue // Se descartan las filas para no bloquear el cálculo
+			}
+
+			indice := fila.indice
+			err = codificador.Encode(LineaFlujo{Tipo: "fila", Indice: &indice, Archivo: fila.archivo, Distancias: fila.distancias})
+			filas++
+
+			for j, cercano := range fila.cercanos {
+				if err == nil && cercano != "" {
+					distancia := fila.distancias[j]
+					err = codificador.Encode(LineaFlujo{Tipo: "par", Archivo1: fila.archivo, Archivo2: cercano, Distancia: &distancia})
+					pares++
+				}
+			}
+
+			// Cada fila se entrega de inmediato a quien lee el archivo
+			if err == nil {
+				err = salida.Flush()
+			}
+		}
+
+		if err == nil {
+			err = codificador.Encode(LineaFlujo{Tipo: "fin", Filas: &filas, Pares: &pares})
+		}
+		if err == nil {
+			err = salida.Flush()
+		}
+		if errCerrar := archivo.Close(); err == nil {
+			err = errCerrar
+		}
+
+		flujo.terminado <- err
+	}()
+
+	return flujo, nil
+}
+
+/*
+ * Función para enviar al flujo una fila completa de la matriz (las distancias a los archivos anteriores)
+ * param: arreglo con la información de todos los archivos e índice de la fila
+ */
+func (flujo *FlujoResultados) enviarFila(tablaCodigoFuente []CodigoFuente, indice int) {
+	fila := FilaFlujo{indice: indice, archivo: tablaCodigoFuente[indice].nombre, distancias: make([]float64, indice), cercanos: make([]string, indice)}
+
+	for j := 0; j < indice; j++ {
+		fila.distancias[j] = obtenerDistancia(tablaCodigoFuente[indice], j)
+		if fila.distancias[j] <= flujo.distanciaMaxima && esDistanciaCalculada(fila.distancias[j]) {
+			fila.cercanos[j] = tablaCodigoFuente[j].nombre
+		}
+	}
+
+	flujo.filas <- fila
+}
+
+/*
+ * Función para terminar el flujo de resultados, esperando a que el escritor escriba las filas pendientes
+ * return: un error si no se pudo escribir el flujo
+ */
+func (flujo *FlujoResultados) terminar() error {
+	close(flujo.filas)
+
+	if err := <-flujo.terminado; err != nil {
+		return fmt.Errorf("No se puede escribir el flujo de resultados (--stream): %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+/*
+ * Evidencia en formato de editor (--evidence-format=editor).
+ *
+ * Cada coincidencia de la evidencia se imprime en una línea "ruta:línea:columna: mensaje", el mismo formato de
+ * los compiladores y de "grep -n", de modo que al hacer clic en la ubicación en la terminal (o en VS Code) el editor
+ * abre el archivo en el código coincidente, y las herramientas que leen ese formato (por ejemplo la lista quickfix
+ * de Vim) pueden recorrer los hallazgos. Cada coincidencia se imprime desde ambos archivos del par.
+ *
+ * Las rutas son relativas al directorio desde el que se ejecutó SASC (también cuando el análisis se realiza sobre
+ * las entregas clonadas o descargadas en otro directorio), y las columnas se cuentan en caracteres desde 1.
+ */
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"unicode"
+)
+
+// Formatos de la evidencia
+const (
+	FORMATO_EVIDENCIA_TEXTO  = "text"
+	FORMATO_EVIDENCIA_EDITOR = "editor"
+)
+
+/*
+ * Función para obtener la ruta de un archivo tal como la abre un editor
+ * param: directorio desde el que se ejecutó SASC, directorio del análisis y ruta del archivo relativa a este
+ * return: ruta relativa al directorio de ejecución (o absoluta si no se puede expresar de forma relativa)
+ */
+func obtenerRutaEditor(directorioInicial string, directorioAnalisis string, ruta string) string {
+	absoluta := filepath.Join(directorioAnalisis, ruta)
+
+	relativa, err := filepath.Rel(directorioInicial, absoluta)
+	if err != nil {
+		return absoluta
+	}
+
+	return relativa
+}
+
+/*
+ * Función para formatear una ubicación en el formato de los editores
+ * param: ruta del archivo, número de línea y columna
+ * return: ubicación "ruta:línea:columna"
+ */
+func formatearUbicacion(ruta string, linea int, columna int) string {
+	return fmt.Sprintf("%s:%d:%d", ruta, linea, columna)
+}
+
+/*
+ * Función para obtener la columna del primer carácter de una línea que no es un espacio
+ * param: texto de la línea
+ * return: columna en caracteres (desde 1)
+ */
+func obtenerColumnaLinea(linea string) int {
+	columna := 1
+
+	for _, caracter := range linea {
+		if !unicode.IsSpace(caracter) {
+			break
+		}
+		columna++
+	}
+
+	return columna
+}
+
+/*
+ * Función para imprimir los fragmentos comunes de cada par de archivos a una distancia máxima en formato de editor
+ * param: arreglo con la información del código fuente de los archivos, la comparación de los pares candidatos,
+ *        directorio desde el que se ejecutó SASC y directorio del análisis
+ */
+func imprimirEvidenciaFragmentosEditor(tablaCodigoFuente []CodigoFuente, pares []ParFragmentos, directorioInicial string, directorioAnalisis string) {
+	fmt.Print("\nEVIDENCIA (RUTA:LÍNEA:COLUMNA)\n\n")
+
+	for _, par := range pares {
+		codigo1, codigo2 := tablaCodigoFuente[par.indice1], tablaCodigoFuente[par.indice2]
+		ruta1 := obtenerRutaEditor(directorioInicial, directorioAnalisis, codigo1.ruta)
+		ruta2 := obtenerRutaEditor(directorioInicial, directorioAnalisis, codigo2.ruta)
+
+		if par.sinEvidencia {
+			fmt.Printf("%s: evidencia omitida con %s por el presupuesto de tiempo (distancia %.2f)\n", formatearUbicacion(ruta1, 1, 1), ruta2, par.distancia)
+			continue
+		}
+
+		for _, fragmento := range par.resultado.fragmentos {
+			inicio1, fin1 := codigo1.tokens[fragmento.inicio1], codigo1.tokens[fragmento.inicio1+fragmento.longitud-1]
+			inicio2, fin2 := codigo2.tokens[fragmento.inicio2], codigo2.tokens[fragmento.inicio2+fragmento.longitud-1]
+
+			fmt.Printf("%s: líneas %d-%d coinciden con %s (%d tokens, distancia %.2f)\n",
+				formatearUbicacion(ruta1, inicio1.numero, inicio1.columna), inicio1.numero, fin1.numero,
+				formatearUbicacion(ruta2, inicio2.numero, inicio2.columna), fragmento.longitud, par.distancia)
+			fmt.Printf("%s: líneas %d-%d coinciden con %s (%d tokens, distancia %.2f)\n",
+				formatearUbicacion(ruta2, inicio2.numero, inicio2.columna), inicio2.numero, fin2.numero,
+				formatearUbicacion(ruta1, inicio1.numero, inicio1.columna), fragmento.longitud, par.distancia)
+		}
+	}
+	fmt.Println()
+}
+
+/*
+ * Función para imprimir los bloques de líneas coincidentes de cada par de archivos a una distancia máxima
+ * en formato de editor (una ubicación por bloque, en la primera línea del bloque)
+ * param: arreglo con la información del código fuente de los archivos, la distancia mínima, la cantidad mínima
+ *        de líneas consecutivas de un bloque, directorio desde el que se ejecutó SASC y directorio del análisis
+ */
+func imprimirEvidenciaLineasEditor(tablaCodigoFuente []CodigoFuente, distanciaMinima float64, minimoLineas int, directorioInicial string, directorioAnalisis string) {
+	fmt.Print("\nEVIDENCIA (RUTA:LÍNEA:COLUMNA)\n\n")
+
+	for i := range tablaCodigoFuente {
+		if tablaCodigoFuente[i].lineas == nil {
+			tablaCodigoFuente[i].lineas = obtenerLineasNormalizadas(tablaCodigoFuente[i].contenido)
+		}
+	}
+
+	textos := make(map[int][]string)
+
+	for i := 0; i < len(tablaCodigoFuente); i++ {
+		for j := i + 1; j < len(tablaCodigoFuente); j++ {
+			distancia := obtenerDistancia(tablaCodigoFuente[i], j)
+
+			if distancia > distanciaMinima {
+				continue
+			}
+
+			bloques := agruparBloquesLineas(obtenerLineasCoincidentes(tablaCodigoFuente[i].lineas, tablaCodigoFuente[j].lineas), minimoLineas)
+			if len(bloques) == 0 {
+				continue
+			}
+
+			for _, k := range []int{i, j} {
+				if _, existe := textos[k]; !existe {
+					textos[k] = leerLineas(tablaCodigoFuente[k])
+				}
+			}
+
+			ruta1 := obtenerRutaEditor(directorioInicial, directorioAnalisis, tablaCodigoFuente[i].ruta)
+			ruta2 := obtenerRutaEditor(directorioInicial, directorioAnalisis, tablaCodigoFuente[j].ruta)
+
+			for _, bloque := range bloques {
+				inicio, fin := bloque[0], bloque[len(bloque)-1]
+				ubicacion1 := formatearUbicacion(ruta1, inicio.numero1, obtenerColumnaLinea(textos[i][inicio.numero1-1]))
+				ubicacion2 := formatearUbicacion(ruta2, inicio.numero2, obtenerColumnaLinea(textos[j][inicio.numero2-1]))
+
+				fmt.Printf("%s: líneas %d-%d coinciden con %s (%d líneas coincidentes, distancia %.2f)\n",
+					ubicacion1, inicio.numero1, fin.numero1, ubicacion2, len(bloque), distancia)
+				fmt.Printf("%s: líneas %d-%d coinciden con %s (%d líneas coincidentes, distancia %.2f)\n",
+					ubicacion2, inicio.numero2, fin.numero2, ubicacion1, len(bloque), distancia)
+			}
+		}
+	}
+	fmt.Println()
+}
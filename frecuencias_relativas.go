@@ -0,0 +1,122 @@
+/*
+ * Frecuencias relativas a la longitud del archivo (--normalize=relative).
+ *
+ * Las frecuencias absolutas crecen con la longitud del archivo: una copia a la que se agregan comentarios, líneas en
+ * blanco o código muerto se aleja de su origen aunque el resto del contenido sea idéntico. Con --normalize=relative
+ * cada frecuencia se divide entre la longitud del archivo antes de calcular las distancias, de modo que lo que se
+ * compara es la composición del archivo y no su tamaño:
+ * - motor "ascii": cada carácter entre la cantidad de caracteres del archivo
+ * - motor "tokens": cada n-grama entre la cantidad de n-gramas del archivo (también con --hash-dims, --reduce y --spec)
+ * - motor "ast": cada tipo de nodo y subárbol entre la cantidad de nodos del árbol
+ * Las frecuencias relativas se expresan por cada ESCALA_FRECUENCIA_RELATIVA elementos (en porcentaje), porque los
+ * reportes imprimen las distancias con dos decimales y las proporciones entre 0 y 1 darían distancias de 0.00.
+ *
+ * Los vectores densos relativos se almacenan en la proyección del archivo (el vector de reales con el que calculan
+ * las distancias los motores) y las frecuencias dispersas se dividen en su lugar. La escala de las distancias cambia,
+ * por lo que la distancia máxima debe ajustarse. El nombre del motor indica las frecuencias relativas, por lo que
+ * también hacen parte de la huella de la caché.
+ */
+
+package main
+
+import "fmt"
+
+// Modos de normalización de las frecuencias
+const (
+	NORMALIZACION_NINGUNA  = "none"
+	NORMALIZACION_RELATIVA = "relative"
+)
+
+// Cantidad de elementos por la que se expresan las frecuencias relativas (porcentaje)
+const ESCALA_FRECUENCIA_RELATIVA = 100.0
+
+/*
+ * Función para determinar si se usan las frecuencias relativas indicadas por el usuario
+ * param: parámetros de ejecución
+ * return: si las frecuencias son relativas o un error si el modo no existe o el motor no tiene frecuencias
+ */
+func obtenerFrecuenciasRelativas(parametros Parametros) (bool, error) {
+	switch parametros.normalizacion {
+	case NORMALIZACION_NINGUNA:
+		return false, nil
+	case NORMALIZACION_RELATIVA:
+		if parametros.motor == "lines" || parametros.motor == "ncd" || parametros.motor == "simhash" {
+			return false, fmt.Errorf("La normalización de las frecuencias (--normalize) solo se aplica a los motores \"ascii\", \"tokens\" y \"ast\"")
+		}
+		return true, nil
+	}
+
+	return false, fmt.Errorf("Normalización \"%s\" no soportada (%s | %s)", parametros.normalizacion, NORMALIZACION_NINGUNA, NORMALIZACION_RELATIVA)
+}
+
+/*
+ * Función para describir las frecuencias en el nombre de un motor
+ * param: si las frecuencias son relativas
+ * return: "frecuencia" o "frecuencia relativa"
+ */
+func describirFrecuencia(relativa bool) string {
+	if relativa {
+		return "frecuencia relativa"
+	}
+
+	return "frecuencia"
+}
+
+/*
+ * Función para calcular las frecuencias relativas de un vector de frecuencias
+ * param: vector de frecuencias y cantidad de elementos del archivo
+ * return: vector de frecuencias por cada ESCALA_FRECUENCIA_RELATIVA elementos (ceros si el archivo está vacío)
+ */
+func calcularFrecuenciasRelativas(frecuencias []int, total int) []float64 {
+	relativas := make([]float64, len(frecuencias))
+	if total == 0 {
+		return relativas
+	}
+
+	for i, frecuencia := range frecuencias {
+		relativas[i] = ESCALA_FRECUENCIA_RELATIVA * float64(frecuencia) / float64(total)
+	}
+
+	return relativas
+}
+
+/*
+ * Función para convertir en relativas las frecuencias dispersas de un archivo
+ * param: frecuencias dispersas (se modifican) y cantidad de elementos del archivo
+ */
+func normalizarFrecuenciasDispersas(frecuencias map[uint64]float64, total int) {
+	if total == 0 {
+		return
+	}
+
+	for clave := range frecuencias {
+		frecuencias[clave] *= ESCALA_FRECUENCIA_RELATIVA / float64(total)
+	}
+}
+
+/*
+ * Función para sumar las frecuencias de un vector (la cantidad de caracteres del archivo en el motor "ascii")
+ * param: vector de frecuencias
+ * return: suma de las frecuencias
+ */
+func sumarFrecuencias(frecuencias []int) int {
+	suma := 0
+	for _, frecuencia := range frecuencias {
+		suma += frecuencia
+	}
+
+	return suma
+}
+
+/*
+ * Función para contar los n-gramas de una secuencia de tokens (la longitud del archivo en el motor "tokens")
+ * param: secuencia de tokens y cantidad de tokens de cada n-grama
+ * return: cantidad de n-gramas (0 si hay menos tokens que la longitud de un n-grama)
+ */
+func contarNgramas(tokens []Token, longitud int) int {
+	if len(tokens) < longitud {
+		return 0
+	}
+
+	return len(tokens) - longitud + 1
+}
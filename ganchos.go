@@ -0,0 +1,99 @@
+/*
+ * Ganchos posteriores al análisis (--hook, se puede repetir).
+ *
+ * Cada gancho es un comando que se ejecuta al terminar el análisis con la ruta de un archivo temporal con el
+ * resultado JSON como último argumento, por ejemplo para avisar a los docentes o publicar el resultado:
+ *
+ *     ./SASC --hook="python3 notificar.py --curso prog1" java 40
+ *
+ * El comando se ejecuta sin intérprete de comandos (sus argumentos se separan por espacios). Su salida, si
+ * terminó correctamente o falló y su duración quedan en el registro de la ejecución. Si un gancho falla, el programa
+ * termina con un código de salida distinto de 0 después de generar los demás reportes.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Tiempo máximo de ejecución de un gancho
+const TIEMPO_MAXIMO_GANCHO = 5 * time.Minute
+
+/*
+ * Función para ejecutar un gancho con la ruta del resultado JSON como último argumento
+ * param: comando del gancho y ruta del resultado JSON
+ * return: salida del comando (estándar y de errores) y un error si el comando falla
+ */
+func ejecutarGancho(gancho string, rutaResultado string) ([]byte, error) {
+	argumentos := strings.Fields(gancho)
+	if len(argumentos) == 0 {
+		return nil, fmt.Errorf("gancho vacío")
+	}
+
+	contexto, cancelar := context.WithTimeout(context.Background(), TIEMPO_MAXIMO_GANCHO)
+	defer cancelar()
+
+	salida, err := exec.CommandContext(contexto, argumentos[0], append(argumentos[1:], rutaResultado)...).CombinedOutput()
+	if contexto.Err() == context.DeadlineExceeded {
+		return salida, fmt.Errorf("superó el tiempo máximo de %v", TIEMPO_MAXIMO_GANCHO)
+	}
+
+	return salida, err
+}
+
+/*
+ * Función para ejecutar los ganchos con el resultado del análisis y registrar su ejecución
+ * param: resultado del análisis, comandos de los ganchos y el registro (salida estándar o de errores)
+ * return: cantidad de ganchos que fallaron
+ */
+func ejecutarGanchos(resultado ResultadoAnalisisJSON, ganchos []string, registro io.Writer) int {
+	fallidos := 0
+
+	if len(ganchos) == 0 {
+		return fallidos
+	}
+
+	archivo, err := ioutil.TempFile("", "sasc-resultado-*.json")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(archivo.Name())
+
+	codificador := json.NewEncoder(archivo)
+	codificador.SetIndent("", "  ")
+	err = codificador.Encode(resultado)
+	archivo.Close()
+	if err != nil {
+		panic(err)
+	}
+
+	for _, gancho := range ganchos {
+		inicio := time.Now()
+		salida, err := ejecutarGancho(gancho, archivo.Name())
+		duracion := time.Since(inicio).Round(time.Millisecond)
+
+		if err != nil {
+			fmt.Fprintf(registro, "             gancho \"%s\": falló (%v) en %v\n", gancho, err, duracion)
+			fallidos++
+		} else {
+			fmt.Fprintf(registro, "             gancho \"%s\": terminó correctamente en %v\n", gancho, duracion)
+		}
+
+		for _, linea := range strings.Split(strings.TrimRight(string(salida), "\n"), "\n") {
+			if linea != "" {
+				fmt.Fprintln(registro, "\t"+linea)
+			}
+		}
+	}
+
+	return fallidos
+}
@@ -0,0 +1,3 @@
+module github.com/jugutier73/SASC
+
+go 1.18
@@ -0,0 +1,206 @@
+/*
+ * Idiomas de los reportes HTML (español e inglés).
+ *
+ * Los reportes HTML (--bundle, --review-html y el tablero del comando dashboard) incluyen los textos en ambos
+ * idiomas y un selector ES | EN, de modo que un mismo archivo lo pueden leer docentes y monitores que prefieren
+ * idiomas distintos. El HTML se genera en español (se lee igual sin JavaScript) y cada texto fijo lleva su clave
+ * (data-i18n); al elegir un idioma, un script incrustado reemplaza los textos (y las etiquetas ARIA de la variante
+ * accesible) y recuerda la elección en el navegador. Los datos (nombres de archivos, distancias, grupos) no se traducen.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+)
+
+// Estructura de un texto fijo de los reportes en ambos idiomas
+type TextoReporte struct {
+	clave   string
+	espanol string
+	ingles  string
+}
+
+// Textos fijos de los reportes HTML
+var textosReporte = []TextoReporte{
+	{"titulo_reporte", "SASC - Reporte de similaridad", "SASC - Similarity report"},
+	{"titulo_par", "SASC - Par ", "SASC - Pair "},
+	{"titulo_cola", "SASC - Cola de revisión", "SASC - Review queue"},
+	{"reporte", "Reporte de similaridad de código", "Code similarity report"},
+	{"archivos_extension", "archivos de extensión", "files with extension"},
+	{"analizados_motor", "analizados con el motor", "analyzed with the engine"},
+	{"distancia_maxima", "Distancia máxima", "Maximum distance"},
+	{"definida_usuario", "definida por el usuario", "user-defined"},
+	{"automatica", "automática", "automatic"},
+	{"de_las_distancias", "de las distancias", "of the distances"},
+	{"mediana", "mediana", "median"},
+	{"sin_distancia", "sin distancia máxima", "no maximum distance"},
+	{"descartados", "archivos descartados por no tener contenido:", "files discarded for having no content:"},
+	{"grupos", "Grupos", "Groups"},
+	{"grupo", "Grupo", "Group"},
+	{"diametro", "diámetro", "diameter"},
+	{"pares_distancia", "Pares a la distancia máxima", "Pairs within the maximum distance"},
+	{"pares_cercanos", "Pares más cercanos", "Closest pairs"},
+	{"archivo_1", "Archivo 1", "File 1"},
+	{"archivo_2", "Archivo 2", "File 2"},
+	{"distancia", "Distancia", "Distance"},
+	{"distancia_par", "distancia", "distance"},
+	{"severidad", "Severidad", "Severity"},
+	{"percentil", "Percentil", "Percentile"},
+	{"resultado_json", "Resultado completo en JSON", "Full result in JSON"},
+	{"volver", "Volver al reporte", "Back to the report"},
+	{"par", "Par", "Pair"},
+	{"bloques", "bloques de líneas coincidentes", "blocks of matching lines"},
+	{"lineas", "líneas", "lines"},
+	{"cola", "Cola de revisión", "Review queue"},
+	{"pares_cola", "pares, del más sospechoso al menos sospechoso (uno por grupo).", "pairs, from the most to the least suspicious (one per group)."},
+	{"revisado", "Revisado", "Reviewed"},
+	{"monitor", "Monitor", "TA"},
+	{"observaciones", "Observaciones", "Notes"},
+	{"idioma", "Idioma", "Language"},
+	{"codigo_de", "Código de ", "Code of "},
+	{"linea_coincidente", "línea coincidente:", "matching line:"},
+	{"revisado_par", "Revisado: par ", "Reviewed: pair "},
+	{"observaciones_par", "Observaciones del par ", "Notes for pair "},
+	{"titulo_archivo", "SASC - Archivo ", "SASC - File "},
+	{"archivos", "Archivos", "Files"},
+	{"archivo", "Archivo", "File"},
+	{"vecino_cercano", "Vecino más cercano", "Nearest neighbor"},
+	{"vecinos", "Vecinos más cercanos", "Nearest neighbors"},
+	{"distribucion", "Distancias frente al corpus", "Distances versus the corpus"},
+	{"descripcion_minigrafico", "Distancias del archivo a los demás (barras oscuras) frente a las de todos los pares del corpus (barras grises); la línea roja es la distancia máxima.",
+		"Distances from the file to the others (dark bars) versus those of all the pairs in the corpus (gray bars); the red line is the maximum distance."},
+	{"percentil_vecino", "Percentil del vecino más cercano", "Percentile of the nearest neighbor"},
+	{"contribucion", "Contribución de las características", "Feature contribution"},
+	{"descripcion_contribucion", "Dimensiones de los vectores de características que más aportan a lo que ambos archivos comparten y a su distancia.",
+		"Dimensions of the feature vectors that contribute the most to what both files share and to their distance."},
+	{"por_categoria", "Aporte por categoría", "Contribution by category"},
+	{"mas_comparten", "Dimensiones que más comparten", "Most shared dimensions"},
+	{"mas_diferencian", "Dimensiones que más los diferencian", "Most differing dimensions"},
+	{"dimension", "Dimensión", "Dimension"},
+	{"categoria", "Categoría", "Category"},
+	{"aporte", "Aporte", "Contribution"},
+	{"aporte_comun", "Aporte a lo común", "Contribution to what is shared"},
+	{"aporte_diferencia", "Aporte a la distancia", "Contribution to the distance"},
+	{"categoria_letras", "letras (identificadores, comentarios y cadenas)", "letters (identifiers, comments and strings)"},
+	{"categoria_digitos", "dígitos", "digits"},
+	{"categoria_espacios", "espacios", "whitespace"},
+	{"categoria_simbolos", "símbolos (estructura)", "symbols (structure)"},
+	{"categoria_no_ascii", "no ASCII", "non-ASCII"},
+	{"categoria_palabras", "palabras", "words"},
+	{"categoria_nodos", "tipos de nodos", "node types"},
+	{"categoria_subarboles", "subárboles", "subtrees"},
+	{"titulo_tablero", "SASC - Tablero de la tarea", "SASC - Assignment dashboard"},
+	{"tablero", "Tablero de la tarea", "Assignment dashboard"},
+	{"secciones", "secciones", "sections"},
+	{"archivos_tablero", "archivos", "files"},
+	{"estadisticas", "Estadísticas generales", "Overall statistics"},
+	{"pares_marcados", "Pares a la distancia máxima", "Pairs within the maximum distance"},
+	{"entre_secciones", "entre secciones", "across sections"},
+	{"archivos_marcados", "Archivos con al menos un par a la distancia máxima", "Files with at least one pair within the maximum distance"},
+	{"menor_cruzada", "Menor distancia entre secciones", "Smallest distance across sections"},
+	{"por_seccion", "Por sección", "By section"},
+	{"seccion", "Sección", "Section"},
+	{"trabajo", "Trabajo", "Job"},
+	{"fecha", "Fecha", "Date"},
+	{"marcados", "Marcados", "Flagged"},
+	{"tasa_marcados", "Tasa de marcados", "Flag rate"},
+	{"pares_internos", "Pares en la sección", "Pairs within the section"},
+	{"pares_cruzados", "Pares con otras secciones", "Pairs with other sections"},
+	{"secciones_omitidas", "Secciones omitidas (sus entregas se eliminaron por la retención):", "Omitted sections (their submissions were removed by retention):"},
+	{"pares_entre_secciones", "Pares entre secciones", "Pairs across sections"},
+}
+
+/*
+ * Función para obtener un texto fijo del reporte con su clave de traducción
+ * param: clave del texto
+ * return: el texto en español dentro de un elemento con la clave (el script lo reemplaza al cambiar de idioma)
+ */
+func traducirReporte(clave string) string {
+	for _, texto := range textosReporte {
+		if texto.clave == clave {
+			return fmt.Sprintf("<span data-i18n=\"%s\">%s</span>", clave, html.EscapeString(texto.espanol))
+		}
+	}
+
+	panic("Texto del reporte sin traducción: " + clave)
+}
+
+/*
+ * Función para obtener el título de una página con su clave de traducción
+ * param: clave del título y sufijo que no se traduce (por ejemplo el número del par)
+ * return: el elemento title de la página
+ */
+func titularReporte(clave string, sufijo string) string {
+	for _, texto := range textosReporte {
+		if texto.clave == clave {
+			return fmt.Sprintf("<title data-i18n=\"%s\" data-sufijo=\"%s\">%s</title>", clave, html.EscapeString(sufijo), html.EscapeString(texto.espanol+sufijo))
+		}
+	}
+
+	panic("Texto del reporte sin traducción: " + clave)
+}
+
+/*
+ * Función para describir el criterio de la distancia máxima en un reporte HTML
+ * param: criterio de la distancia máxima
+ * return: descripción con sus textos traducibles
+ */
+func describirCriterioReporte(criterio CriterioDistancia) string {
+	switch {
+	case criterio.criterio == CRITERIO_VALOR:
+		return strconv.FormatFloat(criterio.valor, 'g', -1, 64) + " (" + traducirReporte("definida_usuario") + ")"
+	case criterio.criterio == CRITERIO_AUTOMATICO:
+		return fmt.Sprintf("%.2f (%s, %s %.2f - %.0f * MAD %.2f %s)", criterio.limite(), traducirReporte("automatica"), traducirReporte("mediana"), criterio.mediana, FACTOR_CERCA_AUTOMATICA, criterio.mad, traducirReporte("de_las_distancias"))
+	}
+
+	return traducirReporte("sin_distancia")
+}
+
+/*
+ * Función para obtener el selector de idioma de los reportes HTML
+ * param: si el reporte es accesible
+ * return: los botones ES | EN (van al inicio del cuerpo de la página)
+ */
+func generarSelectorIdioma(accesible bool) string {
+	if accesible {
+		return "<nav" + etiquetarReporte(accesible, "idioma", "") + "><button type=\"button\" lang=\"es\" aria-label=\"Español\" onclick=\"sascIdioma('es')\">ES</button> " +
+			"<button type=\"button\" lang=\"en\" aria-label=\"English\" onclick=\"sascIdioma('en')\">EN</button></nav>\n"
+	}
+
+	return "<nav><button type=\"button\" onclick=\"sascIdioma('es')\">ES</button> <button type=\"button\" onclick=\"sascIdioma('en')\">EN</button></nav>\n"
+}
+
+/*
+ * Función para obtener el script de los idiomas de los reportes HTML, con los textos de ambos idiomas incrustados
+ * return: el script (va al final del cuerpo de la página)
+ */
+func generarScriptIdiomas() string {
+	textos := map[string]map[string]string{"es": {}, "en": {}}
+	for _, texto := range textosReporte {
+		textos["es"][texto.clave], textos["en"][texto.clave] = texto.espanol, texto.ingles
+	}
+
+	contenido, err := json.Marshal(textos)
+	if err != nil {
+		panic(err)
+	}
+
+	return "<script>\nvar sascTextos = " + string(contenido) + ";\n" +
+		"function sascIdioma(idioma) {\n" +
+		"  if (!sascTextos[idioma]) { idioma = \"es\"; }\n" +
+		"  document.documentElement.lang = idioma;\n" +
+		"  document.querySelectorAll(\"[data-i18n]\").forEach(function (elemento) {\n" +
+		"    elemento.textContent = sascTextos[idioma][elemento.dataset.i18n] + (elemento.dataset.sufijo || \"\");\n" +
+		"  });\n" +
+		"  document.querySelectorAll(\"[data-i18n-etiqueta]\").forEach(function (elemento) {\n" +
+		"    elemento.setAttribute(\"aria-label\", sascTextos[idioma][elemento.dataset.i18nEtiqueta] + (elemento.dataset.sufijo || \"\"));\n" +
+		"  });\n" +
+		"  try { localStorage.setItem(\"sasc-idioma\", idioma); } catch (error) {}\n" +
+		"}\n" +
+		"try { sascIdioma(localStorage.getItem(\"sasc-idioma\") || \"es\"); } catch (error) {}\n" +
+		"</script>\n"
+}
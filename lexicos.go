@@ -0,0 +1,335 @@
+/*
+ * Analizadores léxicos por lenguaje del motor "tokens" (--lexer).
+ *
+ * El tokenizador genérico (ver evidencia_fragmentos.go) separa las palabras y los símbolos sin conocer el lenguaje,
+ * por lo que los n-gramas cambian cuando se renombra una variable, y los comentarios y el texto de las cadenas
+ * cuentan como código. Con --lexer el motor "tokens" usa el analizador léxico del lenguaje, que convierte el código
+ * en una secuencia de tokens normalizados:
+ * - palabras reservadas del lenguaje: se conservan (for, if, return, class, def, ...)
+ * - identificadores: ID, de modo que renombrar las variables o las funciones no cambia los n-gramas
+ * - números: NUM, y cadenas y caracteres (también las de varias líneas, como """ en Python): STR
+ * - operadores: se conservan, reconociendo los de varios caracteres (por ejemplo :=, ++, ->, >>>=)
+ * - comentarios y espacios: se descartan, de modo que cambiar el formato no cambia los n-gramas
+ * Con --lexer=auto el lenguaje se toma de la extensión (go, java, py, c o h). Para agregar un lenguaje basta con
+ * registrar su analizador en analizadoresLexicos (palabras reservadas, operadores y sintaxis de comentarios).
+ *
+ * El enunciado (--spec) pondera los n-gramas por los identificadores de la tarea, por lo que no se combina con un
+ * analizador léxico. El lenguaje hace parte del nombre del motor y, por lo tanto, de la huella de la caché.
+ */
+
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Analizadores léxicos especiales
+const (
+	LEXICO_GENERICO   = "generic"
+	LEXICO_AUTOMATICO = "auto"
+)
+
+// Textos de los tokens normalizados
+const (
+	TOKEN_IDENTIFICADOR = "ID"
+	TOKEN_NUMERO        = "NUM"
+	TOKEN_CADENA        = "STR"
+)
+
+// Estructura del analizador léxico de un lenguaje
+// - nombre del lenguaje (para los reportes)
+// - sintaxis de los comentarios y cadenas (ver comentarios.go)
+// - delimitadores de las cadenas de varias líneas (por ejemplo """ en Python)
+// - palabras reservadas (los demás identificadores se normalizan)
+// - operadores de varios caracteres, del más largo al más corto
+// - caracteres adicionales de los identificadores (por ejemplo $ en Java)
+type AnalizadorLexico struct {
+	lenguaje      string
+	sintaxis      SintaxisComentarios
+	cadenasLargas []string
+	palabrasClave map[string]bool
+	operadores    []string
+	identificador string
+}
+
+// Analizadores léxicos por lenguaje
+var analizadoresLexicos = map[string]*AnalizadorLexico{
+	"go": crearAnalizadorLexico("go", obtenerSintaxisComentarios("go"), "", "", `
+		break case chan const continue default defer else fallthrough for func go goto if import interface map package
+		range return select struct switch type var nil true false iota append cap close copy delete len make new panic
+		print println recover bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune
+		string uint uint8 uint16 uint32 uint64 uintptr any`,
+		"<<= >>= &^= ... := == != <= >= && || <- ++ -- += -= *= /= %= &= |= ^= << >> &^"),
+	"java": crearAnalizadorLexico("java", obtenerSintaxisComentarios("java"), "", "$", `
+		abstract assert boolean break byte case catch char class const continue default do double else enum extends
+		final finally float for goto if implements import instanceof int interface long native new package private
+		protected public return short static strictfp super switch synchronized this throw throws transient try void
+		volatile while var record yield true false null`,
+		">>>= <<= >>= >>> ... -> :: == != <= >= && || ++ -- += -= *= /= %= &= |= ^= << >>"),
+	"python": crearAnalizadorLexico("python", obtenerSintaxisComentarios("py"), `""" '''`, "", `
+		False None True and as assert async await break class continue def del elif else except finally for from
+		global if import in is lambda nonlocal not or pass raise return try while with yield match case`,
+		"**= //= >>= <<= ... -> := == != <= >= ** // << >> += -= *= /= %= &= |= ^= @="),
+	"c": crearAnalizadorLexico("c", obtenerSintaxisComentarios("c"), "", "", `
+		auto break case char const continue default do double else enum extern float for goto if inline int long
+		register restrict return short signed sizeof static struct switch typedef union unsigned void volatile while
+		_Bool include define undef ifdef ifndef endif elif pragma NULL`,
+		"<<= >>= ... -> ++ -- == != <= >= && || += -= *= /= %= &= |= ^= << >> ##"),
+}
+
+// Lenguaje del analizador léxico de cada extensión (--lexer=auto)
+var lenguajesPorExtension = map[string]string{"go": "go", "java": "java", "py": "python", "c": "c", "h": "c"}
+
+/*
+ * Función para crear el analizador léxico de un lenguaje
+ * param: nombre del lenguaje, sintaxis de los comentarios y cadenas, delimitadores de las cadenas de varias líneas,
+ *        caracteres adicionales de los identificadores, palabras reservadas y operadores (separados por espacios)
+ * return: el analizador léxico
+ */
+func crearAnalizadorLexico(lenguaje string, sintaxis SintaxisComentarios, cadenasLargas string, identificador string, palabras string, operadores string) *AnalizadorLexico {
+	analizador := &AnalizadorLexico{lenguaje: lenguaje, sintaxis: sintaxis, cadenasLargas: strings.Fields(cadenasLargas), palabrasClave: crearConjuntoPalabras(palabras), operadores: strings.Fields(operadores), identificador: identificador}
+	sort.SliceStable(analizador.operadores, func(i, j int) bool {
+		return len(analizador.operadores[i]) > len(analizador.operadores[j])
+	})
+
+	return analizador
+}
+
+/*
+ * Función para obtener el analizador léxico indicado por el usuario
+ * param: parámetros de ejecución
+ * return: el analizador léxico (nil para el tokenizador genérico) o un error si el lenguaje no existe, no se
+ *         aplica al motor o se combina con el enunciado
+ */
+func obtenerAnalizadorLexico(parametros Parametros) (*AnalizadorLexico, error) {
+	lenguaje := parametros.lexico
+	switch lenguaje {
+	case LEXICO_GENERICO:
+		return nil, nil
+	case LEXICO_AUTOMATICO:
+		lenguaje = lenguajesPorExtension[strings.ToLower(parametros.extension)]
+		if lenguaje == "" {
+			return nil, fmt.Errorf("No hay un analizador léxico para la extensión .%s (--lexer=auto), use --lexer=%s", parametros.extension, LEXICO_GENERICO)
+		}
+	}
+
+	analizador, existe := analizadoresLexicos[lenguaje]
+	if !existe {
+		return nil, fmt.Errorf("Analizador léxico \"%s\" no soportado (%s | %s | %s)", parametros.lexico, LEXICO_GENERICO, LEXICO_AUTOMATICO, strings.Join(obtenerLenguajesLexicos(), " | "))
+	}
+	if parametros.motor != "tokens" {
+		return nil, fmt.Errorf("El analizador léxico (--lexer) solo se aplica al motor \"tokens\"")
+	}
+	if parametros.rutaEspecificacion != "" {
+		return nil, fmt.Errorf("El enunciado (--spec) no se puede combinar con un analizador léxico (--lexer), porque los identificadores se normalizan")
+	}
+
+	return analizador, nil
+}
+
+/*
+ * Función para obtener los lenguajes con analizador léxico
+ * return: nombres de los lenguajes en orden alfabético
+ */
+func obtenerLenguajesLexicos() []string {
+	lenguajes := make([]string, 0, len(analizadoresLexicos))
+	for lenguaje := range analizadoresLexicos {
+		lenguajes = append(lenguajes, lenguaje)
+	}
+	sort.Strings(lenguajes)
+
+	return lenguajes
+}
+
+/*
+ * Función para obtener el analizador léxico con el que se creó un motor a partir de su nombre (para los reportes)
+ * param: nombre del motor
+ * return: el analizador léxico o nil si el motor usa el tokenizador genérico
+ */
+func obtenerAnalizadorLexicoMotor(nombreMotor string) *AnalizadorLexico {
+	for lenguaje, analizador := range analizadoresLexicos {
+		if strings.Contains(nombreMotor, "-gramas de tokens de "+lenguaje+",") {
+			return analizador
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Función para determinar si un texto inicia en una posición de un contenido
+ * param: contenido, posición y texto
+ * return: si el contenido tiene el texto en la posición
+ */
+func iniciaCon(texto []rune, i int, prefijo string) bool {
+	for _, caracter := range prefijo {
+		if i >= len(texto) || texto[i] != caracter {
+			return false
+		}
+		i++
+	}
+
+	return prefijo != ""
+}
+
+/*
+ * Función para iniciar una cadena de caracteres en una posición
+ * param: contenido y posición
+ * return: el delimitador de la cadena (vacío si no inicia una), si es de varias líneas y si admite secuencias de escape
+ */
+func (analizador *AnalizadorLexico) iniciarCadena(texto []rune, i int) (string, bool, bool) {
+	for _, delimitador := range analizador.cadenasLargas {
+		if iniciaCon(texto, i, delimitador) {
+			return delimitador, true, true
+		}
+	}
+	if strings.ContainsRune(analizador.sintaxis.cadenasCrudas, texto[i]) {
+		return string(texto[i]), true, false
+	}
+	if strings.ContainsRune(analizador.sintaxis.cadenas, texto[i]) {
+		return string(texto[i]), false, true
+	}
+
+	return "", false, false
+}
+
+/*
+ * Función para encontrar el final de una cadena de caracteres o de un comentario de bloque
+ * param: contenido, posición siguiente al delimitador inicial, delimitador final, si puede tener varias líneas y si
+ *        admite secuencias de escape
+ * return: posición siguiente al delimitador final (o al final de la línea o del contenido si no se cierra)
+ */
+func encontrarCierre(texto []rune, i int, delimitador string, multilinea bool, escape bool) int {
+	for i < len(texto) {
+		if escape && texto[i] == '\\' {
+			i += 2
+			continue
+		}
+		if texto[i] == '\n' && !multilinea {
+			return i
+		}
+		if iniciaCon(texto, i, delimitador) {
+			return i + len([]rune(delimitador))
+		}
+		i++
+	}
+
+	return len(texto)
+}
+
+/*
+ * Función para determinar si un carácter hace parte de un identificador
+ * param: carácter y si es el primero del identificador
+ * return: si hace parte del identificador
+ */
+func (analizador *AnalizadorLexico) esIdentificador(caracter rune, primero bool) bool {
+	return unicode.IsLetter(caracter) || caracter == '_' || strings.ContainsRune(analizador.identificador, caracter) || (!primero && unicode.IsDigit(caracter))
+}
+
+/*
+ * Función para obtener los tokens normalizados de un contenido con el analizador léxico del lenguaje
+ * param: contenido del archivo
+ * return: secuencia de tokens con su número de línea y columna (ver Token en evidencia_fragmentos.go)
+ */
+func (analizador *AnalizadorLexico) tokenizar(contenido []byte) []Token {
+	var tokens []Token
+	texto := []rune(string(contenido))
+	numero, inicioLinea := 1, 0
+
+	// Avanza hasta una posición contando los saltos de línea
+	avanzar := func(desde int, hasta int) {
+		for k := desde; k < hasta && k < len(texto); k++ {
+			if texto[k] == '\n' {
+				numero, inicioLinea = numero+1, k+1
+			}
+		}
+	}
+
+	agregar := func(textoToken string, inicio int) {
+		hash := fnv.New64a()
+		hash.Write([]byte(textoToken))
+		tokens = append(tokens, Token{texto: textoToken, hash: hash.Sum64(), numero: numero, columna: inicio - inicioLinea + 1})
+	}
+
+	for i := 0; i < len(texto); {
+		caracter := texto[i]
+
+		switch {
+		case unicode.IsSpace(caracter):
+			avanzar(i, i+1)
+			i++
+
+		case iniciaCon(texto, i, analizador.sintaxis.inicioBloque):
+			fin := encontrarCierre(texto, i+len([]rune(analizador.sintaxis.inicioBloque)), analizador.sintaxis.finBloque, true, false)
+			avanzar(i, fin)
+			i = fin
+
+		case analizador.esComentarioLinea(texto, i):
+			for i < len(texto) && texto[i] != '\n' {
+				i++
+			}
+
+		case analizador.esIdentificador(caracter, true):
+			fin := i + 1
+			for fin < len(texto) && analizador.esIdentificador(texto[fin], false) {
+				fin++
+			}
+			palabra := string(texto[i:fin])
+			if analizador.palabrasClave[palabra] {
+				agregar(palabra, i)
+			} else {
+				agregar(TOKEN_IDENTIFICADOR, i)
+			}
+			i = fin
+
+		case unicode.IsDigit(caracter) || (caracter == '.' && i+1 < len(texto) && unicode.IsDigit(texto[i+1])):
+			fin := i + 1
+			for fin < len(texto) && (unicode.IsLetter(texto[fin]) || unicode.IsDigit(texto[fin]) || texto[fin] == '.' || texto[fin] == '_' ||
+				((texto[fin] == '+' || texto[fin] == '-') && strings.ContainsRune("eEpP", texto[fin-1]))) {
+				fin++
+			}
+			agregar(TOKEN_NUMERO, i)
+			i = fin
+
+		default:
+			if delimitador, multilinea, escape := analizador.iniciarCadena(texto, i); delimitador != "" {
+				fin := encontrarCierre(texto, i+len([]rune(delimitador)), delimitador, multilinea, escape)
+				agregar(TOKEN_CADENA, i)
+				avanzar(i, fin)
+				i = fin
+				continue
+			}
+
+			operador := string(caracter)
+			for _, candidato := range analizador.operadores {
+				if iniciaCon(texto, i, candidato) {
+					operador = candidato
+					break
+				}
+			}
+			agregar(operador, i)
+			i += len([]rune(operador))
+		}
+	}
+
+	return tokens
+}
+
+/*
+ * Función para determinar si un comentario de línea inicia en una posición
+ * param: contenido y posición
+ * return: si inicia un comentario de línea
+ */
+func (analizador *AnalizadorLexico) esComentarioLinea(texto []rune, i int) bool {
+	for _, marcador := range analizador.sintaxis.linea {
+		if iniciaCon(texto, i, marcador) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,222 @@
+/*
+ * Límite de peticiones por cliente y cuota por curso del modo servidor (--rate-limit y --course-quota).
+ *
+ * Los análisis (POST /analyze y POST /jobs) y las comparaciones (POST /v1/similarity y POST /v1/self-check) son las
+ * peticiones con las que se podría abusar del servidor, por ejemplo enviando muchas variantes de un archivo a la
+ * autoverificación para reconstruir el corpus. Por eso:
+ * - --rate-limit=N: cada cliente puede hacer N de esas peticiones por minuto (cubeta de fichas que se llena de forma
+ *   continua, con ráfagas de hasta N peticiones). El cliente es la clave de API o el usuario del proxy OIDC si el
+ *   servidor requiere autenticación (--auth-file), o la dirección IP si no.
+ * - --course-quota=N: cada curso puede hacer N de esas peticiones por día (UTC). Las comparaciones pertenecen al curso
+ *   de ?course=, que es obligatorio con una cuota si el servidor requiere autenticación.
+ * Una petición que supera un límite se responde con 429 y el encabezado Retry-After (segundos para reintentar), y se
+ * cuenta en las métricas (sasc_requests_limited_total). Las consultas de los trabajos y las métricas no se limitan.
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Cada cuánto se descartan las cubetas llenas de los clientes inactivos
+const INTERVALO_PURGA_LIMITES = time.Minute
+
+// Estructura de la cubeta de fichas de un cliente
+// - fichas disponibles (una por petición)
+// - última vez que se llenó
+type CubetaPeticiones struct {
+	fichas float64
+	ultima time.Time
+}
+
+// Estructura del consumo de la cuota de un curso
+// - día (UTC) del consumo
+// - peticiones hechas en el día
+type ConsumoCuota struct {
+	dia    string
+	usadas int
+}
+
+// Estructura de los límites del servidor (compartidos entre las peticiones)
+// - peticiones por minuto de cada cliente (0 sin límite) y peticiones por día de cada curso (0 sin cuota)
+// - cubetas de los clientes y consumo de los cursos
+// - última purga de las cubetas
+// - métricas del servidor en las que se cuentan las peticiones rechazadas
+type LimitesServidor struct {
+	mutex            sync.Mutex
+	peticionesMinuto int
+	cuotaDiaria      int
+	cubetas          map[string]*CubetaPeticiones
+	consumos         map[string]*ConsumoCuota
+	ultimaPurga      time.Time
+	metricas         *Metricas
+}
+
+/*
+ * Función para crear los límites del servidor indicados por el usuario
+ * param: peticiones por minuto de cada cliente, peticiones por día de cada curso y métricas del servidor
+ * return: los límites (nil si no hay límite ni cuota) o un error si algún valor es negativo
+ */
+func crearLimitesServidor(peticionesMinuto int, cuotaDiaria int, metricas *Metricas) (*LimitesServidor, error) {
+	if peticionesMinuto < 0 || cuotaDiaria < 0 {
+		return nil, fmt.Errorf("El límite de peticiones (--rate-limit) y la cuota por curso (--course-quota) deben ser mayores o iguales a 0")
+	}
+	if peticionesMinuto == 0 && cuotaDiaria == 0 {
+		return nil, nil
+	}
+
+	return &LimitesServidor{peticionesMinuto: peticionesMinuto, cuotaDiaria: cuotaDiaria, cubetas: make(map[string]*CubetaPeticiones), consumos: make(map[string]*ConsumoCuota), ultimaPurga: time.Now(), metricas: metricas}, nil
+}
+
+/*
+ * Función para determinar si las comparaciones deben indicar su curso (hay una cuota por curso)
+ * return: si se requiere el curso
+ */
+func (limites *LimitesServidor) requiereCurso() bool {
+	return limites != nil && limites.cuotaDiaria > 0
+}
+
+/*
+ * Función para identificar al cliente de una petición sin conservar su clave de API
+ * param: petición y autorización del servidor (nil si no se requiere)
+ * return: identificador del cliente
+ */
+func identificarCliente(peticion *http.Request, autorizacion *Autorizacion) string {
+	if autorizacion != nil {
+		if clave := peticion.Header.Get("Authorization"); clave != "" {
+			huella := sha256.Sum256([]byte(clave))
+			return "clave:" + hex.EncodeToString(huella[:8])
+		}
+		if usuario := autorizacion.obtenerUsuarioProxy(peticion); usuario != "" {
+			return "usuario:" + usuario
+		}
+	}
+
+	direccion, _, err := net.SplitHostPort(peticion.RemoteAddr)
+	if err != nil {
+		direccion = peticion.RemoteAddr
+	}
+
+	return "ip:" + direccion
+}
+
+/*
+ * Función para permitir una petición según el límite de su cliente y la cuota de su curso.
+ * Si la petición no se permite se responde el error.
+ * param: respuesta, petición, autorización del servidor (nil si no se requiere) y curso (vacío si no tiene)
+ * return: si la petición se permite (siempre si no hay límites)
+ */
+func (limites *LimitesServidor) permitir(respuesta http.ResponseWriter, peticion *http.Request, autorizacion *Autorizacion, curso string) bool {
+	if limites == nil {
+		return true
+	}
+
+	ahora := time.Now()
+	cliente := identificarCliente(peticion, autorizacion)
+
+	limites.mutex.Lock()
+	defer limites.mutex.Unlock()
+
+	limites.purgar(ahora)
+
+	var cubeta *CubetaPeticiones
+	if limites.peticionesMinuto > 0 {
+		cubeta = limites.llenarCubeta(cliente, ahora)
+		if cubeta.fichas < 1 {
+			espera := math.Ceil((1 - cubeta.fichas) * 60 / float64(limites.peticionesMinuto))
+			return limites.rechazar(respuesta, int(espera), fmt.Sprintf("Se superó el límite de %d peticiones por minuto", limites.peticionesMinuto))
+		}
+	}
+
+	var consumo *ConsumoCuota
+	if limites.cuotaDiaria > 0 && curso != "" {
+		dia := ahora.UTC().Format("2006-01-02")
+		consumo = limites.consumos[curso]
+		if consumo == nil || consumo.dia != dia {
+			consumo = &ConsumoCuota{dia: dia}
+			limites.consumos[curso] = consumo
+		}
+		if consumo.usadas >= limites.cuotaDiaria {
+			manana := ahora.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+			return limites.rechazar(respuesta, int(math.Ceil(manana.Sub(ahora).Seconds())), fmt.Sprintf("Se agotó la cuota de %d peticiones diarias del curso \"%s\"", limites.cuotaDiaria, curso))
+		}
+	}
+
+	if cubeta != nil {
+		cubeta.fichas--
+	}
+	if consumo != nil {
+		consumo.usadas++
+	}
+
+	return true
+}
+
+/*
+ * Función para obtener la cubeta de un cliente con las fichas acumuladas desde su último uso
+ * param: identificador del cliente y momento de la petición
+ * return: la cubeta del cliente (llena si es nuevo)
+ */
+func (limites *LimitesServidor) llenarCubeta(cliente string, ahora time.Time) *CubetaPeticiones {
+	capacidad := float64(limites.peticionesMinuto)
+
+	cubeta, existe := limites.cubetas[cliente]
+	if !existe {
+		cubeta = &CubetaPeticiones{fichas: capacidad, ultima: ahora}
+		limites.cubetas[cliente] = cubeta
+		return cubeta
+	}
+
+	cubeta.fichas = math.Min(capacidad, cubeta.fichas+ahora.Sub(cubeta.ultima).Minutes()*capacidad)
+	cubeta.ultima = ahora
+
+	return cubeta
+}
+
+/*
+ * Función para descartar las cubetas que ya se llenaron y los consumos de días anteriores, para que la memoria
+ * no crezca con los clientes y los cursos inactivos
+ * param: momento de la petición
+ */
+func (limites *LimitesServidor) purgar(ahora time.Time) {
+	if ahora.Sub(limites.ultimaPurga) < INTERVALO_PURGA_LIMITES {
+		return
+	}
+	limites.ultimaPurga = ahora
+
+	for cliente, cubeta := range limites.cubetas {
+		if ahora.Sub(cubeta.ultima) >= time.Minute {
+			delete(limites.cubetas, cliente)
+		}
+	}
+
+	dia := ahora.UTC().Format("2006-01-02")
+	for curso, consumo := range limites.consumos {
+		if consumo.dia != dia {
+			delete(limites.consumos, curso)
+		}
+	}
+}
+
+/*
+ * Función para rechazar una petición que supera un límite
+ * param: respuesta, segundos para reintentar y mensaje
+ * return: falso (la petición no se permite)
+ */
+func (limites *LimitesServidor) rechazar(respuesta http.ResponseWriter, espera int, mensaje string) bool {
+	limites.metricas.registrarRechazo()
+
+	respuesta.Header().Set("Retry-After", strconv.Itoa(espera))
+	http.Error(respuesta, mensaje, http.StatusTooManyRequests)
+
+	return false
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+ * Función para crear una petición de una prueba desde un cliente
+ * param: dirección IP del cliente y clave de API (vacía si no se envía)
+ * return: la petición
+ */
+func crearPeticionClientePrueba(direccion string, clave string) *http.Request {
+	peticion := httptest.NewRequest(http.MethodPost, "/analyze", nil)
+	peticion.RemoteAddr = direccion + ":40000"
+	if clave != "" {
+		peticion.Header.Set("Authorization", "Bearer "+clave)
+	}
+
+	return peticion
+}
+
+func TestCrearLimitesServidor(t *testing.T) {
+	casos := []struct {
+		peticionesMinuto, cuotaDiaria int
+		creados, valido               bool
+	}{
+		{0, 0, false, true},
+		{10, 0, true, true},
+		{0, 100, true, true},
+		{-1, 0, false, false},
+		{0, -1, false, false},
+	}
+
+	for _, caso := range casos {
+		limites, err := crearLimitesServidor(caso.peticionesMinuto, caso.cuotaDiaria, crearMetricas())
+		if (err == nil) != caso.valido || (limites != nil) != caso.creados {
+			t.Errorf("crearLimitesServidor(%d, %d) = %v, %v", caso.peticionesMinuto, caso.cuotaDiaria, limites, err)
+		}
+	}
+}
+
+func TestIdentificarCliente(t *testing.T) {
+	autorizacion, err := crearAutorizacion(crearArchivoAutorizacionPrueba(t, "{}"), "X-Forwarded-Email", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conUsuario := crearPeticionClientePrueba("10.0.0.1", "")
+	conUsuario.Header.Set("X-Forwarded-Email", "docente@uniquindio.edu.co")
+	fueraDelProxy := crearPeticionClientePrueba("10.0.0.2", "")
+	fueraDelProxy.Header.Set("X-Forwarded-Email", "docente@uniquindio.edu.co")
+
+	casos := []struct {
+		nombre       string
+		peticion     *http.Request
+		autorizacion *Autorizacion
+		cliente      string
+	}{
+		{"dirección IP", crearPeticionClientePrueba("10.0.0.1", ""), nil, "ip:10.0.0.1"},
+		{"clave sin autenticación", crearPeticionClientePrueba("10.0.0.1", "secreta"), nil, "ip:10.0.0.1"},
+		{"usuario del proxy", conUsuario, autorizacion, "usuario:docente@uniquindio.edu.co"},
+		{"usuario fuera del proxy", fueraDelProxy, autorizacion, "ip:10.0.0.2"},
+		{"sin identidad con autenticación", crearPeticionClientePrueba("10.0.0.2", ""), autorizacion, "ip:10.0.0.2"},
+	}
+
+	for _, caso := range casos {
+		if cliente := identificarCliente(caso.peticion, caso.autorizacion); cliente != caso.cliente {
+			t.Errorf("%s: cliente = %q, se esperaba %q", caso.nombre, cliente, caso.cliente)
+		}
+	}
+
+	// La clave de API no se conserva, solamente una huella que distingue a los clientes
+	clave := identificarCliente(crearPeticionClientePrueba("10.0.0.1", "secreta"), autorizacion)
+	otra := identificarCliente(crearPeticionClientePrueba("10.0.0.1", "otra"), autorizacion)
+	if clave == otra || clave[:6] != "clave:" || len(clave) != len("clave:")+16 {
+		t.Errorf("clientes con clave = %q y %q, se esperaban huellas distintas", clave, otra)
+	}
+}
+
+func TestLimitePeticionesPorMinuto(t *testing.T) {
+	metricas := crearMetricas()
+	limites, err := crearLimitesServidor(3, 0, metricas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ráfaga de hasta 3 peticiones por cliente
+	for i := 0; i < 3; i++ {
+		if !limites.permitir(httptest.NewRecorder(), crearPeticionClientePrueba("10.0.0.1", ""), nil, "") {
+			t.Fatalf("petición %d de la ráfaga rechazada", i+1)
+		}
+	}
+
+	respuesta := httptest.NewRecorder()
+	if limites.permitir(respuesta, crearPeticionClientePrueba("10.0.0.1", ""), nil, "") {
+		t.Fatal("cuarta petición del minuto permitida")
+	}
+	if respuesta.Code != http.StatusTooManyRequests {
+		t.Errorf("estado = %d, se esperaba 429", respuesta.Code)
+	}
+	if espera, err := strconv.Atoi(respuesta.Header().Get("Retry-After")); err != nil || espera < 1 || espera > 20 {
+		t.Errorf("Retry-After = %q, se esperaban los segundos para una ficha (hasta 20)", respuesta.Header().Get("Retry-After"))
+	}
+	if metricas.rechazos != 1 {
+		t.Errorf("rechazos en las métricas = %d, se esperaba 1", metricas.rechazos)
+	}
+
+	// Cada cliente tiene su propia cubeta
+	if !limites.permitir(httptest.NewRecorder(), crearPeticionClientePrueba("10.0.0.2", ""), nil, "") {
+		t.Error("petición de otro cliente rechazada")
+	}
+
+	// La cubeta se llena de forma continua: en 20 segundos se recupera una ficha
+	limites.cubetas["ip:10.0.0.1"].ultima = time.Now().Add(-21 * time.Second)
+	if !limites.permitir(httptest.NewRecorder(), crearPeticionClientePrueba("10.0.0.1", ""), nil, "") {
+		t.Error("petición rechazada después de recuperar una ficha")
+	}
+	if limites.permitir(httptest.NewRecorder(), crearPeticionClientePrueba("10.0.0.1", ""), nil, "") {
+		t.Error("segunda petición permitida con una sola ficha recuperada")
+	}
+}
+
+func TestCuotaDiariaPorCurso(t *testing.T) {
+	limites, err := crearLimitesServidor(0, 2, crearMetricas())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !limites.requiereCurso() {
+		t.Error("con una cuota por curso no se requiere el curso")
+	}
+
+	// La cuota es del curso, sin importar el cliente
+	for i, direccion := range []string{"10.0.0.1", "10.0.0.2"} {
+		if !limites.permitir(httptest.NewRecorder(), crearPeticionClientePrueba(direccion, ""), nil, "programacion1") {
+			t.Fatalf("petición %d del curso rechazada", i+1)
+		}
+	}
+
+	respuesta := httptest.NewRecorder()
+	if limites.permitir(respuesta, crearPeticionClientePrueba("10.0.0.3", ""), nil, "programacion1") {
+		t.Fatal("petición permitida con la cuota del curso agotada")
+	}
+	if espera, err := strconv.Atoi(respuesta.Header().Get("Retry-After")); respuesta.Code != http.StatusTooManyRequests || err != nil || espera < 1 || espera > 24*60*60 {
+		t.Errorf("estado = %d con Retry-After %q, se esperaba 429 hasta el día siguiente", respuesta.Code, respuesta.Header().Get("Retry-After"))
+	}
+
+	if !limites.permitir(httptest.NewRecorder(), crearPeticionClientePrueba("10.0.0.1", ""), nil, "programacion2") {
+		t.Error("petición de otro curso rechazada")
+	}
+	if !limites.permitir(httptest.NewRecorder(), crearPeticionClientePrueba("10.0.0.1", ""), nil, "") {
+		t.Error("petición sin curso rechazada")
+	}
+
+	// La cuota se reinicia cada día (UTC)
+	limites.consumos["programacion1"].dia = "2000-01-01"
+	if !limites.permitir(httptest.NewRecorder(), crearPeticionClientePrueba("10.0.0.1", ""), nil, "programacion1") {
+		t.Error("petición rechazada con la cuota de un día anterior")
+	}
+}
+
+func TestPurgarLimites(t *testing.T) {
+	limites, err := crearLimitesServidor(5, 5, crearMetricas())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ahora := time.Now()
+	limites.cubetas["ip:10.0.0.1"] = &CubetaPeticiones{fichas: 5, ultima: ahora.Add(-2 * time.Minute)}
+	limites.cubetas["ip:10.0.0.2"] = &CubetaPeticiones{fichas: 1, ultima: ahora}
+	limites.consumos["programacion1"] = &ConsumoCuota{dia: "2000-01-01", usadas: 5}
+	limites.consumos["programacion2"] = &ConsumoCuota{dia: ahora.UTC().Format("2006-01-02"), usadas: 1}
+
+	// Antes del intervalo de purga no se descarta nada
+	limites.purgar(ahora)
+	if len(limites.cubetas) != 2 || len(limites.consumos) != 2 {
+		t.Fatalf("purga antes del intervalo: %d cubetas y %d consumos", len(limites.cubetas), len(limites.consumos))
+	}
+
+	limites.ultimaPurga = ahora.Add(-INTERVALO_PURGA_LIMITES)
+	limites.purgar(ahora)
+	if _, existe := limites.cubetas["ip:10.0.0.1"]; existe || len(limites.cubetas) != 1 {
+		t.Errorf("cubetas después de la purga = %v, se esperaba solo la del cliente activo", limites.cubetas)
+	}
+	if _, existe := limites.consumos["programacion1"]; existe || len(limites.consumos) != 1 {
+		t.Errorf("consumos después de la purga = %v, se esperaba solo el del día", limites.consumos)
+	}
+}
+
+func TestServidorLimitePeticiones(t *testing.T) {
+	parametros := crearParametrosServidorPrueba()
+	parametros.limitePeticiones = 1
+	servidor := crearServidorPrueba(t, parametros)
+
+	entregas := map[string]string{"a/main.go": "package main\n\nfunc main() {}\n", "b/main.go": "package main\n\nfunc main() { println(1) }\n"}
+
+	if estado, cuerpo := enviarPeticionPrueba(t, http.MethodPost, servidor.URL+"/analyze", crearEntregasPrueba(t, entregas)); estado != http.StatusOK {
+		t.Fatalf("primera petición: estado %d: %s", estado, cuerpo)
+	}
+	if estado, _ := enviarPeticionPrueba(t, http.MethodPost, servidor.URL+"/analyze", crearEntregasPrueba(t, entregas)); estado != http.StatusTooManyRequests {
+		t.Errorf("segunda petición del minuto: estado %d, se esperaba 429", estado)
+	}
+
+	// Las métricas no se limitan y cuentan el rechazo
+	estado, cuerpo := enviarPeticionPrueba(t, http.MethodGet, servidor.URL+"/metrics", nil)
+	if estado != http.StatusOK || !strings.Contains(cuerpo, "sasc_requests_limited_total 1\n") {
+		t.Errorf("métricas: estado %d sin el rechazo:\n%s", estado, cuerpo)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Línea de tiempo de la similaridad en entregas de git (--timeline).
+ *
+ * Para cada par de archivos de distinto estudiante a una distancia máxima, cuyos archivos pertenecen a un
+ * repositorio de git, se recorren en orden cronológico los commits que modificaron alguno de los dos archivos
+ * y se calcula la distancia entre las versiones de ambos en ese momento (con el mismo motor y preprocesamiento).
+ * Una distancia que disminuye poco a poco sugiere un desarrollo en paralelo, mientras que una caída abrupta
+ * en un solo commit sugiere una copia completa tardía.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Estructura para almacenar un commit que modificó un archivo
+// - índice del archivo en la tabla de código fuente
+// - identificador y fecha del commit
+type CommitArchivo struct {
+	archivo int
+	commit  string
+	fecha   time.Time
+}
+
+/*
+ * Función para obtener los commits que modificaron un archivo, del más antiguo al más reciente
+ * param: índice del archivo y su ruta
+ * return: los commits o un error si el archivo no pertenece a un repositorio
+ */
+func obtenerHistorialArchivo(archivo int, ruta string) ([]CommitArchivo, error) {
+	var historial []CommitArchivo
+
+	salida, err := ejecutarGit("-C", filepath.Dir(ruta), "log", "--reverse", "--format=%H %ct", "--", filepath.Base(ruta))
+	if err != nil {
+		return historial, err
+	}
+
+	for _, renglon := range strings.Split(salida, "\n") {
+		campos := strings.Fields(renglon)
+		if len(campos) != 2 {
+			continue
+		}
+
+		segundos, _ := strconv.ParseInt(campos[1], 10, 64)
+		historial = append(historial, CommitArchivo{archivo: archivo, commit: campos[0], fecha: time.Unix(segundos, 0)})
+	}
+
+	return historial, nil
+}
+
+/*
+ * Función para obtener el código fuente de un archivo en un commit (preprocesado y caracterizado)
+ * param: ruta del archivo, commit, el motor a emplear y el preprocesamiento del contenido
+ * return: el código fuente o un error si el archivo no existe en el commit
+ */
+func obtenerVersionArchivo(ruta string, commit string, motor Motor, preprocesamiento Preprocesamiento) (CodigoFuente, error) {
+	// No se usa ejecutarGit porque el contenido debe conservar los espacios del final
+	contenido, err := exec.Command("git", "-C", filepath.Dir(ruta), "show", commit+":./"+filepath.Base(ruta)).Output()
+	if err != nil {
+		return CodigoFuente{}, err
+	}
+
+	codigoFuente := CodigoFuente{nombre: ruta, ruta: ruta, tamano: len(contenido), contenido: preprocesamiento.preprocesar(contenido)}
+	motor.caracterizar(&codigoFuente, codigoFuente.contenido)
+
+	return codigoFuente, nil
+}
+
+/*
+ * Función para imprimir la línea de tiempo de la distancia entre dos archivos de git
+ * param: arreglo con la información del código fuente de los archivos, índices de ambos archivos,
+ *        sus commits, el motor a emplear y el preprocesamiento del contenido
+ */
+func imprimirLineaTiempoPar(tablaCodigoFuente []CodigoFuente, i int, j int, historial []CommitArchivo, motor Motor, preprocesamiento Preprocesamiento) {
+	fmt.Printf("%s <-> %s\n", tablaCodigoFuente[i].nombre, tablaCodigoFuente[j].nombre)
+
+	sort.SliceStable(historial, func(a, b int) bool {
+		return historial[a].fecha.Before(historial[b].fecha)
+	})
+
+	versiones := make(map[int]CodigoFuente)
+	for _, commit := range historial {
+		version, err := obtenerVersionArchivo(tablaCodigoFuente[commit.archivo].ruta, commit.commit, motor, preprocesamiento)
+		if err == nil {
+			versiones[commit.archivo] = version
+		} else {
+			delete(versiones, commit.archivo)
+		}
+
+		distancia := "       -"
+		version1, existe1 := versiones[i]
+		version2, existe2 := versiones[j]
+		if existe1 && existe2 {
+			distancia = fmt.Sprintf("%8.2f", motor.distancia(version1, version2))
+		}
+
+		fmt.Printf("\t%s  %s  %.8s  %s\n", commit.fecha.Format("2006-01-02 15:04"), distancia, commit.commit, tablaCodigoFuente[commit.archivo].nombre)
+	}
+	fmt.Println()
+}
+
+/*
+ * Función para imprimir la línea de tiempo de la similaridad de los pares de archivos de git de distinto
+ * estudiante a una distancia máxima
+ * param: arreglo con la información del código fuente de los archivos, la distancia mínima,
+ *        el motor a emplear y el preprocesamiento del contenido
+ */
+func imprimirLineaTiempo(tablaCodigoFuente []CodigoFuente, distanciaMinima float64, motor Motor, preprocesamiento Preprocesamiento) {
+	fmt.Print("\nLÍNEA DE TIEMPO DE LA SIMILARIDAD (DISTANCIA DESPUÉS DE CADA COMMIT)\n\n")
+
+	historiales := make([][]CommitArchivo, len(tablaCodigoFuente))
+	versionados := 0
+	for i := range tablaCodigoFuente {
+		if historial, err := obtenerHistorialArchivo(i, tablaCodigoFuente[i].ruta); err == nil && len(historial) > 0 {
+			historiales[i] = historial
+			versionados++
+		}
+	}
+
+	if versionados == 0 {
+		fmt.Print("Ningún archivo pertenece a un repositorio de git\n\n")
+		return
+	}
+
+	for i := 0; i < len(tablaCodigoFuente); i++ {
+		for j := i + 1; j < len(tablaCodigoFuente); j++ {
+			if historiales[i] == nil || historiales[j] == nil || obtenerDistancia(tablaCodigoFuente[i], j) > distanciaMinima ||
+				obtenerEstudiante(".", tablaCodigoFuente[i].ruta) == obtenerEstudiante(".", tablaCodigoFuente[j].ruta) {
+				continue
+			}
+
+			historial := append(append([]CommitArchivo{}, historiales[i]...), historiales[j]...)
+			imprimirLineaTiempoPar(tablaCodigoFuente, i, j, historial, motor, preprocesamiento)
+		}
+	}
+}
@@ -0,0 +1,796 @@
+/*
+ * Integración como herramienta externa LTI 1.3 en el modo servidor (Moodle, Canvas, etc.).
+ *
+ * El archivo de configuración (--lti-config) indica las plataformas registradas:
+ *
+ *     {"plataformas": [{"emisor": "https://canvas.instructure.com", "client_id": "10000000000001",
+ *                       "deployment_ids": ["1:8865aa05b4b79b64a91a86042e43af5ea8ae79eb"],
+ *                       "url_autorizacion": "https://canvas.instructure.com/api/lti/authorize_redirect",
+ *                       "url_claves": "https://canvas.instructure.com/api/lti/security/jwks",
+ *                       "url_canvas": "https://canvas.instructure.com", "archivo_token_canvas": "canvas.token"}],
+ *      "clave_privada": "lti.pem"}
+ *
+ * Una misma plataforma (emisor) puede registrar varios clientes, por ejemplo una instancia compartida de Canvas con
+ * varias instituciones, por lo que cada plataforma se identifica por el emisor y el client_id, y opcionalmente se
+ * restringe a sus despliegues (deployment_ids, vacío para aceptar cualquiera).
+ *
+ * Flujo de lanzamiento:
+ * - GET|POST /lti/login   inicio del login OIDC de terceros, redirige a la plataforma con state y nonce
+ * - POST /lti/launch      recibe el id_token, verifica su firma (RS256 con las claves públicas de la plataforma),
+ *                         el emisor, la audiencia, el despliegue, la expiración y el nonce, y abre una sesión del
+ *                         docente para el curso (contexto) del lanzamiento
+ * - POST /lti/jobs        encola el análisis de las entregas del curso, que el docente sube en un archivo .tar o que
+ *                         se descargan de la tarea de Canvas del lanzamiento (?source=canvas, ver lti_entregas.go)
+ * - GET  /lti/report/{id} reporte del trabajo dentro de la plataforma
+ * - GET  /lti/jwks        clave pública de la herramienta para la respuesta de Deep Linking (ver lti_enlaces.go)
+ * Solamente los docentes y administradores del curso pueden abrir una sesión.
+ *
+ * En un lanzamiento de Deep Linking el trabajo encolado se devuelve a la plataforma como un enlace al reporte, y al
+ * abrir ese enlace el lanzamiento lleva al reporte del trabajo. La sesión se identifica con un parámetro (?session=)
+ * porque los navegadores bloquean las cookies de terceros dentro del iframe de la plataforma.
+ */
+
+package main
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tiempo de validez del login OIDC (entre /lti/login y /lti/launch) y de la sesión del docente
+const (
+	VALIDEZ_LOGIN_LTI  = 10 * time.Minute
+	VALIDEZ_SESION_LTI = 8 * time.Hour
+)
+
+// Consulta de las claves públicas de las plataformas: tiempo máximo de la consulta, tamaño máximo de la respuesta,
+// validez de las claves en caché y tiempo mínimo entre dos consultas de un mismo conjunto (cuando el token indica una
+// clave desconocida, para que un token falso no provoque una consulta a la plataforma en cada lanzamiento)
+const (
+	TIEMPO_CONSULTA_CLAVES_LTI = 10 * time.Second
+	TAMANO_MAXIMO_CLAVES_LTI   = 1 << 20
+	VALIDEZ_CLAVES_LTI         = time.Hour
+	INTERVALO_CLAVES_LTI       = time.Minute
+)
+
+// Reclamos (claims) de LTI 1.3 empleados
+const (
+	RECLAMO_TIPO_MENSAJE  = "https://purl.imsglobal.org/spec/lti/claim/message_type"
+	RECLAMO_CONTEXTO      = "https://purl.imsglobal.org/spec/lti/claim/context"
+	RECLAMO_ROLES         = "https://purl.imsglobal.org/spec/lti/claim/roles"
+	RECLAMO_ENLACE        = "https://purl.imsglobal.org/spec/lti/claim/resource_link"
+	RECLAMO_DESPLIEGUE    = "https://purl.imsglobal.org/spec/lti/claim/deployment_id"
+	RECLAMO_VERSION       = "https://purl.imsglobal.org/spec/lti/claim/version"
+	RECLAMO_DESTINO       = "https://purl.imsglobal.org/spec/lti/claim/target_link_uri"
+	RECLAMO_PERSONALIZADO = "https://purl.imsglobal.org/spec/lti/claim/custom"
+)
+
+// Estructura de una plataforma LTI registrada
+// - emisor (iss) e identificador del cliente (client_id) que la identifican
+// - despliegues aceptados (vacío para aceptar cualquiera)
+// - URL de autorización del login OIDC y del conjunto de claves públicas (JWKS)
+// - URL de la API de Canvas y archivo con su token para descargar las entregas (opcionales), y el token leído
+type PlataformaLTI struct {
+	Emisor               string   `json:"emisor"`
+	IdentificadorCliente string   `json:"client_id"`
+	Despliegues          []string `json:"deployment_ids,omitempty"`
+	URLAutorizacion      string   `json:"url_autorizacion"`
+	URLClaves            string   `json:"url_claves"`
+	URLCanvas            string   `json:"url_canvas,omitempty"`
+	ArchivoTokenCanvas   string   `json:"archivo_token_canvas,omitempty"`
+	tokenCanvas          string
+}
+
+// Estructura del archivo de configuración LTI
+// - plataformas registradas
+// - archivo PEM de la clave privada de la herramienta para Deep Linking (opcional)
+type ConfiguracionLTI struct {
+	Plataformas  []PlataformaLTI `json:"plataformas"`
+	ClavePrivada string          `json:"clave_privada,omitempty"`
+}
+
+// Estructura de un login OIDC en curso
+// - plataforma que inició el login (el lanzamiento debe venir de la misma)
+type LoginLTI struct {
+	plataforma PlataformaLTI
+	nonce      string
+	expira     time.Time
+}
+
+// Estructura de la sesión de un docente abierta por un lanzamiento LTI
+// - plataforma, curso (contexto) y título del curso y de la actividad
+// - usuario de la plataforma (correo o identificador del token) para el registro de auditoría
+// - despliegue y URL de destino del lanzamiento, y URL de retorno y datos de Deep Linking (vacía si no lo es)
+// - curso y tarea de Canvas de los campos personalizados (vacíos si no se descargan las entregas)
+type SesionLTI struct {
+	plataforma     PlataformaLTI
+	usuario        string
+	curso          string
+	titulo         string
+	actividad      string
+	despliegue     string
+	destino        string
+	retornoEnlaces string
+	datosEnlaces   string
+	cursoCanvas    string
+	tareaCanvas    string
+	expira         time.Time
+}
+
+// Estructura de una clave pública de una plataforma en caché
+// - clave y hora en que se consultó su conjunto de claves
+type ClavePlataforma struct {
+	clave      *rsa.PublicKey
+	consultada time.Time
+}
+
+// Estructura de las claves públicas de las plataformas
+// - cliente HTTP con tiempo máximo de consulta
+// - claves por URL del conjunto y kid, y hora de la última consulta de cada conjunto (protegidas por el mutex)
+type ClavesPlataformas struct {
+	cliente    *http.Client
+	mutex      sync.Mutex
+	claves     map[string]ClavePlataforma
+	consultado map[string]time.Time
+}
+
+// Estructura de la herramienta LTI
+// - configuración de las plataformas
+// - logins en curso por state y sesiones por identificador (protegidos por el mutex)
+// - claves públicas de las plataformas y clave privada de la herramienta (nil sin Deep Linking)
+type HerramientaLTI struct {
+	configuracion ConfiguracionLTI
+	mutex         sync.Mutex
+	logins        map[string]LoginLTI
+	sesiones      map[string]SesionLTI
+	claves        *ClavesPlataformas
+	clave         *rsa.PrivateKey
+}
+
+/*
+ * Función para crear la herramienta LTI a partir del archivo de configuración
+ * param: nombre del archivo de configuración (vacío si no se usa LTI)
+ * return: la herramienta (nil si no se usa LTI) o un error si la configuración no es válida
+ */
+func crearHerramientaLTI(nombreArchivo string) (*HerramientaLTI, error) {
+	if nombreArchivo == "" {
+		return nil, nil
+	}
+
+	contenido, err := ioutil.ReadFile(nombreArchivo)
+	if err != nil {
+		return nil, fmt.Errorf("No se puede leer la configuración LTI (--lti-config): %v", err)
+	}
+
+	herramienta := &HerramientaLTI{logins: make(map[string]LoginLTI), sesiones: make(map[string]SesionLTI), claves: crearClavesPlataformas()}
+	if err = json.Unmarshal(contenido, &herramienta.configuracion); err != nil {
+		return nil, fmt.Errorf("Configuración LTI (--lti-config) no válida: %v", err)
+	}
+
+	for i := range herramienta.configuracion.Plataformas {
+		plataforma := &herramienta.configuracion.Plataformas[i]
+		if plataforma.tokenCanvas, err = leerTokenCanvasLTI(plataforma.ArchivoTokenCanvas); err != nil {
+			return nil, err
+		}
+	}
+	if herramienta.configuracion.ClavePrivada != "" {
+		if herramienta.clave, err = leerClavePrivadaLTI(herramienta.configuracion.ClavePrivada); err != nil {
+			return nil, err
+		}
+	}
+
+	return herramienta, nil
+}
+
+/*
+ * Función para determinar si una plataforma acepta un despliegue
+ * param: identificador del despliegue (vacío si no se conoce)
+ * return: verdadero si la plataforma no restringe los despliegues, si no se conoce o si está en sus despliegues
+ */
+func (plataforma PlataformaLTI) aceptaDespliegue(despliegue string) bool {
+	if despliegue == "" || len(plataforma.Despliegues) == 0 {
+		return true
+	}
+
+	for _, aceptado := range plataforma.Despliegues {
+		if aceptado == despliegue {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+ * Función para buscar una plataforma registrada
+ * param: emisor, identificador del cliente y del despliegue (vacíos si no se conocen)
+ * return: la única plataforma registrada que coincide o un error si ninguna o varias coinciden
+ */
+func (herramienta *HerramientaLTI) buscarPlataforma(emisor string, identificadorCliente string, despliegue string) (PlataformaLTI, error) {
+	var encontradas []PlataformaLTI
+
+	for _, plataforma := range herramienta.configuracion.Plataformas {
+		if plataforma.Emisor == emisor && (identificadorCliente == "" || plataforma.IdentificadorCliente == identificadorCliente) && plataforma.aceptaDespliegue(despliegue) {
+			encontradas = append(encontradas, plataforma)
+		}
+	}
+
+	switch len(encontradas) {
+	case 0:
+		return PlataformaLTI{}, fmt.Errorf("Plataforma LTI no registrada")
+	case 1:
+		return encontradas[0], nil
+	}
+
+	return PlataformaLTI{}, fmt.Errorf("Varias plataformas LTI registradas con el emisor \"%s\", la plataforma debe indicar el client_id", emisor)
+}
+
+/*
+ * Función para obtener el identificador del cliente al que se emitió un token
+ * param: reclamos aud (texto o arreglo) y azp del token
+ * return: el reclamo azp si existe, la única audiencia o vacío si hay varias audiencias sin azp
+ */
+func obtenerClienteToken(audiencia interface{}, autorizado string) string {
+	if autorizado != "" {
+		return autorizado
+	}
+
+	switch valor := audiencia.(type) {
+	case string:
+		return valor
+	case []interface{}:
+		if len(valor) == 1 {
+			texto, _ := valor[0].(string)
+			return texto
+		}
+	}
+
+	return ""
+}
+
+/*
+ * Función para decodificar una parte de un JWT (base64 URL sin relleno)
+ * param: parte codificada
+ * return: bytes decodificados o un error
+ */
+func decodificarBase64URL(texto string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(texto, "="))
+}
+
+/*
+ * Función para crear la caché de las claves públicas de las plataformas
+ * return: la caché vacía con su cliente HTTP
+ */
+func crearClavesPlataformas() *ClavesPlataformas {
+	return &ClavesPlataformas{
+		cliente:    &http.Client{Timeout: TIEMPO_CONSULTA_CLAVES_LTI},
+		claves:     make(map[string]ClavePlataforma),
+		consultado: make(map[string]time.Time),
+	}
+}
+
+/*
+ * Función para obtener la clave pública RSA de una plataforma, de la caché o consultando su conjunto de claves si no
+ * está o ya expiró (por ejemplo cuando la plataforma rota sus claves)
+ * param: URL del conjunto de claves (JWKS) e identificador de la clave (kid)
+ * return: la clave pública o un error si no se encuentra
+ */
+func (claves *ClavesPlataformas) obtener(urlClaves string, identificadorClave string) (*rsa.PublicKey, error) {
+	claves.mutex.Lock()
+	defer claves.mutex.Unlock()
+
+	if guardada, existe := claves.claves[urlClaves+" "+identificadorClave]; existe && time.Since(guardada.consultada) < VALIDEZ_CLAVES_LTI {
+		return guardada.clave, nil
+	}
+	if time.Since(claves.consultado[urlClaves]) < INTERVALO_CLAVES_LTI {
+		return nil, fmt.Errorf("La plataforma no publica la clave \"%s\"", identificadorClave)
+	}
+
+	consultadas, err := consultarClavesPlataforma(claves.cliente, urlClaves)
+	if err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now()
+	claves.consultado[urlClaves] = ahora
+	for identificador, clave := range consultadas {
+		claves.claves[urlClaves+" "+identificador] = ClavePlataforma{clave: clave, consultada: ahora}
+	}
+
+	if clave, existe := consultadas[identificadorClave]; existe {
+		return clave, nil
+	}
+
+	return nil, fmt.Errorf("La plataforma no publica la clave \"%s\"", identificadorClave)
+}
+
+/*
+ * Función para consultar el conjunto de claves públicas RSA de una plataforma
+ * param: cliente HTTP y URL del conjunto de claves (JWKS)
+ * return: las claves por su identificador (kid) o un error si no se pueden consultar
+ */
+func consultarClavesPlataforma(cliente *http.Client, urlClaves string) (map[string]*rsa.PublicKey, error) {
+	respuesta, err := cliente.Get(urlClaves)
+	if err != nil {
+		return nil, fmt.Errorf("No se puede consultar el conjunto de claves de la plataforma: %v", err)
+	}
+	defer respuesta.Body.Close()
+
+	if respuesta.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("No se puede consultar el conjunto de claves de la plataforma: %s", respuesta.Status)
+	}
+
+	var conjunto struct {
+		Claves []struct {
+			Identificador string `json:"kid"`
+			Tipo          string `json:"kty"`
+			Modulo        string `json:"n"`
+			Exponente     string `json:"e"`
+		} `json:"keys"`
+	}
+	if err = json.NewDecoder(io.LimitReader(respuesta.Body, TAMANO_MAXIMO_CLAVES_LTI)).Decode(&conjunto); err != nil {
+		return nil, fmt.Errorf("Conjunto de claves de la plataforma no válido: %v", err)
+	}
+
+	claves := make(map[string]*rsa.PublicKey)
+	for _, clave := range conjunto.Claves {
+		if clave.Tipo != "RSA" {
+			continue
+		}
+
+		modulo, err := decodificarBase64URL(clave.Modulo)
+		if err != nil {
+			return nil, err
+		}
+		exponente, err := decodificarBase64URL(clave.Exponente)
+		if err != nil {
+			return nil, err
+		}
+
+		claves[clave.Identificador] = &rsa.PublicKey{N: new(big.Int).SetBytes(modulo), E: int(new(big.Int).SetBytes(exponente).Int64())}
+	}
+
+	return claves, nil
+}
+
+/*
+ * Función para verificar el id_token de un lanzamiento LTI
+ * param: id_token, plataforma, nonce esperado y claves públicas de las plataformas
+ * return: reclamos del token o un error si no es válido
+ */
+func verificarTokenLTI(token string, plataforma PlataformaLTI, nonce string, claves *ClavesPlataformas) (map[string]interface{}, error) {
+	partes := strings.Split(token, ".")
+	if len(partes) != 3 {
+		return nil, fmt.Errorf("id_token mal formado")
+	}
+
+	var encabezado struct {
+		Algoritmo     string `json:"alg"`
+		Identificador string `json:"kid"`
+	}
+	contenido, err := decodificarBase64URL(partes[0])
+	if err != nil || json.Unmarshal(contenido, &encabezado) != nil {
+		return nil, fmt.Errorf("Encabezado del id_token no válido")
+	}
+	if encabezado.Algoritmo != "RS256" {
+		return nil, fmt.Errorf("Algoritmo del id_token \"%s\" no soportado (RS256)", encabezado.Algoritmo)
+	}
+
+	clave, err := claves.obtener(plataforma.URLClaves, encabezado.Identificador)
+	if err != nil {
+		return nil, err
+	}
+
+	firma, err := decodificarBase64URL(partes[2])
+	if err != nil {
+		return nil, fmt.Errorf("Firma del id_token no válida")
+	}
+	resumen := sha256.Sum256([]byte(partes[0] + "." + partes[1]))
+	if err = rsa.VerifyPKCS1v15(clave, crypto.SHA256, resumen[:], firma); err != nil {
+		return nil, fmt.Errorf("Firma del id_token no válida")
+	}
+
+	reclamos := make(map[string]interface{})
+	contenido, err = decodificarBase64URL(partes[1])
+	if err != nil || json.Unmarshal(contenido, &reclamos) != nil {
+		return nil, fmt.Errorf("Reclamos del id_token no válidos")
+	}
+
+	if reclamos["iss"] != plataforma.Emisor {
+		return nil, fmt.Errorf("Emisor del id_token no válido")
+	}
+	if !contieneAudiencia(reclamos["aud"], plataforma.IdentificadorCliente) {
+		return nil, fmt.Errorf("Audiencia del id_token no válida")
+	}
+	if autorizado, existe := reclamos["azp"]; existe && autorizado != plataforma.IdentificadorCliente {
+		return nil, fmt.Errorf("Parte autorizada (azp) del id_token no válida")
+	}
+	if despliegue, _ := reclamos[RECLAMO_DESPLIEGUE].(string); despliegue == "" || !plataforma.aceptaDespliegue(despliegue) {
+		return nil, fmt.Errorf("Despliegue del id_token no válido")
+	}
+	if expira, ok := reclamos["exp"].(float64); !ok || time.Now().After(time.Unix(int64(expira), 0)) {
+		return nil, fmt.Errorf("id_token expirado")
+	}
+	if reclamos["nonce"] != nonce {
+		return nil, fmt.Errorf("nonce del id_token no válido")
+	}
+	if tipo := reclamos[RECLAMO_TIPO_MENSAJE]; tipo != "LtiResourceLinkRequest" && tipo != "LtiDeepLinkingRequest" {
+		return nil, fmt.Errorf("Tipo de mensaje LTI no soportado")
+	}
+
+	return reclamos, nil
+}
+
+/*
+ * Función para determinar si la audiencia de un token incluye al cliente
+ * param: reclamo aud (texto o arreglo) e identificador del cliente
+ * return: verdadero si lo incluye
+ */
+func contieneAudiencia(audiencia interface{}, identificadorCliente string) bool {
+	switch valor := audiencia.(type) {
+	case string:
+		return valor == identificadorCliente
+	case []interface{}:
+		for _, elemento := range valor {
+			if elemento == identificadorCliente {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+/*
+ * Función para determinar si los roles de un lanzamiento son de docente o administrador
+ * param: reclamos del token
+ * return: verdadero si tiene un rol de docente o administrador
+ */
+func esDocenteLTI(reclamos map[string]interface{}) bool {
+	roles, _ := reclamos[RECLAMO_ROLES].([]interface{})
+
+	for _, rol := range roles {
+		texto, _ := rol.(string)
+		if strings.HasSuffix(texto, "#Instructor") || strings.HasSuffix(texto, "#Administrator") {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+ * Función para obtener un texto de un reclamo de tipo objeto
+ * param: reclamos del token, nombre del reclamo y campo
+ * return: valor del campo (vacío si no existe)
+ */
+func obtenerCampoReclamo(reclamos map[string]interface{}, reclamo string, campo string) string {
+	objeto, _ := reclamos[reclamo].(map[string]interface{})
+	valor, _ := objeto[campo].(string)
+
+	return valor
+}
+
+/*
+ * Función para obtener el usuario de un lanzamiento (su correo si la plataforma lo comparte, si no el identificador
+ * del token)
+ * param: reclamos del token
+ * return: usuario del lanzamiento
+ */
+func obtenerUsuarioLTI(reclamos map[string]interface{}) string {
+	if correo, _ := reclamos["email"].(string); correo != "" {
+		return correo
+	}
+	sujeto, _ := reclamos["sub"].(string)
+
+	return sujeto
+}
+
+/*
+ * Función para consultar una sesión vigente
+ * param: identificador de la sesión
+ * return: la sesión y si está vigente
+ */
+func (herramienta *HerramientaLTI) consultarSesion(identificador string) (SesionLTI, bool) {
+	herramienta.mutex.Lock()
+	defer herramienta.mutex.Unlock()
+
+	sesion, existe := herramienta.sesiones[identificador]
+	if !existe || time.Now().After(sesion.expira) {
+		delete(herramienta.sesiones, identificador)
+		return SesionLTI{}, false
+	}
+
+	return sesion, true
+}
+
+/*
+ * Función para registrar las rutas de la herramienta LTI en el servidor
+ * param: rutas del servidor, cola de trabajos, la función de análisis de los trabajos, el registro de auditoría
+ *        (nil si no se audita) y el tamaño máximo en bytes de las entregas de una petición
+ */
+func (herramienta *HerramientaLTI) registrarRutas(rutas *http.ServeMux, cola *ColaTrabajos, analizar func(io.Reader) (ResultadoAnalisisJSON, error), auditoria *Auditoria, tamanoMaximo int64) {
+	rutas.HandleFunc("/lti/login", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		peticion.ParseForm()
+
+		plataforma, err := herramienta.buscarPlataforma(peticion.Form.Get("iss"), peticion.Form.Get("client_id"), peticion.Form.Get("lti_deployment_id"))
+		if err != nil {
+			http.Error(respuesta, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		estado, nonce := generarIdentificadorAleatorio(), generarIdentificadorAleatorio()
+		herramienta.mutex.Lock()
+		for anterior, login := range herramienta.logins {
+			if time.Now().After(login.expira) {
+				delete(herramienta.logins, anterior)
+			}
+		}
+		herramienta.logins[estado] = LoginLTI{plataforma: plataforma, nonce: nonce, expira: time.Now().Add(VALIDEZ_LOGIN_LTI)}
+		herramienta.mutex.Unlock()
+
+		autorizacion := url.Values{
+			"scope":         {"openid"},
+			"response_type": {"id_token"},
+			"response_mode": {"form_post"},
+			"prompt":        {"none"},
+			"client_id":     {plataforma.IdentificadorCliente},
+			"redirect_uri":  {peticion.Form.Get("target_link_uri")},
+			"login_hint":    {peticion.Form.Get("login_hint")},
+			"state":         {estado},
+			"nonce":         {nonce},
+		}
+		if sugerencia := peticion.Form.Get("lti_message_hint"); sugerencia != "" {
+			autorizacion.Set("lti_message_hint", sugerencia)
+		}
+
+		http.Redirect(respuesta, peticion, plataforma.URLAutorizacion+"?"+autorizacion.Encode(), http.StatusFound)
+	})
+
+	rutas.HandleFunc("/lti/launch", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		peticion.ParseForm()
+
+		herramienta.mutex.Lock()
+		login, existe := herramienta.logins[peticion.PostForm.Get("state")]
+		delete(herramienta.logins, peticion.PostForm.Get("state"))
+		herramienta.mutex.Unlock()
+
+		if !existe || time.Now().After(login.expira) {
+			http.Error(respuesta, "Lanzamiento LTI no válido o expirado", http.StatusBadRequest)
+			return
+		}
+
+		// El emisor, el cliente y el despliegue se leen sin verificar para buscar la plataforma, que debe ser la misma
+		// del login; luego el token se verifica con la clave pública de esa plataforma
+		token := peticion.PostForm.Get("id_token")
+		var sinVerificar struct {
+			Emisor     string      `json:"iss"`
+			Audiencia  interface{} `json:"aud"`
+			Autorizado string      `json:"azp"`
+			Despliegue string      `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+		}
+		if partes := strings.Split(token, "."); len(partes) == 3 {
+			contenido, _ := decodificarBase64URL(partes[1])
+			json.Unmarshal(contenido, &sinVerificar)
+		}
+
+		plataforma, err := herramienta.buscarPlataforma(sinVerificar.Emisor, obtenerClienteToken(sinVerificar.Audiencia, sinVerificar.Autorizado), sinVerificar.Despliegue)
+		if err != nil {
+			http.Error(respuesta, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if plataforma.Emisor != login.plataforma.Emisor || plataforma.IdentificadorCliente != login.plataforma.IdentificadorCliente {
+			http.Error(respuesta, "El lanzamiento LTI no corresponde a la plataforma del login", http.StatusBadRequest)
+			return
+		}
+
+		reclamos, err := verificarTokenLTI(token, plataforma, login.nonce, herramienta.claves)
+		if err != nil {
+			http.Error(respuesta, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if !esDocenteLTI(reclamos) {
+			http.Error(respuesta, "Solamente los docentes del curso pueden usar SASC", http.StatusForbidden)
+			return
+		}
+
+		sesion := SesionLTI{
+			plataforma:  plataforma,
+			usuario:     obtenerUsuarioLTI(reclamos),
+			curso:       obtenerCampoReclamo(reclamos, RECLAMO_CONTEXTO, "id"),
+			titulo:      obtenerCampoReclamo(reclamos, RECLAMO_CONTEXTO, "title"),
+			actividad:   obtenerCampoReclamo(reclamos, RECLAMO_ENLACE, "title"),
+			despliegue:  reclamos[RECLAMO_DESPLIEGUE].(string),
+			cursoCanvas: obtenerCampoReclamo(reclamos, RECLAMO_PERSONALIZADO, CAMPO_CURSO_CANVAS),
+			tareaCanvas: obtenerCampoReclamo(reclamos, RECLAMO_PERSONALIZADO, CAMPO_TAREA_CANVAS),
+			expira:      time.Now().Add(VALIDEZ_SESION_LTI),
+		}
+		sesion.destino, _ = reclamos[RECLAMO_DESTINO].(string)
+		if reclamos[RECLAMO_TIPO_MENSAJE] == "LtiDeepLinkingRequest" {
+			if herramienta.clave == nil {
+				http.Error(respuesta, "La herramienta LTI no tiene clave privada (clave_privada) para responder con Deep Linking", http.StatusBadRequest)
+				return
+			}
+			sesion.retornoEnlaces = obtenerCampoReclamo(reclamos, RECLAMO_AJUSTES_ENLACES, "deep_link_return_url")
+			sesion.datosEnlaces = obtenerCampoReclamo(reclamos, RECLAMO_AJUSTES_ENLACES, "data")
+			if sesion.retornoEnlaces == "" {
+				http.Error(respuesta, "Lanzamiento de Deep Linking sin deep_link_return_url", http.StatusBadRequest)
+				return
+			}
+		}
+		identificadorSesion := generarIdentificadorAleatorio()
+
+		herramienta.mutex.Lock()
+		herramienta.sesiones[identificadorSesion] = sesion
+		herramienta.mutex.Unlock()
+
+		// Un enlace creado por Deep Linking lleva al reporte de su trabajo
+		if trabajo := obtenerCampoReclamo(reclamos, RECLAMO_PERSONALIZADO, CAMPO_TRABAJO_LTI); trabajo != "" && sesion.retornoEnlaces == "" {
+			http.Redirect(respuesta, peticion, "/lti/report/"+url.PathEscape(trabajo)+"?session="+identificadorSesion, http.StatusSeeOther)
+			return
+		}
+
+		respuesta.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(respuesta, "<!DOCTYPE html><html><body><h1>SASC - %s</h1><p>%s</p>",
+			html.EscapeString(sesion.titulo), html.EscapeString(sesion.actividad))
+		if sesion.descargaCanvas() {
+			fmt.Fprintf(respuesta, "<form method=\"post\" action=\"/lti/jobs?session=%s&source=canvas\">"+
+				"<p><button type=\"submit\">Analizar las entregas de la tarea de Canvas</button></p></form>", identificadorSesion)
+		}
+		fmt.Fprintf(respuesta, "<form method=\"post\" enctype=\"multipart/form-data\" action=\"/lti/jobs?session=%s\">"+
+			"<p>Entregas (archivo .tar con un directorio por estudiante, por ejemplo el que se descarga de la plataforma): "+
+			"<input type=\"file\" name=\"entregas\" required></p>"+
+			"<p><button type=\"submit\">Analizar</button></p></form>", identificadorSesion)
+		if sesion.retornoEnlaces != "" {
+			fmt.Fprintf(respuesta, "<p>Al encolar el análisis se agrega a la plataforma un enlace a su reporte.</p>")
+		}
+		fmt.Fprintf(respuesta, "</body></html>")
+	})
+
+	rutas.HandleFunc("/lti/jobs", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		identificadorSesion := peticion.URL.Query().Get("session")
+		sesion, vigente := herramienta.consultarSesion(identificadorSesion)
+		if !vigente {
+			http.Error(respuesta, "Sesión LTI no válida o expirada, abra SASC de nuevo desde la plataforma", http.StatusUnauthorized)
+			return
+		}
+
+		registro := RegistroAuditoriaJSON{Accion: ACCION_ENCOLAR, Identidad: "lti:" + sesion.usuario, Curso: sesion.curso, Estado: http.StatusSeeOther}
+
+		var archivo io.Reader
+		if peticion.URL.Query().Get("source") == "canvas" {
+			if !sesion.descargaCanvas() {
+				http.Error(respuesta, "El lanzamiento no indica una tarea de Canvas con acceso a su API", http.StatusBadRequest)
+				return
+			}
+
+			descargado, err := descargarEntregasLTI(sesion, tamanoMaximo)
+			if err != nil {
+				registro.Estado = http.StatusBadGateway
+				if !auditoria.registrarPeticion(respuesta, peticion, registro) {
+					return
+				}
+				http.Error(respuesta, err.Error(), http.StatusBadGateway)
+				return
+			}
+			defer os.Remove(descargado.Name())
+			defer descargado.Close()
+			archivo = descargado
+		} else {
+			cuerpo := limitarCuerpo(respuesta, peticion, tamanoMaximo)
+			subido, _, err := peticion.FormFile("entregas")
+			if err != nil {
+				http.Error(respuesta, cuerpo.obtenerMensajeError(fmt.Errorf("Se debe enviar el archivo .tar de las entregas")), cuerpo.obtenerEstadoError())
+				return
+			}
+			defer subido.Close()
+			archivo = subido
+		}
+
+		// Cada sección es un curso de la plataforma y la actividad es la tarea (ver tablero.go)
+		trabajo, err := cola.agregar(sesion.curso, sesion.actividad, sesion.titulo, archivo, analizar)
+		if err == errorColaLlena {
+			registro.Estado = http.StatusServiceUnavailable
+			if !auditoria.registrarPeticion(respuesta, peticion, registro) {
+				return
+			}
+			responderColaLlena(respuesta)
+			return
+		}
+		if err != nil {
+			registro.Estado = http.StatusBadRequest
+			if !auditoria.registrarPeticion(respuesta, peticion, registro) {
+				return
+			}
+			http.Error(respuesta, err.Error(), http.StatusBadRequest)
+			return
+		}
+		registro.Trabajo = trabajo.Identificador
+		if sesion.retornoEnlaces != "" {
+			registro.Estado = http.StatusOK
+		}
+		if !auditoria.registrarPeticion(respuesta, peticion, registro) {
+			return
+		}
+
+		if sesion.retornoEnlaces != "" {
+			herramienta.responderEnlaces(respuesta, sesion, trabajo)
+			return
+		}
+		http.Redirect(respuesta, peticion, "/lti/report/"+trabajo.Identificador+"?session="+identificadorSesion, http.StatusSeeOther)
+	})
+
+	rutas.HandleFunc("/lti/jwks", herramienta.publicarClaves)
+
+	rutas.HandleFunc("/lti/report/", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		sesion, vigente := herramienta.consultarSesion(peticion.URL.Query().Get("session"))
+		if !vigente {
+			http.Error(respuesta, "Sesión LTI no válida o expirada, abra SASC de nuevo desde la plataforma", http.StatusUnauthorized)
+			return
+		}
+
+		registro := RegistroAuditoriaJSON{Accion: ACCION_CONSULTAR_TRABAJO, Identidad: "lti:" + sesion.usuario, Curso: sesion.curso, Trabajo: strings.TrimPrefix(peticion.URL.Path, "/lti/report/"), Estado: http.StatusOK}
+
+		trabajo, existe := cola.consultar(registro.Trabajo)
+		if !existe || trabajo.Curso != sesion.curso {
+			registro.Estado = http.StatusNotFound
+			if !auditoria.registrarPeticion(respuesta, peticion, registro) {
+				return
+			}
+			http.Error(respuesta, "Trabajo no encontrado", http.StatusNotFound)
+			return
+		}
+		if !auditoria.registrarPeticion(respuesta, peticion, registro) {
+			return
+		}
+
+		respuesta.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(respuesta, "<!DOCTYPE html><html><head>")
+		if trabajo.Estado == TRABAJO_EN_COLA || trabajo.Estado == TRABAJO_EN_EJECUCION {
+			fmt.Fprintf(respuesta, "<meta http-equiv=\"refresh\" content=\"5\">")
+		}
+		fmt.Fprintf(respuesta, "</head><body><h1>SASC - %s</h1><p>Trabajo %s: %s</p>",
+			html.EscapeString(sesion.titulo), trabajo.Identificador, trabajo.Estado)
+
+		if trabajo.Error != "" {
+			fmt.Fprintf(respuesta, "<p>%s</p>", html.EscapeString(trabajo.Error))
+		}
+
+		if trabajo.Resultado != nil {
+			fmt.Fprintf(respuesta, "<p>%d archivos analizados</p>", len(trabajo.Resultado.Archivos))
+			for _, grupo := range trabajo.Resultado.Grupos {
+				fmt.Fprintf(respuesta, "<h2>Grupo %s (diámetro %.2f)</h2>", html.EscapeString(describirNombreGrupo(grupo.Grupo, grupo.Terminos)), grupo.Diametro)
+				fmt.Fprintf(respuesta, "<p>Par ejemplar: %s - %s (%.2f)</p><ul>", html.EscapeString(grupo.ParEjemplar.Archivo1), html.EscapeString(grupo.ParEjemplar.Archivo2), grupo.ParEjemplar.Distancia)
+				for _, integrante := range grupo.Integrantes {
+					fmt.Fprintf(respuesta, "<li>%s (%.2f)</li>", html.EscapeString(integrante.Archivo), integrante.DistanciaCentro)
+				}
+				fmt.Fprintf(respuesta, "</ul>")
+			}
+
+			if len(trabajo.Resultado.Severidad) > 0 {
+				fmt.Fprintf(respuesta, "<h2>Pares por severidad</h2><ul>")
+				for _, par := range trabajo.Resultado.Severidad {
+					fmt.Fprintf(respuesta, "<li>%s %s - %s (%.2f)</li>", obtenerInsigniaSeveridad(par.Severidad),
+						html.EscapeString(par.Archivo1), html.EscapeString(par.Archivo2), par.Distancia)
+				}
+				fmt.Fprintf(respuesta, "</ul>")
+			}
+		}
+
+		fmt.Fprintf(respuesta, "</body></html>")
+	})
+}
@@ -0,0 +1,177 @@
+/*
+ * Deep Linking de LTI 1.3: devuelve a la plataforma un enlace al reporte de un trabajo.
+ *
+ * Cuando el docente agrega SASC como actividad o contenido del curso (LtiDeepLinkingRequest), el análisis se encola
+ * igual que en un lanzamiento normal y la herramienta responde a la plataforma (deep_link_return_url) con un JWT
+ * firmado que crea un enlace (ltiResourceLink) con el identificador del trabajo en sus campos personalizados
+ * (sasc_trabajo). Al abrir ese enlace desde la plataforma, el lanzamiento lleva directamente al reporte del trabajo.
+ *
+ * La herramienta firma con su clave privada RSA (clave_privada en --lti-config, archivo PEM PKCS#1 o PKCS#8) y
+ * publica la clave pública en GET /lti/jwks, la URL que se registra en la plataforma como conjunto de claves de la
+ * herramienta.
+ */
+
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// Reclamos (claims) de Deep Linking empleados
+const (
+	RECLAMO_AJUSTES_ENLACES    = "https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings"
+	RECLAMO_DATOS_ENLACES      = "https://purl.imsglobal.org/spec/lti-dl/claim/data"
+	RECLAMO_CONTENIDOS_ENLACES = "https://purl.imsglobal.org/spec/lti-dl/claim/content_items"
+)
+
+// Campo personalizado del enlace con el identificador del trabajo y validez de la respuesta de Deep Linking
+const (
+	CAMPO_TRABAJO_LTI         = "sasc_trabajo"
+	VALIDEZ_RESPUESTA_ENLACES = 5 * time.Minute
+)
+
+/*
+ * Función para leer la clave privada RSA de la herramienta
+ * param: nombre del archivo PEM (PKCS#1 o PKCS#8)
+ * return: la clave privada o un error si no se puede leer o no es una clave RSA
+ */
+func leerClavePrivadaLTI(nombreArchivo string) (*rsa.PrivateKey, error) {
+	contenido, err := ioutil.ReadFile(nombreArchivo)
+	if err != nil {
+		return nil, fmt.Errorf("No se puede leer la clave privada LTI (clave_privada): %v", err)
+	}
+
+	bloque, _ := pem.Decode(contenido)
+	if bloque == nil {
+		return nil, fmt.Errorf("La clave privada LTI (clave_privada) no está en formato PEM")
+	}
+
+	if clave, err := x509.ParsePKCS1PrivateKey(bloque.Bytes); err == nil {
+		return clave, nil
+	}
+	clave, err := x509.ParsePKCS8PrivateKey(bloque.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Clave privada LTI (clave_privada) no válida: %v", err)
+	}
+	claveRSA, ok := clave.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("La clave privada LTI (clave_privada) no es una clave RSA")
+	}
+
+	return claveRSA, nil
+}
+
+/*
+ * Función para obtener el identificador (kid) de la clave pública de la herramienta
+ * param: clave pública
+ * return: los primeros 16 caracteres hexadecimales del SHA-256 del módulo
+ */
+func obtenerIdentificadorClaveLTI(clave *rsa.PublicKey) string {
+	resumen := sha256.Sum256(clave.N.Bytes())
+
+	return hex.EncodeToString(resumen[:8])
+}
+
+/*
+ * Función para publicar el conjunto de claves públicas (JWKS) de la herramienta
+ * param: respuesta HTTP y petición
+ */
+func (herramienta *HerramientaLTI) publicarClaves(respuesta http.ResponseWriter, peticion *http.Request) {
+	if herramienta.clave == nil {
+		http.Error(respuesta, "La herramienta LTI no tiene clave privada (clave_privada)", http.StatusNotFound)
+		return
+	}
+
+	respuesta.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(respuesta).Encode(map[string]interface{}{"keys": []map[string]string{{
+		"kid": obtenerIdentificadorClaveLTI(&herramienta.clave.PublicKey),
+		"kty": "RSA",
+		"alg": "RS256",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(herramienta.clave.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(herramienta.clave.E)).Bytes()),
+	}}})
+}
+
+/*
+ * Función para firmar la respuesta de Deep Linking con el enlace al reporte de un trabajo
+ * param: sesión LTI de Deep Linking y trabajo encolado
+ * return: el JWT firmado (RS256) o un error
+ */
+func (herramienta *HerramientaLTI) firmarRespuestaEnlaces(sesion SesionLTI, trabajo Trabajo) (string, error) {
+	titulo := "Reporte SASC"
+	if sesion.actividad != "" {
+		titulo += " - " + sesion.actividad
+	}
+	enlace := map[string]interface{}{"type": "ltiResourceLink", "title": titulo, "custom": map[string]string{CAMPO_TRABAJO_LTI: trabajo.Identificador}}
+	if sesion.destino != "" {
+		enlace["url"] = sesion.destino
+	}
+
+	ahora := time.Now()
+	reclamos := map[string]interface{}{
+		"iss":                      sesion.plataforma.IdentificadorCliente,
+		"aud":                      sesion.plataforma.Emisor,
+		"iat":                      ahora.Unix(),
+		"exp":                      ahora.Add(VALIDEZ_RESPUESTA_ENLACES).Unix(),
+		"nonce":                    generarIdentificadorAleatorio(),
+		RECLAMO_DESPLIEGUE:         sesion.despliegue,
+		RECLAMO_TIPO_MENSAJE:       "LtiDeepLinkingResponse",
+		RECLAMO_VERSION:            "1.3.0",
+		RECLAMO_CONTENIDOS_ENLACES: []interface{}{enlace},
+	}
+	if sesion.datosEnlaces != "" {
+		reclamos[RECLAMO_DATOS_ENLACES] = sesion.datosEnlaces
+	}
+
+	encabezado, err := json.Marshal(map[string]string{"alg": "RS256", "kid": obtenerIdentificadorClaveLTI(&herramienta.clave.PublicKey), "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	contenido, err := json.Marshal(reclamos)
+	if err != nil {
+		return "", err
+	}
+	firmado := base64.RawURLEncoding.EncodeToString(encabezado) + "." + base64.RawURLEncoding.EncodeToString(contenido)
+
+	resumen := sha256.Sum256([]byte(firmado))
+	firma, err := rsa.SignPKCS1v15(rand.Reader, herramienta.clave, crypto.SHA256, resumen[:])
+	if err != nil {
+		return "", err
+	}
+
+	return firmado + "." + base64.RawURLEncoding.EncodeToString(firma), nil
+}
+
+/*
+ * Función para responder a la plataforma con el enlace al reporte de un trabajo (formulario que se envía solo a la
+ * URL de retorno de Deep Linking)
+ * param: respuesta HTTP, sesión LTI de Deep Linking y trabajo encolado
+ */
+func (herramienta *HerramientaLTI) responderEnlaces(respuesta http.ResponseWriter, sesion SesionLTI, trabajo Trabajo) {
+	token, err := herramienta.firmarRespuestaEnlaces(sesion, trabajo)
+	if err != nil {
+		http.Error(respuesta, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respuesta.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(respuesta, "<!DOCTYPE html><html><body onload=\"document.forms[0].submit()\">"+
+		"<form method=\"post\" action=\"%s\"><input type=\"hidden\" name=\"JWT\" value=\"%s\">"+
+		"<p>Trabajo %s encolado. <button type=\"submit\">Agregar el enlace al reporte en la plataforma</button></p>"+
+		"</form></body></html>", html.EscapeString(sesion.retornoEnlaces), token, trabajo.Identificador)
+}
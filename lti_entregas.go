@@ -0,0 +1,106 @@
+/*
+ * Descarga de las entregas de una tarea de Canvas desde un lanzamiento LTI.
+ *
+ * LTI no da acceso a los archivos de las entregas, por lo que la plataforma registrada indica la URL de la API de
+ * Canvas y el archivo con el token de acceso (url_canvas y archivo_token_canvas en --lti-config), y el lanzamiento
+ * indica el curso y la tarea con los campos personalizados de la herramienta (custom fields de la clave de
+ * desarrollador de Canvas):
+ *
+ *     canvas_course_id=$Canvas.course.id
+ *     canvas_assignment_id=$Canvas.assignment.id
+ *
+ * Los adjuntos se empaquetan en un archivo .tar temporal con un directorio por estudiante, el mismo formato del
+ * archivo que sube el docente, y ese archivo se encola como cualquier otro trabajo.
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+// Campos personalizados del lanzamiento con el curso y la tarea de Canvas
+const (
+	CAMPO_CURSO_CANVAS = "canvas_course_id"
+	CAMPO_TAREA_CANVAS = "canvas_assignment_id"
+)
+
+/*
+ * Función para leer el token de acceso a la API de Canvas de una plataforma
+ * param: nombre del archivo del token (vacío si la plataforma no descarga de Canvas)
+ * return: el token (vacío si no hay archivo) o un error si no se puede leer
+ */
+func leerTokenCanvasLTI(nombreArchivo string) (string, error) {
+	if nombreArchivo == "" {
+		return "", nil
+	}
+
+	contenido, err := ioutil.ReadFile(nombreArchivo)
+	if err != nil {
+		return "", fmt.Errorf("No se puede leer el token de Canvas de la configuración LTI (archivo_token_canvas): %v", err)
+	}
+
+	return strings.TrimSpace(string(contenido)), nil
+}
+
+/*
+ * Función para determinar si las entregas de una sesión se pueden descargar de Canvas
+ * return: verdadero si la plataforma tiene la API de Canvas y el lanzamiento indicó el curso y la tarea
+ */
+func (sesion SesionLTI) descargaCanvas() bool {
+	return sesion.plataforma.URLCanvas != "" && sesion.plataforma.tokenCanvas != "" && sesion.cursoCanvas != "" && sesion.tareaCanvas != ""
+}
+
+/*
+ * Función para descargar las entregas de la tarea de Canvas de una sesión en un archivo .tar temporal
+ * param: sesión LTI y tamaño máximo en bytes de las entregas
+ * return: el archivo .tar al inicio (se debe cerrar y eliminar) o un error de la API o si se supera el tamaño máximo
+ */
+func descargarEntregasLTI(sesion SesionLTI, tamanoMaximo int64) (*os.File, error) {
+	archivo, err := ioutil.TempFile("", "sasc-canvas-*.tar")
+	if err != nil {
+		return nil, err
+	}
+
+	empaquetador := tar.NewWriter(archivo)
+	var total int64
+
+	estudiantes, err := recorrerAdjuntosCanvas(sesion.plataforma.URLCanvas, sesion.plataforma.tokenCanvas, sesion.cursoCanvas, sesion.tareaCanvas, func(estudiante string, nombre string, contenido io.Reader) error {
+		datos, err := io.ReadAll(io.LimitReader(contenido, tamanoMaximo-total+1))
+		if err != nil {
+			return err
+		}
+		total += int64(len(datos))
+		if total > tamanoMaximo {
+			return fmt.Errorf("Las entregas de Canvas superan el tamaño máximo de %d bytes", tamanoMaximo)
+		}
+
+		if err = empaquetador.WriteHeader(&tar.Header{Name: estudiante + "/" + nombre, Mode: 0644, Size: int64(len(datos)), ModTime: time.Now()}); err != nil {
+			return err
+		}
+		_, err = empaquetador.Write(datos)
+		return err
+	})
+	if err == nil && estudiantes == 0 {
+		err = fmt.Errorf("La tarea de Canvas no tiene entregas con archivos adjuntos")
+	}
+	if err == nil {
+		err = empaquetador.Close()
+	}
+	if err == nil {
+		_, err = archivo.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		archivo.Close()
+		os.Remove(archivo.Name())
+		return nil, err
+	}
+
+	return archivo, nil
+}
@@ -0,0 +1,680 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	EMISOR_PRUEBA     = "https://lms.ejemplo.edu"
+	DESPLIEGUE_PRUEBA = "1:despliegue"
+)
+
+// Estructura de una plataforma LTI simulada con su conjunto de claves públicas
+// - clave privada con la que firma los id_token e identificador de la clave (kid)
+// - servidor del conjunto de claves (JWKS) y cantidad de consultas recibidas
+type PlataformaPrueba struct {
+	clave         *rsa.PrivateKey
+	identificador string
+	servidor      *httptest.Server
+	consultas     int32
+}
+
+/*
+ * Función para crear una plataforma LTI simulada con una clave RSA y su servidor JWKS
+ * param: prueba
+ * return: la plataforma simulada (el servidor se cierra al terminar la prueba)
+ */
+func crearPlataformaPrueba(t *testing.T) *PlataformaPrueba {
+	t.Helper()
+
+	clave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plataforma := &PlataformaPrueba{clave: clave, identificador: "clave1"}
+	plataforma.servidor = httptest.NewServer(http.HandlerFunc(func(respuesta http.ResponseWriter, peticion *http.Request) {
+		atomic.AddInt32(&plataforma.consultas, 1)
+		json.NewEncoder(respuesta).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": plataforma.identificador,
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(clave.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(clave.E)).Bytes()),
+		}}})
+	}))
+	t.Cleanup(plataforma.servidor.Close)
+
+	return plataforma
+}
+
+/*
+ * Función para firmar un id_token con la clave de la plataforma simulada
+ * param: prueba, clave privada, identificador de la clave y reclamos
+ * return: el id_token firmado (RS256)
+ */
+func firmarTokenPrueba(t *testing.T, clave *rsa.PrivateKey, identificador string, reclamos map[string]interface{}) string {
+	t.Helper()
+
+	encabezado, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": identificador, "typ": "JWT"})
+	contenido, _ := json.Marshal(reclamos)
+	firmado := base64.RawURLEncoding.EncodeToString(encabezado) + "." + base64.RawURLEncoding.EncodeToString(contenido)
+
+	resumen := sha256.Sum256([]byte(firmado))
+	firma, err := rsa.SignPKCS1v15(rand.Reader, clave, crypto.SHA256, resumen[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return firmado + "." + base64.RawURLEncoding.EncodeToString(firma)
+}
+
+/*
+ * Función para crear los reclamos de un lanzamiento válido de un docente
+ * param: identificador del cliente y nonce del login
+ * return: reclamos del id_token
+ */
+func crearReclamosPrueba(identificadorCliente string, nonce string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":                EMISOR_PRUEBA,
+		"aud":                identificadorCliente,
+		"sub":                "docente1",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"nonce":              nonce,
+		RECLAMO_TIPO_MENSAJE: "LtiResourceLinkRequest",
+		RECLAMO_DESPLIEGUE:   DESPLIEGUE_PRUEBA,
+		RECLAMO_ROLES:        []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor"},
+		RECLAMO_CONTEXTO:     map[string]string{"id": "curso1", "title": "Programación"},
+		RECLAMO_ENLACE:       map[string]string{"title": "Taller 1"},
+	}
+}
+
+func TestBuscarPlataforma(t *testing.T) {
+	herramienta := &HerramientaLTI{configuracion: ConfiguracionLTI{Plataformas: []PlataformaLTI{
+		{Emisor: EMISOR_PRUEBA, IdentificadorCliente: "A"},
+		{Emisor: EMISOR_PRUEBA, IdentificadorCliente: "B", Despliegues: []string{"1:b"}},
+		{Emisor: "https://otra.edu", IdentificadorCliente: "A"},
+	}}}
+
+	casos := []struct {
+		emisor, cliente, despliegue string
+		esperado                    string
+	}{
+		{EMISOR_PRUEBA, "A", "", "A"},
+		{EMISOR_PRUEBA, "B", "1:b", "B"},
+		{EMISOR_PRUEBA, "B", "1:otro", ""},
+		{EMISOR_PRUEBA, "", "1:otro", "A"},
+		{EMISOR_PRUEBA, "", "", ""},
+		{EMISOR_PRUEBA, "C", "", ""},
+		{"https://desconocida.edu", "A", "", ""},
+	}
+
+	for _, caso := range casos {
+		plataforma, err := herramienta.buscarPlataforma(caso.emisor, caso.cliente, caso.despliegue)
+		if caso.esperado == "" && err == nil {
+			t.Errorf("buscarPlataforma(%s, %q, %q) = %s, se esperaba un error", caso.emisor, caso.cliente, caso.despliegue, plataforma.IdentificadorCliente)
+		}
+		if caso.esperado != "" && (err != nil || plataforma.IdentificadorCliente != caso.esperado || plataforma.Emisor != caso.emisor) {
+			t.Errorf("buscarPlataforma(%s, %q, %q) = %v, %v, se esperaba el cliente %s", caso.emisor, caso.cliente, caso.despliegue, plataforma, err, caso.esperado)
+		}
+	}
+}
+
+func TestVerificarTokenLTI(t *testing.T) {
+	simulada := crearPlataformaPrueba(t)
+	otraClave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plataforma := PlataformaLTI{Emisor: EMISOR_PRUEBA, IdentificadorCliente: "A", Despliegues: []string{DESPLIEGUE_PRUEBA}, URLClaves: simulada.servidor.URL}
+	claves := crearClavesPlataformas()
+
+	casos := []struct {
+		nombre   string
+		cambiar  func(map[string]interface{})
+		clave    *rsa.PrivateKey
+		valido   bool
+		esperado string
+	}{
+		{"válido", func(map[string]interface{}) {}, nil, true, ""},
+		{"audiencia en arreglo", func(r map[string]interface{}) { r["aud"] = []string{"A", "otro"}; r["azp"] = "A" }, nil, true, ""},
+		{"otra audiencia", func(r map[string]interface{}) { r["aud"] = "B" }, nil, false, "Audiencia"},
+		{"azp de otro cliente", func(r map[string]interface{}) { r["aud"] = []string{"A", "B"}; r["azp"] = "B" }, nil, false, "azp"},
+		{"otro emisor", func(r map[string]interface{}) { r["iss"] = "https://otra.edu" }, nil, false, "Emisor"},
+		{"otro despliegue", func(r map[string]interface{}) { r[RECLAMO_DESPLIEGUE] = "1:otro" }, nil, false, "Despliegue"},
+		{"sin despliegue", func(r map[string]interface{}) { delete(r, RECLAMO_DESPLIEGUE) }, nil, false, "Despliegue"},
+		{"expirado", func(r map[string]interface{}) { r["exp"] = time.Now().Add(-time.Minute).Unix() }, nil, false, "expirado"},
+		{"otro nonce", func(r map[string]interface{}) { r["nonce"] = "otro" }, nil, false, "nonce"},
+		{"Deep Linking", func(r map[string]interface{}) { r[RECLAMO_TIPO_MENSAJE] = "LtiDeepLinkingRequest" }, nil, true, ""},
+		{"otro mensaje", func(r map[string]interface{}) { r[RECLAMO_TIPO_MENSAJE] = "LtiSubmissionReviewRequest" }, nil, false, "mensaje"},
+		{"firma de otra clave", func(map[string]interface{}) {}, otraClave, false, "Firma"},
+	}
+
+	for _, caso := range casos {
+		reclamos := crearReclamosPrueba("A", "n1")
+		caso.cambiar(reclamos)
+		clave := simulada.clave
+		if caso.clave != nil {
+			clave = caso.clave
+		}
+
+		_, err := verificarTokenLTI(firmarTokenPrueba(t, clave, simulada.identificador, reclamos), plataforma, "n1", claves)
+		if caso.valido && err != nil {
+			t.Errorf("%s: error = %v", caso.nombre, err)
+		}
+		if !caso.valido && (err == nil || !strings.Contains(err.Error(), caso.esperado)) {
+			t.Errorf("%s: error = %v, se esperaba un error de %s", caso.nombre, err, caso.esperado)
+		}
+	}
+
+	if _, err := verificarTokenLTI("a.b", plataforma, "n1", claves); err == nil {
+		t.Error("un id_token mal formado no debe ser válido")
+	}
+	sinFirma := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(`{}`)) + "."
+	if _, err := verificarTokenLTI(sinFirma, plataforma, "n1", claves); err == nil {
+		t.Error("un id_token sin firma (alg none) no debe ser válido")
+	}
+}
+
+func TestClavesPlataformasEnCache(t *testing.T) {
+	simulada := crearPlataformaPrueba(t)
+	claves := crearClavesPlataformas()
+
+	for i := 0; i < 3; i++ {
+		if _, err := claves.obtener(simulada.servidor.URL, simulada.identificador); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if consultas := atomic.LoadInt32(&simulada.consultas); consultas != 1 {
+		t.Errorf("consultas del conjunto de claves = %d, se esperaba 1", consultas)
+	}
+
+	// Una clave desconocida no provoca otra consulta antes del intervalo mínimo
+	if _, err := claves.obtener(simulada.servidor.URL, "desconocida"); err == nil {
+		t.Error("se esperaba un error con una clave desconocida")
+	}
+	if consultas := atomic.LoadInt32(&simulada.consultas); consultas != 1 {
+		t.Errorf("consultas con una clave desconocida = %d, se esperaba 1", consultas)
+	}
+
+	// Después del intervalo, una clave nueva (rotación de claves) se consulta de nuevo
+	simulada.identificador = "clave2"
+	claves.consultado[simulada.servidor.URL] = time.Now().Add(-INTERVALO_CLAVES_LTI)
+	if _, err := claves.obtener(simulada.servidor.URL, "clave2"); err != nil {
+		t.Errorf("clave rotada: %v", err)
+	}
+	if consultas := atomic.LoadInt32(&simulada.consultas); consultas != 2 {
+		t.Errorf("consultas después de la rotación = %d, se esperaban 2", consultas)
+	}
+}
+
+func TestClavesPlataformasTiempoMaximo(t *testing.T) {
+	detenido := make(chan struct{})
+	lento := httptest.NewServer(http.HandlerFunc(func(respuesta http.ResponseWriter, peticion *http.Request) {
+		<-detenido
+	}))
+	defer lento.Close()
+	defer close(detenido)
+
+	claves := crearClavesPlataformas()
+	claves.cliente.Timeout = 50 * time.Millisecond
+
+	inicio := time.Now()
+	if _, err := claves.obtener(lento.URL, "clave1"); err == nil {
+		t.Error("se esperaba un error por el tiempo máximo de la consulta")
+	}
+	if duracion := time.Since(inicio); duracion > 5*time.Second {
+		t.Errorf("la consulta tardó %v", duracion)
+	}
+}
+
+/*
+ * Función para iniciar el login OIDC en la herramienta
+ * param: prueba, servidor de la herramienta y parámetros del login
+ * return: state y nonce enviados a la plataforma
+ */
+func iniciarLoginPrueba(t *testing.T, servidor *httptest.Server, parametros url.Values) (string, string) {
+	t.Helper()
+
+	cliente := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	respuesta, err := cliente.PostForm(servidor.URL+"/lti/login", parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	respuesta.Body.Close()
+	if respuesta.StatusCode != http.StatusFound {
+		t.Fatalf("login = %d, se esperaba una redirección", respuesta.StatusCode)
+	}
+
+	destino, err := url.Parse(respuesta.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return destino.Query().Get("state"), destino.Query().Get("nonce")
+}
+
+func TestLanzamientoLTIPorCliente(t *testing.T) {
+	simulada := crearPlataformaPrueba(t)
+	herramienta := &HerramientaLTI{
+		configuracion: ConfiguracionLTI{Plataformas: []PlataformaLTI{
+			{Emisor: EMISOR_PRUEBA, IdentificadorCliente: "A", URLAutorizacion: "https://lms.ejemplo.edu/auth", URLClaves: "http://127.0.0.1:1/sin-claves"},
+			{Emisor: EMISOR_PRUEBA, IdentificadorCliente: "B", URLAutorizacion: "https://lms.ejemplo.edu/auth", URLClaves: simulada.servidor.URL},
+		}},
+		logins:   make(map[string]LoginLTI),
+		sesiones: make(map[string]SesionLTI),
+		claves:   crearClavesPlataformas(),
+	}
+	rutas := http.NewServeMux()
+	herramienta.registrarRutas(rutas, nil, nil, nil, 1<<20)
+	servidor := httptest.NewServer(rutas)
+	defer servidor.Close()
+
+	lanzar := func(estado string, token string) (int, string) {
+		respuesta, err := http.PostForm(servidor.URL+"/lti/launch", url.Values{"state": {estado}, "id_token": {token}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer respuesta.Body.Close()
+		cuerpo, err := io.ReadAll(respuesta.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return respuesta.StatusCode, string(cuerpo)
+	}
+
+	// Sin client_id en el login, las dos plataformas del emisor son ambiguas
+	respuesta, err := http.PostForm(servidor.URL+"/lti/login", url.Values{"iss": {EMISOR_PRUEBA}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	respuesta.Body.Close()
+	if respuesta.StatusCode != http.StatusBadRequest {
+		t.Errorf("login sin client_id = %d, se esperaba %d", respuesta.StatusCode, http.StatusBadRequest)
+	}
+
+	// El lanzamiento del cliente B se verifica con las claves de B, no con las de la primera plataforma del emisor
+	estado, nonce := iniciarLoginPrueba(t, servidor, url.Values{"iss": {EMISOR_PRUEBA}, "client_id": {"B"}, "target_link_uri": {servidor.URL + "/lti/launch"}})
+	if codigo, cuerpo := lanzar(estado, firmarTokenPrueba(t, simulada.clave, simulada.identificador, crearReclamosPrueba("B", nonce))); codigo != http.StatusOK || !strings.Contains(cuerpo, "Taller 1") {
+		t.Errorf("lanzamiento del cliente B = %d %s", codigo, cuerpo)
+	}
+
+	// Un token del cliente A no puede usar el login del cliente B
+	estado, nonce = iniciarLoginPrueba(t, servidor, url.Values{"iss": {EMISOR_PRUEBA}, "client_id": {"B"}})
+	if codigo, _ := lanzar(estado, firmarTokenPrueba(t, simulada.clave, simulada.identificador, crearReclamosPrueba("A", nonce))); codigo != http.StatusBadRequest {
+		t.Errorf("lanzamiento del cliente A con el login de B = %d, se esperaba %d", codigo, http.StatusBadRequest)
+	}
+
+	// El state solo se puede usar una vez
+	estado, nonce = iniciarLoginPrueba(t, servidor, url.Values{"iss": {EMISOR_PRUEBA}, "client_id": {"B"}})
+	token := firmarTokenPrueba(t, simulada.clave, simulada.identificador, crearReclamosPrueba("B", nonce))
+	lanzar(estado, token)
+	if codigo, _ := lanzar(estado, token); codigo != http.StatusBadRequest {
+		t.Errorf("segundo lanzamiento con el mismo state = %d, se esperaba %d", codigo, http.StatusBadRequest)
+	}
+}
+
+func TestTrabajoLTIConEntregasSubidas(t *testing.T) {
+	cola, err := crearColaTrabajos(1, 10, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	herramienta := &HerramientaLTI{logins: make(map[string]LoginLTI), sesiones: make(map[string]SesionLTI), claves: crearClavesPlataformas()}
+	herramienta.sesiones["s1"] = SesionLTI{usuario: "docente1", curso: "curso1", titulo: "Programación", actividad: "Taller 1", expira: time.Now().Add(time.Hour)}
+	herramienta.sesiones["s2"] = SesionLTI{usuario: "docente2", curso: "curso2", expira: time.Now().Add(time.Hour)}
+
+	recibidas := make(chan string, 1)
+	analizar := func(lector io.Reader) (ResultadoAnalisisJSON, error) {
+		contenido, _ := io.ReadAll(lector)
+		recibidas <- string(contenido)
+		return ResultadoAnalisisJSON{Archivos: []string{"ana/Main.java", "luis/Main.java"}}, nil
+	}
+
+	rutas := http.NewServeMux()
+	herramienta.registrarRutas(rutas, cola, analizar, nil, 1<<20)
+	servidor := httptest.NewServer(rutas)
+	defer servidor.Close()
+
+	subir := func(sesion string, entregas string) *http.Response {
+		var cuerpo bytes.Buffer
+		formulario := multipart.NewWriter(&cuerpo)
+		if entregas != "" {
+			archivo, _ := formulario.CreateFormFile("entregas", "entregas.tar")
+			archivo.Write([]byte(entregas))
+		}
+		formulario.Close()
+
+		cliente := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		respuesta, err := cliente.Post(servidor.URL+"/lti/jobs?session="+sesion, formulario.FormDataContentType(), &cuerpo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		respuesta.Body.Close()
+		return respuesta
+	}
+
+	if respuesta := subir("otra", "tar"); respuesta.StatusCode != http.StatusUnauthorized {
+		t.Errorf("subida sin sesión = %d, se esperaba %d", respuesta.StatusCode, http.StatusUnauthorized)
+	}
+	if respuesta := subir("s1", ""); respuesta.StatusCode != http.StatusBadRequest {
+		t.Errorf("subida sin archivo = %d, se esperaba %d", respuesta.StatusCode, http.StatusBadRequest)
+	}
+
+	respuesta := subir("s1", "contenido del tar")
+	if respuesta.StatusCode != http.StatusSeeOther || !strings.HasPrefix(respuesta.Header.Get("Location"), "/lti/report/") {
+		t.Fatalf("subida = %d %s, se esperaba la redirección al reporte", respuesta.StatusCode, respuesta.Header.Get("Location"))
+	}
+	if entregas := <-recibidas; entregas != "contenido del tar" {
+		t.Errorf("entregas analizadas = %q", entregas)
+	}
+
+	reporte := servidor.URL + respuesta.Header.Get("Location")
+	var cuerpo string
+	for intento := 0; intento < 100 && !strings.Contains(cuerpo, TRABAJO_TERMINADO); intento++ {
+		time.Sleep(10 * time.Millisecond)
+		consulta, err := http.Get(reporte)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contenido, _ := io.ReadAll(consulta.Body)
+		consulta.Body.Close()
+		cuerpo = string(contenido)
+	}
+	if !strings.Contains(cuerpo, "2 archivos analizados") {
+		t.Errorf("reporte = %s", cuerpo)
+	}
+
+	// El reporte de un curso no se puede consultar con la sesión de otro curso
+	otroCurso := strings.Replace(reporte, "session=s1", "session=s2", 1)
+	consulta, err := http.Get(otroCurso)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consulta.Body.Close()
+	if consulta.StatusCode != http.StatusNotFound {
+		t.Errorf("reporte con la sesión de otro curso = %d, se esperaba %d", consulta.StatusCode, http.StatusNotFound)
+	}
+}
+
+/*
+ * Función para crear una API de Canvas simulada con las entregas de una tarea
+ * param: prueba, token esperado y archivos adjuntos por usuario (login) y nombre del archivo
+ * return: el servidor simulado (se cierra al terminar la prueba)
+ */
+func crearCanvasPrueba(t *testing.T, token string, adjuntos map[string]map[string]string) *httptest.Server {
+	t.Helper()
+
+	var servidor *httptest.Server
+	servidor = httptest.NewServer(http.HandlerFunc(func(respuesta http.ResponseWriter, peticion *http.Request) {
+		if peticion.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(respuesta, "token no válido", http.StatusUnauthorized)
+			return
+		}
+
+		if strings.HasPrefix(peticion.URL.Path, "/archivos/") {
+			partes := strings.SplitN(strings.TrimPrefix(peticion.URL.Path, "/archivos/"), "/", 2)
+			io.WriteString(respuesta, adjuntos[partes[0]][partes[1]])
+			return
+		}
+		if peticion.URL.Path != "/api/v1/courses/77/assignments/88/submissions" {
+			http.NotFound(respuesta, peticion)
+			return
+		}
+
+		var entregas []map[string]interface{}
+		for usuario, archivos := range adjuntos {
+			var lista []map[string]string
+			for nombre := range archivos {
+				lista = append(lista, map[string]string{"filename": nombre, "url": servidor.URL + "/archivos/" + usuario + "/" + nombre})
+			}
+			entregas = append(entregas, map[string]interface{}{"user": map[string]string{"login_id": usuario}, "attachments": lista})
+		}
+		json.NewEncoder(respuesta).Encode(entregas)
+	}))
+	t.Cleanup(servidor.Close)
+
+	return servidor
+}
+
+func TestTrabajoLTIConEntregasCanvas(t *testing.T) {
+	canvas := crearCanvasPrueba(t, "token-canvas", map[string]map[string]string{
+		"ana":  {"Main.java": "class Main {}\n"},
+		"luis": {"Main.java": "class Main { int x; }\n"},
+	})
+
+	cola, err := crearColaTrabajos(1, 10, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plataforma := PlataformaLTI{Emisor: EMISOR_PRUEBA, URLCanvas: canvas.URL, tokenCanvas: "token-canvas"}
+	herramienta := &HerramientaLTI{logins: make(map[string]LoginLTI), sesiones: make(map[string]SesionLTI), claves: crearClavesPlataformas()}
+	herramienta.sesiones["s1"] = SesionLTI{plataforma: plataforma, usuario: "docente1", curso: "curso1", cursoCanvas: "77", tareaCanvas: "88", expira: time.Now().Add(time.Hour)}
+	herramienta.sesiones["s2"] = SesionLTI{plataforma: plataforma, usuario: "docente1", curso: "curso1", expira: time.Now().Add(time.Hour)}
+	plataforma.tokenCanvas = "otro"
+	herramienta.sesiones["s3"] = SesionLTI{plataforma: plataforma, usuario: "docente1", curso: "curso1", cursoCanvas: "77", tareaCanvas: "88", expira: time.Now().Add(time.Hour)}
+
+	recibidas := make(chan map[string]string, 1)
+	analizar := func(lector io.Reader) (ResultadoAnalisisJSON, error) {
+		archivos := make(map[string]string)
+		desempaquetador := tar.NewReader(lector)
+		for {
+			encabezado, err := desempaquetador.Next()
+			if err != nil {
+				break
+			}
+			contenido, _ := io.ReadAll(desempaquetador)
+			archivos[encabezado.Name] = string(contenido)
+		}
+		recibidas <- archivos
+		return ResultadoAnalisisJSON{}, nil
+	}
+
+	rutas := http.NewServeMux()
+	herramienta.registrarRutas(rutas, cola, analizar, nil, 1<<20)
+	servidor := httptest.NewServer(rutas)
+	defer servidor.Close()
+
+	cliente := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	descargar := func(sesion string) *http.Response {
+		respuesta, err := cliente.Post(servidor.URL+"/lti/jobs?source=canvas&session="+sesion, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		respuesta.Body.Close()
+		return respuesta
+	}
+
+	if respuesta := descargar("s2"); respuesta.StatusCode != http.StatusBadRequest {
+		t.Errorf("descarga sin tarea de Canvas = %d, se esperaba %d", respuesta.StatusCode, http.StatusBadRequest)
+	}
+	if respuesta := descargar("s3"); respuesta.StatusCode != http.StatusBadGateway {
+		t.Errorf("descarga con un token rechazado por Canvas = %d, se esperaba %d", respuesta.StatusCode, http.StatusBadGateway)
+	}
+
+	respuesta := descargar("s1")
+	if respuesta.StatusCode != http.StatusSeeOther || !strings.HasPrefix(respuesta.Header.Get("Location"), "/lti/report/") {
+		t.Fatalf("descarga = %d %s, se esperaba la redirección al reporte", respuesta.StatusCode, respuesta.Header.Get("Location"))
+	}
+	esperadas := map[string]string{"ana/Main.java": "class Main {}\n", "luis/Main.java": "class Main { int x; }\n"}
+	if archivos := <-recibidas; !reflect.DeepEqual(archivos, esperadas) {
+		t.Errorf("entregas analizadas = %v, se esperaba %v", archivos, esperadas)
+	}
+
+	// Las entregas de Canvas también respetan el tamaño máximo
+	herramienta = &HerramientaLTI{logins: make(map[string]LoginLTI), sesiones: herramienta.sesiones, claves: crearClavesPlataformas()}
+	rutas = http.NewServeMux()
+	herramienta.registrarRutas(rutas, cola, analizar, nil, 20)
+	limitado := httptest.NewServer(rutas)
+	defer limitado.Close()
+
+	descargado, err := cliente.Post(limitado.URL+"/lti/jobs?source=canvas&session=s1", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	descargado.Body.Close()
+	if descargado.StatusCode != http.StatusBadGateway {
+		t.Errorf("descarga mayor al tamaño máximo = %d, se esperaba %d", descargado.StatusCode, http.StatusBadGateway)
+	}
+}
+
+func TestLanzamientoLTIConEnlaces(t *testing.T) {
+	simulada := crearPlataformaPrueba(t)
+
+	// Configuración con la clave privada de la herramienta en un archivo PEM
+	claveHerramienta, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	directorio := t.TempDir()
+	archivoClave := filepath.Join(directorio, "lti.pem")
+	if err = os.WriteFile(archivoClave, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(claveHerramienta)}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	configuracion, _ := json.Marshal(ConfiguracionLTI{ClavePrivada: archivoClave, Plataformas: []PlataformaLTI{
+		{Emisor: EMISOR_PRUEBA, IdentificadorCliente: "A", URLAutorizacion: "https://lms.ejemplo.edu/auth", URLClaves: simulada.servidor.URL},
+	}})
+	archivoConfiguracion := filepath.Join(directorio, "lti.json")
+	if err = os.WriteFile(archivoConfiguracion, configuracion, 0600); err != nil {
+		t.Fatal(err)
+	}
+	herramienta, err := crearHerramientaLTI(archivoConfiguracion)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cola, err := crearColaTrabajos(1, 10, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	analizar := func(lector io.Reader) (ResultadoAnalisisJSON, error) { return ResultadoAnalisisJSON{}, nil }
+
+	rutas := http.NewServeMux()
+	herramienta.registrarRutas(rutas, cola, analizar, nil, 1<<20)
+	servidor := httptest.NewServer(rutas)
+	defer servidor.Close()
+
+	cliente := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	lanzar := func(cambiar func(map[string]interface{})) (*http.Response, string) {
+		estado, nonce := iniciarLoginPrueba(t, servidor, url.Values{"iss": {EMISOR_PRUEBA}, "client_id": {"A"}})
+		reclamos := crearReclamosPrueba("A", nonce)
+		cambiar(reclamos)
+
+		respuesta, err := cliente.PostForm(servidor.URL+"/lti/launch", url.Values{"state": {estado}, "id_token": {firmarTokenPrueba(t, simulada.clave, simulada.identificador, reclamos)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer respuesta.Body.Close()
+		cuerpo, _ := io.ReadAll(respuesta.Body)
+		return respuesta, string(cuerpo)
+	}
+
+	// El lanzamiento de Deep Linking abre la sesión para subir las entregas
+	_, cuerpo := lanzar(func(r map[string]interface{}) {
+		r[RECLAMO_TIPO_MENSAJE] = "LtiDeepLinkingRequest"
+		r[RECLAMO_DESTINO] = servidor.URL + "/lti/launch"
+		r[RECLAMO_AJUSTES_ENLACES] = map[string]string{"deep_link_return_url": "https://lms.ejemplo.edu/deep_links", "data": "datos-plataforma"}
+		delete(r, RECLAMO_ENLACE)
+	})
+	sesion := regexp.MustCompile(`session=([0-9a-f]+)`).FindStringSubmatch(cuerpo)
+	if sesion == nil {
+		t.Fatalf("lanzamiento de Deep Linking sin sesión: %s", cuerpo)
+	}
+
+	var entregas bytes.Buffer
+	formulario := multipart.NewWriter(&entregas)
+	archivo, _ := formulario.CreateFormFile("entregas", "entregas.tar")
+	archivo.Write([]byte("contenido del tar"))
+	formulario.Close()
+	respuesta, err := cliente.Post(servidor.URL+"/lti/jobs?session="+sesion[1], formulario.FormDataContentType(), &entregas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contenido, _ := io.ReadAll(respuesta.Body)
+	respuesta.Body.Close()
+
+	// La respuesta envía a la plataforma el JWT firmado con el enlace al trabajo
+	if !strings.Contains(string(contenido), "action=\"https://lms.ejemplo.edu/deep_links\"") {
+		t.Fatalf("respuesta de Deep Linking sin la URL de retorno: %s", contenido)
+	}
+	token := regexp.MustCompile(`name="JWT" value="([^"]+)"`).FindStringSubmatch(string(contenido))
+	if token == nil {
+		t.Fatalf("respuesta de Deep Linking sin el JWT: %s", contenido)
+	}
+	partes := strings.Split(token[1], ".")
+	resumen := sha256.Sum256([]byte(partes[0] + "." + partes[1]))
+	firma, _ := base64.RawURLEncoding.DecodeString(partes[2])
+	if err = rsa.VerifyPKCS1v15(&claveHerramienta.PublicKey, crypto.SHA256, resumen[:], firma); err != nil {
+		t.Fatalf("firma de la respuesta de Deep Linking: %v", err)
+	}
+
+	var reclamos struct {
+		Emisor     string `json:"iss"`
+		Audiencia  string `json:"aud"`
+		Tipo       string `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+		Despliegue string `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+		Datos      string `json:"https://purl.imsglobal.org/spec/lti-dl/claim/data"`
+		Contenidos []struct {
+			Tipo         string            `json:"type"`
+			URL          string            `json:"url"`
+			Personalizar map[string]string `json:"custom"`
+		} `json:"https://purl.imsglobal.org/spec/lti-dl/claim/content_items"`
+	}
+	reclamosJSON, _ := base64.RawURLEncoding.DecodeString(partes[1])
+	if err = json.Unmarshal(reclamosJSON, &reclamos); err != nil {
+		t.Fatal(err)
+	}
+	if reclamos.Emisor != "A" || reclamos.Audiencia != EMISOR_PRUEBA || reclamos.Tipo != "LtiDeepLinkingResponse" || reclamos.Despliegue != DESPLIEGUE_PRUEBA || reclamos.Datos != "datos-plataforma" {
+		t.Errorf("reclamos de la respuesta de Deep Linking = %+v", reclamos)
+	}
+	if len(reclamos.Contenidos) != 1 || reclamos.Contenidos[0].Tipo != "ltiResourceLink" || reclamos.Contenidos[0].URL != servidor.URL+"/lti/launch" {
+		t.Fatalf("contenidos de la respuesta de Deep Linking = %+v", reclamos.Contenidos)
+	}
+	trabajo := reclamos.Contenidos[0].Personalizar[CAMPO_TRABAJO_LTI]
+	if _, existe := cola.consultar(trabajo); !existe {
+		t.Fatalf("el enlace indica el trabajo %q, que no está en la cola", trabajo)
+	}
+
+	// La clave pública de la herramienta se publica para que la plataforma verifique la respuesta
+	claves, err := consultarClavesPlataforma(http.DefaultClient, servidor.URL+"/lti/jwks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var encabezado struct {
+		Identificador string `json:"kid"`
+	}
+	encabezadoJSON, _ := base64.RawURLEncoding.DecodeString(partes[0])
+	json.Unmarshal(encabezadoJSON, &encabezado)
+	if publica, existe := claves[encabezado.Identificador]; !existe || publica.N.Cmp(claveHerramienta.N) != 0 {
+		t.Errorf("/lti/jwks no publica la clave %q de la respuesta", encabezado.Identificador)
+	}
+
+	// Al abrir el enlace desde la plataforma, el lanzamiento lleva al reporte del trabajo
+	respuesta, _ = lanzar(func(r map[string]interface{}) {
+		r[RECLAMO_PERSONALIZADO] = map[string]string{CAMPO_TRABAJO_LTI: trabajo}
+	})
+	if destino := respuesta.Header.Get("Location"); respuesta.StatusCode != http.StatusSeeOther || !strings.HasPrefix(destino, "/lti/report/"+trabajo+"?session=") {
+		t.Errorf("lanzamiento del enlace = %d %s, se esperaba la redirección al reporte", respuesta.StatusCode, destino)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Marca institucional de los reportes HTML (--branding).
+ *
+ * Los reportes HTML (el reporte estático de --bundle y la cola de revisión de --review-html) pueden terminar en
+ * procesos disciplinarios u otros documentos oficiales, por lo que la institución puede agregarles su logo, el
+ * nombre del curso y un texto al pie (por ejemplo un aviso sobre el alcance del reporte) con un archivo JSON:
+ *
+ *     {"logo": "logo.png", "curso": "Programación I - 2021-2", "pie": "Este reporte es un indicio, no una prueba."}
+ *
+ * El logo puede ser una URL (http, https o data:) o un archivo local, que se incrusta en el HTML (como data:)
+ * para que el reporte siga siendo un documento autocontenido. Todos los campos son opcionales.
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// Estructura de la marca institucional de los reportes
+// - logo (URL o archivo local), nombre del curso y texto al pie
+// - dirección del logo en el HTML (la URL o el archivo incrustado como data:)
+type MarcaReporte struct {
+	Logo       string `json:"logo"`
+	Curso      string `json:"curso"`
+	Pie        string `json:"pie"`
+	fuenteLogo string
+}
+
+/*
+ * Función para cargar la marca institucional de los reportes
+ * param: nombre del archivo JSON (vacío si no se usa)
+ * return: la marca (vacía si no se indica el archivo) o un error si no se puede leer o no es válida
+ */
+func cargarMarcaReporte(nombreArchivo string) (MarcaReporte, error) {
+	var marca MarcaReporte
+
+	if nombreArchivo == "" {
+		return marca, nil
+	}
+
+	contenido, err := ioutil.ReadFile(nombreArchivo)
+	if err != nil {
+		return marca, fmt.Errorf("No se puede leer la marca de los reportes (--branding): %v", err)
+	}
+
+	if err = json.Unmarshal(contenido, &marca); err != nil {
+		return marca, fmt.Errorf("Marca de los reportes (--branding) no válida: %v", err)
+	}
+
+	switch {
+	case marca.Logo == "":
+	case strings.HasPrefix(marca.Logo, "http://") || strings.HasPrefix(marca.Logo, "https://") || strings.HasPrefix(marca.Logo, "data:"):
+		marca.fuenteLogo = marca.Logo
+	default:
+		// La ruta del logo es relativa al archivo de la marca
+		ruta := marca.Logo
+		if !filepath.IsAbs(ruta) {
+			ruta = filepath.Join(filepath.Dir(nombreArchivo), ruta)
+		}
+
+		imagen, err := ioutil.ReadFile(ruta)
+		if err != nil {
+			return marca, fmt.Errorf("No se puede leer el logo de la marca de los reportes (--branding): %v", err)
+		}
+
+		tipo := mime.TypeByExtension(filepath.Ext(ruta))
+		if tipo == "" {
+			tipo = http.DetectContentType(imagen)
+		}
+		marca.fuenteLogo = "data:" + tipo + ";base64," + base64.StdEncoding.EncodeToString(imagen)
+	}
+
+	return marca, nil
+}
+
+/*
+ * Función para obtener el encabezado HTML de la marca (logo y nombre del curso)
+ * return: el encabezado, vacío si la marca no tiene logo ni curso
+ */
+func (marca MarcaReporte) encabezado() string {
+	if marca.fuenteLogo == "" && marca.Curso == "" {
+		return ""
+	}
+
+	encabezado := "<header>"
+	if marca.fuenteLogo != "" {
+		encabezado += fmt.Sprintf("<img src=\"%s\" alt=\"Logo\" style=\"max-height:64px\">", html.EscapeString(marca.fuenteLogo))
+	}
+	if marca.Curso != "" {
+		encabezado += fmt.Sprintf("<p><strong>%s</strong></p>", html.EscapeString(marca.Curso))
+	}
+
+	return encabezado + "</header>\n"
+}
+
+/*
+ * Función para obtener el pie HTML de la marca
+ * return: el pie, vacío si la marca no tiene texto al pie
+ */
+func (marca MarcaReporte) pie() string {
+	if marca.Pie == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("<footer><hr><p><small>%s</small></p></footer>\n", html.EscapeString(marca.Pie))
+}
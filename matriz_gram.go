@@ -0,0 +1,150 @@
+/*
+ * Cálculo de la matriz de distancias con la matriz de Gram (--gram).
+ *
+ * Para los motores de vectores densos (ascii, tokens con --hash-dims o con --reduce) todas las distancias
+ * euclidianas se obtienen de los productos punto entre los vectores (la matriz de Gram G = X * X^T):
+ *
+ *     ‖a - b‖² = ‖a‖² + ‖b‖² - 2 a·b
+ *
+ * Los productos punto se calculan por bloques de filas (como una multiplicación de matrices estilo BLAS), de modo que
+ * cada bloque de vectores se reutiliza desde la memoria caché del procesador en lugar de recorrer la memoria una vez
+ * por par. No se usa gonum porque SASC se compila solo con la biblioteca estándar de Go.
+ *
+ * Con vectores enteros (ascii y --hash-dims) las distancias son exactas mientras las sumas no superen 2^53. Con
+ * vectores reales (--reduce) la resta puede perder precisión cuando dos vectores son casi iguales; las distancias
+ * negativas por redondeo se ajustan a 0.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Cantidad de vectores de cada bloque del producto X * X^T
+const BLOQUE_MATRIZ_GRAM = 64
+
+// Interfaz opcional de los motores cuyas características son un vector denso de reales
+type MotorDenso interface {
+	vectorDenso(codigoFuente CodigoFuente) []float64
+}
+
+func (motor MotorASCII) vectorDenso(codigoFuente CodigoFuente) []float64 {
+	if motor.relativa {
+		return codigoFuente.proyeccion
+	}
+	return convertirVectorReales(codigoFuente.caracteristica)
+}
+
+func (motor MotorTokens) vectorDenso(codigoFuente CodigoFuente) []float64 {
+	if motor.dimensionesHash > 0 && !motor.relativa {
+		return convertirVectorReales(codigoFuente.caracteristica)
+	}
+	return codigoFuente.proyeccion
+}
+
+/*
+ * Función para convertir un vector de enteros en un vector de reales
+ * param: vector de enteros
+ * return: vector de reales con los mismos valores
+ */
+func convertirVectorReales(vector []int) []float64 {
+	reales := make([]float64, len(vector))
+	for i, valor := range vector {
+		reales[i] = float64(valor)
+	}
+
+	return reales
+}
+
+/*
+ * Función para verificar que el motor puede calcular la matriz de distancias con la matriz de Gram
+ * param: parámetros de ejecución
+ * return: un error si el motor no es de vectores densos
+ */
+func validarMatrizGram(parametros Parametros) error {
+	if parametros.motor == "ascii" || (parametros.motor == "tokens" && (parametros.dimensionesHash > 0 || parametros.reduccion != "")) {
+		return nil
+	}
+
+	return fmt.Errorf("La matriz de Gram (--gram) requiere un motor de vectores densos: \"ascii\" o \"tokens\" con --hash-dims o --reduce")
+}
+
+/*
+ * Función para calcular el producto punto de dos vectores de la misma longitud, de a 4 elementos
+ * param: dos vectores
+ * return: producto punto
+ */
+func calcularProductoPunto(v1 []float64, v2 []float64) float64 {
+	v2 = v2[:len(v1)]
+
+	var suma0, suma1, suma2, suma3 float64
+
+	i := 0
+	for ; i+4 <= len(v1); i += 4 {
+		a, b := v1[i:i+4:i+4], v2[i:i+4:i+4]
+		suma0 += a[0] * b[0]
+		suma1 += a[1] * b[1]
+		suma2 += a[2] * b[2]
+		suma3 += a[3] * b[3]
+	}
+	for ; i < len(v1); i++ {
+		suma0 += v1[i] * v2[i]
+	}
+
+	return (suma0 + suma1) + (suma2 + suma3)
+}
+
+/*
+ * Función que determina las distancias entre todos los archivos con la matriz de Gram
+ * param: arreglo de la información de todos los archivos, motor de vectores densos, si se almacenan las distancias cuadradas
+ *        y el flujo de resultados (nil si no se usa)
+ * return: completa la información en el arreglo de código fuente con la distancia a todos los demás (matriz de similaridad)
+ */
+func determinarDistanciasGram(tablaCodigoFuente []CodigoFuente, motor MotorDenso, cuadradas bool, flujo *FlujoResultados) []CodigoFuente {
+	cantidad := len(tablaCodigoFuente)
+
+	vectores := make([][]float64, cantidad)
+	normas := make([]float64, cantidad)
+	for i := range tablaCodigoFuente {
+		vectores[i] = motor.vectorDenso(tablaCodigoFuente[i])
+		normas[i] = calcularProductoPunto(vectores[i], vectores[i])
+	}
+
+	for inicioI := 0; inicioI < cantidad; inicioI += BLOQUE_MATRIZ_GRAM {
+		finI := inicioI + BLOQUE_MATRIZ_GRAM
+		if finI > cantidad {
+			finI = cantidad
+		}
+
+		for inicioJ := 0; inicioJ <= inicioI; inicioJ += BLOQUE_MATRIZ_GRAM {
+			for i := inicioI; i < finI; i++ {
+				finJ := inicioJ + BLOQUE_MATRIZ_GRAM
+				if finJ > i+1 {
+					finJ = i + 1
+				}
+
+				for j := inicioJ; j < finJ; j++ {
+					valor := 0.0
+					if i != j {
+						valor = math.Max(normas[i]+normas[j]-2*calcularProductoPunto(vectores[i], vectores[j]), 0)
+					}
+					if !cuadradas {
+						valor = math.Sqrt(valor)
+					}
+
+					asignarValorDistancia(&tablaCodigoFuente[i], j, valor)
+					asignarValorDistancia(&tablaCodigoFuente[j], i, valor)
+				}
+			}
+		}
+
+		// Las filas del bloque quedan completas al terminar todos los bloques anteriores
+		for i := inicioI; i < finI && flujo != nil; i++ {
+			flujo.enviarFila(tablaCodigoFuente, i)
+		}
+	}
+
+	return tablaCodigoFuente
+}
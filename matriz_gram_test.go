@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalcularProductoPunto(t *testing.T) {
+	// Longitudes con y sin resto de la multiplicación de a 4 elementos
+	for longitud := 0; longitud <= 9; longitud++ {
+		v1, v2 := make([]float64, longitud), make([]float64, longitud+2)
+		esperado := 0.0
+		for i := 0; i < longitud; i++ {
+			v1[i], v2[i] = float64(i+1), float64(2*i-3)
+			esperado += v1[i] * v2[i]
+		}
+
+		if obtenido := calcularProductoPunto(v1, v2); obtenido != esperado {
+			t.Errorf("longitud %d: producto punto = %v, se esperaba %v", longitud, obtenido, esperado)
+		}
+	}
+}
+
+func TestValidarMatrizGram(t *testing.T) {
+	casos := []struct {
+		motor           string
+		dimensionesHash int
+		reduccion       string
+		valido          bool
+	}{
+		{"ascii", 0, "", true},
+		{"tokens", 256, "", true},
+		{"tokens", 0, "pca:8", true},
+		{"tokens", 0, "", false},
+		{"lines", 0, "", false},
+		{"ast", 0, "", false},
+	}
+
+	for _, caso := range casos {
+		parametros := crearParametrosPrueba()
+		parametros.motor, parametros.dimensionesHash, parametros.reduccion = caso.motor, caso.dimensionesHash, caso.reduccion
+
+		if err := validarMatrizGram(parametros); (err == nil) != caso.valido {
+			t.Errorf("%s hash-dims=%d reduce=%q: error = %v", caso.motor, caso.dimensionesHash, caso.reduccion, err)
+		}
+	}
+}
+
+func TestMatrizGramIgualDirecta(t *testing.T) {
+	casos := []struct {
+		motor           string
+		dimensionesHash int
+		cuadradas       bool
+	}{
+		{"ascii", 0, false},
+		{"ascii", 0, true},
+		{"tokens", 256, false},
+	}
+
+	for _, caso := range casos {
+		parametros := crearParametrosPrueba()
+		parametros.motor, parametros.dimensionesHash, parametros.distanciasCuadradas = caso.motor, caso.dimensionesHash, caso.cuadradas
+
+		// 70 archivos: un bloque completo de la matriz de Gram y uno incompleto
+		tablaCodigoFuente, motor := prepararCorpusDistanciasPrueba(t, parametros, 7, 10)
+		directa := calcularMatrizTrabajadoresPrueba(tablaCodigoFuente, motor, parametros, 1)
+
+		parametros.matrizGram = true
+		gram := calcularMatrizTrabajadoresPrueba(tablaCodigoFuente, motor, parametros, 1)
+
+		for i := range directa {
+			for j := range directa[i] {
+				if math.Abs(gram[i][j]-directa[i][j]) > 1e-9*math.Max(1, directa[i][j]) {
+					t.Fatalf("%s cuadradas=%v: distancia %d-%d = %v con la matriz de Gram, %v directa",
+						caso.motor, caso.cuadradas, i, j, gram[i][j], directa[i][j])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Reutilización de la memoria del análisis.
+ *
+ * Con corpus de decenas de miles de archivos, crear por separado el vector de características y la tabla de
+ * distancias de cada archivo significa decenas de miles de asignaciones que el recolector de basura debe recorrer.
+ * - las tablas de distancias de todos los archivos se toman de una única arena (una sola asignación de n * n)
+ * - los vectores de características de tamaño fijo (motor "ascii" y motor "tokens" con --hash-dims) se toman de
+ *   bloques grandes compartidos por muchos archivos
+ * - los arreglos temporales de los hashes de los n-gramas (motor "tokens") se reutilizan entre archivos con un sync.Pool
+ *
+ * Cada análisis tiene su propia arena (no se comparten entre los trabajos del modo servidor) y la memoria se libera
+ * con el resultado del análisis.
+ */
+
+package main
+
+import "sync"
+
+// Cantidad mínima de enteros de cada bloque de la arena de características
+const BLOQUE_ARENA_CARACTERISTICAS = 64 * 1024
+
+// Interfaz opcional de los motores cuyas características son un vector de enteros de longitud fija
+type MotorVectorial interface {
+	dimensiones() int
+}
+
+func (motor MotorASCII) dimensiones() int {
+	if motor.longitudNgrama > 0 {
+		return motor.dimensionesNgramas
+	}
+	return MAX_ASCII
+}
+
+func (motor MotorTokens) dimensiones() int {
+	return motor.dimensionesHash
+}
+
+// Arena de los vectores de características de un análisis
+// - bloque del que se toman los siguientes vectores
+type ArenaCaracteristicas struct {
+	bloque []int
+}
+
+// Arreglos temporales reutilizables de los hashes de los n-gramas
+var poolHashes = sync.Pool{
+	New: func() interface{} {
+		return new([]uint64)
+	},
+}
+
+/*
+ * Función para tomar un vector de la arena, creando un nuevo bloque si el actual no tiene espacio
+ * param: longitud del vector
+ * return: el vector (en ceros y con capacidad igual a su longitud, para que no invada a los demás al crecer)
+ */
+func (arena *ArenaCaracteristicas) obtener(longitud int) []int {
+	if len(arena.bloque) < longitud {
+		tamano := BLOQUE_ARENA_CARACTERISTICAS
+		if longitud > tamano {
+			tamano = longitud
+		}
+		arena.bloque = make([]int, tamano)
+	}
+
+	vector := arena.bloque[:longitud:longitud]
+	arena.bloque = arena.bloque[longitud:]
+
+	return vector
+}
+
+/*
+ * Función para determinar las características de un archivo tomando su vector de la arena (si el motor es vectorial)
+ * param: arena del análisis, motor y archivo a caracterizar
+ */
+func caracterizarEnArena(arena *ArenaCaracteristicas, motor Motor, codigoFuente *CodigoFuente) {
+	if motorVectorial, ok := motor.(MotorVectorial); ok && motorVectorial.dimensiones() > 0 {
+		codigoFuente.caracteristica = arena.obtener(motorVectorial.dimensiones())
+	}
+
+	motor.caracterizar(codigoFuente, codigoFuente.contenido)
+}
+
+/*
+ * Función para crear las tablas de distancias de todos los archivos a partir de una única arena
+ * param: arreglo con la información de todos los archivos y si las distancias se almacenan en float32
+ */
+func crearTablasDistancias(tablaCodigoFuente []CodigoFuente, reducida bool) {
+	cantidad := len(tablaCodigoFuente)
+
+	if reducida {
+		arena := make([]float32, cantidad*cantidad)
+		for i := range tablaCodigoFuente {
+			tablaCodigoFuente[i].tablaDistancias, tablaCodigoFuente[i].tablaDistancias32 = nil, arena[i*cantidad:(i+1)*cantidad:(i+1)*cantidad]
+		}
+		return
+	}
+
+	arena := make([]Distancia, cantidad*cantidad)
+	for i := range tablaCodigoFuente {
+		tablaCodigoFuente[i].tablaDistancias, tablaCodigoFuente[i].tablaDistancias32 = arena[i*cantidad:(i+1)*cantidad:(i+1)*cantidad], nil
+	}
+}
+
+/*
+ * Función para preparar un vector de características recibido para contar en él
+ * param: vector recibido (puede ser nulo) y su longitud
+ * return: el mismo vector en ceros si tiene la longitud indicada, o un vector nuevo
+ */
+func prepararVectorCaracteristicas(vector []int, longitud int) []int {
+	if len(vector) != longitud {
+		return make([]int, longitud)
+	}
+
+	for i := range vector {
+		vector[i] = 0
+	}
+
+	return vector
+}
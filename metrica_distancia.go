@@ -0,0 +1,127 @@
+/*
+ * Métricas de distancia de los motores de vectores (--metric).
+ *
+ * Los motores "ascii" y "tokens" comparan los vectores de frecuencias con la distancia euclidiana (L2), que eleva
+ * al cuadrado cada diferencia y por lo tanto pesa mucho unas pocas dimensiones muy distintas. Con --metric se
+ * selecciona otra métrica en tiempo de ejecución:
+ * - "euclidean": raíz de la suma de los cuadrados de las diferencias (por defecto)
+ * - "manhattan": suma de las diferencias absolutas (L1), cada dimensión aporta en proporción a su diferencia
+ * - "chebyshev": mayor diferencia absoluta (L∞), la distancia es la de la dimensión más distinta
+ * Se aplica a las frecuencias (densas, dispersas, con --hash-dims o proyectadas con --reduce). La escala de las
+ * distancias cambia con la métrica, por lo que una distancia máxima (--max-distance) de una métrica no sirve para
+ * otra; el criterio automático (--threshold=auto) se calcula sobre las distancias de la métrica seleccionada.
+ *
+ * --squared y --gram se basan en el cuadrado de la distancia euclidiana, por lo que solo se aplican con ella. El
+ * nombre del motor incluye la métrica, por lo que también hace parte de la huella de la caché.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jugutier73/SASC/pkg/sasc"
+)
+
+// Métricas de distancia soportadas
+const (
+	METRICA_EUCLIDIANA = "euclidean"
+	METRICA_MANHATTAN  = "manhattan"
+	METRICA_CHEBYSHEV  = "chebyshev"
+)
+
+// Estructura de una métrica de distancia distinta a la euclidiana
+// - nombre de la métrica para el nombre del motor
+// - distancia entre dos vectores enteros (frecuencias de caracteres o hashing)
+// - distancia entre dos vectores reales (proyecciones)
+// - distancia entre dos vectores dispersos (frecuencias de n-gramas)
+type MetricaDistancia struct {
+	nombre    string
+	enteros   func(v1 []int, v2 []int) float64
+	reales    func(v1 []float64, v2 []float64) float64
+	dispersos func(v1 map[uint64]float64, v2 map[uint64]float64) float64
+}
+
+// Métricas de distancia seleccionables con --metric (la euclidiana no está porque es la de los motores)
+var metricasDistancia = map[string]*MetricaDistancia{
+	METRICA_MANHATTAN: {nombre: "distancia Manhattan", enteros: sasc.ManhattanDistance, reales: sasc.ManhattanDistanceFloat, dispersos: calcularDistanciaDispersaManhattan},
+	METRICA_CHEBYSHEV: {nombre: "distancia Chebyshev", enteros: sasc.ChebyshevDistance, reales: sasc.ChebyshevDistanceFloat, dispersos: calcularDistanciaDispersaChebyshev},
+}
+
+/*
+ * Función para obtener la métrica de distancia indicada por el usuario
+ * param: parámetros de ejecución
+ * return: la métrica (nil para la euclidiana) o un error si no existe o no se puede combinar con los demás parámetros
+ */
+func obtenerMetricaDistancia(parametros Parametros) (*MetricaDistancia, error) {
+	if parametros.metrica == METRICA_EUCLIDIANA {
+		return nil, nil
+	}
+
+	metrica, existe := metricasDistancia[parametros.metrica]
+	if !existe {
+		return nil, fmt.Errorf("Métrica \"%s\" no soportada (%s | %s | %s)", parametros.metrica, METRICA_EUCLIDIANA, METRICA_MANHATTAN, METRICA_CHEBYSHEV)
+	}
+	if parametros.motor == "lines" || parametros.motor == "ncd" || parametros.motor == "simhash" {
+		return nil, fmt.Errorf("La métrica (--metric) solo se aplica a los motores \"ascii\", \"tokens\" y \"ast\"")
+	}
+	if parametros.distanciasCuadradas || parametros.matrizGram {
+		return nil, fmt.Errorf("Las distancias al cuadrado (--squared) y la matriz de Gram (--gram) requieren la métrica \"%s\"", METRICA_EUCLIDIANA)
+	}
+
+	return metrica, nil
+}
+
+/*
+ * Función para describir la métrica de distancia en el nombre de un motor
+ * param: la métrica (nil para la euclidiana)
+ * return: por ejemplo "distancia euclidiana" o "distancia Manhattan"
+ */
+func describirMetrica(metrica *MetricaDistancia) string {
+	if metrica == nil {
+		return "distancia euclidiana"
+	}
+
+	return metrica.nombre
+}
+
+/*
+ * Función que calcula la distancia Manhattan (L1) entre dos vectores dispersos
+ * param: dos vectores dispersos
+ * return: distancia Manhattan
+ */
+func calcularDistanciaDispersaManhattan(v1 map[uint64]float64, v2 map[uint64]float64) float64 {
+	suma := 0.0
+
+	for clave, valor := range v1 {
+		suma += math.Abs(valor - v2[clave])
+	}
+	for clave, valor := range v2 {
+		if _, existe := v1[clave]; !existe {
+			suma += math.Abs(valor)
+		}
+	}
+
+	return suma
+}
+
+/*
+ * Función que calcula la distancia Chebyshev (L∞) entre dos vectores dispersos
+ * param: dos vectores dispersos
+ * return: distancia Chebyshev
+ */
+func calcularDistanciaDispersaChebyshev(v1 map[uint64]float64, v2 map[uint64]float64) float64 {
+	maximo := 0.0
+
+	for clave, valor := range v1 {
+		maximo = math.Max(maximo, math.Abs(valor-v2[clave]))
+	}
+	for clave, valor := range v2 {
+		if _, existe := v1[clave]; !existe {
+			maximo = math.Max(maximo, math.Abs(valor))
+		}
+	}
+
+	return maximo
+}
@@ -0,0 +1,131 @@
+/*
+ * Métricas del modo servidor en el formato de texto de Prometheus.
+ *
+ * Se exponen en /metrics los contadores de análisis realizados y fallidos, archivos procesados, pares
+ * marcados (a una distancia máxima), comparaciones de fragmentos (POST /v1/similarity), autoverificaciones
+ * (POST /v1/self-check) y peticiones rechazadas por los límites (ver limites_servidor.go), y un histograma con la duración de los análisis, para que el servicio se pueda monitorear como cualquier otro.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Límites superiores (en segundos) de las cubetas del histograma de duración de los análisis
+var limitesDuracion = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300}
+
+// Estructura para almacenar las métricas del servidor (compartidas entre las peticiones)
+// - contadores de análisis realizados, análisis fallidos, archivos procesados y pares marcados
+// - contadores de comparaciones de fragmentos y de autoverificaciones (ver similitud.go y autoverificacion.go)
+// - contador de peticiones rechazadas por el límite de peticiones o la cuota por curso
+// - cantidad de análisis por cada cubeta del histograma de duración, suma y cantidad de las duraciones
+type Metricas struct {
+	mutex            sync.Mutex
+	analisis         uint64
+	analisisFallidos uint64
+	archivos         uint64
+	paresMarcados    uint64
+	comparaciones    uint64
+	autoverificacion uint64
+	rechazos         uint64
+	cubetasDuracion  []uint64
+	sumaDuracion     float64
+	cantidadDuracion uint64
+}
+
+/*
+ * Función para crear las métricas del servidor en cero
+ * return: las métricas
+ */
+func crearMetricas() *Metricas {
+	return &Metricas{cubetasDuracion: make([]uint64, len(limitesDuracion))}
+}
+
+/*
+ * Función para registrar un análisis exitoso
+ * param: duración del análisis en segundos, cantidad de archivos procesados y cantidad de pares marcados
+ */
+func (metricas *Metricas) registrarAnalisis(duracion float64, archivos int, paresMarcados int) {
+	metricas.mutex.Lock()
+	defer metricas.mutex.Unlock()
+
+	metricas.analisis++
+	metricas.archivos += uint64(archivos)
+	metricas.paresMarcados += uint64(paresMarcados)
+
+	for i, limite := range limitesDuracion {
+		if duracion <= limite {
+			metricas.cubetasDuracion[i]++
+		}
+	}
+	metricas.sumaDuracion += duracion
+	metricas.cantidadDuracion++
+}
+
+/*
+ * Función para registrar un análisis fallido
+ */
+func (metricas *Metricas) registrarFallo() {
+	metricas.mutex.Lock()
+	defer metricas.mutex.Unlock()
+
+	metricas.analisisFallidos++
+}
+
+/*
+ * Función para registrar una comparación de fragmentos exitosa
+ */
+func (metricas *Metricas) registrarComparacion() {
+	metricas.mutex.Lock()
+	defer metricas.mutex.Unlock()
+
+	metricas.comparaciones++
+}
+
+/*
+ * Función para registrar una autoverificación exitosa
+ */
+func (metricas *Metricas) registrarAutoverificacion() {
+	metricas.mutex.Lock()
+	defer metricas.mutex.Unlock()
+
+	metricas.autoverificacion++
+}
+
+/*
+ * Función para registrar una petición rechazada por un límite
+ */
+func (metricas *Metricas) registrarRechazo() {
+	metricas.mutex.Lock()
+	defer metricas.mutex.Unlock()
+
+	metricas.rechazos++
+}
+
+/*
+ * Función para escribir las métricas en el formato de texto de Prometheus
+ * param: destino de las métricas
+ */
+func (metricas *Metricas) escribir(destino io.Writer) {
+	metricas.mutex.Lock()
+	defer metricas.mutex.Unlock()
+
+	fmt.Fprintf(destino, "# HELP sasc_analyses_total Análisis realizados.\n# TYPE sasc_analyses_total counter\nsasc_analyses_total %d\n", metricas.analisis)
+	fmt.Fprintf(destino, "# HELP sasc_analysis_errors_total Análisis fallidos.\n# TYPE sasc_analysis_errors_total counter\nsasc_analysis_errors_total %d\n", metricas.analisisFallidos)
+	fmt.Fprintf(destino, "# HELP sasc_files_processed_total Archivos procesados.\n# TYPE sasc_files_processed_total counter\nsasc_files_processed_total %d\n", metricas.archivos)
+	fmt.Fprintf(destino, "# HELP sasc_pairs_flagged_total Pares de archivos a una distancia máxima.\n# TYPE sasc_pairs_flagged_total counter\nsasc_pairs_flagged_total %d\n", metricas.paresMarcados)
+	fmt.Fprintf(destino, "# HELP sasc_similarity_requests_total Comparaciones de fragmentos.\n# TYPE sasc_similarity_requests_total counter\nsasc_similarity_requests_total %d\n", metricas.comparaciones)
+	fmt.Fprintf(destino, "# HELP sasc_self_checks_total Autoverificaciones de los estudiantes.\n# TYPE sasc_self_checks_total counter\nsasc_self_checks_total %d\n", metricas.autoverificacion)
+	fmt.Fprintf(destino, "# HELP sasc_requests_limited_total Peticiones rechazadas por el límite de peticiones o la cuota por curso.\n# TYPE sasc_requests_limited_total counter\nsasc_requests_limited_total %d\n", metricas.rechazos)
+
+	fmt.Fprintf(destino, "# HELP sasc_analysis_duration_seconds Duración de los análisis.\n# TYPE sasc_analysis_duration_seconds histogram\n")
+	for i, limite := range limitesDuracion {
+		fmt.Fprintf(destino, "sasc_analysis_duration_seconds_bucket{le=\"%g\"} %d\n", limite, metricas.cubetasDuracion[i])
+	}
+	fmt.Fprintf(destino, "sasc_analysis_duration_seconds_bucket{le=\"+Inf\"} %d\n", metricas.cantidadDuracion)
+	fmt.Fprintf(destino, "sasc_analysis_duration_seconds_sum %g\n", metricas.sumaDuracion)
+	fmt.Fprintf(destino, "sasc_analysis_duration_seconds_count %d\n", metricas.cantidadDuracion)
+}
@@ -0,0 +1,129 @@
+/*
+ * Minigráfico de las distancias de cada archivo en el paquete de reporte estático (--bundle).
+ *
+ * Cada archivo del paquete tiene una página con un minigráfico PNG: el histograma de las distancias del archivo a
+ * los demás (barras oscuras) sobre el histograma de las distancias de todos los pares del corpus (barras grises),
+ * con la distancia máxima marcada en rojo. Un archivo con vecinos inusualmente cercanos se reconoce a simple vista
+ * porque tiene barras oscuras a la izquierda del grueso del corpus.
+ */
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+// Dimensiones del minigráfico (en píxeles) y ancho de cada barra del histograma
+const (
+	ANCHO_MINIGRAFICO       = 180
+	ALTO_MINIGRAFICO        = 32
+	ANCHO_BARRA_MINIGRAFICO = 4
+)
+
+// Colores del minigráfico
+var (
+	COLOR_FONDO_MINIGRAFICO   = color.RGBA{255, 255, 255, 255}
+	COLOR_CORPUS_MINIGRAFICO  = color.RGBA{200, 200, 200, 255}
+	COLOR_ARCHIVO_MINIGRAFICO = color.RGBA{31, 78, 140, 255}
+	COLOR_LIMITE_MINIGRAFICO  = color.RGBA{204, 0, 0, 255}
+)
+
+/*
+ * Función para obtener el histograma de unas distancias
+ * param: distancias, distancia máxima del eje y cantidad de barras
+ * return: cantidad de distancias de cada barra y la cantidad de la barra más alta
+ */
+func obtenerHistogramaMinigrafico(distancias []float64, maximo float64, barras int) ([]int, int) {
+	histograma := make([]int, barras)
+	mayor := 0
+
+	for _, distancia := range distancias {
+		barra := int(distancia / maximo * float64(barras))
+		if barra >= barras {
+			barra = barras - 1
+		}
+		if barra < 0 {
+			barra = 0
+		}
+
+		histograma[barra]++
+		if histograma[barra] > mayor {
+			mayor = histograma[barra]
+		}
+	}
+
+	return histograma, mayor
+}
+
+/*
+ * Función para dibujar una barra del minigráfico desde la base de la imagen
+ * param: imagen, posición horizontal y ancho de la barra, altura relativa (entre 0.0 y 1.0) y color
+ */
+func dibujarBarraMinigrafico(imagen *image.RGBA, x int, ancho int, altura float64, relleno color.RGBA) {
+	pixeles := int(math.Ceil(altura * float64(ALTO_MINIGRAFICO)))
+
+	for i := x; i < x+ancho && i < ANCHO_MINIGRAFICO; i++ {
+		for j := ALTO_MINIGRAFICO - pixeles; j < ALTO_MINIGRAFICO; j++ {
+			imagen.SetRGBA(i, j, relleno)
+		}
+	}
+}
+
+/*
+ * Función para generar el minigráfico de las distancias de un archivo frente a las del corpus
+ * param: distribución de las distancias del corpus (ordenada), distancias del archivo a los demás archivos
+ *        y distancia máxima (infinita si no se definió)
+ * return: la imagen en formato PNG
+ */
+func generarMinigrafico(distribucion []float64, distancias []float64, limite float64) []byte {
+	maximo := 0.0
+	if len(distribucion) > 0 {
+		maximo = distribucion[len(distribucion)-1]
+	}
+	if maximo <= 0 {
+		maximo = 1
+	}
+
+	barras := ANCHO_MINIGRAFICO / ANCHO_BARRA_MINIGRAFICO
+	corpus, mayorCorpus := obtenerHistogramaMinigrafico(distribucion, maximo, barras)
+	archivo, mayorArchivo := obtenerHistogramaMinigrafico(distancias, maximo, barras)
+
+	imagen := image.NewRGBA(image.Rect(0, 0, ANCHO_MINIGRAFICO, ALTO_MINIGRAFICO))
+	for i := 0; i < ANCHO_MINIGRAFICO; i++ {
+		for j := 0; j < ALTO_MINIGRAFICO; j++ {
+			imagen.SetRGBA(i, j, COLOR_FONDO_MINIGRAFICO)
+		}
+	}
+
+	// Cada histograma se escala a su barra más alta, para comparar las formas y no las cantidades
+	for barra := 0; barra < barras; barra++ {
+		x := barra * ANCHO_BARRA_MINIGRAFICO
+		if corpus[barra] > 0 {
+			dibujarBarraMinigrafico(imagen, x, ANCHO_BARRA_MINIGRAFICO, float64(corpus[barra])/float64(mayorCorpus), COLOR_CORPUS_MINIGRAFICO)
+		}
+		if archivo[barra] > 0 {
+			dibujarBarraMinigrafico(imagen, x+1, ANCHO_BARRA_MINIGRAFICO-2, float64(archivo[barra])/float64(mayorArchivo), COLOR_ARCHIVO_MINIGRAFICO)
+		}
+	}
+
+	if !math.IsInf(limite, 1) && limite <= maximo {
+		x := int(limite / maximo * float64(ANCHO_MINIGRAFICO))
+		if x >= ANCHO_MINIGRAFICO {
+			x = ANCHO_MINIGRAFICO - 1
+		}
+		for j := 0; j < ALTO_MINIGRAFICO; j++ {
+			imagen.SetRGBA(x, j, COLOR_LIMITE_MINIGRAFICO)
+		}
+	}
+
+	var contenido bytes.Buffer
+	if err := png.Encode(&contenido, imagen); err != nil {
+		panic(err)
+	}
+
+	return contenido.Bytes()
+}
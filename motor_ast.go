@@ -0,0 +1,215 @@
+/*
+ * Motor estructural de Go (--engine=ast).
+ *
+ * Cada archivo .go se analiza con go/parser y se representa por la frecuencia de dos tipos de características de su
+ * árbol de sintaxis abstracta (AST):
+ * - tipos de nodos: cuántas veces aparece cada tipo de nodo (FuncDecl, ForStmt, IfStmt, ...), con el operador de las
+ *   expresiones y asignaciones y el tipo de los literales (BinaryExpr+, AssignStmt:=, BasicLit INT, ...)
+ * - subárboles normalizados: el hash de la estructura de cada subárbol de al menos NODOS_MINIMOS_SUBARBOL nodos, es
+ *   decir, de los tipos de sus nodos en orden, sin los nombres de los identificadores ni el valor de los literales
+ * Los comentarios no hacen parte del árbol, por lo que una copia en la que se renombran los identificadores y se
+ * reescriben los comentarios conserva las mismas características. La distancia es la euclidiana entre las
+ * frecuencias (almacenadas de forma dispersa como las del motor "tokens"), con --metric y --normalize opcionales.
+ *
+ * Un archivo con errores de sintaxis se representa por la parte que se pudo analizar. Un fragmento sin la cláusula
+ * package (por ejemplo en POST /v1/similarity) se analiza como archivo, y si no tiene declaraciones, como el cuerpo
+ * de una función.
+ */
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"hash/fnv"
+	"reflect"
+	"strings"
+)
+
+// Cantidad mínima de nodos de un subárbol para contar su hash
+const NODOS_MINIMOS_SUBARBOL = 3
+
+// Motor estructural de Go con distancia euclidiana
+// - métrica de distancia (nil para la euclidiana, ver metrica_distancia.go)
+// - si las frecuencias son relativas a la cantidad de nodos del archivo (ver frecuencias_relativas.go)
+type MotorAST struct {
+	metrica  *MetricaDistancia
+	relativa bool
+}
+
+func (motor MotorAST) nombre() string {
+	return "ast (" + describirFrecuencia(motor.relativa) + " de tipos de nodos y subárboles normalizados de Go, " + describirMetrica(motor.metrica) + ")"
+}
+
+func (motor MotorAST) caracterizar(codigoFuente *CodigoFuente, contenido []byte) {
+	var nodos int
+	codigoFuente.ngramas, nodos = calcularFrecuenciasAST(contenido, nil)
+	if motor.relativa {
+		normalizarFrecuenciasDispersas(codigoFuente.ngramas, nodos)
+	}
+}
+
+func (motor MotorAST) distancia(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	if motor.metrica != nil {
+		return motor.metrica.dispersos(c1.ngramas, c2.ngramas)
+	}
+	return calcularDistanciaDispersa(c1.ngramas, c2.ngramas)
+}
+
+func (motor MotorAST) distanciaCuadrada(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	return calcularDistanciaDispersaCuadrada(c1.ngramas, c2.ngramas)
+}
+
+/*
+ * Función para analizar un contenido de Go, completándolo si es un fragmento
+ * param: contenido del archivo o del fragmento
+ * return: el árbol de sintaxis (parcial si tiene errores, nil si no se pudo analizar)
+ */
+func analizarArchivoGo(contenido []byte) *ast.File {
+	archivo, err := parser.ParseFile(token.NewFileSet(), "", contenido, 0)
+	if err == nil {
+		return archivo
+	}
+
+	// Fragmentos sin la cláusula package: como archivo o como el cuerpo de una función
+	for _, plantilla := range []string{"package fragmento\n%s", "package fragmento\nfunc fragmento() {\n%s\n}"} {
+		completo, err := parser.ParseFile(token.NewFileSet(), "", fmt.Sprintf(plantilla, contenido), 0)
+		if err == nil && len(completo.Decls) > 0 {
+			return completo
+		}
+	}
+
+	return archivo
+}
+
+/*
+ * Función para obtener el nombre del tipo de un nodo, con su operador o el tipo de literal
+ * param: nodo del árbol
+ * return: por ejemplo "ForStmt", "BinaryExpr+" o "BasicLit INT"
+ */
+func nombrarNodoAST(nodo ast.Node) string {
+	nombre := reflect.TypeOf(nodo).Elem().Name()
+
+	switch nodo := nodo.(type) {
+	case *ast.BinaryExpr:
+		return nombre + nodo.Op.String()
+	case *ast.UnaryExpr:
+		return nombre + nodo.Op.String()
+	case *ast.AssignStmt:
+		return nombre + nodo.Tok.String()
+	case *ast.IncDecStmt:
+		return nombre + nodo.Tok.String()
+	case *ast.BranchStmt:
+		return nombre + " " + nodo.Tok.String()
+	case *ast.BasicLit:
+		return nombre + " " + nodo.Kind.String()
+	}
+
+	return nombre
+}
+
+/*
+ * Función para calcular el hash de la característica de un tipo de nodo
+ * param: nombre del tipo de nodo
+ * return: hash de la característica
+ */
+func calcularHashTipoAST(nombre string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("tipo:" + nombre))
+
+	return hash.Sum64()
+}
+
+/*
+ * Función para determinar la frecuencia de los tipos de nodos y los subárboles normalizados de un contenido de Go
+ * param: contenido del archivo y etiquetas de cada característica por su hash (se completan si no son nulas, para
+ *        los reportes)
+ * return: frecuencia de cada característica por su hash y cantidad de nodos del árbol
+ */
+func calcularFrecuenciasAST(contenido []byte, etiquetas map[uint64]string) (map[uint64]float64, int) {
+	frecuencias := make(map[uint64]float64)
+	archivo := analizarArchivoGo(contenido)
+	if archivo == nil {
+		return frecuencias, 0
+	}
+
+	// Subárbol en construcción: hash de los tipos de sus nodos en orden, cantidad de nodos y tipo de su raíz
+	type Subarbol struct {
+		hash  uint64
+		nodos int
+		raiz  string
+	}
+	var pila []Subarbol
+	nodos := 0
+
+	ast.Inspect(archivo, func(nodo ast.Node) bool {
+		if nodo == nil {
+			subarbol := pila[len(pila)-1]
+			pila = pila[:len(pila)-1]
+
+			if subarbol.nodos >= NODOS_MINIMOS_SUBARBOL {
+				frecuencias[subarbol.hash]++
+				if etiquetas != nil {
+					etiquetas[subarbol.hash] = fmt.Sprintf("%s (%d nodos)", subarbol.raiz, subarbol.nodos)
+				}
+			}
+			if len(pila) > 0 {
+				padre := &pila[len(pila)-1]
+				padre.hash = combinarHashAST(padre.hash, subarbol.hash)
+				padre.nodos += subarbol.nodos
+			}
+			return true
+		}
+
+		nombre := nombrarNodoAST(nodo)
+		tipo := calcularHashTipoAST(nombre)
+		frecuencias[tipo]++
+		if etiquetas != nil {
+			etiquetas[tipo] = nombre
+		}
+		nodos++
+
+		pila = append(pila, Subarbol{hash: tipo, nodos: 1, raiz: nombre})
+		return true
+	})
+
+	return frecuencias, nodos
+}
+
+/*
+ * Función para combinar el hash de un subárbol con el de uno de sus hijos (el orden de los hijos importa)
+ * param: hash acumulado del subárbol y hash del hijo
+ * return: hash combinado
+ */
+func combinarHashAST(hash uint64, hijo uint64) uint64 {
+	var bytes [16]byte
+	binary.LittleEndian.PutUint64(bytes[:8], hash)
+	binary.LittleEndian.PutUint64(bytes[8:], hijo)
+
+	combinado := fnv.New64a()
+	combinado.Write(bytes[:])
+
+	return combinado.Sum64()
+}
+
+/*
+ * Función para obtener la etiqueta y la categoría de cada característica de un archivo (para los reportes)
+ * param: contenido del archivo
+ * return: etiqueta y categoría ("categoria_nodos" o "categoria_subarboles") de cada característica, por su hash
+ */
+func etiquetarCaracteristicasAST(contenido []byte) (map[uint64]string, map[uint64]string) {
+	etiquetas, categorias := make(map[uint64]string), make(map[uint64]string)
+	calcularFrecuenciasAST(contenido, etiquetas)
+
+	for hash, etiqueta := range etiquetas {
+		categorias[hash] = "categoria_nodos"
+		if strings.HasSuffix(etiqueta, " nodos)") {
+			categorias[hash] = "categoria_subarboles"
+		}
+	}
+
+	return etiquetas, categorias
+}
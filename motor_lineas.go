@@ -0,0 +1,274 @@
+/*
+ * Motor de líneas normalizadas.
+ *
+ * Cada archivo se representa por el multiconjunto de sus líneas normalizadas (sin espacios al inicio y al final,
+ * espacios internos colapsados y sin líneas vacías), almacenadas como hash junto con su número de línea.
+ * El puntaje entre dos archivos es:
+ * - Jaccard: coincidencias / (total de líneas de ambos - coincidencias)
+ * - Contención: coincidencias / líneas del archivo más pequeño
+ * y la distancia es 100 * (1 - puntaje), de modo que 0.0 indica las mismas líneas en ambos archivos.
+ *
+ * Como cada línea conserva su número, las líneas coincidentes se pueden reportar como evidencia.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Estructura para almacenar una línea normalizada de un archivo
+// - hash de la línea normalizada
+// - número de la línea en el archivo (inicia en 1)
+type Linea struct {
+	hash   uint64
+	numero int
+}
+
+// Estructura para almacenar una línea coincidente entre dos archivos
+// - número de la línea en el primer archivo
+// - número de la línea en el segundo archivo
+// - posición de la línea entre las líneas normalizadas de cada archivo
+type LineaCoincidente struct {
+	numero1   int
+	numero2   int
+	posicion1 int
+	posicion2 int
+}
+
+// Motor de líneas normalizadas con puntaje de Jaccard o de contención
+type MotorLineas struct {
+	contencion bool
+}
+
+func (motor MotorLineas) nombre() string {
+	if motor.contencion {
+		return "lines (líneas normalizadas, distancia 100 * (1 - contención))"
+	}
+	return "lines (líneas normalizadas, distancia 100 * (1 - Jaccard))"
+}
+
+func (motor MotorLineas) caracterizar(codigoFuente *CodigoFuente, contenido []byte) {
+	codigoFuente.lineas = obtenerLineasNormalizadas(contenido)
+}
+
+func (motor MotorLineas) distancia(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	return 100.0 * (1.0 - calcularPuntajeLineas(c1.lineas, c2.lineas, motor.contencion))
+}
+
+/*
+ * Función para normalizar una línea: elimina los espacios al inicio y al final y colapsa los espacios internos
+ * param: línea original
+ * return: línea normalizada
+ */
+func normalizarLinea(linea string) string {
+	return strings.Join(strings.Fields(linea), " ")
+}
+
+/*
+ * Función para obtener las líneas normalizadas (no vacías) de un contenido
+ * param: contenido del archivo
+ * return: arreglo con el hash y el número de cada línea normalizada
+ */
+func obtenerLineasNormalizadas(contenido []byte) []Linea {
+	var lineas []Linea
+
+	data := bufio.NewScanner(bytes.NewReader(contenido))
+	data.Buffer(make([]byte, 64*1024), len(contenido)+1)
+
+	numero := 0
+	for data.Scan() {
+		numero++
+		texto := normalizarLinea(data.Text())
+
+		if texto == "" {
+			continue
+		}
+
+		hash := fnv.New64a()
+		hash.Write([]byte(texto))
+		lineas = append(lineas, Linea{hash: hash.Sum64(), numero: numero})
+	}
+
+	return lineas
+}
+
+/*
+ * Función para contar la cantidad de líneas coincidentes (intersección de los multiconjuntos)
+ * param: líneas de ambos archivos
+ * return: cantidad de líneas coincidentes
+ */
+func contarLineasCoincidentes(lineas1 []Linea, lineas2 []Linea) int {
+	frecuencia := make(map[uint64]int, len(lineas1))
+
+	for _, linea := range lineas1 {
+		frecuencia[linea.hash]++
+	}
+
+	coincidencias := 0
+	for _, linea := range lineas2 {
+		if frecuencia[linea.hash] > 0 {
+			frecuencia[linea.hash]--
+			coincidencias++
+		}
+	}
+
+	return coincidencias
+}
+
+/*
+ * Función que calcula el puntaje de similaridad entre dos archivos a partir de sus líneas
+ * param: líneas de ambos archivos y si se usa contención (en otro caso Jaccard)
+ * return: puntaje entre 0.0 (nada en común) y 1.0 (mismas líneas)
+ */
+func calcularPuntajeLineas(lineas1 []Linea, lineas2 []Linea, contencion bool) float64 {
+	if len(lineas1) == 0 && len(lineas2) == 0 {
+		return 1.0
+	}
+
+	coincidencias := float64(contarLineasCoincidentes(lineas1, lineas2))
+
+	if contencion {
+		menor := len(lineas1)
+		if len(lineas2) < menor {
+			menor = len(lineas2)
+		}
+		if menor == 0 {
+			return 0.0
+		}
+		return coincidencias / float64(menor)
+	}
+
+	return coincidencias / (float64(len(lineas1)+len(lineas2)) - coincidencias)
+}
+
+/*
+ * Función para obtener las líneas coincidentes entre dos archivos.
+ * Cada línea del primer archivo se empareja con la primera ocurrencia aún no emparejada en el segundo archivo.
+ * param: líneas de ambos archivos
+ * return: arreglo con los números de línea coincidentes en ambos archivos
+ */
+func obtenerLineasCoincidentes(lineas1 []Linea, lineas2 []Linea) []LineaCoincidente {
+	var coincidentes []LineaCoincidente
+
+	ocurrencias := make(map[uint64][]int)
+	for posicion, linea := range lineas2 {
+		ocurrencias[linea.hash] = append(ocurrencias[linea.hash], posicion)
+	}
+
+	for posicion, linea := range lineas1 {
+		if posiciones := ocurrencias[linea.hash]; len(posiciones) > 0 {
+			coincidentes = append(coincidentes, LineaCoincidente{numero1: linea.numero, numero2: lineas2[posiciones[0]].numero, posicion1: posicion, posicion2: posiciones[0]})
+			ocurrencias[linea.hash] = posiciones[1:]
+		}
+	}
+
+	return coincidentes
+}
+
+/*
+ * Función para agrupar las líneas coincidentes en bloques de al menos la cantidad mínima de líneas
+ * consecutivas (sin contar líneas vacías) en ambos archivos
+ * param: líneas coincidentes y la cantidad mínima de líneas de un bloque
+ * return: arreglo con las líneas coincidentes de cada bloque
+ */
+func agruparBloquesLineas(coincidentes []LineaCoincidente, minimoLineas int) [][]LineaCoincidente {
+	var bloques [][]LineaCoincidente
+
+	for inicio := 0; inicio < len(coincidentes); {
+		fin := inicio + 1
+		for fin < len(coincidentes) &&
+			coincidentes[fin].posicion1 == coincidentes[fin-1].posicion1+1 &&
+			coincidentes[fin].posicion2 == coincidentes[fin-1].posicion2+1 {
+			fin++
+		}
+
+		if fin-inicio >= minimoLineas {
+			bloques = append(bloques, coincidentes[inicio:fin])
+		}
+		inicio = fin
+	}
+
+	return bloques
+}
+
+/*
+ * Función para conservar solamente las líneas coincidentes que forman bloques de al menos la cantidad mínima
+ * de líneas consecutivas (sin contar líneas vacías) en ambos archivos
+ * param: líneas coincidentes y la cantidad mínima de líneas de un bloque
+ * return: las líneas coincidentes que pertenecen a un bloque
+ */
+func filtrarBloquesLineas(coincidentes []LineaCoincidente, minimoLineas int) []LineaCoincidente {
+	var filtradas []LineaCoincidente
+
+	for _, bloque := range agruparBloquesLineas(coincidentes, minimoLineas) {
+		filtradas = append(filtradas, bloque...)
+	}
+
+	return filtradas
+}
+
+/*
+ * Función para leer el contenido original de un archivo (para mostrar la evidencia)
+ * param: información del archivo (del disco o de un .zip)
+ * return: contenido del archivo
+ */
+func leerContenido(codigoFuente CodigoFuente) string {
+	contenido, err := codigoFuente.archivosZip.leer(codigoFuente.ruta)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(contenido)
+}
+
+/*
+ * Función para leer las líneas originales de un archivo (para mostrar la evidencia)
+ * param: información del archivo (del disco o de un .zip)
+ * return: arreglo con las líneas del archivo
+ */
+func leerLineas(codigoFuente CodigoFuente) []string {
+	return strings.Split(leerContenido(codigoFuente), "\n")
+}
+
+/*
+ * Función para imprimir las líneas coincidentes de cada par de archivos a una distancia máxima.
+ * Si el motor empleado no es el de líneas, las líneas normalizadas se calculan en este momento.
+ * param: arreglo con la información del código fuente de los archivos, la distancia mínima
+ *        y la cantidad mínima de líneas consecutivas de un bloque coincidente
+ */
+func imprimirEvidenciaLineas(tablaCodigoFuente []CodigoFuente, distanciaMinima float64, minimoLineas int) {
+	fmt.Printf("\nEVIDENCIA (BLOQUES DE AL MENOS %d LÍNEAS COINCIDENTES)\n\n", minimoLineas)
+
+	for i := range tablaCodigoFuente {
+		if tablaCodigoFuente[i].lineas == nil {
+			tablaCodigoFuente[i].lineas = obtenerLineasNormalizadas(tablaCodigoFuente[i].contenido)
+		}
+	}
+
+	for i := 0; i < len(tablaCodigoFuente); i++ {
+		for j := i + 1; j < len(tablaCodigoFuente); j++ {
+			distancia := obtenerDistancia(tablaCodigoFuente[i], j)
+
+			if distancia > distanciaMinima {
+				continue
+			}
+
+			coincidentes := filtrarBloquesLineas(obtenerLineasCoincidentes(tablaCodigoFuente[i].lineas, tablaCodigoFuente[j].lineas), minimoLineas)
+
+			fmt.Printf("%s <-> %s (distancia %.2f, %d líneas coincidentes)\n", tablaCodigoFuente[i].nombre, tablaCodigoFuente[j].nombre, distancia, len(coincidentes))
+
+			if len(coincidentes) > 0 {
+				texto := leerLineas(tablaCodigoFuente[i])
+				for _, coincidente := range coincidentes {
+					fmt.Printf("\t%5d = %-5d %s\n", coincidente.numero1, coincidente.numero2, normalizarLinea(texto[coincidente.numero1-1]))
+				}
+			}
+			fmt.Println()
+		}
+	}
+}
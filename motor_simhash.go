@@ -0,0 +1,273 @@
+/*
+ * Motor e índice de huellas SimHash (--engine=simhash y comando simhash).
+ *
+ * La huella SimHash de un archivo es un número de 64 bits en el que archivos parecidos difieren en pocos bits: cada
+ * teja (ventana de LONGITUD_TEJA_SIMHASH tokens consecutivos) suma su peso en los bits en 1 de su hash y lo resta en
+ * los bits en 0, y la huella tiene en 1 los bits con suma positiva. La distancia entre dos archivos es la distancia de
+ * Hamming entre sus huellas (de 0 a 64 bits), por lo que con --engine=simhash los grupos se forman con una distancia
+ * máxima en bits (por ejemplo 3 a 10). Comparar dos huellas cuesta una operación XOR y un conteo de bits.
+ *
+ * Para verificar entregas nuevas contra un corpus grande almacenado (por ejemplo las de semestres anteriores) sin
+ * volver a leerlo, las huellas se guardan en un índice:
+ * - ./SASC simhash index --simhash-index=huellas.json [--dir=corpus] [--paths=label] java
+ *   agrega al índice (o actualiza) la huella de cada archivo del directorio, con su nombre en los reportes
+ *   (--paths=label distingue los archivos de distintos semestres con la etiqueta del directorio base)
+ * - ./SASC simhash check --simhash-index=huellas.json [--dir=nuevas] java 6
+ *   imprime, por cada archivo del directorio, los archivos del índice a la distancia de Hamming máxima
+ * La búsqueda recorre todas las huellas del índice (un millón de huellas ocupan 8 MB en memoria) y se imprime su
+ * tiempo. Con --encryption-key el índice se guarda cifrado (ver cifrado.go).
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Cantidad de tokens de cada teja de la huella SimHash
+const LONGITUD_TEJA_SIMHASH = 3
+
+// Versión del formato del índice de huellas SimHash
+const VERSION_INDICE_SIMHASH = 1
+
+// Motor de huellas SimHash de 64 bits con distancia de Hamming
+type MotorSimHash struct{}
+
+func (motor MotorSimHash) nombre() string {
+	return fmt.Sprintf("simhash (huella de 64 bits de las tejas de %d tokens, distancia de Hamming en bits)", LONGITUD_TEJA_SIMHASH)
+}
+
+func (motor MotorSimHash) caracterizar(codigoFuente *CodigoFuente, contenido []byte) {
+	codigoFuente.simhash = calcularSimHash(contenido)
+}
+
+func (motor MotorSimHash) distancia(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	return float64(bits.OnesCount64(c1.simhash ^ c2.simhash))
+}
+
+/*
+ * Función para calcular la huella SimHash de un contenido
+ * param: contenido del archivo
+ * return: huella de 64 bits (0 si el contenido no tiene tokens)
+ */
+func calcularSimHash(contenido []byte) uint64 {
+	tokens := obtenerTokens(contenido)
+	longitud := LONGITUD_TEJA_SIMHASH
+	if len(tokens) < longitud {
+		longitud = len(tokens)
+	}
+	if longitud == 0 {
+		return 0
+	}
+
+	// Cada teja repetida suma su peso una vez por aparición
+	var sumas [64]int
+	for _, teja := range calcularHashesVentanas(tokens, longitud) {
+		hash := mezclarHash(teja)
+		for bit := 0; bit < 64; bit++ {
+			if hash&(1<<uint(bit)) != 0 {
+				sumas[bit]++
+			} else {
+				sumas[bit]--
+			}
+		}
+	}
+
+	var huella uint64
+	for bit, suma := range sumas {
+		if suma > 0 {
+			huella |= 1 << uint(bit)
+		}
+	}
+
+	return huella
+}
+
+// Estructura de una huella del índice SimHash en JSON
+// - nombre del archivo en los reportes y huella en hexadecimal
+type HuellaSimHashJSON struct {
+	Archivo string `json:"archivo"`
+	SimHash string `json:"simhash"`
+}
+
+// Estructura del índice de huellas SimHash en JSON
+// - versión del formato, tokens de cada teja y huellas de los archivos
+type IndiceSimHashJSON struct {
+	Version int                 `json:"version"`
+	Teja    int                 `json:"teja"`
+	Huellas []HuellaSimHashJSON `json:"huellas"`
+}
+
+// Estructura del índice de huellas SimHash en memoria
+// - nombres de los archivos y sus huellas (en el mismo orden)
+type IndiceSimHash struct {
+	archivos []string
+	huellas  []uint64
+}
+
+/*
+ * Función para leer el índice de huellas SimHash
+ * param: nombre del archivo del índice, cifrado (nil si no se cifra) y si el índice puede no existir todavía
+ * return: el índice (vacío si no existe y puede no existir) o un error si no se puede leer o es de otro formato
+ */
+func leerIndiceSimHash(nombreArchivo string, cifrado *Cifrado, opcional bool) (IndiceSimHash, error) {
+	var indice IndiceSimHash
+
+	contenido, err := cifrado.leerArchivo(nombreArchivo)
+	if os.IsNotExist(err) && opcional {
+		return indice, nil
+	}
+	if err != nil {
+		return indice, fmt.Errorf("No se puede leer el índice SimHash (--simhash-index): %v", err)
+	}
+
+	var indiceJSON IndiceSimHashJSON
+	if err = json.Unmarshal(contenido, &indiceJSON); err != nil {
+		return indice, fmt.Errorf("Índice SimHash (--simhash-index) no válido: %v", err)
+	}
+	if indiceJSON.Version != VERSION_INDICE_SIMHASH || indiceJSON.Teja != LONGITUD_TEJA_SIMHASH {
+		return indice, fmt.Errorf("El índice SimHash (--simhash-index) es de otra versión (%d, tejas de %d tokens) y se debe volver a generar", indiceJSON.Version, indiceJSON.Teja)
+	}
+
+	for _, huella := range indiceJSON.Huellas {
+		valor, err := strconv.ParseUint(huella.SimHash, 16, 64)
+		if err != nil {
+			return indice, fmt.Errorf("Huella \"%s\" del índice SimHash (--simhash-index) no válida", huella.SimHash)
+		}
+		indice.archivos = append(indice.archivos, huella.Archivo)
+		indice.huellas = append(indice.huellas, valor)
+	}
+
+	return indice, nil
+}
+
+/*
+ * Función para guardar el índice de huellas SimHash
+ * param: nombre del archivo del índice y cifrado (nil si no se cifra)
+ */
+func (indice IndiceSimHash) guardar(nombreArchivo string, cifrado *Cifrado) {
+	indiceJSON := IndiceSimHashJSON{Version: VERSION_INDICE_SIMHASH, Teja: LONGITUD_TEJA_SIMHASH, Huellas: []HuellaSimHashJSON{}}
+	for i, archivo := range indice.archivos {
+		indiceJSON.Huellas = append(indiceJSON.Huellas, HuellaSimHashJSON{Archivo: archivo, SimHash: fmt.Sprintf("%016x", indice.huellas[i])})
+	}
+
+	contenido, err := json.MarshalIndent(indiceJSON, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	if err = cifrado.escribirArchivo(nombreArchivo, contenido, 0644); err != nil {
+		panic(err)
+	}
+}
+
+/*
+ * Función para ejecutar el comando simhash (index o check)
+ * param: parámetros de ejecución, el preprocesamiento, el formato de las rutas y el directorio base
+ * return: un error si la acción no es válida o el índice no se puede leer
+ */
+func ejecutarComandoSimHash(parametros Parametros, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas, directorioActual string) error {
+	cifrado, err := crearCifrado(parametros.archivoCifrado)
+	if err != nil {
+		return err
+	}
+
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		return err
+	}
+	listado, archivosZip, omitidos, err := obtenerListadoEntregas(directorioActual, parametros.extension, limites)
+	if err != nil {
+		return fmt.Errorf("Error al obtener el listado de los programas: %v", err)
+	}
+	preprocesamiento.archivosZip = archivosZip
+	imprimirZipOmitidos(omitidos, formatoRutas)
+
+	switch parametros.accionComando {
+	case "index":
+		indice, err := leerIndiceSimHash(parametros.indiceSimHash, cifrado, true)
+		if err != nil {
+			return err
+		}
+
+		posiciones := make(map[string]int)
+		for i, archivo := range indice.archivos {
+			posiciones[archivo] = i
+		}
+
+		for _, archivo := range listado {
+			codigoFuente := leerCodigoFuente(archivo, preprocesamiento)
+			nombre, huella := formatoRutas.formatear(archivo), calcularSimHash(codigoFuente.contenido)
+
+			if posicion, existe := posiciones[nombre]; existe {
+				indice.huellas[posicion] = huella
+				continue
+			}
+			posiciones[nombre] = len(indice.archivos)
+			indice.archivos = append(indice.archivos, nombre)
+			indice.huellas = append(indice.huellas, huella)
+		}
+
+		indice.guardar(parametros.indiceSimHash, cifrado)
+		fmt.Println("Índice SimHash \""+parametros.indiceSimHash+"\":", len(listado), "archivos agregados o actualizados,", len(indice.archivos), "huellas en total")
+		return nil
+	case "check":
+		indice, err := leerIndiceSimHash(parametros.indiceSimHash, cifrado, false)
+		if err != nil {
+			return err
+		}
+		verificarIndiceSimHash(indice, listado, parametros, preprocesamiento, formatoRutas)
+		return nil
+	case "":
+		return fmt.Errorf("Se debe indicar la acción del comando simhash (index | check)")
+	}
+
+	return fmt.Errorf("Acción \"%s\" del comando simhash no soportada (index | check)", parametros.accionComando)
+}
+
+/*
+ * Función para imprimir los archivos del índice a la distancia de Hamming máxima de cada archivo de un listado
+ * param: índice de huellas, listado de archivos a verificar, parámetros de ejecución (índice y distancia máxima),
+ *        el preprocesamiento y el formato de las rutas
+ */
+func verificarIndiceSimHash(indice IndiceSimHash, listado []string, parametros Parametros, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas) {
+	limite := parametros.criterioDistancia.limite()
+
+	fmt.Printf("\nVERIFICACIÓN CONTRA EL ÍNDICE SIMHASH \"%s\" (%d HUELLAS, DISTANCIA DE HAMMING MÁXIMA DE %s BITS)\n\n",
+		parametros.indiceSimHash, len(indice.huellas), strconv.FormatFloat(limite, 'g', -1, 64))
+
+	var duracion time.Duration
+	for _, archivo := range listado {
+		huella := calcularSimHash(leerCodigoFuente(archivo, preprocesamiento).contenido)
+
+		inicio := time.Now()
+		var cercanos []Distancia
+		for i, otra := range indice.huellas {
+			if distancia := float64(bits.OnesCount64(huella ^ otra)); distancia <= limite {
+				cercanos = append(cercanos, Distancia{indiceCodigoFuente: i, distancia: distancia})
+			}
+		}
+		duracion += time.Since(inicio)
+
+		sort.SliceStable(cercanos, func(i, j int) bool {
+			return cercanos[i].distancia < cercanos[j].distancia
+		})
+
+		fmt.Println(formatoRutas.formatear(archivo))
+		if len(cercanos) == 0 {
+			fmt.Println("\t(sin huellas cercanas en el índice)")
+		}
+		for _, cercano := range cercanos {
+			fmt.Printf("\t%8.0f %s\n", cercano.distancia, indice.archivos[cercano.indiceCodigoFuente])
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("Búsqueda de %d archivos en el índice: %.3f ms\n", len(listado), float64(duracion.Microseconds())/1000)
+}
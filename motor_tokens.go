@@ -0,0 +1,250 @@
+/*
+ * Motor de n-gramas de tokens.
+ *
+ * Cada archivo se representa por la frecuencia de sus n-gramas de tokens (secuencias de LONGITUD_NGRAMA_TOKENS
+ * tokens consecutivos, con los mismos tokens de la evidencia por fragmentos) y la distancia entre dos archivos
+ * es la distancia euclidiana entre sus frecuencias. El vocabulario de n-gramas no tiene límite, por lo que las
+ * frecuencias se almacenan de forma dispersa (solo los n-gramas presentes en el archivo).
+ *
+ * Con una reducción de dimensiones (--reduce) las frecuencias se proyectan a un vector denso de pocas
+ * dimensiones antes de calcular las distancias (ver reduccion.go).
+ *
+ * Con --hash-dims=N se usa el hashing trick: cada n-grama suma (o resta, según un bit de su hash, para que las
+ * colisiones se compensen en promedio) en la posición hash % N de un arreglo de N frecuencias, el mismo arreglo
+ * denso del motor ASCII, por lo que la memoria y el costo de cada distancia quedan fijos sin importar el vocabulario.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Cantidad de tokens de cada n-grama
+const LONGITUD_NGRAMA_TOKENS = 3
+
+// Motor de n-gramas de tokens con distancia euclidiana
+// - cantidad de componentes principales de la proyección (0 si no se reducen las dimensiones)
+// - cantidad de dimensiones del hashing de los n-gramas (0 si no se usa)
+// - enunciado de la tarea con el que se ponderan los n-gramas (nil si no se usa, ver especificacion.go)
+// - métrica de distancia (nil para la euclidiana, ver metrica_distancia.go)
+// - si las frecuencias son relativas a la cantidad de n-gramas del archivo (ver frecuencias_relativas.go)
+// - analizador léxico del lenguaje (nil para el tokenizador genérico, ver lexicos.go)
+type MotorTokens struct {
+	componentesPCA  int
+	dimensionesHash int
+	especificacion  *Especificacion
+	metrica         *MetricaDistancia
+	relativa        bool
+	lexico          *AnalizadorLexico
+}
+
+func (motor MotorTokens) nombre() string {
+	lenguaje := ""
+	if motor.lexico != nil {
+		lenguaje = " de " + motor.lexico.lenguaje
+	}
+
+	if motor.dimensionesHash > 0 {
+		return fmt.Sprintf("tokens (%s de %d-gramas de tokens%s, hashing a %d dimensiones, %s)", describirFrecuencia(motor.relativa), LONGITUD_NGRAMA_TOKENS, lenguaje, motor.dimensionesHash, describirMetrica(motor.metrica))
+	}
+
+	enunciado := ""
+	if motor.especificacion != nil {
+		enunciado = ", " + motor.especificacion.describir()
+	}
+	if motor.componentesPCA > 0 {
+		return fmt.Sprintf("tokens (%s de %d-gramas de tokens%s, PCA a %d dimensiones, %s)", describirFrecuencia(motor.relativa), LONGITUD_NGRAMA_TOKENS, lenguaje+enunciado, motor.componentesPCA, describirMetrica(motor.metrica))
+	}
+	return fmt.Sprintf("tokens (%s de %d-gramas de tokens%s, %s)", describirFrecuencia(motor.relativa), LONGITUD_NGRAMA_TOKENS, lenguaje+enunciado, describirMetrica(motor.metrica))
+}
+
+func (motor MotorTokens) caracterizar(codigoFuente *CodigoFuente, contenido []byte) {
+	tokens := motor.tokenizar(contenido)
+	if motor.dimensionesHash > 0 {
+		codigoFuente.caracteristica = calcularFrecuenciasHash(tokens, LONGITUD_NGRAMA_TOKENS, motor.dimensionesHash, codigoFuente.caracteristica)
+		if motor.relativa {
+			codigoFuente.proyeccion = calcularFrecuenciasRelativas(codigoFuente.caracteristica, contarNgramas(tokens, LONGITUD_NGRAMA_TOKENS))
+		}
+		return
+	}
+	codigoFuente.ngramas = calcularFrecuenciasNgramas(tokens, LONGITUD_NGRAMA_TOKENS)
+	if motor.especificacion != nil {
+		motor.especificacion.ponderar(tokens, codigoFuente.ngramas)
+	}
+	if motor.relativa {
+		normalizarFrecuenciasDispersas(codigoFuente.ngramas, contarNgramas(tokens, LONGITUD_NGRAMA_TOKENS))
+	}
+}
+
+/*
+ * Función para obtener los tokens de un contenido con el analizador léxico del motor
+ * param: contenido del archivo
+ * return: secuencia de tokens (normalizados si el motor tiene un analizador léxico)
+ */
+func (motor MotorTokens) tokenizar(contenido []byte) []Token {
+	if motor.lexico != nil {
+		return motor.lexico.tokenizar(contenido)
+	}
+
+	return obtenerTokens(contenido)
+}
+
+func (motor MotorTokens) distancia(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	if motor.metrica != nil {
+		return motor.distanciaMetrica(c1, c2)
+	}
+	if motor.dimensionesHash > 0 && !motor.relativa {
+		return calcularDistancia(c1, c2)
+	}
+	if c1.proyeccion != nil && c2.proyeccion != nil {
+		return calcularDistanciaVectores(c1.proyeccion, c2.proyeccion)
+	}
+	return calcularDistanciaDispersa(c1.ngramas, c2.ngramas)
+}
+
+/*
+ * Función para calcular la distancia entre dos archivos con una métrica distinta a la euclidiana
+ * param: dos archivos ya caracterizados
+ * return: distancia con la métrica del motor
+ */
+func (motor MotorTokens) distanciaMetrica(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	if motor.dimensionesHash > 0 && !motor.relativa {
+		return motor.metrica.enteros(c1.caracteristica, c2.caracteristica)
+	}
+	if c1.proyeccion != nil && c2.proyeccion != nil {
+		return motor.metrica.reales(c1.proyeccion, c2.proyeccion)
+	}
+	return motor.metrica.dispersos(c1.ngramas, c2.ngramas)
+}
+
+func (motor MotorTokens) distanciaCuadrada(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	if motor.dimensionesHash > 0 && !motor.relativa {
+		return calcularDistanciaCuadrada(c1, c2)
+	}
+	if c1.proyeccion != nil && c2.proyeccion != nil {
+		return calcularDistanciaVectoresCuadrada(c1.proyeccion, c2.proyeccion)
+	}
+	return calcularDistanciaDispersaCuadrada(c1.ngramas, c2.ngramas)
+}
+
+func (motor MotorTokens) proyectar(tablaCodigoFuente []CodigoFuente) *Proyeccion {
+	if motor.dimensionesHash > 0 {
+		return &Proyeccion{Metodo: "hashing", Dimensiones: motor.dimensionesHash}
+	}
+	if motor.componentesPCA == 0 {
+		return nil
+	}
+	return proyectarPCA(tablaCodigoFuente, motor.componentesPCA)
+}
+
+/*
+ * Función para calcular la frecuencia de los n-gramas de una secuencia de tokens
+ * param: secuencia de tokens y cantidad de tokens de cada n-grama
+ * return: frecuencia de cada n-grama presente, indexada por el hash del n-grama
+ */
+func calcularFrecuenciasNgramas(tokens []Token, longitud int) map[uint64]float64 {
+	frecuencias := make(map[uint64]float64)
+
+	hashes := poolHashes.Get().(*[]uint64)
+	*hashes = agregarHashesVentanas((*hashes)[:0], tokens, longitud)
+
+	for _, hash := range *hashes {
+		frecuencias[hash]++
+	}
+	poolHashes.Put(hashes)
+
+	return frecuencias
+}
+
+/*
+ * Función para calcular la frecuencia de los n-gramas de una secuencia de tokens en un arreglo de tamaño fijo
+ * (hashing trick). El bit más alto del hash indica si el n-grama suma o resta en su posición.
+ * param: secuencia de tokens, cantidad de tokens de cada n-grama, cantidad de dimensiones del arreglo
+ *        y arreglo en el que se cuenta (se crea si es nulo, ver memoria.go)
+ * return: arreglo con la frecuencia (con signo) de cada posición
+ */
+func calcularFrecuenciasHash(tokens []Token, longitud int, dimensiones int, tabla []int) []int {
+	tabla = prepararVectorCaracteristicas(tabla, dimensiones)
+
+	hashes := poolHashes.Get().(*[]uint64)
+	*hashes = agregarHashesVentanas((*hashes)[:0], tokens, longitud)
+
+	for _, hash := range *hashes {
+		if hash>>63 == 1 {
+			tabla[hash%uint64(dimensiones)]--
+		} else {
+			tabla[hash%uint64(dimensiones)]++
+		}
+	}
+	poolHashes.Put(hashes)
+
+	return tabla
+}
+
+/*
+ * Función para calcular el producto punto entre dos vectores dispersos
+ * param: dos vectores dispersos
+ * return: producto punto
+ */
+func calcularProductoDisperso(v1 map[uint64]float64, v2 map[uint64]float64) float64 {
+	if len(v1) > len(v2) {
+		v1, v2 = v2, v1
+	}
+
+	producto := 0.0
+	for clave, valor := range v1 {
+		producto += valor * v2[clave]
+	}
+
+	return producto
+}
+
+/*
+ * Función que calcula la distancia euclidiana entre dos vectores dispersos
+ * param: dos vectores dispersos
+ * return: distancia euclidiana
+ */
+func calcularDistanciaDispersa(v1 map[uint64]float64, v2 map[uint64]float64) float64 {
+	return math.Sqrt(calcularDistanciaDispersaCuadrada(v1, v2))
+}
+
+/*
+ * Función que calcula el cuadrado de la distancia euclidiana entre dos vectores dispersos
+ * param: dos vectores dispersos
+ * return: cuadrado de la distancia euclidiana
+ */
+func calcularDistanciaDispersaCuadrada(v1 map[uint64]float64, v2 map[uint64]float64) float64 {
+	suma := 0.0
+
+	for clave, valor := range v1 {
+		suma += math.Pow(valor-v2[clave], 2.0)
+	}
+	for clave, valor := range v2 {
+		if _, existe := v1[clave]; !existe {
+			suma += valor * valor
+		}
+	}
+
+	return suma
+}
+
+/*
+ * Función que calcula la distancia euclidiana entre dos vectores densos de la misma longitud
+ * param: dos vectores densos
+ * return: distancia euclidiana
+ */
+func calcularDistanciaVectores(v1 []float64, v2 []float64) float64 {
+	return math.Sqrt(calcularDistanciaVectoresCuadrada(v1, v2))
+}
+
+/*
+ * Función que calcula el cuadrado de la distancia euclidiana entre dos vectores densos de la misma longitud
+ * (con el núcleo seleccionado, ver nucleos_distancia.go)
+ * param: dos vectores densos
+ * return: cuadrado de la distancia euclidiana
+ */
+func calcularDistanciaVectoresCuadrada(v1 []float64, v2 []float64) float64 {
+	return nucleoDistancia.reales(v1, v2)
+}
@@ -0,0 +1,157 @@
+/*
+ * Motores de características de SASC.
+ *
+ * Un motor determina las características de cada archivo y la distancia entre dos archivos ya caracterizados.
+ * - "ascii": frecuencia de todos los caracteres de la tabla ASCII y distancia euclidiana (motor original), o de los
+ *   n-gramas de caracteres agrupados por su hash (--features=ngram, ver ngramas_caracteres.go).
+ * - "lines": conjunto (multiconjunto) de líneas normalizadas y distancia 100 * (1 - puntaje), donde el puntaje
+ *   es el índice de Jaccard o de contención entre los archivos.
+ * - "tokens": frecuencia de los n-gramas de tokens y distancia euclidiana, con reducción de dimensiones o hashing opcional,
+ *   y los tokens normalizados del analizador léxico del lenguaje (--lexer, ver lexicos.go).
+ * - "ast": frecuencia de los tipos de nodos y subárboles normalizados del árbol de sintaxis de Go (solo .go, ver
+ *   motor_ast.go) y distancia euclidiana.
+ * - "ncd": tamaño comprimido de cada archivo y distancia de compresión normalizada de cada par (ver motor_ncd.go).
+ * - "simhash": huella de 64 bits de cada archivo y distancia de Hamming entre las huellas (ver motor_simhash.go).
+ * Los motores "ascii", "tokens" y "ast" pueden usar otra métrica de distancia (--metric, ver metrica_distancia.go) y
+ * frecuencias relativas a la longitud del archivo (--normalize=relative, ver frecuencias_relativas.go).
+ */
+
+package main
+
+import "fmt"
+
+// Interfaz que deben cumplir los motores de características
+// - nombre del motor para los reportes
+// - determina las características de un archivo a partir de su contenido
+// - calcula la distancia entre dos archivos ya caracterizados (0.0 indica que son idénticos para el motor)
+type Motor interface {
+	nombre() string
+	caracterizar(codigoFuente *CodigoFuente, contenido []byte)
+	distancia(c1 CodigoFuente, c2 CodigoFuente) float64
+}
+
+// Motor original: frecuencia de los caracteres de la tabla ASCII con distancia euclidiana
+// - métrica de distancia (nil para la euclidiana, ver metrica_distancia.go)
+// - si las frecuencias son relativas a la longitud del archivo (en la proyección, ver frecuencias_relativas.go)
+// - cantidad de caracteres de los n-gramas (0 para la frecuencia de cada carácter) y tamaño de su vector (ver ngramas_caracteres.go)
+type MotorASCII struct {
+	metrica            *MetricaDistancia
+	relativa           bool
+	longitudNgrama     int
+	dimensionesNgramas int
+}
+
+func (motor MotorASCII) nombre() string {
+	if motor.longitudNgrama > 0 {
+		return fmt.Sprintf("ascii (%s de %d-gramas de caracteres, hashing a %d dimensiones, %s)", describirFrecuencia(motor.relativa), motor.longitudNgrama, motor.dimensionesNgramas, describirMetrica(motor.metrica))
+	}
+	return "ascii (" + describirFrecuencia(motor.relativa) + " de caracteres, " + describirMetrica(motor.metrica) + ")"
+}
+
+func (motor MotorASCII) caracterizar(codigoFuente *CodigoFuente, contenido []byte) {
+	if motor.longitudNgrama > 0 {
+		codigoFuente.caracteristica = calcularFrecuenciasNgramasCaracteres(contenido, motor.longitudNgrama, motor.dimensionesNgramas, codigoFuente.caracteristica)
+	} else {
+		codigoFuente.caracteristica = calcularFrecuencias(contenido, codigoFuente.caracteristica)
+	}
+	if motor.relativa {
+		codigoFuente.proyeccion = calcularFrecuenciasRelativas(codigoFuente.caracteristica, sumarFrecuencias(codigoFuente.caracteristica))
+	}
+}
+
+func (motor MotorASCII) distancia(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	switch {
+	case motor.relativa && motor.metrica != nil:
+		return motor.metrica.reales(c1.proyeccion, c2.proyeccion)
+	case motor.relativa:
+		return calcularDistanciaVectores(c1.proyeccion, c2.proyeccion)
+	case motor.metrica != nil:
+		return motor.metrica.enteros(c1.caracteristica, c2.caracteristica)
+	}
+	return calcularDistancia(c1, c2)
+}
+
+func (motor MotorASCII) distanciaCuadrada(c1 CodigoFuente, c2 CodigoFuente) float64 {
+	if motor.relativa {
+		return calcularDistanciaVectoresCuadrada(c1.proyeccion, c2.proyeccion)
+	}
+	return calcularDistanciaCuadrada(c1, c2)
+}
+
+/*
+ * Función para crear el motor de características indicado por el usuario
+ * param: parámetros de ejecución
+ * return: el motor de características o un error si el motor o su puntaje no existen
+ */
+func crearMotor(parametros Parametros) (Motor, error) {
+	componentes, err := obtenerComponentesReduccion(parametros.reduccion)
+	if err != nil {
+		return nil, err
+	}
+	if componentes > 0 && parametros.motor != "tokens" {
+		return nil, fmt.Errorf("La reducción de dimensiones (--reduce) solo se aplica al motor \"tokens\"")
+	}
+	if parametros.dimensionesHash < 0 || (parametros.dimensionesHash > 0 && parametros.motor != "tokens") {
+		return nil, fmt.Errorf("Las dimensiones del hashing (--hash-dims) deben ser mayores a 0 y solo se aplican al motor \"tokens\"")
+	}
+	if parametros.dimensionesHash > 0 && componentes > 0 {
+		return nil, fmt.Errorf("El hashing (--hash-dims) y la reducción de dimensiones (--reduce) no se pueden combinar")
+	}
+	if parametros.rutaEspecificacion != "" && (parametros.motor != "tokens" || parametros.dimensionesHash > 0) {
+		return nil, fmt.Errorf("El enunciado (--spec) solo se aplica al motor \"tokens\" sin --hash-dims")
+	}
+	metrica, err := obtenerMetricaDistancia(parametros)
+	if err != nil {
+		return nil, err
+	}
+	relativa, err := obtenerFrecuenciasRelativas(parametros)
+	if err != nil {
+		return nil, err
+	}
+	longitudNgrama, err := obtenerNgramasCaracteres(parametros)
+	if err != nil {
+		return nil, err
+	}
+	lexico, err := obtenerAnalizadorLexico(parametros)
+	if err != nil {
+		return nil, err
+	}
+	if parametros.matrizGram {
+		if err := validarMatrizGram(parametros); err != nil {
+			return nil, err
+		}
+	}
+
+	switch parametros.motor {
+	case "ascii":
+		motor := MotorASCII{metrica: metrica, relativa: relativa, longitudNgrama: longitudNgrama}
+		if longitudNgrama > 0 {
+			motor.dimensionesNgramas = parametros.dimensionesVector
+		}
+		return motor, nil
+	case "lines":
+		if parametros.puntajeLineas != "jaccard" && parametros.puntajeLineas != "containment" {
+			return nil, fmt.Errorf("Puntaje \"%s\" no soportado por el motor de líneas (jaccard | containment)", parametros.puntajeLineas)
+		}
+		return MotorLineas{contencion: parametros.puntajeLineas == "containment"}, nil
+	case "tokens":
+		motor := MotorTokens{componentesPCA: componentes, dimensionesHash: parametros.dimensionesHash, metrica: metrica, relativa: relativa, lexico: lexico}
+		if parametros.rutaEspecificacion != "" {
+			if motor.especificacion, err = cargarEspecificacion(parametros.rutaEspecificacion, parametros.pesoEspecificacion); err != nil {
+				return nil, err
+			}
+		}
+		return motor, nil
+	case "ast":
+		if parametros.extension != "go" {
+			return nil, fmt.Errorf("El motor \"ast\" solo se aplica a los archivos .go")
+		}
+		return MotorAST{metrica: metrica, relativa: relativa}, nil
+	case "ncd":
+		return MotorNCD{}, nil
+	case "simhash":
+		return MotorSimHash{}, nil
+	}
+
+	return nil, fmt.Errorf("Motor \"%s\" no soportado (ascii | lines | tokens | ast | ncd | simhash)", parametros.motor)
+}
@@ -0,0 +1,124 @@
+/*
+ * Grupos en varios niveles de distancia máxima (--thresholds).
+ *
+ * Con una sola matriz de distancias se forman los grupos para cada distancia máxima indicada (de menor a mayor),
+ * de modo que se puede ver cómo crecen los grupos a medida que el criterio se relaja, sin repetir el análisis.
+ */
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Estructura de los grupos de un nivel
+// - distancia máxima del nivel
+// - grupos formados con esa distancia máxima
+type NivelGrupos struct {
+	distanciaMaxima float64
+	grupos          []Grupo
+}
+
+/*
+ * Función para obtener las distancias máximas de los niveles
+ * param: distancias separadas por comas (por ejemplo "20,50,100")
+ * return: las distancias sin repetir, de menor a mayor, o un error si alguna no es un número
+ */
+func obtenerUmbrales(texto string) ([]float64, error) {
+	var umbrales []float64
+
+	repetidos := make(map[float64]bool)
+	for _, valor := range strings.Split(texto, ",") {
+		umbral, err := strconv.ParseFloat(strings.TrimSpace(valor), 64)
+		if err != nil {
+			return umbrales, fmt.Errorf("La distancia máxima \"%s\" de --thresholds no es un número", valor)
+		}
+
+		if !repetidos[umbral] {
+			repetidos[umbral] = true
+			umbrales = append(umbrales, umbral)
+		}
+	}
+
+	sort.Float64s(umbrales)
+
+	return umbrales, nil
+}
+
+/*
+ * Función para determinar los grupos de cada nivel a partir de la misma matriz de distancias
+ * param: arreglo con la información del código fuente de los archivos y las distancias máximas de los niveles
+ * return: los grupos de cada nivel
+ */
+func determinarGruposPorNivel(tablaCodigoFuente []CodigoFuente, umbrales []float64) []NivelGrupos {
+	var niveles []NivelGrupos
+
+	for _, umbral := range umbrales {
+		niveles = append(niveles, NivelGrupos{distanciaMaxima: umbral, grupos: determinarGrupos(tablaCodigoFuente, umbral)})
+	}
+
+	return niveles
+}
+
+/*
+ * Función para obtener el nombre del archivo de un nivel, agregando su distancia máxima antes de la extensión
+ * param: nombre del archivo (por ejemplo "grupos.csv") y distancia máxima del nivel
+ * return: nombre del archivo del nivel (por ejemplo "grupos-20.csv")
+ */
+func obtenerNombreArchivoNivel(nombre string, distanciaMaxima float64) string {
+	extension := filepath.Ext(nombre)
+
+	return strings.TrimSuffix(nombre, extension) + "-" + strconv.FormatFloat(distanciaMaxima, 'f', -1, 64) + extension
+}
+
+/*
+ * Función para generar los archivos CSV y JSON con los grupos de cada nivel
+ * param: resultado del análisis y los nombres de los archivos CSV y JSON (vacíos si no se generan)
+ */
+func generarArchivosNiveles(resultado ResultadoAnalisis, nombreGruposCSV string, nombreGruposJSON string) {
+	for _, nivel := range resultado.niveles {
+		if nombreGruposCSV != "" {
+			nombre := obtenerNombreArchivoNivel(nombreGruposCSV, nivel.distanciaMaxima)
+			fmt.Println("             generando el archivo \"" + nombre + "\" con los grupos del nivel")
+			generarGruposCSV(resultado.archivos, nivel.grupos, nombre)
+		}
+		if nombreGruposJSON != "" {
+			nombre := obtenerNombreArchivoNivel(nombreGruposJSON, nivel.distanciaMaxima)
+			fmt.Println("             generando el archivo \"" + nombre + "\" con los grupos del nivel")
+			generarGruposJSON(resultado.archivos, nivel.grupos, crearCriterioValor(nivel.distanciaMaxima), nombre)
+		}
+	}
+}
+
+/*
+ * Función para imprimir el resumen de los niveles y los grupos de cada uno
+ * param: resultado del análisis
+ */
+func imprimirNiveles(resultado ResultadoAnalisis) {
+	fmt.Print("\nGRUPOS POR NIVEL DE DISTANCIA MÁXIMA\n\n")
+	fmt.Printf("%18s %8s %20s %18s\n", "DISTANCIA MÁXIMA", "GRUPOS", "ARCHIVOS AGRUPADOS", "GRUPO MÁS GRANDE")
+
+	for _, nivel := range resultado.niveles {
+		agrupados := make(map[int]bool)
+		mayor := 0
+		for _, grupo := range nivel.grupos {
+			for _, integrante := range grupo.integrantes {
+				agrupados[integrante.indice] = true
+			}
+			if len(grupo.integrantes) > mayor {
+				mayor = len(grupo.integrantes)
+			}
+		}
+
+		fmt.Printf("%18.2f %8d %20d %18d\n", nivel.distanciaMaxima, len(nivel.grupos), len(agrupados), mayor)
+	}
+	fmt.Println()
+
+	for _, nivel := range resultado.niveles {
+		imprimitGrupos(resultado.archivos, nivel.grupos, crearCriterioValor(nivel.distanciaMaxima))
+	}
+}
@@ -0,0 +1,160 @@
+/*
+ * Nombres de los grupos por sus términos característicos (--group-terms).
+ *
+ * El identificador de un grupo (hash del contenido del medoide) es estable pero no dice nada del código. Para
+ * reconocer los grupos de un vistazo, cada grupo se nombra con los identificadores más característicos de sus
+ * integrantes respecto al corpus, por ejemplo "GRUPO 3f9a1c2e: matrizTraspuesta, ordenarBurbuja". Se usa TF-IDF
+ * del grupo frente a los archivos del corpus:
+ * - tf: proporción de los integrantes del grupo que tienen el término (con las ocurrencias, las palabras reservadas
+ *   que se repiten en cada línea desplazan a los identificadores aunque estén en muchos archivos)
+ * - idf: logaritmo de la cantidad de archivos entre la cantidad de archivos con el término
+ * Los empates se resuelven por la cantidad de ocurrencias en el grupo, luego por la longitud (los identificadores
+ * suelen ser más largos que las palabras reservadas) y luego alfabéticamente.
+ * Los términos son los tokens que empiezan por una letra o "_" con al menos LONGITUD_MINIMA_TERMINO caracteres, que
+ * aparecen en al menos dos integrantes del grupo (un identificador de un solo archivo no caracteriza al grupo). Los
+ * términos de todos los archivos (palabras reservadas, nombres del enunciado) tienen idf 0 y no se usan.
+ */
+
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Cantidad mínima de caracteres de un término característico de un grupo
+const LONGITUD_MINIMA_TERMINO = 3
+
+// Estructura de un término candidato a nombrar un grupo
+// - texto del término
+// - puntaje TF-IDF del término en el grupo
+// - ocurrencias del término en el grupo
+type TerminoGrupo struct {
+	texto       string
+	puntaje     float64
+	ocurrencias int
+}
+
+/*
+ * Función para determinar si un token puede ser un término característico (un identificador)
+ * param: texto del token
+ * return: si empieza por una letra o "_" y tiene la longitud mínima
+ */
+func esTerminoCaracteristico(texto string) bool {
+	primero, _ := utf8.DecodeRuneInString(texto)
+
+	return utf8.RuneCountInString(texto) >= LONGITUD_MINIMA_TERMINO && (unicode.IsLetter(primero) || primero == '_')
+}
+
+/*
+ * Función para contar las ocurrencias de cada término de un archivo
+ * param: arreglo con la información del código fuente de los archivos e índice del archivo
+ * return: cantidad de ocurrencias de cada término en el archivo
+ */
+func contarTerminos(tablaCodigoFuente []CodigoFuente, indice int) map[string]int {
+	tokens := tablaCodigoFuente[indice].tokens
+	if tokens == nil {
+		tokens = obtenerTokens(tablaCodigoFuente[indice].contenido)
+	}
+
+	terminos := make(map[string]int)
+	for _, token := range tokens {
+		if esTerminoCaracteristico(token.texto) {
+			terminos[token.texto]++
+		}
+	}
+
+	return terminos
+}
+
+/*
+ * Función para nombrar cada grupo con sus términos más característicos respecto al corpus (TF-IDF)
+ * param: arreglo con la información del código fuente de los archivos, los grupos (se les asignan los términos)
+ *        y la cantidad de términos de cada grupo
+ */
+func nombrarGrupos(tablaCodigoFuente []CodigoFuente, grupos []Grupo, cantidadTerminos int) {
+	if len(grupos) == 0 || cantidadTerminos <= 0 {
+		return
+	}
+
+	enGrupos := make(map[int]bool)
+	for _, grupo := range grupos {
+		for _, integrante := range grupo.integrantes {
+			enGrupos[integrante.indice] = true
+		}
+	}
+
+	// Cantidad de archivos con cada término y ocurrencias de los términos de los archivos de los grupos
+	archivosConTermino := make(map[string]int)
+	terminosArchivo := make(map[int]map[string]int)
+	for i := range tablaCodigoFuente {
+		terminos := contarTerminos(tablaCodigoFuente, i)
+		for termino := range terminos {
+			archivosConTermino[termino]++
+		}
+		if enGrupos[i] {
+			terminosArchivo[i] = terminos
+		}
+	}
+
+	cantidadArchivos := float64(len(tablaCodigoFuente))
+
+	for g := range grupos {
+		ocurrencias := make(map[string]int)
+		integrantesConTermino := make(map[string]int)
+
+		for _, integrante := range grupos[g].integrantes {
+			for termino, cantidad := range terminosArchivo[integrante.indice] {
+				ocurrencias[termino] += cantidad
+				integrantesConTermino[termino]++
+			}
+		}
+		cantidadIntegrantes := float64(len(grupos[g].integrantes))
+
+		var candidatos []TerminoGrupo
+		for termino, cantidad := range ocurrencias {
+			if integrantesConTermino[termino] < 2 {
+				continue
+			}
+
+			puntaje := float64(integrantesConTermino[termino]) / cantidadIntegrantes * math.Log(cantidadArchivos/float64(archivosConTermino[termino]))
+			if puntaje > 0 {
+				candidatos = append(candidatos, TerminoGrupo{texto: termino, puntaje: puntaje, ocurrencias: cantidad})
+			}
+		}
+
+		sort.Slice(candidatos, func(a, b int) bool {
+			if candidatos[a].puntaje != candidatos[b].puntaje {
+				return candidatos[a].puntaje > candidatos[b].puntaje
+			}
+			if candidatos[a].ocurrencias != candidatos[b].ocurrencias {
+				return candidatos[a].ocurrencias > candidatos[b].ocurrencias
+			}
+			if len(candidatos[a].texto) != len(candidatos[b].texto) {
+				return len(candidatos[a].texto) > len(candidatos[b].texto)
+			}
+			return candidatos[a].texto < candidatos[b].texto
+		})
+
+		grupos[g].terminos = nil
+		for k := 0; k < len(candidatos) && k < cantidadTerminos; k++ {
+			grupos[g].terminos = append(grupos[g].terminos, candidatos[k].texto)
+		}
+	}
+}
+
+/*
+ * Función para describir el nombre de un grupo: su identificador y sus términos característicos
+ * param: identificador y términos característicos del grupo
+ * return: por ejemplo "3f9a1c2e: matrizTraspuesta, ordenarBurbuja" (solo el identificador si no tiene términos)
+ */
+func describirNombreGrupo(identificador string, terminos []string) string {
+	if len(terminos) == 0 {
+		return identificador
+	}
+
+	return identificador + ": " + strings.Join(terminos, ", ")
+}
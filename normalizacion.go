@@ -0,0 +1,108 @@
+/*
+ * Normalización del contenido antes del análisis (--collapse-whitespace, --lowercase y --rename-identifiers).
+ *
+ * Las copias se suelen disimular con cambios que no alteran el programa: reindentar o cambiar el espaciado, cambiar
+ * mayúsculas y minúsculas, o renombrar las variables y funciones. Cada transformación se activa por separado y se
+ * aplica a todos los motores, después de eliminar el encabezado y los comentarios (ver preprocesamiento.go):
+ * - --rename-identifiers: cada identificador que no es palabra reservada del lenguaje de la extensión se reemplaza
+ *   por TOKEN_IDENTIFICADOR ("ID"); el texto de las cadenas y de los comentarios se conserva. Usa las palabras
+ *   reservadas del analizador léxico del lenguaje (ver lexicos.go), por lo que solo se admite en las extensiones
+ *   con analizador (go, java, py, c, h)
+ * - --lowercase: todo el contenido en minúsculas (después de renombrar, ya que las palabras reservadas de algunos
+ *   lenguajes distinguen mayúsculas, como True en Python)
+ * - --collapse-whitespace: los espacios y tabuladores de cada línea se reducen a uno solo y se eliminan los del inicio
+ *   y el final de la línea (la indentación)
+ * Las transformaciones conservan los saltos de línea, por lo que los números de línea de la evidencia no cambian.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+/*
+ * Función para obtener el analizador léxico con el que se renombran los identificadores
+ * param: extensión de los archivos
+ * return: el analizador léxico del lenguaje o un error si la extensión no tiene analizador
+ */
+func obtenerAnalizadorRenombrado(extension string) (*AnalizadorLexico, error) {
+	analizador, existe := analizadoresLexicos[lenguajesPorExtension[strings.ToLower(extension)]]
+	if !existe {
+		return nil, fmt.Errorf("No hay palabras reservadas para renombrar los identificadores de la extensión .%s (--rename-identifiers solo se admite con go, java, py, c y h)", extension)
+	}
+
+	return analizador, nil
+}
+
+/*
+ * Función para reemplazar los identificadores de un contenido por TOKEN_IDENTIFICADOR
+ * param: contenido del archivo y analizador léxico del lenguaje (palabras reservadas, cadenas y comentarios)
+ * return: contenido con los identificadores renombrados (con los mismos números de línea)
+ */
+func normalizarIdentificadores(contenido []byte, analizador *AnalizadorLexico) []byte {
+	texto := []rune(string(contenido))
+	var resultado strings.Builder
+	resultado.Grow(len(contenido))
+
+	for i := 0; i < len(texto); {
+		fin := i + 1
+
+		switch {
+		case iniciaCon(texto, i, analizador.sintaxis.inicioBloque):
+			fin = encontrarCierre(texto, i+len([]rune(analizador.sintaxis.inicioBloque)), analizador.sintaxis.finBloque, true, false)
+
+		case analizador.esComentarioLinea(texto, i):
+			for fin < len(texto) && texto[fin] != '\n' {
+				fin++
+			}
+
+		case analizador.esIdentificador(texto[i], true):
+			for fin < len(texto) && analizador.esIdentificador(texto[fin], false) {
+				fin++
+			}
+			if palabra := string(texto[i:fin]); !analizador.palabrasClave[palabra] {
+				resultado.WriteString(TOKEN_IDENTIFICADOR)
+				i = fin
+				continue
+			}
+
+		case unicode.IsDigit(texto[i]):
+			// Los sufijos de los números (por ejemplo 10L o 0xFF) no son identificadores
+			for fin < len(texto) && (unicode.IsLetter(texto[fin]) || unicode.IsDigit(texto[fin]) || texto[fin] == '_') {
+				fin++
+			}
+
+		default:
+			if delimitador, multilinea, escape := analizador.iniciarCadena(texto, i); delimitador != "" {
+				fin = encontrarCierre(texto, i+len([]rune(delimitador)), delimitador, multilinea, escape)
+			}
+		}
+
+		if fin > len(texto) {
+			fin = len(texto)
+		}
+		resultado.WriteString(string(texto[i:fin]))
+		i = fin
+	}
+
+	return []byte(resultado.String())
+}
+
+/*
+ * Función para reducir los espacios y tabuladores de cada línea de un contenido a uno solo
+ * param: contenido del archivo
+ * return: contenido sin indentación ni espacios repetidos (con los mismos números de línea)
+ */
+func colapsarEspacios(contenido []byte) []byte {
+	lineas := bytes.Split(contenido, []byte("\n"))
+
+	for i, linea := range lineas {
+		lineas[i] = bytes.Join(bytes.Fields(linea), []byte(" "))
+	}
+
+	return bytes.Join(lineas, []byte("\n"))
+}
@@ -0,0 +1,84 @@
+/*
+ * Núcleos del cálculo de las distancias (--distance-kernel).
+ *
+ * La fase 2 (distancia entre todos los pares) recorre n * (n - 1) / 2 veces los vectores de características, por lo
+ * que su ciclo interno domina el tiempo del análisis con corpus grandes. Hay dos núcleos:
+ * - "generic": el ciclo original, un elemento a la vez con math.Pow (referencia para comparar resultados)
+ * - "unrolled": ciclo desenrollado de a 4 elementos con 4 acumuladores independientes, multiplicaciones en lugar de
+ *   math.Pow, diferencias enteras exactas (motor "ascii" y --hash-dims) y sin verificación de límites en el ciclo,
+ *   lo que permite al procesador ejecutar varias operaciones a la vez (paralelismo a nivel de instrucción)
+ *
+ * Con "default" (por defecto) el núcleo se selecciona al iniciar solamente según el tamaño de int: "unrolled" si es de
+ * 64 bits y "generic" si es de 32 bits, en los que los acumuladores enteros del núcleo desenrollado se podrían
+ * desbordar. No se detectan las características del procesador (SIMD, AVX2): SASC se compila como una lista de
+ * archivos .go (go build *.go), que no admite archivos en ensamblador ni restricciones de compilación por
+ * arquitectura, un núcleo en C (cgo) obligaría a tener un compilador de C y golang.org/x/sys/cpu sería la primera
+ * dependencia del módulo; por eso los núcleos son de Go puro y la aceleración viene del paralelismo a nivel de
+ * instrucción, no de instrucciones vectoriales. "auto", el nombre anterior de "default", se sigue aceptando.
+ *
+ * Con vectores enteros ambos núcleos dan exactamente la misma distancia; con vectores reales (--reduce) el orden de
+ * las sumas cambia y la distancia puede diferir en los últimos decimales.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jugutier73/SASC/pkg/sasc"
+)
+
+// Estructura de un núcleo del cálculo de las distancias
+// - nombre del núcleo (--distance-kernel)
+// - cuadrado de la distancia euclidiana entre dos vectores enteros y entre dos vectores reales (de la misma longitud)
+type NucleoDistancia struct {
+	nombre  string
+	enteros func(v1 []int, v2 []int) float64
+	reales  func(v1 []float64, v2 []float64) float64
+}
+
+// Núcleos disponibles (implementados en pkg/sasc)
+var nucleosDistancia = []NucleoDistancia{
+	{"generic", sasc.SquaredDistanceGeneric, sasc.SquaredDistanceFloatGeneric},
+	{"unrolled", sasc.SquaredDistanceUnrolled, sasc.SquaredDistanceFloatUnrolled},
+}
+
+// Núcleo con el que se calculan las distancias (se selecciona una sola vez, al iniciar)
+var nucleoDistancia = seleccionarNucleoPorDefecto()
+
+/*
+ * Función para seleccionar el núcleo por defecto según el tamaño de int (sin detectar las características del procesador)
+ * return: "unrolled" si int es de 64 bits y "generic" si es de 32 bits (los acumuladores enteros se podrían desbordar)
+ */
+func seleccionarNucleoPorDefecto() NucleoDistancia {
+	if strconv.IntSize == 64 {
+		return nucleosDistancia[1]
+	}
+
+	return nucleosDistancia[0]
+}
+
+/*
+ * Función para configurar el núcleo con el que se calculan las distancias
+ * param: nombre del núcleo ("default" o su nombre anterior "auto", "generic" o "unrolled")
+ * return: un error si el núcleo no existe
+ */
+func configurarNucleoDistancia(nombre string) error {
+	if nombre == "default" || nombre == "auto" {
+		nucleoDistancia = seleccionarNucleoPorDefecto()
+		return nil
+	}
+
+	var nombres []string
+	for _, nucleo := range nucleosDistancia {
+		if nucleo.nombre == nombre {
+			nucleoDistancia = nucleo
+			return nil
+		}
+		nombres = append(nombres, nucleo.nombre)
+	}
+
+	return fmt.Errorf("Núcleo de distancias \"%s\" no soportado (default | %s)", nombre, strings.Join(nombres, " | "))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestConfigurarNucleoDistancia(t *testing.T) {
+	anterior := nucleoDistancia
+	defer func() { nucleoDistancia = anterior }()
+
+	porDefecto := "generic"
+	if strconv.IntSize == 64 {
+		porDefecto = "unrolled"
+	}
+
+	casos := []struct {
+		nombre   string
+		esperado string
+		valido   bool
+	}{
+		{"default", porDefecto, true},
+		{"auto", porDefecto, true},
+		{"generic", "generic", true},
+		{"unrolled", "unrolled", true},
+		{"avx2", "", false},
+		{"", "", false},
+	}
+
+	for _, caso := range casos {
+		err := configurarNucleoDistancia(caso.nombre)
+		if (err == nil) != caso.valido {
+			t.Errorf("configurarNucleoDistancia(%q) error = %v", caso.nombre, err)
+			continue
+		}
+		if caso.valido && nucleoDistancia.nombre != caso.esperado {
+			t.Errorf("configurarNucleoDistancia(%q) = %s, se esperaba %s", caso.nombre, nucleoDistancia.nombre, caso.esperado)
+		}
+	}
+}
+
+func TestNucleosDistanciaIguales(t *testing.T) {
+	aleatorio := rand.New(rand.NewSource(1))
+
+	for _, longitud := range []int{0, 1, 3, 4, 7, MAX_ASCII} {
+		v1, v2 := make([]int, longitud), make([]int, longitud)
+		for i := range v1 {
+			v1[i], v2[i] = aleatorio.Intn(5000), aleatorio.Intn(5000)
+		}
+
+		generica, desenrollada := nucleosDistancia[0].enteros(v1, v2), nucleosDistancia[1].enteros(v1, v2)
+		if generica != desenrollada {
+			t.Errorf("longitud %d: núcleo genérico = %v, desenrollado = %v", longitud, generica, desenrollada)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+/*
+ * Ofuscaciones sintéticas de un archivo (comando robustness).
+ *
+ * Para saber qué tan robusto es un motor ante los cambios con los que se suele disfrazar una copia, se le aplican al
+ * archivo ofuscaciones sintéticas que no cambian lo que hace el programa:
+ * - "rename": renombra de forma consistente los identificadores (v1, v2, ...) que aparecen al menos una vez sin un
+ *   punto a sus lados (los declarados en el archivo), salvo las palabras reservadas y los nombres comunes de las
+ *   bibliotecas (palabrasConservadas); los paquetes y los métodos de las bibliotecas (fmt.Println,
+ *   System.out.println) solo aparecen junto a un punto y no se renombran
+ * - "reorder": invierte el orden de los bloques hermanos (funciones, métodos o tipos) del primer nivel que tenga al
+ *   menos dos, por ejemplo las funciones de un archivo de Go o los métodos de la clase de un archivo de Java
+ * - "comments": elimina los comentarios y agrega un comentario genérico cada LINEAS_COMENTARIO_OFUSCACION líneas
+ * - "whitespace": cambia la indentación (dos espacios por nivel), elimina las líneas en blanco y agrega una después
+ *   de cada línea que abre un bloque
+ * - "all": todas las anteriores, en ese orden
+ * Las ofuscaciones reconocen las cadenas y los comentarios con la sintaxis del lenguaje (ver comentarios.go), por lo
+ * que no renombran el texto de una cadena ni cuentan las llaves de un comentario.
+ */
+
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Cantidad de líneas entre los comentarios genéricos de la ofuscación de comentarios
+const LINEAS_COMENTARIO_OFUSCACION = 5
+
+// Profundidad máxima de los bloques que se buscan para reordenar
+const PROFUNDIDAD_REORDENAMIENTO = 4
+
+// Tipos de los segmentos de un contenido
+const (
+	SEGMENTO_CODIGO = iota
+	SEGMENTO_CADENA
+	SEGMENTO_COMENTARIO
+)
+
+// Estructura de un segmento de un contenido
+// - texto del segmento
+// - tipo (código, cadena o comentario)
+type SegmentoCodigo struct {
+	texto string
+	tipo  int
+}
+
+// Estructura de una ofuscación sintética
+//   - nombre de la ofuscación
+//   - función que la aplica a un contenido con la sintaxis de su lenguaje y retorna el contenido ofuscado y la
+//     cantidad de cambios (identificadores, bloques, comentarios o líneas)
+//   - descripción de los cambios para el reporte
+type OfuscacionSintetica struct {
+	nombre      string
+	aplicar     func(contenido string, sintaxis SintaxisComentarios) (string, int)
+	descripcion string
+}
+
+// Ofuscaciones sintéticas en el orden del reporte (all las aplica todas)
+var ofuscacionesSinteticas = []OfuscacionSintetica{
+	{nombre: "rename", aplicar: renombrarIdentificadores, descripcion: "identificadores renombrados"},
+	{nombre: "reorder", aplicar: reordenarBloques, descripcion: "bloques reordenados"},
+	{nombre: "comments", aplicar: cambiarComentarios, descripcion: "comentarios agregados"},
+	{nombre: "whitespace", aplicar: cambiarEspacios, descripcion: "líneas reindentadas"},
+	{nombre: "all", aplicar: aplicarTodasOfuscaciones, descripcion: "cambios"},
+}
+
+// Palabras reservadas y nombres comunes de las bibliotecas de los lenguajes más comunes, que no se renombran
+var palabrasConservadas = crearConjuntoPalabras(`
+	break case chan const continue default defer else fallthrough for func go goto if import interface map package
+	range return select struct switch type var nil true false iota append cap close copy delete len make new panic
+	print println recover bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune string
+	uint uint8 uint16 uint32 uint64 uintptr any main
+	abstract assert boolean catch char class do double enum extends final finally float implements instanceof long
+	native private protected public short static super synchronized this throw throws transient try void volatile
+	while null String Object Integer Double Boolean Character Long Math System Scanner List ArrayList Map HashMap
+	Exception args length
+	auto extern register signed sizeof typedef union unsigned include define printf scanf malloc free std cout cin
+	endl vector using namespace template typename virtual delete operator friend inline
+	and as def del elif except from global in is lambda nonlocal not or pass raise with yield None True False self
+	range input print len int str float list dict set tuple open
+	function let async await export undefined console document window require module`)
+
+/*
+ * Función para crear un conjunto de palabras
+ * param: palabras separadas por espacios
+ * return: conjunto de las palabras
+ */
+func crearConjuntoPalabras(palabras string) map[string]bool {
+	conjunto := make(map[string]bool)
+	for _, palabra := range strings.Fields(palabras) {
+		conjunto[palabra] = true
+	}
+
+	return conjunto
+}
+
+/*
+ * Función para dividir un contenido en segmentos de código, cadenas y comentarios
+ * param: contenido y sintaxis de los comentarios y cadenas del lenguaje
+ * return: los segmentos en orden (su concatenación es el contenido)
+ */
+func segmentarCodigo(texto string, sintaxis SintaxisComentarios) []SegmentoCodigo {
+	var segmentos []SegmentoCodigo
+	inicioCodigo := 0
+
+	agregar := func(fin int, tipo int, finSegmento int) {
+		if fin > inicioCodigo {
+			segmentos = append(segmentos, SegmentoCodigo{texto: texto[inicioCodigo:fin], tipo: SEGMENTO_CODIGO})
+		}
+		segmentos = append(segmentos, SegmentoCodigo{texto: texto[fin:finSegmento], tipo: tipo})
+		inicioCodigo = finSegmento
+	}
+
+	for i := 0; i < len(texto); {
+		caracter := texto[i]
+
+		if strings.IndexByte(sintaxis.cadenas, caracter) >= 0 || strings.IndexByte(sintaxis.cadenasCrudas, caracter) >= 0 {
+			escape := strings.IndexByte(sintaxis.cadenasCrudas, caracter) < 0
+			fin := i + 1
+			for fin < len(texto) && texto[fin] != caracter {
+				if escape && texto[fin] == '\\' {
+					fin++
+				} else if escape && texto[fin] == '\n' {
+					break
+				}
+				fin++
+			}
+			if fin < len(texto) && texto[fin] == caracter {
+				fin++
+			}
+			if fin > len(texto) {
+				fin = len(texto)
+			}
+			agregar(i, SEGMENTO_CADENA, fin)
+			i = fin
+			continue
+		}
+
+		if sintaxis.inicioBloque != "" && strings.HasPrefix(texto[i:], sintaxis.inicioBloque) {
+			fin := strings.Index(texto[i+len(sintaxis.inicioBloque):], sintaxis.finBloque)
+			if fin < 0 {
+				fin = len(texto)
+			} else {
+				fin += i + len(sintaxis.inicioBloque) + len(sintaxis.finBloque)
+			}
+			agregar(i, SEGMENTO_COMENTARIO, fin)
+			i = fin
+			continue
+		}
+
+		comentarioLinea := false
+		for _, marcador := range sintaxis.linea {
+			if strings.HasPrefix(texto[i:], marcador) {
+				comentarioLinea = true
+				break
+			}
+		}
+		if comentarioLinea {
+			fin := strings.IndexByte(texto[i:], '\n')
+			if fin < 0 {
+				fin = len(texto)
+			} else {
+				fin += i
+			}
+			agregar(i, SEGMENTO_COMENTARIO, fin)
+			i = fin
+			continue
+		}
+
+		i++
+	}
+
+	if inicioCodigo < len(texto) {
+		segmentos = append(segmentos, SegmentoCodigo{texto: texto[inicioCodigo:], tipo: SEGMENTO_CODIGO})
+	}
+
+	return segmentos
+}
+
+/*
+ * Función para determinar si un byte hace parte de un identificador (los bytes de UTF-8 no se separan)
+ * param: byte
+ * return: si es una letra, un dígito, "_" o un byte de un carácter no ASCII
+ */
+func esByteIdentificador(caracter byte) bool {
+	return caracter == '_' || caracter >= 0x80 || ('a' <= caracter && caracter <= 'z') || ('A' <= caracter && caracter <= 'Z') || ('0' <= caracter && caracter <= '9')
+}
+
+/*
+ * Función para obtener el primer carácter que no es un espacio antes o después de una posición
+ * param: texto, posición y dirección (-1 hacia atrás, 1 hacia adelante)
+ * return: el carácter o 0 si no hay
+ */
+func obtenerVecinoSinEspacio(texto string, posicion int, direccion int) byte {
+	for i := posicion; i >= 0 && i < len(texto); i += direccion {
+		if texto[i] != ' ' && texto[i] != '\t' && texto[i] != '\n' && texto[i] != '\r' {
+			return texto[i]
+		}
+	}
+
+	return 0
+}
+
+/*
+ * Función para recorrer los identificadores del código (sin cadenas ni comentarios) y reemplazarlos
+ * param: contenido, sintaxis del lenguaje y función que recibe cada identificador con el carácter anterior y el
+ *        siguiente que no son espacios y retorna su reemplazo
+ * return: contenido con los identificadores reemplazados
+ */
+func reemplazarIdentificadores(contenido string, sintaxis SintaxisComentarios, reemplazo func(palabra string, anterior byte, siguiente byte) string) string {
+	var resultado strings.Builder
+
+	for _, segmento := range segmentarCodigo(contenido, sintaxis) {
+		if segmento.tipo != SEGMENTO_CODIGO {
+			resultado.WriteString(segmento.texto)
+			continue
+		}
+
+		texto := segmento.texto
+		for i := 0; i < len(texto); {
+			if !esByteIdentificador(texto[i]) {
+				resultado.WriteByte(texto[i])
+				i++
+				continue
+			}
+
+			fin := i
+			for fin < len(texto) && esByteIdentificador(texto[fin]) {
+				fin++
+			}
+			if palabra := texto[i:fin]; '0' <= palabra[0] && palabra[0] <= '9' {
+				resultado.WriteString(palabra)
+			} else {
+				resultado.WriteString(reemplazo(palabra, obtenerVecinoSinEspacio(texto, i-1, -1), obtenerVecinoSinEspacio(texto, fin, 1)))
+			}
+			i = fin
+		}
+	}
+
+	return resultado.String()
+}
+
+/*
+ * Función para renombrar de forma consistente los identificadores del código (ofuscación "rename"). Se renombran en
+ * todas sus apariciones los identificadores que aparecen al menos una vez sin un punto a sus lados.
+ * param: contenido y sintaxis del lenguaje
+ * return: contenido con los identificadores renombrados y cantidad de identificadores distintos renombrados
+ */
+func renombrarIdentificadores(contenido string, sintaxis SintaxisComentarios) (string, int) {
+	candidatos := make(map[string]bool)
+	reemplazarIdentificadores(contenido, sintaxis, func(palabra string, anterior byte, siguiente byte) string {
+		if anterior != '.' && siguiente != '.' && !palabrasConservadas[palabra] {
+			candidatos[palabra] = true
+		}
+		return palabra
+	})
+
+	nombres := make(map[string]string)
+	renombrado := reemplazarIdentificadores(contenido, sintaxis, func(palabra string, anterior byte, siguiente byte) string {
+		if !candidatos[palabra] {
+			return palabra
+		}
+
+		nombre, existe := nombres[palabra]
+		if !existe {
+			nombre = "v" + strconv.Itoa(len(nombres)+1)
+			nombres[palabra] = nombre
+		}
+		return nombre
+	})
+
+	return renombrado, len(nombres)
+}
+
+/*
+ * Función para calcular la profundidad de los bloques al inicio y al final de cada línea (sin cadenas ni comentarios)
+ * param: contenido y sintaxis del lenguaje
+ * return: profundidad al inicio de cada línea, profundidad al final de cada línea
+ */
+func calcularProfundidadLineas(contenido string, sintaxis SintaxisComentarios) ([]int, []int) {
+	var inicio, fin []int
+	profundidad := 0
+	inicio = append(inicio, 0)
+
+	for _, segmento := range segmentarCodigo(contenido, sintaxis) {
+		for i := 0; i < len(segmento.texto); i++ {
+			switch {
+			case segmento.texto[i] == '\n':
+				fin = append(fin, profundidad)
+				inicio = append(inicio, profundidad)
+			case segmento.tipo != SEGMENTO_CODIGO:
+			case segmento.texto[i] == '{':
+				profundidad++
+			case segmento.texto[i] == '}':
+				profundidad--
+			}
+		}
+	}
+	fin = append(fin, profundidad)
+
+	return inicio, fin
+}
+
+/*
+ * Función para encontrar los bloques de un nivel entre dos líneas: desde una línea que abre un bloque (o la anterior
+ * a una línea que empieza con la llave) hasta la línea en la que se cierra
+ * param: líneas, profundidad al inicio y al final de cada línea, rango de líneas y nivel
+ * return: la primera y la última línea de cada bloque
+ */
+func encontrarBloques(lineas []string, inicio []int, fin []int, desde int, hasta int, nivel int) [][2]int {
+	var bloques [][2]int
+
+	for i := desde; i < hasta; i++ {
+		if inicio[i] != nivel || strings.TrimSpace(lineas[i]) == "" {
+			continue
+		}
+
+		apertura := i
+		if fin[i] == nivel && i+1 < hasta && strings.HasPrefix(strings.TrimSpace(lineas[i+1]), "{") {
+			apertura = i + 1 // Llave en la línea siguiente (estilo Allman)
+		}
+		if fin[apertura] <= nivel {
+			continue
+		}
+
+		cierre := apertura
+		for cierre < hasta && fin[cierre] != nivel {
+			cierre++
+		}
+		if cierre >= hasta {
+			break
+		}
+
+		bloques = append(bloques, [2]int{i, cierre})
+		i = cierre
+	}
+
+	return bloques
+}
+
+/*
+ * Función para invertir el orden de los bloques hermanos del primer nivel que tenga al menos dos (ofuscación "reorder")
+ * param: contenido y sintaxis del lenguaje
+ * return: contenido con los bloques reordenados y cantidad de bloques reordenados (0 si no hay dos bloques hermanos)
+ */
+func reordenarBloques(contenido string, sintaxis SintaxisComentarios) (string, int) {
+	lineas := strings.Split(contenido, "\n")
+	inicio, fin := calcularProfundidadLineas(contenido, sintaxis)
+
+	desde, hasta := 0, len(lineas)
+	for nivel := 0; nivel < PROFUNDIDAD_REORDENAMIENTO; nivel++ {
+		bloques := encontrarBloques(lineas, inicio, fin, desde, hasta, nivel)
+
+		if len(bloques) == 1 {
+			// Un único bloque (por ejemplo la clase de un archivo de Java): se buscan los bloques dentro de él
+			desde, hasta = bloques[0][0]+1, bloques[0][1]
+			continue
+		}
+		if len(bloques) < 2 {
+			break
+		}
+
+		var reordenadas []string
+		anterior := 0
+		for k, bloque := range bloques {
+			reflejo := bloques[len(bloques)-1-k]
+			reordenadas = append(reordenadas, lineas[anterior:bloque[0]]...)
+			reordenadas = append(reordenadas, lineas[reflejo[0]:reflejo[1]+1]...)
+			anterior = bloque[1] + 1
+		}
+		reordenadas = append(reordenadas, lineas[anterior:]...)
+
+		return strings.Join(reordenadas, "\n"), len(bloques)
+	}
+
+	return contenido, 0
+}
+
+/*
+ * Función para eliminar los comentarios y agregar comentarios genéricos (ofuscación "comments")
+ * param: contenido y sintaxis del lenguaje
+ * return: contenido con los comentarios cambiados y cantidad de comentarios agregados
+ */
+func cambiarComentarios(contenido string, sintaxis SintaxisComentarios) (string, int) {
+	lineas := strings.Split(string(eliminarComentarios([]byte(contenido), sintaxis)), "\n")
+
+	apertura, cierre := "", ""
+	switch {
+	case len(sintaxis.linea) > 0:
+		apertura = sintaxis.linea[0] + " "
+	case sintaxis.inicioBloque != "":
+		apertura, cierre = sintaxis.inicioBloque+" ", " "+sintaxis.finBloque
+	default:
+		return strings.Join(lineas, "\n"), 0
+	}
+
+	var resultado []string
+	agregados := 0
+	for i, linea := range lineas {
+		if i%LINEAS_COMENTARIO_OFUSCACION == 0 && strings.TrimSpace(linea) != "" {
+			agregados++
+			sangria := linea[:len(linea)-len(strings.TrimLeft(linea, " \t"))]
+			resultado = append(resultado, sangria+apertura+"Paso "+strconv.Itoa(agregados)+cierre)
+		}
+		resultado = append(resultado, strings.TrimRight(linea, " \t"))
+	}
+
+	return strings.Join(resultado, "\n"), agregados
+}
+
+/*
+ * Función para cambiar la indentación y las líneas en blanco (ofuscación "whitespace")
+ * param: contenido y sintaxis del lenguaje
+ * return: contenido con los espacios cambiados y cantidad de líneas con contenido
+ */
+func cambiarEspacios(contenido string, sintaxis SintaxisComentarios) (string, int) {
+	var resultado []string
+	cantidad := 0
+
+	for _, linea := range strings.Split(contenido, "\n") {
+		texto := strings.TrimSpace(linea)
+		if texto == "" {
+			continue
+		}
+
+		// Nivel de indentación: un tabulador o cuatro espacios por nivel
+		nivel, espacios := 0, 0
+		for _, caracter := range linea[:len(linea)-len(strings.TrimLeft(linea, " \t"))] {
+			if caracter == '\t' {
+				nivel++
+			} else if espacios++; espacios == 4 {
+				nivel, espacios = nivel+1, 0
+			}
+		}
+
+		cantidad++
+		resultado = append(resultado, strings.Repeat("  ", nivel)+texto)
+		if strings.HasSuffix(texto, "{") {
+			resultado = append(resultado, "")
+		}
+	}
+
+	return strings.Join(resultado, "\n") + "\n", cantidad
+}
+
+/*
+ * Función para aplicar todas las ofuscaciones en orden (ofuscación "all")
+ * param: contenido y sintaxis del lenguaje
+ * return: contenido ofuscado y cantidad total de cambios
+ */
+func aplicarTodasOfuscaciones(contenido string, sintaxis SintaxisComentarios) (string, int) {
+	total := 0
+
+	for _, aplicar := range []func(string, SintaxisComentarios) (string, int){renombrarIdentificadores, reordenarBloques, cambiarComentarios, cambiarEspacios} {
+		var cambios int
+		contenido, cambios = aplicar(contenido, sintaxis)
+		total += cambios
+	}
+
+	return contenido, total
+}
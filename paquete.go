@@ -0,0 +1,401 @@
+/*
+ * Paquete de reporte estático (--bundle).
+ *
+ * Genera un directorio autocontenido con el reporte del análisis, que se puede comprimir y compartir o publicar
+ * en cualquier servidor web estático, sin necesidad de ejecutar SASC como servidor:
+ * - index.html                     resumen, grupos, archivos y pares ordenados por distancia, con enlaces a sus páginas
+ * - pares/par-NNNN.html            código de ambos archivos lado a lado, con las líneas coincidentes resaltadas, y la
+ *                                  contribución de las características a su distancia (ver contribucion_dimensiones.go)
+ * - archivos/archivo-NNNN.html     vecinos más cercanos de cada archivo y minigráfico de sus distancias frente al corpus
+ * - archivos/minigrafico-NNNN.png  minigráfico de las distancias de cada archivo
+ * - recursos/estilo.css            hoja de estilos de todas las páginas
+ * - datos/resultado.json           el mismo resultado JSON del modo de entrada estándar
+ *
+ * Se genera una página por cada par a la distancia máxima; sin distancia máxima, por los pares más cercanos.
+ * Cada archivo tiene además su página, con sus vecinos más cercanos y su minigráfico (ver minigrafico.go).
+ * Las líneas coincidentes se comparan normalizadas (como la evidencia del motor de líneas) con cualquier motor.
+ * Los datos personales de los comentarios se ocultan en el código de los pares, salvo con --bundle-keep-pii (ver redaccion.go).
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Cantidad de pares con página en el paquete cuando no se define una distancia máxima
+const PARES_PAQUETE_SIN_DISTANCIA = 50
+
+// Cantidad de vecinos en la página de cada archivo del paquete
+const VECINOS_PAGINA_ARCHIVO = 10
+
+// Hoja de estilos del paquete
+const ESTILO_PAQUETE = `body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 8px; text-align: left; }
+.codigo { display: flex; gap: 1em; }
+.codigo > section { flex: 1; overflow-x: auto; }
+pre { margin: 0; font-size: 13px; }
+pre span { display: block; }
+pre span.coincide { background: #ffe08a; }
+pre span i { display: inline-block; width: 4em; color: #888; font-style: normal; user-select: none; }
+img.minigrafico { border: 1px solid #ccc; vertical-align: middle; }
+.barra { display: inline-block; height: 0.8em; background: #c0504d; vertical-align: middle; }
+.barra.comun { background: #4f81bd; }
+`
+
+// Estructura de un par con página en el paquete
+// - índices de los dos archivos, distancia entre ellos y nombre de su página (relativo al directorio del paquete)
+type ParPaquete struct {
+	archivo1  int
+	archivo2  int
+	distancia float64
+	pagina    string
+}
+
+/*
+ * Función para escribir un archivo del paquete, creando su directorio si no existe
+ * param: directorio del paquete, ruta del archivo dentro del paquete y contenido
+ */
+func escribirArchivoPaquete(directorio string, ruta string, contenido string) {
+	nombre := filepath.Join(directorio, filepath.FromSlash(ruta))
+
+	if err := os.MkdirAll(filepath.Dir(nombre), 0755); err != nil {
+		panic(err)
+	}
+
+	if err := ioutil.WriteFile(nombre, []byte(contenido), 0644); err != nil {
+		panic(err)
+	}
+}
+
+/*
+ * Función para obtener los pares con página en el paquete
+ * param: resultado del análisis
+ * return: los pares a la distancia máxima (o los más cercanos si no se definió), del más cercano al más lejano
+ */
+func obtenerParesPaquete(resultado ResultadoAnalisis) []ParPaquete {
+	var pares []ParPaquete
+	var distancias []float64
+
+	for i := range resultado.archivos {
+		for j := i + 1; j < len(resultado.archivos); j++ {
+			if esDistanciaCalculada(resultado.matriz[i][j]) {
+				pares = append(pares, ParPaquete{archivo1: i, archivo2: j, distancia: resultado.matriz[i][j]})
+				distancias = append(distancias, resultado.matriz[i][j])
+			}
+		}
+	}
+
+	var seleccionados []ParPaquete
+	for _, indice := range ordenarIndicesPorDistancia(distancias) {
+		par := pares[indice]
+
+		if resultado.tieneDistanciaMaxima() && par.distancia > resultado.criterio.limite() {
+			break
+		}
+		if !resultado.tieneDistanciaMaxima() && len(seleccionados) == PARES_PAQUETE_SIN_DISTANCIA {
+			break
+		}
+
+		par.pagina = fmt.Sprintf("pares/par-%04d.html", len(seleccionados)+1)
+		seleccionados = append(seleccionados, par)
+	}
+
+	return seleccionados
+}
+
+/*
+ * Función para iniciar una página del paquete
+ * param: elemento title de la página, ruta de la hoja de estilos relativa a la página, marca institucional
+ *        y si el reporte es accesible
+ * return: el inicio de la página
+ */
+func iniciarPaginaPaquete(titulo string, estilo string, marca MarcaReporte, accesible bool) string {
+	return fmt.Sprintf("<!DOCTYPE html><html lang=\"es\"><head><meta charset=\"utf-8\">%s<link rel=\"stylesheet\" href=\"%s\"></head><body>\n%s%s",
+		titulo, estilo, generarSelectorIdioma(accesible), marca.encabezado())
+}
+
+/*
+ * Función para terminar una página del paquete
+ * param: marca institucional
+ * return: el final de la página
+ */
+func terminarPaginaPaquete(marca MarcaReporte) string {
+	return marca.pie() + generarScriptIdiomas() + "</body></html>\n"
+}
+
+/*
+ * Función para generar la página principal del paquete
+ * param: resultado del análisis, pares con página, bandas de severidad, distribución de las distancias del corpus,
+ *        marca institucional y si el reporte es accesible
+ * return: el contenido de index.html
+ */
+func generarIndicePaquete(resultado ResultadoAnalisis, pares []ParPaquete, bandas []BandaSeveridad, distribucion []float64, marca MarcaReporte, accesible bool) string {
+	var pagina strings.Builder
+
+	pagina.WriteString(iniciarPaginaPaquete(titularReporte("titulo_reporte", ""), "recursos/estilo.css", marca, accesible))
+	fmt.Fprintf(&pagina, "<h1>%s</h1>\n", traducirReporte("reporte"))
+	fmt.Fprintf(&pagina, "<p>%d %s .%s %s %s. %s: %s.</p>\n",
+		len(resultado.archivos), traducirReporte("archivos_extension"), html.EscapeString(resultado.extension), traducirReporte("analizados_motor"),
+		html.EscapeString(resultado.motor), traducirReporte("distancia_maxima"), describirCriterioReporte(resultado.criterio))
+
+	if len(resultado.descartados) > 0 {
+		fmt.Fprintf(&pagina, "<p>%d %s %s</p>\n", len(resultado.descartados), traducirReporte("descartados"), html.EscapeString(strings.Join(resultado.descartados, ", ")))
+	}
+
+	if resultado.tieneDistanciaMaxima() {
+		fmt.Fprintf(&pagina, "<h2>%s (%d)</h2>\n", traducirReporte("grupos"), len(resultado.grupos))
+		for _, grupo := range resultado.grupos {
+			fmt.Fprintf(&pagina, "<h3>%s %s (%s %.2f)</h3><ul>\n", traducirReporte("grupo"), html.EscapeString(describirNombreGrupo(grupo.identificador, grupo.terminos)), traducirReporte("diametro"), grupo.diametro)
+			for _, integrante := range grupo.integrantes {
+				fmt.Fprintf(&pagina, "<li>%s (%.2f)</li>\n", html.EscapeString(resultado.archivos[integrante.indice].nombre), integrante.distanciaCentro)
+			}
+			pagina.WriteString("</ul>\n")
+		}
+	}
+
+	tituloPares := traducirReporte("pares_cercanos")
+	if resultado.tieneDistanciaMaxima() {
+		tituloPares = traducirReporte("pares_distancia")
+	}
+	fmt.Fprintf(&pagina, "<h2>%s (%d)</h2>\n", tituloPares, len(pares))
+
+	pagina.WriteString(iniciarTablaReporte(accesible, tituloPares,
+		"#", traducirReporte("archivo_1"), traducirReporte("archivo_2"), traducirReporte("distancia"), traducirReporte("severidad")))
+	for posicion, par := range pares {
+		fmt.Fprintf(&pagina, "<tr>%s<td>%s</td><td>%s</td><td>%.2f</td><td>%s</td></tr>\n",
+			encabezarFilaReporte(accesible, fmt.Sprintf("<a href=\"%s\">%d</a>", par.pagina, posicion+1)),
+			html.EscapeString(resultado.archivos[par.archivo1].nombre), html.EscapeString(resultado.archivos[par.archivo2].nombre), par.distancia,
+			obtenerInsigniaReporte(accesible, clasificarSeveridad(bandas, par.distancia)))
+	}
+	pagina.WriteString(terminarTablaReporte(accesible))
+
+	fmt.Fprintf(&pagina, "<h2>%s (%d)</h2>\n", traducirReporte("archivos"), len(resultado.archivos))
+	pagina.WriteString(iniciarTablaReporte(accesible, traducirReporte("archivos"),
+		traducirReporte("archivo"), traducirReporte("vecino_cercano"), traducirReporte("distancia"), traducirReporte("percentil"), traducirReporte("distribucion")))
+	for i, archivo := range resultado.archivos {
+		vecino, distancia, percentil := "-", "-", "-"
+		if vecinos := obtenerVecinosPaquete(resultado, i, 1); len(vecinos) > 0 {
+			vecino = html.EscapeString(resultado.archivos[vecinos[0]].nombre)
+			distancia = fmt.Sprintf("%.2f", resultado.matriz[i][vecinos[0]])
+			percentil = fmt.Sprintf("%.1f%%", calcularPercentil(distribucion, resultado.matriz[i][vecinos[0]]))
+		}
+
+		fmt.Fprintf(&pagina, "<tr>%s<td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			encabezarFilaReporte(accesible, fmt.Sprintf("<a href=\"archivos/archivo-%04d.html\">%s</a>", i+1, html.EscapeString(archivo.nombre))),
+			vecino, distancia, percentil, obtenerImagenMinigrafico(fmt.Sprintf("archivos/minigrafico-%04d.png", i+1), archivo.nombre))
+	}
+	pagina.WriteString(terminarTablaReporte(accesible))
+	fmt.Fprintf(&pagina, "<p><a href=\"datos/resultado.json\">%s</a></p>\n", traducirReporte("resultado_json"))
+	pagina.WriteString(terminarPaginaPaquete(marca))
+
+	return pagina.String()
+}
+
+/*
+ * Función para generar el código de un archivo con sus líneas coincidentes resaltadas
+ * En la variante accesible las líneas coincidentes llevan además una marca visible y un texto para lectores de pantalla.
+ * param: líneas del archivo, números de las líneas coincidentes, prefijo de los anclajes de las líneas y si el reporte es accesible
+ * return: el código en HTML
+ */
+func generarCodigoPaquete(lineas []string, coincidentes map[int]bool, prefijo string, accesible bool) string {
+	var codigo strings.Builder
+
+	codigo.WriteString("<pre>")
+	for i, linea := range lineas {
+		clase, marca := "", ""
+		if coincidentes[i+1] {
+			clase = " class=\"coincide\""
+		}
+		if accesible && coincidentes[i+1] {
+			marca = "<b aria-hidden=\"true\">=</b><span class=\"oculto\">" + traducirReporte("linea_coincidente") + " </span>"
+		} else if accesible {
+			marca = "<b aria-hidden=\"true\"> </b>"
+		}
+
+		fmt.Fprintf(&codigo, "<span id=\"%s%d\"%s><i>%d</i>%s%s</span>", prefijo, i+1, clase, i+1, marca, html.EscapeString(linea))
+	}
+	codigo.WriteString("</pre>")
+
+	return codigo.String()
+}
+
+/*
+ * Función para generar la página de un par del paquete
+ * param: resultado del análisis, par, posición del par, cantidad mínima de líneas consecutivas de un bloque coincidente
+ *        marca institucional, si el reporte es accesible y ocultamiento de los datos personales (nil si se conservan)
+ * return: el contenido de la página del par
+ */
+func generarPaginaParPaquete(resultado ResultadoAnalisis, par ParPaquete, posicion int, minimoLineas int, marca MarcaReporte, accesible bool, redaccion *Redaccion) string {
+	archivo1, archivo2 := resultado.archivos[par.archivo1], resultado.archivos[par.archivo2]
+	bloques := agruparBloquesLineas(obtenerLineasCoincidentes(archivo1.lineas, archivo2.lineas), minimoLineas)
+
+	coincidentes1, coincidentes2 := make(map[int]bool), make(map[int]bool)
+	for _, bloque := range bloques {
+		for _, coincidente := range bloque {
+			coincidentes1[coincidente.numero1], coincidentes2[coincidente.numero2] = true, true
+		}
+	}
+
+	var pagina strings.Builder
+
+	lineas := traducirReporte("lineas")
+
+	pagina.WriteString(iniciarPaginaPaquete(titularReporte("titulo_par", strconv.Itoa(posicion)), "../recursos/estilo.css", marca, accesible))
+	fmt.Fprintf(&pagina, "<p><a href=\"../index.html\">%s</a></p><h1>%s %d: %s %.2f</h1>\n", traducirReporte("volver"), traducirReporte("par"), posicion, traducirReporte("distancia_par"), par.distancia)
+	fmt.Fprintf(&pagina, "<p>%d %s (%d %s)</p><ul>\n", len(bloques), traducirReporte("bloques"), len(coincidentes1), lineas)
+
+	for _, bloque := range bloques {
+		inicio, fin := bloque[0], bloque[len(bloque)-1]
+		fmt.Fprintf(&pagina, "<li><a href=\"#a%d\">%s %d-%d</a> = <a href=\"#b%d\">%s %d-%d</a></li>\n",
+			inicio.numero1, lineas, inicio.numero1, fin.numero1, inicio.numero2, lineas, inicio.numero2, fin.numero2)
+	}
+	pagina.WriteString("</ul>\n")
+	pagina.WriteString(generarGraficoContribucion(resultado, par.archivo1, par.archivo2, accesible))
+
+	fmt.Fprintf(&pagina, "<div class=\"codigo\"><section%s><h2>%s</h2>%s</section><section%s><h2>%s</h2>%s</section></div>\n",
+		etiquetarReporte(accesible, "codigo_de", archivo1.nombre), html.EscapeString(archivo1.nombre), generarCodigoPaquete(redaccion.redactarLineas(leerLineas(archivo1)), coincidentes1, "a", accesible),
+		etiquetarReporte(accesible, "codigo_de", archivo2.nombre), html.EscapeString(archivo2.nombre), generarCodigoPaquete(redaccion.redactarLineas(leerLineas(archivo2)), coincidentes2, "b", accesible))
+	pagina.WriteString(terminarPaginaPaquete(marca))
+
+	return pagina.String()
+}
+
+/*
+ * Función para obtener los vecinos más cercanos de un archivo
+ * param: resultado del análisis, índice del archivo y cantidad máxima de vecinos
+ * return: índices de los vecinos, del más cercano al más lejano
+ */
+func obtenerVecinosPaquete(resultado ResultadoAnalisis, indice int, cantidad int) []int {
+	var vecinos []int
+
+	for _, vecino := range ordenarIndicesPorDistancia(resultado.matriz[indice]) {
+		if len(vecinos) == cantidad {
+			break
+		}
+		if vecino != indice && esDistanciaCalculada(resultado.matriz[indice][vecino]) {
+			vecinos = append(vecinos, vecino)
+		}
+	}
+
+	return vecinos
+}
+
+/*
+ * Función para obtener el elemento de la imagen del minigráfico de un archivo
+ * param: ruta de la imagen relativa a la página y nombre del archivo
+ * return: el elemento img
+ */
+func obtenerImagenMinigrafico(ruta string, nombre string) string {
+	return fmt.Sprintf("<img class=\"minigrafico\" src=\"%s\" width=\"%d\" height=\"%d\" alt=\"%s\">",
+		ruta, ANCHO_MINIGRAFICO, ALTO_MINIGRAFICO, html.EscapeString("Minigráfico de las distancias de "+nombre))
+}
+
+/*
+ * Función para generar la página de un archivo del paquete
+ * param: resultado del análisis, índice del archivo, páginas de los pares (por los índices de sus archivos),
+ *        bandas de severidad, distribución de las distancias del corpus, marca institucional y si el reporte es accesible
+ * return: el contenido de la página del archivo
+ */
+func generarPaginaArchivoPaquete(resultado ResultadoAnalisis, indice int, paginasPares map[[2]int]string, bandas []BandaSeveridad, distribucion []float64, marca MarcaReporte, accesible bool) string {
+	archivo := resultado.archivos[indice]
+	vecinos := obtenerVecinosPaquete(resultado, indice, VECINOS_PAGINA_ARCHIVO)
+
+	var pagina strings.Builder
+
+	pagina.WriteString(iniciarPaginaPaquete(titularReporte("titulo_archivo", archivo.nombre), "../recursos/estilo.css", marca, accesible))
+	fmt.Fprintf(&pagina, "<p><a href=\"../index.html\">%s</a></p><h1>%s %s</h1>\n", traducirReporte("volver"), traducirReporte("archivo"), html.EscapeString(archivo.nombre))
+
+	// La descripción del minigráfico incluye el percentil del vecino más cercano, que es lo que el gráfico muestra
+	descripcion := traducirReporte("descripcion_minigrafico")
+	if len(vecinos) > 0 {
+		descripcion += fmt.Sprintf(" %s: %.1f%%.", traducirReporte("percentil_vecino"), calcularPercentil(distribucion, resultado.matriz[indice][vecinos[0]]))
+	}
+	fmt.Fprintf(&pagina, "<figure>%s<figcaption id=\"descripcion\">%s</figcaption></figure>\n",
+		strings.Replace(obtenerImagenMinigrafico(fmt.Sprintf("minigrafico-%04d.png", indice+1), archivo.nombre), ">", " aria-describedby=\"descripcion\">", 1), descripcion)
+
+	fmt.Fprintf(&pagina, "<h2>%s (%d)</h2>\n", traducirReporte("vecinos"), len(vecinos))
+	pagina.WriteString(iniciarTablaReporte(accesible, traducirReporte("vecinos"),
+		"#", traducirReporte("archivo"), traducirReporte("distancia"), traducirReporte("percentil"), traducirReporte("severidad"), traducirReporte("par")))
+	for posicion, vecino := range vecinos {
+		distancia := resultado.matriz[indice][vecino]
+
+		par := "-"
+		if ruta, existe := paginasPares[[2]int{indice, vecino}]; existe {
+			par = fmt.Sprintf("<a href=\"../%s\">%s</a>", ruta, traducirReporte("par"))
+		}
+
+		fmt.Fprintf(&pagina, "<tr>%s<td><a href=\"archivo-%04d.html\">%s</a></td><td>%.2f</td><td>%.1f%%</td><td>%s</td><td>%s</td></tr>\n",
+			encabezarFilaReporte(accesible, strconv.Itoa(posicion+1)), vecino+1, html.EscapeString(resultado.archivos[vecino].nombre), distancia,
+			calcularPercentil(distribucion, distancia), obtenerInsigniaReporte(accesible, clasificarSeveridad(bandas, distancia)), par)
+	}
+	pagina.WriteString(terminarTablaReporte(accesible))
+	pagina.WriteString(terminarPaginaPaquete(marca))
+
+	return pagina.String()
+}
+
+/*
+ * Función para generar el paquete de reporte estático
+ * param: resultado del análisis, resultado JSON, directorio del paquete, bandas de severidad, marca institucional,
+ *        si el reporte es accesible, cantidad mínima de líneas consecutivas de un bloque coincidente y ocultamiento
+ *        de los datos personales de los comentarios (nil si se conservan)
+ * return: cantidad de páginas de pares generadas
+ */
+func generarPaquete(resultado ResultadoAnalisis, resultadoJSON ResultadoAnalisisJSON, directorio string, bandas []BandaSeveridad, marca MarcaReporte, accesible bool, minimoLineas int, redaccion *Redaccion) int {
+	for i := range resultado.archivos {
+		if resultado.archivos[i].lineas == nil {
+			resultado.archivos[i].lineas = obtenerLineasNormalizadas(resultado.archivos[i].contenido)
+		}
+	}
+
+	pares := obtenerParesPaquete(resultado)
+
+	estilo := ESTILO_PAQUETE
+	if accesible {
+		estilo += ESTILO_ACCESIBLE
+	}
+
+	escribirArchivoPaquete(directorio, "recursos/estilo.css", estilo)
+	distribucion := resultado.distribucion
+	if distribucion == nil {
+		distribucion = obtenerDistribucionDistancias(resultado)
+	}
+
+	escribirArchivoPaquete(directorio, "index.html", generarIndicePaquete(resultado, pares, bandas, distribucion, marca, accesible))
+
+	paginasPares := make(map[[2]int]string)
+	for posicion, par := range pares {
+		escribirArchivoPaquete(directorio, par.pagina, generarPaginaParPaquete(resultado, par, posicion+1, minimoLineas, marca, accesible, redaccion))
+		paginasPares[[2]int{par.archivo1, par.archivo2}], paginasPares[[2]int{par.archivo2, par.archivo1}] = par.pagina, par.pagina
+	}
+
+	for i := range resultado.archivos {
+		distancias := make([]float64, 0, len(resultado.archivos)-1)
+		for j := range resultado.archivos {
+			if j != i && esDistanciaCalculada(resultado.matriz[i][j]) {
+				distancias = append(distancias, resultado.matriz[i][j])
+			}
+		}
+
+		escribirArchivoPaquete(directorio, fmt.Sprintf("archivos/minigrafico-%04d.png", i+1), string(generarMinigrafico(distribucion, distancias, resultado.criterio.limite())))
+		escribirArchivoPaquete(directorio, fmt.Sprintf("archivos/archivo-%04d.html", i+1),
+			generarPaginaArchivoPaquete(resultado, i, paginasPares, bandas, distribucion, marca, accesible))
+	}
+
+	datos, err := json.MarshalIndent(resultadoJSON, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	escribirArchivoPaquete(directorio, "datos/resultado.json", string(datos)+"\n")
+
+	return len(pares)
+}
@@ -0,0 +1,65 @@
+/*
+ * Percentiles de las distancias (--percentiles).
+ *
+ * Cada distancia impresa se acompaña de su percentil en la distribución de las distancias de todos los pares
+ * del corpus (por ejemplo "12.40 [p0.3]" indica que solamente el 0.3% de los pares está a esa distancia o menos),
+ * de modo que se sabe si un valor es notable sin consultar el reporte de composición del corpus.
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+ * Función para obtener la distribución de las distancias de todos los pares de archivos (sin repetir)
+ * param: resultado del análisis
+ * return: distancias de todos los pares (sin los no calculados de --pairs=lsh), ordenadas de forma ascendente
+ */
+func obtenerDistribucionDistancias(resultado ResultadoAnalisis) []float64 {
+	var distribucion []float64
+
+	for i := range resultado.matriz {
+		for j := i + 1; j < len(resultado.matriz); j++ {
+			if esDistanciaCalculada(resultado.matriz[i][j]) {
+				distribucion = append(distribucion, resultado.matriz[i][j])
+			}
+		}
+	}
+
+	sort.Float64s(distribucion)
+
+	return distribucion
+}
+
+/*
+ * Función para calcular el percentil de una distancia: porcentaje de los pares a esa distancia o menos
+ * param: distribución de las distancias (ordenada) y la distancia
+ * return: percentil entre 0.0 y 100.0
+ */
+func calcularPercentil(distribucion []float64, distancia float64) float64 {
+	if len(distribucion) == 0 {
+		return 100.0
+	}
+
+	cantidad := sort.Search(len(distribucion), func(i int) bool {
+		return distribucion[i] > distancia
+	})
+
+	return 100.0 * float64(cantidad) / float64(len(distribucion))
+}
+
+/*
+ * Función para obtener la anotación del percentil de una distancia
+ * param: distribución de las distancias (vacía si no se anotan los percentiles) y la distancia
+ * return: anotación (por ejemplo " [p0.3]") o vacío si no se anotan los percentiles
+ */
+func anotarPercentil(distribucion []float64, distancia float64) string {
+	if distribucion == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(" [p%.1f]", calcularPercentil(distribucion, distancia))
+}
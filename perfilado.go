@@ -0,0 +1,95 @@
+/*
+ * Perfilado de ejecuciones largas (--pprof y --memstats).
+ *
+ * Con corpus de decenas de miles de archivos un análisis puede tardar horas o agotar la memoria, y el problema no se
+ * reproduce con corpus pequeños. Para diagnosticarlo durante la misma ejecución:
+ * - --pprof=:6060 atiende los perfiles de net/http/pprof (CPU, memoria, goroutines, bloqueos) en /debug/pprof/,
+ *   por ejemplo: go tool pprof http://localhost:6060/debug/pprof/heap
+ * - --memstats imprime periódicamente (--memstats-interval) el uso de memoria y del recolector de basura
+ *
+ * Los perfiles se atienden en su propia dirección, nunca en la del modo servidor (--serve), y el registro de la
+ * memoria se escribe en la salida de errores para no mezclarse con el JSON de los modos --stdin y --rpc.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// Cantidad de bytes de un MB (para el registro de la memoria)
+const BYTES_MB = 1024 * 1024
+
+/*
+ * Función para atender los perfiles de net/http/pprof en segundo plano
+ * param: dirección en la que se atienden (por ejemplo ":6060")
+ * return: la dirección real en la que se atienden o un error si no se puede abrir
+ */
+func iniciarPerfilado(direccion string) (string, error) {
+	escucha, err := net.Listen("tcp", direccion)
+	if err != nil {
+		return "", fmt.Errorf("No se pueden atender los perfiles (--pprof) en \"%s\": %v", direccion, err)
+	}
+
+	rutas := http.NewServeMux()
+	rutas.HandleFunc("/debug/pprof/", pprof.Index)
+	rutas.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	rutas.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	rutas.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	rutas.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	go http.Serve(escucha, rutas)
+
+	return escucha.Addr().String(), nil
+}
+
+/*
+ * Función para imprimir una línea con el uso de memoria y del recolector de basura
+ * param: salida del registro y tiempo transcurrido desde el inicio
+ */
+func registrarEstadisticasMemoria(salida io.Writer, transcurrido time.Duration) {
+	var estadisticas runtime.MemStats
+	runtime.ReadMemStats(&estadisticas)
+
+	fmt.Fprintf(salida, "[memstats %s] heap %.1f MB en uso (máximo del sistema %.1f MB), %d objetos, %.1f MB asignados en total, %d GC (pausa total %s), %d goroutines\n",
+		transcurrido.Round(time.Second), float64(estadisticas.HeapAlloc)/BYTES_MB, float64(estadisticas.Sys)/BYTES_MB, estadisticas.HeapObjects,
+		float64(estadisticas.TotalAlloc)/BYTES_MB, estadisticas.NumGC, time.Duration(estadisticas.PauseTotalNs).Round(time.Microsecond), runtime.NumGoroutine())
+}
+
+/*
+ * Función para iniciar el registro periódico del uso de memoria en segundo plano
+ * param: salida del registro e intervalo entre registros
+ * return: función que detiene el registro e imprime el último
+ */
+func iniciarEstadisticasMemoria(salida io.Writer, intervalo time.Duration) func() {
+	inicio := time.Now()
+	reloj := time.NewTicker(intervalo)
+	terminar := make(chan bool)
+	terminado := make(chan bool)
+
+	go func() {
+		defer close(terminado)
+
+		for {
+			select {
+			case <-reloj.C:
+				registrarEstadisticasMemoria(salida, time.Since(inicio))
+			case <-terminar:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		reloj.Stop()
+		close(terminar)
+		<-terminado
+		registrarEstadisticasMemoria(salida, time.Since(inicio))
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Características de los archivos: frecuencia de cada entrada de la tabla ASCII o de los n-gramas de caracteres
+ * agrupados por su hash en un vector de tamaño fijo.
+ */
+
+package sasc
+
+import (
+	"bufio"
+	"bytes"
+	"unicode/utf8"
+)
+
+// Tamaño de la tabla ASCII (longitud del vector de características)
+const MaxASCII = 256
+
+/*
+ * Función para determinar la frecuencia de todos los elementos de la tabla ASCII en un contenido.
+ * Se cuenta el primer byte de cada carácter UTF-8 (los bytes no válidos cuentan como el primer byte de U+FFFD).
+ * param: contenido del archivo y arreglo en el que se cuentan (se crea si no tiene la longitud de la tabla ASCII)
+ * return: arreglo con la frecuencia de todos los elementos de la tabla ASCII en el contenido
+ */
+func CharacterFrequencies(content []byte, table []int) []int {
+	if len(table) != MaxASCII {
+		table = make([]int, MaxASCII)
+	} else {
+		for i := range table {
+			table[i] = 0
+		}
+	}
+
+	data := bufio.NewScanner(bytes.NewReader(content))
+	data.Split(bufio.ScanRunes)
+
+	for data.Scan() {
+		table[int(data.Text()[0])]++
+	}
+
+	return table
+}
+
+/*
+ * Función para determinar la frecuencia de los n-gramas de caracteres de un contenido en un vector de tamaño fijo:
+ * cada secuencia de n caracteres UTF-8 consecutivos suma en la posición hash % size (FNV-1a de sus bytes).
+ * param: contenido del archivo, cantidad de caracteres de cada n-grama, tamaño del vector y arreglo en el que se
+ *        cuentan (se crea si no tiene el tamaño del vector)
+ * return: arreglo con la frecuencia de los n-gramas de cada posición
+ */
+func CharacterNgramFrequencies(content []byte, n int, size int, table []int) []int {
+	if len(table) != size {
+		table = make([]int, size)
+	} else {
+		for i := range table {
+			table[i] = 0
+		}
+	}
+
+	// Posición en bytes del inicio de los últimos n caracteres (arreglo circular)
+	starts := make([]int, n)
+	count := 0
+
+	for offset := 0; offset < len(content); {
+		_, width := utf8.DecodeRune(content[offset:])
+		starts[count%n] = offset
+		count++
+		offset += width
+
+		if count >= n {
+			hash := uint64(14695981039346656037)
+			for _, b := range content[starts[count%n]:offset] {
+				hash ^= uint64(b)
+				hash *= 1099511628211
+			}
+			table[hash%uint64(size)]++
+		}
+	}
+
+	return table
+}
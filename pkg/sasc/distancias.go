@@ -0,0 +1,222 @@
+/*
+ * Distancias euclidianas entre los vectores de características y matriz de distancias de un corpus.
+ *
+ * Hay dos núcleos del cuadrado de la distancia (para vectores enteros y reales):
+ * - genérico: un elemento a la vez con math.Pow (referencia para comparar resultados)
+ * - desenrollado: de a 4 elementos con 4 acumuladores independientes y sin verificación de límites en el ciclo
+ * Con vectores enteros ambos dan exactamente la misma distancia mientras la suma no supere 2^53; con vectores reales
+ * el orden de las sumas cambia y la distancia puede diferir en los últimos decimales.
+ * Además de la euclidiana están las distancias Manhattan (L1, suma de las diferencias absolutas) y Chebyshev (L∞,
+ * mayor diferencia absoluta), para comparar qué métrica separa mejor las copias de los trabajos independientes.
+ */
+
+package sasc
+
+import "math"
+
+// Estructura de la matriz de distancias entre los archivos de un corpus
+// - nombres de los archivos (en el orden de la matriz)
+// - distancias entre cada par de archivos (simétrica, con ceros en la diagonal)
+type DistanceMatrix struct {
+	Files  []string
+	Values [][]float64
+}
+
+/*
+ * Función para obtener la cantidad de archivos de la matriz
+ * return: cantidad de archivos
+ */
+func (matriz *DistanceMatrix) Len() int {
+	return len(matriz.Values)
+}
+
+/*
+ * Función para obtener la distancia entre dos archivos de la matriz
+ * param: índices de los dos archivos
+ * return: distancia entre ambos archivos
+ */
+func (matriz *DistanceMatrix) Distance(i int, j int) float64 {
+	return matriz.Values[i][j]
+}
+
+/*
+ * Función para calcular la matriz de distancias euclidianas entre todos los archivos de un corpus (con características)
+ * param: corpus
+ * return: la matriz de distancias
+ */
+func ComputeDistanceMatrix(corpus *Corpus) *DistanceMatrix {
+	cantidad := len(corpus.Files)
+	matriz := &DistanceMatrix{Files: make([]string, cantidad), Values: make([][]float64, cantidad)}
+
+	valores := make([]float64, cantidad*cantidad)
+	for i := range corpus.Files {
+		matriz.Files[i] = corpus.Files[i].Name
+		matriz.Values[i] = valores[i*cantidad : (i+1)*cantidad : (i+1)*cantidad]
+	}
+
+	for i := 0; i < cantidad; i++ {
+		for j := 0; j < i; j++ {
+			distancia := math.Sqrt(SquaredDistanceUnrolled(corpus.Files[i].Features, corpus.Files[j].Features))
+			matriz.Values[i][j], matriz.Values[j][i] = distancia, distancia
+		}
+	}
+
+	return matriz
+}
+
+/*
+ * Función que calcula el cuadrado de la distancia euclidiana entre dos vectores enteros, un elemento a la vez
+ * param: dos vectores enteros de la misma longitud
+ * return: cuadrado de la distancia euclidiana
+ */
+func SquaredDistanceGeneric(v1 []int, v2 []int) float64 {
+	suma := 0.0
+	for i := 0; i < len(v1); i++ {
+		suma += math.Pow((float64)(v1[i]-v2[i]), 2.0)
+	}
+
+	return suma
+}
+
+/*
+ * Función que calcula el cuadrado de la distancia euclidiana entre dos vectores reales, un elemento a la vez
+ * param: dos vectores reales de la misma longitud
+ * return: cuadrado de la distancia euclidiana
+ */
+func SquaredDistanceFloatGeneric(v1 []float64, v2 []float64) float64 {
+	suma := 0.0
+	for i := range v1 {
+		suma += math.Pow(v1[i]-v2[i], 2.0)
+	}
+
+	return suma
+}
+
+/*
+ * Función que calcula el cuadrado de la distancia euclidiana entre dos vectores enteros, de a 4 elementos
+ * La suma es entera (exacta), igual a la del núcleo genérico mientras no supere 2^53.
+ * param: dos vectores enteros de la misma longitud
+ * return: cuadrado de la distancia euclidiana
+ */
+func SquaredDistanceUnrolled(v1 []int, v2 []int) float64 {
+	v2 = v2[:len(v1)] // Permite al compilador eliminar la verificación de límites de v2
+
+	var suma0, suma1, suma2, suma3 int
+
+	i := 0
+	for ; i+4 <= len(v1); i += 4 {
+		a, b := v1[i:i+4:i+4], v2[i:i+4:i+4]
+		d0, d1, d2, d3 := a[0]-b[0], a[1]-b[1], a[2]-b[2], a[3]-b[3]
+		suma0 += d0 * d0
+		suma1 += d1 * d1
+		suma2 += d2 * d2
+		suma3 += d3 * d3
+	}
+	for ; i < len(v1); i++ {
+		d := v1[i] - v2[i]
+		suma0 += d * d
+	}
+
+	return float64(suma0 + suma1 + suma2 + suma3)
+}
+
+/*
+ * Función que calcula el cuadrado de la distancia euclidiana entre dos vectores reales, de a 4 elementos
+ * param: dos vectores reales de la misma longitud
+ * return: cuadrado de la distancia euclidiana
+ */
+func SquaredDistanceFloatUnrolled(v1 []float64, v2 []float64) float64 {
+	v2 = v2[:len(v1)]
+
+	var suma0, suma1, suma2, suma3 float64
+
+	i := 0
+	for ; i+4 <= len(v1); i += 4 {
+		a, b := v1[i:i+4:i+4], v2[i:i+4:i+4]
+		d0, d1, d2, d3 := a[0]-b[0], a[1]-b[1], a[2]-b[2], a[3]-b[3]
+		suma0 += d0 * d0
+		suma1 += d1 * d1
+		suma2 += d2 * d2
+		suma3 += d3 * d3
+	}
+	for ; i < len(v1); i++ {
+		d := v1[i] - v2[i]
+		suma0 += d * d
+	}
+
+	return (suma0 + suma1) + (suma2 + suma3)
+}
+
+/*
+ * Función que calcula la distancia Manhattan (L1) entre dos vectores enteros: la suma de las diferencias absolutas
+ * param: dos vectores enteros de la misma longitud
+ * return: distancia Manhattan
+ */
+func ManhattanDistance(v1 []int, v2 []int) float64 {
+	v2 = v2[:len(v1)]
+
+	suma := 0
+	for i := range v1 {
+		if d := v1[i] - v2[i]; d < 0 {
+			suma -= d
+		} else {
+			suma += d
+		}
+	}
+
+	return float64(suma)
+}
+
+/*
+ * Función que calcula la distancia Manhattan (L1) entre dos vectores reales
+ * param: dos vectores reales de la misma longitud
+ * return: distancia Manhattan
+ */
+func ManhattanDistanceFloat(v1 []float64, v2 []float64) float64 {
+	v2 = v2[:len(v1)]
+
+	suma := 0.0
+	for i := range v1 {
+		suma += math.Abs(v1[i] - v2[i])
+	}
+
+	return suma
+}
+
+/*
+ * Función que calcula la distancia Chebyshev (L∞) entre dos vectores enteros: la mayor diferencia absoluta
+ * param: dos vectores enteros de la misma longitud
+ * return: distancia Chebyshev
+ */
+func ChebyshevDistance(v1 []int, v2 []int) float64 {
+	v2 = v2[:len(v1)]
+
+	maximo := 0
+	for i := range v1 {
+		d := v1[i] - v2[i]
+		if d < 0 {
+			d = -d
+		}
+		if d > maximo {
+			maximo = d
+		}
+	}
+
+	return float64(maximo)
+}
+
+/*
+ * Función que calcula la distancia Chebyshev (L∞) entre dos vectores reales
+ * param: dos vectores reales de la misma longitud
+ * return: distancia Chebyshev
+ */
+func ChebyshevDistanceFloat(v1 []float64, v2 []float64) float64 {
+	v2 = v2[:len(v1)]
+
+	maximo := 0.0
+	for i := range v1 {
+		maximo = math.Max(maximo, math.Abs(v1[i]-v2[i]))
+	}
+
+	return maximo
+}
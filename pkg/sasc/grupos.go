@@ -0,0 +1,260 @@
+/*
+ * Grupos de archivos a una distancia máxima de su código central (medoide).
+ *
+ * Por cada archivo (semilla) se toman los archivos a una distancia máxima y el código central se reemplaza por el
+ * medoide del grupo (el integrante con la menor suma de distancias a los demás) hasta que ambos coinciden. Solamente
+ * se conservan los grupos con al menos un integrante nuevo, por lo que un archivo puede estar en varios grupos.
+ * Cada grupo se identifica por el hash del contenido de su medoide, de modo que es estable entre ejecuciones.
+ */
+
+package sasc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strconv"
+)
+
+// Cantidad máxima de veces que se reemplaza el código central de un grupo por su medoide
+const MaxMedoidIterations = 10
+
+// Cantidad de caracteres hexadecimales del identificador de un grupo
+const GroupIDLength = 8
+
+// Interfaz de las distancias entre los archivos de un corpus, por índice (por ejemplo DistanceMatrix)
+type Distances interface {
+	Len() int
+	Distance(i int, j int) float64
+}
+
+// Interfaz opcional de las distancias que se comparan con la distancia máxima sin calcular la distancia
+// (por ejemplo, si se almacenan al cuadrado)
+type ThresholdDistances interface {
+	Within(i int, j int, maxDistance float64) bool
+}
+
+// Estructura de un integrante de un grupo
+// - índice del archivo
+// - distancia al medoide del grupo
+// - si el archivo ya pertenecía a un grupo anterior
+type Member struct {
+	Index            int
+	DistanceToMedoid float64
+	InPreviousGroup  bool
+}
+
+// Estructura de un grupo de archivos a una distancia máxima de su medoide
+// - identificador estable del grupo (hash del contenido del medoide)
+// - índice del medoide y diámetro del grupo (la mayor distancia entre dos integrantes)
+// - par ejemplar: los dos integrantes más parecidos y su distancia
+// - integrantes del grupo (incluyendo el medoide), en orden
+type Group struct {
+	ID               string
+	Medoid           int
+	Diameter         float64
+	Exemplar         [2]int
+	ExemplarDistance float64
+	Members          []Member
+}
+
+/*
+ * Función para determinar si dos archivos están a una distancia máxima
+ * param: distancias, índices de los dos archivos y distancia máxima
+ * return: si la distancia es menor o igual a la distancia máxima
+ */
+func within(distancias Distances, i int, j int, maxDistance float64) bool {
+	if umbral, ok := distancias.(ThresholdDistances); ok {
+		return umbral.Within(i, j, maxDistance)
+	}
+
+	return distancias.Distance(i, j) <= maxDistance
+}
+
+/*
+ * Función para obtener los archivos que están a una distancia máxima de un código central
+ * param: distancias, índice del código central y distancia máxima
+ * return: índices de los archivos a una distancia máxima del código central (incluyéndolo), en orden
+ */
+func membersWithin(distancias Distances, centro int, maxDistance float64) []int {
+	var miembros []int
+
+	for indice := 0; indice < distancias.Len(); indice++ {
+		if within(distancias, centro, indice, maxDistance) {
+			miembros = append(miembros, indice)
+		}
+	}
+
+	return miembros
+}
+
+/*
+ * Función para calcular el medoide de un grupo: el archivo con la menor suma de distancias a los demás integrantes.
+ * En caso de empate se prefiere el archivo indicado (por ejemplo el código central actual).
+ * param: distancias, índices de los integrantes y archivo preferido
+ * return: índice del medoide
+ */
+func Medoid(distancias Distances, members []int, preferred int) int {
+	medoide, menorSuma := preferred, math.MaxFloat64
+
+	for _, candidato := range members {
+		suma := 0.0
+		for _, miembro := range members {
+			suma += distancias.Distance(candidato, miembro)
+		}
+
+		if suma < menorSuma || (suma == menorSuma && candidato == preferred) {
+			medoide, menorSuma = candidato, suma
+		}
+	}
+
+	return medoide
+}
+
+/*
+ * Función para calcular el diámetro de un grupo: la mayor distancia entre dos de sus integrantes
+ * param: distancias e índices de los integrantes
+ * return: diámetro del grupo
+ */
+func Diameter(distancias Distances, members []int) float64 {
+	diametro := 0.0
+
+	for _, miembro1 := range members {
+		for _, miembro2 := range members {
+			diametro = math.Max(diametro, distancias.Distance(miembro1, miembro2))
+		}
+	}
+
+	return diametro
+}
+
+/*
+ * Función para obtener el par ejemplar de un grupo: los dos integrantes a la menor distancia entre sí
+ * param: distancias e índices de los integrantes (al menos dos)
+ * return: índices de los dos integrantes y su distancia
+ */
+func ExemplarPair(distancias Distances, members []int) ([2]int, float64) {
+	ejemplar, distanciaEjemplar := [2]int{members[0], members[1]}, math.MaxFloat64
+
+	for i, miembro1 := range members {
+		for _, miembro2 := range members[i+1:] {
+			if distancia := distancias.Distance(miembro1, miembro2); distancia < distanciaEjemplar {
+				ejemplar, distanciaEjemplar = [2]int{miembro1, miembro2}, distancia
+			}
+		}
+	}
+
+	return ejemplar, distanciaEjemplar
+}
+
+/*
+ * Función para obtener el identificador de un grupo a partir del contenido de su medoide
+ * param: contenido del medoide
+ * return: identificador del grupo (primeros caracteres del hash SHA-256 del contenido)
+ */
+func GroupID(content []byte) string {
+	hash := sha256.Sum256(content)
+
+	return hex.EncodeToString(hash[:])[:GroupIDLength]
+}
+
+/*
+ * Función para construir un grupo con su medoide y sus integrantes
+ * param: distancias, identificador del grupo, índice del medoide, índices de los integrantes (al menos dos)
+ *        y archivos que ya pertenecen a un grupo anterior
+ * return: el grupo con su diámetro, su par ejemplar y la distancia de cada integrante al medoide
+ */
+func BuildGroup(distancias Distances, id string, medoid int, members []int, grouped map[int]bool) Group {
+	grupo := Group{ID: id, Medoid: medoid, Diameter: Diameter(distancias, members)}
+	grupo.Exemplar, grupo.ExemplarDistance = ExemplarPair(distancias, members)
+
+	for _, miembro := range members {
+		grupo.Members = append(grupo.Members, Member{
+			Index:            miembro,
+			DistanceToMedoid: distancias.Distance(medoid, miembro),
+			InPreviousGroup:  grouped[miembro],
+		})
+	}
+
+	return grupo
+}
+
+/*
+ * Función para formar un grupo a partir de un archivo semilla (el código central se reemplaza por el medoide del
+ * grupo hasta que ambos coinciden, máximo MaxMedoidIterations veces)
+ * param: distancias, índice de la semilla y distancia máxima
+ * return: índice del código central y los índices de los integrantes
+ */
+func formGroup(distancias Distances, semilla int, maxDistance float64) (int, []int) {
+	centro := semilla
+	miembros := membersWithin(distancias, centro, maxDistance)
+
+	for iteracion := 0; iteracion < MaxMedoidIterations; iteracion++ {
+		medoide := Medoid(distancias, miembros, centro)
+		if medoide == centro {
+			break
+		}
+
+		centro = medoide
+		miembros = membersWithin(distancias, centro, maxDistance)
+	}
+
+	return centro, miembros
+}
+
+/*
+ * Función para formar los grupos de archivos a una distancia máxima de su medoide.
+ * No modifica las distancias, por lo que se puede llamar varias veces (por ejemplo con distintas distancias máximas)
+ * o de forma concurrente sobre las mismas distancias.
+ * param: distancias, distancia máxima y contenido de cada archivo (para el identificador de los grupos)
+ * return: los grupos (solamente los que tienen al menos un integrante nuevo)
+ */
+func FormGroups(distancias Distances, maxDistance float64, content func(i int) []byte) []Group {
+	var grupos []Group
+
+	agrupados := make(map[int]bool) // Archivos que ya pertenecen a un grupo anterior
+	centrosAgrupados := make(map[int]bool)
+	identificadores := make(map[string]int)
+
+	for semilla := 0; semilla < distancias.Len(); semilla++ {
+		centro, miembros := formGroup(distancias, semilla, maxDistance)
+
+		if len(miembros) < 2 || centrosAgrupados[centro] {
+			continue
+		}
+
+		// Solamente se conserva el grupo si tiene al menos un integrante nuevo
+		nuevoIntegrante := false
+		for _, miembro := range miembros {
+			if !agrupados[miembro] {
+				nuevoIntegrante = true
+			}
+		}
+		if !nuevoIntegrante {
+			continue
+		}
+		centrosAgrupados[centro] = true
+
+		grupos = append(grupos, BuildGroup(distancias, UniqueGroupID(identificadores, GroupID(content(centro))), centro, miembros, agrupados))
+
+		for _, miembro := range miembros {
+			agrupados[miembro] = true
+		}
+	}
+
+	return grupos
+}
+
+/*
+ * Función para distinguir con un sufijo los identificadores de los grupos cuyos medoides tienen el mismo contenido
+ * param: cantidad de veces que se ha usado cada identificador (se actualiza) e identificador del grupo
+ * return: el identificador, con el sufijo "-N" si ya se usó
+ */
+func UniqueGroupID(used map[string]int, id string) string {
+	used[id]++
+	if used[id] > 1 {
+		return id + "-" + strconv.Itoa(used[id])
+	}
+
+	return id
+}
@@ -0,0 +1,143 @@
+/*
+ * Paquete sasc: núcleo reutilizable del análisis de similaridad de código de SASC.
+ *
+ * Contiene la extracción de características (frecuencia de los caracteres ASCII), el cálculo de la matriz de
+ * distancias euclidianas y la formación de los grupos alrededor de su medoide, para usar el análisis desde otras
+ * herramientas sin ejecutar el programa SASC:
+ *
+ *     resultado, err := sasc.Analyze(sasc.Options{Directory: "entregas", Extension: "java", Group: true, MaxDistance: 30})
+ *     for _, grupo := range resultado.Groups {
+ *         fmt.Println(grupo.ID, resultado.Corpus.Files[grupo.Medoid].Name, grupo.Diameter)
+ *     }
+ *
+ * Analyze solamente implementa el motor "ascii", sin preprocesamiento ni descarte de archivos: equivale a
+ * ./SASC --engine=ascii --min-content=0 con la misma distancia máxima. El programa SASC no llama a Analyze, porque sus
+ * demás motores, el preprocesamiento, la caché y los reportes siguen siendo parte del programa, pero usa este paquete
+ * para las características del motor "ascii", los núcleos de las distancias y los grupos, por lo que ambos dan la
+ * misma matriz y los mismos grupos.
+ */
+
+package sasc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Estructura de un archivo del corpus
+// - nombre del archivo (ruta relativa al directorio del corpus, iniciando con "./")
+// - ruta del archivo para leerlo
+// - contenido del archivo
+// - características (frecuencia de cada entrada de la tabla ASCII)
+type SourceFile struct {
+	Name     string
+	Path     string
+	Content  []byte
+	Features []int
+}
+
+// Estructura de un corpus: los archivos de una extensión de un directorio (en orden)
+type Corpus struct {
+	Extension string
+	Files     []SourceFile
+}
+
+// Estructura de las opciones de un análisis (el valor cero analiza el directorio actual sin formar grupos)
+// - directorio del corpus (todos sus subdirectorios inclusive) y extensión de los archivos (por ejemplo "go")
+// - si se forman grupos y su distancia máxima (0 agrupa solamente los archivos con las mismas características)
+type Options struct {
+	Directory   string
+	Extension   string
+	Group       bool
+	MaxDistance float64
+}
+
+// Estructura del resultado de un análisis
+// - corpus con las características de los archivos
+// - matriz de distancias entre los archivos (en el orden del corpus)
+// - grupos a la distancia máxima (vacío si no se forman grupos)
+type Result struct {
+	Corpus *Corpus
+	Matrix *DistanceMatrix
+	Groups []Group
+}
+
+/*
+ * Función para leer todos los archivos de una extensión de un directorio y sus subdirectorios
+ * param: directorio del corpus y extensión de los archivos
+ * return: el corpus (sin características) o un error si el directorio o un archivo no se puede leer
+ */
+func LoadCorpus(directory string, extension string) (*Corpus, error) {
+	corpus := &Corpus{Extension: extension}
+
+	err := filepath.Walk(directory, func(ruta string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(ruta, extension) {
+			return nil
+		}
+
+		contenido, err := os.ReadFile(ruta)
+		if err != nil {
+			return err
+		}
+
+		relativo, err := filepath.Rel(directory, ruta)
+		if err != nil {
+			return err
+		}
+
+		corpus.Files = append(corpus.Files, SourceFile{Name: "./" + filepath.ToSlash(relativo), Path: ruta, Content: contenido})
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("No se puede leer el corpus en \"%s\": %v", directory, err)
+	}
+
+	return corpus, nil
+}
+
+/*
+ * Función para determinar las características de todos los archivos del corpus
+ */
+func (corpus *Corpus) ComputeFeatures() {
+	for i := range corpus.Files {
+		corpus.Files[i].Features = CharacterFrequencies(corpus.Files[i].Content, corpus.Files[i].Features)
+	}
+}
+
+/*
+ * Función para analizar un corpus: características, matriz de distancias y grupos (si se indicó Group)
+ * param: opciones del análisis
+ * return: el resultado del análisis o un error si la distancia máxima de los grupos no es válida o el corpus no se
+ *         puede leer
+ */
+func Analyze(options Options) (*Result, error) {
+	if options.Directory == "" {
+		options.Directory = "."
+	}
+	if options.Group && !(options.MaxDistance >= 0) {
+		return nil, fmt.Errorf("La distancia máxima de los grupos debe ser mayor o igual a cero: %v", options.MaxDistance)
+	}
+
+	corpus, err := LoadCorpus(options.Directory, options.Extension)
+	if err != nil {
+		return nil, err
+	}
+
+	corpus.ComputeFeatures()
+
+	resultado := &Result{Corpus: corpus, Matrix: ComputeDistanceMatrix(corpus)}
+
+	if options.Group {
+		resultado.Groups = FormGroups(resultado.Matrix, options.MaxDistance, func(i int) []byte {
+			return corpus.Files[i].Content
+		})
+	}
+
+	return resultado, nil
+}
@@ -0,0 +1,126 @@
+package sasc
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+/*
+ * Función para crear un corpus temporal
+ * param: prueba y contenido de cada archivo por su ruta relativa
+ * return: directorio del corpus
+ */
+func crearCorpusPrueba(t *testing.T, archivos map[string]string) string {
+	t.Helper()
+
+	directorio := t.TempDir()
+	for ruta, contenido := range archivos {
+		completa := filepath.Join(directorio, filepath.FromSlash(ruta))
+		if err := os.MkdirAll(filepath.Dir(completa), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(completa, []byte(contenido), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return directorio
+}
+
+var corpusPrueba = map[string]string{
+	"ana/main.go":   "package main\n\nfunc main() { println(\"hola\") }\n",
+	"luis/main.go":  "package main\n\nfunc main() { println(\"hola\") }\n",
+	"eva/main.go":   "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfor i := 0; i < 3; i++ {\n\t\tfmt.Println(i)\n\t}\n}\n",
+	"eva/notas.txt": "no se analiza",
+}
+
+func TestAnalyzeSinGrupos(t *testing.T) {
+	resultado, err := Analyze(Options{Directory: crearCorpusPrueba(t, corpusPrueba), Extension: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(resultado.Matrix.Files, []string{"./ana/main.go", "./eva/main.go", "./luis/main.go"}) {
+		t.Errorf("archivos = %v", resultado.Matrix.Files)
+	}
+	if resultado.Groups != nil {
+		t.Errorf("grupos = %v, el valor cero de Group no forma grupos", resultado.Groups)
+	}
+	if resultado.Matrix.Distance(0, 2) != 0 || resultado.Matrix.Distance(0, 1) == 0 {
+		t.Errorf("matriz = %v", resultado.Matrix.Values)
+	}
+}
+
+func TestAnalyzeConGrupos(t *testing.T) {
+	directorio := crearCorpusPrueba(t, corpusPrueba)
+
+	casos := []struct {
+		maxDistance float64
+		grupos      int
+		valido      bool
+	}{
+		{0, 1, true},
+		{math.Inf(1), 1, true},
+		{-1, 0, false},
+		{math.NaN(), 0, false},
+	}
+
+	for _, caso := range casos {
+		resultado, err := Analyze(Options{Directory: directorio, Extension: "go", Group: true, MaxDistance: caso.maxDistance})
+		if (err == nil) != caso.valido {
+			t.Errorf("MaxDistance %v: error = %v", caso.maxDistance, err)
+			continue
+		}
+		if caso.valido && len(resultado.Groups) != caso.grupos {
+			t.Errorf("MaxDistance %v: grupos = %v, se esperaban %d", caso.maxDistance, resultado.Groups, caso.grupos)
+		}
+	}
+
+	resultado, err := Analyze(Options{Directory: directorio, Extension: "go", Group: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if miembros := resultado.Groups[0].Members; len(miembros) != 2 || miembros[0].Index != 0 || miembros[1].Index != 2 {
+		t.Errorf("integrantes = %v, se esperaban las dos copias", miembros)
+	}
+}
+
+func TestCharacterFrequencies(t *testing.T) {
+	tabla := CharacterFrequencies([]byte("aab\xff"), nil)
+
+	if len(tabla) != MaxASCII || tabla['a'] != 2 || tabla['b'] != 1 || tabla[0xef] != 1 {
+		t.Errorf("frecuencias de a, b y U+FFFD = %d, %d, %d", tabla['a'], tabla['b'], tabla[0xef])
+	}
+	if reutilizada := CharacterFrequencies([]byte("c"), tabla); &reutilizada[0] != &tabla[0] || reutilizada['a'] != 0 {
+		t.Error("el arreglo de la longitud de la tabla no se reutiliza en ceros")
+	}
+}
+
+func TestNucleosDistancia(t *testing.T) {
+	v1, v2 := []int{1, 2, 3, 4, 5}, []int{5, 4, 3, 2, 1}
+	r1, r2 := []float64{1, 2, 3, 4, 5}, []float64{5, 4, 3, 2, 1}
+
+	casos := []struct {
+		nombre   string
+		obtenido float64
+		esperado float64
+	}{
+		{"SquaredDistanceGeneric", SquaredDistanceGeneric(v1, v2), 40},
+		{"SquaredDistanceUnrolled", SquaredDistanceUnrolled(v1, v2), 40},
+		{"SquaredDistanceFloatGeneric", SquaredDistanceFloatGeneric(r1, r2), 40},
+		{"SquaredDistanceFloatUnrolled", SquaredDistanceFloatUnrolled(r1, r2), 40},
+		{"ManhattanDistance", ManhattanDistance(v1, v2), 12},
+		{"ManhattanDistanceFloat", ManhattanDistanceFloat(r1, r2), 12},
+		{"ChebyshevDistance", ChebyshevDistance(v1, v2), 4},
+		{"ChebyshevDistanceFloat", ChebyshevDistanceFloat(r1, r2), 4},
+	}
+
+	for _, caso := range casos {
+		if caso.obtenido != caso.esperado {
+			t.Errorf("%s = %v, se esperaba %v", caso.nombre, caso.obtenido, caso.esperado)
+		}
+	}
+}
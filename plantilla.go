@@ -0,0 +1,173 @@
+/*
+ * Código base entregado por el docente (--template).
+ *
+ * Cuando el docente entrega un código base (clases, firmas, lectura de datos) que todos los estudiantes completan,
+ * ese código aparece en todas las entregas y acerca a todos los pares, honestos o no. Con --template=directorio se
+ * leen los archivos de la extensión del directorio del código base, con el mismo preprocesamiento de las entregas
+ * (encabezado, comentarios y normalizaciones), y de cada entrega se eliminan sus líneas presentes en el código base
+ * antes de calcular las características:
+ * - las líneas se comparan normalizadas (sin espacios al inicio y al final y con los espacios internos colapsados,
+ *   como en el motor de líneas)
+ * - cada línea del código base se elimina tantas veces como aparece en él (una línea que el estudiante repite en su
+ *   propio código se conserva en las demás apariciones)
+ * - solo se eliminan las líneas con letras o dígitos, así los cierres como "}" o ");" se conservan
+ * El texto eliminado se reemplaza por una línea vacía para conservar los números de línea, por lo que el código base
+ * no cuenta en ningún motor de características ni en la evidencia (incluidas las huellas de winnowing). Si el
+ * directorio del código base está dentro del directorio a analizar, sus archivos no se analizan como una entrega.
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+/*
+ * Función para obtener los archivos del código base
+ * param: directorio del código base, extensión de los archivos y límites de la extracción de los .zip
+ * return: rutas de los archivos y contenido de los archivos de los .zip, o un error si el directorio no existe, un
+ *         .zip no es válido o no tiene archivos de la extensión
+ */
+func obtenerListadoPlantilla(directorio string, extension string, limites LimitesExtraccion) ([]string, ArchivosComprimidos, error) {
+	if informacion, err := os.Stat(directorio); err != nil || !informacion.IsDir() {
+		return nil, nil, fmt.Errorf("El directorio del código base (--template) \"%s\" no existe", directorio)
+	}
+
+	listado, comprimidos, err := obtenerListadoDirectorio(directorio, extension, limites)
+	if err != nil {
+		return nil, nil, fmt.Errorf("El código base (--template) no se puede leer: %v", err)
+	}
+	if len(listado) == 0 {
+		return nil, nil, fmt.Errorf("El directorio del código base (--template) \"%s\" no tiene archivos de extensión .%s", directorio, extension)
+	}
+
+	return listado, comprimidos, nil
+}
+
+/*
+ * Función para calcular el hash de una línea normalizada (el mismo de obtenerLineasNormalizadas)
+ * param: línea normalizada
+ * return: hash de la línea
+ */
+func calcularHashLinea(texto string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(texto))
+
+	return hash.Sum64()
+}
+
+/*
+ * Función para determinar si una línea normalizada se puede eliminar por estar en el código base
+ * param: línea normalizada
+ * return: si la línea tiene letras o dígitos
+ */
+func esLineaPlantilla(texto string) bool {
+	return strings.IndexFunc(texto, func(caracter rune) bool {
+		return unicode.IsLetter(caracter) || unicode.IsDigit(caracter)
+	}) >= 0
+}
+
+/*
+ * Función para leer las líneas del código base
+ * param: directorio del código base, extensión de los archivos, límites de la extracción de los .zip y
+ *        preprocesamiento de las entregas
+ * return: cantidad de apariciones de cada línea normalizada (por su hash) o un error si no hay archivos
+ */
+func leerLineasPlantilla(directorio string, extension string, limites LimitesExtraccion, preprocesamiento Preprocesamiento) (map[uint64]int, error) {
+	listado, comprimidos, err := obtenerListadoPlantilla(directorio, extension, limites)
+	if err != nil {
+		return nil, err
+	}
+
+	lineas := make(map[uint64]int)
+	for _, archivo := range listado {
+		contenido, err := comprimidos.leer(archivo)
+		if err != nil {
+			panic(err)
+		}
+
+		for _, linea := range strings.Split(string(preprocesamiento.preprocesar(contenido)), "\n") {
+			if texto := normalizarLinea(linea); esLineaPlantilla(texto) {
+				lineas[calcularHashLinea(texto)]++
+			}
+		}
+	}
+
+	return lineas, nil
+}
+
+/*
+ * Función para eliminar de un contenido las líneas del código base
+ * param: contenido del archivo y apariciones de cada línea del código base
+ * return: contenido sin las líneas del código base (con los mismos números de línea)
+ */
+func eliminarLineasPlantilla(contenido []byte, plantilla map[uint64]int) []byte {
+	restantes := make(map[uint64]int)
+	lineas := strings.Split(string(contenido), "\n")
+
+	for i, linea := range lineas {
+		texto := normalizarLinea(linea)
+		if !esLineaPlantilla(texto) {
+			continue
+		}
+
+		hash := calcularHashLinea(texto)
+		if _, contada := restantes[hash]; !contada {
+			restantes[hash] = plantilla[hash]
+		}
+		if restantes[hash] > 0 {
+			restantes[hash]--
+			lineas[i] = ""
+		}
+	}
+
+	return []byte(strings.Join(lineas, "\n"))
+}
+
+/*
+ * Función para calcular la huella del contenido del código base (para la huella de la caché)
+ * param: directorio del código base, extensión de los archivos y límites de la extracción de los .zip
+ * return: huella hexadecimal (SHA-256) de las rutas y el contenido de sus archivos
+ */
+func calcularHuellaPlantilla(directorio string, extension string, limites LimitesExtraccion) string {
+	huella := sha256.New()
+
+	listado, comprimidos, _ := obtenerListadoPlantilla(directorio, extension, limites)
+	for _, archivo := range listado {
+		contenido, err := comprimidos.leer(archivo)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintf(huella, "%s\x00%x\n", archivo, sha256.Sum256(contenido))
+	}
+
+	return fmt.Sprintf("%x", huella.Sum(nil))
+}
+
+/*
+ * Función para excluir del listado los archivos del código base, si su directorio está dentro del directorio base
+ * param: listado de archivos (rutas relativas al directorio base), directorio base y directorio del código base
+ * return: el listado sin los archivos del código base
+ */
+func excluirArchivosPlantilla(listado []string, directorioActual string, directorioPlantilla string) []string {
+	relativo, err := filepath.Rel(directorioActual, directorioPlantilla)
+	if err != nil || relativo == "." || relativo == ".." || strings.HasPrefix(relativo, ".."+string(filepath.Separator)) {
+		return listado
+	}
+	prefijo := "./" + filepath.ToSlash(relativo) + "/"
+
+	var conservados []string
+	for _, archivo := range listado {
+		if !strings.HasPrefix(archivo, prefijo) {
+			conservados = append(conservados, archivo)
+		}
+	}
+
+	return conservados
+}
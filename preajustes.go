@@ -0,0 +1,188 @@
+/*
+ * Preajustes de la configuración (--save-preset y --preset).
+ *
+ * Para que un departamento estandarice la configuración revisada de cada curso (motor, distancia máxima, evidencia,
+ * preprocesamiento, reportes), con --save-preset=curso.json se guarda la configuración efectiva completa de una
+ * ejecución: el valor de todas las opciones, incluidas las que quedaron con su valor por defecto (así el preajuste no
+ * cambia si una versión posterior cambia un valor por defecto), con la extensión, la distancia máxima y el archivo
+ * CSV indicados como parámetros después de las opciones. La ejecución continúa normalmente.
+ *
+ * Con --preset=curso.json se aplican las opciones del preajuste en otra ejecución o en otro equipo; las opciones y los
+ * parámetros de la línea de comandos tienen prioridad sobre el preajuste (--max-distance y --threshold se consideran
+ * una sola opción). El preajuste no guarda el directorio a analizar (--dir), el resultado del comando report (--from)
+ * ni el token de Canvas (--canvas-token), y un preajuste con una opción desconocida (por ejemplo de una versión
+ * posterior) no se aplica.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+)
+
+// Versión del formato de los preajustes
+const VERSION_PREAJUSTE = 1
+
+// Opciones que no se guardan en los preajustes (propias de cada ejecución o secretas)
+var opcionesSinPreajuste = map[string]bool{
+	"preset":       true,
+	"save-preset":  true,
+	"dir":          true,
+	"from":         true,
+	"canvas-token": true,
+}
+
+// Estructura de un preajuste en JSON
+// - versión del formato
+// - valor de cada opción por su nombre (texto, o lista de textos en las opciones que se pueden repetir)
+type PreajusteJSON struct {
+	Version  int                    `json:"version"`
+	Opciones map[string]interface{} `json:"opciones"`
+}
+
+/*
+ * Función para obtener las opciones indicadas en la línea de comandos, incluidas las de los parámetros después de las
+ * opciones (extensión y distancia máxima o archivo CSV)
+ * param: parámetros después de las opciones
+ * return: los nombres de las opciones indicadas
+ */
+func obtenerOpcionesExplicitas(posicionales []string) map[string]bool {
+	explicitas := make(map[string]bool)
+	flag.Visit(func(opcion *flag.Flag) {
+		explicitas[opcion.Name] = true
+	})
+
+	if len(posicionales) >= 1 {
+		explicitas["ext"] = true
+	}
+	if len(posicionales) == 2 {
+		if _, err := strconv.ParseFloat(posicionales[1], 64); err == nil {
+			explicitas["max-distance"] = true
+		} else {
+			explicitas["csv"] = true
+		}
+	}
+
+	// La distancia máxima del preajuste no debe reemplazar la indicada con la otra opción
+	if explicitas["max-distance"] || explicitas["threshold"] {
+		explicitas["max-distance"], explicitas["threshold"] = true, true
+	}
+
+	return explicitas
+}
+
+/*
+ * Función para aplicar un preajuste a las opciones que no se indicaron en la línea de comandos
+ * param: nombre del archivo del preajuste y parámetros después de las opciones
+ * return: un error si el preajuste no se puede leer, es de otra versión o tiene una opción desconocida o no válida
+ */
+func aplicarPreajuste(nombreArchivo string, posicionales []string) error {
+	contenido, err := ioutil.ReadFile(nombreArchivo)
+	if err != nil {
+		return fmt.Errorf("No se puede leer el preajuste (--preset): %v", err)
+	}
+
+	var preajuste PreajusteJSON
+	if err = json.Unmarshal(contenido, &preajuste); err != nil {
+		return fmt.Errorf("Preajuste (--preset) no válido: %v", err)
+	}
+	if preajuste.Version != VERSION_PREAJUSTE {
+		return fmt.Errorf("El preajuste (--preset) es de otra versión (%d) y se debe volver a generar con --save-preset", preajuste.Version)
+	}
+
+	explicitas := obtenerOpcionesExplicitas(posicionales)
+
+	// Se aplican en orden para que los errores sean siempre los mismos
+	nombres := make([]string, 0, len(preajuste.Opciones))
+	for nombre := range preajuste.Opciones {
+		nombres = append(nombres, nombre)
+	}
+	sort.Strings(nombres)
+
+	for _, nombre := range nombres {
+		if flag.Lookup(nombre) == nil {
+			return fmt.Errorf("Opción \"%s\" del preajuste (--preset) desconocida", nombre)
+		}
+		if opcionesSinPreajuste[nombre] {
+			return fmt.Errorf("La opción \"%s\" no se puede indicar en un preajuste (--preset)", nombre)
+		}
+		if explicitas[nombre] {
+			continue
+		}
+
+		var valores []string
+		switch valor := preajuste.Opciones[nombre].(type) {
+		case string:
+			valores = []string{valor}
+		case []interface{}:
+			for _, elemento := range valor {
+				texto, esTexto := elemento.(string)
+				if !esTexto {
+					return fmt.Errorf("Valor de la opción \"%s\" del preajuste (--preset) no válido", nombre)
+				}
+				valores = append(valores, texto)
+			}
+		default:
+			return fmt.Errorf("Valor de la opción \"%s\" del preajuste (--preset) no válido (debe ser un texto o una lista de textos)", nombre)
+		}
+
+		for _, valor := range valores {
+			if err = flag.Set(nombre, valor); err != nil {
+				return fmt.Errorf("Valor \"%s\" de la opción \"%s\" del preajuste (--preset) no válido: %v", valor, nombre, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Función para guardar la configuración efectiva de la ejecución como preajuste
+ * param: parámetros de ejecución (extensión, distancia máxima y archivo CSV efectivos y archivo del preajuste)
+ */
+func guardarPreajuste(parametros Parametros) {
+	preajuste := PreajusteJSON{Version: VERSION_PREAJUSTE, Opciones: make(map[string]interface{})}
+
+	flag.VisitAll(func(opcion *flag.Flag) {
+		if opcionesSinPreajuste[opcion.Name] {
+			return
+		}
+		if lista, esLista := opcion.Value.(*ListaOpciones); esLista {
+			preajuste.Opciones[opcion.Name] = append([]string{}, *lista...)
+			return
+		}
+		preajuste.Opciones[opcion.Name] = opcion.Value.String()
+	})
+
+	// La extensión, la distancia máxima y el archivo CSV también se pueden indicar como parámetros
+	preajuste.Opciones["ext"] = parametros.extension
+	preajuste.Opciones["csv"] = parametros.nombreTablaCSV
+	preajuste.Opciones["max-distance"] = ""
+	switch parametros.criterioDistancia.criterio {
+	case CRITERIO_VALOR:
+		preajuste.Opciones["threshold"] = strconv.FormatFloat(parametros.criterioDistancia.valor, 'g', -1, 64)
+	case CRITERIO_AUTOMATICO:
+		preajuste.Opciones["threshold"] = CRITERIO_AUTOMATICO
+	default:
+		preajuste.Opciones["threshold"] = ""
+	}
+
+	// Sin escapar <, > y & de las reglas, para que el preajuste se pueda revisar y editar
+	var contenido bytes.Buffer
+	codificador := json.NewEncoder(&contenido)
+	codificador.SetEscapeHTML(false)
+	codificador.SetIndent("", "  ")
+	if err := codificador.Encode(preajuste); err != nil {
+		panic(err)
+	}
+
+	if err := ioutil.WriteFile(parametros.preajusteGuardado, contenido.Bytes(), 0644); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,35 @@
+/*
+ * Precisión de la matriz de distancias en memoria (--matrix-precision).
+ *
+ * Con n archivos la tabla de distancias ocupa n * n entradas de 16 bytes (índice y distancia en float64), por lo que
+ * con 30000 archivos ya no cabe en la memoria de muchos equipos. Con "float32" cada entrada ocupa 4 bytes y, junto con
+ * la matriz de los reportes (n * n en float64), la memoria de las distancias se reduce a la mitad.
+ *
+ * Compromisos de precisión de "float32" (unos 7 dígitos significativos, error relativo menor a 6e-8):
+ * - las distancias de los reportes (con 2 decimales) no cambian mientras sean menores a 100000
+ * - los archivos idénticos siguen a distancia 0 y el orden de las distancias solo cambia entre pares casi empatados
+ * - un par a una distancia casi igual a la distancia máxima puede quedar del otro lado del límite
+ * - el archivo CSV con --precision=full muestra las distancias redondeadas a float32
+ * Con --squared se almacena el cuadrado de la distancia, que pierde la misma precisión relativa antes de la raíz.
+ */
+
+package main
+
+// Precisiones de la matriz de distancias
+const (
+	PRECISION_MATRIZ_COMPLETA = "float64"
+	PRECISION_MATRIZ_REDUCIDA = "float32"
+)
+
+/*
+ * Función para almacenar un valor en la tabla de distancias de un archivo para el archivo con el índice indicado
+ * param: información del archivo, índice del otro archivo y valor (la distancia al cuadrado si la tabla es de distancias cuadradas)
+ */
+func asignarValorDistancia(codigoFuente *CodigoFuente, indice int, valor float64) {
+	if codigoFuente.tablaDistancias32 != nil {
+		codigoFuente.tablaDistancias32[indice] = float32(valor)
+		return
+	}
+
+	codigoFuente.tablaDistancias[indice] = Distancia{indiceCodigoFuente: indice, distancia: valor}
+}
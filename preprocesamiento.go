@@ -0,0 +1,159 @@
+/*
+ * Preprocesamiento del contenido de cada archivo antes del análisis.
+ *
+ * Permite eliminar el comentario de encabezado que la plantilla del curso exige al inicio de cada archivo
+ * (autor, fecha, curso), ya que es idéntico en todas las entregas honestas:
+ * - por cantidad de líneas (--strip-header-lines)
+ * - por una expresión regular que debe coincidir desde el inicio del archivo (--strip-header-regex)
+ * y, con --strip-comments, todos los comentarios según el lenguaje de la extensión (de línea y de bloque, ver
+ * comentarios.go), ya que los estudiantes suelen borrar o traducir los comentarios para disimular una copia.
+ * Después se aplican las normalizaciones de --rename-identifiers, --lowercase y --collapse-whitespace (ver
+ * normalizacion.go) y, con --template, se eliminan las líneas del código base entregado por el docente (ver
+ * plantilla.go). El texto eliminado se reemplaza por la misma cantidad de saltos de línea para conservar los números de línea.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// Estructura con las transformaciones a aplicar al contenido de cada archivo
+// - cantidad de líneas del encabezado a eliminar
+// - expresión regular del encabezado a eliminar (nil si no se usa)
+// - sintaxis de los comentarios a eliminar (nil si se conservan)
+// - analizador léxico con el que se renombran los identificadores (nil si se conservan)
+// - si se pasa el contenido a minúsculas y si se colapsan los espacios de cada línea
+// - apariciones de cada línea del código base a eliminar (nil si no hay código base)
+// - contenido de los archivos de los .zip de las entregas (se asigna al obtener el listado, ver comprimidos.go)
+type Preprocesamiento struct {
+	lineasEncabezado    int
+	expresionEncabezado *regexp.Regexp
+	comentarios         *SintaxisComentarios
+	identificadores     *AnalizadorLexico
+	minusculas          bool
+	espacios            bool
+	plantilla           map[uint64]int
+	archivosZip         ArchivosComprimidos
+}
+
+/*
+ * Función para crear el preprocesamiento indicado por el usuario
+ * param: parámetros de ejecución
+ * return: el preprocesamiento o un error si la expresión regular no es válida, los identificadores no se pueden
+ *         renombrar en la extensión o el código base no tiene archivos
+ */
+func crearPreprocesamiento(parametros Parametros) (Preprocesamiento, error) {
+	preprocesamiento := Preprocesamiento{lineasEncabezado: parametros.lineasEncabezado, minusculas: parametros.minusculas, espacios: parametros.colapsarEspacios}
+
+	if parametros.lineasEncabezado < 0 {
+		return preprocesamiento, fmt.Errorf("La cantidad de líneas del encabezado (--strip-header-lines) no puede ser negativa")
+	}
+
+	if parametros.expresionEncabezado != "" {
+		// (?s) para que el punto incluya los saltos de línea de un encabezado de varias líneas
+		expresion, err := regexp.Compile("(?s)" + parametros.expresionEncabezado)
+		if err != nil {
+			return preprocesamiento, fmt.Errorf("Expresión regular del encabezado (--strip-header-regex) no válida: %v", err)
+		}
+		preprocesamiento.expresionEncabezado = expresion
+	}
+
+	if parametros.sinComentarios {
+		sintaxis := obtenerSintaxisComentarios(parametros.extension)
+		preprocesamiento.comentarios = &sintaxis
+	}
+
+	if parametros.sinIdentificadores {
+		analizador, err := obtenerAnalizadorRenombrado(parametros.extension)
+		if err != nil {
+			return preprocesamiento, err
+		}
+		preprocesamiento.identificadores = analizador
+	}
+
+	// El código base se lee con las demás transformaciones, antes de activar la eliminación de sus líneas
+	if parametros.directorioPlantilla != "" {
+		limites, err := crearLimitesExtraccion(parametros)
+		if err != nil {
+			return preprocesamiento, err
+		}
+		plantilla, err := leerLineasPlantilla(parametros.directorioPlantilla, parametros.extension, limites, preprocesamiento)
+		if err != nil {
+			return preprocesamiento, err
+		}
+		preprocesamiento.plantilla = plantilla
+	}
+
+	return preprocesamiento, nil
+}
+
+/*
+ * Función para reemplazar un texto por la misma cantidad de saltos de línea que contiene
+ * param: texto a reemplazar
+ * return: saltos de línea del texto
+ */
+func conservarSaltosLinea(texto []byte) []byte {
+	return bytes.Repeat([]byte("\n"), bytes.Count(texto, []byte("\n")))
+}
+
+/*
+ * Función para eliminar el encabezado de un contenido
+ * param: contenido del archivo
+ * return: contenido sin el encabezado (con los mismos números de línea)
+ */
+func (preprocesamiento Preprocesamiento) eliminarEncabezado(contenido []byte) []byte {
+	if preprocesamiento.lineasEncabezado > 0 {
+		fin := 0
+		for linea := 0; linea < preprocesamiento.lineasEncabezado && fin < len(contenido); linea++ {
+			siguiente := bytes.IndexByte(contenido[fin:], '\n')
+			if siguiente < 0 {
+				fin = len(contenido)
+			} else {
+				fin += siguiente + 1
+			}
+		}
+		contenido = append(conservarSaltosLinea(contenido[:fin]), contenido[fin:]...)
+	}
+
+	if preprocesamiento.expresionEncabezado != nil {
+		// Se permiten espacios y líneas vacías antes del encabezado
+		inicio := len(contenido) - len(bytes.TrimLeft(contenido, " \t\r\n"))
+
+		if ubicacion := preprocesamiento.expresionEncabezado.FindIndex(contenido[inicio:]); ubicacion != nil && ubicacion[0] == 0 {
+			fin := inicio + ubicacion[1]
+			contenido = append(conservarSaltosLinea(contenido[:fin]), contenido[fin:]...)
+		}
+	}
+
+	return contenido
+}
+
+/*
+ * Función para aplicar todas las transformaciones al contenido de un archivo
+ * param: contenido original del archivo
+ * return: contenido a analizar
+ */
+func (preprocesamiento Preprocesamiento) preprocesar(contenido []byte) []byte {
+	contenido = preprocesamiento.eliminarEncabezado(contenido)
+
+	if preprocesamiento.comentarios != nil {
+		contenido = eliminarComentarios(contenido, *preprocesamiento.comentarios)
+	}
+	if preprocesamiento.identificadores != nil {
+		contenido = normalizarIdentificadores(contenido, preprocesamiento.identificadores)
+	}
+	if preprocesamiento.minusculas {
+		contenido = bytes.ToLower(contenido)
+	}
+	if preprocesamiento.espacios {
+		contenido = colapsarEspacios(contenido)
+	}
+	if preprocesamiento.plantilla != nil {
+		contenido = eliminarLineasPlantilla(contenido, preprocesamiento.plantilla)
+	}
+
+	return contenido
+}
@@ -0,0 +1,93 @@
+/*
+ * Presupuesto de tiempo de la evidencia por fragmentos (--evidence-pair-timeout y --evidence-timeout).
+ *
+ * La búsqueda de fragmentos comunes con Rabin-Karp es casi lineal en los archivos normales, pero un par patológico
+ * (por ejemplo dos archivos con miles de líneas repetidas, en los que cada ventana coincide con miles de ventanas del
+ * otro archivo) puede tardar minutos y detener todo el reporte. Con un presupuesto:
+ * - cada par tiene como máximo --evidence-pair-timeout para encontrar sus fragmentos
+ * - la evidencia de todos los pares tiene como máximo --evidence-timeout en total
+ * Un par que agota su presupuesto (o que no alcanza a compararse porque se agotó el presupuesto total) se reporta solo
+ * con su distancia según el motor de características, sin fragmentos ni cobertura, y el análisis continúa.
+ * El presupuesto también aplica a la evidencia por winnowing (--evidence-engine=winnowing) y por Greedy String Tiling
+ * (--evidence-engine=gst), que buscan por par.
+ *
+ * Con el arreglo de sufijos (--evidence-engine=suffix-array) los fragmentos de todo el corpus se obtienen de una sola
+ * vez en tiempo casi lineal, por lo que el presupuesto no aplica; tampoco a la evidencia por líneas.
+ */
+
+package main
+
+import "time"
+
+// Cantidad de comparaciones de ventanas entre cada consulta del reloj
+const VERIFICACION_PRESUPUESTO = 256
+
+// Estructura del presupuesto de tiempo de la evidencia
+// - tiempo máximo por par y total (0 si no hay límite)
+// - momento en el que inició la evidencia
+type PresupuestoEvidencia struct {
+	porPar time.Duration
+	total  time.Duration
+	inicio time.Time
+}
+
+/*
+ * Función para iniciar el presupuesto de tiempo de la evidencia
+ * param: tiempo máximo por par y total (0 si no hay límite)
+ * return: el presupuesto, contado desde este momento
+ */
+func iniciarPresupuestoEvidencia(porPar time.Duration, total time.Duration) PresupuestoEvidencia {
+	return PresupuestoEvidencia{porPar: porPar, total: total, inicio: time.Now()}
+}
+
+/*
+ * Función para saber si se agotó el presupuesto total
+ * return: verdadero si el presupuesto total tiene límite y ya pasó
+ */
+func (presupuesto PresupuestoEvidencia) agotado() bool {
+	return presupuesto.total > 0 && time.Since(presupuesto.inicio) >= presupuesto.total
+}
+
+/*
+ * Función para obtener el límite de tiempo del par que se va a comparar
+ * return: el primero entre el límite del par y el límite total (cero si no hay límite)
+ */
+func (presupuesto PresupuestoEvidencia) limitePar() time.Time {
+	var limite time.Time
+
+	if presupuesto.porPar > 0 {
+		limite = time.Now().Add(presupuesto.porPar)
+	}
+	if presupuesto.total > 0 {
+		if limiteTotal := presupuesto.inicio.Add(presupuesto.total); limite.IsZero() || limiteTotal.Before(limite) {
+			limite = limiteTotal
+		}
+	}
+
+	return limite
+}
+
+/*
+ * Función para saber si ya pasó un límite de tiempo
+ * param: límite (cero si no hay límite)
+ * return: verdadero si el límite existe y ya pasó
+ */
+func superaLimite(limite time.Time) bool {
+	return !limite.IsZero() && time.Now().After(limite)
+}
+
+/*
+ * Función para contar los pares que se quedaron sin evidencia por el presupuesto de tiempo
+ * param: comparación de los pares candidatos
+ * return: cantidad de pares sin evidencia
+ */
+func contarParesSinEvidencia(pares []ParFragmentos) int {
+	cantidad := 0
+	for _, par := range pares {
+		if par.sinEvidencia {
+			cantidad++
+		}
+	}
+
+	return cantidad
+}
@@ -0,0 +1,521 @@
+/*
+ * Protocolo para extensiones de editor (--rpc).
+ *
+ * SASC se ejecuta como un proceso que atiende peticiones JSON-RPC 2.0 por la entrada y la salida estándar,
+ * pensado como backend de una extensión de VS Code (u otro editor) para explorar un corpus de forma interactiva:
+ * - open       {"directory": "...", "extension": "java"}: analiza el corpus del directorio (con el motor y los
+ *              demás parámetros de la línea de comandos) y lo deja abierto para las siguientes peticiones (los .zip
+ *              que no son válidos o superan los límites de la extracción se omiten y se devuelven en "zip_omitidos")
+ * - neighbors  {"file": "...", "k": 5}: los k archivos más cercanos al archivo indicado (por ejemplo el archivo
+ *              abierto en el editor), con su distancia y percentil
+ * - fragments  {"file1": "...", "file2": "..."}: los fragmentos comunes del par, con la línea y columna de inicio
+ *              y fin en ambos archivos (como la evidencia "rabin-karp")
+ * - shutdown   termina el proceso
+ *
+ * Los archivos se indican con su ruta absoluta, relativa al directorio del corpus o con su nombre en los reportes.
+ * Cada mensaje puede ir en una línea (JSON delimitado por saltos de línea) o con el encabezado "Content-Length"
+ * de LSP (el formato de vscode-jsonrpc); la respuesta usa el mismo formato de la petición.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Códigos de error de JSON-RPC 2.0 (los de -32000 en adelante son propios de SASC)
+const (
+	ERROR_RPC_SINTAXIS          = -32700
+	ERROR_RPC_PETICION          = -32600
+	ERROR_RPC_METODO            = -32601
+	ERROR_RPC_PARAMETROS        = -32602
+	ERROR_RPC_CORPUS_NO_ABIERTO = -32001
+	ERROR_RPC_ANALISIS          = -32002
+)
+
+// Cantidad de vecinos de la petición "neighbors" si no se indica
+const VECINOS_RPC_DEFECTO = 5
+
+// Estructura de una petición JSON-RPC (sin identificador es una notificación y no tiene respuesta)
+type PeticionRPC struct {
+	Version    string          `json:"jsonrpc"`
+	Id         json.RawMessage `json:"id,omitempty"`
+	Metodo     string          `json:"method"`
+	Parametros json.RawMessage `json:"params,omitempty"`
+}
+
+// Estructura de un error JSON-RPC
+type ErrorRPC struct {
+	Codigo  int    `json:"code"`
+	Mensaje string `json:"message"`
+}
+
+// Estructura de una respuesta JSON-RPC (con resultado o con error)
+type RespuestaRPC struct {
+	Version   string          `json:"jsonrpc"`
+	Id        json.RawMessage `json:"id"`
+	Resultado interface{}     `json:"result,omitempty"`
+	Error     *ErrorRPC       `json:"error,omitempty"`
+}
+
+// Estructura de un archivo del corpus abierto
+type ArchivoRPC struct {
+	Nombre string `json:"nombre"`
+	Ruta   string `json:"ruta"`
+}
+
+// Estructura del resultado de la petición "open"
+type CorpusRPC struct {
+	Directorio        string       `json:"directorio"`
+	Extension         string       `json:"extension"`
+	Motor             string       `json:"motor"`
+	CriterioDistancia string       `json:"criterio_distancia"`
+	DistanciaMaxima   *float64     `json:"distancia_maxima,omitempty"`
+	Archivos          []ArchivoRPC `json:"archivos"`
+	Descartados       []string     `json:"descartados"`
+	ZipOmitidos       []ZipOmitido `json:"zip_omitidos"`
+	Grupos            int          `json:"grupos"`
+}
+
+// Estructura de un vecino de la petición "neighbors"
+// - si está a la distancia máxima (falso si no se definió)
+type VecinoRPC struct {
+	ArchivoRPC
+	Distancia float64 `json:"distancia"`
+	Percentil float64 `json:"percentil"`
+	Cercano   bool    `json:"cercano"`
+}
+
+// Estructura de una ubicación en un archivo (línea y columna desde 1)
+type UbicacionRPC struct {
+	Linea   int `json:"linea"`
+	Columna int `json:"columna"`
+}
+
+// Estructura de un fragmento común de la petición "fragments"
+type FragmentoRPC struct {
+	Tokens  int          `json:"tokens"`
+	Inicio1 UbicacionRPC `json:"inicio1"`
+	Fin1    UbicacionRPC `json:"fin1"`
+	Inicio2 UbicacionRPC `json:"inicio2"`
+	Fin2    UbicacionRPC `json:"fin2"`
+}
+
+// Estructura del resultado de la petición "fragments"
+// - distancia del par (null si no se calculó con --pairs=lsh)
+type FragmentosRPC struct {
+	Archivo1   ArchivoRPC     `json:"archivo1"`
+	Archivo2   ArchivoRPC     `json:"archivo2"`
+	Distancia  *float64       `json:"distancia"`
+	Cobertura1 float64        `json:"cobertura1"`
+	Cobertura2 float64        `json:"cobertura2"`
+	Fragmentos []FragmentoRPC `json:"fragmentos"`
+	Agotado    bool           `json:"presupuesto_agotado,omitempty"`
+}
+
+// Estructura de la sesión del protocolo: parámetros de ejecución y el corpus abierto (tabla vacía si no hay)
+type SesionRPC struct {
+	parametros        Parametros
+	motor             Motor
+	preprocesamiento  Preprocesamiento
+	formatoRutas      FormatoRutas
+	directorio        string
+	tablaCodigoFuente []CodigoFuente
+	resultado         ResultadoAnalisis
+	distribucion      []float64
+	terminada         bool
+}
+
+/*
+ * Función para crear un error JSON-RPC
+ * param: código del error, formato del mensaje y sus valores
+ * return: el error
+ */
+func crearErrorRPC(codigo int, formato string, valores ...interface{}) *ErrorRPC {
+	return &ErrorRPC{Codigo: codigo, Mensaje: fmt.Sprintf(formato, valores...)}
+}
+
+/*
+ * Función para leer el siguiente mensaje, en una línea o con el encabezado "Content-Length"
+ * param: lector de la entrada
+ * return: el mensaje, si tenía encabezado y un error (io.EOF al terminar la entrada)
+ */
+func leerMensajeRPC(lector *bufio.Reader) ([]byte, bool, error) {
+	for {
+		linea, err := lector.ReadString('\n')
+		if err != nil && (err != io.EOF || strings.TrimSpace(linea) == "") {
+			return nil, false, err
+		}
+
+		linea = strings.TrimSpace(linea)
+		if linea == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(strings.ToLower(linea), "content-length:") {
+			return []byte(linea), false, nil
+		}
+
+		longitud, err := strconv.Atoi(strings.TrimSpace(linea[len("content-length:"):]))
+		if err != nil || longitud < 0 {
+			return nil, true, fmt.Errorf("Encabezado \"%s\" no válido", linea)
+		}
+
+		// Los demás encabezados (por ejemplo Content-Type) terminan con una línea vacía
+		for linea != "" {
+			if linea, err = lector.ReadString('\n'); err != nil {
+				return nil, true, err
+			}
+			linea = strings.TrimSpace(linea)
+		}
+
+		mensaje := make([]byte, longitud)
+		if _, err = io.ReadFull(lector, mensaje); err != nil {
+			return nil, true, err
+		}
+
+		return mensaje, true, nil
+	}
+}
+
+/*
+ * Función para escribir una respuesta en el mismo formato de la petición
+ * param: escritor de la salida, respuesta y si se usa el encabezado "Content-Length"
+ */
+func escribirRespuestaRPC(escritor *bufio.Writer, respuesta RespuestaRPC, encabezado bool) {
+	contenido, err := json.Marshal(respuesta)
+	if err != nil {
+		panic(err)
+	}
+
+	if encabezado {
+		fmt.Fprintf(escritor, "Content-Length: %d\r\n\r\n%s", len(contenido), contenido)
+	} else {
+		fmt.Fprintf(escritor, "%s\n", contenido)
+	}
+
+	if err = escritor.Flush(); err != nil {
+		panic(err)
+	}
+}
+
+/*
+ * Función para atender las peticiones JSON-RPC de la entrada estándar hasta "shutdown" o el fin de la entrada
+ * param: parámetros de ejecución, el motor a emplear, el preprocesamiento del contenido y el formato de las rutas
+ * return: un error si la entrada no se puede leer
+ */
+func atenderProtocoloEditor(parametros Parametros, motor Motor, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas) error {
+	sesion := &SesionRPC{parametros: parametros, motor: motor, preprocesamiento: preprocesamiento, formatoRutas: formatoRutas}
+
+	lector, escritor := bufio.NewReader(os.Stdin), bufio.NewWriter(os.Stdout)
+
+	for !sesion.terminada {
+		mensaje, encabezado, err := leerMensajeRPC(lector)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil && !encabezado {
+			return err
+		}
+
+		var peticion PeticionRPC
+		if err == nil {
+			err = json.Unmarshal(mensaje, &peticion)
+		}
+		if err != nil {
+			escribirRespuestaRPC(escritor, RespuestaRPC{Version: "2.0", Id: json.RawMessage("null"), Error: crearErrorRPC(ERROR_RPC_SINTAXIS, "Mensaje no válido: %v", err)}, encabezado)
+			continue
+		}
+
+		resultado, errorRPC := sesion.atender(peticion)
+		if len(peticion.Id) == 0 {
+			continue
+		}
+
+		respuesta := RespuestaRPC{Version: "2.0", Id: peticion.Id, Resultado: resultado, Error: errorRPC}
+		if errorRPC == nil && resultado == nil {
+			respuesta.Resultado = json.RawMessage("null")
+		}
+		escribirRespuestaRPC(escritor, respuesta, encabezado)
+	}
+
+	return nil
+}
+
+/*
+ * Función para atender una petición
+ * param: petición
+ * return: el resultado de la petición o un error JSON-RPC
+ */
+func (sesion *SesionRPC) atender(peticion PeticionRPC) (interface{}, *ErrorRPC) {
+	if peticion.Version != "2.0" {
+		return nil, crearErrorRPC(ERROR_RPC_PETICION, "La petición debe ser JSON-RPC 2.0 (\"jsonrpc\": \"2.0\")")
+	}
+
+	switch peticion.Metodo {
+	case "open":
+		var parametros struct {
+			Directorio string `json:"directory"`
+			Extension  string `json:"extension"`
+		}
+		if err := json.Unmarshal(peticion.Parametros, &parametros); err != nil || parametros.Directorio == "" {
+			return nil, crearErrorRPC(ERROR_RPC_PARAMETROS, "La petición \"open\" requiere el directorio del corpus (\"directory\")")
+		}
+		return sesion.abrir(parametros.Directorio, parametros.Extension)
+
+	case "neighbors":
+		parametros := struct {
+			Archivo string `json:"file"`
+			Vecinos int    `json:"k"`
+		}{Vecinos: VECINOS_RPC_DEFECTO}
+		if err := json.Unmarshal(peticion.Parametros, &parametros); err != nil || parametros.Archivo == "" || parametros.Vecinos < 0 {
+			return nil, crearErrorRPC(ERROR_RPC_PARAMETROS, "La petición \"neighbors\" requiere el archivo (\"file\") y una cantidad de vecinos (\"k\") no negativa")
+		}
+		return sesion.obtenerVecinos(parametros.Archivo, parametros.Vecinos)
+
+	case "fragments":
+		var parametros struct {
+			Archivo1 string `json:"file1"`
+			Archivo2 string `json:"file2"`
+		}
+		if err := json.Unmarshal(peticion.Parametros, &parametros); err != nil || parametros.Archivo1 == "" || parametros.Archivo2 == "" {
+			return nil, crearErrorRPC(ERROR_RPC_PARAMETROS, "La petición \"fragments\" requiere los dos archivos (\"file1\" y \"file2\")")
+		}
+		return sesion.obtenerFragmentos(parametros.Archivo1, parametros.Archivo2)
+
+	case "shutdown":
+		sesion.terminada = true
+		return nil, nil
+	}
+
+	return nil, crearErrorRPC(ERROR_RPC_METODO, "Método \"%s\" no soportado (open | neighbors | fragments | shutdown)", peticion.Metodo)
+}
+
+/*
+ * Función para analizar el corpus de un directorio y dejarlo abierto en la sesión
+ * param: directorio del corpus y extensión de los archivos (vacía para la de la línea de comandos)
+ * return: la descripción del corpus abierto o un error JSON-RPC
+ */
+func (sesion *SesionRPC) abrir(directorio string, extension string) (interface{}, *ErrorRPC) {
+	if extension == "" {
+		extension = sesion.parametros.extension
+	}
+
+	directorio, err := filepath.Abs(directorio)
+	if err == nil {
+		var informacion os.FileInfo
+		if informacion, err = os.Stat(directorio); err == nil && !informacion.IsDir() {
+			err = fmt.Errorf("no es un directorio")
+		}
+	}
+	if err != nil {
+		return nil, crearErrorRPC(ERROR_RPC_ANALISIS, "No se puede abrir el directorio \"%s\": %v", directorio, err)
+	}
+
+	limites, err := crearLimitesExtraccion(sesion.parametros)
+	if err != nil {
+		return nil, crearErrorRPC(ERROR_RPC_ANALISIS, "%v", err)
+	}
+	listado, archivosZip, omitidos, err := obtenerListadoEntregas(directorio, extension, limites)
+	if err != nil {
+		return nil, crearErrorRPC(ERROR_RPC_ANALISIS, "No se puede obtener el listado de \"%s\": %v", directorio, err)
+	}
+
+	// La etiqueta por defecto de las rutas (--paths=label) es el nombre del directorio del corpus
+	formatoRutas := sesion.formatoRutas
+	if sesion.parametros.etiquetaRaiz == "" {
+		formatoRutas.etiqueta = filepath.Base(directorio)
+	}
+
+	var tablaCodigoFuente []CodigoFuente
+	var descartados []string
+	var arena ArenaCaracteristicas
+
+	// Las rutas del corpus abierto son absolutas, también las de los archivos de los .zip
+	archivosZipAbsolutos := make(ArchivosComprimidos, len(archivosZip))
+	for ruta, contenido := range archivosZip {
+		archivosZipAbsolutos[filepath.Join(directorio, ruta)] = contenido
+	}
+
+	for _, ruta := range listado {
+		contenido, err := archivosZipAbsolutos.leer(filepath.Join(directorio, ruta))
+		if err != nil {
+			return nil, crearErrorRPC(ERROR_RPC_ANALISIS, "No se puede leer \"%s\": %v", ruta, err)
+		}
+
+		codigoFuente := CodigoFuente{nombre: formatoRutas.formatear(ruta), ruta: filepath.Join(directorio, ruta), tamano: len(contenido), contenido: sesion.preprocesamiento.preprocesar(contenido), archivosZip: archivosZipAbsolutos}
+
+		if sesion.parametros.minimoContenido > 0 && contarContenidoSignificativo(codigoFuente.contenido, extension) < sesion.parametros.minimoContenido {
+			descartados = append(descartados, codigoFuente.nombre)
+			continue
+		}
+
+		caracterizarEnArena(&arena, sesion.motor, &codigoFuente)
+		tablaCodigoFuente = append(tablaCodigoFuente, codigoFuente)
+	}
+
+	proyeccion := proyectarCorpus(tablaCodigoFuente, sesion.motor)
+
+	tablaCodigoFuente = determinarDistanciasEntreArchivos(tablaCodigoFuente, sesion.motor, sesion.parametros, nil)
+
+	parametros := sesion.parametros
+	parametros.extension = extension
+
+	sesion.directorio, sesion.tablaCodigoFuente = directorio, tablaCodigoFuente
+	sesion.resultado = construirResultadoAnalisis(tablaCodigoFuente, parametros, sesion.motor, descartados, nil, proyeccion)
+	sesion.distribucion = sesion.resultado.distribucion
+	if sesion.distribucion == nil {
+		sesion.distribucion = obtenerDistribucionDistancias(sesion.resultado)
+	}
+
+	corpus := CorpusRPC{
+		Directorio:        directorio,
+		Extension:         extension,
+		Motor:             sesion.resultado.motor,
+		CriterioDistancia: sesion.resultado.criterio.criterio,
+		Archivos:          []ArchivoRPC{},
+		Descartados:       descartados,
+		ZipOmitidos:       omitidos,
+		Grupos:            len(sesion.resultado.grupos),
+	}
+	if corpus.Descartados == nil {
+		corpus.Descartados = []string{}
+	}
+	if corpus.ZipOmitidos == nil {
+		corpus.ZipOmitidos = []ZipOmitido{}
+	}
+	if sesion.resultado.tieneDistanciaMaxima() {
+		distanciaMaxima := sesion.resultado.criterio.limite()
+		corpus.DistanciaMaxima = &distanciaMaxima
+	}
+	for i := range tablaCodigoFuente {
+		corpus.Archivos = append(corpus.Archivos, sesion.describirArchivo(i))
+	}
+
+	return corpus, nil
+}
+
+/*
+ * Función para describir un archivo del corpus abierto
+ * param: índice del archivo
+ * return: nombre en los reportes y ruta absoluta del archivo
+ */
+func (sesion *SesionRPC) describirArchivo(indice int) ArchivoRPC {
+	return ArchivoRPC{Nombre: sesion.tablaCodigoFuente[indice].nombre, Ruta: sesion.tablaCodigoFuente[indice].ruta}
+}
+
+/*
+ * Función para buscar un archivo en el corpus abierto
+ * param: ruta absoluta, ruta relativa al directorio del corpus o nombre del archivo en los reportes
+ * return: índice del archivo o un error JSON-RPC si no hay corpus abierto o el archivo no está en él
+ */
+func (sesion *SesionRPC) buscarArchivo(archivo string) (int, *ErrorRPC) {
+	if sesion.directorio == "" {
+		return -1, crearErrorRPC(ERROR_RPC_CORPUS_NO_ABIERTO, "No hay un corpus abierto (petición \"open\")")
+	}
+
+	ruta := archivo
+	if !filepath.IsAbs(ruta) {
+		ruta = filepath.Join(sesion.directorio, ruta)
+	}
+	ruta = filepath.Clean(ruta)
+
+	for i, codigoFuente := range sesion.tablaCodigoFuente {
+		if codigoFuente.ruta == ruta || codigoFuente.nombre == archivo {
+			return i, nil
+		}
+	}
+
+	return -1, crearErrorRPC(ERROR_RPC_PARAMETROS, "El archivo \"%s\" no está en el corpus abierto (o se descartó)", archivo)
+}
+
+/*
+ * Función para obtener los archivos más cercanos a un archivo del corpus abierto
+ * param: archivo y cantidad de vecinos
+ * return: los vecinos ordenados desde el más cercano o un error JSON-RPC
+ */
+func (sesion *SesionRPC) obtenerVecinos(archivo string, cantidad int) (interface{}, *ErrorRPC) {
+	indice, errorRPC := sesion.buscarArchivo(archivo)
+	if errorRPC != nil {
+		return nil, errorRPC
+	}
+
+	vecinos := []VecinoRPC{}
+
+	for _, j := range ordenarIndicesPorDistancia(sesion.resultado.matriz[indice]) {
+		if len(vecinos) >= cantidad {
+			break
+		}
+		distancia := sesion.resultado.matriz[indice][j]
+		if j == indice || !esDistanciaCalculada(distancia) {
+			continue
+		}
+
+		vecinos = append(vecinos, VecinoRPC{
+			ArchivoRPC: sesion.describirArchivo(j),
+			Distancia:  distancia,
+			Percentil:  calcularPercentil(sesion.distribucion, distancia),
+			Cercano:    sesion.resultado.tieneDistanciaMaxima() && distancia <= sesion.resultado.criterio.limite(),
+		})
+	}
+
+	return vecinos, nil
+}
+
+/*
+ * Función para obtener los fragmentos comunes de un par de archivos del corpus abierto
+ * param: ambos archivos
+ * return: los fragmentos comunes y la cobertura del par o un error JSON-RPC
+ */
+func (sesion *SesionRPC) obtenerFragmentos(archivo1 string, archivo2 string) (interface{}, *ErrorRPC) {
+	i, errorRPC := sesion.buscarArchivo(archivo1)
+	if errorRPC != nil {
+		return nil, errorRPC
+	}
+	j, errorRPC := sesion.buscarArchivo(archivo2)
+	if errorRPC != nil {
+		return nil, errorRPC
+	}
+
+	tokens1, tokens2 := obtenerTokensArchivo(sesion.tablaCodigoFuente, i), obtenerTokensArchivo(sesion.tablaCodigoFuente, j)
+
+	// Una petición con un par patológico no debe bloquear al editor: sin fragmentos si agota el presupuesto del par
+	presupuesto := iniciarPresupuestoEvidencia(sesion.parametros.presupuestoPar, 0)
+	fragmentos, completo := buscarFragmentosComunes(tokens1, tokens2, sesion.parametros.minimoTokens, presupuesto.limitePar())
+	fragmentos = filtrarFragmentosPorLineas(fragmentos, tokens1, tokens2, sesion.parametros.minimoLineas)
+	resumen := resumirFragmentos(fragmentos, len(tokens1), len(tokens2))
+
+	resultado := FragmentosRPC{
+		Archivo1:   sesion.describirArchivo(i),
+		Archivo2:   sesion.describirArchivo(j),
+		Cobertura1: resumen.cobertura1,
+		Cobertura2: resumen.cobertura2,
+		Fragmentos: []FragmentoRPC{},
+		Agotado:    !completo,
+	}
+	if distancia := sesion.resultado.matriz[i][j]; esDistanciaCalculada(distancia) {
+		resultado.Distancia = &distancia
+	}
+
+	for _, fragmento := range resumen.fragmentos {
+		inicio1, fin1 := tokens1[fragmento.inicio1], tokens1[fragmento.inicio1+fragmento.longitud-1]
+		inicio2, fin2 := tokens2[fragmento.inicio2], tokens2[fragmento.inicio2+fragmento.longitud-1]
+
+		resultado.Fragmentos = append(resultado.Fragmentos, FragmentoRPC{
+			Tokens:  fragmento.longitud,
+			Inicio1: UbicacionRPC{Linea: inicio1.numero, Columna: inicio1.columna},
+			Fin1:    UbicacionRPC{Linea: fin1.numero, Columna: fin1.columna + len([]rune(fin1.texto)) - 1},
+			Inicio2: UbicacionRPC{Linea: inicio2.numero, Columna: inicio2.columna},
+			Fin2:    UbicacionRPC{Linea: fin2.numero, Columna: fin2.columna + len([]rune(fin2.texto)) - 1},
+		})
+	}
+
+	return resultado, nil
+}
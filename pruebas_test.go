@@ -0,0 +1,237 @@
+/*
+ * Utilidades de las pruebas: corpus temporales y análisis completos sin salida en la consola.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/*
+ * Función para crear los parámetros de ejecución de una prueba, con los valores por defecto de las opciones
+ * (los de la línea de comandos sin argumentos)
+ * return: los parámetros por defecto (extensión go, motor ascii y sin distancia máxima)
+ */
+func crearParametrosPrueba() Parametros {
+	parametros, err := obtenerParametros(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return parametros
+}
+
+/*
+ * Función para crear un corpus temporal y cambiar a su directorio durante la prueba
+ * param: prueba y contenido de cada archivo por su ruta relativa
+ * return: directorio del corpus
+ */
+func crearCorpusPrueba(t testing.TB, archivos map[string]string) string {
+	t.Helper()
+
+	directorio := t.TempDir()
+	for ruta, contenido := range archivos {
+		completa := filepath.Join(directorio, filepath.FromSlash(ruta))
+		if err := os.MkdirAll(filepath.Dir(completa), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(completa, []byte(contenido), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	anterior, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(directorio); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(anterior) })
+
+	return directorio
+}
+
+/*
+ * Función para capturar la salida estándar de una función durante una prueba
+ * param: prueba y función a ejecutar
+ * return: lo que la función escribió en la salida estándar
+ */
+func capturarSalidaPrueba(t *testing.T, funcion func()) string {
+	t.Helper()
+
+	archivo, err := os.CreateTemp(t.TempDir(), "salida")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archivo.Close()
+
+	salida := os.Stdout
+	os.Stdout = archivo
+	defer func() { os.Stdout = salida }()
+
+	funcion()
+
+	contenido, err := os.ReadFile(archivo.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(contenido)
+}
+
+/*
+ * Función para analizar un corpus temporal como lo hace la línea de comandos (fases 1 a 3), con destinos de salida
+ * param: prueba, parámetros de ejecución, contenido de cada archivo por su ruta relativa y destinos de salida
+ * return: el resultado del análisis, lo que se imprimió en la consola y el error de los destinos o los ganchos
+ */
+func analizarCorpusDestinosPrueba(t *testing.T, parametros Parametros, archivos map[string]string, destinos []DestinoSalida) (ResultadoAnalisis, string, error) {
+	t.Helper()
+
+	directorio := crearCorpusPrueba(t, archivos)
+
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listado, archivosZip, omitidos, err := obtenerListadoEntregas(directorio, parametros.extension, limites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	motor, err := crearMotor(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	preprocesamiento, err := crearPreprocesamiento(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	preprocesamiento.archivosZip = archivosZip
+	formatoRutas, err := crearFormatoRutas(parametros, directorio)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resultado ResultadoAnalisis
+	salida := capturarSalidaPrueba(t, func() {
+		resultado, err = analizarListado(listado, omitidos, parametros, motor, preprocesamiento, formatoRutas, nil, destinos, directorio, directorio)
+	})
+
+	return resultado, salida, err
+}
+
+/*
+ * Función para analizar un corpus temporal como lo hace la línea de comandos (fases 1 a 3)
+ * param: prueba, parámetros de ejecución y contenido de cada archivo por su ruta relativa
+ * return: el resultado del análisis y lo que se imprimió en la consola
+ */
+func analizarCorpusSalidaPrueba(t *testing.T, parametros Parametros, archivos map[string]string) (ResultadoAnalisis, string) {
+	t.Helper()
+
+	resultado, salida, err := analizarCorpusDestinosPrueba(t, parametros, archivos, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resultado, salida
+}
+
+/*
+ * Función para analizar un corpus temporal sin conservar lo que se imprime en la consola
+ * param: prueba, parámetros de ejecución y contenido de cada archivo por su ruta relativa
+ * return: el resultado del análisis
+ */
+func analizarCorpusPrueba(t *testing.T, parametros Parametros, archivos map[string]string) ResultadoAnalisis {
+	t.Helper()
+
+	resultado, _ := analizarCorpusSalidaPrueba(t, parametros, archivos)
+
+	return resultado
+}
+
+/*
+ * Función para buscar un archivo del resultado por su nombre
+ * param: prueba, resultado del análisis y nombre del archivo en los reportes
+ * return: índice del archivo en el resultado
+ */
+func buscarArchivoPrueba(t *testing.T, resultado ResultadoAnalisis, nombre string) int {
+	t.Helper()
+
+	for i, archivo := range resultado.archivos {
+		if archivo.nombre == nombre {
+			return i
+		}
+	}
+	t.Fatalf("el archivo %s no está en el resultado", nombre)
+
+	return -1
+}
+
+// Par de archivos con una función común (líneas 5-11 del primero y 8-14 del segundo) y el resto distinto
+var archivosFragmentoPrueba = [2]string{
+	"package main\n" +
+		"\n" +
+		"import \"fmt\"\n" +
+		"\n" +
+		"func sumar(valores []int) int {\n" +
+		"\ttotal := 0\n" +
+		"\tfor _, valor := range valores {\n" +
+		"\t\ttotal += valor\n" +
+		"\t}\n" +
+		"\treturn total\n" +
+		"}\n" +
+		"\n" +
+		"func main() {\n" +
+		"\tfmt.Println(sumar([]int{1, 2, 3}))\n" +
+		"}\n",
+	"package main\n" +
+		"\n" +
+		"// Suma de los datos de la entrega\n" +
+		"\n" +
+		"const base = 2\n" +
+		"\n" +
+		"var limite = 10\n" +
+		"func sumar(valores []int) int {\n" +
+		"\ttotal := 0\n" +
+		"\tfor _, valor := range valores {\n" +
+		"\t\ttotal += valor\n" +
+		"\t}\n" +
+		"\treturn total\n" +
+		"}\n" +
+		"\n" +
+		"type Dato struct{ valor int }\n",
+}
+
+/*
+ * Función para comparar con un motor de evidencia el par de archivos con una función común
+ * param: prueba, motor de evidencia y longitud mínima de un fragmento (en tokens)
+ * return: la comparación del par y los rangos de líneas de cada fragmento reportado (inicio y fin en ambos archivos)
+ */
+func compararFragmentoPrueba(t *testing.T, motorEvidencia string, minimoTokens int) (ParFragmentos, [][4]int) {
+	t.Helper()
+
+	tablaCodigoFuente := make([]CodigoFuente, len(archivosFragmentoPrueba))
+	for i, contenido := range archivosFragmentoPrueba {
+		tablaCodigoFuente[i] = CodigoFuente{nombre: fmt.Sprintf("archivo%d.go", i+1), contenido: []byte(contenido), tablaDistancias: make([]Distancia, len(archivosFragmentoPrueba))}
+	}
+
+	pares := compararParesPorFragmentos(tablaCodigoFuente, 0, motorEvidencia, minimoTokens, 1, VENTANA_WINNOWING, PresupuestoEvidencia{})
+	if len(pares) != 1 || pares[0].sinEvidencia {
+		t.Fatalf("%s: pares comparados = %+v, se esperaba uno con evidencia", motorEvidencia, pares)
+	}
+
+	var rangos [][4]int
+	tokens1, tokens2 := tablaCodigoFuente[0].tokens, tablaCodigoFuente[1].tokens
+	for _, fragmento := range pares[0].resultado.fragmentos {
+		rangos = append(rangos, [4]int{
+			tokens1[fragmento.inicio1].numero, tokens1[fragmento.inicio1+fragmento.longitud-1].numero,
+			tokens2[fragmento.inicio2].numero, tokens2[fragmento.inicio2+fragmento.longitud-1].numero,
+		})
+	}
+
+	return pares[0], rangos
+}
@@ -0,0 +1,223 @@
+/*
+ * Puntaje por estudiante de las entregas de varios archivos (--student-score y --file-weight).
+ *
+ * Cuando cada estudiante entrega varios archivos, la menor distancia entre dos archivos no representa la entrega
+ * completa: dos estudiantes con la misma clase auxiliar de 5 líneas quedan tan cerca como dos con la misma solución.
+ * Con --student-score se calcula el puntaje de cada par de estudiantes (subdirectorios inmediatos del directorio base)
+ * como el promedio ponderado, sobre los archivos de ambos, de la distancia de cada archivo al archivo más cercano del
+ * otro estudiante. El peso de cada archivo es:
+ * - "size": la cantidad de líneas no vacías después del preprocesamiento (sin encabezado, comentarios ni código
+ *   base si se eliminan), así los archivos pequeños pesan poco
+ * - "equal": el mismo peso para todos los archivos
+ * multiplicado por la importancia indicada por el docente con --file-weight="patrón=peso" (se puede repetir, por
+ * ejemplo "Main.java=3" para la solución principal y "util/**=0.5" para los auxiliares). Los patrones se comparan con
+ * la ruta dentro del directorio del estudiante como en --subassignment, se usa el primero que coincide y los archivos
+ * sin patrón tienen importancia 1; con importancia 0 el archivo no cuenta en el puntaje. El puntaje está en la escala
+ * de las distancias del motor (menor es más parecido) y se imprime para cada estudiante con su estudiante más cercano.
+ *
+ * Solo cuentan las distancias calculadas: con --pairs=lsh, un archivo sin ningún par calculado con los archivos del otro
+ * estudiante no aporta peso al puntaje del par, y un estudiante sin ningún par calculado con otro estudiante se
+ * imprime "sin datos" en lugar de un puntaje. Con --dedup, las copias exactas cuentan como archivos de sus estudiantes
+ * con distancia 0 entre ellas.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Pesos de los archivos del puntaje por estudiante
+const (
+	PUNTAJE_TAMANO = "size"
+	PUNTAJE_IGUAL  = "equal"
+)
+
+// Estructura de la importancia de los archivos que coinciden con un patrón
+// - patrón de la ruta dentro del directorio del estudiante (en minúsculas)
+// - importancia (multiplica el peso del archivo)
+type PesoArchivo struct {
+	patron      string
+	importancia float64
+}
+
+// Estructura del puntaje de un estudiante
+// - directorio del estudiante
+// - cantidad de archivos con peso y suma de sus pesos
+// - directorio del estudiante más cercano ("" si no hay otro estudiante con distancias calculadas) y puntaje del par
+type PuntajeEstudiante struct {
+	estudiante string
+	archivos   int
+	peso       float64
+	cercano    string
+	puntaje    float64
+}
+
+/*
+ * Función para obtener la importancia de los archivos indicada por el docente
+ * param: definiciones "patrón=peso"
+ * return: la importancia de cada patrón en el orden de las definiciones o un error si una definición no es válida
+ */
+func obtenerPesosArchivos(definiciones []string) ([]PesoArchivo, error) {
+	var pesos []PesoArchivo
+
+	for _, definicion := range definiciones {
+		separador := strings.LastIndex(definicion, "=")
+		if separador < 0 {
+			return nil, fmt.Errorf("Peso de archivo (--file-weight) \"%s\" no válido, debe ser \"patrón=peso\"", definicion)
+		}
+
+		patron := strings.ToLower(strings.Trim(strings.TrimSpace(definicion[:separador]), "/"))
+		if _, err := path.Match(patron, ""); err != nil || patron == "" {
+			return nil, fmt.Errorf("Patrón \"%s\" del peso de archivo (--file-weight) no válido", patron)
+		}
+
+		importancia, err := strconv.ParseFloat(strings.TrimSpace(definicion[separador+1:]), 64)
+		if err != nil || importancia < 0 || math.IsInf(importancia, 0) || math.IsNaN(importancia) {
+			return nil, fmt.Errorf("Peso \"%s\" del patrón \"%s\" (--file-weight) no válido, debe ser un número mayor o igual a cero", definicion[separador+1:], patron)
+		}
+
+		pesos = append(pesos, PesoArchivo{patron: patron, importancia: importancia})
+	}
+
+	return pesos, nil
+}
+
+/*
+ * Función para calcular el peso de un archivo en el puntaje por estudiante
+ * param: código fuente del archivo, peso de los archivos ("size" o "equal") e importancia por patrón
+ * return: peso del archivo (0 si no cuenta en el puntaje)
+ */
+func calcularPesoArchivo(codigoFuente CodigoFuente, modo string, pesos []PesoArchivo) float64 {
+	peso := 1.0
+	if modo == PUNTAJE_TAMANO {
+		peso = 0
+		for _, linea := range strings.Split(string(codigoFuente.contenido), "\n") {
+			if strings.TrimSpace(linea) != "" {
+				peso++
+			}
+		}
+	}
+
+	segmentos := obtenerSegmentosEstudiante(codigoFuente.ruta)
+	for _, pesoArchivo := range pesos {
+		if coincidirSegmentos(strings.Split(pesoArchivo.patron, "/"), segmentos) {
+			return peso * pesoArchivo.importancia
+		}
+	}
+
+	return peso
+}
+
+/*
+ * Función para calcular el puntaje de cada estudiante con su estudiante más cercano
+ * param: resultado del análisis, peso de los archivos e importancia por patrón
+ * return: puntaje de cada estudiante, de menor a mayor puntaje (los estudiantes sin estudiante cercano al final)
+ */
+func calcularPuntajesEstudiantes(resultado ResultadoAnalisis, modo string, pesos []PesoArchivo) []PuntajeEstudiante {
+	var estudiantes []string
+	var archivos [][]int
+	posiciones := make(map[string]int)
+	pesosArchivos := make([]float64, len(resultado.archivos))
+
+	// Los archivos del directorio base y los que no cuentan en el puntaje no pertenecen a ningún estudiante
+	for i, codigoFuente := range resultado.archivos {
+		estudiante := obtenerEstudiante(".", codigoFuente.ruta)
+		pesosArchivos[i] = calcularPesoArchivo(codigoFuente, modo, pesos)
+		if estudiante == "." || pesosArchivos[i] <= 0 {
+			continue
+		}
+
+		if _, existe := posiciones[estudiante]; !existe {
+			posiciones[estudiante] = len(estudiantes)
+			estudiantes = append(estudiantes, estudiante)
+			archivos = append(archivos, nil)
+		}
+		archivos[posiciones[estudiante]] = append(archivos[posiciones[estudiante]], i)
+	}
+
+	// Suma ponderada de las distancias de los archivos de un estudiante a los de otro, y la suma de sus pesos
+	acumular := func(origen []int, destino []int) (float64, float64) {
+		suma, peso := 0.0, 0.0
+		for _, i := range origen {
+			menor := math.Inf(1)
+			for _, j := range destino {
+				if distancia := resultado.matriz[i][j]; esDistanciaCalculada(distancia) && distancia < menor {
+					menor = distancia
+				}
+			}
+			if !math.IsInf(menor, 1) {
+				suma += pesosArchivos[i] * menor
+				peso += pesosArchivos[i]
+			}
+		}
+		return suma, peso
+	}
+
+	puntajes := make([]PuntajeEstudiante, len(estudiantes))
+	for a, estudiante := range estudiantes {
+		puntajes[a] = PuntajeEstudiante{estudiante: estudiante, archivos: len(archivos[a]), puntaje: math.NaN()}
+		for _, i := range archivos[a] {
+			puntajes[a].peso += pesosArchivos[i]
+		}
+	}
+
+	for a := range estudiantes {
+		for b := 0; b < a; b++ {
+			sumaA, pesoA := acumular(archivos[a], archivos[b])
+			sumaB, pesoB := acumular(archivos[b], archivos[a])
+			if pesoA+pesoB == 0 {
+				continue
+			}
+
+			puntaje := (sumaA + sumaB) / (pesoA + pesoB)
+			for _, par := range [][2]int{{a, b}, {b, a}} {
+				if actual := &puntajes[par[0]]; math.IsNaN(actual.puntaje) || puntaje < actual.puntaje {
+					actual.puntaje = puntaje
+					actual.cercano = estudiantes[par[1]]
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(puntajes, func(i, j int) bool {
+		if math.IsNaN(puntajes[j].puntaje) {
+			return !math.IsNaN(puntajes[i].puntaje)
+		}
+		return puntajes[i].puntaje < puntajes[j].puntaje
+	})
+
+	return puntajes
+}
+
+/*
+ * Función para imprimir el puntaje por estudiante
+ * param: resultado del análisis, peso de los archivos e importancia por patrón
+ */
+func imprimirPuntajesEstudiantes(resultado ResultadoAnalisis, modo string, pesos []PesoArchivo) {
+	fmt.Printf("\nPUNTAJE POR ESTUDIANTE (PESO POR ARCHIVO \"%s\", DE MENOR A MAYOR PUNTAJE CON OTRO ESTUDIANTE)\n\n", modo)
+	fmt.Printf("%-20s %8s %10s  %-20s %10s\n", "ESTUDIANTE", "ARCHIVOS", "PESO", "MÁS CERCANO", "PUNTAJE")
+
+	for _, puntaje := range calcularPuntajesEstudiantes(resultado, modo, pesos) {
+		cercano, valor := "-", "sin datos"
+		if puntaje.cercano != "" {
+			cercano = puntaje.cercano
+			valor = fmt.Sprintf("%.2f", puntaje.puntaje)
+			if resultado.tieneDistanciaMaxima() && puntaje.puntaje <= resultado.criterio.limite() {
+				valor += " *"
+			}
+		}
+
+		fmt.Printf("%-20s %8d %10.2f  %-20s %10s\n", puntaje.estudiante, puntaje.archivos, puntaje.peso, cercano, valor)
+	}
+
+	if resultado.tieneDistanciaMaxima() {
+		fmt.Println(" (*) Puntaje a la distancia máxima")
+	}
+	fmt.Println()
+}
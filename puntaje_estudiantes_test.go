@@ -0,0 +1,167 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestObtenerPesosArchivos(t *testing.T) {
+	casos := []struct {
+		definiciones []string
+		esperado     []PesoArchivo
+		valido       bool
+	}{
+		{nil, nil, true},
+		{[]string{"Main.java=3", " util/** = 0.5 "}, []PesoArchivo{{"main.java", 3}, {"util/**", 0.5}}, true},
+		{[]string{"Main.java"}, nil, false},
+		{[]string{"=2"}, nil, false},
+		{[]string{"Main.java=-1"}, nil, false},
+		{[]string{"Main.java=NaN"}, nil, false},
+		{[]string{"[=1"}, nil, false},
+	}
+
+	for _, caso := range casos {
+		pesos, err := obtenerPesosArchivos(caso.definiciones)
+		if (err == nil) != caso.valido {
+			t.Errorf("obtenerPesosArchivos(%q) error = %v", caso.definiciones, err)
+			continue
+		}
+		if caso.valido && !reflect.DeepEqual(pesos, caso.esperado) {
+			t.Errorf("obtenerPesosArchivos(%q) = %v, se esperaba %v", caso.definiciones, pesos, caso.esperado)
+		}
+	}
+}
+
+func TestCalcularPesoArchivo(t *testing.T) {
+	codigoFuente := CodigoFuente{ruta: "./ana/util/Lista.java", contenido: []byte("a\n\n  \nb\nc\n")}
+	casos := []struct {
+		modo     string
+		pesos    []PesoArchivo
+		esperado float64
+	}{
+		{PUNTAJE_TAMANO, nil, 3},
+		{PUNTAJE_IGUAL, nil, 1},
+		{PUNTAJE_TAMANO, []PesoArchivo{{"util/**", 0.5}}, 1.5},
+		{PUNTAJE_IGUAL, []PesoArchivo{{"main.java", 3}, {"util/*", 0}}, 0},
+	}
+
+	for _, caso := range casos {
+		if peso := calcularPesoArchivo(codigoFuente, caso.modo, caso.pesos); peso != caso.esperado {
+			t.Errorf("calcularPesoArchivo(%s, %v) = %v, se esperaba %v", caso.modo, caso.pesos, peso, caso.esperado)
+		}
+	}
+}
+
+/*
+ * Función para crear el resultado de un análisis con las distancias indicadas
+ * param: rutas de los archivos y matriz de distancias
+ * return: el resultado del análisis
+ */
+func crearResultadoPuntajesPrueba(rutas []string, matriz [][]float64) ResultadoAnalisis {
+	resultado := ResultadoAnalisis{matriz: matriz}
+	for _, ruta := range rutas {
+		resultado.archivos = append(resultado.archivos, CodigoFuente{ruta: ruta, nombre: ruta, contenido: []byte("x\n")})
+	}
+
+	return resultado
+}
+
+func TestCalcularPuntajesEstudiantes(t *testing.T) {
+	n := DISTANCIA_NO_CALCULADA
+	resultado := crearResultadoPuntajesPrueba(
+		[]string{"./ana/a.go", "./ana/b.go", "./luis/a.go", "./eva/a.go"},
+		[][]float64{
+			{0, 5, 2, n},
+			{5, 0, 6, n},
+			{2, 6, 0, n},
+			{n, n, n, 0},
+		})
+
+	puntajes := calcularPuntajesEstudiantes(resultado, PUNTAJE_IGUAL, nil)
+
+	if len(puntajes) != 3 {
+		t.Fatalf("puntajes = %v, se esperaban 3 estudiantes", puntajes)
+	}
+	// ana-luis: a.go a 2, b.go a 6 y el archivo de luis a 2: (2 + 6 + 2) / 3
+	for _, puntaje := range puntajes[:2] {
+		if math.Abs(puntaje.puntaje-10.0/3) > 1e-9 {
+			t.Errorf("puntaje de %s = %v, se esperaba %v", puntaje.estudiante, puntaje.puntaje, 10.0/3)
+		}
+	}
+	// eva no tiene distancias calculadas con nadie
+	if puntajes[2].estudiante != "eva" || puntajes[2].cercano != "" || !math.IsNaN(puntajes[2].puntaje) {
+		t.Errorf("puntaje de eva = %+v, se esperaba sin datos", puntajes[2])
+	}
+}
+
+func TestCalcularPuntajesEstudiantesParesParciales(t *testing.T) {
+	n := DISTANCIA_NO_CALCULADA
+	resultado := crearResultadoPuntajesPrueba(
+		[]string{"./ana/a.go", "./ana/b.go", "./luis/a.go"},
+		[][]float64{
+			{0, 5, 4},
+			{5, 0, n},
+			{4, n, 0},
+		})
+
+	puntajes := calcularPuntajesEstudiantes(resultado, PUNTAJE_IGUAL, nil)
+
+	// b.go no tiene ningún par calculado con luis, por lo que no aporta peso: (4 + 4) / 2
+	for _, puntaje := range puntajes {
+		if puntaje.puntaje != 4 {
+			t.Errorf("puntaje de %s = %v, se esperaba 4", puntaje.estudiante, puntaje.puntaje)
+		}
+	}
+}
+
+func TestPuntajesEstudiantesConDeduplicar(t *testing.T) {
+	parametros := crearParametrosPrueba()
+	parametros.deduplicar = true
+
+	resultado := analizarCorpusPrueba(t, parametros, corpusIdenticos)
+	puntajes := calcularPuntajesEstudiantes(resultado, PUNTAJE_TAMANO, nil)
+
+	if len(puntajes) != 3 {
+		t.Fatalf("puntajes = %v, se esperaban 3 estudiantes (con las copias)", puntajes)
+	}
+	for _, puntaje := range puntajes[:2] {
+		if puntaje.puntaje != 0 || (puntaje.estudiante != "a" && puntaje.estudiante != "c") {
+			t.Errorf("puntaje = %+v, se esperaba 0 entre las copias a y c", puntaje)
+		}
+	}
+}
+
+func TestPuntajesEstudiantesConLSH(t *testing.T) {
+	archivos := crearCorpusFamiliasPrueba(2, 2)
+	archivos["solo/main.go"] = "package main\n\nimport \"os\"\n\nfunc main() {\n\tos.Exit(len(os.Args) - 1)\n}\n"
+
+	parametros := crearParametrosPrueba()
+	parametros.modoPares = PARES_LSH
+	parametros.criterioDistancia = crearCriterioValor(10)
+	parametros.puntajeEstudiantes = PUNTAJE_IGUAL
+
+	resultado, salida := analizarCorpusSalidaPrueba(t, parametros, archivos)
+	puntajes := calcularPuntajesEstudiantes(resultado, PUNTAJE_IGUAL, nil)
+
+	ultimo := puntajes[len(puntajes)-1]
+	if ultimo.estudiante != "solo" || ultimo.cercano != "" || !math.IsNaN(ultimo.puntaje) {
+		t.Errorf("puntaje del estudiante sin pares calculados = %+v, se esperaba sin datos", ultimo)
+	}
+	for _, puntaje := range puntajes[:len(puntajes)-1] {
+		if puntaje.puntaje > 10 || puntaje.cercano == "" {
+			t.Errorf("puntaje de %s = %v con %q, se esperaba su variante", puntaje.estudiante, puntaje.puntaje, puntaje.cercano)
+		}
+	}
+
+	impreso := false
+	for _, linea := range strings.Split(salida, "\n") {
+		if strings.HasPrefix(linea, "solo ") {
+			impreso = strings.HasSuffix(strings.TrimSpace(linea), "sin datos")
+		}
+	}
+	if !impreso {
+		t.Error("el estudiante sin pares calculados no se imprime \"sin datos\"")
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ * Ocultamiento de los datos personales de los comentarios en los reportes (--redact).
+ *
+ * Los comentarios de las entregas suelen incluir el correo, el código (número de identificación) y el nombre del
+ * estudiante. Los reportes que muestran el código fuente, la vista previa de --show-source y las páginas de los pares
+ * del paquete de --bundle, se comparten con monitores y otros docentes, por lo que estos datos se reemplazan por
+ * [correo], [codigo] y [nombre]:
+ * - correos: cualquier dirección de correo electrónico
+ * - códigos: los números que coinciden con --student-id-pattern (por defecto de 7 a 12 dígitos)
+ * - nombres: las palabras con mayúscula inicial después de una etiqueta como "@author", "Autor:", "Nombre:",
+ *   "Estudiante:", "Integrantes:" o "Elaborado por", incluidas las listas ("Ana Pérez, Juan de la Cruz y Luis Díaz")
+ * Solo se modifica el texto de los comentarios, nunca el código, y el análisis usa siempre el contenido completo.
+ * --redact indica las categorías a ocultar (por defecto "email,id,name", "none" no oculta nada) y con
+ * --bundle-keep-pii el paquete de --bundle conserva los datos, cuando es el paquete confidencial de evidencia que se
+ * entrega al comité de integridad académica.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Categorías de datos personales que se pueden ocultar
+const (
+	REDACCION_CORREO         = "email"
+	REDACCION_IDENTIFICACION = "id"
+	REDACCION_NOMBRE         = "name"
+)
+
+// Expresión regular de un código de estudiante por defecto
+const PATRON_IDENTIFICACION = `\b[0-9]{7,12}\b`
+
+// Expresión regular de una dirección de correo electrónico
+var expresionCorreo = regexp.MustCompile(`[\p{L}0-9._%+-]+@[\p{L}0-9-]+(?:\.[\p{L}0-9-]+)*\.\p{L}{2,}`)
+
+// Expresión regular de los nombres después de una etiqueta (el grupo 1 es la lista de nombres)
+var expresionNombre = func() *regexp.Regexp {
+	nombre := `\p{Lu}[\p{L}'.-]*(?:[ \t]+(?:(?:de|del|la|las|los|van|von|da|di)[ \t]+)*\p{Lu}[\p{L}'.-]*)*`
+	etiqueta := `(?:@author[ \t]*:?|\b(?i:autor(?:a|es)?|authors?|nombres?|names?|estudiantes?|students?|alumnos?|integrantes?)[ \t]*[:=]|` +
+		`\b(?i:(?:hecho|elaborado|desarrollado|realizado|escrito) por|(?:made|created|written|developed) by)[ \t]*:?)`
+
+	return regexp.MustCompile(etiqueta + `[ \t]*(` + nombre + `(?:[ \t]*(?:,|;|&|\by\b|\band\b)[ \t]*` + nombre + `)*)`)
+}()
+
+// Estructura del ocultamiento de los datos personales
+// - sintaxis de los comentarios del lenguaje
+// - si se ocultan los correos y los nombres
+// - expresión regular de los códigos de estudiante (nil si no se ocultan)
+type Redaccion struct {
+	sintaxis                SintaxisComentarios
+	correos                 bool
+	nombres                 bool
+	expresionIdentificacion *regexp.Regexp
+}
+
+/*
+ * Función para crear el ocultamiento de los datos personales indicado por el usuario
+ * param: parámetros de ejecución
+ * return: el ocultamiento (nil si no se oculta nada) o un error si una categoría o la expresión regular no es válida
+ */
+func crearRedaccion(parametros Parametros) (*Redaccion, error) {
+	if parametros.redaccion == "none" {
+		return nil, nil
+	}
+
+	redaccion := Redaccion{sintaxis: obtenerSintaxisComentarios(parametros.extension)}
+
+	for _, categoria := range strings.Split(parametros.redaccion, ",") {
+		switch strings.TrimSpace(categoria) {
+		case REDACCION_CORREO:
+			redaccion.correos = true
+		case REDACCION_NOMBRE:
+			redaccion.nombres = true
+		case REDACCION_IDENTIFICACION:
+			expresion, err := regexp.Compile(parametros.patronIdentificacion)
+			if err != nil {
+				return nil, fmt.Errorf("Expresión regular del código de estudiante (--student-id-pattern) no válida: %v", err)
+			}
+			redaccion.expresionIdentificacion = expresion
+		default:
+			return nil, fmt.Errorf("Categoría de datos personales \"%s\" no soportada en --redact (email | id | name | none)", categoria)
+		}
+	}
+
+	return &redaccion, nil
+}
+
+/*
+ * Función para ocultar los datos personales de un comentario
+ * param: texto del comentario
+ * return: el comentario con los datos reemplazados
+ */
+func (redaccion *Redaccion) redactarComentario(comentario string) string {
+	if redaccion.correos {
+		comentario = expresionCorreo.ReplaceAllString(comentario, "[correo]")
+	}
+
+	if redaccion.nombres {
+		var resultado strings.Builder
+		anterior := 0
+		for _, posiciones := range expresionNombre.FindAllStringSubmatchIndex(comentario, -1) {
+			resultado.WriteString(comentario[anterior:posiciones[2]])
+			resultado.WriteString("[nombre]")
+			anterior = posiciones[3]
+		}
+		resultado.WriteString(comentario[anterior:])
+		comentario = resultado.String()
+	}
+
+	if redaccion.expresionIdentificacion != nil {
+		comentario = redaccion.expresionIdentificacion.ReplaceAllLiteralString(comentario, "[codigo]")
+	}
+
+	return comentario
+}
+
+/*
+ * Función para ocultar los datos personales de los comentarios de un archivo
+ * param: líneas del archivo
+ * return: las mismas líneas con los datos de sus comentarios reemplazados (sin cambios si no se oculta nada)
+ */
+func (redaccion *Redaccion) redactarLineas(lineas []string) []string {
+	if redaccion == nil {
+		return lineas
+	}
+
+	contenido := strings.Join(lineas, "\n")
+
+	var resultado strings.Builder
+	anterior := 0
+	for _, comentario := range buscarComentarios([]byte(contenido), redaccion.sintaxis) {
+		resultado.WriteString(contenido[anterior:comentario[0]])
+		resultado.WriteString(redaccion.redactarComentario(contenido[comentario[0]:comentario[1]]))
+		anterior = comentario[1]
+	}
+	resultado.WriteString(contenido[anterior:])
+
+	return strings.Split(resultado.String(), "\n")
+}
@@ -0,0 +1,267 @@
+/*
+ * Reducción de dimensiones de las características (--reduce).
+ *
+ * Los motores con vocabularios muy grandes (por ejemplo n-gramas de tokens) pueden proyectar las características
+ * de todo el corpus a pocas dimensiones antes de calcular las distancias, de modo que cada distancia cuesta
+ * lo mismo sin importar el tamaño del vocabulario:
+ * - "pca:K": análisis de componentes principales con K componentes. Los componentes se calculan con una
+ *   muestra de hasta MAXIMO_MUESTRA_PCA archivos (con la matriz de Gram de la muestra, por lo que nunca se
+ *   construye la matriz de covarianza del vocabulario) y luego se proyectan todos los archivos.
+ *
+ * La proyección empleada (método, dimensiones originales y finales, varianza explicada) se reporta en la
+ * consola y en el resultado JSON, al igual que el hashing de los n-gramas (--hash-dims, ver motor_tokens.go).
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Cantidad máxima de archivos de la muestra con la que se calculan los componentes principales
+const MAXIMO_MUESTRA_PCA = 200
+
+// Cantidad máxima de iteraciones del método de la potencia para cada componente principal
+const MAXIMO_ITERACIONES_PCA = 500
+
+// Interfaz opcional de los motores que proyectan las características con todo el corpus antes de calcular las distancias
+type MotorCorpus interface {
+	proyectar(tablaCodigoFuente []CodigoFuente) *Proyeccion
+}
+
+// Estructura con la información de la proyección empleada (metadatos del análisis)
+type Proyeccion struct {
+	Metodo                string  `json:"metodo"`
+	DimensionesOriginales int     `json:"dimensiones_originales,omitempty"`
+	Dimensiones           int     `json:"dimensiones"`
+	VarianzaExplicada     float64 `json:"varianza_explicada,omitempty"`
+	Muestra               int     `json:"muestra,omitempty"`
+}
+
+/*
+ * Función para obtener la cantidad de componentes principales de la reducción de dimensiones
+ * param: reducción indicada por el usuario ("" o "pca:K")
+ * return: cantidad de componentes (0 si no se reducen las dimensiones) o un error si la reducción no es válida
+ */
+func obtenerComponentesReduccion(reduccion string) (int, error) {
+	if reduccion == "" {
+		return 0, nil
+	}
+
+	partes := strings.SplitN(reduccion, ":", 2)
+	if len(partes) != 2 || partes[0] != "pca" {
+		return 0, fmt.Errorf("Reducción de dimensiones \"%s\" no soportada (pca:K)", reduccion)
+	}
+
+	componentes, err := strconv.Atoi(partes[1])
+	if err != nil || componentes < 1 {
+		return 0, fmt.Errorf("La cantidad de componentes de la reducción \"%s\" debe ser un entero mayor a 0", reduccion)
+	}
+
+	return componentes, nil
+}
+
+/*
+ * Función para proyectar las características del corpus si el motor lo requiere
+ * param: arreglo con la información del código fuente de los archivos y el motor a emplear
+ * return: la proyección empleada (nil si el motor no proyecta las características)
+ */
+func proyectarCorpus(tablaCodigoFuente []CodigoFuente, motor Motor) *Proyeccion {
+	if motorCorpus, ok := motor.(MotorCorpus); ok {
+		return motorCorpus.proyectar(tablaCodigoFuente)
+	}
+
+	return nil
+}
+
+/*
+ * Función para describir una proyección en la consola
+ * param: proyección empleada
+ * return: descripción (por ejemplo "PCA de 5320 a 20 dimensiones, varianza explicada 91.2% con una muestra de 200 archivos")
+ */
+func describirProyeccion(proyeccion *Proyeccion) string {
+	if proyeccion.DimensionesOriginales == 0 {
+		return fmt.Sprintf("%s a %d dimensiones", proyeccion.Metodo, proyeccion.Dimensiones)
+	}
+
+	descripcion := fmt.Sprintf("%s de %d a %d dimensiones", proyeccion.Metodo, proyeccion.DimensionesOriginales, proyeccion.Dimensiones)
+
+	if proyeccion.Muestra > 0 {
+		descripcion += fmt.Sprintf(", varianza explicada %.1f%% con una muestra de %d archivos", proyeccion.VarianzaExplicada, proyeccion.Muestra)
+	}
+
+	return descripcion
+}
+
+/*
+ * Función para seleccionar la muestra de archivos de la PCA, distribuida uniformemente en el corpus
+ * param: cantidad de archivos del corpus
+ * return: índices de los archivos de la muestra
+ */
+func seleccionarMuestraPCA(cantidadArchivos int) []int {
+	tamano := cantidadArchivos
+	if tamano > MAXIMO_MUESTRA_PCA {
+		tamano = MAXIMO_MUESTRA_PCA
+	}
+
+	muestra := make([]int, tamano)
+	for i := range muestra {
+		muestra[i] = i * cantidadArchivos / tamano
+	}
+
+	return muestra
+}
+
+/*
+ * Función para ortogonalizar un vector respecto a vectores unitarios y normalizarlo
+ * param: vector (se modifica) y vectores unitarios
+ * return: norma del vector antes de normalizarlo (0 si el vector es nulo)
+ */
+func ortonormalizar(vector []float64, unitarios [][]float64) float64 {
+	for _, unitario := range unitarios {
+		producto := 0.0
+		for i := range vector {
+			producto += vector[i] * unitario[i]
+		}
+		for i := range vector {
+			vector[i] -= producto * unitario[i]
+		}
+	}
+
+	norma := 0.0
+	for _, componente := range vector {
+		norma += componente * componente
+	}
+	norma = math.Sqrt(norma)
+
+	if norma > 0 {
+		for i := range vector {
+			vector[i] /= norma
+		}
+	}
+
+	return norma
+}
+
+/*
+ * Función para calcular el vector propio dominante de una matriz simétrica (método de la potencia),
+ * ortogonal a los vectores propios ya calculados
+ * param: matriz simétrica y vectores propios anteriores (unitarios)
+ * return: valor propio y vector propio unitario
+ */
+func calcularVectorPropio(matriz [][]float64, anteriores [][]float64) (float64, []float64) {
+	vector := make([]float64, len(matriz))
+	for i := range vector {
+		vector[i] = 1.0 + float64(i%7)/7.0 // Vector inicial determinista y no simétrico
+	}
+
+	valor := 0.0
+	for iteracion := 0; iteracion < MAXIMO_ITERACIONES_PCA; iteracion++ {
+		if ortonormalizar(vector, anteriores) == 0 {
+			return 0, vector
+		}
+
+		siguiente := make([]float64, len(matriz))
+		nuevoValor := 0.0
+		for i := range matriz {
+			for j, componente := range vector {
+				siguiente[i] += matriz[i][j] * componente
+			}
+			nuevoValor += siguiente[i] * vector[i]
+		}
+
+		convergio := math.Abs(nuevoValor-valor) <= 1e-10*math.Abs(nuevoValor)
+		valor, vector = nuevoValor, siguiente
+		if convergio {
+			break
+		}
+	}
+	ortonormalizar(vector, anteriores)
+
+	return valor, vector
+}
+
+/*
+ * Función para proyectar los n-gramas de todos los archivos en sus componentes principales.
+ * La proyección de un archivo x en el componente k es sum_s u_k[s] * (x - media)·(x_s - media) / sqrt(lambda_k),
+ * donde x_s son los archivos de la muestra y (lambda_k, u_k) los valores y vectores propios de su matriz de Gram.
+ * param: arreglo con la información del código fuente de los archivos y la cantidad de componentes
+ * return: la proyección empleada (completa la proyección de cada archivo)
+ */
+func proyectarPCA(tablaCodigoFuente []CodigoFuente, componentes int) *Proyeccion {
+	vocabulario := make(map[uint64]bool)
+	for _, archivo := range tablaCodigoFuente {
+		for clave := range archivo.ngramas {
+			vocabulario[clave] = true
+		}
+	}
+
+	muestra := seleccionarMuestraPCA(len(tablaCodigoFuente))
+
+	media := make(map[uint64]float64)
+	for _, indice := range muestra {
+		for clave, valor := range tablaCodigoFuente[indice].ngramas {
+			media[clave] += valor / float64(len(muestra))
+		}
+	}
+	normaMedia := calcularProductoDisperso(media, media)
+
+	// Producto punto centrado de un archivo con cada archivo de la muestra
+	productosMedia := make([]float64, len(muestra))
+	for s, indice := range muestra {
+		productosMedia[s] = calcularProductoDisperso(tablaCodigoFuente[indice].ngramas, media)
+	}
+	productosCentrados := func(ngramas map[uint64]float64) []float64 {
+		productoMedia := calcularProductoDisperso(ngramas, media)
+		productos := make([]float64, len(muestra))
+		for s, indice := range muestra {
+			productos[s] = calcularProductoDisperso(ngramas, tablaCodigoFuente[indice].ngramas) - productoMedia - productosMedia[s] + normaMedia
+		}
+		return productos
+	}
+
+	gram := make([][]float64, len(muestra))
+	varianzaTotal := 0.0
+	for s, indice := range muestra {
+		gram[s] = productosCentrados(tablaCodigoFuente[indice].ngramas)
+		varianzaTotal += gram[s][s]
+	}
+
+	var valores []float64
+	var vectores [][]float64
+	for k := 0; k < componentes && k < len(muestra); k++ {
+		valor, vector := calcularVectorPropio(gram, vectores)
+		if valor <= 1e-9*varianzaTotal {
+			break // El resto de la varianza es nula
+		}
+		valores = append(valores, valor)
+		vectores = append(vectores, vector)
+	}
+
+	varianzaExplicada := 0.0
+	for _, valor := range valores {
+		varianzaExplicada += valor
+	}
+
+	for i := range tablaCodigoFuente {
+		productos := productosCentrados(tablaCodigoFuente[i].ngramas)
+
+		tablaCodigoFuente[i].proyeccion = make([]float64, len(valores))
+		for k, valor := range valores {
+			for s := range muestra {
+				tablaCodigoFuente[i].proyeccion[k] += vectores[k][s] * productos[s]
+			}
+			tablaCodigoFuente[i].proyeccion[k] /= math.Sqrt(valor)
+		}
+	}
+
+	proyeccion := &Proyeccion{Metodo: "PCA", DimensionesOriginales: len(vocabulario), Dimensiones: len(valores), Muestra: len(muestra), VarianzaExplicada: 100.0}
+	if varianzaTotal > 0 {
+		proyeccion.VarianzaExplicada = 100.0 * varianzaExplicada / varianzaTotal
+	}
+
+	return proyeccion
+}
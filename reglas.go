@@ -0,0 +1,582 @@
+/*
+ * Reglas personalizadas para marcar pares de archivos (--rule, se puede repetir).
+ *
+ * Cada regla tiene un nombre y una expresión booleana que se evalúa para cada par de archivos al generar los
+ * reportes, por ejemplo:
+ *
+ *     --rule="misma sección: similitud > 85 && componente(archivo1, 0) == componente(archivo2, 0) && diferencia_tamano < 10"
+ *
+ * Las expresiones usan la sintaxis de las expresiones de Go (se analizan con go/parser, sin dependencias):
+ * números, textos entre comillas, true y false, paréntesis, + - * / %, comparaciones, !, && y ||.
+ * Variables de cada par:
+ * - distancia, similitud (100 - distancia, útil con el motor "lines") y percentil de la distancia en el corpus
+ * - archivo1, archivo2, estudiante1, estudiante2 y mismo_estudiante
+ * - tamano1, tamano2 (bytes) y diferencia_tamano (porcentaje respecto al archivo más grande)
+ * Funciones: contiene(texto, subtexto), componente(ruta, n) (n-ésimo directorio, desde 0) y abs(número).
+ *
+ * Los tipos de toda la expresión se verifican al compilar la regla (también los del lado de un && o || que la
+ * evaluación en cortocircuito no alcanzaría con algunos pares), por lo que una regla con un error de tipos se
+ * rechaza antes del análisis.
+ */
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Estructura de una regla personalizada
+// - nombre de la regla
+// - texto y árbol de la expresión
+type ReglaPersonalizada struct {
+	nombre    string
+	texto     string
+	expresion ast.Expr
+}
+
+// Estructura de un par de archivos marcado por una regla (también su representación JSON)
+type ParReglaJSON struct {
+	Regla     string  `json:"regla"`
+	Archivo1  string  `json:"archivo1"`
+	Archivo2  string  `json:"archivo2"`
+	Distancia float64 `json:"distancia"`
+}
+
+// Tipos de los valores de las expresiones
+const (
+	TIPO_NUMERO   = "número"
+	TIPO_TEXTO    = "texto"
+	TIPO_BOOLEANO = "booleano"
+)
+
+// Tipos de las variables de un par, para verificar las expresiones al compilarlas
+var tiposVariablesRegla = map[string]string{
+	"distancia": TIPO_NUMERO, "similitud": TIPO_NUMERO, "percentil": TIPO_NUMERO,
+	"archivo1": TIPO_TEXTO, "archivo2": TIPO_TEXTO, "estudiante1": TIPO_TEXTO, "estudiante2": TIPO_TEXTO,
+	"mismo_estudiante": TIPO_BOOLEANO,
+	"tamano1":          TIPO_NUMERO, "tamano2": TIPO_NUMERO, "diferencia_tamano": TIPO_NUMERO,
+}
+
+/*
+ * Función para compilar las reglas personalizadas
+ * param: definiciones de las reglas ("nombre: expresión")
+ * return: las reglas o un error si alguna definición o expresión no es válida
+ */
+func compilarReglas(definiciones []string) ([]ReglaPersonalizada, error) {
+	var reglas []ReglaPersonalizada
+
+	for _, definicion := range definiciones {
+		partes := strings.SplitN(definicion, ":", 2)
+		if len(partes) != 2 || strings.TrimSpace(partes[0]) == "" {
+			return reglas, fmt.Errorf("La regla (--rule) \"%s\" debe tener la forma \"nombre: expresión\"", definicion)
+		}
+
+		regla := ReglaPersonalizada{nombre: strings.TrimSpace(partes[0]), texto: strings.TrimSpace(partes[1])}
+		for _, anterior := range reglas {
+			if anterior.nombre == regla.nombre {
+				return reglas, fmt.Errorf("La regla \"%s\" está repetida", regla.nombre)
+			}
+		}
+
+		expresion, err := parser.ParseExpr(regla.texto)
+		if err != nil {
+			return reglas, fmt.Errorf("Expresión de la regla \"%s\" no válida: %v", regla.nombre, err)
+		}
+		regla.expresion = expresion
+
+		tipo, err := verificarTipoExpresion(regla.expresion)
+		if err == nil && tipo != TIPO_BOOLEANO {
+			err = fmt.Errorf("el resultado no es verdadero o falso")
+		}
+		if err != nil {
+			return reglas, fmt.Errorf("Expresión de la regla \"%s\" no válida: %v", regla.nombre, err)
+		}
+
+		reglas = append(reglas, regla)
+	}
+
+	return reglas, nil
+}
+
+/*
+ * Función para verificar los tipos de una expresión sin evaluarla (se recorre todo el árbol)
+ * param: árbol de la expresión
+ * return: tipo del valor de la expresión o un error de tipos
+ */
+func verificarTipoExpresion(expresion ast.Expr) (string, error) {
+	switch nodo := expresion.(type) {
+	case *ast.BasicLit:
+		switch nodo.Kind {
+		case token.INT, token.FLOAT:
+			return TIPO_NUMERO, nil
+		case token.STRING:
+			return TIPO_TEXTO, nil
+		}
+		return "", fmt.Errorf("valor %s no soportado", nodo.Value)
+
+	case *ast.Ident:
+		if nodo.Name == "true" || nodo.Name == "false" {
+			return TIPO_BOOLEANO, nil
+		}
+		if tipo, existe := tiposVariablesRegla[nodo.Name]; existe {
+			return tipo, nil
+		}
+		return "", fmt.Errorf("variable \"%s\" no definida", nodo.Name)
+
+	case *ast.ParenExpr:
+		return verificarTipoExpresion(nodo.X)
+
+	case *ast.UnaryExpr:
+		tipo, err := verificarTipoExpresion(nodo.X)
+		if err != nil {
+			return "", err
+		}
+		if (tipo == TIPO_BOOLEANO && nodo.Op == token.NOT) || (tipo == TIPO_NUMERO && nodo.Op == token.SUB) {
+			return tipo, nil
+		}
+		return "", fmt.Errorf("operador %s no válido para un %s", nodo.Op, tipo)
+
+	case *ast.BinaryExpr:
+		return verificarTipoOperacion(nodo)
+
+	case *ast.CallExpr:
+		return verificarTipoFuncion(nodo)
+	}
+
+	return "", fmt.Errorf("expresión no soportada")
+}
+
+/*
+ * Función para verificar los tipos de una operación binaria (los dos lados, también con && y ||)
+ * param: nodo de la operación
+ * return: tipo del valor de la operación o un error de tipos
+ */
+func verificarTipoOperacion(nodo *ast.BinaryExpr) (string, error) {
+	izquierdo, err := verificarTipoExpresion(nodo.X)
+	if err != nil {
+		return "", err
+	}
+	derecho, err := verificarTipoExpresion(nodo.Y)
+	if err != nil {
+		return "", err
+	}
+
+	switch nodo.Op {
+	case token.LAND, token.LOR:
+		if izquierdo != TIPO_BOOLEANO || derecho != TIPO_BOOLEANO {
+			return "", fmt.Errorf("operador %s requiere valores verdadero o falso", nodo.Op)
+		}
+		return TIPO_BOOLEANO, nil
+
+	case token.EQL, token.NEQ:
+		if izquierdo != derecho {
+			return "", fmt.Errorf("operador %s entre un %s y un %s", nodo.Op, izquierdo, derecho)
+		}
+		return TIPO_BOOLEANO, nil
+
+	case token.LSS, token.LEQ, token.GTR, token.GEQ:
+		if izquierdo != derecho || izquierdo == TIPO_BOOLEANO {
+			return "", fmt.Errorf("operador %s requiere dos números o dos textos", nodo.Op)
+		}
+		return TIPO_BOOLEANO, nil
+
+	case token.ADD:
+		if izquierdo != derecho || izquierdo == TIPO_BOOLEANO {
+			return "", fmt.Errorf("operador %s requiere dos números o dos textos", nodo.Op)
+		}
+		return izquierdo, nil
+
+	case token.SUB, token.MUL, token.QUO, token.REM:
+		if izquierdo != TIPO_NUMERO || derecho != TIPO_NUMERO {
+			return "", fmt.Errorf("operador %s requiere números", nodo.Op)
+		}
+		return TIPO_NUMERO, nil
+	}
+
+	return "", fmt.Errorf("operador %s no soportado", nodo.Op)
+}
+
+/*
+ * Función para verificar los tipos de una llamada a una función de las reglas
+ * param: nodo de la llamada
+ * return: tipo del valor de la función o un error si la función no existe o sus argumentos no son válidos
+ */
+func verificarTipoFuncion(nodo *ast.CallExpr) (string, error) {
+	funcion, esIdentificador := nodo.Fun.(*ast.Ident)
+	if !esIdentificador {
+		return "", fmt.Errorf("función no soportada")
+	}
+
+	var argumentos []string
+	for _, argumento := range nodo.Args {
+		tipo, err := verificarTipoExpresion(argumento)
+		if err != nil {
+			return "", err
+		}
+		argumentos = append(argumentos, tipo)
+	}
+	requiere := func(tipos ...string) bool {
+		if len(argumentos) != len(tipos) {
+			return false
+		}
+		for i, tipo := range tipos {
+			if argumentos[i] != tipo {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch funcion.Name {
+	case "contiene":
+		if requiere(TIPO_TEXTO, TIPO_TEXTO) {
+			return TIPO_BOOLEANO, nil
+		}
+		return "", fmt.Errorf("contiene(texto, subtexto) requiere dos textos")
+
+	case "componente":
+		if requiere(TIPO_TEXTO, TIPO_NUMERO) {
+			return TIPO_TEXTO, nil
+		}
+		return "", fmt.Errorf("componente(ruta, n) requiere un texto y un número")
+
+	case "abs":
+		if requiere(TIPO_NUMERO) {
+			return TIPO_NUMERO, nil
+		}
+		return "", fmt.Errorf("abs(número) requiere un número")
+	}
+
+	return "", fmt.Errorf("función \"%s\" no definida", funcion.Name)
+}
+
+/*
+ * Función para evaluar una regla con las variables de un par
+ * param: variables del par
+ * return: si el par cumple la regla o un error si la expresión no es booleana o tiene un error de tipos
+ */
+func (regla ReglaPersonalizada) evaluar(variables map[string]interface{}) (bool, error) {
+	valor, err := evaluarExpresion(regla.expresion, variables)
+	if err != nil {
+		return false, err
+	}
+
+	resultado, esBooleano := valor.(bool)
+	if !esBooleano {
+		return false, fmt.Errorf("el resultado no es verdadero o falso")
+	}
+
+	return resultado, nil
+}
+
+/*
+ * Función para evaluar una expresión (números float64, textos string y booleanos bool)
+ * param: árbol de la expresión y variables
+ * return: valor de la expresión o un error
+ */
+func evaluarExpresion(expresion ast.Expr, variables map[string]interface{}) (interface{}, error) {
+	switch nodo := expresion.(type) {
+	case *ast.BasicLit:
+		switch nodo.Kind {
+		case token.INT, token.FLOAT:
+			return strconv.ParseFloat(nodo.Value, 64)
+		case token.STRING:
+			return strconv.Unquote(nodo.Value)
+		}
+		return nil, fmt.Errorf("valor %s no soportado", nodo.Value)
+
+	case *ast.Ident:
+		if nodo.Name == "true" || nodo.Name == "false" {
+			return nodo.Name == "true", nil
+		}
+		if valor, existe := variables[nodo.Name]; existe {
+			return valor, nil
+		}
+		return nil, fmt.Errorf("variable \"%s\" no definida", nodo.Name)
+
+	case *ast.ParenExpr:
+		return evaluarExpresion(nodo.X, variables)
+
+	case *ast.UnaryExpr:
+		valor, err := evaluarExpresion(nodo.X, variables)
+		if err != nil {
+			return nil, err
+		}
+		if booleano, esBooleano := valor.(bool); esBooleano && nodo.Op == token.NOT {
+			return !booleano, nil
+		}
+		if numero, esNumero := valor.(float64); esNumero && nodo.Op == token.SUB {
+			return -numero, nil
+		}
+		return nil, fmt.Errorf("operador %s no válido para %v", nodo.Op, valor)
+
+	case *ast.BinaryExpr:
+		return evaluarOperacion(nodo, variables)
+
+	case *ast.CallExpr:
+		return evaluarFuncion(nodo, variables)
+	}
+
+	return nil, fmt.Errorf("expresión no soportada")
+}
+
+/*
+ * Función para evaluar una operación binaria (&& y || se evalúan en cortocircuito)
+ * param: nodo de la operación y variables
+ * return: valor de la operación o un error
+ */
+func evaluarOperacion(nodo *ast.BinaryExpr, variables map[string]interface{}) (interface{}, error) {
+	izquierdo, err := evaluarExpresion(nodo.X, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	if nodo.Op == token.LAND || nodo.Op == token.LOR {
+		booleano, esBooleano := izquierdo.(bool)
+		if !esBooleano {
+			return nil, fmt.Errorf("operador %s requiere valores verdadero o falso", nodo.Op)
+		}
+		if (nodo.Op == token.LAND && !booleano) || (nodo.Op == token.LOR && booleano) {
+			return booleano, nil
+		}
+		derecho, err := evaluarExpresion(nodo.Y, variables)
+		if err != nil {
+			return nil, err
+		}
+		if _, esBooleano = derecho.(bool); !esBooleano {
+			return nil, fmt.Errorf("operador %s requiere valores verdadero o falso", nodo.Op)
+		}
+		return derecho, nil
+	}
+
+	derecho, err := evaluarExpresion(nodo.Y, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	switch nodo.Op {
+	case token.EQL:
+		return izquierdo == derecho, nil
+	case token.NEQ:
+		return izquierdo != derecho, nil
+	}
+
+	if texto1, esTexto := izquierdo.(string); esTexto {
+		texto2, esTexto := derecho.(string)
+		if !esTexto {
+			return nil, fmt.Errorf("operador %s entre un texto y otro valor", nodo.Op)
+		}
+		switch nodo.Op {
+		case token.ADD:
+			return texto1 + texto2, nil
+		case token.LSS:
+			return texto1 < texto2, nil
+		case token.LEQ:
+			return texto1 <= texto2, nil
+		case token.GTR:
+			return texto1 > texto2, nil
+		case token.GEQ:
+			return texto1 >= texto2, nil
+		}
+		return nil, fmt.Errorf("operador %s no válido para textos", nodo.Op)
+	}
+
+	numero1, esNumero1 := izquierdo.(float64)
+	numero2, esNumero2 := derecho.(float64)
+	if !esNumero1 || !esNumero2 {
+		return nil, fmt.Errorf("operador %s requiere números", nodo.Op)
+	}
+
+	switch nodo.Op {
+	case token.ADD:
+		return numero1 + numero2, nil
+	case token.SUB:
+		return numero1 - numero2, nil
+	case token.MUL:
+		return numero1 * numero2, nil
+	case token.QUO:
+		return numero1 / numero2, nil
+	case token.REM:
+		return math.Mod(numero1, numero2), nil
+	case token.LSS:
+		return numero1 < numero2, nil
+	case token.LEQ:
+		return numero1 <= numero2, nil
+	case token.GTR:
+		return numero1 > numero2, nil
+	case token.GEQ:
+		return numero1 >= numero2, nil
+	}
+
+	return nil, fmt.Errorf("operador %s no soportado", nodo.Op)
+}
+
+/*
+ * Función para evaluar una llamada a una función de las reglas
+ * param: nodo de la llamada y variables
+ * return: valor de la función o un error
+ */
+func evaluarFuncion(nodo *ast.CallExpr, variables map[string]interface{}) (interface{}, error) {
+	funcion, esIdentificador := nodo.Fun.(*ast.Ident)
+	if !esIdentificador {
+		return nil, fmt.Errorf("función no soportada")
+	}
+
+	var argumentos []interface{}
+	for _, argumento := range nodo.Args {
+		valor, err := evaluarExpresion(argumento, variables)
+		if err != nil {
+			return nil, err
+		}
+		argumentos = append(argumentos, valor)
+	}
+
+	switch funcion.Name {
+	case "contiene":
+		if len(argumentos) == 2 {
+			texto, esTexto1 := argumentos[0].(string)
+			subtexto, esTexto2 := argumentos[1].(string)
+			if esTexto1 && esTexto2 {
+				return strings.Contains(texto, subtexto), nil
+			}
+		}
+		return nil, fmt.Errorf("contiene(texto, subtexto) requiere dos textos")
+
+	case "componente":
+		if len(argumentos) == 2 {
+			ruta, esTexto := argumentos[0].(string)
+			posicion, esNumero := argumentos[1].(float64)
+			if esTexto && esNumero {
+				componentes := strings.Split(strings.TrimPrefix(ruta, "./"), "/")
+				// El último componente es el nombre del archivo, no un directorio
+				if int(posicion) < 0 || int(posicion) >= len(componentes)-1 {
+					return "", nil
+				}
+				return componentes[int(posicion)], nil
+			}
+		}
+		return nil, fmt.Errorf("componente(ruta, n) requiere un texto y un número")
+
+	case "abs":
+		if len(argumentos) == 1 {
+			if numero, esNumero := argumentos[0].(float64); esNumero {
+				return math.Abs(numero), nil
+			}
+		}
+		return nil, fmt.Errorf("abs(número) requiere un número")
+	}
+
+	return nil, fmt.Errorf("función \"%s\" no definida", funcion.Name)
+}
+
+/*
+ * Función para obtener las variables de un par de archivos para evaluar las reglas
+ * param: resultado del análisis, índices del par y la distribución de las distancias del corpus
+ * return: variables del par
+ */
+func obtenerVariablesPar(resultado ResultadoAnalisis, i int, j int, distribucion []float64) map[string]interface{} {
+	tablaCodigoFuente := resultado.archivos
+	distancia := resultado.matriz[i][j]
+	tamano1, tamano2 := float64(tablaCodigoFuente[i].tamano), float64(tablaCodigoFuente[j].tamano)
+
+	diferenciaTamano := 0.0
+	if mayor := math.Max(tamano1, tamano2); mayor > 0 {
+		diferenciaTamano = 100.0 * math.Abs(tamano1-tamano2) / mayor
+	}
+
+	estudiante1 := obtenerEstudiante(".", tablaCodigoFuente[i].ruta)
+	estudiante2 := obtenerEstudiante(".", tablaCodigoFuente[j].ruta)
+
+	return map[string]interface{}{
+		"distancia":         distancia,
+		"similitud":         math.Max(0.0, 100.0-distancia),
+		"percentil":         calcularPercentil(distribucion, distancia),
+		"archivo1":          tablaCodigoFuente[i].ruta,
+		"archivo2":          tablaCodigoFuente[j].ruta,
+		"estudiante1":       estudiante1,
+		"estudiante2":       estudiante2,
+		"mismo_estudiante":  estudiante1 == estudiante2,
+		"tamano1":           tamano1,
+		"tamano2":           tamano2,
+		"diferencia_tamano": diferenciaTamano,
+	}
+}
+
+/*
+ * Función para evaluar las reglas personalizadas sobre todos los pares de archivos.
+ * Un par en el que la regla no se puede evaluar (por ejemplo por un error de tipos) no se marca.
+ * param: resultado del análisis y las reglas
+ * return: los pares marcados por cada regla (en el orden de las reglas) y el primer error de evaluación
+ */
+func evaluarReglas(resultado ResultadoAnalisis, reglas []ReglaPersonalizada) ([]ParReglaJSON, error) {
+	marcados := []ParReglaJSON{}
+
+	if len(reglas) == 0 {
+		return marcados, nil
+	}
+
+	var primerError error
+	tablaCodigoFuente := resultado.archivos
+
+	distribucion := resultado.distribucion
+	if distribucion == nil {
+		distribucion = obtenerDistribucionDistancias(resultado)
+	}
+
+	for _, regla := range reglas {
+		for i := range tablaCodigoFuente {
+			for j := i + 1; j < len(tablaCodigoFuente); j++ {
+				// Los pares sin la distancia calculada (--pairs=lsh) no se evalúan
+				if !esDistanciaCalculada(resultado.matriz[i][j]) {
+					continue
+				}
+
+				cumple, err := regla.evaluar(obtenerVariablesPar(resultado, i, j, distribucion))
+				if err != nil && primerError == nil {
+					primerError = fmt.Errorf("Error al evaluar la regla \"%s\" en %s <-> %s: %v", regla.nombre, tablaCodigoFuente[i].nombre, tablaCodigoFuente[j].nombre, err)
+				}
+
+				if cumple {
+					marcados = append(marcados, ParReglaJSON{
+						Regla:     regla.nombre,
+						Archivo1:  tablaCodigoFuente[i].nombre,
+						Archivo2:  tablaCodigoFuente[j].nombre,
+						Distancia: resultado.matriz[i][j],
+					})
+				}
+			}
+		}
+	}
+
+	return marcados, primerError
+}
+
+/*
+ * Función para imprimir los pares marcados por cada regla personalizada
+ * param: reglas y pares marcados
+ */
+func imprimirReglas(reglas []ReglaPersonalizada, marcados []ParReglaJSON) {
+	fmt.Print("\nREGLAS PERSONALIZADAS\n\n")
+
+	for _, regla := range reglas {
+		fmt.Printf("%s (%s)\n", regla.nombre, regla.texto)
+
+		cantidad := 0
+		for _, par := range marcados {
+			if par.Regla == regla.nombre {
+				fmt.Printf("\t%8.2f %s <-> %s\n", par.Distancia, par.Archivo1, par.Archivo2)
+				cantidad++
+			}
+		}
+		if cantidad == 0 {
+			fmt.Println("\tningún par cumple la regla")
+		}
+		fmt.Println()
+	}
+}
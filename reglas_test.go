@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCompilarReglas(t *testing.T) {
+	casos := []struct {
+		definicion string
+		valida     bool
+	}{
+		{"copia: similitud > 85 && componente(archivo1, 0) == componente(archivo2, 0) && diferencia_tamano < 10", true},
+		{"ajena: !mismo_estudiante || contiene(archivo1, \"main\")", true},
+		{"texto: estudiante1 + \"/\" >= estudiante2", true},
+		{"tamaño: abs(tamano1 - tamano2) % 2 == 0", true},
+		// Los errores de tipos del lado que el cortocircuito no evalúa también se detectan
+		{"derecho del &&: false && distancia + archivo1 > 0", false},
+		{"derecho del ||: true || contiene(distancia, \"a\")", false},
+		{"derecho no booleano: mismo_estudiante && distancia", false},
+		{"comparación de tipos distintos: estudiante1 == 3", false},
+		{"resultado numérico: distancia + 1", false},
+		{"variable: distancia < maxima", false},
+		{"función: longitud(archivo1) > 3", false},
+		{"argumentos: abs(archivo1) > 3", false},
+		{"sin nombre", false},
+	}
+
+	for _, caso := range casos {
+		if _, err := compilarReglas([]string{caso.definicion}); (err == nil) != caso.valida {
+			t.Errorf("compilarReglas(%q): error = %v", caso.definicion, err)
+		}
+	}
+
+	if _, err := compilarReglas([]string{"a: true", "a: false"}); err == nil {
+		t.Error("reglas repetidas compiladas sin error")
+	}
+}
+
+func TestEvaluarRegla(t *testing.T) {
+	reglas, err := compilarReglas([]string{"cercanos: distancia < 10 && !mismo_estudiante && componente(archivo1, 0) == \"grupo1\""})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	casos := []struct {
+		distancia float64
+		archivo1  string
+		mismo     bool
+		cumple    bool
+	}{
+		{5, "grupo1/ana/main.go", false, true},
+		{15, "grupo1/ana/main.go", false, false},
+		{5, "grupo1/ana/main.go", true, false},
+		{5, "grupo2/ana/main.go", false, false},
+	}
+
+	for _, caso := range casos {
+		variables := map[string]interface{}{"distancia": caso.distancia, "archivo1": caso.archivo1, "mismo_estudiante": caso.mismo}
+		if cumple, err := reglas[0].evaluar(variables); err != nil || cumple != caso.cumple {
+			t.Errorf("regla con %v = %v, %v, se esperaba %v", variables, cumple, err, caso.cumple)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Reparto de la cola de revisión entre los monitores (--review-tas).
+ *
+ * Los pares de la cola se reparten de forma equilibrada entre los monitores indicados. Los pares relacionados
+ * (con algún archivo en un mismo grupo) quedan siempre con el mismo monitor, para que una misma copia no la
+ * revisen dos personas con criterios distintos. Los bloques de pares relacionados se asignan del más
+ * grande al más pequeño, cada uno al monitor con menos pares hasta el momento.
+ *
+ * Con --review-csv y --review-html se genera además una hoja de trabajo por monitor (por ejemplo
+ * "revision-Ana.csv"), con los pares que le corresponden en el orden de la cola.
+ */
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+/*
+ * Función para obtener los nombres de los monitores
+ * param: nombres separados por comas (por ejemplo "Ana,Luis,Marta")
+ * return: los nombres sin repetir, en el orden indicado, o un error si alguno está vacío
+ */
+func obtenerAsistentes(texto string) ([]string, error) {
+	var asistentes []string
+
+	repetidos := make(map[string]bool)
+	for _, nombre := range strings.Split(texto, ",") {
+		nombre = strings.TrimSpace(nombre)
+		if nombre == "" {
+			return nil, fmt.Errorf("Los monitores (--review-tas) \"%s\" deben ser nombres no vacíos separados por comas", texto)
+		}
+
+		if !repetidos[nombre] {
+			repetidos[nombre] = true
+			asistentes = append(asistentes, nombre)
+		}
+	}
+
+	return asistentes, nil
+}
+
+/*
+ * Función para repartir los pares de la cola de revisión entre los monitores (completa el monitor de cada par)
+ * param: grupos del análisis, pares de la cola (se modifican) y nombres de los monitores
+ */
+func repartirColaRevision(grupos []Grupo, cola []ParRevision, asistentes []string) {
+	if len(asistentes) == 0 {
+		return
+	}
+
+	// Bloques de pares relacionados (conjuntos disjuntos por los grupos de sus archivos)
+	raices := make([]int, len(cola))
+	for i := range raices {
+		raices[i] = i
+	}
+
+	primeroPorGrupo := make(map[int]int)
+	for i, par := range cola {
+		for posicion, grupo := range grupos {
+			for _, integrante := range grupo.integrantes {
+				if integrante.indice != par.archivo1 && integrante.indice != par.archivo2 {
+					continue
+				}
+
+				if primero, existe := primeroPorGrupo[posicion]; existe {
+					raices[obtenerRaizGrupo(raices, i)] = obtenerRaizGrupo(raices, primero)
+				} else {
+					primeroPorGrupo[posicion] = i
+				}
+				break
+			}
+		}
+	}
+
+	bloques := make(map[int][]int)
+	var ordenBloques []int
+	for i := range cola {
+		raiz := obtenerRaizGrupo(raices, i)
+		if _, existe := bloques[raiz]; !existe {
+			ordenBloques = append(ordenBloques, raiz)
+		}
+		bloques[raiz] = append(bloques[raiz], i)
+	}
+
+	sort.SliceStable(ordenBloques, func(i, j int) bool {
+		return len(bloques[ordenBloques[i]]) > len(bloques[ordenBloques[j]])
+	})
+
+	carga := make([]int, len(asistentes))
+	for _, raiz := range ordenBloques {
+		menor := 0
+		for asistente := range asistentes {
+			if carga[asistente] < carga[menor] {
+				menor = asistente
+			}
+		}
+
+		for _, i := range bloques[raiz] {
+			cola[i].asistente = asistentes[menor]
+		}
+		carga[menor] += len(bloques[raiz])
+	}
+}
+
+/*
+ * Función para obtener los pares de la cola asignados a un monitor
+ * param: pares de la cola y nombre del monitor
+ * return: los pares del monitor, en el orden de la cola
+ */
+func obtenerParesAsistente(cola []ParRevision, asistente string) []ParRevision {
+	var pares []ParRevision
+
+	for _, par := range cola {
+		if par.asistente == asistente {
+			pares = append(pares, par)
+		}
+	}
+
+	return pares
+}
+
+/*
+ * Función para obtener el nombre de la hoja de trabajo de un monitor, agregando su nombre antes de la extensión
+ * param: nombre del archivo (por ejemplo "revision.csv") y nombre del monitor
+ * return: nombre de la hoja del monitor (por ejemplo "revision-Ana.csv"), solo con letras, números, "-" y "_"
+ */
+func obtenerNombreArchivoAsistente(nombre string, asistente string) string {
+	extension := filepath.Ext(nombre)
+
+	seguro := strings.Map(func(caracter rune) rune {
+		if unicode.IsLetter(caracter) || unicode.IsDigit(caracter) || caracter == '-' || caracter == '_' {
+			return caracter
+		}
+		return '_'
+	}, asistente)
+
+	return strings.TrimSuffix(nombre, extension) + "-" + seguro + extension
+}
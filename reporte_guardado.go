@@ -0,0 +1,199 @@
+/*
+ * Reportes de un resultado guardado (comando report).
+ *
+ * El resultado JSON de un análisis (--output=file:ruta, --bundle o un trabajo del directorio de datos del modo
+ * servidor) contiene los archivos, la matriz de distancias y los grupos. Con
+ *
+ *     ./SASC report --from=resultado.json [--csv=...] [--groups-csv=...] [--groups-json=...] [--output=...]
+ *
+ * los reportes se generan de nuevo a partir de él, sin leer las entregas ni calcular las distancias: los grupos y
+ * las distancias en consola, el archivo CSV de la matriz y los archivos de grupos. Se conservan la distancia máxima y
+ * los grupos del resultado (no se pueden cambiar, porque los identificadores de los grupos dependen del contenido de
+ * los archivos); los reportes que necesitan el contenido (evidencia, cobertura, vista previa, paquete) no están disponibles.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/*
+ * Función para cargar un resultado guardado, también dentro de un trabajo del directorio de datos
+ * param: ruta del archivo JSON y cifrado (nil si no se indicó la clave, para un trabajo cifrado)
+ * return: el resultado o un error si el archivo no se puede leer o descifrar o no es un resultado
+ */
+func cargarResultadoGuardado(ruta string, cifrado *Cifrado) (ResultadoAnalisisJSON, error) {
+	var trabajo Trabajo
+	var resultado ResultadoAnalisisJSON
+
+	contenido, err := cifrado.leerArchivo(ruta)
+	if err != nil {
+		return resultado, fmt.Errorf("No se puede leer el resultado guardado (--from): %v", err)
+	}
+
+	if err = json.Unmarshal(contenido, &trabajo); err == nil && trabajo.Resultado != nil {
+		resultado = *trabajo.Resultado
+	} else if err = json.Unmarshal(contenido, &resultado); err != nil {
+		return resultado, fmt.Errorf("El archivo \"%s\" no es un resultado JSON de SASC: %v", ruta, err)
+	}
+
+	if len(resultado.Distancias) != len(resultado.Archivos) {
+		return resultado, fmt.Errorf("El archivo \"%s\" no es un resultado JSON de SASC: la matriz de distancias no corresponde a los archivos", ruta)
+	}
+	for _, fila := range resultado.Distancias {
+		if len(fila) != len(resultado.Archivos) {
+			return resultado, fmt.Errorf("El archivo \"%s\" no es un resultado JSON de SASC: la matriz de distancias no corresponde a los archivos", ruta)
+		}
+	}
+
+	return resultado, nil
+}
+
+/*
+ * Función para reconstruir el resultado de un análisis a partir de un resultado guardado
+ * param: resultado guardado y parámetros de ejecución (percentiles)
+ * return: el resultado del análisis (los archivos sin contenido) o un error si un grupo incluye un archivo desconocido
+ */
+func reconstruirResultadoAnalisis(resultadoJSON ResultadoAnalisisJSON, parametros Parametros) (ResultadoAnalisis, error) {
+	resultado := ResultadoAnalisis{
+		matriz:      make([][]float64, len(resultadoJSON.Distancias)),
+		descartados: resultadoJSON.Descartados,
+		validez:     resultadoJSON.Validez,
+		extension:   resultadoJSON.Extension,
+		motor:       resultadoJSON.Motor,
+		proyeccion:  resultadoJSON.Proyeccion,
+		criterio:    CriterioDistancia{criterio: resultadoJSON.CriterioDistancia},
+	}
+
+	// El tamaño de los archivos solo se conoce si el resultado incluye la validez
+	tamanos := make(map[string]int)
+	for _, validez := range resultadoJSON.Validez {
+		tamanos[validez.Archivo] = validez.Tamano
+	}
+
+	for i, fila := range resultadoJSON.Distancias {
+		resultado.matriz[i] = fila
+	}
+
+	indices := make(map[string]int)
+	for i, nombre := range resultadoJSON.Archivos {
+		resultado.archivos = append(resultado.archivos, CodigoFuente{nombre: nombre, ruta: nombre, tamano: tamanos[nombre], alias: resultadoJSON.Alias[nombre]})
+		indices[nombre] = i
+	}
+
+	crearTablasDistancias(resultado.archivos, false)
+	for i := range resultado.archivos {
+		for j, distancia := range resultado.matriz[i] {
+			asignarValorDistancia(&resultado.archivos[i], j, distancia)
+		}
+	}
+
+	if resultadoJSON.DistanciaMaxima != nil {
+		resultado.criterio.valor, resultado.criterio.resuelto = *resultadoJSON.DistanciaMaxima, true
+	}
+
+	for _, grupoJSON := range resultadoJSON.Grupos {
+		grupo := Grupo{identificador: grupoJSON.Grupo, diametro: grupoJSON.Diametro, distanciaEjemplar: grupoJSON.ParEjemplar.Distancia, terminos: grupoJSON.Terminos}
+
+		nombres := []string{grupoJSON.Medoide, grupoJSON.ParEjemplar.Archivo1, grupoJSON.ParEjemplar.Archivo2}
+		for _, integrante := range grupoJSON.Integrantes {
+			nombres = append(nombres, integrante.Archivo)
+		}
+		for _, nombre := range nombres {
+			if _, ok := indices[nombre]; !ok {
+				return resultado, fmt.Errorf("El grupo %s del resultado guardado incluye el archivo desconocido \"%s\"", grupoJSON.Grupo, nombre)
+			}
+		}
+
+		grupo.centro = indices[grupoJSON.Medoide]
+		grupo.ejemplar = [2]int{indices[grupoJSON.ParEjemplar.Archivo1], indices[grupoJSON.ParEjemplar.Archivo2]}
+		for _, integrante := range grupoJSON.Integrantes {
+			grupo.integrantes = append(grupo.integrantes, IntegranteGrupo{indice: indices[integrante.Archivo], distanciaCentro: integrante.DistanciaCentro, enGrupoAnterior: integrante.EnGrupoAnterior})
+		}
+
+		resultado.grupos = append(resultado.grupos, grupo)
+	}
+	resultado.gruposAntesFusion = len(resultado.grupos)
+
+	if parametros.percentiles {
+		resultado.distribucion = obtenerDistribucionDistancias(resultado)
+	}
+
+	return resultado, nil
+}
+
+/*
+ * Función para generar los reportes de un resultado guardado (comando report)
+ * param: parámetros de ejecución (archivo del resultado y reportes solicitados)
+ * return: un error si el resultado no se puede cargar, un destino de salida no es válido o el envío a alguno falló
+ */
+func generarReportesGuardados(parametros Parametros) error {
+	cifrado, err := crearCifrado(parametros.archivoCifrado)
+	if err != nil {
+		return err
+	}
+
+	resultadoJSON, err := cargarResultadoGuardado(parametros.rutaResultado, cifrado)
+	if err != nil {
+		return err
+	}
+
+	resultado, err := reconstruirResultadoAnalisis(resultadoJSON, parametros)
+	if err != nil {
+		return err
+	}
+
+	destinos, err := crearDestinosSalida(parametros.salidas)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Reportes del resultado guardado \""+parametros.rutaResultado+"\":", len(resultado.archivos), "archivos de extensión ."+resultado.extension)
+	fmt.Println("Motor de características:", resultado.motor)
+	fmt.Println("             distancia máxima:", resultado.criterio.describir())
+
+	if resultado.tieneDistanciaMaxima() {
+		if parametros.nombreGruposCSV != "" {
+			fmt.Println("             generando el archivo \"" + parametros.nombreGruposCSV + "\" con los grupos")
+			generarGruposCSV(resultado.archivos, resultado.grupos, parametros.nombreGruposCSV)
+		}
+		if parametros.nombreGruposJSON != "" {
+			fmt.Println("             generando el archivo \"" + parametros.nombreGruposJSON + "\" con los grupos")
+			generarGruposJSON(resultado.archivos, resultado.grupos, resultado.criterio, parametros.nombreGruposJSON)
+		}
+	} else if parametros.nombreGruposCSV != "" || parametros.nombreGruposJSON != "" {
+		fmt.Println("             los archivos de grupos NO se generan por no tener una distancia máxima")
+	}
+
+	if parametros.nombreTablaCSV != "" {
+		fmt.Println("             generando el archivo \"" + parametros.nombreTablaCSV + "\"")
+		generarArchivoCSV(resultado, parametros.nombreTablaCSV, parametros.filtroCSV, parametros.bandasSeveridad, parametros.precisionCSV)
+	}
+
+	var errSalidas error
+	if len(destinos) > 0 {
+		fmt.Println("             enviando el resultado JSON a", len(destinos), "destinos de salida")
+		if fallidos := enviarResultado(resultadoJSON, destinos); fallidos > 0 {
+			errSalidas = fmt.Errorf("No se pudo enviar el resultado a %d destinos de salida (--output)", fallidos)
+		}
+	}
+
+	if !parametros.consola {
+		return errSalidas
+	}
+
+	if resultado.tieneDistanciaMaxima() {
+		imprimitGrupos(resultado.archivos, resultado.grupos, resultado.criterio)
+		fmt.Println(" (*) Este código pertence a otros grupos")
+	}
+
+	if len(resultadoJSON.Alias) > 0 {
+		imprimirArchivosIdenticos(resultado.archivos)
+	}
+
+	imprimirDistancias(resultado, parametros.bandasSeveridad, VistaFuente{})
+
+	return errSalidas
+}
@@ -0,0 +1,117 @@
+/*
+ * Resultado de un análisis.
+ *
+ * El proceso de análisis (fases 1 y 2 y la formación de los grupos) produce un único ResultadoAnalisis con los
+ * archivos, la matriz de distancias, los grupos, los niveles y los metadatos del análisis. Todos los reportes y
+ * exportaciones (consola, CSV, JSON, HTML) se generan a partir de él, en lugar de volver a derivar lo que
+ * necesitan de la tabla de código fuente, por lo que todos muestran la misma información.
+ *
+ * La matriz y las tablas de distancias siempre están en el orden de los archivos y ningún reporte las modifica:
+ * los reportes que necesitan las distancias ordenadas usan una vista ordenada (ver ordenarIndicesPorDistancia),
+ * por lo que el orden de generación de los reportes no cambia su contenido.
+ */
+
+package main
+
+import "sort"
+
+// Estructura con el resultado completo de un análisis
+// - información de los archivos analizados (con sus características y tablas de distancias)
+// - matriz de distancias en el orden de los archivos (en la escala original, también con --squared)
+// - criterio de la distancia máxima (con la distancia máxima ya calculada si es "auto")
+// - grupos a la distancia máxima (después de la fusión, si se pidió) y cantidad de grupos antes de la fusión
+// - grupos de cada nivel de --thresholds
+// - distribución ordenada de las distancias de todos los pares (solo si se anotan los percentiles)
+// - nombres de los archivos descartados y validez de todos los archivos
+// - metadatos: extensión, nombre del motor y proyección de las características (nil si no se proyectaron)
+type ResultadoAnalisis struct {
+	archivos          []CodigoFuente
+	matriz            [][]float64
+	criterio          CriterioDistancia
+	grupos            []Grupo
+	gruposAntesFusion int
+	niveles           []NivelGrupos
+	distribucion      []float64
+	descartados       []string
+	validez           []ValidezArchivo
+	extension         string
+	motor             string
+	proyeccion        *Proyeccion
+}
+
+/*
+ * Función para construir el resultado de un análisis a partir de los archivos con sus distancias ya calculadas
+ * param: arreglo con la información del código fuente de los archivos, parámetros de ejecución, el motor empleado,
+ *        nombres de los archivos descartados, validez de los archivos y la proyección de las características
+ * return: el resultado del análisis
+ */
+func construirResultadoAnalisis(tablaCodigoFuente []CodigoFuente, parametros Parametros, motor Motor, descartados []string, validez []ValidezArchivo, proyeccion *Proyeccion) ResultadoAnalisis {
+	resultado := ResultadoAnalisis{
+		archivos:    tablaCodigoFuente,
+		descartados: descartados,
+		validez:     validez,
+		extension:   parametros.extension,
+		motor:       motor.nombre(),
+		proyeccion:  proyeccion,
+	}
+
+	resultado.matriz = make([][]float64, len(tablaCodigoFuente))
+	for i, archivo := range tablaCodigoFuente {
+		resultado.matriz[i] = make([]float64, len(tablaCodigoFuente))
+		for j := range tablaCodigoFuente {
+			resultado.matriz[i][j] = obtenerDistancia(archivo, j)
+		}
+	}
+
+	var distribucion []float64
+	if parametros.percentiles || parametros.criterioDistancia.criterio == CRITERIO_AUTOMATICO {
+		distribucion = obtenerDistribucionDistancias(resultado)
+	}
+	if parametros.percentiles {
+		resultado.distribucion = distribucion
+	}
+	resultado.criterio = parametros.criterioDistancia.resolver(distribucion)
+
+	if resultado.criterio.definido() {
+		resultado.grupos = determinarGrupos(tablaCodigoFuente, resultado.criterio.limite())
+		resultado.gruposAntesFusion = len(resultado.grupos)
+
+		if parametros.distanciaFusion >= 0 {
+			resultado.grupos = fusionarGrupos(tablaCodigoFuente, resultado.grupos, parametros.distanciaFusion)
+		}
+
+		nombrarGrupos(tablaCodigoFuente, resultado.grupos, parametros.terminosGrupo)
+	}
+
+	if len(parametros.umbrales) > 0 {
+		resultado.niveles = determinarGruposPorNivel(tablaCodigoFuente, parametros.umbrales)
+	}
+
+	return resultado
+}
+
+/*
+ * Función para determinar si el resultado tiene una distancia máxima definida
+ * return: si se definió la distancia máxima
+ */
+func (resultado ResultadoAnalisis) tieneDistanciaMaxima() bool {
+	return resultado.criterio.definido()
+}
+
+/*
+ * Función para obtener una vista ordenada de una fila de la matriz de distancias, sin modificarla
+ * param: distancias de un archivo a todos los archivos
+ * return: índices de los archivos de menor a mayor distancia (los empates en el orden de los archivos)
+ */
+func ordenarIndicesPorDistancia(distancias []float64) []int {
+	indices := make([]int, len(distancias))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	sort.SliceStable(indices, func(j, k int) bool {
+		return distancias[indices[j]] < distancias[indices[k]]
+	})
+
+	return indices
+}
@@ -0,0 +1,105 @@
+/*
+ * Resumen por directorio (--dir-summary).
+ *
+ * Agrupa los resultados por subdirectorio inmediato del directorio base (normalmente un estudiante o una sección):
+ * cantidad de archivos, menor distancia a un archivo de otro directorio y grupos en los que participa.
+ * Los directorios se ordenan por su menor distancia externa, lo que permite priorizar la revisión de cohortes grandes.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Estructura del resumen de un directorio
+// - nombre del subdirectorio ("." para los archivos del directorio base)
+// - cantidad de archivos analizados
+// - menor distancia calculada a un archivo de otro directorio y el índice de ese archivo (-1 si no hay ninguna)
+// - identificadores de los grupos en los que participa alguno de sus archivos
+type ResumenDirectorio struct {
+	nombre           string
+	cantidadArchivos int
+	distanciaExterna float64
+	indiceMasCercano int
+	gruposTocados    []string
+}
+
+/*
+ * Función para calcular el resumen de cada subdirectorio inmediato del directorio base
+ * param: resultado del análisis
+ * return: resumen de cada directorio, de menor a mayor distancia externa
+ */
+func calcularResumenDirectorios(resultado ResultadoAnalisis) []ResumenDirectorio {
+	var resumenes []ResumenDirectorio
+
+	directorios := make([]string, len(resultado.archivos))
+	posiciones := make(map[string]int)
+
+	for i, archivo := range resultado.archivos {
+		directorios[i] = obtenerEstudiante(".", archivo.ruta)
+	}
+
+	for i := range resultado.archivos {
+		if _, existe := posiciones[directorios[i]]; !existe {
+			posiciones[directorios[i]] = len(resumenes)
+			resumenes = append(resumenes, ResumenDirectorio{nombre: directorios[i], distanciaExterna: math.MaxFloat64, indiceMasCercano: -1})
+		}
+		resumen := &resumenes[posiciones[directorios[i]]]
+		resumen.cantidadArchivos++
+
+		for j, distancia := range resultado.matriz[i] {
+			if directorios[j] == directorios[i] || !esDistanciaCalculada(distancia) {
+				continue
+			}
+
+			if distancia < resumen.distanciaExterna {
+				resumen.distanciaExterna = distancia
+				resumen.indiceMasCercano = j
+			}
+		}
+	}
+
+	for _, grupo := range resultado.grupos {
+		tocados := make(map[string]bool)
+
+		for _, integrante := range grupo.integrantes {
+			directorio := directorios[integrante.indice]
+
+			if !tocados[directorio] {
+				tocados[directorio] = true
+				resumen := &resumenes[posiciones[directorio]]
+				resumen.gruposTocados = append(resumen.gruposTocados, grupo.identificador)
+			}
+		}
+	}
+
+	sort.SliceStable(resumenes, func(i, j int) bool {
+		return resumenes[i].distanciaExterna < resumenes[j].distanciaExterna
+	})
+
+	return resumenes
+}
+
+/*
+ * Función para imprimir el resumen por directorio
+ * param: resultado del análisis
+ */
+func imprimirResumenDirectorios(resultado ResultadoAnalisis) {
+	fmt.Print("\nRESUMEN POR DIRECTORIO (DE MENOR A MAYOR DISTANCIA A OTRO DIRECTORIO)\n\n")
+	fmt.Printf("%-20s %8s %18s  %-30s %s\n", "DIRECTORIO", "ARCHIVOS", "DISTANCIA EXTERNA", "ARCHIVO MÁS CERCANO", "GRUPOS")
+
+	for _, resumen := range calcularResumenDirectorios(resultado) {
+		distancia, cercano := "-", "-"
+		if resumen.indiceMasCercano >= 0 {
+			distancia = fmt.Sprintf("%.2f", resumen.distanciaExterna)
+			cercano = resultado.archivos[resumen.indiceMasCercano].nombre
+		}
+
+		fmt.Printf("%-20s %8d %18s  %-30s %s\n", resumen.nombre, resumen.cantidadArchivos, distancia, cercano, strings.Join(resumen.gruposTocados, ";"))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,214 @@
+/*
+ * Robustez de los motores ante ofuscaciones sintéticas (comando robustness).
+ *
+ * ./SASC robustness archivo.go aplica al archivo las ofuscaciones sintéticas (ver ofuscaciones.go) y reporta, para
+ * cada motor de --robustness-engines, la distancia entre el archivo original y cada versión ofuscada, de modo que el
+ * docente ve qué motor sigue encontrando la copia según los cambios que espera en la tarea.
+ *
+ * Las distancias de motores distintos no están en la misma escala. Si el directorio de ejecución (o --dir) tiene
+ * otros archivos de la misma extensión (las entregas de la tarea), cada distancia se acompaña de su percentil entre
+ * las distancias de todos los pares de ese corpus: un percentil bajo indica que la versión ofuscada seguiría entre
+ * los pares más cercanos, y el motor con el menor percentil promedio es el más robusto para esa tarea.
+ *
+ * Cada motor se crea con las opciones que le aplican (--line-score, --metric, --normalize, --features, --hash-dims,
+ * --spec y --lexer), sin reducción de dimensiones (la proyección depende del corpus) ni matriz de Gram.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Motores por defecto del comando robustness
+const MOTORES_ROBUSTEZ = "ascii,lines,tokens"
+
+/*
+ * Función para crear un motor del comando robustness con las opciones que le aplican
+ * param: parámetros de ejecución y nombre del motor
+ * return: el motor, los parámetros con los que se creó o un error si no existe
+ */
+func crearMotorRobustez(parametros Parametros, nombre string) (Motor, Parametros, error) {
+	parametros.motor = nombre
+	parametros.reduccion = ""
+	parametros.matrizGram, parametros.distanciasCuadradas = false, false
+
+	if nombre != "tokens" {
+		parametros.dimensionesHash, parametros.rutaEspecificacion, parametros.lexico = 0, "", LEXICO_GENERICO
+	}
+	if nombre != "ascii" {
+		parametros.caracteristicas = CARACTERISTICAS_ASCII
+	}
+	if nombre == "lines" || nombre == "ncd" || nombre == "simhash" {
+		parametros.metrica, parametros.normalizacion = METRICA_EUCLIDIANA, NORMALIZACION_NINGUNA
+	}
+
+	motor, err := crearMotor(parametros)
+
+	return motor, parametros, err
+}
+
+/*
+ * Función para caracterizar un contenido que no está en un archivo (el original o una versión ofuscada)
+ * param: nombre para los reportes, contenido, el motor y el preprocesamiento
+ * return: la información del contenido con sus características
+ */
+func caracterizarContenido(nombre string, contenido []byte, motor Motor, preprocesamiento Preprocesamiento) CodigoFuente {
+	var arena ArenaCaracteristicas
+
+	codigoFuente := CodigoFuente{nombre: nombre, ruta: nombre, tamano: len(contenido), contenido: preprocesamiento.preprocesar(contenido)}
+	caracterizarEnArena(&arena, motor, &codigoFuente)
+
+	return codigoFuente
+}
+
+/*
+ * Función para obtener la distribución de las distancias entre los archivos del corpus con un motor
+ * param: listado del corpus, el motor, los parámetros con los que se creó, el preprocesamiento y el formato de las rutas
+ * return: distancias de todos los pares, ordenadas de forma ascendente (nil si hay menos de dos archivos)
+ */
+func obtenerDistribucionCorpus(listado []string, motor Motor, parametros Parametros, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas) []float64 {
+	if len(listado) < 2 {
+		return nil
+	}
+
+	tablaCodigoFuente := determinarCaracteristicas(listado, motor, preprocesamiento, formatoRutas, parametros.trabajadores)
+	tablaCodigoFuente = determinarDistanciasEntreArchivos(tablaCodigoFuente, motor, parametros, nil)
+
+	distribucion := make([]float64, 0, len(listado)*(len(listado)-1)/2)
+	for i := range tablaCodigoFuente {
+		for j := i + 1; j < len(tablaCodigoFuente); j++ {
+			distribucion = append(distribucion, obtenerDistancia(tablaCodigoFuente[i], j))
+		}
+	}
+	sort.Float64s(distribucion)
+
+	return distribucion
+}
+
+/*
+ * Función para evaluar la robustez de los motores ante las ofuscaciones sintéticas de un archivo (comando robustness)
+ * param: parámetros de ejecución, el preprocesamiento, el formato de las rutas y el directorio del corpus
+ * return: un error si el archivo no se puede leer o un motor no existe
+ */
+func evaluarRobustez(parametros Parametros, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas, directorioActual string) error {
+	original, err := ioutil.ReadFile(parametros.rutaRobustez)
+	if err != nil {
+		return fmt.Errorf("No se puede leer el archivo del comando robustness: %v", err)
+	}
+
+	sintaxis := obtenerSintaxisComentarios(parametros.extension)
+	versiones := make([][]byte, len(ofuscacionesSinteticas))
+	cambios := make([]int, len(ofuscacionesSinteticas))
+	for k, ofuscacion := range ofuscacionesSinteticas {
+		var texto string
+		texto, cambios[k] = ofuscacion.aplicar(string(original), sintaxis)
+		versiones[k] = []byte(texto)
+	}
+
+	// Corpus: los demás archivos de la extensión en el directorio
+	var corpus []string
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		return err
+	}
+	listado, archivosZip, omitidos, err := obtenerListadoEntregas(directorioActual, parametros.extension, limites)
+	if err != nil {
+		return fmt.Errorf("Error al obtener el listado de los programas: %v", err)
+	}
+	preprocesamiento.archivosZip = archivosZip
+	imprimirZipOmitidos(omitidos, formatoRutas)
+	for _, ruta := range listado {
+		if filepath.Join(directorioActual, ruta) != parametros.rutaRobustez {
+			corpus = append(corpus, ruta)
+		}
+	}
+
+	nombreArchivo := obtenerRutaRelativa(directorioActual, parametros.rutaRobustez)
+	motores := strings.Split(parametros.motoresRobustez, ",")
+	distancias := make([][]float64, len(motores))
+	distribuciones := make([][]float64, len(motores))
+
+	fmt.Println("Evaluando", len(motores), "motores con", len(ofuscacionesSinteticas), "ofuscaciones de", nombreArchivo)
+	fmt.Println()
+
+	for m, nombre := range motores {
+		motor, parametrosMotor, err := crearMotorRobustez(parametros, strings.TrimSpace(nombre))
+		if err != nil {
+			return err
+		}
+		fmt.Println("Motor de características:", motor.nombre())
+
+		codigoOriginal := caracterizarContenido(nombreArchivo, original, motor, preprocesamiento)
+		for k := range ofuscacionesSinteticas {
+			ofuscado := caracterizarContenido(nombreArchivo, versiones[k], motor, preprocesamiento)
+			distancias[m] = append(distancias[m], motor.distancia(codigoOriginal, ofuscado))
+		}
+
+		distribuciones[m] = obtenerDistribucionCorpus(corpus, motor, parametrosMotor, preprocesamiento, formatoRutas)
+	}
+
+	imprimirRobustez(nombreArchivo, motores, cambios, distancias, distribuciones)
+
+	return nil
+}
+
+/*
+ * Función para imprimir la robustez de los motores
+ * param: nombre del archivo, nombres de los motores, cambios de cada ofuscación, distancia del original a cada
+ *        versión ofuscada por motor y distribución de las distancias del corpus por motor (nil sin corpus)
+ */
+func imprimirRobustez(nombreArchivo string, motores []string, cambios []int, distancias [][]float64, distribuciones [][]float64) {
+	conCorpus := distribuciones[0] != nil
+
+	fmt.Println()
+	fmt.Println("ROBUSTEZ DE LOS MOTORES ANTE OFUSCACIONES SINTÉTICAS DE", nombreArchivo)
+	fmt.Println()
+	if conCorpus {
+		fmt.Println("Distancia del original a cada versión ofuscada y su percentil entre los", len(distribuciones[0]), "pares del corpus:")
+	} else {
+		fmt.Println("Distancia del original a cada versión ofuscada:")
+	}
+	fmt.Println()
+
+	fmt.Printf("\t%-12s %-34s", "OFUSCACIÓN", "CAMBIOS")
+	for _, motor := range motores {
+		fmt.Printf(" %18s", strings.ToUpper(strings.TrimSpace(motor)))
+	}
+	fmt.Println()
+
+	for k, ofuscacion := range ofuscacionesSinteticas {
+		fmt.Printf("\t%-12s %-34s", ofuscacion.nombre, fmt.Sprint(cambios[k], " ", ofuscacion.descripcion))
+		for m := range motores {
+			fmt.Printf(" %18s", fmt.Sprintf("%.2f", distancias[m][k])+anotarPercentil(distribuciones[m], distancias[m][k]))
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+
+	if !conCorpus {
+		fmt.Println("Sin otros archivos de la extensión en el directorio las distancias de motores distintos no son comparables;")
+		fmt.Println("ejecute el comando en el directorio de la tarea (o con --dir) para obtener sus percentiles.")
+		fmt.Println()
+		return
+	}
+
+	mejor, mejorPromedio := 0, 0.0
+	for m := range motores {
+		promedio := 0.0
+		for _, distancia := range distancias[m] {
+			promedio += calcularPercentil(distribuciones[m], distancia)
+		}
+		promedio /= float64(len(distancias[m]))
+
+		if m == 0 || promedio < mejorPromedio {
+			mejor, mejorPromedio = m, promedio
+		}
+	}
+
+	fmt.Printf("Motor más robusto para esta tarea: %s (percentil promedio %.1f)\n\n", strings.TrimSpace(motores[mejor]), mejorPromedio)
+}
@@ -0,0 +1,84 @@
+/*
+ * Formato de las rutas de los archivos en los reportes.
+ *
+ * Las rutas se obtienen relativas al directorio base con filepath.Rel (en lugar de reemplazar el directorio base
+ * dentro de la ruta) y se pueden presentar de tres formas:
+ * - relativas al directorio base, por ejemplo "./E1/main.go" (--paths=relative, por defecto)
+ * - solamente el nombre del archivo, por ejemplo "main.go" (--paths=basename)
+ * - con una etiqueta del directorio base como prefijo, por ejemplo "tarea1/E1/main.go" (--paths=label)
+ * Con --students el directorio de cada estudiante se presenta con su nombre y sección (ver estudiantes.go).
+ */
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Estructura con la forma de presentar las rutas de los archivos
+// - formato ("relative", "basename" o "label")
+// - etiqueta del directorio base (solo para el formato "label")
+// - lista de estudiantes con la que se presentan sus directorios (nil si no se usa)
+type FormatoRutas struct {
+	formato  string
+	etiqueta string
+	lista    *ListaEstudiantes
+}
+
+/*
+ * Función para crear el formato de las rutas indicado por el usuario
+ * param: parámetros de ejecución y directorio base
+ * return: el formato de las rutas o un error si el formato no es soportado o la lista de estudiantes no es válida
+ */
+func crearFormatoRutas(parametros Parametros, directorioActual string) (FormatoRutas, error) {
+	formatoRutas := FormatoRutas{formato: parametros.formatoRutas, etiqueta: parametros.etiquetaRaiz}
+
+	if formatoRutas.formato != "relative" && formatoRutas.formato != "basename" && formatoRutas.formato != "label" {
+		return formatoRutas, fmt.Errorf("Formato de rutas \"%s\" no soportado (relative | basename | label)", formatoRutas.formato)
+	}
+
+	// Por defecto la etiqueta es el nombre del directorio base
+	if formatoRutas.etiqueta == "" {
+		formatoRutas.etiqueta = filepath.Base(directorioActual)
+	}
+
+	lista, err := leerListaEstudiantes(parametros)
+	if err != nil {
+		return formatoRutas, err
+	}
+	formatoRutas.lista = lista
+
+	return formatoRutas, nil
+}
+
+/*
+ * Función para obtener la ruta de un archivo relativa al directorio base
+ * param: directorio base y ruta completa del archivo
+ * return: ruta relativa que inicia con "./" (o la ruta original si no se puede obtener)
+ */
+func obtenerRutaRelativa(directorioActual string, path string) string {
+	relativo, err := filepath.Rel(directorioActual, path)
+
+	if err != nil {
+		return path
+	}
+
+	return "./" + filepath.ToSlash(relativo)
+}
+
+/*
+ * Función para presentar la ruta relativa de un archivo en los reportes
+ * param: ruta relativa del archivo (iniciando con "./")
+ * return: la ruta en el formato indicado
+ */
+func (formatoRutas FormatoRutas) formatear(ruta string) string {
+	switch formatoRutas.formato {
+	case "basename":
+		return filepath.Base(ruta)
+	case "label":
+		return formatoRutas.etiqueta + "/" + filepath.ToSlash(filepath.Clean(formatoRutas.lista.presentarRuta(ruta)))
+	}
+
+	return formatoRutas.lista.presentarRuta(ruta)
+}
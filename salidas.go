@@ -0,0 +1,326 @@
+/*
+ * Destinos de salida del resultado JSON (--output, se puede repetir).
+ *
+ * Cada destino (output sink) recibe el mismo resultado JSON del análisis, de modo que una instalación
+ * automática puede enviar los reportes a donde los necesite sin scripts adicionales:
+ * - "-" o "console"      salida estándar (los mensajes y los errores se escriben entonces en la salida de errores
+ *                        y no se imprimen los reportes en consola, para no mezclarlos con el JSON)
+ * - "file:ruta" o ruta   archivo local
+ * - "http://..."         petición POST con el JSON (también https://)
+ * - "s3://bucket/clave"  objeto de S3 (o compatible), con las credenciales de las variables de ambiente
+ *                        AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY y AWS_SESSION_TOKEN, la región de AWS_REGION
+ *                        y AWS_ENDPOINT_URL para otro servicio compatible (por ejemplo MinIO)
+ * Los envíos HTTP y S3 tienen un tiempo máximo, de modo que un servidor que no responde no detiene el programa.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tiempo máximo de cada envío a un destino HTTP o S3
+const TIEMPO_ENVIO_SALIDA = 2 * time.Minute
+
+// Cliente HTTP de los destinos de salida
+var clienteSalidas = &http.Client{Timeout: TIEMPO_ENVIO_SALIDA}
+
+// Salida estándar original, la del destino "-" aunque los mensajes se desvíen a la salida de errores
+var salidaEstandar = os.Stdout
+
+// Interfaz de un destino de salida del resultado
+type DestinoSalida interface {
+	nombre() string
+	enviar(contenido []byte) error
+}
+
+// Lista de valores de una opción que se puede repetir (por ejemplo --output)
+type ListaOpciones []string
+
+func (lista *ListaOpciones) String() string {
+	return strings.Join(*lista, ",")
+}
+
+func (lista *ListaOpciones) Set(valor string) error {
+	*lista = append(*lista, valor)
+	return nil
+}
+
+// Destino de salida: salida estándar
+type DestinoConsola struct{}
+
+func (destino DestinoConsola) nombre() string {
+	return "la salida estándar"
+}
+
+func (destino DestinoConsola) enviar(contenido []byte) error {
+	_, err := salidaEstandar.Write(contenido)
+	return err
+}
+
+// Destino de salida: archivo local
+type DestinoArchivo struct {
+	ruta string
+}
+
+func (destino DestinoArchivo) nombre() string {
+	return "el archivo \"" + destino.ruta + "\""
+}
+
+func (destino DestinoArchivo) enviar(contenido []byte) error {
+	return ioutil.WriteFile(destino.ruta, contenido, 0644)
+}
+
+// Destino de salida: petición HTTP POST
+type DestinoHTTP struct {
+	direccion string
+}
+
+func (destino DestinoHTTP) nombre() string {
+	return destino.direccion
+}
+
+func (destino DestinoHTTP) enviar(contenido []byte) error {
+	respuesta, err := clienteSalidas.Post(destino.direccion, "application/json", bytes.NewReader(contenido))
+	if err != nil {
+		return err
+	}
+	defer respuesta.Body.Close()
+
+	if respuesta.StatusCode < 200 || respuesta.StatusCode > 299 {
+		return fmt.Errorf("%s respondió \"%s\"", destino.direccion, respuesta.Status)
+	}
+
+	return nil
+}
+
+// Destino de salida: objeto de S3
+type DestinoS3 struct {
+	bucket string
+	clave  string
+}
+
+func (destino DestinoS3) nombre() string {
+	return "s3://" + destino.bucket + "/" + destino.clave
+}
+
+func (destino DestinoS3) enviar(contenido []byte) error {
+	acceso, secreto := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if acceso == "" || secreto == "" {
+		return fmt.Errorf("Para enviar a S3 se requieren las variables de ambiente AWS_ACCESS_KEY_ID y AWS_SECRET_ACCESS_KEY")
+	}
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	// Con otro servicio compatible se usa el bucket en la ruta, en AWS se usa en el nombre del servidor
+	direccion := "https://" + destino.bucket + ".s3." + region + ".amazonaws.com/" + escaparRutaS3(destino.clave)
+	if servicio := os.Getenv("AWS_ENDPOINT_URL"); servicio != "" {
+		direccion = strings.TrimRight(servicio, "/") + "/" + escaparRutaS3(destino.bucket) + "/" + escaparRutaS3(destino.clave)
+	}
+
+	peticion, err := http.NewRequest(http.MethodPut, direccion, bytes.NewReader(contenido))
+	if err != nil {
+		return err
+	}
+	peticion.Header.Set("Content-Type", "application/json")
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		peticion.Header.Set("X-Amz-Security-Token", token)
+	}
+	firmarPeticionS3(peticion, contenido, acceso, secreto, region, time.Now())
+
+	respuesta, err := clienteSalidas.Do(peticion)
+	if err != nil {
+		return err
+	}
+	defer respuesta.Body.Close()
+
+	if respuesta.StatusCode != http.StatusOK {
+		detalle, _ := ioutil.ReadAll(respuesta.Body)
+		return fmt.Errorf("S3 respondió \"%s\": %s", respuesta.Status, strings.TrimSpace(string(detalle)))
+	}
+
+	return nil
+}
+
+/*
+ * Función para escapar una ruta de S3 (cada segmento, conservando las barras) como lo exige la firma
+ * param: ruta sin escapar
+ * return: ruta escapada
+ */
+func escaparRutaS3(ruta string) string {
+	var escapada strings.Builder
+
+	for _, caracter := range []byte(ruta) {
+		if (caracter >= 'A' && caracter <= 'Z') || (caracter >= 'a' && caracter <= 'z') || (caracter >= '0' && caracter <= '9') ||
+			caracter == '-' || caracter == '_' || caracter == '.' || caracter == '~' || caracter == '/' {
+			escapada.WriteByte(caracter)
+		} else {
+			fmt.Fprintf(&escapada, "%%%02X", caracter)
+		}
+	}
+
+	return escapada.String()
+}
+
+/*
+ * Función para calcular el HMAC-SHA256 de un mensaje
+ * param: clave y mensaje
+ * return: HMAC del mensaje
+ */
+func calcularHMAC(clave []byte, mensaje string) []byte {
+	firma := hmac.New(sha256.New, clave)
+	firma.Write([]byte(mensaje))
+	return firma.Sum(nil)
+}
+
+/*
+ * Función para firmar una petición a S3 (AWS Signature Version 4), se firman todos sus encabezados
+ * param: petición, contenido, clave de acceso, clave secreta, región y fecha de la petición
+ */
+func firmarPeticionS3(peticion *http.Request, contenido []byte, acceso string, secreto string, region string, fecha time.Time) {
+	fecha = fecha.UTC()
+	dia := fecha.Format("20060102")
+	resumen := sha256.Sum256(contenido)
+
+	peticion.Header.Set("X-Amz-Date", fecha.Format("20060102T150405Z"))
+	peticion.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(resumen[:]))
+
+	encabezados := map[string]string{"host": peticion.URL.Host}
+	for nombre, valores := range peticion.Header {
+		encabezados[strings.ToLower(nombre)] = strings.TrimSpace(strings.Join(valores, ","))
+	}
+
+	var nombres []string
+	for nombre := range encabezados {
+		nombres = append(nombres, nombre)
+	}
+	sort.Strings(nombres)
+
+	var canonicos strings.Builder
+	for _, nombre := range nombres {
+		canonicos.WriteString(nombre + ":" + encabezados[nombre] + "\n")
+	}
+	firmados := strings.Join(nombres, ";")
+
+	peticionCanonica := strings.Join([]string{
+		peticion.Method,
+		peticion.URL.EscapedPath(),
+		peticion.URL.RawQuery,
+		canonicos.String(),
+		firmados,
+		hex.EncodeToString(resumen[:]),
+	}, "\n")
+	resumenPeticion := sha256.Sum256([]byte(peticionCanonica))
+
+	alcance := dia + "/" + region + "/s3/aws4_request"
+	textoFirmar := "AWS4-HMAC-SHA256\n" + fecha.Format("20060102T150405Z") + "\n" + alcance + "\n" + hex.EncodeToString(resumenPeticion[:])
+
+	clave := calcularHMAC([]byte("AWS4"+secreto), dia)
+	clave = calcularHMAC(clave, region)
+	clave = calcularHMAC(clave, "s3")
+	clave = calcularHMAC(clave, "aws4_request")
+
+	peticion.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+acceso+"/"+alcance+
+		", SignedHeaders="+firmados+", Signature="+hex.EncodeToString(calcularHMAC(clave, textoFirmar)))
+}
+
+/*
+ * Función para crear los destinos de salida indicados por el usuario
+ * param: especificaciones de los destinos (ver el encabezado del archivo)
+ * return: los destinos o un error si alguno no es válido
+ */
+func crearDestinosSalida(especificaciones []string) ([]DestinoSalida, error) {
+	var destinos []DestinoSalida
+
+	for _, especificacion := range especificaciones {
+		switch {
+		case especificacion == "-" || especificacion == "console":
+			destinos = append(destinos, DestinoConsola{})
+		case strings.HasPrefix(especificacion, "http://") || strings.HasPrefix(especificacion, "https://"):
+			if _, err := url.Parse(especificacion); err != nil {
+				return destinos, fmt.Errorf("Destino de salida (--output) \"%s\" no válido: %v", especificacion, err)
+			}
+			destinos = append(destinos, DestinoHTTP{direccion: especificacion})
+		case strings.HasPrefix(especificacion, "s3://"):
+			partes := strings.SplitN(strings.TrimPrefix(especificacion, "s3://"), "/", 2)
+			if len(partes) != 2 || partes[0] == "" || partes[1] == "" {
+				return destinos, fmt.Errorf("Destino de salida (--output) \"%s\" no válido, use s3://bucket/clave", especificacion)
+			}
+			destinos = append(destinos, DestinoS3{bucket: partes[0], clave: partes[1]})
+		case especificacion == "" || especificacion == "file:":
+			return destinos, fmt.Errorf("Destino de salida (--output) vacío")
+		default:
+			destinos = append(destinos, DestinoArchivo{ruta: strings.TrimPrefix(especificacion, "file:")})
+		}
+	}
+
+	return destinos, nil
+}
+
+/*
+ * Función para reservar la salida estándar para el resultado JSON cuando es uno de los destinos de salida: los
+ * mensajes y los errores se escriben en la salida de errores y no se imprimen los reportes en consola
+ * param: parámetros de ejecución (se desactivan los reportes en consola si la salida estándar es un destino)
+ */
+func reservarSalidaEstandar(parametros *Parametros) {
+	for _, especificacion := range parametros.salidas {
+		if especificacion == "-" || especificacion == "console" {
+			os.Stdout = os.Stderr
+			parametros.consola = false
+			return
+		}
+	}
+}
+
+/*
+ * Función para crear el error de los envíos y los ganchos que fallaron al publicar el resultado
+ * param: descripción de cada falla
+ * return: un error con todas las fallas o nil si no hubo fallas
+ */
+func crearErrorPublicacion(fallas []string) error {
+	if len(fallas) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s", strings.Join(fallas, "; "))
+}
+
+/*
+ * Función para enviar el resultado JSON a todos los destinos de salida.
+ * Un destino que falla no impide el envío a los demás.
+ * param: resultado del análisis y destinos de salida
+ * return: cantidad de destinos que fallaron
+ */
+func enviarResultado(resultado ResultadoAnalisisJSON, destinos []DestinoSalida) int {
+	fallidos := 0
+
+	contenido, err := json.MarshalIndent(resultado, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	contenido = append(contenido, '\n')
+
+	for _, destino := range destinos {
+		if err = destino.enviar(contenido); err != nil {
+			fmt.Fprintln(os.Stderr, "Error al enviar el resultado a "+destino.nombre()+":", err)
+			fallidos++
+		}
+	}
+
+	return fallidos
+}
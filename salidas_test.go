@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnviarResultadoDestinosFallidos(t *testing.T) {
+	rechaza := httptest.NewServer(http.HandlerFunc(func(respuesta http.ResponseWriter, peticion *http.Request) {
+		http.Error(respuesta, "error", http.StatusInternalServerError)
+	}))
+	defer rechaza.Close()
+
+	responder := make(chan struct{})
+	noResponde := httptest.NewServer(http.HandlerFunc(func(respuesta http.ResponseWriter, peticion *http.Request) {
+		<-responder
+	}))
+	defer noResponde.Close()
+	defer close(responder)
+
+	anterior := clienteSalidas.Timeout
+	clienteSalidas.Timeout = 100 * time.Millisecond
+	defer func() { clienteSalidas.Timeout = anterior }()
+
+	archivo := filepath.Join(t.TempDir(), "resultado.json")
+	destinos, err := crearDestinosSalida([]string{rechaza.URL, noResponde.URL, "file:" + archivo})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Los destinos que fallan o no responden no impiden el envío a los demás
+	if fallidos := enviarResultado(ResultadoAnalisisJSON{Version: VERSION_RESULTADO}, destinos); fallidos != 2 {
+		t.Errorf("destinos fallidos = %d, se esperaban 2", fallidos)
+	}
+	if _, err := os.Stat(archivo); err != nil {
+		t.Errorf("resultado sin enviar al archivo: %v", err)
+	}
+}
+
+func TestReservarSalidaEstandar(t *testing.T) {
+	anterior := os.Stdout
+	defer func() { os.Stdout = anterior }()
+
+	parametros := crearParametrosPrueba()
+	parametros.salidas = ListaOpciones{"file:resultado.json"}
+	reservarSalidaEstandar(&parametros)
+	if os.Stdout != anterior || !parametros.consola {
+		t.Error("salida estándar reservada sin el destino \"-\"")
+	}
+
+	parametros.salidas = ListaOpciones{"file:resultado.json", "-"}
+	reservarSalidaEstandar(&parametros)
+	if os.Stdout != os.Stderr || parametros.consola {
+		t.Error("con el destino \"-\" los mensajes y los reportes en consola siguen en la salida estándar")
+	}
+}
+
+func TestAnalizarListadoPublicacionFallida(t *testing.T) {
+	corpus := map[string]string{
+		"ana/main.go": "package main\n\nfunc main() { println(\"hola mundo\") }\n",
+		"eva/main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"otro\")\n}\n",
+	}
+	rechaza := httptest.NewServer(http.HandlerFunc(func(respuesta http.ResponseWriter, peticion *http.Request) {
+		http.Error(respuesta, "error", http.StatusInternalServerError)
+	}))
+	defer rechaza.Close()
+
+	casos := []struct {
+		nombre   string
+		ganchos  []string
+		destinos []DestinoSalida
+		error    string
+	}{
+		{"gancho correcto", []string{"true"}, nil, ""},
+		{"gancho fallido", []string{"true", "false"}, nil, "Fallaron 1 ganchos (--hook)"},
+		{"destino y gancho fallidos", []string{"false"}, []DestinoSalida{DestinoHTTP{direccion: rechaza.URL}}, "No se pudo enviar el resultado a 1 destinos de salida (--output); Fallaron 1 ganchos (--hook)"},
+	}
+
+	for _, caso := range casos {
+		parametros := crearParametrosPrueba()
+		parametros.ganchos = caso.ganchos
+
+		resultado, _, err := analizarCorpusDestinosPrueba(t, parametros, corpus, caso.destinos)
+		if (err == nil && caso.error != "") || (err != nil && err.Error() != caso.error) {
+			t.Errorf("%s: error = %v, se esperaba %q", caso.nombre, err, caso.error)
+		}
+		if len(resultado.archivos) != 2 {
+			t.Errorf("%s: el análisis no terminó, %d archivos", caso.nombre, len(resultado.archivos))
+		}
+	}
+}
@@ -0,0 +1,391 @@
+/*
+ * Modo servidor (--serve).
+ *
+ * SASC se ejecuta como un servicio HTTP que recibe las entregas como un flujo tar y responde con el mismo
+ * resultado JSON del modo de entrada estándar:
+ * - POST /analyze   cuerpo: flujo tar de las entregas, respuesta: resultado JSON (análisis inmediato)
+ * - POST /jobs      cuerpo: flujo tar de las entregas, respuesta: trabajo encolado (análisis en la cola)
+ * - GET  /jobs      trabajos retenidos con su estado
+ * - GET  /jobs/{id} estado del trabajo y su resultado cuando termina
+ * - POST /v1/similarity cuerpo: JSON con dos fragmentos, respuesta: distancia con cada motor (ver similitud.go)
+ * - GET  /metrics   métricas del servicio en el formato de texto de Prometheus
+ * - /lti/...        lanzamiento como herramienta externa LTI 1.3 si se indica --lti-config (ver lti.go)
+ * La distancia máxima se puede cambiar por petición con ?max-distance=
+ * Las entregas de una petición no pueden superar --max-body-size; una petición mayor se responde con 413 sin leer el
+ * resto de su cuerpo.
+ * Los trabajos pueden indicar su tarea (?assignment=) y su sección o sede (?section=), con los que el comando dashboard
+ * compara las entregas de la misma tarea entre secciones (ver tablero.go).
+ * Si se indica un archivo de autorización (--auth-file), cada análisis pertenece a un curso (?course=)
+ * y solamente quienes tienen acceso al curso pueden enviarlo o consultarlo (ver autenticacion.go).
+ * Los análisis y las comparaciones se pueden limitar por cliente y por curso (--rate-limit y --course-quota, ver
+ * limites_servidor.go).
+ * Con --audit-log se registra quién ejecuta cada análisis y quién consulta cada trabajo (ver auditoria.go).
+ * Con --self-check el servidor es restringido para los estudiantes y solo atiende la autoverificación (ver autoverificacion.go).
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Función para obtener los parámetros de una petición (los del servidor con la distancia máxima de la petición)
+ * param: petición y parámetros de ejecución del servidor
+ * return: parámetros de la petición o un error si la distancia máxima no es válida
+ */
+func obtenerParametrosPeticion(peticion *http.Request, parametros Parametros) (Parametros, error) {
+	if valor := peticion.URL.Query().Get("max-distance"); valor != "" {
+		criterio, err := obtenerCriterioValor(valor)
+		if err != nil {
+			return parametros, err
+		}
+		parametros.criterioDistancia = criterio
+	}
+
+	if valor := peticion.URL.Query().Get("threshold"); valor != "" {
+		criterio, err := obtenerCriterioDistancia(valor)
+		if err != nil {
+			return parametros, err
+		}
+		parametros.criterioDistancia = criterio
+	}
+
+	return parametros, nil
+}
+
+/*
+ * Función para responder con un valor en JSON
+ * param: respuesta, código de estado y valor a responder
+ */
+func responderJSON(respuesta http.ResponseWriter, estado int, valor interface{}) {
+	respuesta.Header().Set("Content-Type", "application/json")
+	respuesta.WriteHeader(estado)
+	json.NewEncoder(respuesta).Encode(valor)
+}
+
+// Estructura del cuerpo de una petición con un tamaño máximo (http.MaxBytesReader)
+// - cuerpo limitado y tamaño máximo en bytes
+// - bytes leídos y si la petición superó el tamaño máximo
+type CuerpoLimitado struct {
+	cuerpo   io.ReadCloser
+	maximo   int64
+	leidos   int64
+	excedido bool
+}
+
+/*
+ * Función para limitar el tamaño del cuerpo de una petición
+ * param: respuesta, petición (su cuerpo se reemplaza por el cuerpo limitado) y tamaño máximo en bytes
+ * return: el cuerpo limitado, para saber si la petición superó el tamaño máximo
+ */
+func limitarCuerpo(respuesta http.ResponseWriter, peticion *http.Request, maximo int64) *CuerpoLimitado {
+	cuerpo := &CuerpoLimitado{cuerpo: http.MaxBytesReader(respuesta, peticion.Body, maximo), maximo: maximo}
+	peticion.Body = cuerpo
+
+	return cuerpo
+}
+
+/*
+ * Función para leer el cuerpo limitado de una petición
+ * param: arreglo en donde se leen los bytes
+ * return: cantidad de bytes leídos y un error si no se puede leer o se supera el tamaño máximo
+ */
+func (cuerpo *CuerpoLimitado) Read(bytes []byte) (int, error) {
+	leidos, err := cuerpo.cuerpo.Read(bytes)
+	cuerpo.leidos += int64(leidos)
+	if err != nil && err != io.EOF && cuerpo.leidos >= cuerpo.maximo {
+		cuerpo.excedido = true
+	}
+
+	return leidos, err
+}
+
+/*
+ * Función para cerrar el cuerpo limitado de una petición
+ * return: un error si no se puede cerrar
+ */
+func (cuerpo *CuerpoLimitado) Close() error {
+	return cuerpo.cuerpo.Close()
+}
+
+/*
+ * Función para obtener el código de estado de una petición con entregas que no se pudieron analizar o encolar
+ * param: cuerpo limitado de la petición
+ * return: 413 si la petición superó el tamaño máximo, si no 400
+ */
+func (cuerpo *CuerpoLimitado) obtenerEstadoError() int {
+	if cuerpo.excedido {
+		return http.StatusRequestEntityTooLarge
+	}
+
+	return http.StatusBadRequest
+}
+
+/*
+ * Función para responder a una petición de un trabajo cuando la cola de trabajos está llena
+ * param: respuesta
+ */
+func responderColaLlena(respuesta http.ResponseWriter) {
+	respuesta.Header().Set("Retry-After", strconv.Itoa(ESPERA_COLA_LLENA))
+	http.Error(respuesta, errorColaLlena.Error(), http.StatusServiceUnavailable)
+}
+
+/*
+ * Función para obtener el mensaje de error de una petición con entregas que no se pudieron analizar o encolar
+ * param: error del análisis o de la cola
+ * return: el mensaje del error, o el del tamaño máximo si la petición lo superó
+ */
+func (cuerpo *CuerpoLimitado) obtenerMensajeError(err error) string {
+	if cuerpo.excedido {
+		return fmt.Sprintf("Las entregas superan el tamaño máximo de la petición (--max-body-size, %d MB)", cuerpo.maximo/(1024*1024))
+	}
+
+	return err.Error()
+}
+
+/*
+ * Función para iniciar el servidor
+ * param: parámetros de ejecución, el motor a emplear, el preprocesamiento del contenido y el formato de las rutas
+ * return: un error si el servidor no se puede iniciar o se detiene
+ */
+func iniciarServidor(parametros Parametros, motor Motor, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas) error {
+	metricas := crearMetricas()
+
+	autorizacion, err := crearAutorizacion(parametros.archivoAutorizacion, parametros.encabezadoUsuario, parametros.proxiesUsuario)
+	if err != nil {
+		return err
+	}
+
+	limites, err := crearLimitesServidor(parametros.limitePeticiones, parametros.cuotaCurso, metricas)
+	if err != nil {
+		return err
+	}
+
+	auditoria, err := abrirAuditoria(parametros.archivoAuditoria)
+	if err != nil {
+		return err
+	}
+
+	if parametros.corpusVerificacion != "" {
+		return iniciarAutoverificacion(parametros, preprocesamiento, formatoRutas, autorizacion, limites, auditoria, metricas)
+	}
+
+	rutas, err := crearRutasServidor(parametros, motor, preprocesamiento, formatoRutas, autorizacion, limites, auditoria, metricas)
+	if err != nil {
+		return err
+	}
+
+	return http.ListenAndServe(parametros.direccionServidor, rutas)
+}
+
+/*
+ * Función para crear las rutas del servidor
+ * param: parámetros de ejecución, el motor a emplear, el preprocesamiento del contenido, el formato de las rutas, la
+ *        autorización, los límites de las peticiones, el registro de auditoría (nil si no se audita) y las métricas
+ * return: las rutas del servidor o un error si su configuración no es válida
+ */
+func crearRutasServidor(parametros Parametros, motor Motor, preprocesamiento Preprocesamiento, formatoRutas FormatoRutas, autorizacion *Autorizacion, limites *LimitesServidor, auditoria *Auditoria, metricas *Metricas) (*http.ServeMux, error) {
+	if parametros.tamanoMaximoPeticion < 1 {
+		return nil, fmt.Errorf("El tamaño máximo de las peticiones (--max-body-size) debe ser al menos 1 MB")
+	}
+	tamanoMaximo := int64(parametros.tamanoMaximoPeticion) * 1024 * 1024
+
+	// Registro de auditoría de una petición con la identidad de quien la hace (y el motor si es un análisis)
+	// (si no se puede escribir, se responde 500 y retorna falso)
+	auditar := func(respuesta http.ResponseWriter, peticion *http.Request, accion string, curso string, trabajo string, estado int) bool {
+		registro := RegistroAuditoriaJSON{Accion: accion, Identidad: identificarCliente(peticion, autorizacion), Curso: curso, Trabajo: trabajo, Estado: estado}
+		if accion == ACCION_ANALIZAR || accion == ACCION_ENCOLAR {
+			registro.Parametros = map[string]string{"motor": motor.nombre()}
+		}
+		return auditoria.registrarPeticion(respuesta, peticion, registro)
+	}
+
+	// Curso de una petición que envía entregas, obligatorio si el servidor requiere autenticación, y sus límites
+	obtenerCurso := func(respuesta http.ResponseWriter, peticion *http.Request) (string, bool) {
+		curso, autorizado := autorizacion.autorizarCurso(respuesta, peticion, true)
+		if !autorizado || !limites.permitir(respuesta, peticion, autorizacion, curso) {
+			return curso, false
+		}
+		return curso, true
+	}
+
+	cifrado, err := crearCifrado(parametros.archivoCifrado)
+	if err != nil {
+		return nil, err
+	}
+
+	almacen, err := crearAlmacenTrabajos(parametros.directorioDatos, parametros.retencionFuentes, parametros.retencionTrabajos, cifrado)
+	if err != nil {
+		return nil, err
+	}
+
+	cola, err := crearColaTrabajos(parametros.maximoTrabajos, parametros.maximoEnCola, parametros.retencionTrabajos, almacen)
+	if err != nil {
+		return nil, err
+	}
+
+	almacen.purgarPeriodicamente()
+
+	// Análisis de un flujo tar con los parámetros de la petición, registrando sus métricas
+	analizar := func(parametrosPeticion Parametros) func(io.Reader) (ResultadoAnalisisJSON, error) {
+		return func(lector io.Reader) (ResultadoAnalisisJSON, error) {
+			inicio := time.Now()
+			resultado, err := analizarTar(lector, parametrosPeticion, motor, preprocesamiento, formatoRutas)
+			if err != nil {
+				metricas.registrarFallo()
+				return resultado, err
+			}
+			metricas.registrarAnalisis(time.Since(inicio).Seconds(), len(resultado.Archivos)+len(resultado.Descartados), contarParesMarcados(resultado))
+
+			return resultado, nil
+		}
+	}
+
+	rutas := http.NewServeMux()
+
+	rutas.HandleFunc("/analyze", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		if peticion.Method != http.MethodPost {
+			http.Error(respuesta, "Se debe usar POST con un flujo tar de las entregas", http.StatusMethodNotAllowed)
+			return
+		}
+
+		curso, autorizado := obtenerCurso(respuesta, peticion)
+		if !autorizado {
+			return
+		}
+
+		parametrosPeticion, err := obtenerParametrosPeticion(peticion, parametros)
+		if err != nil {
+			metricas.registrarFallo()
+			if !auditar(respuesta, peticion, ACCION_ANALIZAR, curso, "", http.StatusBadRequest) {
+				return
+			}
+			http.Error(respuesta, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		cuerpo := limitarCuerpo(respuesta, peticion, tamanoMaximo)
+		resultado, err := analizar(parametrosPeticion)(cuerpo)
+		if err != nil {
+			if !auditar(respuesta, peticion, ACCION_ANALIZAR, curso, "", cuerpo.obtenerEstadoError()) {
+				return
+			}
+			http.Error(respuesta, cuerpo.obtenerMensajeError(err), cuerpo.obtenerEstadoError())
+			return
+		}
+		if !auditar(respuesta, peticion, ACCION_ANALIZAR, curso, "", http.StatusOK) {
+			return
+		}
+
+		responderJSON(respuesta, http.StatusOK, resultado)
+	})
+
+	rutas.HandleFunc("/jobs", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		switch peticion.Method {
+		case http.MethodGet:
+			cursos, autorizado := autorizacion.autorizar(respuesta, peticion, "")
+			if !autorizado {
+				return
+			}
+
+			if !auditar(respuesta, peticion, ACCION_LISTAR_TRABAJOS, "", "", http.StatusOK) {
+				return
+			}
+			responderJSON(respuesta, http.StatusOK, cola.listar(cursos))
+
+		case http.MethodPost:
+			curso, autorizado := obtenerCurso(respuesta, peticion)
+			if !autorizado {
+				return
+			}
+
+			parametrosPeticion, err := obtenerParametrosPeticion(peticion, parametros)
+			if err != nil {
+				metricas.registrarFallo()
+				if !auditar(respuesta, peticion, ACCION_ENCOLAR, curso, "", http.StatusBadRequest) {
+					return
+				}
+				http.Error(respuesta, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			consulta := peticion.URL.Query()
+			cuerpo := limitarCuerpo(respuesta, peticion, tamanoMaximo)
+			trabajo, err := cola.agregar(curso, consulta.Get("assignment"), consulta.Get("section"), cuerpo, analizar(parametrosPeticion))
+			if err == errorColaLlena {
+				if !auditar(respuesta, peticion, ACCION_ENCOLAR, curso, "", http.StatusServiceUnavailable) {
+					return
+				}
+				responderColaLlena(respuesta)
+				return
+			}
+			if err != nil {
+				if !auditar(respuesta, peticion, ACCION_ENCOLAR, curso, "", cuerpo.obtenerEstadoError()) {
+					return
+				}
+				http.Error(respuesta, cuerpo.obtenerMensajeError(err), cuerpo.obtenerEstadoError())
+				return
+			}
+			if !auditar(respuesta, peticion, ACCION_ENCOLAR, curso, trabajo.Identificador, http.StatusAccepted) {
+				return
+			}
+
+			responderJSON(respuesta, http.StatusAccepted, trabajo)
+
+		default:
+			http.Error(respuesta, "Se debe usar GET o POST", http.StatusMethodNotAllowed)
+		}
+	})
+
+	rutas.HandleFunc("/jobs/", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		cursos, autorizado := autorizacion.autorizar(respuesta, peticion, "")
+		if !autorizado {
+			return
+		}
+
+		// Los trabajos de otros cursos se reportan como no encontrados para no revelar su existencia
+		identificador := strings.TrimPrefix(peticion.URL.Path, "/jobs/")
+		trabajo, existe := cola.consultar(identificador)
+		if !existe || !permiteCurso(cursos, trabajo.Curso) {
+			if !auditar(respuesta, peticion, ACCION_CONSULTAR_TRABAJO, "", identificador, http.StatusNotFound) {
+				return
+			}
+			http.Error(respuesta, "Trabajo no encontrado", http.StatusNotFound)
+			return
+		}
+		if !auditar(respuesta, peticion, ACCION_CONSULTAR_TRABAJO, trabajo.Curso, identificador, http.StatusOK) {
+			return
+		}
+
+		responderJSON(respuesta, http.StatusOK, trabajo)
+	})
+
+	herramientaLTI, err := crearHerramientaLTI(parametros.configuracionLTI)
+	if err != nil {
+		return nil, err
+	}
+	if herramientaLTI != nil {
+		herramientaLTI.registrarRutas(rutas, cola, analizar(parametros), auditoria, tamanoMaximo)
+	}
+
+	comparador, err := crearComparadorSimilitud(parametros, preprocesamiento)
+	if err != nil {
+		return nil, err
+	}
+	comparador.registrarRuta(rutas, autorizacion, limites, auditoria, metricas)
+
+	rutas.HandleFunc("/metrics", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		respuesta.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		metricas.escribir(respuesta)
+		cola.escribirMetricas(respuesta)
+	})
+
+	return rutas, nil
+}
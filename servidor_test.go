@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+ * Función para crear un servidor de prueba con las rutas del modo servidor
+ * param: prueba y parámetros de ejecución
+ * return: el servidor (se cierra al terminar la prueba)
+ */
+func crearServidorPrueba(t *testing.T, parametros Parametros) *httptest.Server {
+	t.Helper()
+
+	return crearServidorAuditoriaPrueba(t, parametros, nil)
+}
+
+/*
+ * Función para crear un servidor de prueba que registra las peticiones en un registro de auditoría
+ * param: prueba, parámetros de ejecución y registro de auditoría (nil si no se audita)
+ * return: el servidor (se cierra al terminar la prueba)
+ */
+func crearServidorAuditoriaPrueba(t *testing.T, parametros Parametros, auditoria *Auditoria) *httptest.Server {
+	t.Helper()
+
+	motor, err := crearMotor(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	preprocesamiento, err := crearPreprocesamiento(parametros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatoRutas, err := crearFormatoRutas(parametros, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metricas := crearMetricas()
+	autorizacion, err := crearAutorizacion(parametros.archivoAutorizacion, parametros.encabezadoUsuario, parametros.proxiesUsuario)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limites, err := crearLimitesServidor(parametros.limitePeticiones, parametros.cuotaCurso, metricas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rutas, err := crearRutasServidor(parametros, motor, preprocesamiento, formatoRutas, autorizacion, limites, auditoria, metricas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	servidor := httptest.NewServer(rutas)
+	t.Cleanup(servidor.Close)
+
+	return servidor
+}
+
+/*
+ * Función para crear los parámetros del modo servidor de una prueba
+ * return: los parámetros por defecto con un tamaño máximo de las peticiones de 1 MB
+ */
+func crearParametrosServidorPrueba() Parametros {
+	parametros := crearParametrosPrueba()
+	parametros.maximoTrabajos = 1
+	parametros.tamanoMaximoPeticion = 1
+	parametros.retencionTrabajos = time.Hour
+
+	return parametros
+}
+
+/*
+ * Función para crear el flujo tar de las entregas de una prueba
+ * param: prueba y contenido de cada archivo por su ruta
+ * return: el flujo tar
+ */
+func crearEntregasPrueba(t *testing.T, archivos map[string]string) *bytes.Buffer {
+	t.Helper()
+
+	var lista [][2]string
+	for ruta, contenido := range archivos {
+		lista = append(lista, [2]string{ruta, contenido})
+	}
+
+	return crearTarPrueba(t, lista)
+}
+
+/*
+ * Función para enviar una petición al servidor de prueba
+ * param: prueba, método, URL y cuerpo de la petición (nil si no tiene)
+ * return: código de estado y cuerpo de la respuesta
+ */
+func enviarPeticionPrueba(t *testing.T, metodo string, direccion string, cuerpo io.Reader) (int, string) {
+	t.Helper()
+
+	peticion, err := http.NewRequest(metodo, direccion, cuerpo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	respuesta, err := http.DefaultClient.Do(peticion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer respuesta.Body.Close()
+
+	contenido, err := io.ReadAll(respuesta.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return respuesta.StatusCode, string(contenido)
+}
+
+func TestServidorAnalizar(t *testing.T) {
+	servidor := crearServidorPrueba(t, crearParametrosServidorPrueba())
+
+	estado, cuerpo := enviarPeticionPrueba(t, http.MethodPost, servidor.URL+"/analyze?max-distance=5", crearEntregasPrueba(t, corpusIdenticos))
+	if estado != http.StatusOK {
+		t.Fatalf("POST /analyze = %d %s", estado, cuerpo)
+	}
+
+	var resultado ResultadoAnalisisJSON
+	if err := json.Unmarshal([]byte(cuerpo), &resultado); err != nil {
+		t.Fatal(err)
+	}
+	if len(resultado.Archivos) != 3 || len(resultado.Grupos) != 1 {
+		t.Errorf("resultado = %d archivos y %d grupos, se esperaban 3 y 1", len(resultado.Archivos), len(resultado.Grupos))
+	}
+
+	if estado, _ = enviarPeticionPrueba(t, http.MethodGet, servidor.URL+"/analyze", nil); estado != http.StatusMethodNotAllowed {
+		t.Errorf("GET /analyze = %d, se esperaba %d", estado, http.StatusMethodNotAllowed)
+	}
+	for _, distancia := range []string{"x", "-1"} {
+		if estado, _ = enviarPeticionPrueba(t, http.MethodPost, servidor.URL+"/analyze?max-distance="+distancia, crearEntregasPrueba(t, corpusIdenticos)); estado != http.StatusBadRequest {
+			t.Errorf("POST /analyze con la distancia no válida %q = %d, se esperaba %d", distancia, estado, http.StatusBadRequest)
+		}
+	}
+	if estado, _ = enviarPeticionPrueba(t, http.MethodPost, servidor.URL+"/analyze", strings.NewReader("no es un tar")); estado != http.StatusBadRequest {
+		t.Errorf("POST /analyze con un flujo no válido = %d, se esperaba %d", estado, http.StatusBadRequest)
+	}
+}
+
+func TestServidorTamanoMaximoPeticion(t *testing.T) {
+	servidor := crearServidorPrueba(t, crearParametrosServidorPrueba())
+	grande := map[string]string{"ana/main.go": strings.Repeat("// relleno\n", 200000)}
+
+	for _, ruta := range []string{"/analyze", "/jobs"} {
+		estado, cuerpo := enviarPeticionPrueba(t, http.MethodPost, servidor.URL+ruta, crearEntregasPrueba(t, grande))
+		if estado != http.StatusRequestEntityTooLarge || !strings.Contains(cuerpo, "--max-body-size") {
+			t.Errorf("POST %s de más de 1 MB = %d %s, se esperaba %d", ruta, estado, cuerpo, http.StatusRequestEntityTooLarge)
+		}
+	}
+
+	if _, err := crearRutasServidor(Parametros{}, nil, Preprocesamiento{}, FormatoRutas{}, nil, nil, nil, nil); err == nil {
+		t.Error("se esperaba un error con un tamaño máximo de 0 MB")
+	}
+}
+
+func TestServidorTrabajos(t *testing.T) {
+	servidor := crearServidorPrueba(t, crearParametrosServidorPrueba())
+
+	estado, cuerpo := enviarPeticionPrueba(t, http.MethodPost, servidor.URL+"/jobs?assignment=taller1&section=g1", crearEntregasPrueba(t, corpusIdenticos))
+	if estado != http.StatusAccepted {
+		t.Fatalf("POST /jobs = %d %s", estado, cuerpo)
+	}
+	var trabajo Trabajo
+	if err := json.Unmarshal([]byte(cuerpo), &trabajo); err != nil {
+		t.Fatal(err)
+	}
+
+	for intento := 0; intento < 200 && trabajo.Estado != TRABAJO_TERMINADO && trabajo.Estado != TRABAJO_FALLIDO; intento++ {
+		time.Sleep(10 * time.Millisecond)
+		estado, cuerpo = enviarPeticionPrueba(t, http.MethodGet, servidor.URL+"/jobs/"+trabajo.Identificador, nil)
+		if estado != http.StatusOK {
+			t.Fatalf("GET /jobs/{id} = %d %s", estado, cuerpo)
+		}
+		trabajo = Trabajo{}
+		json.Unmarshal([]byte(cuerpo), &trabajo)
+	}
+	if trabajo.Estado != TRABAJO_TERMINADO || trabajo.Resultado == nil || len(trabajo.Resultado.Archivos) != 3 || trabajo.Tarea != "taller1" {
+		t.Errorf("trabajo = %+v", trabajo)
+	}
+
+	if estado, cuerpo = enviarPeticionPrueba(t, http.MethodGet, servidor.URL+"/jobs", nil); estado != http.StatusOK || !strings.Contains(cuerpo, trabajo.Identificador) {
+		t.Errorf("GET /jobs = %d %s", estado, cuerpo)
+	}
+	if estado, _ = enviarPeticionPrueba(t, http.MethodGet, servidor.URL+"/jobs/desconocido", nil); estado != http.StatusNotFound {
+		t.Errorf("GET /jobs/desconocido = %d, se esperaba %d", estado, http.StatusNotFound)
+	}
+	if estado, cuerpo = enviarPeticionPrueba(t, http.MethodGet, servidor.URL+"/metrics", nil); estado != http.StatusOK || !strings.Contains(cuerpo, "sasc_") {
+		t.Errorf("GET /metrics = %d %s", estado, cuerpo)
+	}
+}
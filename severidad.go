@@ -0,0 +1,219 @@
+/*
+ * Niveles de severidad de los pares (--severity).
+ *
+ * Se configuran bandas con la distancia máxima de cada nivel, por ejemplo "critical:10,warn:30,info:60":
+ * un par a una distancia de 8 es "critical", de 25 es "warn", de 50 es "info" y de 70 no tiene severidad.
+ * Como la escala de las distancias depende del motor, se pueden definir perfiles por motor separados por punto
+ * y coma (por ejemplo "ascii=critical:5,warn:10;lines=critical:20,warn:40"), se usa el del motor del análisis.
+ * La severidad se propaga a todas las salidas: etiqueta con color en la consola, columna en el archivo CSV,
+ * campo "severidad" en el resultado JSON e insignias en el reporte HTML de LTI.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Niveles de severidad, de menor a mayor
+const (
+	SEVERIDAD_INFO     = "info"
+	SEVERIDAD_WARN     = "warn"
+	SEVERIDAD_CRITICAL = "critical"
+)
+
+// Colores ANSI y de las insignias HTML de cada nivel de severidad
+var coloresSeveridad = map[string]struct{ ansi, html string }{
+	SEVERIDAD_INFO:     {"\033[36m", "#17a2b8"},
+	SEVERIDAD_WARN:     {"\033[33m", "#e0a800"},
+	SEVERIDAD_CRITICAL: {"\033[31m", "#dc3545"},
+}
+
+// Estructura de una banda de severidad
+// - nivel de severidad
+// - distancia máxima de la banda
+type BandaSeveridad struct {
+	nivel  string
+	limite float64
+}
+
+// Estructura de un par con severidad en el resultado JSON
+type ParSeveridadJSON struct {
+	Archivo1  string  `json:"archivo1"`
+	Archivo2  string  `json:"archivo2"`
+	Distancia float64 `json:"distancia"`
+	Severidad string  `json:"severidad"`
+}
+
+/*
+ * Función para obtener las bandas de severidad
+ * param: bandas separadas por comas con la forma nivel:distancia (por ejemplo "critical:10,warn:30,info:60")
+ * return: las bandas de menor a mayor distancia o un error si no son válidas
+ */
+func obtenerBandasSeveridad(texto string) ([]BandaSeveridad, error) {
+	var bandas []BandaSeveridad
+
+	for _, definicion := range strings.Split(texto, ",") {
+		partes := strings.SplitN(strings.TrimSpace(definicion), ":", 2)
+		if len(partes) != 2 {
+			return bandas, fmt.Errorf("La banda de severidad \"%s\" debe tener la forma nivel:distancia", definicion)
+		}
+
+		if _, existe := coloresSeveridad[partes[0]]; !existe {
+			return bandas, fmt.Errorf("Nivel de severidad \"%s\" no soportado (info | warn | critical)", partes[0])
+		}
+		for _, banda := range bandas {
+			if banda.nivel == partes[0] {
+				return bandas, fmt.Errorf("El nivel de severidad \"%s\" está repetido", partes[0])
+			}
+		}
+
+		limite, err := strconv.ParseFloat(partes[1], 64)
+		if err != nil {
+			return bandas, fmt.Errorf("La distancia \"%s\" del nivel de severidad \"%s\" no es un número", partes[1], partes[0])
+		}
+
+		bandas = append(bandas, BandaSeveridad{nivel: partes[0], limite: limite})
+	}
+
+	sort.SliceStable(bandas, func(i, j int) bool {
+		return bandas[i].limite < bandas[j].limite
+	})
+
+	return bandas, nil
+}
+
+/*
+ * Función para obtener las bandas de severidad del perfil del motor del análisis
+ * param: bandas o perfiles por motor (ver el encabezado del archivo) y motor de características
+ * return: las bandas del motor (vacías si no tiene perfil) o un error si no son válidas
+ */
+func obtenerPerfilSeveridad(texto string, motor string) ([]BandaSeveridad, error) {
+	if !strings.Contains(texto, "=") {
+		return obtenerBandasSeveridad(texto)
+	}
+
+	var bandasMotor []BandaSeveridad
+
+	for _, perfil := range strings.Split(texto, ";") {
+		partes := strings.SplitN(perfil, "=", 2)
+		if len(partes) != 2 {
+			return nil, fmt.Errorf("El perfil de severidad \"%s\" debe tener la forma motor=bandas", perfil)
+		}
+
+		bandas, err := obtenerBandasSeveridad(partes[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.TrimSpace(partes[0]) == motor {
+			bandasMotor = bandas
+		}
+	}
+
+	return bandasMotor, nil
+}
+
+/*
+ * Función para clasificar una distancia en su nivel de severidad
+ * param: bandas de severidad y distancia
+ * return: nivel de severidad (vacío si la distancia supera todas las bandas)
+ */
+func clasificarSeveridad(bandas []BandaSeveridad, distancia float64) string {
+	for _, banda := range bandas {
+		if distancia <= banda.limite {
+			return banda.nivel
+		}
+	}
+
+	return ""
+}
+
+/*
+ * Función para determinar si la consola admite colores (la salida estándar es una terminal)
+ * return: si se imprimen colores
+ */
+func consolaConColores() bool {
+	informacion, err := os.Stdout.Stat()
+
+	return err == nil && informacion.Mode()&os.ModeCharDevice != 0
+}
+
+/*
+ * Función para obtener la etiqueta de severidad de una distancia para la consola
+ * param: bandas de severidad, distancia y si se usan colores
+ * return: etiqueta (por ejemplo " [critical]") o vacío si no tiene severidad
+ */
+func etiquetarSeveridad(bandas []BandaSeveridad, distancia float64, colores bool) string {
+	nivel := clasificarSeveridad(bandas, distancia)
+	if nivel == "" {
+		return ""
+	}
+
+	if colores {
+		return " " + coloresSeveridad[nivel].ansi + "[" + nivel + "]\033[0m"
+	}
+
+	return " [" + nivel + "]"
+}
+
+/*
+ * Función para obtener la mayor severidad de los pares de un archivo (para el archivo CSV)
+ * param: resultado del análisis, índice del archivo y bandas de severidad
+ * return: nivel de la mayor severidad (vacío si ningún par tiene severidad)
+ */
+func obtenerSeveridadMaxima(resultado ResultadoAnalisis, indice int, bandas []BandaSeveridad) string {
+	menor := -1.0
+
+	for j, distancia := range resultado.matriz[indice] {
+		if j == indice || !esDistanciaCalculada(distancia) {
+			continue
+		}
+		if menor < 0 || distancia < menor {
+			menor = distancia
+		}
+	}
+
+	if menor < 0 {
+		return ""
+	}
+
+	return clasificarSeveridad(bandas, menor)
+}
+
+/*
+ * Función para construir los pares con severidad del resultado JSON
+ * param: resultado del análisis y bandas de severidad
+ * return: los pares (sin repetir) que tienen un nivel de severidad
+ */
+func construirSeveridadesJSON(resultado ResultadoAnalisis, bandas []BandaSeveridad) []ParSeveridadJSON {
+	var pares []ParSeveridadJSON
+
+	for i := range resultado.archivos {
+		for j := i + 1; j < len(resultado.archivos); j++ {
+			distancia := resultado.matriz[i][j]
+			if !esDistanciaCalculada(distancia) {
+				continue
+			}
+
+			if nivel := clasificarSeveridad(bandas, distancia); nivel != "" {
+				pares = append(pares, ParSeveridadJSON{Archivo1: resultado.archivos[i].nombre, Archivo2: resultado.archivos[j].nombre, Distancia: distancia, Severidad: nivel})
+			}
+		}
+	}
+
+	return pares
+}
+
+/*
+ * Función para obtener la insignia HTML de un nivel de severidad
+ * param: nivel de severidad
+ * return: insignia HTML
+ */
+func obtenerInsigniaSeveridad(nivel string) string {
+	return fmt.Sprintf("<span style=\"background:%s;color:#fff;border-radius:4px;padding:0 6px\">%s</span>", coloresSeveridad[nivel].html, nivel)
+}
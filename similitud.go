@@ -0,0 +1,155 @@
+/*
+ * Similitud entre dos fragmentos de código en el modo servidor (POST /v1/similarity).
+ *
+ * Otras herramientas del campus (por ejemplo un portal de entregas) pueden comparar dos fragmentos antes de aceptar
+ * una entrega, sin armar un flujo tar ni esperar un análisis completo. El cuerpo de la petición es un JSON con los
+ * dos fragmentos y la respuesta tiene la distancia entre ellos con cada motor de características:
+ *
+ *     curl -X POST localhost:8080/v1/similarity -d '{"codigo1": "...", "codigo2": "..."}'
+ *
+ * Cada motor se crea al iniciar el servidor con las opciones que le aplican, como en el comando robustness (ver
+ * robustez.go), porque la reducción de dimensiones y la matriz de Gram dependen de un corpus. Las distancias de
+ * motores distintos no están en la misma escala; la del motor "lines" es 100 * (1 - puntaje). Si la extensión del
+ * servidor es go también se incluye el motor "ast" (ver motor_ast.go).
+ * Si el servidor requiere autenticación (--auth-file), la petición debe identificarse, pero no pertenece a un curso,
+ * salvo que se indique ?course= (obligatorio con --course-quota, ver limites_servidor.go).
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Motores con los que se comparan los fragmentos (más "ast" con la extensión go)
+const MOTORES_SIMILITUD = "ascii,lines,tokens"
+
+// Tamaño máximo (en bytes) del cuerpo de una petición de similitud
+const LIMITE_PETICION_SIMILITUD = 1 << 20
+
+// Estructura de una petición de similitud
+// - fragmentos de código a comparar
+type PeticionSimilitudJSON struct {
+	Codigo1 string `json:"codigo1"`
+	Codigo2 string `json:"codigo2"`
+}
+
+// Estructura de la distancia entre los fragmentos con un motor
+// - motor y su nombre completo con las opciones empleadas
+// - distancia entre los fragmentos (0.0 indica que son idénticos para el motor)
+type DistanciaMotorJSON struct {
+	Motor       string  `json:"motor"`
+	Descripcion string  `json:"descripcion"`
+	Distancia   float64 `json:"distancia"`
+}
+
+// Estructura de la respuesta de similitud
+// - distancia entre los fragmentos con cada motor
+type ResultadoSimilitudJSON struct {
+	Distancias []DistanciaMotorJSON `json:"distancias"`
+}
+
+// Estructura para comparar fragmentos con varios motores
+// - nombres de los motores y motores creados con las opciones que les aplican
+// - preprocesamiento del contenido
+type ComparadorSimilitud struct {
+	nombres          []string
+	motores          []Motor
+	preprocesamiento Preprocesamiento
+}
+
+/*
+ * Función para crear el comparador de fragmentos con los motores de MOTORES_SIMILITUD
+ * param: parámetros de ejecución y el preprocesamiento del contenido
+ * return: el comparador o un error si algún motor no se puede crear
+ */
+func crearComparadorSimilitud(parametros Parametros, preprocesamiento Preprocesamiento) (*ComparadorSimilitud, error) {
+	comparador := &ComparadorSimilitud{preprocesamiento: preprocesamiento}
+
+	nombres := strings.Split(MOTORES_SIMILITUD, ",")
+	if parametros.extension == "go" {
+		nombres = append(nombres, "ast")
+	}
+
+	for _, nombre := range nombres {
+		motor, _, err := crearMotorRobustez(parametros, nombre)
+		if err != nil {
+			return nil, err
+		}
+		comparador.nombres = append(comparador.nombres, nombre)
+		comparador.motores = append(comparador.motores, motor)
+	}
+
+	return comparador, nil
+}
+
+/*
+ * Función para comparar dos fragmentos de código con cada motor
+ * param: cuerpo JSON de la petición
+ * return: la distancia con cada motor o un error si el cuerpo no es válido
+ */
+func (comparador *ComparadorSimilitud) comparar(cuerpo io.Reader) (ResultadoSimilitudJSON, error) {
+	var peticion PeticionSimilitudJSON
+	resultado := ResultadoSimilitudJSON{Distancias: []DistanciaMotorJSON{}}
+
+	if err := json.NewDecoder(cuerpo).Decode(&peticion); err != nil {
+		return resultado, fmt.Errorf("El cuerpo de la petición no es un JSON válido: %v", err)
+	}
+	if strings.TrimSpace(peticion.Codigo1) == "" || strings.TrimSpace(peticion.Codigo2) == "" {
+		return resultado, fmt.Errorf("Se deben indicar los dos fragmentos de código (\"codigo1\" y \"codigo2\")")
+	}
+
+	for m, motor := range comparador.motores {
+		codigo1 := caracterizarContenido("codigo1", []byte(peticion.Codigo1), motor, comparador.preprocesamiento)
+		codigo2 := caracterizarContenido("codigo2", []byte(peticion.Codigo2), motor, comparador.preprocesamiento)
+
+		resultado.Distancias = append(resultado.Distancias, DistanciaMotorJSON{
+			Motor:       comparador.nombres[m],
+			Descripcion: motor.nombre(),
+			Distancia:   motor.distancia(codigo1, codigo2),
+		})
+	}
+
+	return resultado, nil
+}
+
+/*
+ * Función para registrar la ruta de similitud en el servidor
+ * param: rutas del servidor, autorización, límites y registro de auditoría (nil si no se requieren) y métricas del servidor
+ */
+func (comparador *ComparadorSimilitud) registrarRuta(rutas *http.ServeMux, autorizacion *Autorizacion, limites *LimitesServidor, auditoria *Auditoria, metricas *Metricas) {
+	rutas.HandleFunc("/v1/similarity", func(respuesta http.ResponseWriter, peticion *http.Request) {
+		if peticion.Method != http.MethodPost {
+			http.Error(respuesta, "Se debe usar POST con un JSON de los dos fragmentos de código", http.StatusMethodNotAllowed)
+			return
+		}
+
+		curso, autorizado := autorizacion.autorizarCurso(respuesta, peticion, limites.requiereCurso())
+		if !autorizado || !limites.permitir(respuesta, peticion, autorizacion, curso) {
+			return
+		}
+
+		registro := RegistroAuditoriaJSON{Accion: ACCION_COMPARAR, Identidad: identificarCliente(peticion, autorizacion), Curso: curso, Estado: http.StatusOK}
+
+		resultado, err := comparador.comparar(http.MaxBytesReader(respuesta, peticion.Body, LIMITE_PETICION_SIMILITUD))
+		if err != nil {
+			metricas.registrarFallo()
+			registro.Estado = http.StatusBadRequest
+			if !auditoria.registrarPeticion(respuesta, peticion, registro) {
+				return
+			}
+			http.Error(respuesta, err.Error(), http.StatusBadRequest)
+			return
+		}
+		metricas.registrarComparacion()
+		if !auditoria.registrarPeticion(respuesta, peticion, registro) {
+			return
+		}
+
+		responderJSON(respuesta, http.StatusOK, resultado)
+	})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+/*
+ * Función para crear el cuerpo JSON de una petición de similitud
+ * param: prueba y los dos fragmentos de código
+ * return: el cuerpo de la petición
+ */
+func crearPeticionSimilitudPrueba(t *testing.T, codigo1 string, codigo2 string) *strings.Reader {
+	t.Helper()
+
+	contenido, err := json.Marshal(PeticionSimilitudJSON{Codigo1: codigo1, Codigo2: codigo2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.NewReader(string(contenido))
+}
+
+func TestServidorSimilitud(t *testing.T) {
+	servidor := crearServidorPrueba(t, crearParametrosServidorPrueba())
+	direccion := servidor.URL + "/v1/similarity"
+
+	codigo := "package main\n\nfunc main() {\n\tsuma := 0\n\tfor i := 0; i < 10; i++ {\n\t\tsuma += i\n\t}\n\tprintln(suma)\n}\n"
+	otro := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tnombres := []string{\"ana\", \"luis\"}\n\tfmt.Println(len(nombres))\n}\n"
+
+	casos := []struct {
+		nombre           string
+		codigo1, codigo2 string
+		identicos        bool
+	}{
+		{"fragmentos idénticos", codigo, codigo, true},
+		{"fragmentos distintos", codigo, otro, false},
+	}
+
+	for _, caso := range casos {
+		estado, cuerpo := enviarPeticionPrueba(t, http.MethodPost, direccion, crearPeticionSimilitudPrueba(t, caso.codigo1, caso.codigo2))
+		if estado != http.StatusOK {
+			t.Fatalf("%s: estado %d: %s", caso.nombre, estado, cuerpo)
+		}
+
+		var resultado ResultadoSimilitudJSON
+		if err := json.Unmarshal([]byte(cuerpo), &resultado); err != nil {
+			t.Fatal(err)
+		}
+
+		var motores []string
+		distintos := false
+		for _, distancia := range resultado.Distancias {
+			motores = append(motores, distancia.Motor)
+			if caso.identicos && distancia.Distancia != 0 {
+				t.Errorf("%s: distancia %v con el motor %s, se esperaba 0", caso.nombre, distancia.Distancia, distancia.Motor)
+			}
+			distintos = distintos || distancia.Distancia > 0
+		}
+		if strings.Join(motores, ",") != MOTORES_SIMILITUD+",ast" {
+			t.Errorf("%s: motores = %v, se esperaban %s y ast", caso.nombre, motores, MOTORES_SIMILITUD)
+		}
+		if !caso.identicos && !distintos {
+			t.Errorf("%s: todas las distancias son 0", caso.nombre)
+		}
+	}
+}
+
+func TestServidorSimilitudPeticionNoValida(t *testing.T) {
+	servidor := crearServidorPrueba(t, crearParametrosServidorPrueba())
+	direccion := servidor.URL + "/v1/similarity"
+
+	casos := []struct {
+		nombre, metodo, cuerpo string
+		estado                 int
+	}{
+		{"GET", http.MethodGet, "", http.StatusMethodNotAllowed},
+		{"JSON no válido", http.MethodPost, "{\"codigo1\": ", http.StatusBadRequest},
+		{"sin el segundo fragmento", http.MethodPost, "{\"codigo1\": \"package main\"}", http.StatusBadRequest},
+		{"fragmento en blanco", http.MethodPost, "{\"codigo1\": \"package main\", \"codigo2\": \"  \\n\"}", http.StatusBadRequest},
+		{"más del tamaño máximo", http.MethodPost, "{\"codigo1\": \"" + strings.Repeat("a", LIMITE_PETICION_SIMILITUD) + "\", \"codigo2\": \"b\"}", http.StatusBadRequest},
+	}
+
+	for _, caso := range casos {
+		if estado, cuerpo := enviarPeticionPrueba(t, caso.metodo, direccion, strings.NewReader(caso.cuerpo)); estado != caso.estado {
+			t.Errorf("%s: estado %d, se esperaba %d: %s", caso.nombre, estado, caso.estado, cuerpo)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+/*
+ * Análisis por subtareas de un mismo corpus (--subassignment).
+ *
+ * Los laboratorios suelen tener varios ejercicios en una misma entrega (por ejemplo "ej1/Main.java" y
+ * "ej2/Main.java" en el directorio de cada estudiante), y analizarlos juntos compara ejercicios distintos entre sí.
+ * Con --subassignment="nombre=patrón[,patrón...]" (se puede repetir) cada archivo se asigna a la primera subtarea
+ * con un patrón que coincide con su ruta dentro del directorio del estudiante (el subdirectorio inmediato del
+ * directorio base), sin distinguir mayúsculas, por ejemplo:
+ *     --subassignment="ej1=ej1/**" --subassignment="ej2=ej2/**,ejercicio2/**"
+ * En los patrones "*" y "?" no incluyen "/" (como en path.Match) y "**" coincide con cualquier cantidad de
+ * directorios. Cada subtarea se analiza de forma independiente (fases 1 a 3, con sus grupos y, con "auto", su propia
+ * distancia máxima), los archivos de los reportes llevan el nombre de la subtarea como sufijo (por ejemplo
+ * "tabla-ej1.csv" o el directorio "reporte-ej1" de --bundle) y al final se imprime un resumen de todas las subtareas.
+ * Los archivos que no coinciden con ninguna subtarea no se analizan y se listan. Las salidas del resultado JSON
+ * (--output), los ganchos (--hook) y el flujo de resultados (--stream) no se admiten con subtareas.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Estructura de una subtarea
+// - nombre de la subtarea (sufijo de los archivos de sus reportes)
+// - patrones de las rutas de sus archivos dentro del directorio del estudiante
+type Subtarea struct {
+	nombre   string
+	patrones []string
+}
+
+// Estructura del resumen del análisis de una subtarea
+// - nombre de la subtarea
+// - cantidad de archivos y de estudiantes (subdirectorios inmediatos del directorio base) analizados
+// - distancia máxima empleada (NaN si no se definió) y cantidad de grupos
+// - cantidad de pares a la distancia máxima y menor distancia entre dos archivos (NaN si no hay pares)
+type ResumenSubtarea struct {
+	nombre          string
+	archivos        int
+	estudiantes     int
+	distanciaMaxima float64
+	grupos          int
+	paresMarcados   int
+	menorDistancia  float64
+}
+
+/*
+ * Función para obtener las subtareas indicadas por el usuario
+ * param: definiciones "nombre=patrón[,patrón...]" (un nombre repetido agrega sus patrones a la misma subtarea)
+ * return: las subtareas en el orden de su primera definición o un error si una definición no es válida
+ */
+func obtenerSubtareas(definiciones []string) ([]Subtarea, error) {
+	var subtareas []Subtarea
+	posiciones := make(map[string]int)
+
+	for _, definicion := range definiciones {
+		partes := strings.SplitN(definicion, "=", 2)
+		nombre := strings.TrimSpace(partes[0])
+		if len(partes) < 2 || nombre == "" || strings.TrimSpace(partes[1]) == "" {
+			return nil, fmt.Errorf("Subtarea (--subassignment) \"%s\" no válida, debe ser \"nombre=patrón[,patrón...]\"", definicion)
+		}
+
+		posicion, existe := posiciones[nombre]
+		if !existe {
+			posicion = len(subtareas)
+			posiciones[nombre] = posicion
+			subtareas = append(subtareas, Subtarea{nombre: nombre})
+		}
+
+		for _, patron := range strings.Split(partes[1], ",") {
+			patron = strings.ToLower(strings.Trim(strings.TrimSpace(patron), "/"))
+			if _, err := path.Match(patron, ""); err != nil || patron == "" {
+				return nil, fmt.Errorf("Patrón \"%s\" de la subtarea \"%s\" (--subassignment) no válido", patron, nombre)
+			}
+			subtareas[posicion].patrones = append(subtareas[posicion].patrones, patron)
+		}
+	}
+
+	return subtareas, nil
+}
+
+/*
+ * Función para determinar si una ruta coincide con un patrón en el que "**" coincide con cualquier cantidad de
+ * directorios
+ * param: segmentos del patrón y de la ruta (separados por "/")
+ * return: si la ruta coincide con el patrón
+ */
+func coincidirSegmentos(patron []string, ruta []string) bool {
+	if len(patron) == 0 {
+		return len(ruta) == 0
+	}
+
+	if patron[0] == "**" {
+		for k := 0; k <= len(ruta); k++ {
+			if coincidirSegmentos(patron[1:], ruta[k:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(ruta) == 0 {
+		return false
+	}
+	coincide, _ := path.Match(patron[0], ruta[0])
+
+	return coincide && coincidirSegmentos(patron[1:], ruta[1:])
+}
+
+/*
+ * Función para obtener los segmentos de la ruta de un archivo dentro del directorio del estudiante
+ * param: ruta relativa del archivo (iniciando con "./")
+ * return: segmentos de la ruta en minúsculas, sin el directorio del estudiante
+ */
+func obtenerSegmentosEstudiante(archivo string) []string {
+	segmentos := strings.Split(strings.ToLower(strings.TrimPrefix(filepath.ToSlash(archivo), "./")), "/")
+	if obtenerEstudiante(".", archivo) != "." {
+		segmentos = segmentos[1:]
+	}
+
+	return segmentos
+}
+
+/*
+ * Función para obtener la subtarea de un archivo
+ * param: subtareas y ruta relativa del archivo (iniciando con "./")
+ * return: posición de la primera subtarea con un patrón que coincide con la ruta dentro del directorio del estudiante
+ *         (-1 si no coincide con ninguna)
+ */
+func obtenerSubtareaArchivo(subtareas []Subtarea, archivo string) int {
+	segmentos := obtenerSegmentosEstudiante(archivo)
+
+	for posicion, subtarea := range subtareas {
+		for _, patron := range subtarea.patrones {
+			if coincidirSegmentos(strings.Split(patron, "/"), segmentos) {
+				return posicion
+			}
+		}
+	}
+
+	return -1
+}
+
+/*
+ * Función para obtener los parámetros de ejecución del análisis de una subtarea
+ * param: parámetros de ejecución y nombre de la subtarea
+ * return: los parámetros con el nombre de la subtarea como sufijo de los archivos y directorios de los reportes
+ */
+func obtenerParametrosSubtarea(parametros Parametros, subtarea string) Parametros {
+	sufijar := func(nombre string) string {
+		if nombre == "" {
+			return nombre
+		}
+		return obtenerNombreArchivoAsistente(nombre, subtarea)
+	}
+
+	parametros.nombreTablaCSV = sufijar(parametros.nombreTablaCSV)
+	parametros.reporteValidez = sufijar(parametros.reporteValidez)
+	parametros.nombreGruposCSV = sufijar(parametros.nombreGruposCSV)
+	parametros.nombreGruposJSON = sufijar(parametros.nombreGruposJSON)
+	parametros.directorioCartas = sufijar(parametros.directorioCartas)
+	parametros.nombreRevisionCSV = sufijar(parametros.nombreRevisionCSV)
+	parametros.nombreRevisionHTML = sufijar(parametros.nombreRevisionHTML)
+	parametros.directorioPaquete = sufijar(parametros.directorioPaquete)
+
+	return parametros
+}
+
+/*
+ * Función para resumir el resultado del análisis de una subtarea
+ * param: nombre de la subtarea, listado de sus archivos y resultado del análisis
+ * return: el resumen de la subtarea
+ */
+func resumirSubtarea(nombre string, listado []string, resultado ResultadoAnalisis) ResumenSubtarea {
+	resumen := ResumenSubtarea{nombre: nombre, archivos: len(listado), distanciaMaxima: math.NaN(), grupos: len(resultado.grupos), menorDistancia: math.NaN()}
+
+	estudiantes := make(map[string]bool)
+	for _, archivo := range listado {
+		if directorio := obtenerEstudiante(".", archivo); directorio != "." {
+			estudiantes[directorio] = true
+		}
+	}
+	resumen.estudiantes = len(estudiantes)
+
+	if resultado.tieneDistanciaMaxima() {
+		resumen.distanciaMaxima = resultado.criterio.limite()
+	}
+
+	for i := range resultado.matriz {
+		for j := 0; j < i; j++ {
+			distancia := resultado.matriz[i][j]
+			if distancia == DISTANCIA_NO_CALCULADA {
+				continue
+			}
+			if math.IsNaN(resumen.menorDistancia) || distancia < resumen.menorDistancia {
+				resumen.menorDistancia = distancia
+			}
+			if resultado.tieneDistanciaMaxima() && distancia <= resumen.distanciaMaxima {
+				resumen.paresMarcados++
+			}
+		}
+	}
+
+	return resumen
+}
+
+/*
+ * Función para imprimir el resumen de todas las subtareas
+ * param: resúmenes de las subtareas, archivos sin subtarea y formato de las rutas
+ */
+func imprimirResumenSubtareas(resumenes []ResumenSubtarea, sinSubtarea []string, formatoRutas FormatoRutas) {
+	formatear := func(valor float64) string {
+		if math.IsNaN(valor) {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", valor)
+	}
+
+	fmt.Print("\nRESUMEN DE LAS SUBTAREAS\n\n")
+	fmt.Printf("%-20s %9s %12s %10s %7s %15s %15s\n", "Subtarea", "Archivos", "Estudiantes", "Dist. máx.", "Grupos", "Pares marcados", "Menor distancia")
+	for _, resumen := range resumenes {
+		marcados := "-"
+		if !math.IsNaN(resumen.distanciaMaxima) {
+			marcados = fmt.Sprint(resumen.paresMarcados)
+		}
+		fmt.Printf("%-20s %9d %12d %10s %7d %15s %15s\n", resumen.nombre, resumen.archivos, resumen.estudiantes, formatear(resumen.distanciaMaxima), resumen.grupos, marcados, formatear(resumen.menorDistancia))
+	}
+
+	if len(sinSubtarea) > 0 {
+		fmt.Println()
+		fmt.Println("Archivos sin subtarea (no se analizaron):", len(sinSubtarea))
+		for _, archivo := range sinSubtarea {
+			fmt.Println("\t" + formatoRutas.formatear(archivo))
+		}
+	}
+	fmt.Println()
+}
+
+/*
+ * Función para analizar cada subtarea de forma independiente e imprimir el resumen de todas
+ * param: listado de todos los archivos, parámetros de ejecución (con las subtareas), formato de las rutas y función
+ *        que analiza un listado con sus parámetros (fases 1 a 3)
+ */
+func analizarSubtareas(listado []string, parametros Parametros, formatoRutas FormatoRutas, analizar func([]string, Parametros) ResultadoAnalisis) {
+	listados := make([][]string, len(parametros.subtareas))
+	var sinSubtarea []string
+
+	for _, archivo := range listado {
+		if posicion := obtenerSubtareaArchivo(parametros.subtareas, archivo); posicion >= 0 {
+			listados[posicion] = append(listados[posicion], archivo)
+		} else {
+			sinSubtarea = append(sinSubtarea, archivo)
+		}
+	}
+
+	var resumenes []ResumenSubtarea
+	for posicion, subtarea := range parametros.subtareas {
+		fmt.Printf("SUBTAREA \"%s\" (%s)\n\n", subtarea.nombre, strings.Join(subtarea.patrones, ", "))
+
+		if len(listados[posicion]) == 0 {
+			fmt.Print("Sin archivos, la subtarea no se analiza\n\n")
+			resumenes = append(resumenes, ResumenSubtarea{nombre: subtarea.nombre, distanciaMaxima: math.NaN(), menorDistancia: math.NaN()})
+			continue
+		}
+
+		resultado := analizar(listados[posicion], obtenerParametrosSubtarea(parametros, subtarea.nombre))
+		resumenes = append(resumenes, resumirSubtarea(subtarea.nombre, listados[posicion], resultado))
+		fmt.Println()
+	}
+
+	imprimirResumenSubtareas(resumenes, sinSubtarea, formatoRutas)
+}
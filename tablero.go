@@ -0,0 +1,398 @@
+/*
+ * Tablero de una tarea entre secciones (comando dashboard).
+ *
+ * Cuando la misma tarea se asigna en varias secciones o sedes, cada una envía sus entregas al servidor como un
+ * trabajo distinto (POST /jobs?assignment=taller1&section=grupo2, o desde la plataforma LTI, en donde la actividad es
+ * la tarea y el curso es la sección), y cada análisis solo compara las entregas de su sección. El comando
+ *
+ *     ./SASC dashboard --data-dir=datos --assignment=taller1 --max-distance=30 [--dashboard-html=tablero.html] java
+ *
+ * toma del directorio de datos el último trabajo terminado de cada sección de la tarea, analiza juntas todas sus
+ * entregas originales (con el motor y las opciones indicadas) y reporta en consola y en un HTML:
+ * - los pares a la distancia máxima entre archivos de secciones distintas, del más cercano al más lejano
+ * - por cada sección: sus archivos, los que tienen al menos un par a la distancia máxima (en cualquier sección) y su
+ *   tasa, y sus pares marcados dentro de la sección y con otras secciones
+ * - las estadísticas generales: secciones, archivos, pares marcados, tasa general y menor distancia entre secciones
+ * Los archivos se nombran con su sección (grupo2/estudiante/Main.java). Las secciones cuyas entregas ya se eliminaron
+ * por la retención (--source-retention) se reportan como omitidas. Con --encryption-key se leen los datos cifrados.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Nombre de la sección de los trabajos sin sección ni curso
+const SECCION_SIN_NOMBRE = "sin-seccion"
+
+// Estructura de una sección de la tarea en el tablero
+// - nombre de la sección, trabajo del que se tomaron sus entregas y fecha del trabajo
+// - índices de sus archivos en el análisis combinado
+// - archivos con al menos un par a la distancia máxima y pares marcados dentro de la sección y con otras secciones
+type SeccionTablero struct {
+	nombre        string
+	trabajo       string
+	creado        time.Time
+	archivos      []int
+	marcados      int
+	paresInternos int
+	paresCruzados int
+}
+
+// Estructura de un par de archivos de secciones distintas a la distancia máxima
+// - índices de los archivos y de sus secciones y distancia entre ellos
+type ParTablero struct {
+	archivo1  int
+	archivo2  int
+	seccion1  int
+	seccion2  int
+	distancia float64
+}
+
+// Estructura del tablero de una tarea
+// - nombre de la tarea, resultado del análisis combinado y sección de cada archivo
+// - secciones analizadas y secciones omitidas (sin entregas disponibles)
+// - pares entre secciones y cantidad total de pares marcados
+type TableroTarea struct {
+	tarea          string
+	resultado      ResultadoAnalisis
+	seccionArchivo []int
+	secciones      []SeccionTablero
+	omitidas       []string
+	cruzados       []ParTablero
+	paresMarcados  int
+}
+
+/*
+ * Función para obtener el nombre de la sección de un trabajo
+ * param: trabajo
+ * return: la sección, o el curso si no se indicó la sección
+ */
+func obtenerSeccionTrabajo(trabajo Trabajo) string {
+	switch {
+	case trabajo.Seccion != "":
+		return trabajo.Seccion
+	case trabajo.Curso != "":
+		return trabajo.Curso
+	}
+
+	return SECCION_SIN_NOMBRE
+}
+
+/*
+ * Función para seleccionar el último trabajo terminado de cada sección de una tarea
+ * param: trabajos guardados y nombre de la tarea
+ * return: un trabajo por sección, ordenados por el nombre de la sección
+ */
+func seleccionarTrabajosTarea(trabajos []Trabajo, tarea string) []Trabajo {
+	ultimos := make(map[string]Trabajo)
+
+	for _, trabajo := range trabajos {
+		if trabajo.Tarea != tarea || trabajo.Estado != TRABAJO_TERMINADO {
+			continue
+		}
+		seccion := obtenerSeccionTrabajo(trabajo)
+		if anterior, existe := ultimos[seccion]; !existe || trabajo.Creado.After(anterior.Creado) {
+			ultimos[seccion] = trabajo
+		}
+	}
+
+	seleccionados := make([]Trabajo, 0, len(ultimos))
+	for _, trabajo := range ultimos {
+		seleccionados = append(seleccionados, trabajo)
+	}
+	sort.Slice(seleccionados, func(i, j int) bool {
+		return obtenerSeccionTrabajo(seleccionados[i]) < obtenerSeccionTrabajo(seleccionados[j])
+	})
+
+	return seleccionados
+}
+
+/*
+ * Función para construir el tablero de una tarea analizando juntas las entregas de todas sus secciones
+ * param: parámetros de ejecución (directorio de datos, tarea, distancia máxima y clave de cifrado), el motor y el preprocesamiento
+ * return: el tablero o un error si el directorio no se puede leer o ninguna sección tiene entregas disponibles
+ */
+func construirTableroTarea(parametros Parametros, motor Motor, preprocesamiento Preprocesamiento) (TableroTarea, error) {
+	tablero := TableroTarea{tarea: parametros.tareaClassroom}
+
+	if _, err := os.Stat(parametros.directorioDatos); err != nil {
+		return tablero, fmt.Errorf("No se puede leer el directorio de datos (--data-dir): %v", err)
+	}
+
+	cifrado, err := crearCifrado(parametros.archivoCifrado)
+	if err != nil {
+		return tablero, err
+	}
+	almacen, err := crearAlmacenTrabajos(parametros.directorioDatos, 0, 0, cifrado)
+	if err != nil {
+		return tablero, err
+	}
+	limites, err := crearLimitesExtraccion(parametros)
+	if err != nil {
+		return tablero, err
+	}
+
+	trabajos, err := almacen.cargarTrabajos()
+	if err != nil {
+		return tablero, err
+	}
+
+	var tablaCodigoFuente []CodigoFuente
+	var arena ArenaCaracteristicas
+	var descartados []string
+
+	for _, trabajo := range seleccionarTrabajosTarea(trabajos, tablero.tarea) {
+		seccion := obtenerSeccionTrabajo(trabajo)
+
+		entregas, err := cifrado.leerArchivo(filepath.Join(parametros.directorioDatos, trabajo.Identificador+".tar"))
+		if os.IsNotExist(err) {
+			tablero.omitidas = append(tablero.omitidas, seccion)
+			continue
+		}
+		if err != nil {
+			return tablero, fmt.Errorf("No se pueden leer las entregas de la sección \"%s\": %v", seccion, err)
+		}
+
+		// Los .zip omitidos ya se reportaron en la validez del trabajo de la sección
+		rutas, contenidos, _, err := leerArchivosTar(bytes.NewReader(entregas), parametros.extension, limites)
+		if err != nil {
+			return tablero, fmt.Errorf("Entregas no válidas de la sección \"%s\": %v", seccion, err)
+		}
+
+		actual := SeccionTablero{nombre: seccion, trabajo: trabajo.Identificador, creado: trabajo.Creado}
+		for i, ruta := range rutas {
+			nombre := seccion + "/" + strings.TrimPrefix(ruta, "./")
+			codigoFuente := CodigoFuente{nombre: nombre, ruta: nombre, tamano: len(contenidos[i]), contenido: preprocesamiento.preprocesar(contenidos[i])}
+
+			if parametros.minimoContenido > 0 && contarContenidoSignificativo(codigoFuente.contenido, parametros.extension) < parametros.minimoContenido {
+				descartados = append(descartados, nombre)
+				continue
+			}
+
+			caracterizarEnArena(&arena, motor, &codigoFuente)
+			actual.archivos = append(actual.archivos, len(tablaCodigoFuente))
+			tablero.seccionArchivo = append(tablero.seccionArchivo, len(tablero.secciones))
+			tablaCodigoFuente = append(tablaCodigoFuente, codigoFuente)
+		}
+		tablero.secciones = append(tablero.secciones, actual)
+	}
+
+	if len(tablaCodigoFuente) < 2 {
+		return tablero, fmt.Errorf("La tarea \"%s\" no tiene al menos dos entregas disponibles en el directorio de datos (trabajos terminados con ?assignment=)", tablero.tarea)
+	}
+
+	proyeccion := proyectarCorpus(tablaCodigoFuente, motor)
+	tablaCodigoFuente = determinarDistanciasEntreArchivos(tablaCodigoFuente, motor, parametros, nil)
+	tablero.resultado = construirResultadoAnalisis(tablaCodigoFuente, parametros, motor, descartados, nil, proyeccion)
+
+	tablero.contarPares()
+
+	return tablero, nil
+}
+
+/*
+ * Función para contar los pares a la distancia máxima de cada sección y los pares entre secciones
+ */
+func (tablero *TableroTarea) contarPares() {
+	limite := tablero.resultado.criterio.limite()
+	marcados := make([]bool, len(tablero.resultado.archivos))
+
+	for i := range tablero.resultado.archivos {
+		for j := i + 1; j < len(tablero.resultado.archivos); j++ {
+			distancia := tablero.resultado.matriz[i][j]
+			if distancia > limite || !esDistanciaCalculada(distancia) {
+				continue
+			}
+
+			tablero.paresMarcados++
+			marcados[i], marcados[j] = true, true
+
+			seccion1, seccion2 := tablero.seccionArchivo[i], tablero.seccionArchivo[j]
+			if seccion1 == seccion2 {
+				tablero.secciones[seccion1].paresInternos++
+				continue
+			}
+			tablero.secciones[seccion1].paresCruzados++
+			tablero.secciones[seccion2].paresCruzados++
+			tablero.cruzados = append(tablero.cruzados, ParTablero{archivo1: i, archivo2: j, seccion1: seccion1, seccion2: seccion2, distancia: distancia})
+		}
+	}
+
+	for i, marcado := range marcados {
+		if marcado {
+			tablero.secciones[tablero.seccionArchivo[i]].marcados++
+		}
+	}
+
+	sort.SliceStable(tablero.cruzados, func(i, j int) bool {
+		return tablero.cruzados[i].distancia < tablero.cruzados[j].distancia
+	})
+}
+
+/*
+ * Función para calcular la tasa de archivos marcados
+ * param: archivos marcados y total de archivos
+ * return: porcentaje de archivos marcados (0 si no hay archivos)
+ */
+func calcularTasaMarcados(marcados int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return 100.0 * float64(marcados) / float64(total)
+}
+
+/*
+ * Función para obtener la menor distancia entre archivos de secciones distintas
+ * return: la menor distancia y si hay al menos dos secciones con archivos
+ */
+func (tablero TableroTarea) obtenerMenorDistanciaCruzada() (float64, bool) {
+	menor, existe := 0.0, false
+
+	for i := range tablero.resultado.archivos {
+		for j := i + 1; j < len(tablero.resultado.archivos); j++ {
+			if !esDistanciaCalculada(tablero.resultado.matriz[i][j]) {
+				continue
+			}
+			if tablero.seccionArchivo[i] != tablero.seccionArchivo[j] && (!existe || tablero.resultado.matriz[i][j] < menor) {
+				menor, existe = tablero.resultado.matriz[i][j], true
+			}
+		}
+	}
+
+	return menor, existe
+}
+
+/*
+ * Función para imprimir el tablero de una tarea en consola
+ */
+func (tablero TableroTarea) imprimir() {
+	marcados := 0
+	for _, seccion := range tablero.secciones {
+		marcados += seccion.marcados
+	}
+
+	fmt.Printf("\nTABLERO DE LA TAREA \"%s\" (%d SECCIONES, %d ARCHIVOS, DISTANCIA MÁXIMA %s)\n\n",
+		tablero.tarea, len(tablero.secciones), len(tablero.resultado.archivos), tablero.resultado.criterio.describir())
+
+	fmt.Printf("\t%-20s %-16s %-16s %8s %8s %7s %8s %8s\n", "SECCIÓN", "TRABAJO", "FECHA", "ARCHIVOS", "MARCADOS", "TASA", "INTERNOS", "CRUZADOS")
+	for _, seccion := range tablero.secciones {
+		fmt.Printf("\t%-20s %-16s %-16s %8d %8d %6.1f%% %8d %8d\n", seccion.nombre, seccion.trabajo, seccion.creado.Format("2006-01-02 15:04"),
+			len(seccion.archivos), seccion.marcados, calcularTasaMarcados(seccion.marcados, len(seccion.archivos)), seccion.paresInternos, seccion.paresCruzados)
+	}
+	for _, seccion := range tablero.omitidas {
+		fmt.Printf("\t%-20s (omitida, sus entregas se eliminaron por la retención)\n", seccion)
+	}
+
+	fmt.Printf("\nPARES ENTRE SECCIONES A LA DISTANCIA MÁXIMA (%d)\n\n", len(tablero.cruzados))
+	for _, par := range tablero.cruzados {
+		fmt.Printf("\t%8.2f %s <-> %s\n", par.distancia, tablero.resultado.archivos[par.archivo1].nombre, tablero.resultado.archivos[par.archivo2].nombre)
+	}
+
+	fmt.Print("\nESTADÍSTICAS GENERALES\n\n")
+	fmt.Printf("\tPares marcados:            %d (%d entre secciones)\n", tablero.paresMarcados, len(tablero.cruzados))
+	fmt.Printf("\tArchivos marcados:         %d de %d (%.1f%%)\n", marcados, len(tablero.resultado.archivos), calcularTasaMarcados(marcados, len(tablero.resultado.archivos)))
+	if menor, existe := tablero.obtenerMenorDistanciaCruzada(); existe {
+		fmt.Printf("\tMenor distancia cruzada:   %.2f\n", menor)
+	}
+	fmt.Println()
+}
+
+/*
+ * Función para guardar el tablero de una tarea como un reporte HTML
+ * param: marca institucional, si el reporte es accesible y nombre del archivo HTML
+ */
+func (tablero TableroTarea) generarHTML(marca MarcaReporte, accesible bool, nombreArchivo string) {
+	ptrArchivo, err := os.Create(nombreArchivo)
+
+	if err != nil {
+		panic(err)
+	}
+	defer ptrArchivo.Close()
+
+	estilo := ""
+	if accesible {
+		estilo = "<style>\n" + ESTILO_ACCESIBLE + "</style>"
+	}
+
+	marcados := 0
+	for _, seccion := range tablero.secciones {
+		marcados += seccion.marcados
+	}
+
+	fmt.Fprintf(ptrArchivo, "<!DOCTYPE html><html lang=\"es\"><head><meta charset=\"utf-8\">%s%s</head><body>", titularReporte("titulo_tablero", " "+tablero.tarea), estilo)
+	fmt.Fprint(ptrArchivo, generarSelectorIdioma(accesible), marca.encabezado())
+	fmt.Fprintf(ptrArchivo, "<h1>%s: %s</h1><p>%d %s, %d %s. %s: %s</p>\n",
+		traducirReporte("tablero"), html.EscapeString(tablero.tarea), len(tablero.secciones), traducirReporte("secciones"),
+		len(tablero.resultado.archivos), traducirReporte("archivos_tablero"), traducirReporte("distancia_maxima"), describirCriterioReporte(tablero.resultado.criterio))
+
+	// Estadísticas generales
+	fmt.Fprintf(ptrArchivo, "<h2>%s</h2><ul><li>%s: %d (%d %s)</li><li>%s: %d / %d (%.1f%%)</li>",
+		traducirReporte("estadisticas"), traducirReporte("pares_marcados"), tablero.paresMarcados, len(tablero.cruzados), traducirReporte("entre_secciones"),
+		traducirReporte("archivos_marcados"), marcados, len(tablero.resultado.archivos), calcularTasaMarcados(marcados, len(tablero.resultado.archivos)))
+	if menor, existe := tablero.obtenerMenorDistanciaCruzada(); existe {
+		fmt.Fprintf(ptrArchivo, "<li>%s: %.2f</li>", traducirReporte("menor_cruzada"), menor)
+	}
+	fmt.Fprint(ptrArchivo, "</ul>\n")
+
+	// Secciones
+	fmt.Fprintf(ptrArchivo, "<h2>%s</h2>", traducirReporte("por_seccion"))
+	fmt.Fprint(ptrArchivo, iniciarTablaReporte(accesible, traducirReporte("por_seccion"),
+		traducirReporte("seccion"), traducirReporte("trabajo"), traducirReporte("fecha"), traducirReporte("archivos"),
+		traducirReporte("marcados"), traducirReporte("tasa_marcados"), traducirReporte("pares_internos"), traducirReporte("pares_cruzados")))
+	for _, seccion := range tablero.secciones {
+		fmt.Fprintf(ptrArchivo, "%s<td>%s</td><td>%s</td><td>%d</td><td>%d</td><td>%.1f%%</td><td>%d</td><td>%d</td></tr>\n",
+			"<tr>"+encabezarFilaReporte(accesible, html.EscapeString(seccion.nombre)), seccion.trabajo, seccion.creado.Format("2006-01-02 15:04"),
+			len(seccion.archivos), seccion.marcados, calcularTasaMarcados(seccion.marcados, len(seccion.archivos)), seccion.paresInternos, seccion.paresCruzados)
+	}
+	fmt.Fprint(ptrArchivo, terminarTablaReporte(accesible))
+	if len(tablero.omitidas) > 0 {
+		fmt.Fprintf(ptrArchivo, "<p>%s %s</p>\n", traducirReporte("secciones_omitidas"), html.EscapeString(strings.Join(tablero.omitidas, ", ")))
+	}
+
+	// Pares entre secciones
+	fmt.Fprintf(ptrArchivo, "<h2>%s (%d)</h2>", traducirReporte("pares_entre_secciones"), len(tablero.cruzados))
+	fmt.Fprint(ptrArchivo, iniciarTablaReporte(accesible, traducirReporte("pares_entre_secciones"),
+		"#", traducirReporte("archivo_1"), traducirReporte("seccion"), traducirReporte("archivo_2"), traducirReporte("seccion"), traducirReporte("distancia")))
+	for posicion, par := range tablero.cruzados {
+		fmt.Fprintf(ptrArchivo, "<tr>%s<td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%.2f</td></tr>\n",
+			encabezarFilaReporte(accesible, strconv.Itoa(posicion+1)),
+			html.EscapeString(tablero.resultado.archivos[par.archivo1].nombre), html.EscapeString(tablero.secciones[par.seccion1].nombre),
+			html.EscapeString(tablero.resultado.archivos[par.archivo2].nombre), html.EscapeString(tablero.secciones[par.seccion2].nombre), par.distancia)
+	}
+	fmt.Fprint(ptrArchivo, terminarTablaReporte(accesible))
+
+	fmt.Fprintf(ptrArchivo, "%s%s</body></html>\n", marca.pie(), generarScriptIdiomas())
+}
+
+/*
+ * Función para generar el tablero de una tarea en consola y en HTML
+ * param: parámetros de ejecución, el motor y el preprocesamiento
+ * return: un error si el tablero no se puede construir
+ */
+func generarTableroTarea(parametros Parametros, motor Motor, preprocesamiento Preprocesamiento) error {
+	tablero, err := construirTableroTarea(parametros, motor, preprocesamiento)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Motor de características:", motor.nombre())
+	tablero.imprimir()
+
+	if parametros.nombreTableroHTML != "" {
+		tablero.generarHTML(parametros.marcaReporte, parametros.reporteAccesible, parametros.nombreTableroHTML)
+		fmt.Println("Tablero generado en \"" + parametros.nombreTableroHTML + "\"")
+	}
+
+	return nil
+}
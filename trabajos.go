@@ -0,0 +1,335 @@
+/*
+ * Cola de trabajos del modo servidor.
+ *
+ * Cada análisis enviado a POST /jobs se convierte en un trabajo independiente (con su propia copia de las
+ * entregas y de los parámetros) que espera en la cola hasta que haya cupo según el límite de análisis
+ * concurrentes (--max-jobs). El estado de cada trabajo se consulta con GET /jobs/{id} y los trabajos
+ * terminados se eliminan después del tiempo de retención (--job-retention), para que varios grupos de un
+ * curso puedan enviar sus entregas al mismo tiempo. Con un directorio de datos los trabajos también se
+ * guardan en disco (ver almacenamiento.go).
+ *
+ * Las entregas se copian al disco mientras se reciben (en el directorio de datos o en un archivo temporal) y se leen
+ * de allí al ejecutar el trabajo, para no conservarlas en memoria mientras esperan. La cola admite hasta
+ * --max-queued-jobs trabajos en espera; con la cola llena la petición se responde con 503 y Retry-After. Los trabajos
+ * que estaban en espera o en ejecución cuando el servidor se detuvo se marcan como fallidos al iniciar y sus entregas
+ * se eliminan.
+ */
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Estados de un trabajo
+const (
+	TRABAJO_EN_COLA      = "en_cola"
+	TRABAJO_EN_EJECUCION = "en_ejecucion"
+	TRABAJO_TERMINADO    = "terminado"
+	TRABAJO_FALLIDO      = "fallido"
+)
+
+// Segundos sugeridos (Retry-After) para reintentar cuando la cola está llena
+const ESPERA_COLA_LLENA = 60
+
+// Error de la cola con la cantidad máxima de trabajos en espera, la petición se responde con 503
+var errorColaLlena = fmt.Errorf("La cola de trabajos está llena (--max-queued-jobs), intente de nuevo más tarde")
+
+// Estructura de un trabajo de análisis (también es su representación JSON)
+// - identificador, curso y estado del trabajo
+// - tarea y sección (o sede) de las entregas, para el tablero de la tarea entre secciones (ver tablero.go)
+// - fechas de creación, inicio y finalización
+// - mensaje de error (si falló) y resultado del análisis (si terminó)
+type Trabajo struct {
+	Identificador string                 `json:"id"`
+	Curso         string                 `json:"curso,omitempty"`
+	Tarea         string                 `json:"tarea,omitempty"`
+	Seccion       string                 `json:"seccion,omitempty"`
+	Estado        string                 `json:"estado"`
+	Creado        time.Time              `json:"creado"`
+	Iniciado      *time.Time             `json:"iniciado,omitempty"`
+	Terminado     *time.Time             `json:"terminado,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+	Resultado     *ResultadoAnalisisJSON `json:"resultado,omitempty"`
+}
+
+// Estructura de la cola de trabajos
+// - trabajos por identificador (protegidos por el mutex)
+// - cupos de ejecución concurrente
+// - cantidad máxima de trabajos en espera y trabajos en espera (incluidos los que se están recibiendo)
+// - tiempo de retención de los trabajos terminados o fallidos
+// - almacenamiento de los trabajos (nil si solamente se conservan en memoria)
+type ColaTrabajos struct {
+	mutex        sync.Mutex
+	trabajos     map[string]*Trabajo
+	cupos        chan struct{}
+	maximoEnCola int
+	enEspera     int
+	retencion    time.Duration
+	almacen      *AlmacenTrabajos
+}
+
+/*
+ * Función para crear la cola de trabajos con los trabajos guardados en el almacenamiento. Los trabajos guardados sin
+ * terminar (el servidor se detuvo antes) se marcan como fallidos y se eliminan sus entregas.
+ * param: cantidad máxima de análisis concurrentes y de trabajos en espera, tiempo de retención de los trabajos
+ *        terminados y el almacenamiento
+ * return: la cola de trabajos o un error si una cantidad máxima no es válida o los trabajos no se pueden cargar
+ */
+func crearColaTrabajos(maximoConcurrentes int, maximoEnCola int, retencion time.Duration, almacen *AlmacenTrabajos) (*ColaTrabajos, error) {
+	if maximoConcurrentes < 1 {
+		return nil, fmt.Errorf("La cantidad de análisis concurrentes (--max-jobs) debe ser al menos 1")
+	}
+	if maximoEnCola < 1 {
+		return nil, fmt.Errorf("La cantidad de trabajos en espera (--max-queued-jobs) debe ser al menos 1")
+	}
+
+	cola := &ColaTrabajos{trabajos: make(map[string]*Trabajo), cupos: make(chan struct{}, maximoConcurrentes), maximoEnCola: maximoEnCola, retencion: retencion, almacen: almacen}
+
+	guardados, err := almacen.cargarTrabajos()
+	if err != nil {
+		return nil, err
+	}
+	for i := range guardados {
+		trabajo := &guardados[i]
+
+		if trabajo.Terminado == nil {
+			fin := time.Now()
+			trabajo.Estado, trabajo.Error, trabajo.Terminado = TRABAJO_FALLIDO, "El servidor se detuvo antes de terminar el trabajo", &fin
+			if err = almacen.guardarTrabajo(*trabajo); err != nil {
+				return nil, err
+			}
+			if err = almacen.eliminarFuentes(trabajo.Identificador); err != nil {
+				return nil, err
+			}
+		}
+
+		cola.trabajos[trabajo.Identificador] = trabajo
+	}
+
+	return cola, nil
+}
+
+/*
+ * Función para generar un identificador aleatorio (trabajos y sesiones)
+ * return: identificador hexadecimal
+ */
+func generarIdentificadorAleatorio() string {
+	aleatorio := make([]byte, 8)
+
+	if _, err := rand.Read(aleatorio); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(aleatorio)
+}
+
+/*
+ * Función para agregar un trabajo a la cola. Las entregas se copian al disco antes de encolar el trabajo,
+ * porque el cuerpo de la petición deja de estar disponible cuando se responde.
+ * param: curso, tarea y sección del trabajo (vacías si no se indican), flujo tar de las entregas y la función de análisis del trabajo
+ * return: una copia del trabajo encolado, errorColaLlena si la cola está llena o un error si las entregas no se pueden guardar
+ */
+func (cola *ColaTrabajos) agregar(curso string, tarea string, seccion string, lector io.Reader, analizar func(io.Reader) (ResultadoAnalisisJSON, error)) (Trabajo, error) {
+	cola.mutex.Lock()
+	if cola.enEspera >= cola.maximoEnCola {
+		cola.mutex.Unlock()
+		return Trabajo{}, errorColaLlena
+	}
+	cola.enEspera++
+	cola.mutex.Unlock()
+
+	trabajo := &Trabajo{Identificador: generarIdentificadorAleatorio(), Curso: curso, Tarea: tarea, Seccion: seccion, Estado: TRABAJO_EN_COLA, Creado: time.Now()}
+
+	temporal, err := cola.guardarEntregas(*trabajo, lector)
+	if err != nil {
+		cola.mutex.Lock()
+		cola.enEspera--
+		cola.mutex.Unlock()
+		return Trabajo{}, err
+	}
+
+	cola.mutex.Lock()
+	cola.purgar()
+	cola.trabajos[trabajo.Identificador] = trabajo
+	copia := *trabajo
+	cola.mutex.Unlock()
+
+	go func() {
+		cola.cupos <- struct{}{}
+		defer func() { <-cola.cupos }()
+
+		inicio := time.Now()
+		cola.mutex.Lock()
+		cola.enEspera--
+		trabajo.Estado, trabajo.Iniciado = TRABAJO_EN_EJECUCION, &inicio
+		cola.mutex.Unlock()
+
+		resultado, err := cola.analizarEntregas(trabajo.Identificador, temporal, analizar)
+
+		fin := time.Now()
+		cola.mutex.Lock()
+		defer cola.mutex.Unlock()
+
+		trabajo.Terminado = &fin
+		if err != nil {
+			trabajo.Estado, trabajo.Error = TRABAJO_FALLIDO, err.Error()
+		} else {
+			trabajo.Estado, trabajo.Resultado = TRABAJO_TERMINADO, &resultado
+		}
+
+		if err = cola.almacen.guardarTrabajo(*trabajo); err != nil {
+			fmt.Println("Error al guardar el trabajo", trabajo.Identificador+":", err)
+		}
+	}()
+
+	return copia, nil
+}
+
+/*
+ * Función para copiar al disco las entregas de un trabajo en espera: en el almacenamiento, con el trabajo en cola para
+ * marcarlo como fallido si el servidor se detiene antes de terminarlo, o en un archivo temporal si no hay almacenamiento
+ * param: trabajo y flujo tar de las entregas
+ * return: ruta del archivo temporal (vacía si las entregas están en el almacenamiento) o un error si no se pueden guardar
+ */
+func (cola *ColaTrabajos) guardarEntregas(trabajo Trabajo, lector io.Reader) (string, error) {
+	if cola.almacen != nil {
+		if err := cola.almacen.guardarTrabajo(trabajo); err != nil {
+			return "", err
+		}
+		if err := cola.almacen.guardarFuentes(trabajo.Identificador, lector); err != nil {
+			cola.almacen.eliminarTrabajo(trabajo.Identificador)
+			return "", err
+		}
+		return "", nil
+	}
+
+	archivo, err := ioutil.TempFile("", "sasc-trabajo-*.tar")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(archivo, lector)
+	if errCerrar := archivo.Close(); err == nil {
+		err = errCerrar
+	}
+	if err != nil {
+		os.Remove(archivo.Name())
+		return "", err
+	}
+
+	return archivo.Name(), nil
+}
+
+/*
+ * Función para analizar las entregas de un trabajo desde el disco (el archivo temporal se elimina al terminar)
+ * param: identificador del trabajo, ruta del archivo temporal (vacía si están en el almacenamiento) y la función de análisis
+ * return: resultado del análisis o un error si las entregas no se pueden leer o analizar
+ */
+func (cola *ColaTrabajos) analizarEntregas(identificador string, temporal string, analizar func(io.Reader) (ResultadoAnalisisJSON, error)) (ResultadoAnalisisJSON, error) {
+	var entregas io.ReadCloser
+	var err error
+
+	if temporal != "" {
+		defer os.Remove(temporal)
+		entregas, err = os.Open(temporal)
+	} else {
+		entregas, err = cola.almacen.abrirFuentes(identificador)
+	}
+	if err != nil {
+		return ResultadoAnalisisJSON{}, err
+	}
+	defer entregas.Close()
+
+	return analizar(entregas)
+}
+
+/*
+ * Función para consultar un trabajo
+ * param: identificador del trabajo
+ * return: una copia del trabajo y si existe
+ */
+func (cola *ColaTrabajos) consultar(identificador string) (Trabajo, bool) {
+	cola.mutex.Lock()
+	defer cola.mutex.Unlock()
+
+	cola.purgar()
+
+	trabajo, existe := cola.trabajos[identificador]
+	if !existe {
+		return Trabajo{}, false
+	}
+
+	return *trabajo, true
+}
+
+/*
+ * Función para listar los trabajos (sin sus resultados) de los cursos permitidos, del más antiguo al más reciente
+ * param: cursos a los que se tiene acceso
+ * return: copia de los trabajos
+ */
+func (cola *ColaTrabajos) listar(cursos []string) []Trabajo {
+	cola.mutex.Lock()
+	defer cola.mutex.Unlock()
+
+	cola.purgar()
+
+	trabajos := []Trabajo{}
+	for _, trabajo := range cola.trabajos {
+		if !permiteCurso(cursos, trabajo.Curso) {
+			continue
+		}
+
+		copia := *trabajo
+		copia.Resultado = nil
+		trabajos = append(trabajos, copia)
+	}
+
+	sort.Slice(trabajos, func(i, j int) bool {
+		return trabajos[i].Creado.Before(trabajos[j].Creado)
+	})
+
+	return trabajos
+}
+
+/*
+ * Función para eliminar los trabajos terminados o fallidos que superan el tiempo de retención.
+ * Se debe llamar con el mutex de la cola bloqueado.
+ */
+func (cola *ColaTrabajos) purgar() {
+	if cola.retencion <= 0 {
+		return
+	}
+
+	for identificador, trabajo := range cola.trabajos {
+		if trabajo.Terminado != nil && time.Since(*trabajo.Terminado) > cola.retencion {
+			delete(cola.trabajos, identificador)
+		}
+	}
+}
+
+/*
+ * Función para escribir la cantidad de trabajos por estado en el formato de texto de Prometheus
+ * param: destino de las métricas
+ */
+func (cola *ColaTrabajos) escribirMetricas(destino io.Writer) {
+	cola.mutex.Lock()
+	defer cola.mutex.Unlock()
+
+	cantidad := make(map[string]int)
+	for _, trabajo := range cola.trabajos {
+		cantidad[trabajo.Estado]++
+	}
+
+	fmt.Fprintf(destino, "# HELP sasc_jobs Trabajos retenidos por estado.\n# TYPE sasc_jobs gauge\n")
+	for _, estado := range []string{TRABAJO_EN_COLA, TRABAJO_EN_EJECUCION, TRABAJO_TERMINADO, TRABAJO_FALLIDO} {
+		fmt.Fprintf(destino, "sasc_jobs{estado=\"%s\"} %d\n", estado, cantidad[estado])
+	}
+}
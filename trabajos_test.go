@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+ * Función para esperar a que un trabajo llegue a un estado
+ * param: prueba, cola, identificador del trabajo y estado esperado
+ * return: el trabajo en el estado esperado
+ */
+func esperarEstadoTrabajoPrueba(t *testing.T, cola *ColaTrabajos, identificador string, estado string) Trabajo {
+	t.Helper()
+
+	for intento := 0; intento < 500; intento++ {
+		if trabajo, _ := cola.consultar(identificador); trabajo.Estado == estado {
+			return trabajo
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("el trabajo %s no llegó al estado %s", identificador, estado)
+
+	return Trabajo{}
+}
+
+func TestColaTrabajosLlena(t *testing.T) {
+	cola, err := crearColaTrabajos(1, 1, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	continuar := make(chan struct{})
+	analizar := func(lector io.Reader) (ResultadoAnalisisJSON, error) {
+		<-continuar
+		return ResultadoAnalisisJSON{}, nil
+	}
+
+	// Un trabajo en ejecución y uno en espera llenan la cola
+	enEjecucion, err := cola.agregar("", "", "", strings.NewReader("tar 1"), analizar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperarEstadoTrabajoPrueba(t, cola, enEjecucion.Identificador, TRABAJO_EN_EJECUCION)
+	enEspera, err := cola.agregar("", "", "", strings.NewReader("tar 2"), analizar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = cola.agregar("", "", "", strings.NewReader("tar 3"), analizar); err != errorColaLlena {
+		t.Errorf("trabajo con la cola llena: error = %v, se esperaba errorColaLlena", err)
+	}
+
+	// Al iniciar el trabajo en espera se libera su lugar en la cola
+	continuar <- struct{}{}
+	esperarEstadoTrabajoPrueba(t, cola, enEspera.Identificador, TRABAJO_EN_EJECUCION)
+	if _, err = cola.agregar("", "", "", strings.NewReader("tar 3"), analizar); err != nil {
+		t.Errorf("trabajo con un lugar libre en la cola: error = %v", err)
+	}
+	close(continuar)
+
+	if _, err := crearColaTrabajos(1, 0, time.Hour, nil); err == nil {
+		t.Error("cola sin trabajos en espera creada sin error")
+	}
+}
+
+func TestColaTrabajosEntregasEnDisco(t *testing.T) {
+	temporal := t.TempDir()
+	t.Setenv("TMPDIR", temporal)
+	directorio := t.TempDir()
+	almacen, err := crearAlmacenTrabajos(directorio, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, caso := range []struct {
+		nombre  string
+		almacen *AlmacenTrabajos
+	}{
+		{"almacenamiento", almacen},
+		{"archivo temporal", nil},
+	} {
+		cola, err := crearColaTrabajos(1, 10, time.Hour, caso.almacen)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		continuar := make(chan struct{})
+		recibidas := make(chan string, 1)
+		trabajo, err := cola.agregar("", "", "", strings.NewReader("contenido del tar"), func(lector io.Reader) (ResultadoAnalisisJSON, error) {
+			<-continuar
+			contenido, _ := io.ReadAll(lector)
+			recibidas <- string(contenido)
+			return ResultadoAnalisisJSON{}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Mientras el trabajo espera, sus entregas y el trabajo en cola están en el directorio de datos
+		if caso.almacen != nil {
+			if contenido, err := os.ReadFile(filepath.Join(directorio, trabajo.Identificador+".tar")); err != nil || string(contenido) != "contenido del tar" {
+				t.Errorf("%s: entregas guardadas = %q, %v", caso.nombre, contenido, err)
+			}
+			if _, err := os.Stat(filepath.Join(directorio, trabajo.Identificador+".json")); err != nil {
+				t.Errorf("%s: trabajo en cola sin guardar: %v", caso.nombre, err)
+			}
+		}
+
+		close(continuar)
+		if entregas := <-recibidas; entregas != "contenido del tar" {
+			t.Errorf("%s: entregas analizadas = %q", caso.nombre, entregas)
+		}
+		esperarEstadoTrabajoPrueba(t, cola, trabajo.Identificador, TRABAJO_TERMINADO)
+	}
+
+	// El archivo temporal se elimina al terminar el trabajo
+	if temporales, _ := filepath.Glob(filepath.Join(temporal, "sasc-trabajo-*.tar")); len(temporales) != 0 {
+		t.Errorf("archivos temporales sin eliminar: %v", temporales)
+	}
+}
+
+func TestCrearColaTrabajosInterrumpidos(t *testing.T) {
+	directorio := t.TempDir()
+	almacen, err := crearAlmacenTrabajos(directorio, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Trabajos guardados cuando el servidor se detuvo: uno en espera, uno en ejecución y uno terminado
+	inicio, fin := time.Now().Add(-time.Minute), time.Now()
+	guardados := []Trabajo{
+		{Identificador: "encola", Estado: TRABAJO_EN_COLA, Creado: inicio},
+		{Identificador: "ejecucion", Estado: TRABAJO_EN_EJECUCION, Creado: inicio, Iniciado: &inicio},
+		{Identificador: "terminado", Estado: TRABAJO_TERMINADO, Creado: inicio, Iniciado: &inicio, Terminado: &fin},
+	}
+	for _, trabajo := range guardados {
+		if err = almacen.guardarTrabajo(trabajo); err != nil {
+			t.Fatal(err)
+		}
+		if err = almacen.guardarFuentes(trabajo.Identificador, strings.NewReader("tar")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(directorio, "ejecucion.tar"+EXTENSION_TEMPORAL), []byte("t"), 0600)
+
+	cola, err := crearColaTrabajos(1, 10, time.Hour, almacen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, identificador := range []string{"encola", "ejecucion"} {
+		trabajo, _ := cola.consultar(identificador)
+		if trabajo.Estado != TRABAJO_FALLIDO || trabajo.Terminado == nil || trabajo.Error == "" {
+			t.Errorf("trabajo interrumpido %s = %+v, se esperaba fallido y terminado", identificador, trabajo)
+		}
+
+		var guardado Trabajo
+		contenido, _ := os.ReadFile(filepath.Join(directorio, identificador+".json"))
+		if json.Unmarshal(contenido, &guardado) != nil || guardado.Estado != TRABAJO_FALLIDO {
+			t.Errorf("trabajo interrumpido %s guardado como %q, se esperaba fallido", identificador, guardado.Estado)
+		}
+
+		for _, entregas := range []string{identificador + ".tar", identificador + ".tar" + EXTENSION_TEMPORAL} {
+			if _, err := os.Stat(filepath.Join(directorio, entregas)); !os.IsNotExist(err) {
+				t.Errorf("entregas %s del trabajo interrumpido sin eliminar", entregas)
+			}
+		}
+	}
+
+	if trabajo, _ := cola.consultar("terminado"); trabajo.Estado != TRABAJO_TERMINADO {
+		t.Errorf("trabajo terminado = %q", trabajo.Estado)
+	}
+	if _, err := os.Stat(filepath.Join(directorio, "terminado.tar")); err != nil {
+		t.Errorf("entregas del trabajo terminado eliminadas: %v", err)
+	}
+
+	// Los trabajos interrumpidos se purgan con la retención de los terminados
+	cola.retencion = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if trabajos := cola.listar([]string{"*"}); len(trabajos) != 0 {
+		t.Errorf("trabajos después de la retención = %v", trabajos)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+ * Reporte de validez de los archivos (--validity-report).
+ *
+ * Por cada archivo del listado (analizado o descartado) se reporta su tamaño, la codificación detectada, si es
+ * binario o de texto, los errores de sintaxis (solamente para Go, con el analizador de la biblioteca estándar)
+ * y si se analizó o se descartó, además de los archivos .zip omitidos por no ser válidos o superar los límites de la
+ * extracción (ver comprimidos.go), de modo que los monitores puedan contactar a los estudiantes cuyas entregas no
+ * se pudieron analizar. El resultado JSON de --stdin y del modo servidor incluye el mismo reporte.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"unicode/utf8"
+)
+
+// Cantidad de bytes iniciales en los que se busca un byte nulo para considerar un archivo binario (como git)
+const BYTES_DETECCION_BINARIO = 8000
+
+// Estados de un archivo en el reporte de validez
+const (
+	VALIDEZ_ANALIZADO  = "analizado"
+	VALIDEZ_DESCARTADO = "descartado (sin contenido)"
+	VALIDEZ_OMITIDO    = "omitido (.zip no válido)"
+)
+
+// Estructura de la validez de un archivo (también su representación JSON)
+// - archivo y estudiante al que pertenece
+// - tamaño en bytes
+// - codificación detectada y si es binario
+// - primer error de sintaxis (vacío si no tiene errores o el lenguaje no se valida)
+// - estado del archivo en el análisis y motivo (solo de los .zip omitidos)
+type ValidezArchivo struct {
+	Archivo       string `json:"archivo"`
+	Estudiante    string `json:"estudiante"`
+	Tamano        int    `json:"tamano"`
+	Codificacion  string `json:"codificacion"`
+	Binario       bool   `json:"binario"`
+	ErrorSintaxis string `json:"error_sintaxis,omitempty"`
+	Estado        string `json:"estado"`
+	Motivo        string `json:"motivo,omitempty"`
+}
+
+/*
+ * Función para detectar la codificación de un contenido
+ * param: contenido del archivo (sin preprocesar)
+ * return: nombre de la codificación detectada
+ */
+func detectarCodificacion(contenido []byte) string {
+	switch {
+	case bytes.HasPrefix(contenido, []byte{0xEF, 0xBB, 0xBF}):
+		return "UTF-8 con BOM"
+	case bytes.HasPrefix(contenido, []byte{0xFF, 0xFE}):
+		return "UTF-16 LE"
+	case bytes.HasPrefix(contenido, []byte{0xFE, 0xFF}):
+		return "UTF-16 BE"
+	}
+
+	if !utf8.Valid(contenido) {
+		return "ISO-8859-1 / Windows-1252"
+	}
+
+	for _, caracter := range contenido {
+		if caracter >= utf8.RuneSelf {
+			return "UTF-8"
+		}
+	}
+
+	return "ASCII"
+}
+
+/*
+ * Función para determinar si un contenido es binario (tiene un byte nulo al inicio)
+ * param: contenido del archivo (sin preprocesar) y su codificación
+ * return: si el contenido es binario
+ */
+func esBinario(contenido []byte, codificacion string) bool {
+	// Los archivos UTF-16 tienen bytes nulos aunque sean de texto
+	if codificacion == "UTF-16 LE" || codificacion == "UTF-16 BE" {
+		return false
+	}
+
+	if len(contenido) > BYTES_DETECCION_BINARIO {
+		contenido = contenido[:BYTES_DETECCION_BINARIO]
+	}
+
+	return bytes.IndexByte(contenido, 0) >= 0
+}
+
+/*
+ * Función para obtener el primer error de sintaxis de un contenido
+ * param: contenido del archivo y extensión
+ * return: el error de sintaxis (vacío si no tiene errores o el lenguaje no se valida)
+ */
+func obtenerErrorSintaxis(contenido []byte, extension string) string {
+	if extension != "go" {
+		return ""
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "", contenido, parser.AllErrors); err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
+
+/*
+ * Función para evaluar la validez de un archivo
+ * param: nombre del archivo en los reportes, estudiante, contenido (sin preprocesar), extensión y estado en el análisis
+ * return: la validez del archivo
+ */
+func evaluarValidez(nombre string, estudiante string, contenido []byte, extension string, estado string) ValidezArchivo {
+	validez := ValidezArchivo{Archivo: nombre, Estudiante: estudiante, Tamano: len(contenido), Codificacion: detectarCodificacion(contenido), Estado: estado}
+	validez.Binario = esBinario(contenido, validez.Codificacion)
+
+	if validez.Binario {
+		validez.Codificacion = "-"
+	} else {
+		validez.ErrorSintaxis = obtenerErrorSintaxis(contenido, extension)
+	}
+
+	return validez
+}
+
+/*
+ * Función para evaluar la validez de un archivo .zip omitido del análisis
+ * param: nombre del .zip en los reportes, estudiante, tamaño en bytes y motivo
+ * return: la validez del .zip (binario, sin codificación)
+ */
+func evaluarValidezOmitido(nombre string, estudiante string, tamano int, motivo string) ValidezArchivo {
+	return ValidezArchivo{Archivo: nombre, Estudiante: estudiante, Tamano: tamano, Codificacion: "-", Binario: true, Estado: VALIDEZ_OMITIDO, Motivo: motivo}
+}
+
+/*
+ * Función para evaluar la validez de los archivos de un listado
+ * param: listado de archivos, archivos descartados, .zip omitidos, extensión, formato de las rutas y contenido de los
+ *        .zip del listado
+ * return: la validez de cada archivo, en el orden del listado, seguida de la de los .zip omitidos
+ */
+func evaluarValidezListado(listado []string, descartados []string, omitidos []ZipOmitido, extension string, formatoRutas FormatoRutas, archivosZip ArchivosComprimidos) []ValidezArchivo {
+	var reporte []ValidezArchivo
+
+	esDescartado := make(map[string]bool, len(descartados))
+	for _, archivo := range descartados {
+		esDescartado[archivo] = true
+	}
+
+	for _, archivo := range listado {
+		contenido, err := archivosZip.leer(archivo)
+		if err != nil {
+			panic(err)
+		}
+
+		estado := VALIDEZ_ANALIZADO
+		if esDescartado[archivo] {
+			estado = VALIDEZ_DESCARTADO
+		}
+
+		reporte = append(reporte, evaluarValidez(formatoRutas.formatear(archivo), obtenerEstudiante(".", archivo), contenido, extension, estado))
+	}
+
+	for _, omitido := range omitidos {
+		reporte = append(reporte, evaluarValidezOmitido(formatoRutas.formatear(omitido.Archivo), obtenerEstudiante(".", omitido.Archivo), omitido.tamano, omitido.Motivo))
+	}
+
+	return reporte
+}
+
+/*
+ * Función para guardar el reporte de validez en un archivo CSV (separado por tabuladores, como la matriz de distancias)
+ * param: validez de cada archivo y nombre del archivo CSV
+ */
+func generarReporteValidez(reporte []ValidezArchivo, nombreArchivo string) {
+	ptrArchivo, err := os.Create(nombreArchivo)
+
+	if err != nil {
+		panic(err)
+	}
+	defer ptrArchivo.Close()
+
+	fmt.Fprintf(ptrArchivo, "ARCHIVO\tESTUDIANTE\tTAMAÑO (BYTES)\tCODIFICACIÓN\tTIPO\tESTADO\tERROR DE SINTAXIS\tMOTIVO\n")
+
+	for _, validez := range reporte {
+		tipo := "texto"
+		if validez.Binario {
+			tipo = "binario"
+		}
+
+		fmt.Fprintf(ptrArchivo, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			validez.Archivo, validez.Estudiante, validez.Tamano, validez.Codificacion, tipo, validez.Estado, validez.ErrorSintaxis, validez.Motivo)
+	}
+}
+
+/*
+ * Función para contar los archivos con problemas de validez (binarios, con errores de sintaxis o descartados)
+ * param: validez de cada archivo
+ * return: cantidad de archivos con problemas
+ */
+func contarProblemasValidez(reporte []ValidezArchivo) int {
+	cantidad := 0
+
+	for _, validez := range reporte {
+		if validez.Binario || validez.ErrorSintaxis != "" || validez.Estado != VALIDEZ_ANALIZADO {
+			cantidad++
+		}
+	}
+
+	return cantidad
+}
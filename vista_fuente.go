@@ -0,0 +1,66 @@
+/*
+ * Vista previa del código fuente en el reporte de distancias (--show-source).
+ *
+ * Debajo de cada par a una distancia máxima se imprimen las primeras N líneas coincidentes (con los números de
+ * línea en ambos archivos, como en la evidencia del motor de líneas), de modo que se puede hacer un primer juicio
+ * sin abrir los archivos. Las líneas se comparan normalizadas, con cualquier motor de características, y los datos
+ * personales de sus comentarios se ocultan (ver redaccion.go).
+ */
+
+package main
+
+import "fmt"
+
+// Estructura con las líneas de los archivos ya leídas para la vista previa (cada archivo se lee una sola vez)
+type VistaFuente struct {
+	cantidad     int
+	minimoLineas int
+	redaccion    *Redaccion
+	textos       map[int][]string
+}
+
+/*
+ * Función para crear la vista previa del código fuente
+ * param: arreglo con la información del código fuente de los archivos, cantidad de líneas por par (0 si no se muestra)
+ *        cantidad mínima de líneas consecutivas de un bloque coincidente y ocultamiento de los datos personales
+ * return: la vista previa (calcula las líneas normalizadas de los archivos si el motor no las calculó)
+ */
+func crearVistaFuente(tablaCodigoFuente []CodigoFuente, cantidad int, minimoLineas int, redaccion *Redaccion) VistaFuente {
+	if cantidad > 0 {
+		for i := range tablaCodigoFuente {
+			if tablaCodigoFuente[i].lineas == nil {
+				tablaCodigoFuente[i].lineas = obtenerLineasNormalizadas(tablaCodigoFuente[i].contenido)
+			}
+		}
+	}
+
+	return VistaFuente{cantidad: cantidad, minimoLineas: minimoLineas, redaccion: redaccion, textos: make(map[int][]string)}
+}
+
+/*
+ * Función para imprimir las primeras líneas coincidentes de un par de archivos
+ * param: arreglo con la información del código fuente de los archivos e índices del par
+ */
+func (vista VistaFuente) imprimir(tablaCodigoFuente []CodigoFuente, i int, j int) {
+	if vista.cantidad <= 0 {
+		return
+	}
+
+	coincidentes := filtrarBloquesLineas(obtenerLineasCoincidentes(tablaCodigoFuente[i].lineas, tablaCodigoFuente[j].lineas), vista.minimoLineas)
+	if len(coincidentes) == 0 {
+		fmt.Println("\t\t         (sin líneas coincidentes)")
+		return
+	}
+
+	if _, existe := vista.textos[i]; !existe {
+		vista.textos[i] = vista.redaccion.redactarLineas(leerLineas(tablaCodigoFuente[i]))
+	}
+
+	for posicion, coincidente := range coincidentes {
+		if posicion == vista.cantidad {
+			fmt.Printf("\t\t         ... %d líneas coincidentes más\n", len(coincidentes)-vista.cantidad)
+			break
+		}
+		fmt.Printf("\t\t%5d = %-5d %s\n", coincidente.numero1, coincidente.numero2, normalizarLinea(vista.textos[i][coincidente.numero1-1]))
+	}
+}